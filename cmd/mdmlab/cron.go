@@ -37,6 +37,7 @@ import (
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/macoffice"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/msrc"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/nvd"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/osv"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/oval"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/utils"
 	"github.com/it-laborato/MDM_Lab/server/webhooks"
@@ -97,6 +98,16 @@ func cronVulnerabilities(
 
 	vulnPath := configureVulnPath(*config, appConfig, logger)
 	if vulnPath != "" {
+		if config.BundleImportDir != "" {
+			importVulnBundle(ctx, logger, config, vulnPath)
+
+			// the data feeds are provided by the offline bundles, they must never be
+			// downloaded.
+			offlineConfig := *config
+			offlineConfig.DisableDataSync = true
+			config = &offlineConfig
+		}
+
 		level.Info(logger).Log("msg", "scanning vulnerabilities")
 		if err := scanVulnerabilities(ctx, ds, logger, config, appConfig, vulnPath); err != nil {
 			return fmt.Errorf("scanning vulnerabilities: %w", err)
@@ -108,6 +119,23 @@ func cronVulnerabilities(
 			return fmt.Errorf("updating vulnerability host counts: %w", err)
 		}
 		level.Info(logger).Log("msg", "vulnerability host counts updated", "took", time.Since(start).Seconds())
+
+		start = time.Now()
+		level.Info(logger).Log("msg", "syncing host vulnerabilities")
+		if err := ds.SyncHostVulnerabilities(ctx, start); err != nil {
+			return fmt.Errorf("syncing host vulnerabilities: %w", err)
+		}
+		level.Info(logger).Log("msg", "host vulnerabilities synced", "took", time.Since(start).Seconds())
+
+		if err := webhooks.TriggerVulnerabilitySLABreachWebhook(
+			ctx,
+			ds,
+			kitlog.With(logger, "webhook", "vulnerability_sla"),
+			appConfig,
+			time.Now(),
+		); err != nil {
+			errHandler(ctx, logger, "triggering vulnerability SLA breach webhook", err)
+		}
 	}
 
 	return nil
@@ -165,6 +193,7 @@ func scanVulnerabilities(
 	ovalVulns := checkOvalVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 	govalDictVulns := checkGovalDictionaryVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 	macOfficeVulns := checkMacOfficeVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
+	osvVulns := checkOSVVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 	customVulns := checkCustomVulnerabilities(ctx, ds, logger, config, vulnAutomationEnabled != "")
 
 	checkWinVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
@@ -179,8 +208,16 @@ func scanVulnerabilities(
 	vulns = append(vulns, ovalVulns...)
 	vulns = append(vulns, macOfficeVulns...)
 	vulns = append(vulns, govalDictVulns...)
+	vulns = append(vulns, osvVulns...)
 	vulns = append(vulns, customVulns...)
 
+	// suppressed vulnerabilities are not sent to the automations
+	vulns, err = ds.FilterSuppressedSoftwareVulnerabilities(ctx, vulns)
+	if err != nil {
+		errHandler(ctx, logger, "could not filter suppressed vulnerabilities", err)
+		return nil
+	}
+
 	meta, err := ds.ListCVEs(ctx, config.RecentVulnerabilityMaxAge)
 	if err != nil {
 		errHandler(ctx, logger, "could not fetch CVE meta", err)
@@ -497,6 +534,39 @@ func checkMacOfficeVulnerabilities(
 	return r
 }
 
+func checkOSVVulnerabilities(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	vulnPath string,
+	config *config.VulnerabilitiesConfig,
+	collectVulns bool,
+) []mdmlab.SoftwareVulnerability {
+	if !config.DisableDataSync {
+		err := osv.SyncFromGithub(ctx, vulnPath)
+		if err != nil {
+			errHandler(ctx, logger, "updating osv ecosystem dumps", err)
+		}
+
+		level.Debug(logger).Log("msg", "finished sync osv ecosystem dumps")
+	}
+
+	start := time.Now()
+	r, err := osv.Analyze(ctx, ds, vulnPath, collectVulns, config.Periodicity)
+	elapsed := time.Since(start)
+
+	level.Debug(logger).Log(
+		"msg", "osv-analysis-done",
+		"elapsed", elapsed,
+		"found new", len(r))
+
+	if err != nil {
+		errHandler(ctx, logger, "analyzing language packages for vulnerabilities", err)
+	}
+
+	return r
+}
+
 func newAutomationsSchedule(
 	ctx context.Context,
 	instanceID string,
@@ -533,6 +603,14 @@ func newAutomationsSchedule(
 				)
 			},
 		),
+		schedule.WithJob(
+			"certificate_expiration_webhook",
+			func(ctx context.Context) error {
+				return service.TriggerHostCertificatesExpirationWebhook(
+					ctx, ds, kitlog.With(logger, "automation", "certificate_expiration"),
+				)
+			},
+		),
 		schedule.WithJob(
 			"fire_outdated_automations",
 			func(ctx context.Context) error {
@@ -1214,6 +1292,9 @@ func newAppleMDMProfileManagerSchedule(
 		schedule.WithJob("manage_apple_declarations", func(ctx context.Context) error {
 			return service.ReconcileAppleDeclarations(ctx, ds, commander, logger)
 		}),
+		schedule.WithJob("manage_apple_recovery_locks", func(ctx context.Context) error {
+			return service.ReconcileAppleRecoveryLocks(ctx, ds, commander, logger)
+		}),
 	)
 
 	return s, nil
@@ -1428,6 +1509,27 @@ func newIPhoneIPadRefetcher(
 	return s, nil
 }
 
+func newHostCertificatesRefetcher(
+	ctx context.Context,
+	instanceID string,
+	periodicity time.Duration,
+	ds mdmlab.Datastore,
+	commander *apple_mdm.MDMAppleCommander,
+	logger kitlog.Logger,
+) (*schedule.Schedule, error) {
+	const name = string(mdmlab.CronHostCertificatesRefetcher)
+	logger = kitlog.With(logger, "cron", name, "component", "host-certificates-refetcher")
+	s := schedule.New(
+		ctx, name, instanceID, periodicity, ds, ds,
+		schedule.WithLogger(logger),
+		schedule.WithJob("cron_host_certificates_refetcher", func(ctx context.Context) error {
+			return service.RefetchHostCertificates(ctx, ds, commander, logger)
+		}),
+	)
+
+	return s, nil
+}
+
 // cronUninstallSoftwareMigration will update uninstall scripts for software.
 // Once all customers are using on MDMlab 4.57 or later, this job can be removed.
 func cronUninstallSoftwareMigration(
@@ -1478,3 +1580,49 @@ func newMaintainedAppSchedule(
 
 	return s, nil
 }
+
+func newSoftwareRolloutsSchedule(
+	ctx context.Context,
+	instanceID string,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+) (*schedule.Schedule, error) {
+	const (
+		name            = string(mdmlab.CronSoftwareRollouts)
+		defaultInterval = 5 * time.Minute
+	)
+
+	logger = kitlog.With(logger, "cron", name)
+	s := schedule.New(
+		ctx, name, instanceID, defaultInterval, ds, ds,
+		schedule.WithLogger(logger),
+		schedule.WithJob("process_software_rollouts", func(ctx context.Context) error {
+			return service.ProcessSoftwareInstallerRollouts(ctx, ds, logger, time.Now())
+		}),
+	)
+
+	return s, nil
+}
+
+func newSoftwareBlocklistSchedule(
+	ctx context.Context,
+	instanceID string,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+) (*schedule.Schedule, error) {
+	const (
+		name            = string(mdmlab.CronSoftwareBlocklist)
+		defaultInterval = 1 * time.Hour
+	)
+
+	logger = kitlog.With(logger, "cron", name)
+	s := schedule.New(
+		ctx, name, instanceID, defaultInterval, ds, ds,
+		schedule.WithLogger(logger),
+		schedule.WithJob("process_software_blocklist", func(ctx context.Context) error {
+			return service.ProcessSoftwareBlocklist(ctx, ds, logger, time.Now())
+		}),
+	)
+
+	return s, nil
+}
@@ -27,6 +27,8 @@ func main() {
 	configManager := config.NewManager(rootCmd)
 
 	rootCmd.AddCommand(createVulnProcessingCmd(configManager))
+	rootCmd.AddCommand(createVulnBundleCmd(configManager))
+	rootCmd.AddCommand(createSoftwareCacheCmd(configManager))
 	rootCmd.AddCommand(createPrepareCmd(configManager))
 	rootCmd.AddCommand(createServeCmd(configManager))
 	rootCmd.AddCommand(createConfigDumpCmd(configManager))
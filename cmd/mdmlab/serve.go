@@ -978,6 +978,13 @@ the way that the MDMlab server works.
 				initFatal(err, "failed to register APNs pusher schedule")
 			}
 
+			if err := cronSchedules.StartCronSchedule(func() (mdmlab.CronSchedule, error) {
+				commander := apple_mdm.NewMDMAppleCommander(mdmStorage, mdmPushService)
+				return newHostCertificatesRefetcher(ctx, instanceID, 1*time.Hour, ds, commander, logger)
+			}); err != nil {
+				initFatal(err, "failed to register host_certificates_refetcher schedule")
+			}
+
 			if license.IsPremium() {
 				if err := cronSchedules.StartCronSchedule(func() (mdmlab.CronSchedule, error) {
 					commander := apple_mdm.NewMDMAppleCommander(mdmStorage, mdmPushService)
@@ -991,6 +998,18 @@ the way that the MDMlab server works.
 				}); err != nil {
 					initFatal(err, "failed to register maintained apps schedule")
 				}
+
+				if err := cronSchedules.StartCronSchedule(func() (mdmlab.CronSchedule, error) {
+					return newSoftwareRolloutsSchedule(ctx, instanceID, ds, logger)
+				}); err != nil {
+					initFatal(err, "failed to register software rollouts schedule")
+				}
+
+				if err := cronSchedules.StartCronSchedule(func() (mdmlab.CronSchedule, error) {
+					return newSoftwareBlocklistSchedule(ctx, instanceID, ds, logger)
+				}); err != nil {
+					initFatal(err, "failed to register software blocklist schedule")
+				}
 			}
 
 			if license.IsPremium() && config.Activity.EnableAuditLog {
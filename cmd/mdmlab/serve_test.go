@@ -565,6 +565,9 @@ func TestScanVulnerabilities(t *testing.T) {
 			},
 		}, nil
 	}
+	ds.FilterSuppressedSoftwareVulnerabilitiesFunc = func(ctx context.Context, vulns []mdmlab.SoftwareVulnerability) ([]mdmlab.SoftwareVulnerability, error) {
+		return vulns, nil
+	}
 	ds.HostVulnSummariesBySoftwareIDsFunc = func(ctx context.Context, softwareIDs []uint) ([]mdmlab.HostVulnerabilitySummary, error) {
 		return []mdmlab.HostVulnerabilitySummary{
 			{
@@ -594,6 +597,9 @@ func TestScanVulnerabilities(t *testing.T) {
 	// ensure that nvd vulnerabilities are not deleted
 	require.False(t, ds.DeleteSoftwareVulnerabilitiesFuncInvoked)
 
+	// ensure that suppressed vulnerabilities were filtered out
+	require.True(t, ds.FilterSuppressedSoftwareVulnerabilitiesFuncInvoked)
+
 	// ensure that webhook was called
 	require.Equal(t, 1, webhookCount)
 }
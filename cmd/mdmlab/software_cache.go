@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/pkg/mdmlabhttp"
+	"github.com/it-laborato/MDM_Lab/server/config"
+	"github.com/it-laborato/MDM_Lab/server/softwarecache"
+	"github.com/spf13/cobra"
+)
+
+func createSoftwareCacheCmd(configManager config.Manager) *cobra.Command {
+	var (
+		serverURL     string
+		listenAddress string
+		cacheDir      string
+		maxSizeMB     int64
+		tlsCert       string
+		tlsKey        string
+		serverRootCA  string
+		insecure      bool
+	)
+	softwareCacheCmd := &cobra.Command{
+		Use:   "software_cache",
+		Short: "Run a local cache of the software installers and bootstrap packages",
+		Long: `The software_cache command runs a caching proxy of the software installer and bootstrap package downloads
+on a designated host of a site, so that large packages are downloaded only once over the WAN link.
+
+Configure the cache URL and the subnets of the hosts it serves in software_installer_settings.caches. Orbit
+downloads the software installers from the cache of its subnet and falls back to the MDMlab server if the cache
+is unreachable. The cache URL must be an https URL: serve the cache with --tls_cert and --tls_key, or behind a
+TLS-terminating proxy. The cache doesn't hold any credentials, the downloads of the hosts are authorized by
+short-lived tokens issued by the MDMlab server for a single install, and the packages are verified against their
+SHA-256 hash before they are stored.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if serverURL == "" || cacheDir == "" {
+				return errors.New("--server_url and --cache_dir are required")
+			}
+			if (tlsCert == "") != (tlsKey == "") {
+				return errors.New("--tls_cert and --tls_key must be set together")
+			}
+
+			cfg := configManager.LoadConfig()
+			logger := initLogger(cfg)
+
+			tlsConfig := &tls.Config{
+				InsecureSkipVerify: insecure, //nolint:gosec // explicitly requested for test environments
+			}
+			if serverRootCA != "" {
+				pem, err := os.ReadFile(serverRootCA)
+				if err != nil {
+					return fmt.Errorf("reading server root CA: %w", err)
+				}
+				rootCAs := x509.NewCertPool()
+				if !rootCAs.AppendCertsFromPEM(pem) {
+					return fmt.Errorf("no certificate found in %s", serverRootCA)
+				}
+				tlsConfig.RootCAs = rootCAs
+			}
+			client := mdmlabhttp.NewClient(mdmlabhttp.WithTLSClientConfig(tlsConfig))
+
+			cache, err := softwarecache.New(serverURL, cacheDir, maxSizeMB*1024*1024, client, logger)
+			if err != nil {
+				return err
+			}
+
+			srv := &http.Server{
+				Addr:              listenAddress,
+				Handler:           cache.Handler(),
+				ReadHeaderTimeout: 30 * time.Second,
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			errs := make(chan error, 1)
+			go func() {
+				level.Info(logger).Log("msg", "software cache listening", "address", listenAddress, "server_url", serverURL, "tls", tlsCert != "")
+				if tlsCert != "" {
+					errs <- srv.ListenAndServeTLS(tlsCert, tlsKey)
+				} else {
+					errs <- srv.ListenAndServe()
+				}
+			}()
+
+			select {
+			case err := <-errs:
+				return err
+			case <-ctx.Done():
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		},
+	}
+	softwareCacheCmd.Flags().StringVar(&serverURL, "server_url", "", "URL of the MDMlab server")
+	softwareCacheCmd.Flags().StringVar(&listenAddress, "listen_address", "0.0.0.0:8443", "Address on which the cache listens")
+	softwareCacheCmd.Flags().StringVar(&cacheDir, "cache_dir", "", "Directory where the packages are cached")
+	softwareCacheCmd.Flags().Int64Var(&maxSizeMB, "max_size_mb", 0, "Maximum size of the cache in MB, the least recently used packages are removed above it (0 for no limit)")
+	softwareCacheCmd.Flags().StringVar(&tlsCert, "tls_cert", "", "Path of the TLS certificate of the cache, it must be trusted by the hosts")
+	softwareCacheCmd.Flags().StringVar(&tlsKey, "tls_key", "", "Path of the TLS key of the cache")
+	softwareCacheCmd.Flags().StringVar(&serverRootCA, "server_root_ca", "", "Path of the root CA certificate used to verify the MDMlab server certificate")
+	softwareCacheCmd.Flags().BoolVar(&insecure, "insecure", false, "Disable the verification of the MDMlab server certificate")
+	softwareCacheCmd.SilenceUsage = true
+	return softwareCacheCmd
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/server/config"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/bundle"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/goval_dictionary"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/macoffice"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/msrc"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/nvd"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/osv"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/oval"
+	"github.com/spf13/cobra"
+)
+
+// defaultBundleGovalDictionaryPlatforms are the goval-dictionary databases
+// included in the bundles by default. openSUSE Leap databases are published
+// per minor version and must be requested explicitly (e.g. opensuse-leap_1506).
+var defaultBundleGovalDictionaryPlatforms = []string{
+	"amzn_01",
+	"amzn_02",
+	"amzn_2022",
+	"amzn_2023",
+	"sles_12",
+	"sles_15",
+	"opensuse-tumbleweed",
+}
+
+func createVulnBundleCmd(configManager config.Manager) *cobra.Command {
+	vulnBundleCmd := &cobra.Command{
+		Use:   "vuln_bundle",
+		Short: "Create and import offline vulnerability data bundles",
+		Long: `The vuln_bundle command allows vulnerability processing on MDMlab servers without internet access.
+
+A bundle is a signed, versioned archive of all the vulnerability data feeds (NVD, CPE, OVAL, goval-dictionary,
+MSRC, Mac Office and OSV). Bundles are created with 'vuln_bundle create' on a machine with internet access and
+imported on the offline server either with 'vuln_bundle import' or by copying them into the directory configured
+with 'vulnerabilities.bundle_import_dir', in which case the newest bundle is imported before each vulnerability
+processing run and the data feeds are never downloaded.`,
+	}
+	vulnBundleCmd.AddCommand(
+		createVulnBundleKeygenCmd(),
+		createVulnBundleCreateCmd(configManager),
+		createVulnBundleImportCmd(configManager),
+	)
+	return vulnBundleCmd
+}
+
+func createVulnBundleKeygenCmd() *cobra.Command {
+	var publicKeyPath, privateKeyPath string
+	keygenCmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate the key pair used to sign and verify vulnerability data bundles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if publicKeyPath == "" || privateKeyPath == "" {
+				return errors.New("--public_key and --private_key are required")
+			}
+			pub, priv, err := bundle.GenerateKeyPair()
+			if err != nil {
+				return err
+			}
+			// never overwrite an existing key, bundles signed with it would no longer be importable
+			if err := writeNewFile(privateKeyPath, priv, 0o600); err != nil {
+				return err
+			}
+			if err := writeNewFile(publicKeyPath, pub, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Private key written to %s, keep it on the machine creating the bundles.\n", privateKeyPath)
+			fmt.Fprintf(cmd.OutOrStdout(), "Public key written to %s, configure it with vulnerabilities.bundle_public_key on the offline server.\n", publicKeyPath)
+			return nil
+		},
+	}
+	keygenCmd.Flags().StringVar(&publicKeyPath, "public_key", "", "Path of the public key to generate")
+	keygenCmd.Flags().StringVar(&privateKeyPath, "private_key", "", "Path of the private key to generate")
+	keygenCmd.SilenceUsage = true
+	return keygenCmd
+}
+
+func createVulnBundleCreateCmd(configManager config.Manager) *cobra.Command {
+	var (
+		output                   string
+		privateKeyPath           string
+		version                  string
+		srcDir                   string
+		govalDictionaryPlatforms []string
+	)
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Download all vulnerability data feeds into a signed bundle",
+		Long: `Download all vulnerability data feeds and write them into a bundle signed with the given private key.
+
+If --from_dir is set, the feeds already present in that directory (e.g. the databases path of an online MDMlab
+server) are bundled instead of being downloaded.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" || privateKeyPath == "" {
+				return errors.New("--output and --private_key are required")
+			}
+			privateKey, err := bundle.LoadPrivateKey(privateKeyPath)
+			if err != nil {
+				return err
+			}
+
+			cfg := configManager.LoadConfig()
+			logger := initLogger(cfg)
+
+			if srcDir == "" {
+				tmpDir, err := os.MkdirTemp("", "mdmlab-vuln-bundle-")
+				if err != nil {
+					return fmt.Errorf("create temporary directory: %w", err)
+				}
+				defer os.RemoveAll(tmpDir)
+
+				platforms := make([]oval.Platform, 0, len(govalDictionaryPlatforms))
+				for _, p := range govalDictionaryPlatforms {
+					platforms = append(platforms, oval.Platform(p))
+				}
+				if err := syncAllVulnFeeds(cmd.Context(), logger, cfg.Vulnerabilities, tmpDir, platforms); err != nil {
+					return err
+				}
+				srcDir = tmpDir
+			}
+
+			// write to a temporary file first so that a partially written bundle is never
+			// picked up if the output is a watched directory
+			tmpOutput := output + ".tmp"
+			f, err := os.Create(tmpOutput)
+			if err != nil {
+				return fmt.Errorf("create bundle: %w", err)
+			}
+			defer os.Remove(tmpOutput)
+
+			manifest, err := bundle.Create(f, srcDir, bundle.CreateOptions{
+				Version:    version,
+				PrivateKey: privateKey,
+			})
+			if err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("close bundle: %w", err)
+			}
+			if err := os.Rename(tmpOutput, output); err != nil {
+				return fmt.Errorf("save bundle: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Bundle %s (%d files) written to %s\n", manifest.Version, len(manifest.Files), output)
+			return nil
+		},
+	}
+	createCmd.Flags().StringVar(&output, "output", "", "Path of the bundle to create, should end with "+bundle.Extension)
+	createCmd.Flags().StringVar(&privateKeyPath, "private_key", "", "Path of the private key used to sign the bundle")
+	createCmd.Flags().StringVar(&version, "version", "", "Version of the bundle (defaults to the creation timestamp)")
+	createCmd.Flags().StringVar(&srcDir, "from_dir", "", "Bundle the feeds found in this directory instead of downloading them")
+	createCmd.Flags().StringSliceVar(&govalDictionaryPlatforms, "goval_dictionary_platforms", defaultBundleGovalDictionaryPlatforms,
+		"goval-dictionary databases to include in the bundle")
+	createCmd.SilenceUsage = true
+	return createCmd
+}
+
+func createVulnBundleImportCmd(configManager config.Manager) *cobra.Command {
+	var (
+		publicKeyPath string
+		databasesPath string
+		force         bool
+	)
+	importCmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Verify and import a vulnerability data bundle into the databases path",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := configManager.LoadConfig()
+			if publicKeyPath == "" {
+				publicKeyPath = cfg.Vulnerabilities.BundlePublicKey
+			}
+			if databasesPath == "" {
+				databasesPath = cfg.Vulnerabilities.DatabasesPath
+			}
+			if publicKeyPath == "" {
+				return errors.New("public key not set, use --public_key or vulnerabilities.bundle_public_key")
+			}
+			if databasesPath == "" {
+				return errors.New("databases path not set, use --databases_path or vulnerabilities.databases_path")
+			}
+
+			publicKey, err := bundle.LoadPublicKey(publicKeyPath)
+			if err != nil {
+				return err
+			}
+			manifest, err := bundle.Import(args[0], databasesPath, bundle.ImportOptions{
+				PublicKey: publicKey,
+				Force:     force,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Bundle %s created at %s imported into %s\n",
+				manifest.Version, manifest.CreatedAt.Format(time.RFC3339), databasesPath)
+			if !cfg.Vulnerabilities.DisableDataSync && cfg.Vulnerabilities.BundleImportDir == "" {
+				fmt.Fprintln(cmd.OutOrStdout(),
+					"Set vulnerabilities.disable_data_sync or vulnerabilities.bundle_import_dir so that MDMlab doesn't try to download the feeds.")
+			}
+			return nil
+		},
+	}
+	importCmd.Flags().StringVar(&publicKeyPath, "public_key", "", "Path of the public key used to verify the bundle (defaults to vulnerabilities.bundle_public_key)")
+	importCmd.Flags().StringVar(&databasesPath, "databases_path", "", "Path to import the bundle into (defaults to vulnerabilities.databases_path)")
+	importCmd.Flags().BoolVar(&force, "force", false, "Import the bundle even if it is older than the last imported bundle")
+	importCmd.SilenceUsage = true
+	return importCmd
+}
+
+// syncAllVulnFeeds downloads the vulnerability data feeds of all the supported
+// platforms into dstDir.
+func syncAllVulnFeeds(
+	ctx context.Context,
+	logger kitlog.Logger,
+	vulnConfig config.VulnerabilitiesConfig,
+	dstDir string,
+	govalDictionaryPlatforms []oval.Platform,
+) error {
+	level.Info(logger).Log("msg", "downloading NVD feeds")
+	if err := nvd.Sync(nvd.SyncOptions{
+		VulnPath:           dstDir,
+		CPEDBURL:           vulnConfig.CPEDatabaseURL,
+		CPETranslationsURL: vulnConfig.CPETranslationsURL,
+		CVEFeedPrefixURL:   vulnConfig.CVEFeedPrefixURL,
+	}, logger); err != nil {
+		return fmt.Errorf("sync NVD: %w", err)
+	}
+
+	level.Info(logger).Log("msg", "downloading OVAL definitions")
+	if err := oval.Sync(dstDir, nil); err != nil {
+		return fmt.Errorf("sync OVAL: %w", err)
+	}
+
+	level.Info(logger).Log("msg", "downloading goval-dictionary databases")
+	if _, err := goval_dictionary.Sync(dstDir, govalDictionaryPlatforms); err != nil {
+		return fmt.Errorf("sync goval-dictionary: %w", err)
+	}
+
+	level.Info(logger).Log("msg", "downloading MSRC bulletins")
+	if err := msrc.SyncFromGithub(ctx, dstDir, nil); err != nil {
+		return fmt.Errorf("sync MSRC: %w", err)
+	}
+
+	level.Info(logger).Log("msg", "downloading Mac Office release notes")
+	if err := macoffice.SyncFromGithub(ctx, dstDir); err != nil {
+		return fmt.Errorf("sync Mac Office: %w", err)
+	}
+
+	level.Info(logger).Log("msg", "downloading OSV dumps")
+	if err := osv.SyncFromGithub(ctx, dstDir); err != nil {
+		return fmt.Errorf("sync OSV: %w", err)
+	}
+
+	return nil
+}
+
+// importVulnBundle imports the newest bundle found in the watched bundles
+// directory into vulnPath. Failures are reported but don't prevent the
+// vulnerability processing from running with the previously imported data.
+func importVulnBundle(ctx context.Context, logger kitlog.Logger, vulnConfig *config.VulnerabilitiesConfig, vulnPath string) {
+	if vulnConfig.BundlePublicKey == "" {
+		errHandler(ctx, logger, "importing vulnerability data bundle",
+			errors.New("vulnerabilities.bundle_public_key is required with vulnerabilities.bundle_import_dir"))
+		return
+	}
+	publicKey, err := bundle.LoadPublicKey(vulnConfig.BundlePublicKey)
+	if err != nil {
+		errHandler(ctx, logger, "loading vulnerability data bundle public key", err)
+		return
+	}
+
+	manifest, err := bundle.ImportLatest(vulnConfig.BundleImportDir, vulnPath, bundle.ImportOptions{PublicKey: publicKey})
+	if err != nil {
+		errHandler(ctx, logger, "importing vulnerability data bundle", err)
+	}
+	if manifest != nil {
+		level.Info(logger).Log(
+			"msg", "vulnerability data bundle imported",
+			"version", manifest.Version,
+			"created_at", manifest.CreatedAt,
+			"files", len(manifest.Files),
+		)
+	}
+}
+
+func writeNewFile(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
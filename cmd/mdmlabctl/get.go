@@ -302,6 +302,7 @@ func getCommand() *cli.Command {
 			getUserRolesCommand(),
 			getTeamsCommand(),
 			getSoftwareCommand(),
+			getSBOMCommand(),
 			getMDMAppleCommand(),
 			getMDMAppleBMCommand(),
 			getMDMCommandResultsCommand(),
@@ -1259,6 +1260,69 @@ func getSoftwareCommand() *cli.Command {
 	}
 }
 
+func getSBOMCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sbom",
+		Usage:     "Export the software bill of materials (SBOM) of a host or team",
+		UsageText: `mdmlabctl get sbom [options]`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "host",
+				Usage: "Export the SBOM of the host with the specified identifier (hostname, UUID, serial number or ID)",
+			},
+			&cli.UintFlag{
+				Name:  teamFlagName,
+				Usage: "Export the SBOM of the hosts that belong to the specified team (0 for hosts with no team)",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "cyclonedx",
+				Usage: "The SBOM format, either cyclonedx or spdx",
+			},
+			configFlag(),
+			contextFlag(),
+			debugFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			identifier := c.String("host")
+			if identifier != "" && c.IsSet(teamFlagName) {
+				return errors.New("Can't specify both host and team flags.")
+			}
+
+			var sbom []byte
+			switch {
+			case identifier != "":
+				host, err := client.HostByIdentifier(identifier)
+				if err != nil {
+					return fmt.Errorf("could not get host: %w", err)
+				}
+				sbom, err = client.GetHostSBOM(host.ID, c.String("format"))
+				if err != nil {
+					return fmt.Errorf("could not get host SBOM: %w", err)
+				}
+			default:
+				var teamID *uint
+				if c.IsSet(teamFlagName) {
+					tid := c.Uint(teamFlagName)
+					teamID = &tid
+				}
+				sbom, err = client.GetTeamSBOM(teamID, c.String("format"))
+				if err != nil {
+					return fmt.Errorf("could not get SBOM: %w", err)
+				}
+			}
+
+			fmt.Fprintln(c.App.Writer, string(sbom))
+			return nil
+		},
+	}
+}
+
 func printSoftwareVersions(c *cli.Context, client *service.Client, query url.Values) error {
 	software, err := client.ListSoftwareVersions(query.Encode())
 	if err != nil {
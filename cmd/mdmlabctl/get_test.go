@@ -2019,6 +2019,15 @@ func TestEnrichedAppConfig(t *testing.T) {
             "destination_url": "",
             "host_batch_size": 0
           },
+          "certificate_expiration_webhook": {
+            "enable_certificate_expiration_webhook": false,
+            "destination_url": "",
+            "days_before_expiration": 0
+          },
+          "software_requests_webhook": {
+            "enable_software_requests_webhook": false,
+            "destination_url": ""
+          },
           "interval": "24h0m0s"
         },
         "integrations": {
@@ -3120,3 +3129,33 @@ func TestFormatXML(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSBOM(t *testing.T) {
+	_, ds := runServerWithMockedDS(t)
+
+	var gotOpts mdmlab.SoftwareListOptions
+	ds.ListSoftwareFunc = func(ctx context.Context, opt mdmlab.SoftwareListOptions) ([]mdmlab.Software, *mdmlab.PaginationMetadata, error) {
+		gotOpts = opt
+		return []mdmlab.Software{
+			{ID: 1, Name: "lodash", Version: "4.17.20", Source: "npm_packages", Vulnerabilities: mdmlab.Vulnerabilities{{CVE: "CVE-2021-23337"}}},
+		}, &mdmlab.PaginationMetadata{}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*mdmlab.Team, error) {
+		return &mdmlab.Team{ID: tid, Name: "team1"}, nil
+	}
+
+	out := runAppForTest(t, []string{"get", "sbom"})
+	require.Nil(t, gotOpts.TeamID)
+	require.Contains(t, out, `"bomFormat": "CycloneDX"`)
+	require.Contains(t, out, `"purl": "pkg:npm/lodash@4.17.20"`)
+	require.Contains(t, out, `"id": "CVE-2021-23337"`)
+
+	out = runAppForTest(t, []string{"get", "sbom", "--team", "1", "--format", "spdx"})
+	require.Equal(t, ptr.Uint(1), gotOpts.TeamID)
+	require.Contains(t, out, `"spdxVersion": "SPDX-2.3"`)
+	require.Contains(t, out, `"name": "team1"`)
+
+	runAppCheckErr(t, []string{"get", "sbom", "--team", "0", "--format", "swid"},
+		`could not get SBOM: get SBOM received status 422: Validation Failed: unsupported SBOM format "swid", supported formats are "cyclonedx" and "spdx"`)
+	runAppCheckErr(t, []string{"get", "sbom", "--team", "1", "--host", "test_host"}, "Can't specify both host and team flags.")
+}
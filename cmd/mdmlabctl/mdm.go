@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/service"
@@ -27,6 +30,7 @@ func mdmCommand() *cli.Command {
 			mdmLockCommand(),
 			mdmUnlockCommand(),
 			mdmWipeCommand(),
+			mdmCommandsCommand(),
 		},
 	}
 }
@@ -256,6 +260,10 @@ func mdmWipeCommand() *cli.Command {
 			Name:     "host",
 			Usage:    "The host, specified by identifier, that you want to wipe.",
 			Required: true,
+		}, &cli.StringFlag{
+			Name: "windows-wipe-type",
+			Usage: fmt.Sprintf("The RemoteWipe CSP node used to wipe a Windows host: %s, %s (default) or %s.",
+				mdmlab.WindowsWipeTypeDoWipe, mdmlab.WindowsWipeTypeDoWipeProtected, mdmlab.WindowsWipeTypeDoWipePersistProvisionedData),
 		}},
 		Action: func(c *cli.Context) error {
 			hostIdent := c.String("host")
@@ -265,7 +273,11 @@ func mdmWipeCommand() *cli.Command {
 				return err
 			}
 
-			if err := client.MDMWipeHost(host.ID); err != nil {
+			var opts mdmlab.MDMWipeOptions
+			if wipeType := c.String("windows-wipe-type"); wipeType != "" {
+				opts.Windows = &mdmlab.MDMWindowsWipeOptions{WipeType: mdmlab.WindowsWipeType(wipeType)}
+			}
+			if err := client.MDMWipeHost(host.ID, opts); err != nil {
 				return fmt.Errorf("Failed to wipe host: %w", err)
 			}
 
@@ -281,6 +293,211 @@ mdmlabctl get host %s`, hostIdent)
 	}
 }
 
+func mdmCommandsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "commands",
+		Usage: "Inspect and manage the queue of Apple MDM commands",
+		Subcommands: []*cli.Command{
+			mdmCommandsListCommand(),
+			mdmCommandsCancelCommand(),
+			mdmCommandsPushCommand(),
+		},
+	}
+}
+
+func mdmCommandsListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List the Apple MDM commands queued for your hosts, with their status and NotNow retry count.",
+		Flags: []cli.Flag{
+			contextFlag(),
+			debugFlag(),
+			&cli.StringFlag{
+				Name:  "host",
+				Usage: "Filter by a host, specified by hostname, UUID, or serial number.",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Filter by the command's request type (e.g. InstallProfile).",
+			},
+			&cli.StringFlag{
+				Name:  "command-uuid",
+				Usage: "Filter by a command UUID.",
+			},
+			&cli.StringFlag{
+				Name:  "status",
+				Usage: "Filter by status: Pending, Acknowledged, Error, CommandFormatError or NotNow.",
+			},
+			&cli.UintFlag{
+				Name:  "per-page",
+				Usage: "Maximum number of results to return.",
+				Value: 100,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return fmt.Errorf("create client: %w", err)
+			}
+			if err := client.CheckAppleMDMEnabled(); err != nil {
+				return err
+			}
+
+			opts := mdmlab.MDMAppleCommandQueueListOptions{
+				ListOptions:    mdmlab.ListOptions{PerPage: c.Uint("per-page")},
+				HostIdentifier: c.String("host"),
+				RequestType:    c.String("type"),
+				CommandUUID:    c.String("command-uuid"),
+				Status:         mdmlab.MDMAppleCommandQueueStatus(c.String("status")),
+			}
+			results, err := client.MDMListAppleCommandQueue(opts)
+			if err != nil {
+				if strings.Contains(err.Error(), mdmlab.HostIdentiferNotFound) {
+					return errors.New(mdmlab.HostIdentiferNotFound)
+				}
+				return err
+			}
+			if len(results) == 0 {
+				log(c, "No queued MDM commands match the specified filters.\n")
+				return nil
+			}
+
+			data := make([][]string, 0, len(results))
+			for _, r := range results {
+				data = append(data, []string{
+					r.CommandUUID,
+					r.RequestType,
+					string(r.Status),
+					strconv.Itoa(r.NotNowCount),
+					r.Hostname,
+					r.LastSeenAt.Format(time.RFC3339),
+					r.UpdatedAt.Format(time.RFC3339),
+				})
+			}
+			columns := []string{"UUID", "TYPE", "STATUS", "NOT NOW", "HOSTNAME", "LAST SEEN", "UPDATED"}
+			printTable(c, columns, data)
+
+			return nil
+		},
+	}
+}
+
+func mdmCommandsCancelCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cancel",
+		Usage: "Cancel the pending Apple MDM commands of the specified hosts, or a pending command on all hosts. Profile, lock, wipe, lost mode, recovery lock and software install commands are not canceled.",
+		Flags: []cli.Flag{
+			contextFlag(),
+			debugFlag(),
+			&cli.StringSliceFlag{
+				Name:  "hosts",
+				Usage: "Comma-separated hosts to target. Hosts can be specified by hostname, UUID, or serial number.",
+			},
+			&cli.StringFlag{
+				Name:  "command-uuid",
+				Usage: "Only cancel this command. Required if no hosts are specified.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return fmt.Errorf("create client: %w", err)
+			}
+			if err := client.CheckAppleMDMEnabled(); err != nil {
+				return err
+			}
+
+			hostUUIDs, err := hostUUIDsFromIdentifiers(client, c.StringSlice("hosts"))
+			if err != nil {
+				return err
+			}
+			commandUUID := c.String("command-uuid")
+			if len(hostUUIDs) == 0 && commandUUID == "" {
+				return errors.New(`Either the "hosts" or the "command-uuid" flag must be set`)
+			}
+
+			n, err := client.MDMCancelAppleCommands(hostUUIDs, commandUUID)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(c.App.Writer, "Canceled %d pending MDM command(s).\n", n)
+			return nil
+		},
+	}
+}
+
+func mdmCommandsPushCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "push",
+		Usage: "Re-send a push notification to the hosts that have pending Apple MDM commands.",
+		Flags: []cli.Flag{
+			contextFlag(),
+			debugFlag(),
+			&cli.StringSliceFlag{
+				Name:  "hosts",
+				Usage: "Comma-separated hosts to target. Hosts can be specified by hostname, UUID, or serial number. Defaults to all hosts with pending commands.",
+			},
+			&cli.DurationFlag{
+				Name:  "stale-for",
+				Usage: "Only target hosts that did not check in with MDM for at least this duration (e.g. 24h).",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return fmt.Errorf("create client: %w", err)
+			}
+			if err := client.CheckAppleMDMEnabled(); err != nil {
+				return err
+			}
+
+			hostUUIDs, err := hostUUIDsFromIdentifiers(client, c.StringSlice("hosts"))
+			if err != nil {
+				return err
+			}
+			var lastSeenBefore *time.Time
+			if staleFor := c.Duration("stale-for"); staleFor > 0 {
+				t := time.Now().Add(-staleFor)
+				lastSeenBefore = &t
+			}
+
+			res, err := client.MDMResendApplePushNotifications(hostUUIDs, lastSeenBefore)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(c.App.Writer, "Sent a push notification to %d host(s).\n", len(res.HostUUIDs)-len(res.FailedHostUUIDs))
+			if len(res.FailedHostUUIDs) > 0 {
+				fmt.Fprintf(c.App.Writer, "Failed to deliver the push notification to: %s\n", strings.Join(res.FailedHostUUIDs, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// hostUUIDsFromIdentifiers returns the UUIDs of the hosts specified by the
+// provided identifiers, ignoring empty and duplicate identifiers.
+func hostUUIDsFromIdentifiers(client *service.Client, hostIdents []string) ([]string, error) {
+	slices.Sort(hostIdents)
+	hostIdents = slices.Compact(hostIdents)
+
+	var hostUUIDs []string
+	for _, ident := range hostIdents {
+		if ident == "" {
+			continue
+		}
+		host, err := client.HostByIdentifier(ident)
+		if err != nil {
+			var nfe service.NotFoundErr
+			if errors.As(err, &nfe) {
+				return nil, errors.New(mdmlab.TargetedHostsDontExistErrMsg)
+			}
+			return nil, err
+		}
+		hostUUIDs = append(hostUUIDs, host.UUID)
+	}
+	return hostUUIDs, nil
+}
+
 // Does some common setup for the host mdm actions such as validating the host,
 // creating the client, getting the desired host, checking permissions, and
 // ensuring MDM is turned on for the host.
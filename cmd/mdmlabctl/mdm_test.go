@@ -1309,6 +1309,48 @@ func writeTmpMobileconfig(t *testing.T, name string) string {
 	return tmpFile.Name()
 }
 
+func TestMDMCommandsCommand(t *testing.T) {
+	ds := setupTestServer(t)
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{MDM: mdmlab.MDM{EnabledAndConfigured: true}}, nil
+	}
+
+	var gotOpts *mdmlab.MDMAppleCommandQueueListOptions
+	ds.ListMDMAppleCommandQueueFunc = func(ctx context.Context, tmFilter mdmlab.TeamFilter,
+		listOpts *mdmlab.MDMAppleCommandQueueListOptions,
+	) ([]*mdmlab.MDMAppleCommandQueueItem, error) {
+		gotOpts = listOpts
+		return []*mdmlab.MDMAppleCommandQueueItem{
+			{
+				HostUUID:    "mac-uuid",
+				Hostname:    "mac-host",
+				CommandUUID: "cmd-uuid",
+				RequestType: "InstallProfile",
+				Status:      mdmlab.MDMAppleCommandQueueStatusNotNow,
+				NotNowCount: 4,
+			},
+		}, nil
+	}
+	ds.ListMDMAppleHostsWithPendingCommandsFunc = func(ctx context.Context, tmFilter mdmlab.TeamFilter, hostUUIDs []string,
+		commandUUID string, lastSeenBefore *time.Time,
+	) ([]*mdmlab.Host, error) {
+		return []*mdmlab.Host{{ID: 1, UUID: "mac-uuid"}}, nil
+	}
+
+	out := runAppForTest(t, []string{"mdm", "commands", "list", "--status", "NotNow", "--type", "InstallProfile"})
+	require.Contains(t, out, "cmd-uuid")
+	require.Contains(t, out, "NotNow")
+	require.Contains(t, out, " 4 | mac-host")
+	require.Equal(t, mdmlab.MDMAppleCommandQueueStatusNotNow, gotOpts.Status)
+	require.Equal(t, "InstallProfile", gotOpts.RequestType)
+
+	_, err := runAppNoChecks([]string{"mdm", "commands", "cancel"})
+	require.ErrorContains(t, err, `Either the "hosts" or the "command-uuid" flag must be set`)
+
+	out = runAppForTest(t, []string{"mdm", "commands", "push", "--stale-for", "24h"})
+	require.Contains(t, out, "Sent a push notification to 1 host(s).")
+}
+
 // sets up the test server with the mock datastore and returns the mock datastore
 func setupTestServer(t *testing.T) *mock.Store {
 	enqueuer := new(mdmmock.MDMAppleStore)
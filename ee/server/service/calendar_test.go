@@ -10,9 +10,10 @@ import (
 
 // Implement mdmlab.Lock interface
 type mockLock struct {
-	AcquireLockFn func(ctx context.Context, key string, value string, expireMs uint64) (ok bool, err error)
-	GetFn         func(ctx context.Context, key string) (*string, error)
-	AddToSetFn    func(ctx context.Context, key string, value string) error
+	AcquireLockFn  func(ctx context.Context, key string, value string, expireMs uint64) (ok bool, err error)
+	GetFn          func(ctx context.Context, key string) (*string, error)
+	GetAndDeleteFn func(ctx context.Context, key string) (*string, error)
+	AddToSetFn     func(ctx context.Context, key string, value string) error
 }
 
 func (m *mockLock) SetIfNotExist(ctx context.Context, key string, value string, expireMs uint64) (ok bool, err error) {
@@ -28,7 +29,7 @@ func (m *mockLock) Get(ctx context.Context, key string) (*string, error) {
 }
 
 func (m *mockLock) GetAndDelete(ctx context.Context, key string) (*string, error) {
-	panic("implement me")
+	return m.GetAndDeleteFn(ctx, key)
 }
 
 func (m *mockLock) AddToSet(ctx context.Context, key string, value string) error {
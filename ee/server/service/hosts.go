@@ -225,7 +225,7 @@ func (svc *Service) UnlockHost(ctx context.Context, hostID uint) (string, error)
 	return svc.enqueueUnlockHostRequest(ctx, host, lockWipe)
 }
 
-func (svc *Service) WipeHost(ctx context.Context, hostID uint) error {
+func (svc *Service) WipeHost(ctx context.Context, hostID uint, opts mdmlab.MDMWipeOptions) error {
 	// First ensure the user has access to list hosts, then check the specific
 	// host once team_id is loaded.
 	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
@@ -287,6 +287,24 @@ func (svc *Service) WipeHost(ctx context.Context, hostID uint) error {
 		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", fmt.Sprintf("Unsupported host platform: %s", host.Platform)))
 	}
 
+	// the wipe type can only be chosen for Windows hosts, which default to
+	// doWipeProtected.
+	var windowsWipeType mdmlab.WindowsWipeType
+	if host.MDMlabPlatform() == "windows" {
+		windowsWipeType = mdmlab.WindowsWipeTypeDoWipeProtected
+	}
+	if opts.Windows != nil && opts.Windows.WipeType != "" {
+		if host.MDMlabPlatform() != "windows" {
+			return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("windows", "The wipe type can only be set for Windows hosts."))
+		}
+		if !opts.Windows.WipeType.IsValid() {
+			return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("windows.wipe_type",
+				fmt.Sprintf("Unsupported wipe type: %s. Supported types are %s, %s and %s.", opts.Windows.WipeType,
+					mdmlab.WindowsWipeTypeDoWipe, mdmlab.WindowsWipeTypeDoWipeProtected, mdmlab.WindowsWipeTypeDoWipePersistProvisionedData)))
+		}
+		windowsWipeType = opts.Windows.WipeType
+	}
+
 	if requireMDM {
 		// the wipe command requires the host to be MDM-enrolled in MDMlab
 		connected, err := svc.ds.IsHostConnectedToMDMlabMDM(ctx, host)
@@ -317,7 +335,7 @@ func (svc *Service) WipeHost(ctx context.Context, hostID uint) error {
 	}
 
 	// all good, go ahead with queuing the wipe request.
-	return svc.enqueueWipeHostRequest(ctx, host, lockWipe)
+	return svc.enqueueWipeHostRequest(ctx, host, lockWipe, windowsWipeType)
 }
 
 func (svc *Service) enqueueLockHostRequest(ctx context.Context, host *mdmlab.Host, lockStatus *mdmlab.HostLockWipeStatus, viewPIN bool) (
@@ -436,7 +454,7 @@ func (svc *Service) enqueueUnlockHostRequest(ctx context.Context, host *mdmlab.H
 	return unlockPIN, nil
 }
 
-func (svc *Service) enqueueWipeHostRequest(ctx context.Context, host *mdmlab.Host, wipeStatus *mdmlab.HostLockWipeStatus) error {
+func (svc *Service) enqueueWipeHostRequest(ctx context.Context, host *mdmlab.Host, wipeStatus *mdmlab.HostLockWipeStatus, windowsWipeType mdmlab.WindowsWipeType) error {
 	vc, ok := viewer.FromContext(ctx)
 	if !ok {
 		return mdmlab.ErrNoContext
@@ -450,12 +468,7 @@ func (svc *Service) enqueueWipeHostRequest(ctx context.Context, host *mdmlab.Hos
 		}
 
 	case "windows":
-		wipeCmdUUID := uuid.NewString()
-		wipeCmd := &mdmlab.MDMWindowsCommand{
-			CommandUUID:  wipeCmdUUID,
-			RawCommand:   []byte(fmt.Sprintf(windowsWipeCommand, wipeCmdUUID)),
-			TargetLocURI: "./Device/Vendor/MSFT/RemoteWipe/doWipeProtected",
-		}
+		wipeCmd := newWindowsExecCommand(uuid.NewString(), windowsWipeType.LocURI(), "chr")
 		if err := svc.ds.WipeHostViaWindowsMDM(ctx, host, wipeCmd); err != nil {
 			return ctxerr.Wrap(ctx, err, "enqueuing wipe request for windows")
 		}
@@ -482,6 +495,7 @@ func (svc *Service) enqueueWipeHostRequest(ctx context.Context, host *mdmlab.Hos
 		mdmlab.ActivityTypeWipedHost{
 			HostID:          host.ID,
 			HostDisplayName: host.DisplayName(),
+			WindowsWipeType: windowsWipeType,
 		},
 	); err != nil {
 		return ctxerr.Wrap(ctx, err, "create activity for wipe host request")
@@ -489,6 +503,392 @@ func (svc *Service) enqueueWipeHostRequest(ctx context.Context, host *mdmlab.Hos
 	return nil
 }
 
+// mdmActionHost loads the host targeted by an MDM device action, and
+// validates that the user is allowed to send MDM commands to it, that MDM is
+// configured for its platform and that it is MDM-enrolled in MDMlab with one
+// of the supported platforms.
+func (svc *Service) mdmActionHost(ctx context.Context, hostID uint, action string, platforms ...string) (*mdmlab.Host, error) {
+	// First ensure the user has access to list hosts, then check the specific
+	// host once team_id is loaded.
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, err
+	}
+	host, err := svc.ds.HostLite(ctx, hostID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get host lite")
+	}
+
+	// Authorize again with team loaded now that we have the host's team_id.
+	if err := svc.authz.Authorize(ctx, mdmlab.MDMCommandAuthz{TeamID: host.TeamID}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	var supported bool
+	for _, p := range platforms {
+		if host.MDMlabPlatform() == p {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", fmt.Sprintf("Unsupported host platform: %s", host.Platform)))
+	}
+
+	// the endpoints of actions supported on multiple platforms only check that
+	// MDM is configured for one of them.
+	if host.MDMlabPlatform() == "windows" {
+		if err := svc.VerifyMDMWindowsConfigured(ctx); err != nil {
+			if errors.Is(err, mdmlab.ErrMDMNotConfigured) {
+				err = mdmlab.NewInvalidArgumentError("host_id", mdmlab.WindowsMDMNotConfiguredMessage).WithStatus(http.StatusBadRequest)
+			}
+			return nil, ctxerr.Wrap(ctx, err, "check windows MDM enabled")
+		}
+	} else {
+		if err := svc.VerifyMDMAppleConfigured(ctx); err != nil {
+			if errors.Is(err, mdmlab.ErrMDMNotConfigured) {
+				err = mdmlab.NewInvalidArgumentError("host_id", mdmlab.AppleMDMNotConfiguredMessage).WithStatus(http.StatusBadRequest)
+			}
+			return nil, ctxerr.Wrap(ctx, err, "check Apple MDM enabled")
+		}
+	}
+
+	connected, err := svc.ds.IsHostConnectedToMDMlabMDM(ctx, host)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "checking if host is connected to MDMlab")
+	}
+	if !connected {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id",
+			fmt.Sprintf("Can't %s the host because it doesn't have MDM turned on.", action)))
+	}
+	return host, nil
+}
+
+func (svc *Service) RestartHost(ctx context.Context, hostID uint, notifyUser bool) error {
+	host, err := svc.mdmActionHost(ctx, hostID, "restart", "darwin", "ios", "ipados", "windows")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if host.MDMlabPlatform() == "windows" {
+		// the Reboot CSP has no option to prompt the user, notifyUser only
+		// applies to macOS.
+		notifyUser = false
+		cmd := newWindowsExecCommand(uuid.NewString(), mdmlab.MDMWindowsRebootLocURI, "null")
+		if err := svc.ds.MDMWindowsInsertCommandForHosts(ctx, []string{host.UUID}, cmd); err != nil {
+			return ctxerr.Wrap(ctx, err, "enqueuing windows restart request")
+		}
+	} else if err := svc.mdmAppleCommander.RestartDevice(ctx, []string{host.UUID}, uuid.NewString(), notifyUser); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing restart request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeRestartedHost{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+		NotifyUser:      notifyUser,
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for restart host request")
+	}
+	return nil
+}
+
+func (svc *Service) ShutDownHost(ctx context.Context, hostID uint) error {
+	host, err := svc.mdmActionHost(ctx, hostID, "shut down", "darwin", "ios", "ipados")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if err := svc.mdmAppleCommander.ShutDownDevice(ctx, []string{host.UUID}, uuid.NewString()); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing shut down request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeShutDownHost{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for shut down host request")
+	}
+	return nil
+}
+
+func (svc *Service) EnableHostLostMode(ctx context.Context, hostID uint, opts mdmlab.MDMAppleLostModeOptions) error {
+	host, err := svc.mdmActionHost(ctx, hostID, "enable lost mode on", "ios", "ipados")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if opts.Message == "" && opts.PhoneNumber == "" {
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("message", "A message or a phone number is required to enable lost mode."))
+	}
+
+	status, err := svc.ds.GetHostLockWipeStatus(ctx, host)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get host lock/wipe status")
+	}
+	switch {
+	case status.IsPendingWipe():
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", "Host has pending wipe request. Cannot enable lost mode once host is wiped."))
+	case status.IsWiped():
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", "Host is wiped. Cannot enable lost mode once host is wiped."))
+	case status.IsPendingLostMode():
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", "Host has pending lost mode request. Lost mode will be enabled when the host comes online."))
+	case status.IsInLostMode() && !status.IsPendingDisableLostMode():
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", "Host is already in lost mode.").WithStatus(http.StatusConflict))
+	}
+
+	if err := svc.mdmAppleCommander.EnableLostMode(ctx, host, uuid.NewString(), opts); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing enable lost mode request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeEnabledLostMode{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for enable lost mode request")
+	}
+	return nil
+}
+
+func (svc *Service) DisableHostLostMode(ctx context.Context, hostID uint) error {
+	host, err := svc.mdmActionHost(ctx, hostID, "disable lost mode on", "ios", "ipados")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	status, err := svc.ds.GetHostLockWipeStatus(ctx, host)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get host lock/wipe status")
+	}
+	switch {
+	case status.IsPendingDisableLostMode():
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", "Host has pending disable lost mode request. Lost mode will be disabled when the host comes online."))
+	case !status.IsInLostMode() && !status.IsPendingLostMode():
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", "Host is not in lost mode.").WithStatus(http.StatusConflict))
+	}
+
+	if err := svc.mdmAppleCommander.DisableLostMode(ctx, host, uuid.NewString()); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing disable lost mode request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeDisabledLostMode{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for disable lost mode request")
+	}
+	return nil
+}
+
+// lostModeHost is like mdmActionHost but additionally requires the host
+// to currently be in lost mode, as needed by the PlayLostModeSound and
+// DeviceLocation commands.
+func (svc *Service) lostModeHost(ctx context.Context, hostID uint, action string) (*mdmlab.Host, error) {
+	host, err := svc.mdmActionHost(ctx, hostID, action, "ios", "ipados")
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := svc.ds.GetHostLockWipeStatus(ctx, host)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get host lock/wipe status")
+	}
+	if !status.IsInLostMode() {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id",
+			fmt.Sprintf("Can't %s the host because it isn't in lost mode.", action)))
+	}
+	return host, nil
+}
+
+func (svc *Service) PlayHostLostModeSound(ctx context.Context, hostID uint) error {
+	host, err := svc.lostModeHost(ctx, hostID, "play a sound on")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if err := svc.mdmAppleCommander.PlayLostModeSound(ctx, []string{host.UUID}, uuid.NewString()); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing play lost mode sound request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypePlayedLostModeSound{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for play lost mode sound request")
+	}
+	return nil
+}
+
+func (svc *Service) RequestHostLocation(ctx context.Context, hostID uint) error {
+	host, err := svc.lostModeHost(ctx, hostID, "locate")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if err := svc.mdmAppleCommander.DeviceLocation(ctx, []string{host.UUID}, uuid.NewString()); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing device location request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeRequestedHostLocation{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for device location request")
+	}
+	return nil
+}
+
+func (svc *Service) SetHostRecoveryLock(ctx context.Context, hostID uint, currentPassword, newPassword string) error {
+	host, err := svc.mdmActionHost(ctx, hostID, "set the recovery lock of", "darwin")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if currentPassword == "" && newPassword == "" {
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("new_password", "The current password is required to clear the recovery lock."))
+	}
+
+	// the password of hosts in teams that enforce the managed recovery lock
+	// is escrowed by MDMlab, changing it manually would lose track of it.
+	rl, err := svc.recoveryLockSettings(ctx, host.TeamID)
+	if err != nil {
+		return err
+	}
+	if rl != nil && rl.Enable {
+		return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id",
+			"Can't set the recovery lock of the host because it's managed by MDMlab. Use the recovery lock password endpoint to reveal it."))
+	}
+
+	if err := svc.mdmAppleCommander.SetRecoveryLock(ctx, []string{host.UUID}, uuid.NewString(), currentPassword, newPassword); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing set recovery lock request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeSetRecoveryLock{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+		Cleared:         newPassword == "",
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for set recovery lock request")
+	}
+	return nil
+}
+
+// recoveryLockSettings returns the managed recovery lock settings of the team,
+// or of hosts with no team if teamID is nil.
+func (svc *Service) recoveryLockSettings(ctx context.Context, teamID *uint) (*mdmlab.MacOSRecoveryLock, error) {
+	if teamID == nil {
+		appCfg, err := svc.ds.AppConfig(ctx)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "get app config")
+		}
+		return appCfg.MDM.MacOSSettings.RecoveryLock, nil
+	}
+	tmConfig, err := svc.ds.TeamMDMConfig(ctx, *teamID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get team mdm config")
+	}
+	return tmConfig.MacOSSettings.RecoveryLock, nil
+}
+
+func (svc *Service) GetHostRecoveryLockPassword(ctx context.Context, hostID uint) (*mdmlab.HostRecoveryLock, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, err
+	}
+	host, err := svc.ds.HostLite(ctx, hostID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get host lite")
+	}
+
+	// Permissions to read the recovery lock password are the same as the ones
+	// required to read the disk encryption key.
+	if err := svc.authz.Authorize(ctx, host, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	if host.MDMlabPlatform() != "darwin" {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_id", fmt.Sprintf("Unsupported host platform: %s", host.Platform)))
+	}
+
+	lock, err := svc.ds.GetHostRecoveryLock(ctx, host.UUID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get host recovery lock")
+	}
+	if lock.Password == "" {
+		return nil, ctxerr.Wrap(ctx, notFoundError{}, "host recovery lock password is not set")
+	}
+
+	// rotate the password shortly after it's revealed, leaving enough time to
+	// use it.
+	if err := svc.ds.SetHostRecoveryLockRevealed(ctx, host.UUID, time.Now().Add(mdmlab.RecoveryLockRevealRotationDelay)); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "set host recovery lock revealed")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeReadHostRecoveryLockPassword{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for read host recovery lock password")
+	}
+	return lock, nil
+}
+
+func (svc *Service) RotateHostBitLockerRecoveryPassword(ctx context.Context, hostID uint) error {
+	host, err := svc.mdmActionHost(ctx, hostID, "rotate the BitLocker recovery password of", "windows")
+	if err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	// The host reports an error status if BitLocker isn't enabled or if the
+	// new recovery password can't be backed up to Entra ID, it is available
+	// in the command's results.
+	cmd := newWindowsExecCommand(uuid.NewString(), mdmlab.MDMWindowsRotateBitLockerLocURI, "chr")
+	if err := svc.ds.MDMWindowsInsertCommandForHosts(ctx, []string{host.UUID}, cmd); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing rotate BitLocker recovery password request")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeRotatedHostBitLockerRecoveryPassword{
+		HostID:          host.ID,
+		HostDisplayName: host.DisplayName(),
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for rotate BitLocker recovery password request")
+	}
+	return nil
+}
+
 var (
 	//go:embed embedded_scripts/windows_lock.ps1
 	windowsLockScript []byte
@@ -501,18 +901,28 @@ var (
 	//go:embed embedded_scripts/linux_wipe.sh
 	linuxWipeScript []byte
 
-	windowsWipeCommand = `
+	windowsExecCommand = `
 		<Exec>
 			<CmdID>%s</CmdID>
 			<Item>
 				<Target>
-					<LocURI>./Device/Vendor/MSFT/RemoteWipe/doWipeProtected</LocURI>
+					<LocURI>%s</LocURI>
 				</Target>
 				<Meta>
-					<Format xmlns="syncml:metinf">chr</Format>
+					<Format xmlns="syncml:metinf">%s</Format>
 					<Type>text/plain</Type>
 				</Meta>
 				<Data></Data>
 			</Item>
 		</Exec>`
 )
+
+// newWindowsExecCommand returns an <Exec> command for the CSP node, with the
+// format expected by the node.
+func newWindowsExecCommand(cmdUUID, locURI, format string) *mdmlab.MDMWindowsCommand {
+	return &mdmlab.MDMWindowsCommand{
+		CommandUUID:  cmdUUID,
+		RawCommand:   []byte(fmt.Sprintf(windowsExecCommand, cmdUUID, locURI, format)),
+		TargetLocURI: locURI,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -25,7 +26,7 @@ func (svc *Service) AddMDMlabMaintainedApp(
 	teamID *uint,
 	appID uint,
 	installScript, preInstallQuery, postInstallScript, uninstallScript string,
-	selfService bool,
+	selfService, automaticInstall bool,
 	labelsIncludeAny, labelsExcludeAny []string,
 ) (titleID uint, err error) {
 	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionWrite); err != nil {
@@ -75,28 +76,36 @@ func (svc *Service) AddMDMlabMaintainedApp(
 	}
 	defer installerTFR.Close()
 
-	extension, err := maintainedapps.ExtensionForBundleIdentifier(app.BundleIdentifier)
+	extension, err := maintainedapps.ExtensionForToken(app.Token)
 	if err != nil {
-		return 0, ctxerr.Errorf(ctx, "getting extension from bundle identifier %q", app.BundleIdentifier)
+		return 0, ctxerr.Errorf(ctx, "getting extension from token %q", app.Token)
 	}
 
-	// Validate the bytes we got are what we expected, if homebrew supports
-	// it, the string "no_check" is a special token used to signal that the
-	// hash shouldn't be checked.
-	if app.SHA256 != noCheckHash {
-		h := sha256.New()
-		_, _ = io.Copy(h, installerTFR) // writes to a Hash can never fail
-		gotHash := hex.EncodeToString(h.Sum(nil))
-
-		if gotHash != app.SHA256 {
-			return 0, ctxerr.New(ctx, "mismatch in maintained app SHA256 hash")
+	var automaticInstallQuery string
+	if automaticInstall {
+		automaticInstallQuery, err = maintainedapps.AutomaticPolicyQuery(app.Token)
+		if err != nil {
+			return 0, ctxerr.Errorf(ctx, "getting automatic policy query from token %q", app.Token)
 		}
-
-		if err := installerTFR.Rewind(); err != nil {
-			return 0, ctxerr.Wrap(ctx, err, "rewind installer reader")
+		if automaticInstallQuery == "" {
+			return 0, mdmlab.NewInvalidArgumentError("automatic_install", "Automatic install isn't supported for this app.")
 		}
 	}
 
+	// Validate the bytes we got are what we expected, if homebrew supports
+	// it, the string "no_check" is a special token used to signal that the
+	// hash shouldn't be checked. The hash of the bytes is the storage ID of
+	// the installer in both cases.
+	h := sha256.New()
+	_, _ = io.Copy(h, installerTFR) // writes to a Hash can never fail
+	gotHash := hex.EncodeToString(h.Sum(nil))
+	if app.SHA256 != noCheckHash && gotHash != app.SHA256 {
+		return 0, ctxerr.New(ctx, "mismatch in maintained app SHA256 hash")
+	}
+	if err := installerTFR.Rewind(); err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "rewind installer reader")
+	}
+
 	// Fall back to the filename if we weren't able to extract a filename from the installer response
 	if filename == "" {
 		filename = app.Name
@@ -118,18 +127,43 @@ func (svc *Service) AddMDMlabMaintainedApp(
 		uninstallScript = app.UninstallScript
 	}
 
+	// software titles of Linux packages are matched on the package name
+	title, source := app.Name, "apps"
+	var packageIDs []string
+	if app.Platform == "linux" {
+		meta, err := file.ExtractInstallerMetadata(installerTFR)
+		switch {
+		case err == nil:
+			title = meta.Name
+			source, err = mdmlab.SofwareInstallerSourceFromExtensionAndName(meta.Extension, meta.Name)
+			if err != nil {
+				return 0, ctxerr.Wrap(ctx, err, "getting source of maintained app package")
+			}
+			packageIDs = meta.PackageIDs
+		case errors.Is(err, file.ErrUnsupportedType):
+			// tarballs are not reported in the software inventory
+			source = "tgz_packages"
+		default:
+			return 0, ctxerr.Wrap(ctx, err, "extracting maintained app package metadata")
+		}
+		if err := installerTFR.Rewind(); err != nil {
+			return 0, ctxerr.Wrap(ctx, err, "rewind installer reader")
+		}
+	}
+
 	payload := &mdmlab.UploadSoftwareInstallerPayload{
 		InstallerFile:     installerTFR,
-		Title:             app.Name,
+		Title:             title,
 		UserID:            vc.UserID(),
 		TeamID:            teamID,
 		Version:           app.Version,
 		Filename:          filename,
-		Platform:          string(app.Platform),
-		Source:            "apps",
+		Platform:          app.Platform,
+		Source:            source,
 		Extension:         extension,
+		PackageIDs:        packageIDs,
 		BundleIdentifier:  app.BundleIdentifier,
-		StorageID:         app.SHA256,
+		StorageID:         gotHash,
 		MDMlabLibraryAppID: &app.ID,
 		PreInstallQuery:   preInstallQuery,
 		PostInstallScript: postInstallScript,
@@ -137,6 +171,13 @@ func (svc *Service) AddMDMlabMaintainedApp(
 		InstallScript:     installScript,
 		UninstallScript:   uninstallScript,
 		ValidatedLabels:   validatedLabels,
+		AutomaticInstall:  automaticInstall,
+		// the automatic install policy uses the detection query of the app
+		AutomaticInstallQuery: automaticInstallQuery,
+	}
+
+	if err := svc.verifySoftwarePayloadSignature(ctx, payload); err != nil {
+		return 0, err
 	}
 
 	// Create record in software installers table
@@ -20,6 +20,7 @@ type Service struct {
 	ds                    mdmlab.Datastore
 	logger                kitlog.Logger
 	config                config.MDMlabConfig
+	mailService           mdmlab.MailService
 	clock                 clock.Clock
 	authz                 *authz.Authorizer
 	depStorage            storage.AllDEPStorage
@@ -59,6 +60,7 @@ func NewService(
 		ds:                    ds,
 		logger:                logger,
 		config:                config,
+		mailService:           mailService,
 		clock:                 c,
 		authz:                 authorizer,
 		depStorage:            depStorage,
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+func (svc *Service) ListSoftwareBlocklistRules(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareBlocklistRule, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	rules, err := svc.ds.ListSoftwareBlocklistRules(ctx, teamID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software blocklist rules")
+	}
+	return rules, nil
+}
+
+func (svc *Service) GetSoftwareBlocklistRule(ctx context.Context, id uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	rule, err := svc.authorizeSoftwareBlocklistRule(ctx, id, mdmlab.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.HostCounts, err = svc.ds.GetSoftwareBlocklistRuleHostCounts(ctx, rule)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software blocklist rule host counts")
+	}
+	return rule, nil
+}
+
+func (svc *Service) CreateSoftwareBlocklistRule(ctx context.Context, payload *mdmlab.SoftwareBlocklistRulePayload) (*mdmlab.SoftwareBlocklistRule, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: payload.TeamID}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	labelIDs, err := svc.validateSoftwareBlocklistRulePayload(ctx, payload.TeamID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := svc.ds.NewSoftwareBlocklistRule(ctx, &mdmlab.SoftwareBlocklistRule{
+		TeamID:           payload.TeamID,
+		Name:             payload.Name,
+		BundleIdentifier: payload.BundleIdentifier,
+		Source:           payload.Source,
+		MinVersion:       payload.MinVersion,
+		MaxVersion:       payload.MaxVersion,
+		ScriptID:         payload.ScriptID,
+	}, labelIDs)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create software blocklist rule")
+	}
+
+	details, err := svc.softwareBlocklistRuleActivityDetails(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeCreatedSoftwareBlocklistRule{ActivitySoftwareBlocklistRule: details}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "creating activity for created software blocklist rule")
+	}
+	return rule, nil
+}
+
+func (svc *Service) ModifySoftwareBlocklistRule(ctx context.Context, id uint, payload *mdmlab.SoftwareBlocklistRulePayload) (*mdmlab.SoftwareBlocklistRule, error) {
+	rule, err := svc.authorizeSoftwareBlocklistRule(ctx, id, mdmlab.ActionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	// the team of a rule can't be changed.
+	labelIDs, err := svc.validateSoftwareBlocklistRulePayload(ctx, rule.TeamID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Name = payload.Name
+	rule.BundleIdentifier = payload.BundleIdentifier
+	rule.Source = payload.Source
+	rule.MinVersion = payload.MinVersion
+	rule.MaxVersion = payload.MaxVersion
+	rule.ScriptID = payload.ScriptID
+	rule, err = svc.ds.UpdateSoftwareBlocklistRule(ctx, rule, labelIDs)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "update software blocklist rule")
+	}
+
+	details, err := svc.softwareBlocklistRuleActivityDetails(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeEditedSoftwareBlocklistRule{ActivitySoftwareBlocklistRule: details}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "creating activity for edited software blocklist rule")
+	}
+	return rule, nil
+}
+
+func (svc *Service) DeleteSoftwareBlocklistRule(ctx context.Context, id uint) error {
+	rule, err := svc.authorizeSoftwareBlocklistRule(ctx, id, mdmlab.ActionWrite)
+	if err != nil {
+		return err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if err := svc.ds.DeleteSoftwareBlocklistRule(ctx, rule.ID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software blocklist rule")
+	}
+
+	details, err := svc.softwareBlocklistRuleActivityDetails(ctx, rule)
+	if err != nil {
+		return err
+	}
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeDeletedSoftwareBlocklistRule{ActivitySoftwareBlocklistRule: details}); err != nil {
+		return ctxerr.Wrap(ctx, err, "creating activity for deleted software blocklist rule")
+	}
+	return nil
+}
+
+func (svc *Service) ListSoftwareBlocklistRuleHosts(ctx context.Context, id uint) ([]*mdmlab.SoftwareBlocklistHost, error) {
+	rule, err := svc.authorizeSoftwareBlocklistRule(ctx, id, mdmlab.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, err := svc.ds.ListSoftwareBlocklistRuleHosts(ctx, rule)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software blocklist rule hosts")
+	}
+	return hosts, nil
+}
+
+// authorizeSoftwareBlocklistRule authorizes the action on the software of the
+// team of the rule and returns the rule.
+func (svc *Service) authorizeSoftwareBlocklistRule(ctx context.Context, id uint, action string) (*mdmlab.SoftwareBlocklistRule, error) {
+	// first ensure the user has access to list software, then check the
+	// specific team of the rule.
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	rule, err := svc.ds.GetSoftwareBlocklistRule(ctx, id)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software blocklist rule")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: rule.TeamID}, action); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// validateSoftwareBlocklistRulePayload validates the payload of a rule of the
+// team and returns the IDs of the excepted labels.
+func (svc *Service) validateSoftwareBlocklistRulePayload(ctx context.Context, teamID *uint, payload *mdmlab.SoftwareBlocklistRulePayload) ([]uint, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "validate software blocklist rule")
+	}
+
+	if payload.ScriptID != nil {
+		script, err := svc.ds.Script(ctx, *payload.ScriptID)
+		if err != nil {
+			if mdmlab.IsNotFound(err) {
+				return nil, mdmlab.NewInvalidArgumentError("script_id", "script does not exist")
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get software blocklist rule script")
+		}
+		var scriptTeamID, ruleTeamID uint
+		if script.TeamID != nil {
+			scriptTeamID = *script.TeamID
+		}
+		if teamID != nil {
+			ruleTeamID = *teamID
+		}
+		if scriptTeamID != ruleTeamID {
+			return nil, mdmlab.NewInvalidArgumentError("script_id", "script must belong to the team of the rule")
+		}
+	}
+
+	validatedLabels, err := ValidateSoftwareLabels(ctx, svc, nil, payload.LabelsExcludeAny)
+	if err != nil {
+		return nil, err
+	}
+	labelIDs := make([]uint, 0, len(validatedLabels.ByName))
+	for _, lbl := range validatedLabels.ByName {
+		labelIDs = append(labelIDs, lbl.LabelID)
+	}
+	return labelIDs, nil
+}
+
+func (svc *Service) softwareBlocklistRuleActivityDetails(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) (mdmlab.ActivitySoftwareBlocklistRule, error) {
+	var teamName *string
+	if rule.TeamID != nil && *rule.TeamID != 0 {
+		team, err := svc.ds.Team(ctx, *rule.TeamID)
+		if err != nil {
+			return mdmlab.ActivitySoftwareBlocklistRule{}, ctxerr.Wrap(ctx, err, "get team of software blocklist rule")
+		}
+		teamName = &team.Name
+	}
+	return rule.ActivityDetails(teamName), nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+)
+
+func (svc *Service) GetSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	if teamID == nil {
+		svc.authz.SkipAuthorization(ctx)
+		return nil, mdmlab.NewInvalidArgumentError("team_id", "is required")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	rollout, err := svc.getSoftwareInstallerRollout(ctx, titleID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := svc.ds.GetSoftwareInstallerRolloutStats(ctx, rollout.SoftwareInstallerID, rollout.StartedAt)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software installer rollout stats")
+	}
+	rollout.Stats = stats
+	return rollout, nil
+}
+
+func (svc *Service) SetSoftwareInstallerRollout(ctx context.Context, payload *mdmlab.SoftwareInstallerRolloutPayload) (*mdmlab.SoftwareInstallerRollout, error) {
+	if payload.TeamID == nil {
+		svc.authz.SkipAuthorization(ctx)
+		return nil, mdmlab.NewInvalidArgumentError("team_id", "is required")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: payload.TeamID}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	filter := mdmlab.TeamFilter{User: vc.User, IncludeObserver: true}
+	for i, stage := range payload.Stages {
+		if stage.LabelID == nil {
+			continue
+		}
+		if _, _, err := svc.ds.Label(ctx, *stage.LabelID, filter); err != nil {
+			if mdmlab.IsNotFound(err) {
+				return nil, mdmlab.NewInvalidArgumentError("stages", fmt.Sprintf("stage %d: label %d does not exist", i+1, *stage.LabelID))
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get rollout stage label")
+		}
+	}
+
+	meta, err := svc.ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, payload.TeamID, payload.TitleID, false)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "getting software installer metadata")
+	}
+
+	rollout := &mdmlab.SoftwareInstallerRollout{
+		SoftwareInstallerID:     meta.InstallerID,
+		Version:                 meta.Version,
+		Stages:                  payload.Stages,
+		SuccessThresholdPercent: mdmlab.DefaultSoftwareInstallerRolloutSuccessThreshold,
+		MaxFailedInstalls:       mdmlab.DefaultSoftwareInstallerRolloutMaxFailedInstalls,
+	}
+	if payload.SuccessThresholdPercent != nil {
+		rollout.SuccessThresholdPercent = *payload.SuccessThresholdPercent
+	}
+	if payload.MaxFailedInstalls != nil {
+		rollout.MaxFailedInstalls = *payload.MaxFailedInstalls
+	}
+	rollout, err = svc.ds.SetSoftwareInstallerRollout(ctx, rollout)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "set software installer rollout")
+	}
+
+	details, err := svc.softwareInstallerRolloutActivityDetails(ctx, rollout)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeStartedSoftwareRollout{ActivitySoftwareRollout: details}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "creating activity for started software rollout")
+	}
+	return rollout, nil
+}
+
+func (svc *Service) PauseSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	return svc.updateSoftwareInstallerRolloutStatus(ctx, titleID, teamID, mdmlab.SoftwareInstallerRolloutPaused)
+}
+
+func (svc *Service) ResumeSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	return svc.updateSoftwareInstallerRolloutStatus(ctx, titleID, teamID, mdmlab.SoftwareInstallerRolloutInProgress)
+}
+
+func (svc *Service) updateSoftwareInstallerRolloutStatus(
+	ctx context.Context,
+	titleID uint,
+	teamID *uint,
+	status mdmlab.SoftwareInstallerRolloutStatus,
+) (*mdmlab.SoftwareInstallerRollout, error) {
+	if teamID == nil {
+		svc.authz.SkipAuthorization(ctx)
+		return nil, mdmlab.NewInvalidArgumentError("team_id", "is required")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	rollout, err := svc.getSoftwareInstallerRollout(ctx, titleID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	var activity func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails
+	switch status {
+	case mdmlab.SoftwareInstallerRolloutPaused:
+		if rollout.Status != mdmlab.SoftwareInstallerRolloutInProgress {
+			return nil, mdmlab.NewInvalidArgumentError("status", fmt.Sprintf("cannot pause a rollout that is %s", rollout.Status))
+		}
+		rollout.StatusReason = nil
+		activity = func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails {
+			return mdmlab.ActivityTypePausedSoftwareRollout{ActivitySoftwareRollout: details}
+		}
+
+	case mdmlab.SoftwareInstallerRolloutInProgress:
+		if rollout.Status != mdmlab.SoftwareInstallerRolloutPaused {
+			return nil, mdmlab.NewInvalidArgumentError("status", fmt.Sprintf("cannot resume a rollout that is %s", rollout.Status))
+		}
+		// the failed installs are counted again from now on, otherwise the
+		// rollout would be paused again right away.
+		now := time.Now().UTC()
+		rollout.StatusReason = nil
+		rollout.StageStartedAt = now
+		rollout.StartedAt = now
+		activity = func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails {
+			return mdmlab.ActivityTypeResumedSoftwareRollout{ActivitySoftwareRollout: details}
+		}
+	}
+	rollout.Status = status
+
+	if err := svc.ds.UpdateSoftwareInstallerRollout(ctx, rollout); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "update software installer rollout")
+	}
+
+	details, err := svc.softwareInstallerRolloutActivityDetails(ctx, rollout)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.NewActivity(ctx, vc.User, activity(details)); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "creating activity for software rollout status change")
+	}
+	return rollout, nil
+}
+
+func (svc *Service) DeleteSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) error {
+	if teamID == nil {
+		svc.authz.SkipAuthorization(ctx)
+		return mdmlab.NewInvalidArgumentError("team_id", "is required")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionWrite); err != nil {
+		return err
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	rollout, err := svc.getSoftwareInstallerRollout(ctx, titleID, teamID)
+	if err != nil {
+		return err
+	}
+	if err := svc.ds.DeleteSoftwareInstallerRollout(ctx, rollout.SoftwareInstallerID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software installer rollout")
+	}
+
+	details, err := svc.softwareInstallerRolloutActivityDetails(ctx, rollout)
+	if err != nil {
+		return err
+	}
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeCanceledSoftwareRollout{ActivitySoftwareRollout: details}); err != nil {
+		return ctxerr.Wrap(ctx, err, "creating activity for canceled software rollout")
+	}
+	return nil
+}
+
+func (svc *Service) getSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	meta, err := svc.ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, teamID, titleID, false)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "getting software installer metadata")
+	}
+	rollout, err := svc.ds.GetSoftwareInstallerRollout(ctx, meta.InstallerID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software installer rollout")
+	}
+	return rollout, nil
+}
+
+func (svc *Service) softwareInstallerRolloutActivityDetails(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) (mdmlab.ActivitySoftwareRollout, error) {
+	var teamName *string
+	if rollout.TeamID != nil && *rollout.TeamID != 0 {
+		team, err := svc.ds.Team(ctx, *rollout.TeamID)
+		if err != nil {
+			return mdmlab.ActivitySoftwareRollout{}, ctxerr.Wrap(ctx, err, "get team of software installer rollout")
+		}
+		teamName = ptr.String(team.Name)
+	}
+	return rollout.ActivityDetails(teamName), nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+)
+
+func (svc *Service) ListSoftwareInstallerVersions(ctx context.Context, titleID uint, teamID *uint) ([]*mdmlab.SoftwareInstallerVersion, error) {
+	if teamID == nil {
+		svc.authz.SkipAuthorization(ctx)
+		return nil, mdmlab.NewInvalidArgumentError("team_id", "is required")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	meta, err := svc.ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, teamID, titleID, false)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "getting software installer metadata")
+	}
+	versions, err := svc.ds.ListSoftwareInstallerVersions(ctx, meta.InstallerID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software installer versions")
+	}
+	return versions, nil
+}
+
+func (svc *Service) PinSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error {
+	meta, version, err := svc.authorizeSoftwareInstallerVersion(ctx, titleID, teamID, &versionID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.PinSoftwareInstallerVersion(ctx, meta.InstallerID, &version.ID); err != nil {
+		return ctxerr.Wrap(ctx, err, "pin software installer version")
+	}
+
+	details, err := svc.softwareInstallerVersionActivityDetails(ctx, meta, version)
+	if err != nil {
+		return err
+	}
+	return svc.newSoftwareInstallerVersionActivity(ctx, mdmlab.ActivityTypePinnedSoftwareVersion{ActivitySoftwareVersion: details})
+}
+
+func (svc *Service) UnpinSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint) error {
+	meta, _, err := svc.authorizeSoftwareInstallerVersion(ctx, titleID, teamID, nil)
+	if err != nil {
+		return err
+	}
+
+	versions, err := svc.ds.ListSoftwareInstallerVersions(ctx, meta.InstallerID)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list software installer versions")
+	}
+	var pinned *mdmlab.SoftwareInstallerVersion
+	for _, v := range versions {
+		if v.Pinned {
+			pinned = v
+			break
+		}
+	}
+	if pinned == nil {
+		// nothing to do
+		return nil
+	}
+
+	if err := svc.ds.PinSoftwareInstallerVersion(ctx, meta.InstallerID, nil); err != nil {
+		return ctxerr.Wrap(ctx, err, "unpin software installer version")
+	}
+
+	details, err := svc.softwareInstallerVersionActivityDetails(ctx, meta, pinned)
+	if err != nil {
+		return err
+	}
+	return svc.newSoftwareInstallerVersionActivity(ctx, mdmlab.ActivityTypeUnpinnedSoftwareVersion{ActivitySoftwareVersion: details})
+}
+
+func (svc *Service) RollbackSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error {
+	meta, version, err := svc.authorizeSoftwareInstallerVersion(ctx, titleID, teamID, &versionID)
+	if err != nil {
+		return err
+	}
+	if version.Current {
+		return mdmlab.NewInvalidArgumentError("version_id", "is already the current version of the software")
+	}
+
+	if err := svc.ds.RollbackSoftwareInstallerVersion(ctx, meta.InstallerID, version.ID); err != nil {
+		return ctxerr.Wrap(ctx, err, "rollback software installer version")
+	}
+
+	details, err := svc.softwareInstallerVersionActivityDetails(ctx, meta, version)
+	if err != nil {
+		return err
+	}
+	return svc.newSoftwareInstallerVersionActivity(ctx, mdmlab.ActivityTypeRolledBackSoftwareVersion{ActivitySoftwareVersion: details})
+}
+
+func (svc *Service) SetSoftwareInstallerVersionLabels(ctx context.Context, titleID uint, teamID *uint, versionID uint, labelsIncludeAny []string) (*mdmlab.SoftwareInstallerVersion, error) {
+	meta, version, err := svc.authorizeSoftwareInstallerVersion(ctx, titleID, teamID, &versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	validatedLabels, err := ValidateSoftwareLabels(ctx, svc, labelsIncludeAny, nil)
+	if err != nil {
+		return nil, err
+	}
+	labelIDs := make([]uint, 0, len(validatedLabels.ByName))
+	activityLabels := make([]mdmlab.ActivitySoftwareLabel, 0, len(validatedLabels.ByName))
+	for _, lbl := range validatedLabels.ByName {
+		labelIDs = append(labelIDs, lbl.LabelID)
+		activityLabels = append(activityLabels, mdmlab.ActivitySoftwareLabel{Name: lbl.LabelName, ID: lbl.LabelID})
+	}
+
+	if err := svc.ds.SetSoftwareInstallerVersionLabels(ctx, meta.InstallerID, version.ID, labelIDs); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "set software installer version labels")
+	}
+
+	details, err := svc.softwareInstallerVersionActivityDetails(ctx, meta, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.newSoftwareInstallerVersionActivity(ctx, mdmlab.ActivityTypeEditedSoftwareVersionLabels{
+		ActivitySoftwareVersion: details,
+		LabelsIncludeAny:        activityLabels,
+	}); err != nil {
+		return nil, err
+	}
+
+	version, err = svc.ds.GetSoftwareInstallerVersion(ctx, meta.InstallerID, version.ID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software installer version")
+	}
+	return version, nil
+}
+
+func (svc *Service) DeleteSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error {
+	meta, version, err := svc.authorizeSoftwareInstallerVersion(ctx, titleID, teamID, &versionID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeleteSoftwareInstallerVersion(ctx, meta.InstallerID, version.ID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software installer version")
+	}
+
+	details, err := svc.softwareInstallerVersionActivityDetails(ctx, meta, version)
+	if err != nil {
+		return err
+	}
+	return svc.newSoftwareInstallerVersionActivity(ctx, mdmlab.ActivityTypeDeletedSoftwareVersion{ActivitySoftwareVersion: details})
+}
+
+// authorizeSoftwareInstallerVersion authorizes the modification of the
+// versions of the software installer of the title and team, and returns the
+// installer and the version if versionID is set.
+func (svc *Service) authorizeSoftwareInstallerVersion(
+	ctx context.Context,
+	titleID uint,
+	teamID *uint,
+	versionID *uint,
+) (*mdmlab.SoftwareInstaller, *mdmlab.SoftwareInstallerVersion, error) {
+	if teamID == nil {
+		svc.authz.SkipAuthorization(ctx)
+		return nil, nil, mdmlab.NewInvalidArgumentError("team_id", "is required")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionWrite); err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := svc.ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, teamID, titleID, false)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "getting software installer metadata")
+	}
+	if versionID == nil {
+		return meta, nil, nil
+	}
+
+	version, err := svc.ds.GetSoftwareInstallerVersion(ctx, meta.InstallerID, *versionID)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "get software installer version")
+	}
+	return meta, version, nil
+}
+
+func (svc *Service) softwareInstallerVersionActivityDetails(
+	ctx context.Context,
+	meta *mdmlab.SoftwareInstaller,
+	version *mdmlab.SoftwareInstallerVersion,
+) (mdmlab.ActivitySoftwareVersion, error) {
+	var teamName *string
+	if meta.TeamID != nil && *meta.TeamID != 0 {
+		team, err := svc.ds.Team(ctx, *meta.TeamID)
+		if err != nil {
+			return mdmlab.ActivitySoftwareVersion{}, ctxerr.Wrap(ctx, err, "get team of software installer")
+		}
+		teamName = ptr.String(team.Name)
+	}
+	return version.ActivityDetails(meta, teamName), nil
+}
+
+func (svc *Service) newSoftwareInstallerVersionActivity(ctx context.Context, activity mdmlab.ActivityDetails) error {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+	if err := svc.NewActivity(ctx, vc.User, activity); err != nil {
+		return ctxerr.Wrapf(ctx, err, "creating activity %s", activity.ActivityName())
+	}
+	return nil
+}
@@ -201,6 +201,18 @@ func preProcessUninstallScript(payload *mdmlab.UploadSoftwareInstallerPayload) {
 	payload.UninstallScript = packageIDRegex.ReplaceAllString(payload.UninstallScript, fmt.Sprintf("%s${suffix}", packageID))
 }
 
+// defaultInstallScript returns the default install script of the extension.
+// The package ID is replaced in the AppImage install script, AppImages are
+// installed under their package ID so the default uninstall script can find
+// them.
+func defaultInstallScript(extension string, packageIDs []string) string {
+	script := file.GetInstallScript(extension)
+	if extension == "appimage" && len(packageIDs) > 0 {
+		script = packageIDRegex.ReplaceAllString(script, fmt.Sprintf("\"%s\"${suffix}", packageIDs[0]))
+	}
+	return script
+}
+
 func (svc *Service) UpdateSoftwareInstaller(ctx context.Context, payload *mdmlab.UpdateSoftwareInstallerPayload) (*mdmlab.SoftwareInstaller, error) {
 	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: payload.TeamID}, mdmlab.ActionWrite); err != nil {
 		return nil, err
@@ -345,6 +357,7 @@ func (svc *Service) UpdateSoftwareInstaller(ctx context.Context, payload *mdmlab
 			payload.Filename = payloadForNewInstallerFile.Filename
 			payload.Version = payloadForNewInstallerFile.Version
 			payload.PackageIDs = payloadForNewInstallerFile.PackageIDs
+			payload.Signature = payloadForNewInstallerFile.Signature
 
 			dirty["Package"] = true
 		} else { // noop if uploaded installer is identical to previous installer
@@ -358,6 +371,7 @@ func (svc *Service) UpdateSoftwareInstaller(ctx context.Context, payload *mdmlab
 		payload.Filename = existingInstaller.Name
 		payload.Version = existingInstaller.Version
 		payload.PackageIDs = existingInstaller.PackageIDs()
+		payload.Signature = existingInstaller.SoftwareInstallerSignature
 	}
 
 	// default pre-install query is blank, so blanking out the query doesn't have a semantic meaning we have to take care of
@@ -368,7 +382,11 @@ func (svc *Service) UpdateSoftwareInstaller(ctx context.Context, payload *mdmlab
 	if payload.InstallScript != nil {
 		installScript := file.Dos2UnixNewlines(*payload.InstallScript)
 		if installScript == "" {
-			installScript = file.GetInstallScript(existingInstaller.Extension)
+			packageIDs := existingInstaller.PackageIDs()
+			if payloadForNewInstallerFile != nil {
+				packageIDs = payloadForNewInstallerFile.PackageIDs
+			}
+			installScript = defaultInstallScript(existingInstaller.Extension, packageIDs)
 		}
 
 		if installScript != existingInstaller.InstallScript {
@@ -817,13 +835,111 @@ func (svc *Service) GetSoftwareInstallDetails(ctx context.Context, installUUID s
 		}
 	}
 
+	host, ok := hostctx.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.OrbitError{Message: "internal error: missing host from request context"}
+	}
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get app config")
+	}
+	if appConfig.SoftwareInstallerSettings.CacheURLForIP(host.PrimaryIP) != "" {
+		token, err := svc.generateSoftwareInstallerCacheToken(ctx, details)
+		if err != nil {
+			// orbit downloads the installer from the server without a token.
+			level.Error(svc.logger).Log("msg", "error generating software installer cache token", "err", err)
+		} else {
+			details.SoftwareInstallerCacheToken = token
+		}
+	}
+
 	return details, nil
 }
 
+const softwareInstallerCacheTokenExpirationMs = 10 * 60 * 1000 // 10 minutes
+
+func softwareInstallerCacheTokenKey(token string) string {
+	return fmt.Sprintf("software_installer_cache_token:%s", token)
+}
+
+// generateSoftwareInstallerCacheToken returns the token sent by the host to
+// the software installer cache instead of its node key. It only authorizes
+// the download of the installer of this install, for a short time.
+func (svc *Service) generateSoftwareInstallerCacheToken(ctx context.Context, details *mdmlab.SoftwareInstallDetails) (string, error) {
+	storageID, filename, err := svc.getHostSoftwareInstallerFile(ctx, details.InstallerID)
+	if err != nil {
+		return "", err
+	}
+	metaByte, err := json.Marshal(mdmlab.SoftwareInstallerCacheTokenMetadata{
+		InstallUUID:     details.ExecutionID,
+		InstallerID:     details.InstallerID,
+		InstallerSHA256: details.InstallerSHA256,
+		StorageID:       storageID,
+		Filename:        filename,
+	})
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "marshaling software installer cache token metadata")
+	}
+
+	token := uuid.NewString()
+	ok, err := svc.distributedLock.SetIfNotExist(ctx, softwareInstallerCacheTokenKey(token), string(metaByte),
+		softwareInstallerCacheTokenExpirationMs)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "saving software installer cache token")
+	}
+	if !ok {
+		// Should not happen since token is unique
+		return "", ctxerr.Errorf(ctx, "failed to save software installer cache token")
+	}
+	return token, nil
+}
+
+func (svc *Service) GetSoftwareInstallerCacheTokenMetadata(ctx context.Context, token string) (*mdmlab.SoftwareInstallerCacheTokenMetadata, error) {
+	// We will manually authorize this endpoint based on the token.
+	svc.authz.SkipAuthorization(ctx)
+
+	return svc.softwareInstallerCacheTokenMetadata(ctx, token, svc.distributedLock.Get)
+}
+
+func (svc *Service) DownloadSoftwareInstallerWithCacheToken(ctx context.Context, token string) (*mdmlab.DownloadSoftwareInstallerPayload, error) {
+	// We will manually authorize this endpoint based on the token.
+	svc.authz.SkipAuthorization(ctx)
+
+	// the cache downloads the installer once, the token can't be reused to
+	// download it again.
+	meta, err := svc.softwareInstallerCacheTokenMetadata(ctx, token, svc.distributedLock.GetAndDelete)
+	if err != nil {
+		return nil, err
+	}
+	return svc.getSoftwareInstallerBinary(ctx, meta.StorageID, meta.Filename)
+}
+
+func (svc *Service) softwareInstallerCacheTokenMetadata(ctx context.Context, token string,
+	get func(ctx context.Context, key string) (*string, error),
+) (*mdmlab.SoftwareInstallerCacheTokenMetadata, error) {
+	if token == "" || len(token) > softwareInstallerTokenMaxLength {
+		return nil, mdmlab.NewPermissionError("invalid token")
+	}
+
+	metaStr, err := get(ctx, softwareInstallerCacheTokenKey(token))
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "getting software installer cache token metadata")
+	}
+	if metaStr == nil {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewPermissionError("invalid token"))
+	}
+
+	var meta mdmlab.SoftwareInstallerCacheTokenMetadata
+	if err := json.Unmarshal([]byte(*metaStr), &meta); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "unmarshaling software installer cache token metadata")
+	}
+	return &meta, nil
+}
+
 func (svc *Service) getSoftwareInstallURL(ctx context.Context, installerID uint) (*mdmlab.SoftwareInstallerURL, error) {
-	meta, err := svc.validateAndGetSoftwareInstallerMetadata(ctx, installerID)
+	storageID, filename, err := svc.getHostSoftwareInstallerFile(ctx, installerID)
 	if err != nil {
-		return nil, ctxerr.Wrap(ctx, err, "validating software installer metadata for download")
+		return nil, err
 	}
 
 	// Note: we could check if the installer exists in the S3 store.
@@ -833,13 +949,13 @@ func (svc *Service) getSoftwareInstallURL(ctx context.Context, installerID uint)
 	// If CloudFront is misconfigured, the server and Orbit clients will experience a greater load since they'll be doing throw-away work.
 
 	// Get the signed URL
-	signedURL, err := svc.softwareInstallStore.Sign(ctx, meta.StorageID)
+	signedURL, err := svc.softwareInstallStore.Sign(ctx, storageID)
 	if err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "signing software installer URL")
 	}
 	return &mdmlab.SoftwareInstallerURL{
 		URL:      signedURL,
-		Filename: meta.Name,
+		Filename: filename,
 	}, nil
 }
 
@@ -847,17 +963,39 @@ func (svc *Service) OrbitDownloadSoftwareInstaller(ctx context.Context, installe
 	// this is not a user-authenticated endpoint
 	svc.authz.SkipAuthorization(ctx)
 
-	meta, err := svc.validateAndGetSoftwareInstallerMetadata(ctx, installerID)
-	if err != nil {
-		return nil, ctxerr.Wrap(ctx, err, "validating software installer metadata for download")
-	}
-
 	// Note that we do allow downloading an installer that is on a different team
 	// than the host's team, because the install request might have come while
 	// the host was on that team, and then the host got moved to a different team
 	// but the request is still pending execution.
+	storageID, filename, err := svc.getHostSoftwareInstallerFile(ctx, installerID)
+	if err != nil {
+		return nil, err
+	}
 
-	return svc.getSoftwareInstallerBinary(ctx, meta.StorageID, meta.Name)
+	return svc.getSoftwareInstallerBinary(ctx, storageID, filename)
+}
+
+// getHostSoftwareInstallerFile returns the storage ID and filename of the
+// installer the host must download for its pending install, which may be a
+// pinned or label-targeted version instead of the current one.
+func (svc *Service) getHostSoftwareInstallerFile(ctx context.Context, installerID uint) (storageID, filename string, err error) {
+	meta, err := svc.validateAndGetSoftwareInstallerMetadata(ctx, installerID)
+	if err != nil {
+		return "", "", ctxerr.Wrap(ctx, err, "validating software installer metadata for download")
+	}
+
+	host, ok := hostctx.FromContext(ctx)
+	if !ok {
+		return "", "", mdmlab.OrbitError{Message: "internal error: missing host from request context"}
+	}
+	version, err := svc.ds.GetPendingSoftwareInstallerVersion(ctx, host.ID, installerID)
+	if err != nil {
+		return "", "", ctxerr.Wrap(ctx, err, "get pending software installer version")
+	}
+	if version != nil {
+		return version.StorageID, version.Filename, nil
+	}
+	return meta.StorageID, meta.Name, nil
 }
 
 func (svc *Service) validateAndGetSoftwareInstallerMetadata(ctx context.Context, installerID uint) (*mdmlab.SoftwareInstaller, error) {
@@ -1137,6 +1275,17 @@ func (svc *Service) InstallVPPAppPostValidation(ctx context.Context, host *mdmla
 }
 
 func (svc *Service) installSoftwareTitleUsingInstaller(ctx context.Context, host *mdmlab.Host, installer *mdmlab.SoftwareInstaller) error {
+	if err := checkSoftwareInstallerPlatform(ctx, host, installer); err != nil {
+		return err
+	}
+
+	_, err := svc.ds.InsertSoftwareInstallRequest(ctx, host.ID, installer.InstallerID, false, nil)
+	return ctxerr.Wrap(ctx, err, "inserting software install request")
+}
+
+// checkSoftwareInstallerPlatform checks that the installer can be installed
+// on the host.
+func checkSoftwareInstallerPlatform(ctx context.Context, host *mdmlab.Host, installer *mdmlab.SoftwareInstaller) error {
 	ext := filepath.Ext(installer.Name)
 	requiredPlatform := packageExtensionToPlatform(ext)
 	if requiredPlatform == "" {
@@ -1153,9 +1302,7 @@ func (svc *Service) installSoftwareTitleUsingInstaller(ctx context.Context, host
 			),
 		}
 	}
-
-	_, err := svc.ds.InsertSoftwareInstallRequest(ctx, host.ID, installer.InstallerID, false, nil)
-	return ctxerr.Wrap(ctx, err, "inserting software install request")
+	return nil
 }
 
 func (svc *Service) UninstallSoftwareTitle(ctx context.Context, hostID uint, softwareTitleID uint) error {
@@ -1367,7 +1514,7 @@ func (svc *Service) addMetadataToSoftwarePayload(ctx context.Context, payload *m
 	if err != nil {
 		if errors.Is(err, file.ErrUnsupportedType) {
 			return "", &mdmlab.BadRequestError{
-				Message:     "Couldn't edit software. File type not supported. The file should be .pkg, .msi, .exe, .deb or .rpm.",
+				Message:     "Couldn't edit software. File type not supported. The file should be .pkg, .msi, .exe, .deb, .rpm, .snap, .snapref, .flatpak, .flatpakref or .AppImage.",
 				InternalErr: ctxerr.Wrap(ctx, err, "extracting metadata from installer"),
 			}
 		}
@@ -1397,8 +1544,12 @@ func (svc *Service) addMetadataToSoftwarePayload(ctx context.Context, payload *m
 		return "", ctxerr.Wrap(ctx, err, "resetting installer file reader")
 	}
 
+	if err := svc.verifySoftwarePayloadSignature(ctx, payload); err != nil {
+		return "", err
+	}
+
 	if payload.InstallScript == "" {
-		payload.InstallScript = file.GetInstallScript(meta.Extension)
+		payload.InstallScript = defaultInstallScript(meta.Extension, meta.PackageIDs)
 	}
 
 	if payload.UninstallScript == "" {
@@ -1420,6 +1571,36 @@ func (svc *Service) addMetadataToSoftwarePayload(ctx context.Context, payload *m
 	return meta.Extension, nil
 }
 
+// verifySoftwarePayloadSignature verifies the signature of the installer
+// file of the payload and sets its signature information. It fails if the
+// signature is not valid and the software installer settings require a
+// valid signature.
+func (svc *Service) verifySoftwarePayloadSignature(ctx context.Context, payload *mdmlab.UploadSoftwareInstallerPayload) error {
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get app config")
+	}
+	settings := appConfig.SoftwareInstallerSettings
+
+	keyring, err := file.ReadGPGKeyRing(settings.TrustedGPGKeys)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "reading trusted GPG keys")
+	}
+	sig, err := file.VerifyInstallerSignature(payload.InstallerFile, keyring)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "verifying installer signature")
+	}
+	payload.Signature = *sig
+
+	if settings.RequireValidSignature && sig.SignatureStatus != mdmlab.SoftwareInstallerSignatureValid {
+		return &mdmlab.BadRequestError{
+			Message:     fmt.Sprintf("Couldn't add. The package signature is %s, a valid signature is required.", sig.SignatureStatus),
+			InternalErr: ctxerr.Errorf(ctx, "installer signature is %s", sig.SignatureStatus),
+		}
+	}
+	return nil
+}
+
 const (
 	batchSoftwarePrefix = "software_batch_"
 )
@@ -1860,12 +2041,13 @@ func (svc *Service) SelfServiceInstallSoftwareTitle(ctx context.Context, host *m
 // package extension. Returns an empty string if there is no match.
 func packageExtensionToPlatform(ext string) string {
 	var requiredPlatform string
-	switch ext {
+	// AppImages are conventionally named with the .AppImage extension.
+	switch strings.ToLower(ext) {
 	case ".msi", ".exe":
 		requiredPlatform = "windows"
 	case ".pkg", ".dmg", ".zip":
 		requiredPlatform = "darwin"
-	case ".deb", ".rpm":
+	case ".deb", ".rpm", ".snap", ".snapref", ".flatpak", ".flatpakref", ".appimage":
 		requiredPlatform = "linux"
 	default:
 		return ""
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"testing"
 
+	"github.com/it-laborato/MDM_Lab/pkg/file"
 	"github.com/it-laborato/MDM_Lab/server/authz"
+	hostctx "github.com/it-laborato/MDM_Lab/server/contexts/host"
 	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/mock"
@@ -76,6 +80,17 @@ quotes and braces for (
 	assert.Equal(t, expected, payload.UninstallScript)
 }
 
+func TestDefaultInstallScript(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, file.GetInstallScript("deb"), defaultInstallScript("deb", []string{"foo"}))
+	require.Equal(t, file.GetInstallScript("snap"), defaultInstallScript("snap", []string{"foo"}))
+
+	script := defaultInstallScript("appimage", []string{"obsidian"})
+	require.Contains(t, script, `/opt/appimages/"obsidian".AppImage`)
+	require.NotContains(t, script, "$PACKAGE_ID")
+}
+
 func TestInstallUninstallAuth(t *testing.T) {
 	t.Parallel()
 	ds := new(mock.Store)
@@ -202,6 +217,37 @@ func TestUninstallSoftwareTitle(t *testing.T) {
 	require.ErrorContains(t, svc.UninstallSoftwareTitle(context.Background(), 1, 10), mdmlab.RunScriptsOrbitDisabledErrMsg)
 }
 
+func TestVerifySoftwarePayloadSignature(t *testing.T) {
+	t.Parallel()
+	ds := new(mock.Store)
+	svc := newTestService(t, ds)
+	ctx := context.Background()
+
+	var settings mdmlab.SoftwareInstallerSettings
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{SoftwareInstallerSettings: settings}, nil
+	}
+
+	tfr, err := mdmlab.NewKeepFileReader("../../../pkg/file/testdata/unsigned.pkg")
+	require.NoError(t, err)
+	t.Cleanup(func() { tfr.Close() })
+	payload := &mdmlab.UploadSoftwareInstallerPayload{InstallerFile: tfr}
+
+	// the signature is recorded
+	require.NoError(t, svc.verifySoftwarePayloadSignature(ctx, payload))
+	require.Equal(t, mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}, payload.Signature)
+
+	// the package is rejected if a valid signature is required
+	settings.RequireValidSignature = true
+	err = svc.verifySoftwarePayloadSignature(ctx, payload)
+	var badReqErr *mdmlab.BadRequestError
+	require.ErrorAs(t, err, &badReqErr)
+	require.Equal(t, "Couldn't add. The package signature is unsigned, a valid signature is required.", badReqErr.Message)
+
+	settings.TrustedGPGKeys = []string{"not a key"}
+	require.ErrorContains(t, svc.verifySoftwarePayloadSignature(ctx, payload), "reading trusted GPG keys")
+}
+
 func checkAuthErr(t *testing.T, shouldFail bool, err error) {
 	t.Helper()
 	if shouldFail {
@@ -223,3 +269,139 @@ func newTestService(t *testing.T, ds mdmlab.Datastore) *Service {
 	}
 	return svc
 }
+
+type signingSoftwareInstallerStore struct {
+	mdmlab.FailingSoftwareInstallerStore
+}
+
+func (signingSoftwareInstallerStore) Sign(ctx context.Context, fileID string) (string, error) {
+	return "https://cdn.example.com/" + fileID, nil
+}
+
+func TestGetSoftwareInstallURL(t *testing.T) {
+	t.Parallel()
+	ds := new(mock.Store)
+	svc := newTestService(t, ds)
+	svc.softwareInstallStore = signingSoftwareInstallerStore{}
+
+	ds.ValidateOrbitSoftwareInstallerAccessFunc = func(ctx context.Context, hostID uint, installerID uint) (bool, error) {
+		return true, nil
+	}
+	ds.GetSoftwareInstallerMetadataByIDFunc = func(ctx context.Context, id uint) (*mdmlab.SoftwareInstaller, error) {
+		return &mdmlab.SoftwareInstaller{InstallerID: id, StorageID: "current", Name: "current.pkg"}, nil
+	}
+
+	// the host has no request context
+	_, err := svc.getSoftwareInstallURL(context.Background(), 1)
+	require.ErrorContains(t, err, "missing host from request context")
+
+	ctx := hostctx.NewContext(context.Background(), &mdmlab.Host{ID: 1})
+
+	// the host installs the current version
+	ds.GetPendingSoftwareInstallerVersionFunc = func(ctx context.Context, hostID, installerID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+		return nil, nil
+	}
+	url, err := svc.getSoftwareInstallURL(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &mdmlab.SoftwareInstallerURL{URL: "https://cdn.example.com/current", Filename: "current.pkg"}, url)
+
+	// the host installs a pinned or label-targeted version
+	ds.GetPendingSoftwareInstallerVersionFunc = func(ctx context.Context, hostID, installerID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+		return &mdmlab.SoftwareInstallerVersion{StorageID: "previous", Filename: "previous.pkg"}, nil
+	}
+	url, err = svc.getSoftwareInstallURL(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &mdmlab.SoftwareInstallerURL{URL: "https://cdn.example.com/previous", Filename: "previous.pkg"}, url)
+}
+
+type memSoftwareInstallerStore struct {
+	mdmlab.FailingSoftwareInstallerStore
+	installers map[string][]byte
+}
+
+func (s memSoftwareInstallerStore) Exists(ctx context.Context, installerID string) (bool, error) {
+	_, ok := s.installers[installerID]
+	return ok, nil
+}
+
+func (s memSoftwareInstallerStore) Get(ctx context.Context, installerID string) (io.ReadCloser, int64, error) {
+	b := s.installers[installerID]
+	return io.NopCloser(bytes.NewReader(b)), int64(len(b)), nil
+}
+
+func TestSoftwareInstallerCacheToken(t *testing.T) {
+	t.Parallel()
+	ds := new(mock.Store)
+	svc := newTestService(t, ds)
+	svc.softwareInstallStore = memSoftwareInstallerStore{installers: map[string][]byte{"previous": []byte("installer")}}
+
+	tokens := make(map[string]string)
+	svc.distributedLock = &mockLock{
+		AcquireLockFn: func(ctx context.Context, key string, value string, expireMs uint64) (bool, error) {
+			require.EqualValues(t, softwareInstallerCacheTokenExpirationMs, expireMs)
+			tokens[key] = value
+			return true, nil
+		},
+		GetFn: func(ctx context.Context, key string) (*string, error) {
+			if v, ok := tokens[key]; ok {
+				return &v, nil
+			}
+			return nil, nil
+		},
+		GetAndDeleteFn: func(ctx context.Context, key string) (*string, error) {
+			if v, ok := tokens[key]; ok {
+				delete(tokens, key)
+				return &v, nil
+			}
+			return nil, nil
+		},
+	}
+
+	ds.ValidateOrbitSoftwareInstallerAccessFunc = func(ctx context.Context, hostID uint, installerID uint) (bool, error) {
+		return true, nil
+	}
+	ds.GetSoftwareInstallerMetadataByIDFunc = func(ctx context.Context, id uint) (*mdmlab.SoftwareInstaller, error) {
+		return &mdmlab.SoftwareInstaller{InstallerID: id, StorageID: "current", Name: "current.pkg"}, nil
+	}
+	ds.GetPendingSoftwareInstallerVersionFunc = func(ctx context.Context, hostID, installerID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+		return &mdmlab.SoftwareInstallerVersion{StorageID: "previous", Filename: "previous.pkg"}, nil
+	}
+
+	ctx := hostctx.NewContext(context.Background(), &mdmlab.Host{ID: 1})
+	token, err := svc.generateSoftwareInstallerCacheToken(ctx, &mdmlab.SoftwareInstallDetails{
+		ExecutionID:     "install-1",
+		InstallerID:     2,
+		InstallerSHA256: "previous",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	// the cache gets the installer to serve, the token is still valid after
+	// that, it doesn't carry any host credential.
+	for i := 0; i < 2; i++ {
+		meta, err := svc.GetSoftwareInstallerCacheTokenMetadata(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, &mdmlab.SoftwareInstallerCacheTokenMetadata{
+			InstallUUID:     "install-1",
+			InstallerID:     2,
+			InstallerSHA256: "previous",
+			StorageID:       "previous",
+			Filename:        "previous.pkg",
+		}, meta)
+	}
+
+	// the token authorizes a single download of the installer of the install
+	payload, err := svc.DownloadSoftwareInstallerWithCacheToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "previous.pkg", payload.Filename)
+	b, err := io.ReadAll(payload.Installer)
+	require.NoError(t, err)
+	assert.Equal(t, "installer", string(b))
+
+	_, err = svc.DownloadSoftwareInstallerWithCacheToken(context.Background(), token)
+	require.ErrorContains(t, err, "invalid token")
+	_, err = svc.GetSoftwareInstallerCacheTokenMetadata(context.Background(), token)
+	require.ErrorContains(t, err, "invalid token")
+	_, err = svc.GetSoftwareInstallerCacheTokenMetadata(context.Background(), "")
+	require.ErrorContains(t, err, "invalid token")
+}
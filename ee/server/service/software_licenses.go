@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+func (svc *Service) ListSoftwareLicenses(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	licenses, err := svc.ds.ListSoftwareLicenses(ctx, teamID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software licenses")
+	}
+	return licenses, nil
+}
+
+func (svc *Service) ListSoftwareLicensesReport(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error) {
+	if teamID != nil {
+		return svc.ListSoftwareLicenses(ctx, teamID)
+	}
+
+	// the licenses of all the teams are only available to global users.
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+	licenses, err := svc.ds.ListAllSoftwareLicenses(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list all software licenses")
+	}
+	return licenses, nil
+}
+
+func (svc *Service) GetSoftwareLicense(ctx context.Context, id uint) (*mdmlab.SoftwareLicense, error) {
+	return svc.authorizeSoftwareLicense(ctx, id, mdmlab.ActionRead)
+}
+
+func (svc *Service) CreateSoftwareLicense(ctx context.Context, payload *mdmlab.SoftwareLicensePayload) (*mdmlab.SoftwareLicense, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: payload.TeamID}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "validate software license")
+	}
+
+	license := &mdmlab.SoftwareLicense{
+		TeamID:          payload.TeamID,
+		SoftwareTitleID: payload.SoftwareTitleID,
+	}
+	setSoftwareLicensePayload(license, payload)
+	license, err := svc.ds.NewSoftwareLicense(ctx, license)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			return nil, mdmlab.NewInvalidArgumentError("software_title_id", "software title does not exist")
+		}
+		return nil, ctxerr.Wrap(ctx, err, "create software license")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeAddedSoftwareLicense{ActivitySoftwareLicense: license.ActivityDetails()}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "creating activity for added software license")
+	}
+	return license, nil
+}
+
+func (svc *Service) ModifySoftwareLicense(ctx context.Context, id uint, payload *mdmlab.SoftwareLicensePayload) (*mdmlab.SoftwareLicense, error) {
+	license, err := svc.authorizeSoftwareLicense(ctx, id, mdmlab.ActionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	// the team and title of a license can't be changed.
+	payload.SoftwareTitleID = license.SoftwareTitleID
+	if err := payload.Validate(); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "validate software license")
+	}
+
+	setSoftwareLicensePayload(license, payload)
+	license, err = svc.ds.UpdateSoftwareLicense(ctx, license)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "update software license")
+	}
+
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeEditedSoftwareLicense{ActivitySoftwareLicense: license.ActivityDetails()}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "creating activity for edited software license")
+	}
+	return license, nil
+}
+
+func (svc *Service) DeleteSoftwareLicense(ctx context.Context, id uint) error {
+	license, err := svc.authorizeSoftwareLicense(ctx, id, mdmlab.ActionWrite)
+	if err != nil {
+		return err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.ErrNoContext
+	}
+
+	if err := svc.ds.DeleteSoftwareLicense(ctx, license.ID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software license")
+	}
+	if err := svc.NewActivity(ctx, vc.User, mdmlab.ActivityTypeDeletedSoftwareLicense{ActivitySoftwareLicense: license.ActivityDetails()}); err != nil {
+		return ctxerr.Wrap(ctx, err, "creating activity for deleted software license")
+	}
+	return nil
+}
+
+func (svc *Service) ListSoftwareLicenseHosts(ctx context.Context, id uint, status mdmlab.SoftwareLicenseUsageStatus) ([]*mdmlab.SoftwareLicenseHost, error) {
+	license, err := svc.authorizeSoftwareLicense(ctx, id, mdmlab.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != "" && !status.IsValid() {
+		return nil, mdmlab.NewInvalidArgumentError("usage_status", "must be one of used, unused or unknown")
+	}
+
+	hosts, err := svc.ds.ListSoftwareLicenseHosts(ctx, license)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software license hosts")
+	}
+	if status == "" {
+		return hosts, nil
+	}
+
+	filtered := hosts[:0]
+	for _, h := range hosts {
+		if h.UsageStatus == status {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered, nil
+}
+
+// authorizeSoftwareLicense authorizes the action on the software of the team
+// of the license and returns the license.
+func (svc *Service) authorizeSoftwareLicense(ctx context.Context, id uint, action string) (*mdmlab.SoftwareLicense, error) {
+	// first ensure the user has access to list hosts, then check the specific
+	// team of the license.
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, err
+	}
+
+	license, err := svc.ds.GetSoftwareLicense(ctx, id)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software license")
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: license.TeamID}, action); err != nil {
+		return nil, err
+	}
+	return license, nil
+}
+
+func setSoftwareLicensePayload(license *mdmlab.SoftwareLicense, payload *mdmlab.SoftwareLicensePayload) {
+	license.Vendor = payload.Vendor
+	license.SeatCount = payload.SeatCount
+	license.ExpiresAt = payload.ExpiresAt
+	license.Cost = payload.Cost
+	license.UnusedAfterDays = mdmlab.SoftwareLicenseDefaultUnusedAfterDays
+	if payload.UnusedAfterDays != nil {
+		license.UnusedAfterDays = *payload.UnusedAfterDays
+	}
+}
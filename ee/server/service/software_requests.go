@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/server"
+	authz_ctx "github.com/it-laborato/MDM_Lab/server/contexts/authz"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mail"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// softwareRequestJustificationMaxLength is the maximum length of the
+// justification of a software request.
+const softwareRequestJustificationMaxLength = 1000
+
+// deviceMappingSourcesByPriority are the sources of the device mapping used to
+// find the email of the end user who requests software, the most reliable
+// first.
+var deviceMappingSourcesByPriority = []string{
+	mdmlab.DeviceMappingMDMIdpAccounts,
+	mdmlab.DeviceMappingCustomOverride,
+	mdmlab.DeviceMappingCustomInstaller,
+	mdmlab.DeviceMappingGoogleChromeProfiles,
+}
+
+func (svc *Service) ListDeviceSoftwareCatalog(ctx context.Context, host *mdmlab.Host) ([]*mdmlab.SoftwareCatalogItem, error) {
+	if !svc.authz.IsAuthenticatedWith(ctx, authz_ctx.AuthnDeviceToken) {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewPermissionError("forbidden: only device-authenticated hosts can access this endpoint"))
+	}
+
+	items, err := svc.ds.ListSoftwareRequestCatalog(ctx, host)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software request catalog")
+	}
+
+	catalog := make([]*mdmlab.SoftwareCatalogItem, 0, len(items))
+	for _, item := range items {
+		scoped, err := svc.ds.IsSoftwareInstallerLabelScoped(ctx, item.InstallerID, host.ID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "checking label scoping of software catalog")
+		}
+		if scoped {
+			catalog = append(catalog, item)
+		}
+	}
+	return catalog, nil
+}
+
+func (svc *Service) RequestDeviceSoftware(ctx context.Context, host *mdmlab.Host, titleID uint, justification string) (*mdmlab.SoftwareRequest, error) {
+	if !svc.authz.IsAuthenticatedWith(ctx, authz_ctx.AuthnDeviceToken) {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewPermissionError("forbidden: only device-authenticated hosts can access this endpoint"))
+	}
+
+	if justification == "" {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("justification", "justification is required"))
+	}
+	if len(justification) > softwareRequestJustificationMaxLength {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("justification",
+			fmt.Sprintf("justification must be at most %d characters", softwareRequestJustificationMaxLength)))
+	}
+
+	installer, err := svc.requestableSoftwareInstaller(ctx, host, titleID)
+	if err != nil {
+		return nil, err
+	}
+	if installer.SelfService {
+		return nil, &mdmlab.BadRequestError{
+			Message: "Software is available in self-service, install it from the Self-service tab.",
+		}
+	}
+
+	pending, _, err := svc.ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{
+		HostID: &host.ID,
+		Status: mdmlab.SoftwareRequestPending,
+	})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list pending software requests of host")
+	}
+	for _, r := range pending {
+		if r.SoftwareTitleID == titleID {
+			return nil, &mdmlab.BadRequestError{
+				Message: "Couldn't request software. A request for this software is already pending.",
+			}
+		}
+	}
+
+	requestedBy, err := svc.softwareRequestEndUserEmail(ctx, host.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := svc.ds.NewSoftwareRequest(ctx, &mdmlab.SoftwareRequest{
+		HostID:          host.ID,
+		TeamID:          host.TeamID,
+		SoftwareTitleID: titleID,
+		SoftwareTitle:   installer.SoftwareTitle,
+		RequestedBy:     requestedBy,
+		Justification:   justification,
+	})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create software request")
+	}
+
+	if err := svc.NewActivity(ctx, nil, mdmlab.ActivityTypeRequestedSoftware{
+		ActivitySoftwareRequest: request.ActivityDetails(),
+		Justification:           request.Justification,
+	}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for software request")
+	}
+
+	// the request is recorded, failing to notify the maintainers is only
+	// logged.
+	svc.notifySoftwareRequest(ctx, request)
+	return request, nil
+}
+
+// requestableSoftwareInstaller returns the installer of the software title in
+// the team of the host if the host can install it.
+func (svc *Service) requestableSoftwareInstaller(ctx context.Context, host *mdmlab.Host, titleID uint) (*mdmlab.SoftwareInstaller, error) {
+	installer, err := svc.ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, host.TeamID, titleID, false)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			return nil, &mdmlab.BadRequestError{
+				Message: "Software is not available for this host.",
+				InternalErr: ctxerr.WrapWithData(
+					ctx, err, "no software installer for the title in the team of the host",
+					map[string]any{"host_id": host.ID, "team_id": host.TeamID, "title_id": titleID},
+				),
+			}
+		}
+		return nil, ctxerr.Wrap(ctx, err, "finding software installer for title")
+	}
+
+	if installer.Platform != mdmlab.PlatformFromHost(host.Platform) {
+		return nil, &mdmlab.BadRequestError{
+			Message: fmt.Sprintf("Software can be installed only on %s hosts.", installer.Platform),
+		}
+	}
+
+	scoped, err := svc.ds.IsSoftwareInstallerLabelScoped(ctx, installer.InstallerID, host.ID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "checking label scoping of requested software")
+	}
+	if !scoped {
+		return nil, &mdmlab.BadRequestError{
+			Message: "Host isn't member of the labels defined for this software title.",
+		}
+	}
+	return installer, nil
+}
+
+// softwareRequestEndUserEmail returns the email of the end user of the host,
+// empty if it is unknown.
+func (svc *Service) softwareRequestEndUserEmail(ctx context.Context, hostID uint) (string, error) {
+	mappings, err := svc.ds.ListHostDeviceMapping(ctx, hostID)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "list host device mapping")
+	}
+	for _, source := range deviceMappingSourcesByPriority {
+		for _, m := range mappings {
+			if m.Source == source && m.Email != "" {
+				return m.Email, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// notifySoftwareRequest sends the software request to the software requests
+// webhook and emails it to the admins and maintainers of the team of the
+// host, or to the global admins and maintainers if the team has none.
+func (svc *Service) notifySoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest) {
+	logger := level.Error(svc.logger)
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		logger.Log("msg", "get app config to notify software request", "err", err)
+		return
+	}
+
+	if webhook := appConfig.WebhookSettings.SoftwareRequestsWebhook; webhook.Enable {
+		requestedBy := request.RequestedBy
+		if requestedBy == "" {
+			requestedBy = "The end user"
+		}
+		payload := map[string]interface{}{
+			"text": fmt.Sprintf(
+				"%s requested %s on %s. "+
+					"You've been sent this message because the Software requests webhook is enabled in your MDMlab instance.",
+				requestedBy, request.SoftwareTitle, request.HostDisplayName,
+			),
+			"data": request,
+		}
+		if err := server.PostJSONWithTimeout(ctx, webhook.DestinationURL, &payload); err != nil {
+			logger.Log("msg", "post software request webhook", "software_request_id", request.ID, "err", err)
+		}
+	}
+
+	var smtpSettings mdmlab.SMTPSettings
+	if appConfig.SMTPSettings != nil {
+		smtpSettings = *appConfig.SMTPSettings
+	}
+	if svc.mailService == nil || !svc.mailService.CanSendEmail(smtpSettings) {
+		return
+	}
+	recipients, err := svc.softwareRequestReviewers(ctx, request.TeamID)
+	if err != nil {
+		logger.Log("msg", "list software request reviewers", "software_request_id", request.ID, "err", err)
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	if err := svc.mailService.SendEmail(mdmlab.Email{
+		Subject:      fmt.Sprintf("Software request: %s on %s", request.SoftwareTitle, request.HostDisplayName),
+		To:           recipients,
+		ServerURL:    appConfig.ServerSettings.ServerURL,
+		SMTPSettings: smtpSettings,
+		Mailer: &mail.SoftwareRequestMailer{
+			SoftwareRequest: request,
+			BaseURL:         template.URL(appConfig.ServerSettings.ServerURL + svc.config.Server.URLPrefix), //nolint:gosec // dismiss G203
+			AssetURL:        template.URL("https://mdmlabdm.com/images/permanent"),
+		},
+	}); err != nil {
+		logger.Log("msg", "send software request email", "software_request_id", request.ID, "err", err)
+	}
+}
+
+// softwareRequestReviewers returns the emails of the users who can review the
+// software requests of the team: the admins and maintainers of the team, or
+// the global admins and maintainers if the team has none.
+func (svc *Service) softwareRequestReviewers(ctx context.Context, teamID *uint) ([]string, error) {
+	isReviewerRole := func(role string) bool {
+		return role == mdmlab.RoleAdmin || role == mdmlab.RoleMaintainer
+	}
+
+	if teamID != nil {
+		users, err := svc.ds.ListUsers(ctx, mdmlab.UserListOptions{TeamID: *teamID})
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "list team users")
+		}
+		var emails []string
+		for _, u := range users {
+			if u.APIOnly {
+				continue
+			}
+			for _, t := range u.Teams {
+				if t.ID == *teamID && isReviewerRole(t.Role) {
+					emails = append(emails, u.Email)
+				}
+			}
+		}
+		if len(emails) > 0 {
+			return emails, nil
+		}
+	}
+
+	users, err := svc.ds.ListUsers(ctx, mdmlab.UserListOptions{})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list users")
+	}
+	var emails []string
+	for _, u := range users {
+		if !u.APIOnly && u.GlobalRole != nil && isReviewerRole(*u.GlobalRole) {
+			emails = append(emails, u.Email)
+		}
+	}
+	return emails, nil
+}
+
+func (svc *Service) ListDeviceSoftwareRequests(ctx context.Context, host *mdmlab.Host, opts mdmlab.ListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+	if !svc.authz.IsAuthenticatedWith(ctx, authz_ctx.AuthnDeviceToken) {
+		return nil, nil, ctxerr.Wrap(ctx, mdmlab.NewPermissionError("forbidden: only device-authenticated hosts can access this endpoint"))
+	}
+
+	requests, meta, err := svc.ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{
+		ListOptions: opts,
+		HostID:      &host.ID,
+	})
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list software requests of host")
+	}
+	return requests, meta, nil
+}
+
+func (svc *Service) ListSoftwareRequests(ctx context.Context, opts mdmlab.SoftwareRequestListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+	// the requests of a host are authorized with its current team, all the
+	// teams require a global role.
+	teamID := opts.TeamID
+	if teamID != nil && *teamID == 0 {
+		teamID = nil
+	}
+	if opts.HostID != nil && opts.TeamID == nil {
+		host, err := svc.ds.HostLite(ctx, *opts.HostID)
+		if err != nil {
+			if mdmlab.IsNotFound(err) {
+				// check first if the user can read the requests to not
+				// leak valid host IDs.
+				if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{}, mdmlab.ActionRead); err != nil {
+					return nil, nil, err
+				}
+			}
+			svc.authz.SkipAuthorization(ctx)
+			return nil, nil, ctxerr.Wrap(ctx, err, "get host")
+		}
+		teamID = host.TeamID
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.SoftwareInstaller{TeamID: teamID}, mdmlab.ActionRead); err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Status != "" && !opts.Status.IsValid() {
+		return nil, nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("status", "status must be one of pending, approved or denied"))
+	}
+
+	requests, meta, err := svc.ds.ListSoftwareRequests(ctx, opts)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list software requests")
+	}
+	return requests, meta, nil
+}
+
+func (svc *Service) ApproveSoftwareRequest(ctx context.Context, id uint, comment string) (*mdmlab.SoftwareRequest, error) {
+	request, host, err := svc.authorizeSoftwareRequestReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if host.OrbitNodeKey == nil || *host.OrbitNodeKey == "" {
+		// mdmlabd is required to install software so if the host is enrolled via plain osquery we return an error
+		return nil, mdmlab.NewUserMessageError(errors.New("host does not have mdmlabd installed"), http.StatusUnprocessableEntity)
+	}
+
+	// the host may have changed team since the request, the software is
+	// installed with the installer of its current team.
+	installer, err := svc.requestableSoftwareInstaller(ctx, host, request.SoftwareTitleID)
+	if err != nil {
+		return nil, err
+	}
+	lastInstallRequest, err := svc.ds.GetHostLastInstallData(ctx, host.ID, installer.InstallerID)
+	if err != nil {
+		return nil, ctxerr.Wrapf(ctx, err, "getting last install data for host %d and installer %d", host.ID, installer.InstallerID)
+	}
+	if lastInstallRequest != nil && lastInstallRequest.Status != nil &&
+		(*lastInstallRequest.Status == mdmlab.SoftwareInstallPending || *lastInstallRequest.Status == mdmlab.SoftwareUninstallPending) {
+		return nil, &mdmlab.BadRequestError{
+			Message: "Couldn't approve the request. Host has a pending install/uninstall request for this software.",
+		}
+	}
+
+	if err := checkSoftwareInstallerPlatform(ctx, host, installer); err != nil {
+		return nil, err
+	}
+	return svc.reviewSoftwareRequest(ctx, request, mdmlab.SoftwareRequestApproved, comment, installer)
+}
+
+func (svc *Service) DenySoftwareRequest(ctx context.Context, id uint, comment string) (*mdmlab.SoftwareRequest, error) {
+	request, _, err := svc.authorizeSoftwareRequestReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return svc.reviewSoftwareRequest(ctx, request, mdmlab.SoftwareRequestDenied, comment, nil)
+}
+
+// authorizeSoftwareRequestReview checks that the user can install software on
+// the host of the pending software request, it returns the request and its
+// host.
+func (svc *Service) authorizeSoftwareRequestReview(ctx context.Context, id uint) (*mdmlab.SoftwareRequest, *mdmlab.Host, error) {
+	request, err := svc.ds.GetSoftwareRequest(ctx, id)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			// check first if the user can install software to not leak valid
+			// request IDs.
+			if err := svc.authz.Authorize(ctx, &mdmlab.HostSoftwareInstallerResultAuthz{}, mdmlab.ActionWrite); err != nil {
+				return nil, nil, err
+			}
+		}
+		svc.authz.SkipAuthorization(ctx)
+		return nil, nil, ctxerr.Wrap(ctx, err, "get software request")
+	}
+
+	// we need to use ds.Host because ds.HostLite doesn't return the orbit node key
+	host, err := svc.ds.Host(ctx, request.HostID)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			if err := svc.authz.Authorize(ctx, &mdmlab.HostSoftwareInstallerResultAuthz{HostTeamID: request.TeamID}, mdmlab.ActionWrite); err != nil {
+				return nil, nil, err
+			}
+		}
+		svc.authz.SkipAuthorization(ctx)
+		return nil, nil, ctxerr.Wrap(ctx, err, "get host")
+	}
+
+	if err := svc.authz.Authorize(ctx, &mdmlab.HostSoftwareInstallerResultAuthz{HostTeamID: host.TeamID}, mdmlab.ActionWrite); err != nil {
+		return nil, nil, err
+	}
+
+	if request.Status != mdmlab.SoftwareRequestPending {
+		return nil, nil, ctxerr.Wrap(ctx, &mdmlab.ConflictError{Message: "The software request was already reviewed."})
+	}
+	return request, host, nil
+}
+
+// reviewSoftwareRequest records the review of the request, the software is
+// installed with installer if the request is approved.
+func (svc *Service) reviewSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest, status mdmlab.SoftwareRequestStatus, comment string,
+	installer *mdmlab.SoftwareInstaller,
+) (*mdmlab.SoftwareRequest, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	request.Status = status
+	request.ReviewComment = comment
+	request.ReviewedByUserID = &vc.User.ID
+	request.ReviewedByName = &vc.User.Name
+	if status == mdmlab.SoftwareRequestApproved {
+		// the request is claimed and the install is queued together, so that
+		// the software is installed once.
+		if err := svc.ds.ApproveSoftwareRequest(ctx, request, installer.InstallerID); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "approve software request")
+		}
+	} else if err := svc.ds.ReviewSoftwareRequest(ctx, request); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "review software request")
+	}
+
+	var activity mdmlab.ActivityDetails
+	if status == mdmlab.SoftwareRequestApproved {
+		activity = mdmlab.ActivityTypeApprovedSoftwareRequest{
+			ActivitySoftwareRequest: request.ActivityDetails(),
+			Comment:                 comment,
+			InstallUUID:             *request.InstallUUID,
+		}
+	} else {
+		activity = mdmlab.ActivityTypeDeniedSoftwareRequest{
+			ActivitySoftwareRequest: request.ActivityDetails(),
+			Comment:                 comment,
+		}
+	}
+	if err := svc.NewActivity(ctx, vc.User, activity); err != nil {
+		return nil, ctxerr.Wrapf(ctx, err, "creating activity %s", activity.ActivityName())
+	}
+
+	request, err := svc.ds.GetSoftwareRequest(ctx, request.ID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get reviewed software request")
+	}
+	return request, nil
+}
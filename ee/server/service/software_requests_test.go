@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	authz_ctx "github.com/it-laborato/MDM_Lab/server/contexts/authz"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+// activityRecorder is the free service used by the software requests tests,
+// it records the activities.
+type activityRecorder struct {
+	mdmlab.Service
+	activities []mdmlab.ActivityDetails
+}
+
+func (r *activityRecorder) NewActivity(ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails) error {
+	r.activities = append(r.activities, activity)
+	return nil
+}
+
+func TestRequestDeviceSoftware(t *testing.T) {
+	ds := new(mock.Store)
+	svc := newTestService(t, ds)
+	recorder := &activityRecorder{}
+	svc.Service = recorder
+
+	host := &mdmlab.Host{ID: 1, Platform: "darwin", TeamID: ptr.Uint(2)}
+	installer := &mdmlab.SoftwareInstaller{InstallerID: 3, Platform: "darwin", SoftwareTitle: "Figma.app"}
+	ds.GetSoftwareInstallerMetadataByTeamAndTitleIDFunc = func(ctx context.Context, teamID *uint, titleID uint, withScriptContents bool) (*mdmlab.SoftwareInstaller, error) {
+		if titleID != 10 {
+			return nil, &mock.Error{Message: "not found"}
+		}
+		require.Equal(t, uint(2), *teamID)
+		return installer, nil
+	}
+	scoped := true
+	ds.IsSoftwareInstallerLabelScopedFunc = func(ctx context.Context, installerID, hostID uint) (bool, error) {
+		return scoped, nil
+	}
+	var pending []*mdmlab.SoftwareRequest
+	ds.ListSoftwareRequestsFunc = func(ctx context.Context, opts mdmlab.SoftwareRequestListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+		require.Equal(t, host.ID, *opts.HostID)
+		require.Equal(t, mdmlab.SoftwareRequestPending, opts.Status)
+		return pending, nil, nil
+	}
+	ds.ListHostDeviceMappingFunc = func(ctx context.Context, id uint) ([]*mdmlab.HostDeviceMapping, error) {
+		return []*mdmlab.HostDeviceMapping{
+			{Email: "chrome@example.com", Source: mdmlab.DeviceMappingGoogleChromeProfiles},
+			{Email: "anna@example.com", Source: mdmlab.DeviceMappingMDMIdpAccounts},
+		}, nil
+	}
+	var created *mdmlab.SoftwareRequest
+	ds.NewSoftwareRequestFunc = func(ctx context.Context, request *mdmlab.SoftwareRequest) (*mdmlab.SoftwareRequest, error) {
+		created = request
+		request.ID = 42
+		return request, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+
+	deviceCtx := func() context.Context {
+		authzCtx := &authz_ctx.AuthorizationContext{}
+		authzCtx.SetAuthnMethod(authz_ctx.AuthnDeviceToken)
+		return authz_ctx.NewContext(context.Background(), authzCtx)
+	}
+
+	// only device-authenticated hosts can request software
+	_, err := svc.RequestDeviceSoftware(context.Background(), host, 10, "design reviews")
+	require.ErrorContains(t, err, "only device-authenticated hosts")
+
+	_, err = svc.RequestDeviceSoftware(deviceCtx(), host, 10, "")
+	require.ErrorContains(t, err, "justification is required")
+
+	request, err := svc.RequestDeviceSoftware(deviceCtx(), host, 10, "design reviews")
+	require.NoError(t, err)
+	require.Equal(t, uint(42), request.ID)
+	require.Equal(t, host.TeamID, created.TeamID)
+	require.Equal(t, "Figma.app", created.SoftwareTitle)
+	require.Equal(t, "anna@example.com", created.RequestedBy)
+	require.Len(t, recorder.activities, 1)
+	require.Equal(t, "requested_software", recorder.activities[0].ActivityName())
+
+	var badReqErr *mdmlab.BadRequestError
+	cases := []struct {
+		name    string
+		setup   func()
+		titleID uint
+		wantErr string
+	}{
+		{"no installer", func() {}, 11, "Software is not available for this host."},
+		{"other platform", func() { installer.Platform = "windows" }, 10, "Software can be installed only on windows hosts."},
+		{"not label scoped", func() { scoped = false }, 10, "Host isn't member of the labels defined for this software title."},
+		{"self-service", func() { installer.SelfService = true }, 10, "Software is available in self-service"},
+		{"pending request", func() {
+			pending = []*mdmlab.SoftwareRequest{{ID: 42, SoftwareTitleID: 10}}
+		}, 10, "A request for this software is already pending."},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			installer.Platform, installer.SelfService, scoped, pending = "darwin", false, true, nil
+			c.setup()
+			_, err := svc.RequestDeviceSoftware(deviceCtx(), host, c.titleID, "design reviews")
+			require.ErrorAs(t, err, &badReqErr)
+			require.Contains(t, badReqErr.Message, c.wantErr)
+		})
+	}
+}
+
+func TestReviewSoftwareRequest(t *testing.T) {
+	ds := new(mock.Store)
+	svc := newTestService(t, ds)
+	recorder := &activityRecorder{}
+	svc.Service = recorder
+
+	request := &mdmlab.SoftwareRequest{ID: 42, HostID: 1, TeamID: ptr.Uint(1), SoftwareTitleID: 10, Status: mdmlab.SoftwareRequestPending}
+	ds.GetSoftwareRequestFunc = func(ctx context.Context, id uint) (*mdmlab.SoftwareRequest, error) {
+		r := *request
+		return &r, nil
+	}
+	host := &mdmlab.Host{ID: 1, OrbitNodeKey: ptr.String("orbit_key"), Platform: "darwin", TeamID: ptr.Uint(1)}
+	ds.HostFunc = func(ctx context.Context, id uint) (*mdmlab.Host, error) {
+		return host, nil
+	}
+	ds.GetSoftwareInstallerMetadataByTeamAndTitleIDFunc = func(ctx context.Context, teamID *uint, titleID uint, withScriptContents bool) (*mdmlab.SoftwareInstaller, error) {
+		return &mdmlab.SoftwareInstaller{InstallerID: 3, Name: "figma.pkg", Platform: "darwin"}, nil
+	}
+	ds.IsSoftwareInstallerLabelScopedFunc = func(ctx context.Context, installerID, hostID uint) (bool, error) {
+		return true, nil
+	}
+	ds.GetHostLastInstallDataFunc = func(ctx context.Context, hostID uint, installerID uint) (*mdmlab.HostLastInstallData, error) {
+		return nil, nil
+	}
+	var reviewed *mdmlab.SoftwareRequest
+	ds.ApproveSoftwareRequestFunc = func(ctx context.Context, r *mdmlab.SoftwareRequest, installerID uint) error {
+		require.Equal(t, uint(3), installerID)
+		r.InstallUUID = ptr.String("install-1")
+		reviewed = r
+		return nil
+	}
+	ds.ReviewSoftwareRequestFunc = func(ctx context.Context, r *mdmlab.SoftwareRequest) error {
+		reviewed = r
+		return nil
+	}
+
+	admin := &mdmlab.User{ID: 5, Name: "Admin", Teams: []mdmlab.UserTeam{{Team: mdmlab.Team{ID: 1}, Role: mdmlab.RoleMaintainer}}}
+	observer := &mdmlab.User{ID: 6, Teams: []mdmlab.UserTeam{{Team: mdmlab.Team{ID: 1}, Role: mdmlab.RoleObserver}}}
+	otherTeam := &mdmlab.User{ID: 7, Teams: []mdmlab.UserTeam{{Team: mdmlab.Team{ID: 2}, Role: mdmlab.RoleAdmin}}}
+	userCtx := func(u *mdmlab.User) context.Context {
+		return viewer.NewContext(context.Background(), viewer.Viewer{User: u})
+	}
+
+	for _, u := range []*mdmlab.User{observer, otherTeam} {
+		_, err := svc.ApproveSoftwareRequest(userCtx(u), 42, "")
+		checkAuthErr(t, true, err)
+		_, err = svc.DenySoftwareRequest(userCtx(u), 42, "")
+		checkAuthErr(t, true, err)
+	}
+
+	_, err := svc.ApproveSoftwareRequest(userCtx(admin), 42, "enjoy")
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareRequestApproved, reviewed.Status)
+	require.Equal(t, "install-1", *reviewed.InstallUUID)
+	require.Equal(t, admin.ID, *reviewed.ReviewedByUserID)
+	require.Equal(t, "Admin", *reviewed.ReviewedByName)
+	require.Equal(t, "enjoy", reviewed.ReviewComment)
+	require.Len(t, recorder.activities, 1)
+	require.Equal(t, mdmlab.ActivityTypeApprovedSoftwareRequest{
+		ActivitySoftwareRequest: mdmlab.ActivitySoftwareRequest{RequestID: 42, HostID: 1},
+		Comment:                 "enjoy",
+		InstallUUID:             "install-1",
+	}, recorder.activities[0])
+
+	_, err = svc.DenySoftwareRequest(userCtx(admin), 42, "no license left")
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareRequestDenied, reviewed.Status)
+	require.Nil(t, reviewed.InstallUUID)
+	require.Len(t, recorder.activities, 2)
+	require.Equal(t, "denied_software_request", recorder.activities[1].ActivityName())
+
+	// the host needs mdmlabd to install the software
+	host.OrbitNodeKey = nil
+	_, err = svc.ApproveSoftwareRequest(userCtx(admin), 42, "")
+	require.ErrorContains(t, err, "host does not have mdmlabd installed")
+	host.OrbitNodeKey = ptr.String("orbit_key")
+
+	// the request is reviewed concurrently, the approval fails without
+	// another activity.
+	ds.ApproveSoftwareRequestFunc = func(ctx context.Context, r *mdmlab.SoftwareRequest, installerID uint) error {
+		return &mdmlab.ConflictError{Message: "The software request was already reviewed."}
+	}
+	_, err = svc.ApproveSoftwareRequest(userCtx(admin), 42, "")
+	var conflictErr *mdmlab.ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Len(t, recorder.activities, 2)
+
+	// a request is reviewed once
+	request.Status = mdmlab.SoftwareRequestDenied
+	_, err = svc.ApproveSoftwareRequest(userCtx(admin), 42, "")
+	require.ErrorAs(t, err, &conflictErr)
+}
@@ -1512,6 +1512,22 @@ func (svc *Service) applyTeamMacOSSettings(ctx context.Context, spec *mdmlab.Tea
 		}
 	}
 
+	if setFields["recovery_lock"] && applyUpon.RecoveryLock != nil {
+		if err := applyUpon.RecoveryLock.Validate(); err != nil {
+			return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("macos_settings.recovery_lock", err.Error()))
+		}
+		if applyUpon.RecoveryLock.Enable {
+			if !appCfg.MDM.EnabledAndConfigured {
+				return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("macos_settings.recovery_lock",
+					`Couldn't update macos_settings because MDM features aren't turned on in MDMlab. Use mdmlabctl generate mdm-apple and then mdmlab serve with mdm configuration to turn on MDM features.`))
+			}
+			if svc.config.Server.PrivateKey == "" {
+				return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("macos_settings.recovery_lock",
+					"Missing required private key. Learn how to configure the private key here: https://mdmlabdm.com/learn-more-about/mdmlab-server-private-key"))
+			}
+		}
+	}
+
 	return nil
 }
 
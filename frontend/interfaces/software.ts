@@ -61,6 +61,16 @@ export interface ISoftwareInstallPolicy {
   name: string;
 }
 
+/** The result of the verification of the signature of a software package
+ * when it was added, empty for the packages added before the verification. */
+export type SoftwareInstallerSignatureStatus =
+  | "valid"
+  | "untrusted"
+  | "invalid"
+  | "unsigned"
+  | "unsupported"
+  | "";
+
 export interface ISoftwarePackage {
   name: string;
   last_install: string | null;
@@ -86,6 +96,11 @@ export interface ISoftwarePackage {
   install_during_setup?: boolean;
   labels_include_any: ILabelSoftwareTitle[] | null;
   labels_exclude_any: ILabelSoftwareTitle[] | null;
+  hash_sha256?: string;
+  signature_status?: SoftwareInstallerSignatureStatus;
+  signer?: string;
+  signer_team_id?: string;
+  has_stapled_ticket?: boolean;
 }
 
 export const isSoftwarePackage = (
@@ -327,7 +342,17 @@ export interface ISoftwareInstallResults {
 
 // ISoftwareInstallerType defines the supported installer types for
 // software uploaded by the IT admin.
-export type ISoftwareInstallerType = "pkg" | "msi" | "deb" | "rpm" | "exe";
+export type ISoftwareInstallerType =
+  | "pkg"
+  | "msi"
+  | "deb"
+  | "rpm"
+  | "exe"
+  | "snap"
+  | "snapref"
+  | "flatpak"
+  | "flatpakref"
+  | "appimage";
 
 export interface ISoftwareLastInstall {
   install_uuid: string;
@@ -12,6 +12,7 @@ import {
   ISoftwarePackage,
   IAppStoreApp,
   isSoftwarePackage,
+  SoftwareInstallerSignatureStatus,
 } from "interfaces/software";
 import softwareAPI from "services/entities/software";
 
@@ -182,6 +183,69 @@ const InstallerStatusCount = ({
   );
 };
 
+const SIGNATURE_DISPLAY_OPTIONS: Partial<
+  Record<
+    SoftwareInstallerSignatureStatus,
+    { text: string; icon: "success" | "warning" | "error" }
+  >
+> = {
+  valid: { text: "Signed", icon: "success" },
+  untrusted: { text: "Untrusted signature", icon: "warning" },
+  invalid: { text: "Invalid signature", icon: "error" },
+  unsigned: { text: "Unsigned", icon: "warning" },
+};
+
+interface ISignatureTagProps {
+  softwarePackage: ISoftwarePackage;
+}
+
+const SignatureTag = ({ softwarePackage }: ISignatureTagProps) => {
+  const {
+    signature_status: status,
+    signer,
+    signer_team_id: teamId,
+    has_stapled_ticket: hasStapledTicket,
+    hash_sha256: hash,
+  } = softwarePackage;
+  const displayOption = status && SIGNATURE_DISPLAY_OPTIONS[status];
+  if (!displayOption) {
+    return null;
+  }
+
+  return (
+    <TooltipWrapper
+      showArrow
+      position="top"
+      underline={false}
+      tipContent={
+        <>
+          {signer && (
+            <>
+              Signed by: {signer}
+              <br />
+            </>
+          )}
+          {teamId && (
+            <>
+              Team ID: {teamId}
+              <br />
+            </>
+          )}
+          {hasStapledTicket && (
+            <>
+              Notarization ticket stapled (not verified)
+              <br />
+            </>
+          )}
+          {hash && <>SHA-256: {hash}</>}
+        </>
+      }
+    >
+      <Tag icon={displayOption.icon} text={displayOption.text} />
+    </TooltipWrapper>
+  );
+};
+
 interface IActionsDropdownProps {
   isPackage: boolean;
   onDownloadClick: () => void;
@@ -385,6 +449,11 @@ const SoftwareInstallerCard = ({
                 />
               </TooltipWrapper>
             )}
+          {isPackage && (
+            <SignatureTag
+              softwarePackage={softwareInstaller as ISoftwarePackage}
+            />
+          )}
           {isSelfService && <Tag icon="user" text="Self-service" />}
           {showActions && (
             <SoftwareActionsDropdown
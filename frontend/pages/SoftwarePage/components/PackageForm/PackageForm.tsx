@@ -68,7 +68,8 @@ interface IPackageFormProps {
   className?: string;
 }
 
-const ACCEPTED_EXTENSIONS = ".pkg,.msi,.exe,.deb,.rpm";
+const ACCEPTED_EXTENSIONS =
+  ".pkg,.msi,.exe,.deb,.rpm,.snap,.snapref,.flatpak,.flatpakref,.AppImage";
 
 const PackageForm = ({
   labels,
@@ -232,7 +233,7 @@ const PackageForm = ({
           canEdit={isEditingSoftware}
           graphicName={"file-pkg"}
           accept={ACCEPTED_EXTENSIONS}
-          message=".pkg, .msi, .exe, .deb, or .rpm"
+          message=".pkg, .msi, .exe, .deb, .rpm, .snap, .snapref, .flatpak, .flatpakref, or .AppImage"
           onFileUpload={onFileSelect}
           buttonMessage="Choose file"
           buttonType="link"
@@ -8,13 +8,23 @@ import installExe from "../../pkg/file/scripts/install_exe.ps1";
 import installDeb from "../../pkg/file/scripts/install_deb.sh";
 // @ts-ignore
 import installRPM from "../../pkg/file/scripts/install_rpm.sh";
+// @ts-ignore
+import installSnap from "../../pkg/file/scripts/install_snap.sh";
+// @ts-ignore
+import installSnapRef from "../../pkg/file/scripts/install_snapref.sh";
+// @ts-ignore
+import installFlatpak from "../../pkg/file/scripts/install_flatpak.sh";
+// @ts-ignore
+import installFlatpakRef from "../../pkg/file/scripts/install_flatpakref.sh";
+// @ts-ignore
+import installAppImage from "../../pkg/file/scripts/install_appimage.sh";
 
 /*
  * getInstallScript returns a string with a script to install the
  * provided software.
  * */
 const getDefaultInstallScript = (fileName: string): string => {
-  const extension = fileName.split(".").pop();
+  const extension = fileName.split(".").pop()?.toLowerCase();
   switch (extension) {
     case "pkg":
       return installPkg;
@@ -26,6 +36,16 @@ const getDefaultInstallScript = (fileName: string): string => {
       return installRPM;
     case "exe":
       return installExe;
+    case "snap":
+      return installSnap;
+    case "snapref":
+      return installSnapRef;
+    case "flatpak":
+      return installFlatpak;
+    case "flatpakref":
+      return installFlatpakRef;
+    case "appimage":
+      return installAppImage;
     default:
       throw new Error(`unsupported file extension: ${extension}`);
   }
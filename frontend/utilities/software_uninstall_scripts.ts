@@ -8,13 +8,19 @@ import uninstallExe from "../../pkg/file/scripts/uninstall_exe.ps1";
 import uninstallDeb from "../../pkg/file/scripts/uninstall_deb.sh";
 // @ts-ignore
 import uninstallRPM from "../../pkg/file/scripts/uninstall_rpm.sh";
+// @ts-ignore
+import uninstallSnap from "../../pkg/file/scripts/uninstall_snap.sh";
+// @ts-ignore
+import uninstallFlatpak from "../../pkg/file/scripts/uninstall_flatpak.sh";
+// @ts-ignore
+import uninstallAppImage from "../../pkg/file/scripts/uninstall_appimage.sh";
 
 /*
  * getUninstallScript returns a string with a script to uninstall the
  * provided software.
  * */
 const getDefaultUninstallScript = (fileName: string): string => {
-  const extension = fileName.split(".").pop();
+  const extension = fileName.split(".").pop()?.toLowerCase();
   switch (extension) {
     case "pkg":
       return uninstallPkg;
@@ -26,6 +32,14 @@ const getDefaultUninstallScript = (fileName: string): string => {
       return uninstallRPM;
     case "exe":
       return uninstallExe;
+    case "snap":
+    case "snapref":
+      return uninstallSnap;
+    case "flatpak":
+    case "flatpakref":
+      return uninstallFlatpak;
+    case "appimage":
+      return uninstallAppImage;
     default:
       throw new Error(`unsupported file extension: ${extension}`);
   }
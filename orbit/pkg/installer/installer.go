@@ -2,9 +2,12 @@ package installer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -35,6 +38,7 @@ type (
 type Client interface {
 	GetInstallerDetails(installID string) (*fleet.SoftwareInstallDetails, error)
 	DownloadSoftwareInstaller(installerID uint, downloadDir string) (string, error)
+	DownloadSoftwareInstallerFromCache(cacheURL string, cacheToken string, installerID uint, downloadDir string) (string, error)
 	// DownloadSoftwareInstallerFromURL(url string, filename string, downloadDir string) (string, error)
 	SaveInstallerResult(payload *fleet.HostSoftwareInstallResultPayload) error
 }
@@ -77,6 +81,10 @@ type Runner struct {
 	rootDirPath string
 
 	retryOpts []retry.Option
+
+	// softwareInstallerCacheURL is the URL of the local cache from which the
+	// installers are downloaded, set from the orbit config on each run.
+	softwareInstallerCacheURL string
 }
 
 func NewRunner(client Client, socketPath string, scriptsEnabled func() bool, rootDirPath string) *Runner {
@@ -130,6 +138,7 @@ func connectOsquery(r *Runner) error {
 
 func (r *Runner) run(ctx context.Context, config *fleet.OrbitConfig) error {
 	log.Debug().Msg("starting software installers run")
+	r.softwareInstallerCacheURL = config.SoftwareInstallerCacheURL
 	var errs []error
 	for _, installerID := range config.Notifications.PendingSoftwareInstallerIDs {
 		if ctx.Err() != nil {
@@ -194,6 +203,27 @@ func (r *Runner) preConditionCheck(ctx context.Context, query string) (bool, str
 	return true, string(response), nil
 }
 
+// downloadInstallerFromCache downloads the installer from the local software
+// installer cache and returns its path, or an empty string if the cache
+// failed to serve the expected installer, in which case it is downloaded from
+// the server.
+func (r *Runner) downloadInstallerFromCache(installID string, installer *fleet.SoftwareInstallDetails, tmpDir string) string {
+	log.Debug().Str("install_id", installID).Str("cache_url", r.softwareInstallerCacheURL).Msg("about to download software installer from cache")
+	installerPath, err := r.OrbitClient.DownloadSoftwareInstallerFromCache(r.softwareInstallerCacheURL, installer.SoftwareInstallerCacheToken, installer.InstallerID, tmpDir)
+	if err != nil {
+		log.Info().Err(err).Str("install_id", installID).Msg("downloading software installer from cache, falling back to the server")
+		return ""
+	}
+	if err := verifyInstallerHash(installerPath, installer.InstallerSHA256); err != nil {
+		log.Info().Err(err).Str("install_id", installID).Msg("verifying software installer from cache, falling back to the server")
+		if err := os.Remove(installerPath); err != nil {
+			log.Err(err).Msg("removing software installer from cache")
+		}
+		return ""
+	}
+	return installerPath
+}
+
 func (r *Runner) installSoftware(ctx context.Context, installID string) (*fleet.HostSoftwareInstallResultPayload, error) {
 	log.Debug().Msgf("about to install software with installer id: %s", installID)
 	installer, err := r.OrbitClient.GetInstallerDetails(installID)
@@ -248,6 +278,11 @@ func (r *Runner) installSoftware(ctx context.Context, installID string) (*fleet.
 	}()
 
 	var installerPath string
+	// the server only issues a cache token if a cache serves the host
+	if r.softwareInstallerCacheURL != "" && installer.SoftwareInstallerCacheToken != "" && installer.InstallerSHA256 != "" {
+		installerPath = r.downloadInstallerFromCache(installID, installer, tmpDir)
+	}
+
 	// if installer.SoftwareInstallerURL != nil && installer.SoftwareInstallerURL.URL != "" {
 	// 	log.Debug().Str("install_id", installID).Msgf("about to download software installer from URL")
 	// 	installerPath, err = r.OrbitClient.DownloadSoftwareInstallerFromURL(installer.SoftwareInstallerURL.URL,
@@ -267,6 +302,15 @@ func (r *Runner) installSoftware(ctx context.Context, installID string) (*fleet.
 		}
 	}
 
+	// the install script is not run if the downloaded installer isn't the one
+	// that was uploaded
+	if err := verifyInstallerHash(installerPath, installer.InstallerSHA256); err != nil {
+		log.Err(err).Str("install_id", installID).Msg("verifying software installer")
+		payload.InstallScriptExitCode = ptr.Int(-1)
+		payload.InstallScriptOutput = ptr.String(fmt.Sprintf("Installer verification failed: %s. The install script was not run.", err))
+		return payload, err
+	}
+
 	scriptExtension := ".sh"
 	if runtime.GOOS == "windows" {
 		scriptExtension = ".ps1"
@@ -314,6 +358,34 @@ func (r *Runner) installSoftware(ctx context.Context, installID string) (*fleet.
 	return payload, nil
 }
 
+// verifyInstallerHash checks that the SHA-256 of the installer file is the
+// expected hex-encoded hash. Servers that don't send the hash (or installers
+// without a recorded hash) are not verified, but a malformed hash fails the
+// verification.
+func verifyInstallerHash(installerPath, expectedSHA256 string) error {
+	if expectedSHA256 == "" {
+		return nil
+	}
+	if b, err := hex.DecodeString(expectedSHA256); err != nil || len(b) != sha256.Size {
+		return fmt.Errorf("invalid expected SHA-256 %q", expectedSHA256)
+	}
+
+	f, err := os.Open(installerPath)
+	if err != nil {
+		return fmt.Errorf("opening installer: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("reading installer: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, expectedSHA256) {
+		return fmt.Errorf("SHA-256 mismatch (expected %s, got %s)", expectedSHA256, got)
+	}
+	return nil
+}
+
 func (r *Runner) runInstallerScript(ctx context.Context, scriptContents string, installerPath string, fileName string) (string, int, error) {
 	// run script in installer directory
 	installerDir := filepath.Dir(installerPath)
@@ -2,6 +2,8 @@ package installer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -12,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/it-laborato/MDM_Lab/orbit/pkg/constant"
 	"github.com/it-laborato/MDM_Lab/pkg/retry"
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/ptr"
@@ -22,6 +25,7 @@ import (
 
 type TestOrbitClient struct {
 	downloadInstallerFn        func(uint, string) (string, error)
+	downloadFromCacheFn        func(cacheURL string, cacheToken string, installerID uint, downloadDir string) (string, error)
 	downloadInstallerFromURLFn func(url string, filename string, downloadDir string) (string, error)
 	getInstallerDetailsFn      func(string) (*mdmlab.SoftwareInstallDetails, error)
 	saveInstallerResultFn      func(*mdmlab.HostSoftwareInstallResultPayload) error
@@ -35,6 +39,10 @@ func (oc *TestOrbitClient) DownloadSoftwareInstaller(installerID uint, downloadD
 	return oc.downloadInstallerFn(installerID, downloadDir)
 }
 
+func (oc *TestOrbitClient) DownloadSoftwareInstallerFromCache(cacheURL string, cacheToken string, installerID uint, downloadDir string) (string, error) {
+	return oc.downloadFromCacheFn(cacheURL, cacheToken, installerID, downloadDir)
+}
+
 func (oc *TestOrbitClient) GetInstallerDetails(installId string) (*mdmlab.SoftwareInstallDetails, error) {
 	return oc.getInstallerDetailsFn(installId)
 }
@@ -732,3 +740,190 @@ func TestScriptsDisabled(t *testing.T) {
 	require.True(t, getInstallerDetailsFnCalled)
 	require.Equal(t, "1", installIdRequested)
 }
+
+func TestInstallerHashVerification(t *testing.T) {
+	oc := &TestOrbitClient{}
+	qc := &TestQueryClient{}
+
+	content := []byte("installer content")
+	sum := sha256.Sum256(content)
+	installDetails := &mdmlab.SoftwareInstallDetails{
+		ExecutionID:     "exec1",
+		InstallerID:     1337,
+		InstallScript:   "script1",
+		InstallerSHA256: hex.EncodeToString(sum[:]),
+	}
+	oc.getInstallerDetailsFn = func(installID string) (*mdmlab.SoftwareInstallDetails, error) {
+		return installDetails, nil
+	}
+
+	var downloaded []byte
+	oc.downloadInstallerFn = func(installerID uint, downloadDir string) (string, error) {
+		installerPath := filepath.Join(downloadDir, fmt.Sprint(installerID)+".pkg")
+		return installerPath, os.WriteFile(installerPath, downloaded, constant.DefaultFileMode)
+	}
+
+	var executedScripts []string
+	r := &Runner{
+		OrbitClient:    oc,
+		OsqueryClient:  qc,
+		scriptsEnabled: func() bool { return true },
+		execCmdFn: func(ctx context.Context, scriptPath string, env []string) ([]byte, int, error) {
+			executedScripts = append(executedScripts, scriptPath)
+			return []byte("installed"), 0, nil
+		},
+	}
+
+	// the expected installer is installed
+	downloaded = content
+	out, err := r.installSoftware(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Len(t, executedScripts, 1)
+
+	// the install script is not run for another installer
+	executedScripts = nil
+	downloaded = []byte("tampered content")
+	tamperedSum := sha256.Sum256(downloaded)
+	out, err = r.installSoftware(context.Background(), "1")
+	require.ErrorContains(t, err, "SHA-256 mismatch")
+	require.Empty(t, executedScripts)
+	require.Equal(t, ptr.Int(-1), out.InstallScriptExitCode)
+	require.Equal(t, fmt.Sprintf(
+		"Installer verification failed: SHA-256 mismatch (expected %s, got %s). The install script was not run.",
+		installDetails.InstallerSHA256, hex.EncodeToString(tamperedSum[:]),
+	), *out.InstallScriptOutput)
+
+	// the install script is not run if the expected hash is malformed
+	installDetails.InstallerSHA256 = "not-a-sha256"
+	out, err = r.installSoftware(context.Background(), "1")
+	require.ErrorContains(t, err, `invalid expected SHA-256 "not-a-sha256"`)
+	require.Empty(t, executedScripts)
+	require.Equal(t, ptr.Int(-1), out.InstallScriptExitCode)
+
+	// the installer isn't verified if the server doesn't send its hash
+	installDetails.InstallerSHA256 = ""
+	out, err = r.installSoftware(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Len(t, executedScripts, 1)
+}
+
+func TestInstallerDownloadFromCache(t *testing.T) {
+	oc := &TestOrbitClient{}
+	qc := &TestQueryClient{}
+
+	content := []byte("installer content")
+	sum := sha256.Sum256(content)
+	installDetails := &mdmlab.SoftwareInstallDetails{
+		ExecutionID:                 "exec1",
+		InstallerID:                 1337,
+		InstallScript:               "script1",
+		InstallerSHA256:             hex.EncodeToString(sum[:]),
+		SoftwareInstallerCacheToken: "cache-token",
+	}
+	oc.getInstallerDetailsFn = func(installID string) (*mdmlab.SoftwareInstallDetails, error) {
+		return installDetails, nil
+	}
+
+	var serverDownloads int
+	oc.downloadInstallerFn = func(installerID uint, downloadDir string) (string, error) {
+		serverDownloads++
+		installerPath := filepath.Join(downloadDir, fmt.Sprint(installerID)+".pkg")
+		return installerPath, os.WriteFile(installerPath, content, constant.DefaultFileMode)
+	}
+	var (
+		cacheDownloads int
+		cacheContent   []byte
+		cacheErr       error
+	)
+	oc.downloadFromCacheFn = func(cacheURL string, cacheToken string, installerID uint, downloadDir string) (string, error) {
+		cacheDownloads++
+		require.Equal(t, "https://cache.example.com", cacheURL)
+		require.Equal(t, "cache-token", cacheToken)
+		require.Equal(t, installDetails.InstallerID, installerID)
+		if cacheErr != nil {
+			return "", cacheErr
+		}
+		installerPath := filepath.Join(downloadDir, fmt.Sprint(installerID)+".pkg")
+		return installerPath, os.WriteFile(installerPath, cacheContent, constant.DefaultFileMode)
+	}
+
+	var executedScripts int
+	r := &Runner{
+		OrbitClient:    oc,
+		OsqueryClient:  qc,
+		scriptsEnabled: func() bool { return true },
+		execCmdFn: func(ctx context.Context, scriptPath string, env []string) ([]byte, int, error) {
+			executedScripts++
+			return []byte("installed"), 0, nil
+		},
+	}
+
+	reset := func() {
+		serverDownloads, cacheDownloads, executedScripts = 0, 0, 0
+	}
+
+	// no cache configured for the host
+	out, err := r.installSoftware(context.Background(), "exec1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Equal(t, 1, serverDownloads)
+	require.Zero(t, cacheDownloads)
+
+	// the installer is downloaded from the cache
+	reset()
+	r.softwareInstallerCacheURL = "https://cache.example.com"
+	cacheContent = content
+	out, err = r.installSoftware(context.Background(), "exec1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Equal(t, 1, cacheDownloads)
+	require.Zero(t, serverDownloads)
+	require.Equal(t, 1, executedScripts)
+
+	// the cache serves another installer, it is downloaded from the server
+	reset()
+	cacheContent = []byte("tampered content")
+	out, err = r.installSoftware(context.Background(), "exec1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Equal(t, 1, cacheDownloads)
+	require.Equal(t, 1, serverDownloads)
+	require.Equal(t, 1, executedScripts)
+
+	// the cache is unreachable
+	reset()
+	cacheErr = errors.New("connection refused")
+	out, err = r.installSoftware(context.Background(), "exec1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Equal(t, 1, cacheDownloads)
+	require.Equal(t, 1, serverDownloads)
+
+	// the server didn't issue a cache token, the installer is downloaded from
+	// the server
+	reset()
+	installDetails.SoftwareInstallerCacheToken = ""
+	out, err = r.installSoftware(context.Background(), "exec1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Zero(t, cacheDownloads)
+	require.Equal(t, 1, serverDownloads)
+
+	// the installer can't be verified without its hash, it is downloaded from
+	// the server
+	reset()
+	installDetails.SoftwareInstallerCacheToken = "cache-token"
+	installDetails.InstallerSHA256 = ""
+	out, err = r.installSoftware(context.Background(), "exec1")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Int(0), out.InstallScriptExitCode)
+	require.Zero(t, cacheDownloads)
+	require.Equal(t, 1, serverDownloads)
+
+	// the cache URL is set from the config on each run
+	oc.saveInstallerResultFn = func(*mdmlab.HostSoftwareInstallResultPayload) error { return nil }
+	require.NoError(t, r.run(context.Background(), &mdmlab.OrbitConfig{}))
+	require.Empty(t, r.softwareInstallerCacheURL)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/it-laborato/MDM_Lab/orbit/pkg/table/crowdstrike/falconctl"
 	"github.com/it-laborato/MDM_Lab/orbit/pkg/table/cryptsetup"
 	"github.com/it-laborato/MDM_Lab/orbit/pkg/table/dataflattentable"
+	"github.com/it-laborato/MDM_Lab/orbit/pkg/table/universal_packages"
 	"github.com/rs/zerolog/log"
 
 	"github.com/osquery/osquery-go"
@@ -17,6 +18,7 @@ func PlatformTables(_ PluginOpts) ([]osquery.OsqueryPlugin, error) {
 		cryptsetup.TablePlugin(log.Logger),            // table name is "cryptsetup_status"
 		falconctl.NewFalconctlOptionTable(log.Logger), // table name is "falconctl_option"
 		falcon_kernel_check.TablePlugin(log.Logger),   // table name is "falcon_kernel_check"
+		universal_packages.TablePlugin(log.Logger),    // table name is "universal_packages"
 		dataflattentable.TablePluginExec(log.Logger, "nftables", dataflattentable.JsonType, []string{"nft", "-jat", "list", "ruleset"}, dataflattentable.WithBinDirs("/usr/bin", "/usr/sbin")), // -j (json) -a (show object handles) -t (terse, omit set contents)
 	}, nil
 }
@@ -0,0 +1,192 @@
+//go:build linux
+// +build linux
+
+// Package universal_packages implements a table with the snap, flatpak and
+// AppImage packages installed on the host, which are not reported by the
+// osquery package tables.
+package universal_packages
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/orbit/pkg/table/tablehelpers"
+	"github.com/it-laborato/MDM_Lab/pkg/file"
+	"github.com/osquery/osquery-go/plugin/table"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// snapsDir is the directory where snaps are mounted, the current
+	// revision of each snap is in <snapsDir>/<name>/current.
+	snapsDir = "/snap"
+	// flatpakDir is the system-wide flatpak installation.
+	flatpakDir = "/var/lib/flatpak"
+	// appImagesDir is the directory where the default AppImage install
+	// script installs AppImages (see pkg/file/scripts/install_appimage.sh).
+	appImagesDir = "/opt/appimages"
+)
+
+var flatpakPaths = []string{
+	"/usr/bin/flatpak",
+	"/usr/local/bin/flatpak",
+}
+
+type Table struct {
+	logger zerolog.Logger
+	name   string
+}
+
+func TablePlugin(logger zerolog.Logger) *table.Plugin {
+	columns := []table.ColumnDefinition{
+		table.TextColumn("name"),
+		table.TextColumn("version"),
+		table.TextColumn("source"),
+		table.TextColumn("path"),
+	}
+
+	tableName := "universal_packages"
+	t := &Table{
+		name:   tableName,
+		logger: logger.With().Str("table", tableName).Logger(),
+	}
+
+	return table.NewPlugin(tableName, columns, t.generate)
+}
+
+func (t *Table) generate(ctx context.Context, queryContext table.QueryContext) ([]map[string]string, error) {
+	var results []map[string]string
+	results = append(results, t.snaps()...)
+	results = append(results, t.flatpaks(ctx)...)
+	results = append(results, t.appImages()...)
+	return results, nil
+}
+
+func (t *Table) snaps() []map[string]string {
+	matches, err := filepath.Glob(filepath.Join(snapsDir, "*", "current", "meta", "snap.yaml"))
+	if err != nil {
+		t.logger.Info().Err(err).Msg("listing snaps")
+		return nil
+	}
+
+	var results []map[string]string
+	for _, match := range matches {
+		blob, err := os.ReadFile(match)
+		if err != nil {
+			t.logger.Debug().Err(err).Str("path", match).Msg("reading snap.yaml")
+			continue
+		}
+		name, version, ok, err := parseSnapYAML(blob)
+		if err != nil {
+			t.logger.Info().Err(err).Str("path", match).Msg("parsing snap.yaml")
+			continue
+		}
+		if !ok {
+			continue
+		}
+		results = append(results, map[string]string{
+			"name":    name,
+			"version": version,
+			"source":  "snap",
+			"path":    filepath.Dir(filepath.Dir(match)),
+		})
+	}
+	return results
+}
+
+// parseSnapYAML returns the name and version of the snap, ok is false for
+// snaps that are not applications (e.g. bases, kernels or snapd itself).
+func parseSnapYAML(blob []byte) (name, version string, ok bool, err error) {
+	var snapYAML struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+		Type    string `yaml:"type"`
+	}
+	if err := yaml.Unmarshal(blob, &snapYAML); err != nil {
+		return "", "", false, err
+	}
+	if snapYAML.Name == "" {
+		return "", "", false, errors.New("snap.yaml has no name")
+	}
+	if snapYAML.Type != "" && snapYAML.Type != "app" {
+		return "", "", false, nil
+	}
+	return snapYAML.Name, snapYAML.Version, true, nil
+}
+
+func (t *Table) flatpaks(ctx context.Context) []map[string]string {
+	output, err := tablehelpers.Exec(ctx, t.logger, 15, flatpakPaths, []string{"list", "--system", "--app", "--columns=application,version"}, false)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			t.logger.Info().Err(err).Msg("listing flatpaks")
+		}
+		return nil
+	}
+
+	var results []map[string]string
+	for _, app := range parseFlatpakList(output) {
+		results = append(results, map[string]string{
+			"name":    app[0],
+			"version": app[1],
+			"source":  "flatpak",
+			"path":    filepath.Join(flatpakDir, "app", app[0]),
+		})
+	}
+	return results
+}
+
+// parseFlatpakList returns the application IDs and versions of the output of
+// `flatpak list --columns=application,version`.
+func parseFlatpakList(output []byte) [][2]string {
+	var apps [][2]string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		id, version, _ := strings.Cut(scanner.Text(), "\t")
+		id = strings.TrimSpace(id)
+		// the header is only printed when the output is a terminal.
+		if id == "" || id == "Application ID" {
+			continue
+		}
+		apps = append(apps, [2]string{id, strings.TrimSpace(version)})
+	}
+	return apps
+}
+
+func (t *Table) appImages() []map[string]string {
+	matches, err := filepath.Glob(filepath.Join(appImagesDir, "*.AppImage"))
+	if err != nil {
+		t.logger.Info().Err(err).Msg("listing AppImages")
+		return nil
+	}
+
+	var results []map[string]string
+	for _, match := range matches {
+		meta, err := readAppImage(match)
+		if err != nil {
+			t.logger.Info().Err(err).Str("path", match).Msg("reading AppImage")
+			continue
+		}
+		results = append(results, map[string]string{
+			"name":    meta.Name,
+			"version": meta.Version,
+			"source":  "appimage",
+			"path":    match,
+		})
+	}
+	return results
+}
+
+func readAppImage(path string) (*file.InstallerMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return file.ReadAppImageMetadata(f)
+}
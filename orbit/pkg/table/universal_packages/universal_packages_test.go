@@ -0,0 +1,39 @@
+//go:build linux
+// +build linux
+
+package universal_packages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSnapYAML(t *testing.T) {
+	name, version, ok, err := parseSnapYAML([]byte("name: firefox\nversion: 131.0.3-1\ntitle: Firefox\nconfinement: strict\n"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "firefox", name)
+	require.Equal(t, "131.0.3-1", version)
+
+	_, _, ok, err = parseSnapYAML([]byte("name: core22\nversion: '20240904'\ntype: base\n"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, _, err = parseSnapYAML([]byte("version: 1.0\n"))
+	require.Error(t, err)
+}
+
+func TestParseFlatpakList(t *testing.T) {
+	output := "Application ID\tVersion\n" +
+		"org.gnome.Calculator\t47.1\n" +
+		"org.mozilla.firefox\t131.0.3\n" +
+		"com.example.NoVersion\t\n" +
+		"\n"
+	require.Equal(t, [][2]string{
+		{"org.gnome.Calculator", "47.1"},
+		{"org.mozilla.firefox", "131.0.3"},
+		{"com.example.NoVersion", ""},
+	}, parseFlatpakList([]byte(output)))
+	require.Empty(t, parseFlatpakList(nil))
+}
@@ -1,4 +1,5 @@
-// Package automatic_policy generates "trigger policies" from metadata of software packages.
+// Package automatic_policy generates "trigger policies" from metadata of software packages
+// and policies detecting vulnerable software versions.
 package automatic_policy
 
 import (
@@ -14,6 +15,8 @@ type PolicyData struct {
 	Query string
 	// Description is the generated description for the policy.
 	Description string
+	// Resolution is the generated resolution for the policy, if any.
+	Resolution string
 	// Platform is the target platform for the policy.
 	Platform string
 }
@@ -46,7 +49,8 @@ func Generate(metadata InstallerMetadata) (*PolicyData, error) {
 	switch {
 	case metadata.Title == "":
 		return nil, ErrMissingTitle
-	case metadata.Extension != "pkg" && metadata.Extension != "msi" && metadata.Extension != "deb" && metadata.Extension != "rpm":
+	case metadata.Extension != "pkg" && metadata.Extension != "msi" && metadata.Extension != "deb" && metadata.Extension != "rpm" &&
+		universalPackageSources[metadata.Extension] == "":
 		return nil, ErrExtensionNotSupported
 	case metadata.Extension == "pkg" && metadata.BundleIdentifier == "":
 		return nil, ErrMissingBundleIdentifier
@@ -110,7 +114,36 @@ func Generate(metadata InstallerMetadata) (*PolicyData, error) {
 			Platform:    "linux",
 			Description: description,
 		}, nil
+	case "snap", "snapref", "flatpak", "flatpakref", "appimage":
+		return &PolicyData{
+			Name: name,
+			Query: fmt.Sprintf(
+				"SELECT 1 FROM universal_packages WHERE source = '%s' AND name = '%s';",
+				universalPackageSources[metadata.Extension], universalPackageName(metadata),
+			),
+			Platform:    "linux",
+			Description: description,
+		}, nil
 	default:
 		return nil, ErrExtensionNotSupported
 	}
 }
+
+// universalPackageSources maps the extensions of snap, flatpak and AppImage packages to the
+// source of the universal_packages table of mdmlabd.
+var universalPackageSources = map[string]string{
+	"snap":       "snap",
+	"snapref":    "snap",
+	"flatpak":    "flatpak",
+	"flatpakref": "flatpak",
+	"appimage":   "appimage",
+}
+
+// universalPackageName returns the name of the package in the universal_packages table, the
+// snap name and flatpak application ID are the package IDs of the installers.
+func universalPackageName(metadata InstallerMetadata) string {
+	if metadata.Extension != "appimage" && len(metadata.PackageIDs) > 0 && metadata.PackageIDs[0] != "" {
+		return metadata.PackageIDs[0]
+	}
+	return metadata.Title
+}
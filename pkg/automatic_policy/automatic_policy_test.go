@@ -105,4 +105,24 @@ Software won't be installed on Linux hosts with Debian-based distributions becau
 ) OR EXISTS (
 	SELECT 1 FROM rpm_packages WHERE name = 'Barzoo'
 );`, policyData.Query)
+
+	policyData, err = Generate(InstallerMetadata{
+		Title:      "org.mozilla.firefox",
+		Extension:  "flatpakref",
+		PackageIDs: []string{"org.mozilla.firefox"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "[Install software] org.mozilla.firefox (flatpakref)", policyData.Name)
+	require.Equal(t, "Policy triggers automatic install of org.mozilla.firefox on each host that's missing this software.", policyData.Description)
+	require.Equal(t, "linux", policyData.Platform)
+	require.Equal(t, "SELECT 1 FROM universal_packages WHERE source = 'flatpak' AND name = 'org.mozilla.firefox';", policyData.Query)
+
+	policyData, err = Generate(InstallerMetadata{
+		Title:      "Obsidian",
+		Extension:  "appimage",
+		PackageIDs: []string{"obsidian"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "linux", policyData.Platform)
+	require.Equal(t, "SELECT 1 FROM universal_packages WHERE source = 'appimage' AND name = 'Obsidian';", policyData.Query)
 }
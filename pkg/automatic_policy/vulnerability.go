@@ -0,0 +1,116 @@
+package automatic_policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VulnerableSoftwareMetadata contains the metadata of a vulnerable software title used to generate
+// a policy that detects its vulnerable versions.
+type VulnerableSoftwareMetadata struct {
+	// CVE is the vulnerability detected by the policy.
+	CVE string
+	// Title is the name of the software title.
+	Title string
+	// Source is the osquery source of the software title, e.g. "apps" or "programs".
+	Source string
+	// BundleIdentifier is the bundle identifier of "apps" software titles, if known.
+	BundleIdentifier string
+	// Versions are the vulnerable versions of the software title.
+	Versions []string
+	// FixedVersion is the version that fixes the vulnerability, if known.
+	FixedVersion string
+}
+
+// vulnerableSoftwareTable describes how to query the osquery table of a software source.
+type vulnerableSoftwareTable struct {
+	// from is the FROM clause of the query.
+	from          string
+	versionColumn string
+	platform      string
+}
+
+var vulnerableSoftwareTables = map[string]vulnerableSoftwareTable{
+	"apps":              {from: "apps", versionColumn: "bundle_short_version", platform: "darwin"},
+	"homebrew_packages": {from: "homebrew_packages", versionColumn: "version", platform: "darwin"},
+	"programs":          {from: "programs", versionColumn: "version", platform: "windows"},
+	"deb_packages":      {from: "deb_packages", versionColumn: "version", platform: "linux"},
+	"rpm_packages":      {from: "rpm_packages", versionColumn: "version", platform: "linux"},
+	"python_packages":   {from: "python_packages", versionColumn: "version"},
+	"npm_packages":      {from: "npm_packages", versionColumn: "version"},
+	"chrome_extensions": {from: "users CROSS JOIN chrome_extensions USING (uid)", versionColumn: "version"},
+	"firefox_addons":    {from: "users CROSS JOIN firefox_addons USING (uid)", versionColumn: "version"},
+}
+
+var (
+	// ErrSourceNotSupported is returned if the software source is not supported to generate vulnerability policies.
+	ErrSourceNotSupported = errors.New("software source not supported")
+	// ErrMissingVersions is returned if no vulnerable version was provided.
+	ErrMissingVersions = errors.New("missing vulnerable versions")
+	// ErrMissingCVE is returned if the CVE was not provided.
+	ErrMissingCVE = errors.New("missing CVE")
+)
+
+// GenerateVulnerabilityDetection generates a policy that fails on the hosts that have one of the
+// vulnerable versions of a software title installed.
+func GenerateVulnerabilityDetection(metadata VulnerableSoftwareMetadata) (*PolicyData, error) {
+	table, ok := vulnerableSoftwareTables[metadata.Source]
+	switch {
+	case metadata.CVE == "":
+		return nil, ErrMissingCVE
+	case metadata.Title == "":
+		return nil, ErrMissingTitle
+	case !ok:
+		return nil, ErrSourceNotSupported
+	}
+
+	versions := make([]string, 0, len(metadata.Versions))
+	seen := make(map[string]bool, len(metadata.Versions))
+	for _, v := range metadata.Versions {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		versions = append(versions, v)
+	}
+	if len(versions) == 0 {
+		return nil, ErrMissingVersions
+	}
+	sort.Strings(versions)
+
+	quoted := make([]string, 0, len(versions))
+	for _, v := range versions {
+		quoted = append(quoted, quoteString(v))
+	}
+
+	nameCondition := fmt.Sprintf("name = %s", quoteString(metadata.Title))
+	if metadata.Source == "apps" && metadata.BundleIdentifier != "" {
+		nameCondition = fmt.Sprintf("bundle_identifier = %s", quoteString(metadata.BundleIdentifier))
+	}
+
+	description := fmt.Sprintf(
+		"Policy fails on each host that has a version of %s (%s) vulnerable to %s installed.\nVulnerable versions: %s.",
+		metadata.Title, metadata.Source, metadata.CVE, strings.Join(versions, ", "),
+	)
+	resolution := fmt.Sprintf("Update %s to a version that is not vulnerable to %s.", metadata.Title, metadata.CVE)
+	if metadata.FixedVersion != "" {
+		resolution = fmt.Sprintf("Update %s to version %s or later.", metadata.Title, metadata.FixedVersion)
+	}
+
+	return &PolicyData{
+		Name: fmt.Sprintf("[Vulnerability] %s %s (%s)", metadata.CVE, metadata.Title, metadata.Source),
+		// The policy passes when none of the vulnerable versions is installed.
+		Query: fmt.Sprintf(`SELECT 1 WHERE NOT EXISTS (
+	SELECT 1 FROM %s WHERE %s AND %s IN (%s)
+);`, table.from, nameCondition, table.versionColumn, strings.Join(quoted, ", ")),
+		Description: description,
+		Resolution:  resolution,
+		Platform:    table.platform,
+	}, nil
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
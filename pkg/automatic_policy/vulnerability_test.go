@@ -0,0 +1,83 @@
+package automatic_policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateVulnerabilityDetectionErrors(t *testing.T) {
+	_, err := GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		Title:    "Foobar",
+		Source:   "apps",
+		Versions: []string{"1.0"},
+	})
+	require.ErrorIs(t, err, ErrMissingCVE)
+
+	_, err = GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		CVE:      "CVE-2024-0001",
+		Source:   "apps",
+		Versions: []string{"1.0"},
+	})
+	require.ErrorIs(t, err, ErrMissingTitle)
+
+	_, err = GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		CVE:      "CVE-2024-0001",
+		Title:    "Foobar",
+		Source:   "ios_apps",
+		Versions: []string{"1.0"},
+	})
+	require.ErrorIs(t, err, ErrSourceNotSupported)
+
+	_, err = GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		CVE:      "CVE-2024-0001",
+		Title:    "Foobar",
+		Source:   "programs",
+		Versions: []string{""},
+	})
+	require.ErrorIs(t, err, ErrMissingVersions)
+}
+
+func TestGenerateVulnerabilityDetection(t *testing.T) {
+	policyData, err := GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		CVE:              "CVE-2024-0001",
+		Title:            "Foobar.app",
+		Source:           "apps",
+		BundleIdentifier: "com.foo.bar",
+		Versions:         []string{"1.1", "1.0", "1.1"},
+		FixedVersion:     "1.2",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "[Vulnerability] CVE-2024-0001 Foobar.app (apps)", policyData.Name)
+	require.Equal(t, "darwin", policyData.Platform)
+	require.Equal(t, `SELECT 1 WHERE NOT EXISTS (
+	SELECT 1 FROM apps WHERE bundle_identifier = 'com.foo.bar' AND bundle_short_version IN ('1.0', '1.1')
+);`, policyData.Query)
+	require.Equal(t, "Policy fails on each host that has a version of Foobar.app (apps) vulnerable to CVE-2024-0001 installed.\nVulnerable versions: 1.0, 1.1.", policyData.Description)
+	require.Equal(t, "Update Foobar.app to version 1.2 or later.", policyData.Resolution)
+
+	policyData, err = GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		CVE:      "CVE-2024-0001",
+		Title:    "Foo's Bar",
+		Source:   "programs",
+		Versions: []string{"2.0'"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "windows", policyData.Platform)
+	require.Equal(t, `SELECT 1 WHERE NOT EXISTS (
+	SELECT 1 FROM programs WHERE name = 'Foo''s Bar' AND version IN ('2.0''')
+);`, policyData.Query)
+	require.Equal(t, "Update Foo's Bar to a version that is not vulnerable to CVE-2024-0001.", policyData.Resolution)
+
+	policyData, err = GenerateVulnerabilityDetection(VulnerableSoftwareMetadata{
+		CVE:      "CVE-2024-0001",
+		Title:    "uBlock Origin",
+		Source:   "chrome_extensions",
+		Versions: []string{"1.0"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, policyData.Platform)
+	require.Equal(t, `SELECT 1 WHERE NOT EXISTS (
+	SELECT 1 FROM users CROSS JOIN chrome_extensions USING (uid) WHERE name = 'uBlock Origin' AND version IN ('1.0')
+);`, policyData.Query)
+}
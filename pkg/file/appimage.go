@@ -0,0 +1,119 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// ExtractAppImageMetadata extracts the name and version metadata from an
+// .AppImage file, an ELF runtime followed by a squashfs image of the
+// application with its desktop entry at the root.
+func ExtractAppImageMetadata(tfr *mdmlab.TempFileReader) (*InstallerMetadata, error) {
+	// compute its hash
+	h := sha256.New()
+	_, _ = io.Copy(h, tfr) // writes to a hash cannot fail
+
+	if err := tfr.Rewind(); err != nil {
+		return nil, err
+	}
+
+	meta, err := ReadAppImageMetadata(tfr)
+	if err != nil {
+		return nil, err
+	}
+	meta.SHASum = h.Sum(nil)
+	return meta, nil
+}
+
+// ReadAppImageMetadata reads the name and version of an AppImage from the
+// desktop entry at the root of its squashfs image. The package ID is the
+// desktop file ID of the application (the name of the desktop entry without
+// the .desktop extension).
+func ReadAppImageMetadata(r io.ReaderAt) (*InstallerMetadata, error) {
+	offset, err := elfSize(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading AppImage runtime: %w", err)
+	}
+	sqfs, err := newSquashfs(io.NewSectionReader(r, offset, 1<<62))
+	if err != nil {
+		return nil, fmt.Errorf("reading AppImage: %w", err)
+	}
+
+	names, err := sqfs.readDirNames("/")
+	if err != nil {
+		return nil, fmt.Errorf("reading AppImage root: %w", err)
+	}
+	for _, name := range names {
+		if path.Ext(name) != ".desktop" {
+			continue
+		}
+		blob, err := sqfs.readFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading AppImage desktop entry: %w", err)
+		}
+		entries, err := parseKeyFile(bytes.NewReader(blob), "Desktop Entry")
+		if err != nil {
+			return nil, fmt.Errorf("parsing AppImage desktop entry: %w", err)
+		}
+		id := strings.TrimSuffix(name, ".desktop")
+		title := entries["Name"]
+		if title == "" {
+			title = id
+		}
+		return &InstallerMetadata{
+			Name:       title,
+			Version:    entries["X-AppImage-Version"],
+			PackageIDs: []string{id},
+		}, nil
+	}
+	return nil, errors.New("AppImage has no desktop entry")
+}
+
+// elfSize returns the size of the ELF file at the start of r, which is the
+// end of its section header table (the last part of an ELF file).
+func elfSize(r io.ReaderAt) (int64, error) {
+	var ident [16]byte
+	if _, err := r.ReadAt(ident[:], 0); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(ident[:4], []byte("\x7fELF")) {
+		return 0, errors.New("invalid ELF magic")
+	}
+
+	var bo binary.ByteOrder
+	switch ident[5] {
+	case 1:
+		bo = binary.LittleEndian
+	case 2:
+		bo = binary.BigEndian
+	default:
+		return 0, fmt.Errorf("invalid ELF data encoding %d", ident[5])
+	}
+
+	switch ident[4] {
+	case 1: // 32-bit
+		var hdr [0x34]byte
+		if _, err := r.ReadAt(hdr[:], 0); err != nil {
+			return 0, err
+		}
+		shoff := int64(bo.Uint32(hdr[0x20:]))
+		return shoff + int64(bo.Uint16(hdr[0x2e:]))*int64(bo.Uint16(hdr[0x30:])), nil
+	case 2: // 64-bit
+		var hdr [0x40]byte
+		if _, err := r.ReadAt(hdr[:], 0); err != nil {
+			return 0, err
+		}
+		shoff := int64(bo.Uint64(hdr[0x28:])) //nolint:gosec // dismiss G115, offsets fit in int64
+		return shoff + int64(bo.Uint16(hdr[0x3a:]))*int64(bo.Uint16(hdr[0x3c:])), nil
+	default:
+		return 0, fmt.Errorf("invalid ELF class %d", ident[4])
+	}
+}
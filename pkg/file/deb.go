@@ -14,8 +14,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/blakesmith/ar"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/klauspost/compress/zstd"
+	"github.com/sassoftware/relic/v8/lib/pgptools"
+	"github.com/sassoftware/relic/v8/lib/signdeb"
 	"github.com/xi2/xz"
 )
 
@@ -162,3 +166,90 @@ func parseControl(r io.Reader, ext string) (name, version string, err error) {
 	}
 	return name, version, nil
 }
+
+// verifyDebSignature verifies the GPG signature of a .deb file with the keys
+// of the keyring. Two formats of signatures are supported: the detached
+// signature of the debsigs tool in the _gpgorigin member, over the contents
+// of the other members, and the clearsigned digests of the members of the
+// dpkg-sig tool in the _gpgbuilder member.
+func verifyDebSignature(tfr *mdmlab.TempFileReader, keyring openpgp.EntityList) (*mdmlab.SoftwareInstallerSignature, error) {
+	invalid := &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}
+
+	var originSig []byte
+	var hasBuilderSig bool
+	rr := ar.NewReader(tfr)
+	for {
+		hdr, err := rr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return invalid, nil
+		}
+
+		switch filename := path.Clean(hdr.Name); {
+		case filename == "_gpgorigin":
+			if originSig, err = io.ReadAll(rr); err != nil {
+				return nil, fmt.Errorf("reading deb signature: %w", err)
+			}
+		case strings.HasPrefix(filename, "_gpg"):
+			hasBuilderSig = true
+		}
+	}
+	if err := tfr.Rewind(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case originSig != nil:
+		return verifyGPGSignature(&debSignedContent{rr: ar.NewReader(tfr)}, originSig, keyring), nil
+
+	case hasBuilderSig:
+		sigs, err := signdeb.Verify(tfr, keyring, false)
+		var errNoKey pgptools.ErrNoKey
+		switch {
+		case errors.As(err, &errNoKey):
+			return &mdmlab.SoftwareInstallerSignature{
+				SignatureStatus: mdmlab.SoftwareInstallerSignatureUntrusted,
+				Signer:          fmt.Sprintf("%016X", uint64(errNoKey)),
+			}, nil
+		case err != nil:
+			return invalid, nil
+		}
+		sig := &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureValid}
+		for _, s := range sigs {
+			if id := s.Key.Entity.PrimaryIdentity(); id != nil {
+				sig.Signer = id.Name
+			}
+			break
+		}
+		return sig, nil
+
+	default:
+		return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}, nil
+	}
+}
+
+// debSignedContent reads the contents signed by debsigs: the concatenated
+// contents of the members of the .deb that are not signatures.
+type debSignedContent struct {
+	rr       *ar.Reader
+	inMember bool
+}
+
+func (c *debSignedContent) Read(p []byte) (int, error) {
+	for {
+		if c.inMember {
+			n, err := c.rr.Read(p)
+			if err != io.EOF || n > 0 {
+				return n, err
+			}
+			c.inMember = false
+		}
+
+		hdr, err := c.rr.Next()
+		if err != nil {
+			return 0, err
+		}
+		c.inMember = !strings.HasPrefix(path.Clean(hdr.Name), "_gpg")
+	}
+}
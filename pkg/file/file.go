@@ -54,6 +54,16 @@ func ExtractInstallerMetadata(tfr *mdmlab.TempFileReader) (*InstallerMetadata, e
 		meta, err = ExtractXARMetadata(tfr)
 	case "msi":
 		meta, err = ExtractMSIMetadata(tfr)
+	case "snap":
+		meta, err = ExtractSnapMetadata(tfr)
+	case "snapref":
+		meta, err = ExtractSnapRefMetadata(tfr)
+	case "flatpak":
+		meta, err = ExtractFlatpakMetadata(tfr)
+	case "flatpakref":
+		meta, err = ExtractFlatpakRefMetadata(tfr)
+	case "appimage":
+		meta, err = ExtractAppImageMetadata(tfr)
 	default:
 		return nil, ErrUnsupportedType
 	}
@@ -77,6 +87,22 @@ func typeFromBytes(br *bufio.Reader) (string, error) {
 		return "rpm", nil
 	case hasPrefix(br, []byte{0xd0, 0xcf}):
 		return "msi", nil
+	case hasPrefix(br, []byte("hsqs")):
+		// snaps are squashfs images, the only squashfs images supported.
+		return "snap", nil
+	case hasPrefix(br, []byte("flatpak\x00\x01\x00\x89\xe5")), hasPrefix(br, []byte("xdg-app\x00\x01\x00\x89\xe5")):
+		return "flatpak", nil
+	case hasPrefix(br, []byte("[Flatpak Ref]")):
+		return "flatpakref", nil
+	case hasPrefix(br, []byte("[Snap Ref]")):
+		return "snapref", nil
+	case hasPrefix(br, []byte("\x7fELF")):
+		// type 2 AppImages have the "AI\x02" magic in the padding of the ELF
+		// identification.
+		if blob, _ := br.Peek(11); len(blob) == 11 && bytes.Equal(blob[8:11], []byte("AI\x02")) {
+			return "appimage", nil
+		}
+		return "", ErrUnsupportedType
 	case hasPrefix(br, []byte("MZ")):
 		if blob, _ := br.Peek(0x3e); len(blob) == 0x3e {
 			reloc := binary.LittleEndian.Uint16(blob[0x3c:0x3e])
@@ -0,0 +1,154 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// flatpakHeaderLength is the length of the beginning of a flatpak bundle that
+// is searched for the metadata of the application, the metadata is stored
+// before the content of the application.
+const flatpakHeaderLength = 1024 * 1024
+
+var (
+	// flatpakRefRegex matches the ref of the bundled application or runtime,
+	// e.g. "app/org.mozilla.firefox/x86_64/stable".
+	flatpakRefRegex = regexp.MustCompile(`(?:app|runtime)/([A-Za-z_][\w-]*(?:\.[A-Za-z_][\w-]*){2,})/(\w+)/([\w.-]+)\x00`)
+	// flatpakReleaseRegex matches the latest release in the AppStream data of
+	// the application, releases are sorted from newest to oldest.
+	flatpakReleaseRegex = regexp.MustCompile(`<release\s[^>]*?version="([^"]+)"`)
+)
+
+// ExtractFlatpakMetadata extracts the application ID and version metadata
+// from a .flatpak file, a single-file bundle of an application created with
+// "flatpak build-bundle". The bundle is an OSTree static delta whose header
+// contains the ref of the application and its compressed AppStream data.
+func ExtractFlatpakMetadata(r io.Reader) (*InstallerMetadata, error) {
+	h := sha256.New()
+	r = io.TeeReader(r, h)
+
+	header := make([]byte, flatpakHeaderLength)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("reading flatpak header: %w", err)
+	}
+	header = header[:n]
+
+	// ensure the whole file is read to get the correct hash
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, fmt.Errorf("failed to read all content: %w", err)
+	}
+
+	m := flatpakRefRegex.FindSubmatchIndex(header)
+	if m == nil {
+		return nil, errors.New("flatpak bundle has no ref")
+	}
+	id := string(header[m[2]:m[3]])
+	// use the branch as version if the bundle has no AppStream data with
+	// releases.
+	version := string(header[m[6]:m[7]])
+	if v := flatpakAppDataVersion(header[m[1]:]); v != "" {
+		version = v
+	}
+
+	return &InstallerMetadata{
+		Name:       id,
+		Version:    version,
+		PackageIDs: []string{id},
+		SHASum:     h.Sum(nil),
+	}, nil
+}
+
+// flatpakAppDataVersion returns the version of the latest release of the
+// gzip-compressed AppStream data found in b, if any.
+func flatpakAppDataVersion(b []byte) string {
+	const maxAttempts = 16
+	gzipMagic := []byte{0x1f, 0x8b, 0x08}
+	for i := 0; i < maxAttempts; i++ {
+		idx := bytes.Index(b, gzipMagic)
+		if idx < 0 {
+			return ""
+		}
+		b = b[idx:]
+		if gz, err := gzip.NewReader(bytes.NewReader(b)); err == nil {
+			gz.Multistream(false)
+			xml, _ := io.ReadAll(io.LimitReader(gz, flatpakHeaderLength))
+			if m := flatpakReleaseRegex.FindSubmatch(xml); m != nil {
+				return string(m[1])
+			}
+		}
+		b = b[len(gzipMagic):]
+	}
+	return ""
+}
+
+// ExtractFlatpakRefMetadata extracts the application ID from a .flatpakref
+// file, a key file referencing an application of a remote repository (e.g.
+// Flathub). The application is installed from the remote, so there is no
+// version.
+func ExtractFlatpakRefMetadata(r io.Reader) (*InstallerMetadata, error) {
+	h := sha256.New()
+	r = io.TeeReader(r, h)
+
+	entries, err := parseKeyFile(r, "Flatpak Ref")
+	if err != nil {
+		return nil, fmt.Errorf("parsing flatpakref: %w", err)
+	}
+	id := entries["Name"]
+	if id == "" {
+		return nil, errors.New("flatpakref has no Name")
+	}
+
+	// ensure the whole file is read to get the correct hash
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, fmt.Errorf("failed to read all content: %w", err)
+	}
+	return &InstallerMetadata{
+		Name:       id,
+		PackageIDs: []string{id},
+		SHASum:     h.Sum(nil),
+	}, nil
+}
+
+// parseKeyFile returns the entries of the group of a key file in the format
+// of the freedesktop.org Desktop Entry specification, used by .flatpakref
+// and .desktop files.
+func parseKeyFile(r io.Reader, group string) (map[string]string, error) {
+	var found, inGroup bool
+	entries := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			inGroup = line[1:len(line)-1] == group
+			found = found || inGroup
+		case inGroup:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key = strings.TrimSpace(key)
+			if _, ok := entries[key]; !ok {
+				entries[key] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no [%s] group", group)
+	}
+	return entries, nil
+}
@@ -0,0 +1,137 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/orbit/pkg/constant"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFlatpakMetadata(t *testing.T) {
+	var appdata bytes.Buffer
+	gz := gzip.NewWriter(&appdata)
+	_, err := gz.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<components version="0.8">
+  <component type="desktop-application">
+    <id>org.gnome.Calculator</id>
+    <name>Calculator</name>
+    <releases>
+      <release version="47.1" timestamp="1729382400"/>
+      <release version="47.0" timestamp="1726704000"/>
+    </releases>
+  </component>
+</components>`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	// the bundle header is a serialized GVariant, only the parts used to
+	// extract the metadata are relevant.
+	var bundle bytes.Buffer
+	bundle.WriteString("flatpak\x00\x01\x00\x89\xe5")
+	bundle.Write(bytes.Repeat([]byte{0}, 32))
+	bundle.WriteString("ref\x00app/org.gnome.Calculator/x86_64/stable\x00\x00s")
+	bundle.WriteString("\x1f\x8b\x08 not a gzip stream")
+	bundle.WriteString("appdata\x00")
+	bundle.Write(appdata.Bytes())
+	bundle.Write(bytes.Repeat([]byte{0xff}, 4096))
+
+	cases := []struct {
+		desc    string
+		content []byte
+		version string
+		wantErr string
+	}{
+		{"with appdata", bundle.Bytes(), "47.1", ""},
+		{"without appdata", []byte("xdg-app\x00\x01\x00\x89\xe5\x00\x00app/org.gnome.Calculator/x86_64/stable\x00"), "stable", ""},
+		{"without ref", []byte("flatpak\x00\x01\x00\x89\xe5\x00\x00"), "", "flatpak bundle has no ref"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			bundlePath := filepath.Join(t.TempDir(), "calculator.flatpak")
+			require.NoError(t, os.WriteFile(bundlePath, c.content, constant.DefaultFileMode))
+
+			tfr, err := mdmlab.NewKeepFileReader(bundlePath)
+			require.NoError(t, err)
+			t.Cleanup(func() { tfr.Close() })
+			m, err := ExtractInstallerMetadata(tfr)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "flatpak", m.Extension)
+			require.Equal(t, "org.gnome.Calculator", m.Name)
+			require.Equal(t, c.version, m.Version)
+			require.Equal(t, []string{"org.gnome.Calculator"}, m.PackageIDs)
+			require.Equal(t, sha256FilePath(t, bundlePath), m.SHASum)
+		})
+	}
+}
+
+func TestExtractStoreRefMetadata(t *testing.T) {
+	cases := []struct {
+		desc      string
+		content   string
+		extension string
+		name      string
+		wantErr   string
+	}{
+		{
+			desc: "flatpakref",
+			content: `[Flatpak Ref]
+Title=Firefox
+Name=org.mozilla.firefox
+Branch=stable
+Url=https://dl.flathub.org/repo/
+IsRuntime=false
+RuntimeRepo=https://dl.flathub.org/repo/flathub.flatpakrepo
+`,
+			extension: "flatpakref",
+			name:      "org.mozilla.firefox",
+		},
+		{
+			desc:      "flatpakref without name",
+			content:   "[Flatpak Ref]\nTitle=Firefox\n",
+			extension: "flatpakref",
+			wantErr:   "flatpakref has no Name",
+		},
+		{
+			desc:      "snapref",
+			content:   "[Snap Ref]\n# the store name of the snap\nName=firefox\nChannel=esr/stable\n",
+			extension: "snapref",
+			name:      "firefox",
+		},
+		{
+			desc:      "snapref without name",
+			content:   "[Snap Ref]\nChannel=esr/stable\n",
+			extension: "snapref",
+			wantErr:   "snapref has no Name",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			refPath := filepath.Join(t.TempDir(), "app."+c.extension)
+			require.NoError(t, os.WriteFile(refPath, []byte(c.content), constant.DefaultFileMode))
+
+			tfr, err := mdmlab.NewKeepFileReader(refPath)
+			require.NoError(t, err)
+			t.Cleanup(func() { tfr.Close() })
+			m, err := ExtractInstallerMetadata(tfr)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.extension, m.Extension)
+			require.Equal(t, c.name, m.Name)
+			require.Empty(t, m.Version)
+			require.Equal(t, []string{c.name}, m.PackageIDs)
+			require.Equal(t, sha256FilePath(t, refPath), m.SHASum)
+		})
+	}
+}
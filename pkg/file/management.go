@@ -19,6 +19,21 @@ var installDebScript string
 //go:embed scripts/install_rpm.sh
 var installRPMScript string
 
+//go:embed scripts/install_snap.sh
+var installSnapScript string
+
+//go:embed scripts/install_snapref.sh
+var installSnapRefScript string
+
+//go:embed scripts/install_flatpak.sh
+var installFlatpakScript string
+
+//go:embed scripts/install_flatpakref.sh
+var installFlatpakRefScript string
+
+//go:embed scripts/install_appimage.sh
+var installAppImageScript string
+
 // GetInstallScript returns a script that can be used to install the given extension
 func GetInstallScript(extension string) string {
 	switch extension {
@@ -32,6 +47,16 @@ func GetInstallScript(extension string) string {
 		return installPkgScript
 	case "exe":
 		return installExeScript
+	case "snap":
+		return installSnapScript
+	case "snapref":
+		return installSnapRefScript
+	case "flatpak":
+		return installFlatpakScript
+	case "flatpakref":
+		return installFlatpakRefScript
+	case "appimage":
+		return installAppImageScript
 	default:
 		return ""
 	}
@@ -53,7 +78,8 @@ var removeDebScript string
 var removeRPMScript string
 
 // GetRemoveScript returns a script that can be used to remove an
-// installer with the given extension.
+// installer with the given extension. There are no remove scripts for the
+// extensions supported after the uninstall script was introduced.
 func GetRemoveScript(extension string) string {
 	switch extension {
 	case "msi":
@@ -86,6 +112,15 @@ var uninstallDebScript string
 //go:embed scripts/uninstall_rpm.sh
 var uninstallRPMScript string
 
+//go:embed scripts/uninstall_snap.sh
+var uninstallSnapScript string
+
+//go:embed scripts/uninstall_flatpak.sh
+var uninstallFlatpakScript string
+
+//go:embed scripts/uninstall_appimage.sh
+var uninstallAppImageScript string
+
 // GetUninstallScript returns a script that can be used to uninstall a
 // software item with the given extension.
 func GetUninstallScript(extension string) string {
@@ -100,6 +135,12 @@ func GetUninstallScript(extension string) string {
 		return uninstallPkgScript
 	case "exe":
 		return uninstallExeScript
+	case "snap", "snapref":
+		return uninstallSnapScript
+	case "flatpak", "flatpakref":
+		return uninstallFlatpakScript
+	case "appimage":
+		return uninstallAppImageScript
 	default:
 		return ""
 	}
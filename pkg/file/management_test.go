@@ -49,6 +49,26 @@ func TestGetInstallAndRemoveScript(t *testing.T) {
 			"remove":    "./scripts/remove_exe.ps1",
 			"uninstall": "./scripts/uninstall_exe.ps1",
 		},
+		"snap": {
+			"install":   "./scripts/install_snap.sh",
+			"uninstall": "./scripts/uninstall_snap.sh",
+		},
+		"snapref": {
+			"install":   "./scripts/install_snapref.sh",
+			"uninstall": "./scripts/uninstall_snap.sh",
+		},
+		"flatpak": {
+			"install":   "./scripts/install_flatpak.sh",
+			"uninstall": "./scripts/uninstall_flatpak.sh",
+		},
+		"flatpakref": {
+			"install":   "./scripts/install_flatpakref.sh",
+			"uninstall": "./scripts/uninstall_flatpak.sh",
+		},
+		"appimage": {
+			"install":   "./scripts/install_appimage.sh",
+			"uninstall": "./scripts/uninstall_appimage.sh",
+		},
 	}
 
 	for itype, scripts := range scriptsByType {
@@ -56,7 +76,11 @@ func TestGetInstallAndRemoveScript(t *testing.T) {
 		assertGoldenMatches(t, scripts["install"], gotScript, *update)
 
 		gotScript = GetRemoveScript(itype)
-		assertGoldenMatches(t, scripts["remove"], gotScript, *update)
+		if scripts["remove"] == "" {
+			assert.Empty(t, gotScript)
+		} else {
+			assertGoldenMatches(t, scripts["remove"], gotScript, *update)
+		}
 
 		gotScript = GetUninstallScript(itype)
 		assertGoldenMatches(t, scripts["uninstall"], gotScript, *update)
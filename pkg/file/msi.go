@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/sassoftware/relic/v8/lib/authenticode"
 	"github.com/sassoftware/relic/v8/lib/comdoc"
 )
 
@@ -297,3 +298,13 @@ func msiDecodeRune(x rune) rune {
 
 	return '_'
 }
+
+// verifyMSISignature verifies the Authenticode signature of a .msi file, see
+// verifyPESignature.
+func verifyMSISignature(tfr *mdmlab.TempFileReader) (*mdmlab.SoftwareInstallerSignature, error) {
+	sig, err := authenticode.VerifyMSI(tfr, false)
+	if err != nil {
+		return authenticodeSignatureFromError(err), nil
+	}
+	return verifyAuthenticodeSignature(sig.TimestampedSignature), nil
+}
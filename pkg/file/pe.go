@@ -8,6 +8,7 @@ import (
 
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/saferwall/pe"
+	"github.com/sassoftware/relic/v8/lib/authenticode"
 )
 
 // ExtractPEMetadata extracts the name and version metadata from a .exe file in
@@ -120,3 +121,18 @@ func applySpecialCases(meta *InstallerMetadata, resources []map[string]string) *
 	}
 	return meta
 }
+
+// verifyPESignature verifies the Authenticode signature of a .exe file. The
+// signature is valid if the signing certificate chains to a trusted root and
+// is valid for code signing, at the time of the signature if it is
+// timestamped.
+func verifyPESignature(tfr *mdmlab.TempFileReader) (*mdmlab.SoftwareInstallerSignature, error) {
+	sigs, err := authenticode.VerifyPE(tfr, false)
+	if err != nil {
+		return authenticodeSignatureFromError(err), nil
+	}
+	if len(sigs) == 0 {
+		return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}, nil
+	}
+	return verifyAuthenticodeSignature(sigs[0].TimestampedSignature), nil
+}
@@ -1,11 +1,20 @@
 package file
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"strings"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/cavaliergopher/rpm"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 )
 
 func ExtractRPMMetadata(r io.Reader) (*InstallerMetadata, error) {
@@ -31,3 +40,194 @@ func ExtractRPMMetadata(r io.Reader) (*InstallerMetadata, error) {
 		PackageIDs: []string{pkg.Name()},
 	}, nil
 }
+
+const (
+	rpmLeadSize = 96
+	// rpmHeaderIntroSize is the size of the intro of a header structure: the
+	// magic, reserved bytes, the number of index entries and the size of the
+	// data store.
+	rpmHeaderIntroSize = 16
+	rpmIndexEntrySize  = 16
+	// rpmMaxHeaderSize is the maximum size of the data of a header accepted by
+	// rpm.
+	rpmMaxHeaderSize = 256 * 1024 * 1024
+)
+
+// Tags of the GPG signatures in the signature header of an rpm package. The
+// header-only signatures (RSA and DSA) are made over the main header, the
+// legacy signatures (PGP and GPG) over the main header and the payload.
+const (
+	rpmSigTagDSA = 267
+	rpmSigTagRSA = 268
+	rpmSigTagPGP = 1002
+	rpmSigTagGPG = 1005
+)
+
+// Tags of the digest of the payload in the main header, which is covered by
+// the header-only signatures.
+const (
+	rpmTagPayloadDigest     = 5092
+	rpmTagPayloadDigestAlgo = 5093
+)
+
+// Types of the values of the tags of a header structure.
+const (
+	rpmTypeInt32       = 4
+	rpmTypeBin         = 7
+	rpmTypeStringArray = 8
+)
+
+// verifyRPMSignature verifies the GPG signature of an .rpm file with the keys
+// of the keyring, the header-only signature if present. A header-only
+// signature is valid only if the payload matches the digest of the signed
+// header, or if the legacy signature of the header and the payload is valid
+// when the header has no payload digest.
+func verifyRPMSignature(tfr *mdmlab.TempFileReader, keyring openpgp.EntityList) (*mdmlab.SoftwareInstallerSignature, error) {
+	invalid := &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}
+
+	sigTags, sigHeaderSize, err := readRPMHeader(io.NewSectionReader(tfr, rpmLeadSize, rpmMaxHeaderSize))
+	if err != nil {
+		return invalid, nil
+	}
+	// the signature header is padded to a multiple of 8 bytes.
+	mainHeaderOffset := rpmLeadSize + (sigHeaderSize+7)/8*8
+	mainTags, mainHeaderSize, err := readRPMHeader(io.NewSectionReader(tfr, mainHeaderOffset, rpmMaxHeaderSize))
+	if err != nil {
+		return invalid, nil
+	}
+	stat, err := tfr.Stat()
+	if err != nil {
+		return nil, err
+	}
+	payloadOffset := mainHeaderOffset + mainHeaderSize
+	payload := io.NewSectionReader(tfr, payloadOffset, stat.Size()-payloadOffset)
+
+	firstSig := func(tags ...uint32) []byte {
+		for _, tag := range tags {
+			if sig := sigTags[tag].binary(); sig != nil {
+				return sig
+			}
+		}
+		return nil
+	}
+	headerSig, legacySig := firstSig(rpmSigTagRSA, rpmSigTagDSA), firstSig(rpmSigTagPGP, rpmSigTagGPG)
+	wantDigest := mainTags[rpmTagPayloadDigest].firstString()
+
+	switch {
+	case headerSig != nil && wantDigest != "":
+		gotDigest, err := rpmPayloadDigest(payload, mainTags[rpmTagPayloadDigestAlgo].firstInt32())
+		if err != nil || !strings.EqualFold(gotDigest, wantDigest) {
+			return invalid, nil
+		}
+		return verifyGPGSignature(io.NewSectionReader(tfr, mainHeaderOffset, mainHeaderSize), headerSig, keyring), nil
+	case legacySig != nil:
+		return verifyGPGSignature(io.NewSectionReader(tfr, mainHeaderOffset, stat.Size()-mainHeaderOffset), legacySig, keyring), nil
+	case headerSig != nil:
+		// the payload isn't covered by any signature.
+		return invalid, nil
+	}
+	return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}, nil
+}
+
+// rpmPayloadDigest returns the hex digest of the payload with the hash
+// algorithm of the RPMTAG_PAYLOADDIGESTALGO tag.
+func rpmPayloadDigest(payload io.Reader, algo uint32) (string, error) {
+	var h hash.Hash
+	switch algo {
+	case 8:
+		h = sha256.New()
+	case 9:
+		h = sha512.New384()
+	case 10:
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported payload digest algorithm %d", algo)
+	}
+	if _, err := io.Copy(h, payload); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// rpmHeaderEntry is the value of a tag of a header structure, data starts at
+// the value and ends with the data store.
+type rpmHeaderEntry struct {
+	typ   uint32
+	count uint32
+	data  []byte
+}
+
+// binary returns the value of a binary tag, nil if the entry is not a binary
+// tag.
+func (e *rpmHeaderEntry) binary() []byte {
+	if e == nil || e.typ != rpmTypeBin || uint64(e.count) > uint64(len(e.data)) {
+		return nil
+	}
+	return e.data[:e.count]
+}
+
+// firstString returns the first value of a string array tag, "" if the entry
+// is not a string array tag.
+func (e *rpmHeaderEntry) firstString() string {
+	if e == nil || e.typ != rpmTypeStringArray || e.count == 0 {
+		return ""
+	}
+	s, _, ok := bytes.Cut(e.data, []byte{0})
+	if !ok {
+		return ""
+	}
+	return string(s)
+}
+
+// firstInt32 returns the first value of an int32 tag, 0 if the entry is not
+// an int32 tag.
+func (e *rpmHeaderEntry) firstInt32() uint32 {
+	if e == nil || e.typ != rpmTypeInt32 || e.count == 0 || len(e.data) < 4 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(e.data)
+}
+
+// readRPMHeader reads the header structure at the start of r and returns the
+// values of its tags and its size.
+func readRPMHeader(r io.Reader) (map[uint32]*rpmHeaderEntry, int64, error) {
+	var intro struct {
+		Magic    [4]byte
+		Reserved uint32
+		Count    uint32
+		Size     uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &intro); err != nil {
+		return nil, 0, err
+	}
+	if !bytes.Equal(intro.Magic[:], []byte{0x8e, 0xad, 0xe8, 0x01}) {
+		return nil, 0, errors.New("invalid rpm header magic")
+	}
+	if intro.Size > rpmMaxHeaderSize || intro.Count > rpmMaxHeaderSize/rpmIndexEntrySize {
+		return nil, 0, errors.New("rpm header too large")
+	}
+
+	entries := make([]struct {
+		Tag    uint32
+		Type   uint32
+		Offset uint32
+		Count  uint32
+	}, intro.Count)
+	if err := binary.Read(r, binary.BigEndian, entries); err != nil {
+		return nil, 0, err
+	}
+	store := make([]byte, intro.Size)
+	if _, err := io.ReadFull(r, store); err != nil {
+		return nil, 0, err
+	}
+
+	tags := make(map[uint32]*rpmHeaderEntry)
+	for _, e := range entries {
+		if e.Offset > intro.Size {
+			continue
+		}
+		tags[e.Tag] = &rpmHeaderEntry{typ: e.Type, count: e.Count, data: store[e.Offset:]}
+	}
+	size := int64(rpmHeaderIntroSize) + int64(intro.Count)*rpmIndexEntrySize + int64(intro.Size)
+	return tags, size, nil
+}
@@ -0,0 +1,6 @@
+#!/bin/sh
+
+# AppImages are self-contained executables, they are installed in
+# /opt/appimages where they are found by the software inventory.
+mkdir -p /opt/appimages
+install -m 0755 "$INSTALLER_PATH" /opt/appimages/$PACKAGE_ID.AppImage
@@ -0,0 +1,3 @@
+#!/bin/sh
+
+flatpak install --system --noninteractive --assumeyes --bundle "$INSTALLER_PATH"
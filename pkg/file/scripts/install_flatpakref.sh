@@ -0,0 +1,5 @@
+#!/bin/sh
+
+# install the application from the remote repository of the flatpakref (e.g.
+# Flathub), the remote is added if needed.
+flatpak install --system --noninteractive --assumeyes --from "$INSTALLER_PATH"
@@ -0,0 +1,5 @@
+#!/bin/sh
+
+# --dangerous allows installing snaps that are not signed by the Snap Store,
+# --classic is ignored for strictly confined snaps.
+snap install --dangerous --classic "$INSTALLER_PATH"
@@ -0,0 +1,8 @@
+#!/bin/sh
+
+snap_name=$(sed -n 's/^Name=//p' "$INSTALLER_PATH" | head -n 1)
+channel=$(sed -n 's/^Channel=//p' "$INSTALLER_PATH" | head -n 1)
+
+# install the snap from the Snap Store, --classic is ignored for strictly
+# confined snaps.
+snap install --classic --channel="${channel:-latest/stable}" "$snap_name"
@@ -0,0 +1,5 @@
+#!/bin/sh
+
+package_name=$PACKAGE_ID
+
+rm -f /opt/appimages/"$package_name".AppImage
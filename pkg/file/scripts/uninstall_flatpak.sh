@@ -0,0 +1,6 @@
+#!/bin/sh
+
+package_name=$PACKAGE_ID
+
+# Fleet uninstalls app using application ID that's extracted on upload
+flatpak uninstall --system --noninteractive --assumeyes "$package_name"
@@ -0,0 +1,6 @@
+#!/bin/sh
+
+package_name=$PACKAGE_ID
+
+# Fleet uninstalls app using snap name that's extracted on upload
+snap remove --purge "$package_name"
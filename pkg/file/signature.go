@@ -0,0 +1,178 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/sassoftware/relic/v8/lib/pkcs9"
+	"github.com/sassoftware/relic/v8/signers/sigerrors"
+)
+
+// appleRootCert is https://www.apple.com/appleca/AppleIncRootCertificate.cer,
+// the root of the Developer ID certificates.
+//
+//go:embed AppleIncRootCertificate.cer
+var appleRootCert []byte
+
+// appleRoots is the pool of trusted roots for the signature of .pkg files, it
+// can be replaced in tests.
+var appleRoots = func() *x509.CertPool {
+	cert, err := x509.ParseCertificate(appleRootCert)
+	if err != nil {
+		panic(fmt.Errorf("could not parse Apple root cert: %w", err))
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool
+}()
+
+// codeSigningRoots is the pool of trusted roots for the Authenticode
+// signature of .msi and .exe files, nil for the system roots. It can be
+// replaced in tests.
+var codeSigningRoots *x509.CertPool
+
+// ReadGPGKeyRing parses the ASCII-armored GPG public keys used to verify the
+// signature of .deb and .rpm packages.
+func ReadGPGKeyRing(armoredKeys []string) (openpgp.EntityList, error) {
+	var keyring openpgp.EntityList
+	for i, armored := range armoredKeys {
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+		if err != nil {
+			return nil, fmt.Errorf("reading GPG key #%d: %w", i+1, err)
+		}
+		keyring = append(keyring, keys...)
+	}
+	return keyring, nil
+}
+
+// VerifyInstallerSignature verifies the signature of the installer file, the
+// format of the installer is determined based on the magic bytes of the
+// content. The keyring holds the trusted keys for .deb and .rpm packages.
+//
+// A signature that doesn't match the content is reported with the "invalid"
+// status, the error is only set if the file can't be read.
+func VerifyInstallerSignature(tfr *mdmlab.TempFileReader, keyring openpgp.EntityList) (*mdmlab.SoftwareInstallerSignature, error) {
+	br := bufio.NewReader(tfr)
+	extension, err := typeFromBytes(br)
+	if err != nil {
+		return nil, err
+	}
+	if err := tfr.Rewind(); err != nil {
+		return nil, err
+	}
+
+	var sig *mdmlab.SoftwareInstallerSignature
+	switch extension {
+	case "pkg":
+		sig, err = verifyXARSignature(tfr)
+	case "msi":
+		sig, err = verifyMSISignature(tfr)
+	case "exe":
+		sig, err = verifyPESignature(tfr)
+	case "deb":
+		sig, err = verifyDebSignature(tfr, keyring)
+	case "rpm":
+		sig, err = verifyRPMSignature(tfr, keyring)
+	default:
+		sig = &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsupported}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tfr.Rewind(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// verifyCertificateSignature returns the signature information of a
+// signature made with the leaf certificate, which is trusted if its chain is
+// verified with opts.
+func verifyCertificateSignature(leaf *x509.Certificate, opts x509.VerifyOptions) *mdmlab.SoftwareInstallerSignature {
+	sig := &mdmlab.SoftwareInstallerSignature{
+		SignatureStatus: mdmlab.SoftwareInstallerSignatureValid,
+		Signer:          leaf.Subject.CommonName,
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		sig.SignatureStatus = mdmlab.SoftwareInstallerSignatureUntrusted
+	}
+	return sig
+}
+
+// verifyGPGSignature verifies the detached GPG signature of signed with the
+// keys of the keyring.
+func verifyGPGSignature(signed io.Reader, signature []byte, keyring openpgp.EntityList) *mdmlab.SoftwareInstallerSignature {
+	signer, err := openpgp.CheckDetachedSignature(keyring, signed, bytes.NewReader(signature), nil)
+	switch {
+	case err == nil:
+		var identity string
+		if id := signer.PrimaryIdentity(); id != nil {
+			identity = id.Name
+		}
+		return &mdmlab.SoftwareInstallerSignature{
+			SignatureStatus: mdmlab.SoftwareInstallerSignatureValid,
+			Signer:          identity,
+		}
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		return &mdmlab.SoftwareInstallerSignature{
+			SignatureStatus: mdmlab.SoftwareInstallerSignatureUntrusted,
+			Signer:          gpgSignatureIssuer(signature),
+		}
+	default:
+		return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}
+	}
+}
+
+// gpgSignatureIssuer returns the ID of the key that made the GPG signature,
+// the fingerprint if available.
+func gpgSignatureIssuer(signature []byte) string {
+	p, err := packet.Read(bytes.NewReader(signature))
+	if err != nil {
+		return ""
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return ""
+	}
+	switch {
+	case len(sig.IssuerFingerprint) > 0:
+		return fmt.Sprintf("%X", sig.IssuerFingerprint)
+	case sig.IssuerKeyId != nil:
+		return fmt.Sprintf("%016X", *sig.IssuerKeyId)
+	default:
+		return ""
+	}
+}
+
+// verifyAuthenticodeSignature returns the signature information of an
+// Authenticode signature whose digest matches the signed file.
+func verifyAuthenticodeSignature(sig pkcs9.TimestampedSignature) *mdmlab.SoftwareInstallerSignature {
+	info := &mdmlab.SoftwareInstallerSignature{
+		SignatureStatus: mdmlab.SoftwareInstallerSignatureValid,
+		Signer:          sig.Certificate.Subject.CommonName,
+	}
+	if err := sig.VerifyChain(codeSigningRoots, nil, x509.ExtKeyUsageCodeSigning); err != nil {
+		info.SignatureStatus = mdmlab.SoftwareInstallerSignatureUntrusted
+	}
+	return info
+}
+
+// authenticodeSignatureFromError returns the signature information of a file
+// whose Authenticode signature failed to verify with err.
+func authenticodeSignatureFromError(err error) *mdmlab.SoftwareInstallerSignature {
+	if errors.As(err, &sigerrors.NotSignedError{}) {
+		return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}
+	}
+	return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}
+}
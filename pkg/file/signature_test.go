@@ -0,0 +1,444 @@
+package file
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" // nolint:gosec
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/blakesmith/ar"
+	"github.com/it-laborato/MDM_Lab/orbit/pkg/constant"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/stretchr/testify/require"
+)
+
+func verifySignatureOfContent(t *testing.T, name string, content []byte, keyring openpgp.EntityList) *mdmlab.SoftwareInstallerSignature {
+	installerPath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(installerPath, content, constant.DefaultFileMode))
+
+	tfr, err := mdmlab.NewKeepFileReader(installerPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { tfr.Close() })
+
+	sig, err := VerifyInstallerSignature(tfr, keyring)
+	require.NoError(t, err)
+
+	// the reader is rewound for the next use
+	pos, err := tfr.Seek(0, 1)
+	require.NoError(t, err)
+	require.Zero(t, pos)
+	return sig
+}
+
+// xarTamper is the part of a flat package corrupted by buildSignedXAR.
+type xarTamper int
+
+const (
+	xarTamperNone xarTamper = iota
+	xarTamperSignature
+	xarTamperPayload
+)
+
+// buildSignedXAR returns a flat package whose TOC is signed with the key of
+// the leaf certificate, with a Payload file in the heap after the signature.
+func buildSignedXAR(t *testing.T, leafKey *rsa.PrivateKey, certs [][]byte, tamper xarTamper) []byte {
+	var certsXML strings.Builder
+	for _, der := range certs {
+		fmt.Fprintf(&certsXML, "<X509Certificate>%s</X509Certificate>", base64.StdEncoding.EncodeToString(der))
+	}
+	payload := []byte("payload contents")
+	payloadChecksum := sha256.Sum256(payload)
+	tocXML := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<xar>
+ <toc>
+  <checksum style="sha1"><offset>0</offset><size>20</size></checksum>
+  <signature style="RSA"><offset>20</offset><size>%d</size>
+   <KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data>%s</X509Data></KeyInfo>
+  </signature>
+  <file id="1">
+   <name>acme.pkg</name>
+   <type>directory</type>
+   <file id="2">
+    <name>Payload</name>
+    <type>file</type>
+    <data>
+     <length>%[3]d</length><offset>%[4]d</offset><size>%[3]d</size>
+     <encoding style="application/octet-stream"/>
+     <archived-checksum style="sha256">%[5]x</archived-checksum>
+     <extracted-checksum style="sha256">%[5]x</extracted-checksum>
+    </data>
+   </file>
+  </file>
+ </toc>
+</xar>`, leafKey.Size(), certsXML.String(), len(payload), 20+leafKey.Size(), payloadChecksum)
+
+	var toc bytes.Buffer
+	zw := zlib.NewWriter(&toc)
+	_, err := zw.Write([]byte(tocXML))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	checksum := sha1.Sum(toc.Bytes()) // nolint:gosec
+	sig, err := rsa.SignPKCS1v15(rand.Reader, leafKey, crypto.SHA1, checksum[:])
+	require.NoError(t, err)
+	switch tamper {
+	case xarTamperSignature:
+		sig[0] ^= 0xff
+	case xarTamperPayload:
+		payload = []byte("modified contents")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.BigEndian, xarHeader{
+		Magic:            xarMagic,
+		HeaderSize:       xarHeaderSize,
+		Version:          1,
+		CompressedSize:   int64(toc.Len()),
+		UncompressedSize: int64(len(tocXML)),
+		HashType:         hashSHA1,
+	}))
+	buf.Write(toc.Bytes())
+	buf.Write(checksum[:])
+	buf.Write(sig)
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func TestVerifyXARSignature(t *testing.T) {
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rootTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(48 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTmpl, rootTmpl, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	root, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newLeaf := func(commonName string, extensions []pkix.Extension) []byte {
+		tmpl := &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			Subject:         pkix.Name{CommonName: commonName, OrganizationalUnit: []string{"ABCDE12345"}},
+			NotBefore:       time.Now().Add(-24 * time.Hour),
+			NotAfter:        time.Now().Add(24 * time.Hour),
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtraExtensions: extensions,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, root, &leafKey.PublicKey, rootKey)
+		require.NoError(t, err)
+		return der
+	}
+	developerIDLeaf := newLeaf("Developer ID Installer: Acme Inc (ABCDE12345)", []pkix.Extension{
+		{Id: developerIDInstallerOID, Critical: true, Value: []byte{0x05, 0x00}},
+	})
+	otherLeaf := newLeaf("Apple Development: Acme Inc (ABCDE12345)", nil)
+
+	defaultRoots := appleRoots
+	appleRoots = x509.NewCertPool()
+	appleRoots.AddCert(root)
+	t.Cleanup(func() { appleRoots = defaultRoots })
+
+	signed := buildSignedXAR(t, leafKey, [][]byte{developerIDLeaf, rootDER}, xarTamperNone)
+	stapled := bytes.Clone(signed)
+	ticket := bytes.Repeat([]byte{0x42}, 64)
+	stapled = append(stapled, ticket...)
+	stapled = append(stapled, xarStapledTicketMagic...)
+	stapled = binary.LittleEndian.AppendUint16(stapled, 1)
+	stapled = binary.LittleEndian.AppendUint16(stapled, 1)
+	stapled = binary.LittleEndian.AppendUint32(stapled, uint32(len(ticket)))
+	unsigned, err := os.ReadFile("./testdata/unsigned.pkg")
+	require.NoError(t, err)
+
+	developerID := mdmlab.SoftwareInstallerSignature{
+		SignatureStatus: mdmlab.SoftwareInstallerSignatureValid,
+		Signer:          "Developer ID Installer: Acme Inc (ABCDE12345)",
+		SignerTeamID:    "ABCDE12345",
+	}
+	stapledTicket := developerID
+	stapledTicket.HasStapledTicket = true
+
+	cases := []struct {
+		desc    string
+		content []byte
+		want    mdmlab.SoftwareInstallerSignature
+	}{
+		{"developer id", signed, developerID},
+		{"stapled ticket", stapled, stapledTicket},
+		{
+			desc:    "not a developer id certificate",
+			content: buildSignedXAR(t, leafKey, [][]byte{otherLeaf}, xarTamperNone),
+			want: mdmlab.SoftwareInstallerSignature{
+				SignatureStatus: mdmlab.SoftwareInstallerSignatureUntrusted,
+				Signer:          "Apple Development: Acme Inc (ABCDE12345)",
+				SignerTeamID:    "ABCDE12345",
+			},
+		},
+		{"tampered", buildSignedXAR(t, leafKey, [][]byte{developerIDLeaf}, xarTamperSignature), mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}},
+		{"tampered payload", buildSignedXAR(t, leafKey, [][]byte{developerIDLeaf, rootDER}, xarTamperPayload), mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}},
+		{"unsigned", unsigned, mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			sig := verifySignatureOfContent(t, "installer.pkg", c.content, nil)
+			require.Equal(t, c.want, *sig)
+		})
+	}
+
+	t.Run("untrusted root", func(t *testing.T) {
+		appleRoots = defaultRoots
+		t.Cleanup(func() {
+			appleRoots = x509.NewCertPool()
+			appleRoots.AddCert(root)
+		})
+
+		sig := verifySignatureOfContent(t, "installer.pkg", signed, nil)
+		require.Equal(t, mdmlab.SoftwareInstallerSignatureUntrusted, sig.SignatureStatus)
+		require.Equal(t, developerID.Signer, sig.Signer)
+	})
+}
+
+func newTestGPGEntity(t *testing.T, name, email string) *openpgp.Entity {
+	entity, err := openpgp.NewEntity(name, "", email, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	require.NoError(t, err)
+	return entity
+}
+
+func gpgDetachSign(t *testing.T, signer *openpgp.Entity, content []byte) []byte {
+	var sig bytes.Buffer
+	require.NoError(t, openpgp.DetachSign(&sig, signer, bytes.NewReader(content), nil))
+	return sig.Bytes()
+}
+
+func TestVerifyDebSignature(t *testing.T) {
+	signer := newTestGPGEntity(t, "Acme Packaging", "packages@example.com")
+	other := newTestGPGEntity(t, "Other", "other@example.com")
+
+	members := []struct {
+		name    string
+		content []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", []byte("control contents")},
+		{"data.tar.gz", []byte("data contents")},
+	}
+	var signedContent []byte
+	for _, m := range members {
+		signedContent = append(signedContent, m.content...)
+	}
+
+	buildDeb := func(dataContent []byte, sig []byte) []byte {
+		var buf bytes.Buffer
+		w := ar.NewWriter(&buf)
+		require.NoError(t, w.WriteGlobalHeader())
+		for _, m := range members {
+			content := m.content
+			if m.name == "data.tar.gz" && dataContent != nil {
+				content = dataContent
+			}
+			require.NoError(t, w.WriteHeader(&ar.Header{Name: m.name, Size: int64(len(content)), Mode: 0o644}))
+			_, err := w.Write(content)
+			require.NoError(t, err)
+		}
+		if sig != nil {
+			require.NoError(t, w.WriteHeader(&ar.Header{Name: "_gpgorigin", Size: int64(len(sig)), Mode: 0o644}))
+			_, err := w.Write(sig)
+			require.NoError(t, err)
+		}
+		return buf.Bytes()
+	}
+
+	sig := gpgDetachSign(t, signer, signedContent)
+	cases := []struct {
+		desc    string
+		content []byte
+		keyring openpgp.EntityList
+		want    mdmlab.SoftwareInstallerSignature
+	}{
+		{
+			desc:    "trusted key",
+			content: buildDeb(nil, sig),
+			keyring: openpgp.EntityList{other, signer},
+			want:    mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureValid, Signer: "Acme Packaging <packages@example.com>"},
+		},
+		{
+			desc:    "unknown key",
+			content: buildDeb(nil, sig),
+			keyring: openpgp.EntityList{other},
+			want: mdmlab.SoftwareInstallerSignature{
+				SignatureStatus: mdmlab.SoftwareInstallerSignatureUntrusted,
+				Signer:          strings.ToUpper(signer.PrimaryKey.KeyIdString()),
+			},
+		},
+		{
+			desc:    "tampered",
+			content: buildDeb([]byte("modified data contents"), sig),
+			keyring: openpgp.EntityList{signer},
+			want:    mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid},
+		},
+		{
+			desc:    "unsigned",
+			content: buildDeb(nil, nil),
+			keyring: openpgp.EntityList{signer},
+			want:    mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got := verifySignatureOfContent(t, "installer.deb", c.content, c.keyring)
+			if c.want.SignatureStatus == mdmlab.SoftwareInstallerSignatureUntrusted {
+				// the issuer is identified by its fingerprint, which ends with
+				// the key ID.
+				require.Equal(t, c.want.SignatureStatus, got.SignatureStatus)
+				require.True(t, strings.HasSuffix(got.Signer, c.want.Signer), got.Signer)
+				return
+			}
+			require.Equal(t, c.want, *got)
+		})
+	}
+}
+
+func TestVerifyRPMSignature(t *testing.T) {
+	signer := newTestGPGEntity(t, "Acme Packaging", "packages@example.com")
+
+	type rpmTag struct {
+		tag, typ, count uint32
+		value           []byte
+	}
+	// rpmHeader returns a header structure with the tags.
+	rpmHeader := func(tags ...rpmTag) []byte {
+		var buf, store bytes.Buffer
+		buf.Write([]byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0})
+		var size uint32
+		for _, tag := range tags {
+			size += uint32(len(tag.value))
+		}
+		for _, v := range []uint32{uint32(len(tags)), size} {
+			require.NoError(t, binary.Write(&buf, binary.BigEndian, v))
+		}
+		for _, tag := range tags {
+			for _, v := range []uint32{tag.tag, tag.typ, uint32(store.Len()), tag.count} {
+				require.NoError(t, binary.Write(&buf, binary.BigEndian, v))
+			}
+			store.Write(tag.value)
+		}
+		buf.Write(store.Bytes())
+		return buf.Bytes()
+	}
+	binTag := func(tag uint32, value []byte) rpmTag {
+		return rpmTag{tag: tag, typ: rpmTypeBin, count: uint32(len(value)), value: value}
+	}
+	buildRPM := func(sigTags []rpmTag, mainHeader, payload []byte) []byte {
+		lead := make([]byte, rpmLeadSize)
+		copy(lead, []byte{0xed, 0xab, 0xee, 0xdb})
+		sigHeader := rpmHeader(sigTags...)
+		if pad := len(sigHeader) % 8; pad != 0 {
+			sigHeader = append(sigHeader, make([]byte, 8-pad)...)
+		}
+		return bytes.Join([][]byte{lead, sigHeader, mainHeader, payload}, nil)
+	}
+
+	payload := []byte("payload contents")
+	payloadDigest := sha256.Sum256(payload)
+	// the main header has the SHA-256 digest of the payload.
+	mainHeader := rpmHeader(
+		rpmTag{tag: rpmTagPayloadDigestAlgo, typ: rpmTypeInt32, count: 1, value: []byte{0, 0, 0, 8}},
+		binTag(1000, []byte("acme-tools")),
+		rpmTag{tag: rpmTagPayloadDigest, typ: rpmTypeStringArray, count: 1, value: []byte(hex.EncodeToString(payloadDigest[:]) + "\x00")},
+	)
+	noDigestHeader := rpmHeader(binTag(1000, []byte("acme-tools")))
+
+	headerSig := binTag(rpmSigTagRSA, gpgDetachSign(t, signer, mainHeader))
+	noDigestHeaderSig := binTag(rpmSigTagRSA, gpgDetachSign(t, signer, noDigestHeader))
+	legacySig := binTag(rpmSigTagGPG, gpgDetachSign(t, signer, append(bytes.Clone(mainHeader), payload...)))
+	noDigestLegacySig := binTag(rpmSigTagGPG, gpgDetachSign(t, signer, append(bytes.Clone(noDigestHeader), payload...)))
+	valid := mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureValid, Signer: "Acme Packaging <packages@example.com>"}
+	invalid := mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}
+
+	cases := []struct {
+		desc    string
+		content []byte
+		keyring openpgp.EntityList
+		want    mdmlab.SoftwareInstallerSignature
+	}{
+		{"header signature", buildRPM([]rpmTag{headerSig}, mainHeader, payload), openpgp.EntityList{signer}, valid},
+		{"header and payload signature", buildRPM([]rpmTag{legacySig}, mainHeader, payload), openpgp.EntityList{signer}, valid},
+		{"both signatures", buildRPM([]rpmTag{headerSig, legacySig}, mainHeader, payload), openpgp.EntityList{signer}, valid},
+		{"tampered header", buildRPM([]rpmTag{headerSig}, rpmHeader(binTag(1000, []byte("acme-tool2"))), payload), openpgp.EntityList{signer}, invalid},
+		{"tampered payload", buildRPM([]rpmTag{legacySig}, mainHeader, []byte("modified payload")), openpgp.EntityList{signer}, invalid},
+		{"header signature with tampered payload", buildRPM([]rpmTag{headerSig}, mainHeader, []byte("modified payload")), openpgp.EntityList{signer}, invalid},
+		{"header signature without payload digest", buildRPM([]rpmTag{noDigestHeaderSig}, noDigestHeader, payload), openpgp.EntityList{signer}, invalid},
+		{
+			"header signature without payload digest, with header and payload signature",
+			buildRPM([]rpmTag{noDigestHeaderSig, noDigestLegacySig}, noDigestHeader, payload), openpgp.EntityList{signer}, valid,
+		},
+		{
+			"header signature without payload digest, with tampered payload",
+			buildRPM([]rpmTag{noDigestHeaderSig, noDigestLegacySig}, noDigestHeader, []byte("modified payload")), openpgp.EntityList{signer}, invalid,
+		},
+		{"unsigned", buildRPM([]rpmTag{binTag(1004, make([]byte, 16))}, mainHeader, payload), openpgp.EntityList{signer}, mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got := verifySignatureOfContent(t, "installer.rpm", c.content, c.keyring)
+			require.Equal(t, c.want, *got)
+		})
+	}
+
+	t.Run("untrusted key", func(t *testing.T) {
+		got := verifySignatureOfContent(t, "installer.rpm", buildRPM([]rpmTag{headerSig}, mainHeader, payload), nil)
+		require.Equal(t, mdmlab.SoftwareInstallerSignatureUntrusted, got.SignatureStatus)
+		require.True(t, strings.HasSuffix(got.Signer, strings.ToUpper(signer.PrimaryKey.KeyIdString())), got.Signer)
+	})
+}
+
+func TestVerifyUnsupportedSignature(t *testing.T) {
+	sig := verifySignatureOfContent(t, "firefox.snapref", []byte("[Snap Ref]\nName=firefox\n"), nil)
+	require.Equal(t, mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsupported}, *sig)
+}
+
+func TestReadGPGKeyRing(t *testing.T) {
+	entity := newTestGPGEntity(t, "Acme Packaging", "packages@example.com")
+	var armored bytes.Buffer
+	w, err := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	keyring, err := ReadGPGKeyRing(nil)
+	require.NoError(t, err)
+	require.Empty(t, keyring)
+
+	keyring, err = ReadGPGKeyRing([]string{armored.String()})
+	require.NoError(t, err)
+	require.Len(t, keyring, 1)
+	require.Equal(t, entity.PrimaryKey.Fingerprint, keyring[0].PrimaryKey.Fingerprint)
+
+	_, err = ReadGPGKeyRing([]string{armored.String(), "not a key"})
+	require.ErrorContains(t, err, "reading GPG key #2")
+}
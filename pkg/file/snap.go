@@ -0,0 +1,82 @@
+package file
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"gopkg.in/yaml.v2"
+)
+
+// ExtractSnapMetadata extracts the name and version metadata from a .snap
+// file, a squashfs image with the metadata of the snap in meta/snap.yaml.
+func ExtractSnapMetadata(tfr *mdmlab.TempFileReader) (*InstallerMetadata, error) {
+	// compute its hash
+	h := sha256.New()
+	_, _ = io.Copy(h, tfr) // writes to a hash cannot fail
+
+	if err := tfr.Rewind(); err != nil {
+		return nil, err
+	}
+
+	sqfs, err := newSquashfs(tfr)
+	if err != nil {
+		return nil, fmt.Errorf("reading snap: %w", err)
+	}
+	blob, err := sqfs.readFile("meta/snap.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("reading snap.yaml: %w", err)
+	}
+
+	var snapYAML struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(blob, &snapYAML); err != nil {
+		return nil, fmt.Errorf("parsing snap.yaml: %w", err)
+	}
+	if snapYAML.Name == "" {
+		return nil, errors.New("snap.yaml has no name")
+	}
+
+	return &InstallerMetadata{
+		Name:       snapYAML.Name,
+		Version:    snapYAML.Version,
+		PackageIDs: []string{snapYAML.Name},
+		SHASum:     h.Sum(nil),
+	}, nil
+}
+
+// ExtractSnapRefMetadata extracts the name of the snap from a .snapref file,
+// a key file referencing a snap of the Snap Store:
+//
+//	[Snap Ref]
+//	Name=firefox
+//	Channel=latest/stable
+//
+// The snap is installed from the store, so there is no version.
+func ExtractSnapRefMetadata(r io.Reader) (*InstallerMetadata, error) {
+	h := sha256.New()
+	r = io.TeeReader(r, h)
+
+	entries, err := parseKeyFile(r, "Snap Ref")
+	if err != nil {
+		return nil, fmt.Errorf("parsing snapref: %w", err)
+	}
+	name := entries["Name"]
+	if name == "" {
+		return nil, errors.New("snapref has no Name")
+	}
+
+	// ensure the whole file is read to get the correct hash
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, fmt.Errorf("failed to read all content: %w", err)
+	}
+	return &InstallerMetadata{
+		Name:       name,
+		PackageIDs: []string{name},
+		SHASum:     h.Sum(nil),
+	}, nil
+}
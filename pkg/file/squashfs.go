@@ -0,0 +1,441 @@
+package file
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/xi2/xz"
+)
+
+// squashfs implements the minimum of the squashfs 4.0 format to read small
+// files (e.g. the metadata of snap packages and AppImages) from an image. See
+// https://dr-emann.github.io/squashfs/ for a description of the format.
+type squashfs struct {
+	r   io.ReaderAt
+	sb  squashfsSuperblock
+	dec func([]byte) ([]byte, error)
+}
+
+const (
+	squashfsMagic = 0x73717368 // "hsqs"
+
+	squashfsMetadataUncompressed = 0x8000
+	squashfsBlockUncompressed    = 1 << 24
+	squashfsNoFragment           = 0xffffffff
+	squashfsFragmentsPerBlock    = 512
+
+	squashfsBasicDir      = 1
+	squashfsBasicFile     = 2
+	squashfsBasicSymlink  = 3
+	squashfsExtDir        = 8
+	squashfsExtFile       = 9
+	squashfsExtSymlink    = 10
+	squashfsMaxSymlinks   = 8
+	squashfsMaxReadLength = 10 * 1024 * 1024
+)
+
+type squashfsSuperblock struct {
+	Magic               uint32
+	InodeCount          uint32
+	ModificationTime    uint32
+	BlockSize           uint32
+	FragmentEntryCount  uint32
+	CompressionID       uint16
+	BlockLog            uint16
+	Flags               uint16
+	IDCount             uint16
+	VersionMajor        uint16
+	VersionMinor        uint16
+	RootInodeRef        uint64
+	BytesUsed           uint64
+	IDTableStart        uint64
+	XattrIDTableStart   uint64
+	InodeTableStart     uint64
+	DirectoryTableStart uint64
+	FragmentTableStart  uint64
+	ExportTableStart    uint64
+}
+
+type squashfsInode struct {
+	typ uint16
+
+	// directories
+	dirBlockStart uint32
+	dirOffset     uint16
+	dirSize       uint32
+
+	// files
+	blocksStart   uint64
+	fileSize      uint64
+	fragmentIndex uint32
+	fragmentOff   uint32
+	blockSizes    []uint32
+
+	// symlinks
+	target string
+}
+
+func newSquashfs(r io.ReaderAt) (*squashfs, error) {
+	fs := &squashfs{r: r}
+	if err := binary.Read(io.NewSectionReader(r, 0, 96), binary.LittleEndian, &fs.sb); err != nil {
+		return nil, fmt.Errorf("reading squashfs superblock: %w", err)
+	}
+	if fs.sb.Magic != squashfsMagic {
+		return nil, errors.New("invalid squashfs magic")
+	}
+	if fs.sb.VersionMajor != 4 {
+		return nil, fmt.Errorf("unsupported squashfs version %d.%d", fs.sb.VersionMajor, fs.sb.VersionMinor)
+	}
+	if fs.sb.BlockSize < 4096 || fs.sb.BlockSize > 1<<20 || fs.sb.BlockSize&(fs.sb.BlockSize-1) != 0 {
+		return nil, fmt.Errorf("invalid squashfs block size %d", fs.sb.BlockSize)
+	}
+
+	switch fs.sb.CompressionID {
+	case 1:
+		fs.dec = func(b []byte) ([]byte, error) {
+			zr, err := zlib.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			return io.ReadAll(io.LimitReader(zr, squashfsMaxReadLength))
+		}
+	case 4:
+		fs.dec = func(b []byte) ([]byte, error) {
+			xr, err := xz.NewReader(bytes.NewReader(b), 0)
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(io.LimitReader(xr, squashfsMaxReadLength))
+		}
+	case 6:
+		fs.dec = func(b []byte) ([]byte, error) {
+			zr, err := zstd.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			return io.ReadAll(io.LimitReader(zr, squashfsMaxReadLength))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported squashfs compression %d", fs.sb.CompressionID)
+	}
+	return fs, nil
+}
+
+// readMetadataBlock reads the metadata block at pos and returns its
+// uncompressed content and the position of the next block.
+func (fs *squashfs) readMetadataBlock(pos int64) ([]byte, int64, error) {
+	var hdr [2]byte
+	if _, err := fs.r.ReadAt(hdr[:], pos); err != nil {
+		return nil, 0, fmt.Errorf("reading metadata block header: %w", err)
+	}
+	h := binary.LittleEndian.Uint16(hdr[:])
+	size := int64(h &^ squashfsMetadataUncompressed)
+	buf := make([]byte, size)
+	if _, err := fs.r.ReadAt(buf, pos+2); err != nil {
+		return nil, 0, fmt.Errorf("reading metadata block: %w", err)
+	}
+	next := pos + 2 + size
+	if h&squashfsMetadataUncompressed != 0 {
+		return buf, next, nil
+	}
+	data, err := fs.dec(buf)
+	if err != nil {
+		return nil, 0, fmt.Errorf("decompressing metadata block: %w", err)
+	}
+	return data, next, nil
+}
+
+// readMetadata reads n bytes of metadata starting at offset of the metadata
+// block at pos.
+func (fs *squashfs) readMetadata(pos int64, offset int, n int) ([]byte, error) {
+	if n > squashfsMaxReadLength {
+		return nil, errors.New("squashfs metadata too large")
+	}
+	var out []byte
+	for len(out) < n {
+		block, next, err := fs.readMetadataBlock(pos)
+		if err != nil {
+			return nil, err
+		}
+		if offset > len(block) {
+			return nil, errors.New("invalid squashfs metadata offset")
+		}
+		block = block[offset:]
+		if rem := n - len(out); len(block) > rem {
+			block = block[:rem]
+		}
+		out = append(out, block...)
+		if len(block) == 0 && next == pos {
+			return nil, io.ErrUnexpectedEOF
+		}
+		pos, offset = next, 0
+	}
+	return out, nil
+}
+
+func (fs *squashfs) readInode(ref uint64) (*squashfsInode, error) {
+	pos := int64(fs.sb.InodeTableStart) + int64(ref>>16)
+	offset := int(ref & 0xffff)
+
+	// the largest fixed part of an inode is the extended file (16 bytes of
+	// header and 40 bytes), read enough to decode any inode and read the
+	// variable part afterwards.
+	const fixedSize = 56
+	blob, err := fs.readMetadata(pos, offset, fixedSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading inode: %w", err)
+	}
+	le := binary.LittleEndian
+	ino := &squashfsInode{typ: le.Uint16(blob[0:2])}
+	body := blob[16:]
+
+	switch ino.typ {
+	case squashfsBasicDir:
+		ino.dirBlockStart = le.Uint32(body[0:4])
+		ino.dirSize = uint32(le.Uint16(body[8:10]))
+		ino.dirOffset = le.Uint16(body[10:12])
+	case squashfsExtDir:
+		ino.dirSize = le.Uint32(body[4:8])
+		ino.dirBlockStart = le.Uint32(body[8:12])
+		ino.dirOffset = le.Uint16(body[18:20])
+	case squashfsBasicFile, squashfsExtFile:
+		var headerSize int
+		if ino.typ == squashfsBasicFile {
+			ino.blocksStart = uint64(le.Uint32(body[0:4]))
+			ino.fragmentIndex = le.Uint32(body[4:8])
+			ino.fragmentOff = le.Uint32(body[8:12])
+			ino.fileSize = uint64(le.Uint32(body[12:16]))
+			headerSize = 16 + 16
+		} else {
+			ino.blocksStart = le.Uint64(body[0:8])
+			ino.fileSize = le.Uint64(body[8:16])
+			ino.fragmentIndex = le.Uint32(body[28:32])
+			ino.fragmentOff = le.Uint32(body[32:36])
+			headerSize = 16 + 40
+		}
+		if ino.fileSize > squashfsMaxReadLength {
+			return nil, errors.New("squashfs file too large")
+		}
+		blockCount := ino.fileSize / uint64(fs.sb.BlockSize)
+		if ino.fragmentIndex == squashfsNoFragment && ino.fileSize%uint64(fs.sb.BlockSize) != 0 {
+			blockCount++
+		}
+		full, err := fs.readMetadata(pos, offset, headerSize+int(blockCount)*4)
+		if err != nil {
+			return nil, fmt.Errorf("reading file inode block sizes: %w", err)
+		}
+		for i := uint64(0); i < blockCount; i++ {
+			ino.blockSizes = append(ino.blockSizes, le.Uint32(full[headerSize+int(i)*4:]))
+		}
+	case squashfsBasicSymlink, squashfsExtSymlink:
+		targetSize := int(le.Uint32(body[4:8]))
+		full, err := fs.readMetadata(pos, offset, 24+targetSize)
+		if err != nil {
+			return nil, fmt.Errorf("reading symlink inode target: %w", err)
+		}
+		ino.target = string(full[24:])
+	default:
+		// other types (devices, fifos, sockets) are not needed to read files.
+	}
+	return ino, nil
+}
+
+type squashfsDirEntry struct {
+	name     string
+	inodeRef uint64
+}
+
+func (fs *squashfs) readDir(ino *squashfsInode) ([]squashfsDirEntry, error) {
+	if ino.typ != squashfsBasicDir && ino.typ != squashfsExtDir {
+		return nil, errors.New("not a directory")
+	}
+	// the size of the directory includes 3 bytes for the "." and ".." entries
+	// that are not stored.
+	if ino.dirSize <= 3 {
+		return nil, nil
+	}
+	blob, err := fs.readMetadata(int64(fs.sb.DirectoryTableStart)+int64(ino.dirBlockStart), int(ino.dirOffset), int(ino.dirSize-3))
+	if err != nil {
+		return nil, fmt.Errorf("reading directory table: %w", err)
+	}
+
+	le := binary.LittleEndian
+	var entries []squashfsDirEntry
+	for len(blob) >= 12 {
+		count := int(le.Uint32(blob[0:4])) + 1
+		start := le.Uint32(blob[4:8])
+		blob = blob[12:]
+		for i := 0; i < count; i++ {
+			if len(blob) < 8 {
+				return nil, errors.New("truncated directory entry")
+			}
+			offset := le.Uint16(blob[0:2])
+			nameSize := int(le.Uint16(blob[6:8])) + 1
+			if len(blob) < 8+nameSize {
+				return nil, errors.New("truncated directory entry name")
+			}
+			entries = append(entries, squashfsDirEntry{
+				name:     string(blob[8 : 8+nameSize]),
+				inodeRef: uint64(start)<<16 | uint64(offset),
+			})
+			blob = blob[8+nameSize:]
+		}
+	}
+	return entries, nil
+}
+
+// lookup returns the inode of the file at the slash-separated path name,
+// following symbolic links.
+func (fs *squashfs) lookup(name string) (*squashfsInode, error) {
+	return fs.lookupDepth(name, 0)
+}
+
+func (fs *squashfs) lookupDepth(name string, depth int) (*squashfsInode, error) {
+	if depth > squashfsMaxSymlinks {
+		return nil, errors.New("too many levels of symbolic links")
+	}
+	ino, err := fs.readInode(fs.sb.RootInodeRef)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(strings.Trim(path.Clean("/"+name), "/"), "/")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		entries, err := fs.readDir(ino)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", strings.Join(parts[:i], "/"), err)
+		}
+		var found bool
+		for _, e := range entries {
+			if e.name == part {
+				if ino, err = fs.readInode(e.inodeRef); err != nil {
+					return nil, err
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+		}
+		if ino.typ == squashfsBasicSymlink || ino.typ == squashfsExtSymlink {
+			target := ino.target
+			if !path.IsAbs(target) {
+				target = path.Join(strings.Join(parts[:i], "/"), target)
+			}
+			target = path.Join(append([]string{target}, parts[i+1:]...)...)
+			return fs.lookupDepth(target, depth+1)
+		}
+	}
+	return ino, nil
+}
+
+// readDirNames returns the names of the entries of the directory at path
+// name.
+func (fs *squashfs) readDirNames(name string) ([]string, error) {
+	ino, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.readDir(ino)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.name)
+	}
+	return names, nil
+}
+
+// readFile returns the content of the regular file at path name.
+func (fs *squashfs) readFile(name string) ([]byte, error) {
+	ino, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if ino.typ != squashfsBasicFile && ino.typ != squashfsExtFile {
+		return nil, fmt.Errorf("%s is not a regular file", name)
+	}
+
+	out := make([]byte, 0, ino.fileSize)
+	pos := int64(ino.blocksStart)
+	for _, size := range ino.blockSizes {
+		onDisk := int64(size &^ squashfsBlockUncompressed)
+		if onDisk == 0 {
+			// sparse block
+			out = append(out, make([]byte, fs.sb.BlockSize)...)
+			continue
+		}
+		block, err := fs.readDataBlock(pos, size)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, block...)
+		pos += onDisk
+	}
+
+	if ino.fragmentIndex != squashfsNoFragment {
+		frag, err := fs.readFragment(ino.fragmentIndex)
+		if err != nil {
+			return nil, err
+		}
+		tail := int(ino.fileSize % uint64(fs.sb.BlockSize))
+		if int(ino.fragmentOff)+tail > len(frag) {
+			return nil, errors.New("invalid squashfs fragment offset")
+		}
+		out = append(out, frag[ino.fragmentOff:int(ino.fragmentOff)+tail]...)
+	}
+
+	if uint64(len(out)) > ino.fileSize {
+		out = out[:ino.fileSize]
+	}
+	return out, nil
+}
+
+func (fs *squashfs) readDataBlock(pos int64, size uint32) ([]byte, error) {
+	buf := make([]byte, size&^squashfsBlockUncompressed)
+	if _, err := fs.r.ReadAt(buf, pos); err != nil {
+		return nil, fmt.Errorf("reading data block: %w", err)
+	}
+	if size&squashfsBlockUncompressed != 0 {
+		return buf, nil
+	}
+	data, err := fs.dec(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing data block: %w", err)
+	}
+	return data, nil
+}
+
+func (fs *squashfs) readFragment(index uint32) ([]byte, error) {
+	if index >= fs.sb.FragmentEntryCount {
+		return nil, errors.New("invalid squashfs fragment index")
+	}
+	// the fragment table is indexed by the positions of the metadata blocks
+	// holding the fragment entries.
+	var ptr [8]byte
+	if _, err := fs.r.ReadAt(ptr[:], int64(fs.sb.FragmentTableStart)+int64(index/squashfsFragmentsPerBlock)*8); err != nil {
+		return nil, fmt.Errorf("reading fragment table: %w", err)
+	}
+	entry, err := fs.readMetadata(int64(binary.LittleEndian.Uint64(ptr[:])), int(index%squashfsFragmentsPerBlock)*16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("reading fragment entry: %w", err)
+	}
+	start := binary.LittleEndian.Uint64(entry[0:8])
+	size := binary.LittleEndian.Uint32(entry[8:12])
+	return fs.readDataBlock(int64(start), size)
+}
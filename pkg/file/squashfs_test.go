@@ -0,0 +1,387 @@
+package file
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/orbit/pkg/constant"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/stretchr/testify/require"
+)
+
+const testSquashfsBlockSize = 4096
+
+type testSquashfsNode struct {
+	name     string
+	content  []byte // regular files
+	target   string // symlinks
+	children []*testSquashfsNode
+	isDir    bool
+
+	inodeOffset int
+	dirOffset   int
+	dirSize     int
+}
+
+// buildTestSquashfs builds a zlib-compressed squashfs image with the files
+// and symlinks, whose keys are slash-separated paths. The tails of the files
+// are stored in a fragment when useFragments is true.
+func buildTestSquashfs(t *testing.T, files map[string]string, symlinks map[string]string, useFragments bool) []byte {
+	t.Helper()
+
+	root := &testSquashfsNode{isDir: true}
+	add := func(p string, leaf *testSquashfsNode) {
+		parts := strings.Split(p, "/")
+		dir := root
+		for _, part := range parts[:len(parts)-1] {
+			var next *testSquashfsNode
+			for _, c := range dir.children {
+				if c.name == part {
+					next = c
+				}
+			}
+			if next == nil {
+				next = &testSquashfsNode{name: part, isDir: true}
+				dir.children = append(dir.children, next)
+			}
+			dir = next
+		}
+		leaf.name = parts[len(parts)-1]
+		dir.children = append(dir.children, leaf)
+	}
+	for p, content := range files {
+		add(p, &testSquashfsNode{content: []byte(content)})
+	}
+	for p, target := range symlinks {
+		add(p, &testSquashfsNode{target: target})
+	}
+
+	var nodes []*testSquashfsNode
+	var walk func(n *testSquashfsNode)
+	walk = func(n *testSquashfsNode) {
+		sort.Slice(n.children, func(i, j int) bool { return n.children[i].name < n.children[j].name })
+		for _, c := range n.children {
+			walk(c)
+		}
+		nodes = append(nodes, n)
+	}
+	walk(root)
+
+	le := binary.LittleEndian
+	compress := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		_, err := zw.Write(b)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+	metadataBlock := func(b []byte) []byte {
+		require.LessOrEqual(t, len(b), 8192)
+		c := compress(b)
+		return append(le.AppendUint16(nil, uint16(len(c))), c...) //nolint:gosec // dismiss G115
+	}
+
+	// data blocks, the first block of the files starts after the superblock.
+	data := make([]byte, 96)
+	var fragment []byte
+	blocks := make(map[*testSquashfsNode][]uint32)
+	starts := make(map[*testSquashfsNode]int)
+	fragmentOffsets := make(map[*testSquashfsNode]int)
+	for _, n := range nodes {
+		if n.isDir || n.target != "" {
+			continue
+		}
+		starts[n] = len(data)
+		content := n.content
+		for len(content) > 0 {
+			if useFragments && len(content) < testSquashfsBlockSize {
+				fragmentOffsets[n] = len(fragment)
+				fragment = append(fragment, content...)
+				break
+			}
+			chunk := content[:min(len(content), testSquashfsBlockSize)]
+			c := compress(chunk)
+			data = append(data, c...)
+			blocks[n] = append(blocks[n], uint32(len(c))) //nolint:gosec // dismiss G115
+			content = content[len(chunk):]
+		}
+	}
+	fragmentStart := len(data)
+	var fragmentSize uint32
+	if len(fragment) > 0 {
+		// store the fragment uncompressed to cover both kinds of blocks.
+		data = append(data, fragment...)
+		fragmentSize = uint32(len(fragment)) | squashfsBlockUncompressed //nolint:gosec // dismiss G115
+	}
+
+	// directory table layout
+	var dirTableSize int
+	for _, n := range nodes {
+		if !n.isDir {
+			continue
+		}
+		n.dirOffset = dirTableSize
+		n.dirSize = 3
+		if len(n.children) > 0 {
+			n.dirSize += 12
+			for _, c := range n.children {
+				n.dirSize += 8 + len(c.name)
+			}
+		}
+		dirTableSize += n.dirSize - 3
+	}
+
+	// inode table layout
+	var inodeTableSize int
+	for _, n := range nodes {
+		n.inodeOffset = inodeTableSize
+		switch {
+		case n.isDir:
+			inodeTableSize += 32
+		case n.target != "":
+			inodeTableSize += 24 + len(n.target)
+		default:
+			inodeTableSize += 32 + 4*len(blocks[n])
+		}
+	}
+
+	var inodes, dirs []byte
+	for i, n := range nodes {
+		hdr := func(typ uint16) {
+			inodes = le.AppendUint16(inodes, typ)
+			inodes = le.AppendUint16(inodes, 0o755)
+			inodes = le.AppendUint16(inodes, 0)
+			inodes = le.AppendUint16(inodes, 0)
+			inodes = le.AppendUint32(inodes, 0)
+			inodes = le.AppendUint32(inodes, uint32(i+1)) //nolint:gosec // dismiss G115
+		}
+		switch {
+		case n.isDir:
+			hdr(squashfsBasicDir)
+			inodes = le.AppendUint32(inodes, 0)
+			inodes = le.AppendUint32(inodes, 2)
+			inodes = le.AppendUint16(inodes, uint16(n.dirSize))   //nolint:gosec // dismiss G115
+			inodes = le.AppendUint16(inodes, uint16(n.dirOffset)) //nolint:gosec // dismiss G115
+			inodes = le.AppendUint32(inodes, 0)
+
+			if len(n.children) > 0 {
+				dirs = le.AppendUint32(dirs, uint32(len(n.children)-1)) //nolint:gosec // dismiss G115
+				dirs = le.AppendUint32(dirs, 0)
+				dirs = le.AppendUint32(dirs, 1)
+				for _, c := range n.children {
+					typ := uint16(squashfsBasicFile)
+					if c.isDir {
+						typ = squashfsBasicDir
+					} else if c.target != "" {
+						typ = squashfsBasicSymlink
+					}
+					dirs = le.AppendUint16(dirs, uint16(c.inodeOffset)) //nolint:gosec // dismiss G115
+					dirs = le.AppendUint16(dirs, 0)
+					dirs = le.AppendUint16(dirs, typ)
+					dirs = le.AppendUint16(dirs, uint16(len(c.name)-1)) //nolint:gosec // dismiss G115
+					dirs = append(dirs, c.name...)
+				}
+			}
+		case n.target != "":
+			hdr(squashfsBasicSymlink)
+			inodes = le.AppendUint32(inodes, 1)
+			inodes = le.AppendUint32(inodes, uint32(len(n.target))) //nolint:gosec // dismiss G115
+			inodes = append(inodes, n.target...)
+		default:
+			hdr(squashfsBasicFile)
+			inodes = le.AppendUint32(inodes, uint32(starts[n])) //nolint:gosec // dismiss G115
+			if off, ok := fragmentOffsets[n]; ok {
+				inodes = le.AppendUint32(inodes, 0)
+				inodes = le.AppendUint32(inodes, uint32(off)) //nolint:gosec // dismiss G115
+			} else {
+				inodes = le.AppendUint32(inodes, squashfsNoFragment)
+				inodes = le.AppendUint32(inodes, 0)
+			}
+			inodes = le.AppendUint32(inodes, uint32(len(n.content))) //nolint:gosec // dismiss G115
+			for _, size := range blocks[n] {
+				inodes = le.AppendUint32(inodes, size)
+			}
+		}
+	}
+	require.Len(t, inodes, inodeTableSize)
+	require.Len(t, dirs, dirTableSize)
+
+	image := data
+	inodeTableStart := len(image)
+	image = append(image, metadataBlock(inodes)...)
+	dirTableStart := len(image)
+	image = append(image, metadataBlock(dirs)...)
+
+	var fragmentCount uint32
+	fragmentTableStart := len(image)
+	if len(fragment) > 0 {
+		fragmentCount = 1
+		entriesStart := len(image)
+		entry := le.AppendUint64(nil, uint64(fragmentStart)) //nolint:gosec // dismiss G115
+		entry = le.AppendUint32(entry, fragmentSize)
+		entry = le.AppendUint32(entry, 0)
+		image = append(image, metadataBlock(entry)...)
+		fragmentTableStart = len(image)
+		image = le.AppendUint64(image, uint64(entriesStart)) //nolint:gosec // dismiss G115
+	}
+
+	sb := squashfsSuperblock{
+		Magic:               squashfsMagic,
+		InodeCount:          uint32(len(nodes)), //nolint:gosec // dismiss G115
+		BlockSize:           testSquashfsBlockSize,
+		FragmentEntryCount:  fragmentCount,
+		CompressionID:       1,
+		BlockLog:            12,
+		VersionMajor:        4,
+		RootInodeRef:        uint64(root.inodeOffset), //nolint:gosec // dismiss G115
+		BytesUsed:           uint64(len(image)),       //nolint:gosec // dismiss G115
+		IDTableStart:        uint64(len(image)),       //nolint:gosec // dismiss G115
+		XattrIDTableStart:   0xffffffffffffffff,
+		InodeTableStart:     uint64(inodeTableStart),    //nolint:gosec // dismiss G115
+		DirectoryTableStart: uint64(dirTableStart),      //nolint:gosec // dismiss G115
+		FragmentTableStart:  uint64(fragmentTableStart), //nolint:gosec // dismiss G115
+		ExportTableStart:    0xffffffffffffffff,
+	}
+	var sbBuf bytes.Buffer
+	require.NoError(t, binary.Write(&sbBuf, le, sb))
+	copy(image, sbBuf.Bytes())
+	return image
+}
+
+func TestSquashfs(t *testing.T) {
+	large := strings.Repeat("0123456789abcdef", 2*testSquashfsBlockSize/16+10)
+	files := map[string]string{
+		"a.txt":         "a",
+		"dir/b.txt":     "b",
+		"dir/sub/large": large,
+	}
+	symlinks := map[string]string{
+		"link":         "dir/b.txt",
+		"dir/sub/back": "../b.txt",
+		"dirlink":      "/dir/sub",
+	}
+
+	for _, useFragments := range []bool{false, true} {
+		sqfs, err := newSquashfs(bytes.NewReader(buildTestSquashfs(t, files, symlinks, useFragments)))
+		require.NoError(t, err)
+
+		for p, want := range files {
+			got, err := sqfs.readFile(p)
+			require.NoError(t, err, p)
+			require.Equal(t, want, string(got), p)
+		}
+		for p, target := range symlinks {
+			if p == "dirlink" {
+				continue
+			}
+			if !path.IsAbs(target) {
+				target = path.Join(path.Dir(p), target)
+			}
+			got, err := sqfs.readFile(p)
+			require.NoError(t, err, p)
+			require.Equal(t, files[strings.TrimPrefix(target, "/")], string(got), p)
+		}
+
+		got, err := sqfs.readFile("dirlink/large")
+		require.NoError(t, err)
+		require.Equal(t, large, string(got))
+
+		names, err := sqfs.readDirNames("/")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.txt", "dir", "dirlink", "link"}, names)
+
+		_, err = sqfs.readFile("nope")
+		require.ErrorIs(t, err, os.ErrNotExist)
+		_, err = sqfs.readFile("dir")
+		require.Error(t, err)
+	}
+
+	_, err := newSquashfs(bytes.NewReader(make([]byte, 96)))
+	require.ErrorContains(t, err, "invalid squashfs magic")
+}
+
+func TestExtractSnapMetadata(t *testing.T) {
+	image := buildTestSquashfs(t, map[string]string{
+		"meta/snap.yaml": "name: hello-world\nversion: 6.4\nsummary: The 'hello-world' of snaps\nconfinement: strict\n",
+		"bin/echo":       "#!/bin/sh\necho hello\n",
+	}, nil, true)
+	snapPath := filepath.Join(t.TempDir(), "hello-world_6.4.snap")
+	require.NoError(t, os.WriteFile(snapPath, image, constant.DefaultFileMode))
+
+	tfr, err := mdmlab.NewKeepFileReader(snapPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { tfr.Close() })
+	m, err := ExtractInstallerMetadata(tfr)
+	require.NoError(t, err)
+	require.Equal(t, "snap", m.Extension)
+	require.Equal(t, "hello-world", m.Name)
+	require.Equal(t, "6.4", m.Version)
+	require.Equal(t, []string{"hello-world"}, m.PackageIDs)
+	require.Equal(t, sha256FilePath(t, snapPath), m.SHASum)
+
+	// a squashfs image that is not a snap
+	image = buildTestSquashfs(t, map[string]string{"foo": "bar"}, nil, false)
+	notSnapPath := filepath.Join(t.TempDir(), "foo.snap")
+	require.NoError(t, os.WriteFile(notSnapPath, image, constant.DefaultFileMode))
+	tfr, err = mdmlab.NewKeepFileReader(notSnapPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { tfr.Close() })
+	_, err = ExtractInstallerMetadata(tfr)
+	require.ErrorContains(t, err, "reading snap.yaml")
+}
+
+func TestExtractAppImageMetadata(t *testing.T) {
+	// minimal 64-bit ELF header of the AppImage runtime with the section
+	// header table right after it.
+	elf := make([]byte, 128)
+	copy(elf, "\x7fELF\x02\x01\x01\x00AI\x02")
+	binary.LittleEndian.PutUint64(elf[0x28:], 64)
+	binary.LittleEndian.PutUint16(elf[0x3a:], 64)
+	binary.LittleEndian.PutUint16(elf[0x3c:], 1)
+
+	image := buildTestSquashfs(t, map[string]string{
+		"AppRun": "#!/bin/sh\nexec usr/bin/obsidian\n",
+		"usr/share/applications/obsidian.desktop": `[Desktop Entry]
+Name=Obsidian
+Name[fr]=Obsidienne
+Exec=AppRun %U
+Type=Application
+X-AppImage-Version=1.7.7
+
+[Desktop Action new]
+Name=New window
+`,
+	}, map[string]string{
+		"obsidian.desktop": "usr/share/applications/obsidian.desktop",
+	}, true)
+	appImagePath := filepath.Join(t.TempDir(), "Obsidian-1.7.7.AppImage")
+	require.NoError(t, os.WriteFile(appImagePath, append(elf, image...), constant.DefaultFileMode))
+
+	tfr, err := mdmlab.NewKeepFileReader(appImagePath)
+	require.NoError(t, err)
+	t.Cleanup(func() { tfr.Close() })
+	m, err := ExtractInstallerMetadata(tfr)
+	require.NoError(t, err)
+	require.Equal(t, "appimage", m.Extension)
+	require.Equal(t, "Obsidian", m.Name)
+	require.Equal(t, "1.7.7", m.Version)
+	require.Equal(t, []string{"obsidian"}, m.PackageIDs)
+	require.Equal(t, sha256FilePath(t, appImagePath), m.SHASum)
+
+	// an ELF file that is not an AppImage is not supported
+	notAppImagePath := filepath.Join(t.TempDir(), "foo")
+	require.NoError(t, os.WriteFile(notAppImagePath, elf[:8], constant.DefaultFileMode))
+	tfr, err = mdmlab.NewKeepFileReader(notAppImagePath)
+	require.NoError(t, err)
+	t.Cleanup(func() { tfr.Close() })
+	_, err = ExtractInstallerMetadata(tfr)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
@@ -23,13 +23,23 @@ import (
 	"compress/bzip2"
 	"compress/zlib"
 	"crypto"
+	"crypto/rsa"
+	"crypto/sha1" // nolint:gosec
 	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
@@ -616,3 +626,191 @@ func parseHeader(r io.Reader) (xarHeader, crypto.Hash, error) {
 
 	return hdr, hashType, nil
 }
+
+// xarStapledTicketMagic is the magic of the trailer of a notarization ticket
+// stapled to a flat package, the ticket is appended to the xar file followed
+// by a 12 bytes trailer: the magic, the version and type (uint16) and the
+// length of the ticket (uint32).
+const xarStapledTicketMagic = "t8lr"
+
+// developerIDInstallerOID is the extension that identifies the Developer ID
+// Installer certificates, used to sign packages distributed outside the Mac
+// App Store.
+var developerIDInstallerOID = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 1, 14}
+
+type xarSignatureTOC struct {
+	TOC struct {
+		Checksum struct {
+			Offset int64 `xml:"offset"`
+			Size   int64 `xml:"size"`
+		} `xml:"checksum"`
+		Signature *struct {
+			Style        string   `xml:"style,attr"`
+			Offset       int64    `xml:"offset"`
+			Size         int64    `xml:"size"`
+			Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"signature"`
+		Files []xarSignedFile `xml:"file"`
+	} `xml:"toc"`
+}
+
+// xarSignedFile is a file of the TOC, the checksum of its archived data in
+// the heap is covered by the signature of the TOC.
+type xarSignedFile struct {
+	Data *struct {
+		Offset           int64 `xml:"offset"`
+		Size             int64 `xml:"size"`
+		ArchivedChecksum struct {
+			Style string `xml:"style,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"archived-checksum"`
+	} `xml:"data"`
+	Files []xarSignedFile `xml:"file"`
+}
+
+// verifyXARHeap verifies the archived data of the files in the heap against
+// their checksum in the TOC.
+func verifyXARHeap(r io.ReaderAt, heapOffset int64, files []xarSignedFile) bool {
+	for _, f := range files {
+		if f.Data != nil {
+			var h hash.Hash
+			switch strings.ToLower(f.Data.ArchivedChecksum.Style) {
+			case "sha1":
+				h = sha1.New()
+			case "sha256":
+				h = sha256.New()
+			case "sha512":
+				h = sha512.New()
+			default:
+				return false
+			}
+			n, err := io.Copy(h, io.NewSectionReader(r, heapOffset+f.Data.Offset, f.Data.Size))
+			if err != nil || n != f.Data.Size {
+				return false
+			}
+			if !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), strings.TrimSpace(f.Data.ArchivedChecksum.Value)) {
+				return false
+			}
+		}
+		if !verifyXARHeap(r, heapOffset, f.Files) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyXARSignature verifies the signature of a .pkg file, the RSA
+// signature of the checksum of the TOC made with the certificate in the TOC.
+// The signature is valid if it was made with a Developer ID Installer
+// certificate issued by Apple.
+//
+// The signing time isn't part of the RSA signature, so the certificate chain
+// is verified at the time the signing certificate was issued (packages remain
+// valid after their certificate expires). The revocation of the certificate
+// isn't checked.
+func verifyXARSignature(tfr *mdmlab.TempFileReader) (*mdmlab.SoftwareInstallerSignature, error) {
+	invalid := &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureInvalid}
+
+	stat, err := tfr.Stat()
+	if err != nil {
+		return nil, err
+	}
+	hdr, hashType, err := parseHeader(io.NewSectionReader(tfr, 0, xarHeaderSize))
+	if err != nil {
+		return invalid, nil
+	}
+
+	tocHash := hashType.New()
+	decomp, err := decompress(io.TeeReader(io.NewSectionReader(tfr, int64(hdr.HeaderSize), hdr.CompressedSize), tocHash))
+	if err != nil {
+		return invalid, nil
+	}
+	var root xarSignatureTOC
+	if err := xml.Unmarshal(decomp, &root); err != nil {
+		return invalid, nil
+	}
+	tocSig := root.TOC.Signature
+	if tocSig == nil {
+		return &mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}, nil
+	}
+	if tocSig.Style != "RSA" || len(tocSig.Certificates) == 0 {
+		return invalid, nil
+	}
+
+	heapOffset := int64(hdr.HeaderSize) + hdr.CompressedSize
+	checksum := make([]byte, root.TOC.Checksum.Size)
+	if _, err := tfr.ReadAt(checksum, heapOffset+root.TOC.Checksum.Offset); err != nil {
+		return invalid, nil
+	}
+	if !bytes.Equal(checksum, tocHash.Sum(nil)) {
+		return invalid, nil
+	}
+	signature := make([]byte, tocSig.Size)
+	if _, err := tfr.ReadAt(signature, heapOffset+tocSig.Offset); err != nil {
+		return invalid, nil
+	}
+
+	certs := make([]*x509.Certificate, 0, len(tocSig.Certificates))
+	for _, b64 := range tocSig.Certificates {
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(b64), ""))
+		if err != nil {
+			return invalid, nil
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return invalid, nil
+		}
+		certs = append(certs, cert)
+	}
+	leaf := certs[0]
+	pub, ok := leaf.PublicKey.(*rsa.PublicKey)
+	if !ok || rsa.VerifyPKCS1v15(pub, hashType, checksum, signature) != nil {
+		return invalid, nil
+	}
+	// the signature covers the files through their checksum in the TOC.
+	if !verifyXARHeap(tfr, heapOffset, root.TOC.Files) {
+		return invalid, nil
+	}
+
+	// the Developer ID extension is critical, it is handled below.
+	isDeveloperID := slices.ContainsFunc(leaf.Extensions, func(ext pkix.Extension) bool {
+		return ext.Id.Equal(developerIDInstallerOID)
+	})
+	leaf.UnhandledCriticalExtensions = slices.DeleteFunc(leaf.UnhandledCriticalExtensions, func(oid asn1.ObjectIdentifier) bool {
+		return oid.Equal(developerIDInstallerOID)
+	})
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	sig := verifyCertificateSignature(leaf, x509.VerifyOptions{
+		Roots:         appleRoots,
+		Intermediates: intermediates,
+		CurrentTime:   leaf.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if !isDeveloperID {
+		sig.SignatureStatus = mdmlab.SoftwareInstallerSignatureUntrusted
+	}
+	if len(leaf.Subject.OrganizationalUnit) > 0 {
+		sig.SignerTeamID = leaf.Subject.OrganizationalUnit[0]
+	}
+	sig.HasStapledTicket = hasStapledTicket(tfr, stat.Size())
+	return sig, nil
+}
+
+// hasStapledTicket returns true if a notarization ticket is stapled to the
+// flat package of the given size. Only the trailer is checked, the ticket
+// isn't validated nor bound to the package.
+func hasStapledTicket(r io.ReaderAt, size int64) bool {
+	const trailerSize = 12
+	if size < xarHeaderSize+trailerSize {
+		return false
+	}
+	var trailer [trailerSize]byte
+	if _, err := r.ReadAt(trailer[:], size-trailerSize); err != nil {
+		return false
+	}
+	ticketLen := int64(binary.LittleEndian.Uint32(trailer[8:]))
+	return string(trailer[:4]) == xarStapledTicketMagic && ticketLen > 0 && ticketLen <= size-xarHeaderSize-trailerSize
+}
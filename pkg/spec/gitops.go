@@ -102,6 +102,9 @@ type GitOps struct {
 	Queries      []*mdmlab.QuerySpec
 	// Software is only allowed on teams, not on global config.
 	Software GitOpsSoftware
+	// CustomCVEMatchingRules is only allowed on global config. A nil value means the rules are not
+	// managed by GitOps.
+	CustomCVEMatchingRules []*mdmlab.CustomCVEMatchingRuleSpec
 	// MDMlabSecrets is a map of secret names to their values, extracted from FLEET_SECRET_ environment variables used in profiles and scripts.
 	MDMlabSecrets map[string]string
 }
@@ -261,6 +264,7 @@ func parseOrgSettings(raw json.RawMessage, result *GitOps, baseDir string, multi
 			multiError = multierror.Append(multiError, fmt.Errorf("failed to unmarshal org settings: %v", err))
 		} else {
 			multiError = parseSecrets(result, multiError)
+			multiError = parseCustomCVEMatchingRules(result, multiError)
 		}
 		// TODO: Validate that integrations.(jira|zendesk)[].api_token is not empty or mdmlab.MaskedPassword
 	}
@@ -368,6 +372,40 @@ func parseSecrets(result *GitOps, multiError *multierror.Error) *multierror.Erro
 	return multiError
 }
 
+// parseCustomCVEMatchingRules extracts the optional 'org_settings.custom_cve_matching_rules' list.
+// The key is removed from the org settings since the rules are not part of the app config. When the
+// key is not present the rules are not managed by GitOps, when it is present (even if empty) it
+// replaces all existing rules.
+func parseCustomCVEMatchingRules(result *GitOps, multiError *multierror.Error) *multierror.Error {
+	rawRules, ok := result.OrgSettings["custom_cve_matching_rules"]
+	if !ok {
+		return multiError
+	}
+	delete(result.OrgSettings, "custom_cve_matching_rules")
+
+	result.CustomCVEMatchingRules = make([]*mdmlab.CustomCVEMatchingRuleSpec, 0)
+	if rawRules == nil {
+		return multiError
+	}
+	b, err := json.Marshal(rawRules)
+	if err != nil {
+		return multierror.Append(multiError, fmt.Errorf("failed to process 'custom_cve_matching_rules': %v", err))
+	}
+	if err := json.Unmarshal(b, &result.CustomCVEMatchingRules); err != nil {
+		return multierror.Append(multiError, fmt.Errorf("failed to unmarshal 'custom_cve_matching_rules': %v", err))
+	}
+	for i, rule := range result.CustomCVEMatchingRules {
+		if rule == nil {
+			multiError = multierror.Append(multiError, fmt.Errorf("custom_cve_matching_rules[%d] is empty", i))
+			continue
+		}
+		if err := rule.Validate(); err != nil {
+			multiError = multierror.Append(multiError, fmt.Errorf("custom_cve_matching_rules[%d]: %v", i, err))
+		}
+	}
+	return multiError
+}
+
 func parseAgentOptions(top map[string]json.RawMessage, result *GitOps, baseDir string, logFn Logf, multiError *multierror.Error) *multierror.Error {
 	agentOptionsRaw, ok := top["agent_options"]
 	if result.IsNoTeam() {
@@ -1091,6 +1091,50 @@ controls:
 	)
 }
 
+func TestGitOpsCustomCVEMatchingRules(t *testing.T) {
+	t.Parallel()
+	orgSettings := topLevelOptions["org_settings"]
+
+	// not managed when the key is absent
+	config := getGlobalConfig(nil)
+	gitops, err := gitOpsFromString(t, config)
+	require.NoError(t, err)
+	assert.Nil(t, gitops.CustomCVEMatchingRules)
+
+	// an empty value removes all rules
+	config = getGlobalConfig([]string{"org_settings"}) + orgSettings + "  custom_cve_matching_rules:\n"
+	gitops, err = gitOpsFromString(t, config)
+	require.NoError(t, err)
+	assert.NotNil(t, gitops.CustomCVEMatchingRules)
+	assert.Empty(t, gitops.CustomCVEMatchingRules)
+	assert.NotContains(t, gitops.OrgSettings, "custom_cve_matching_rules")
+
+	config = getGlobalConfig([]string{"org_settings"}) + orgSettings + `  custom_cve_matching_rules:
+    - name_like_match: "Acme Agent%"
+      source_match: deb_packages
+      cves: [CVE-2024-0001, CVE-2024-0002]
+      resolved_in_version: 2.0.1
+`
+	gitops, err = gitOpsFromString(t, config)
+	require.NoError(t, err)
+	require.Len(t, gitops.CustomCVEMatchingRules, 1)
+	assert.Equal(t, &mdmlab.CustomCVEMatchingRuleSpec{
+		NameLikeMatch:     "Acme Agent%",
+		SourceMatch:       "deb_packages",
+		CVEs:              []string{"CVE-2024-0001", "CVE-2024-0002"},
+		ResolvedInVersion: "2.0.1",
+	}, gitops.CustomCVEMatchingRules[0])
+	assert.NotContains(t, gitops.OrgSettings, "custom_cve_matching_rules")
+
+	config = getGlobalConfig([]string{"org_settings"}) + orgSettings + `  custom_cve_matching_rules:
+    - name_like_match: "Acme Agent%"
+      cves: [not-a-cve]
+`
+	_, err = gitOpsFromString(t, config)
+	assert.ErrorContains(t, err, "custom_cve_matching_rules[0]")
+	assert.ErrorContains(t, err, "resolved_in_version")
+}
+
 func getGlobalConfig(optsToExclude []string) string {
 	return getBaseConfig(topLevelOptions, optsToExclude)
 }
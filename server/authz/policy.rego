@@ -1019,3 +1019,55 @@ allow {
   subject.global_role == [admin, maintainer, gitops][_]
   action == write
 }
+
+##
+# Custom CVE matching rules
+##
+
+# Global admins, maintainers, observer_plus and observers can read custom CVE matching rules.
+allow {
+  object.type == "custom_cve_matching_rule"
+  subject.global_role == [admin, maintainer, observer_plus, observer][_]
+  action == read
+}
+
+# Global admins and gitops can write custom CVE matching rules.
+allow {
+  object.type == "custom_cve_matching_rule"
+  subject.global_role == [admin, gitops][_]
+  action == write
+}
+
+##
+# Vulnerability suppressions
+##
+
+# Global admins, maintainers, observer_plus and observers can read vulnerability suppressions.
+allow {
+  object.type == "vulnerability_suppression"
+  subject.global_role == [admin, maintainer, observer_plus, observer][_]
+  action == read
+}
+
+# Global admins, maintainers and gitops can write vulnerability suppressions.
+allow {
+  object.type == "vulnerability_suppression"
+  subject.global_role == [admin, maintainer, gitops][_]
+  action == write
+}
+
+# Team admins, maintainers, observer_plus and observers can read vulnerability suppressions for their teams.
+allow {
+  object.type == "vulnerability_suppression"
+  not is_null(object.team_id)
+  team_role(subject, object.team_id) == [admin, maintainer, observer_plus, observer][_]
+  action == read
+}
+
+# Team admins, maintainers and gitops can write vulnerability suppressions for their teams.
+allow {
+  object.type == "vulnerability_suppression"
+  not is_null(object.team_id)
+  team_role(subject, object.team_id) == [admin, maintainer, gitops][_]
+  action == write
+}
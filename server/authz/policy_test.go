@@ -484,6 +484,82 @@ func TestAuthorizeLabel(t *testing.T) {
 	})
 }
 
+func TestAuthorizeCustomCVEMatchingRule(t *testing.T) {
+	t.Parallel()
+
+	rule := &mdmlab.CustomCVEMatchingRule{}
+	runTestCases(t, []authTestCase{
+		{user: nil, object: rule, action: read, allow: false},
+		{user: nil, object: rule, action: write, allow: false},
+
+		{user: test.UserNoRoles, object: rule, action: read, allow: false},
+		{user: test.UserNoRoles, object: rule, action: write, allow: false},
+
+		{user: test.UserAdmin, object: rule, action: read, allow: true},
+		{user: test.UserAdmin, object: rule, action: write, allow: true},
+
+		{user: test.UserMaintainer, object: rule, action: read, allow: true},
+		{user: test.UserMaintainer, object: rule, action: write, allow: false},
+
+		{user: test.UserObserver, object: rule, action: read, allow: true},
+		{user: test.UserObserver, object: rule, action: write, allow: false},
+
+		{user: test.UserObserverPlus, object: rule, action: read, allow: true},
+		{user: test.UserObserverPlus, object: rule, action: write, allow: false},
+
+		// Global GitOps can write, but not read rules.
+		{user: test.UserGitOps, object: rule, action: read, allow: false},
+		{user: test.UserGitOps, object: rule, action: write, allow: true},
+
+		// Team users cannot read or write rules.
+		{user: test.UserTeamAdminTeam1, object: rule, action: read, allow: false},
+		{user: test.UserTeamAdminTeam1, object: rule, action: write, allow: false},
+		{user: test.UserTeamGitOpsTeam1, object: rule, action: write, allow: false},
+	})
+}
+
+func TestAuthorizeVulnerabilitySuppression(t *testing.T) {
+	t.Parallel()
+
+	global := &mdmlab.VulnerabilitySuppression{}
+	team1 := &mdmlab.VulnerabilitySuppression{TeamID: ptr.Uint(1)}
+	team2 := &mdmlab.VulnerabilitySuppression{TeamID: ptr.Uint(2)}
+	runTestCases(t, []authTestCase{
+		{user: nil, object: global, action: read, allow: false},
+		{user: nil, object: global, action: write, allow: false},
+
+		{user: test.UserNoRoles, object: global, action: read, allow: false},
+		{user: test.UserNoRoles, object: team1, action: write, allow: false},
+
+		{user: test.UserAdmin, object: global, action: read, allow: true},
+		{user: test.UserAdmin, object: global, action: write, allow: true},
+		{user: test.UserAdmin, object: team1, action: write, allow: true},
+
+		{user: test.UserMaintainer, object: global, action: read, allow: true},
+		{user: test.UserMaintainer, object: global, action: write, allow: true},
+
+		{user: test.UserObserver, object: global, action: read, allow: true},
+		{user: test.UserObserver, object: global, action: write, allow: false},
+
+		{user: test.UserObserverPlus, object: global, action: read, allow: true},
+		{user: test.UserObserverPlus, object: global, action: write, allow: false},
+
+		{user: test.UserGitOps, object: global, action: read, allow: false},
+		{user: test.UserGitOps, object: global, action: write, allow: true},
+
+		// Team users can only read and write suppressions of their teams.
+		{user: test.UserTeamAdminTeam1, object: global, action: read, allow: false},
+		{user: test.UserTeamAdminTeam1, object: global, action: write, allow: false},
+		{user: test.UserTeamAdminTeam1, object: team1, action: read, allow: true},
+		{user: test.UserTeamAdminTeam1, object: team1, action: write, allow: true},
+		{user: test.UserTeamAdminTeam1, object: team2, action: write, allow: false},
+		{user: test.UserTeamMaintainerTeam1, object: team1, action: write, allow: true},
+		{user: test.UserTeamObserverTeam1, object: team1, action: read, allow: true},
+		{user: test.UserTeamObserverTeam1, object: team1, action: write, allow: false},
+		{user: test.UserTeamGitOpsTeam1, object: team1, action: write, allow: true},
+	})
+}
+
 func TestAuthorizeSoftwareInventory(t *testing.T) {
 	t.Parallel()
 
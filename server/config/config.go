@@ -519,6 +519,13 @@ type VulnerabilitiesConfig struct {
 	RecentVulnerabilityMaxAge   time.Duration `json:"recent_vulnerability_max_age" yaml:"recent_vulnerability_max_age"`
 	DisableWinOSVulnerabilities bool          `json:"disable_win_os_vulnerabilities" yaml:"disable_win_os_vulnerabilities"`
 	MaxConcurrency              int           `json:"max_concurrency" yaml:"max_concurrency"`
+	// BundleImportDir is a directory watched for offline vulnerability data
+	// bundles. When set, the data feeds are never downloaded from the internet,
+	// the newest valid bundle found in it is imported instead.
+	BundleImportDir string `json:"bundle_import_dir" yaml:"bundle_import_dir"`
+	// BundlePublicKey is the path of the PEM-encoded Ed25519 public key used to
+	// verify the signature of the offline vulnerability data bundles.
+	BundlePublicKey string `json:"bundle_public_key" yaml:"bundle_public_key"`
 }
 
 // UpgradesConfig defines configs related to mdmlab server upgrades.
@@ -1315,6 +1322,10 @@ func (man Manager) addConfigs() {
 		5,
 		"Maximum number of concurrent database queries to use for processing vulnerabilities.",
 	)
+	man.addConfigString("vulnerabilities.bundle_import_dir", "",
+		"Directory watched for offline vulnerability data bundles (created with 'mdmlab vuln_bundle create'). If set, data feeds are imported from the bundles instead of being downloaded.")
+	man.addConfigString("vulnerabilities.bundle_public_key", "",
+		"Path of the PEM-encoded Ed25519 public key used to verify the offline vulnerability data bundles.")
 
 	// Upgrades
 	man.addConfigBool("upgrades.allow_missing_migrations", false,
@@ -1587,6 +1598,8 @@ func (man Manager) LoadConfig() MDMlabConfig {
 			RecentVulnerabilityMaxAge:   man.getConfigDuration("vulnerabilities.recent_vulnerability_max_age"),
 			DisableWinOSVulnerabilities: man.getConfigBool("vulnerabilities.disable_win_os_vulnerabilities"),
 			MaxConcurrency:              man.getConfigInt("vulnerabilities.max_concurrency"),
+			BundleImportDir:             man.getConfigString("vulnerabilities.bundle_import_dir"),
+			BundlePublicKey:             man.getConfigString("vulnerabilities.bundle_public_key"),
 		},
 		Upgrades: UpgradesConfig{
 			AllowMissingMigrations: man.getConfigBool("upgrades.allow_missing_migrations"),
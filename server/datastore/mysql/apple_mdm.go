@@ -725,6 +725,125 @@ WHERE
 	return results, nil
 }
 
+func (ds *Datastore) ListMDMAppleCommandQueue(
+	ctx context.Context,
+	tmFilter mdmlab.TeamFilter,
+	listOpts *mdmlab.MDMAppleCommandQueueListOptions,
+) ([]*mdmlab.MDMAppleCommandQueueItem, error) {
+	// the queue is read from the device channel enrollment's perspective
+	// (user-channel commands are reported under their parent device), and
+	// the results are wrapped in a sub-select so that the list options and
+	// filters can refer to the computed columns (e.g. status).
+	innerStmt := `
+SELECT
+    e.device_id as host_uuid,
+    h.id as host_id,
+    h.hostname,
+    h.team_id,
+    q.command_uuid,
+    c.request_type,
+    COALESCE(NULLIF(r.status, ''), 'Pending') as status,
+    COALESCE(r.not_now_tally, 0) as not_now_count,
+    r.not_now_at,
+    e.last_seen_at,
+    q.created_at,
+    COALESCE(r.updated_at, q.created_at) as updated_at
+FROM
+    nano_enrollment_queue q
+    INNER JOIN nano_commands c
+        ON c.command_uuid = q.command_uuid
+    INNER JOIN nano_enrollments e
+        ON e.id = q.id
+    INNER JOIN hosts h
+        ON h.uuid = e.device_id
+    LEFT JOIN nano_command_results r
+        ON r.command_uuid = q.command_uuid AND r.id = q.id
+WHERE
+    q.active = 1 AND
+    ` + ds.whereFilterHostsByTeams(tmFilter, "h")
+
+	var params []interface{}
+	innerStmt, params = ds.whereFilterHostsByIdentifier(listOpts.HostIdentifier, innerStmt, params)
+
+	stmt := fmt.Sprintf(`SELECT * FROM (%s) cq WHERE TRUE`, innerStmt)
+	if listOpts.RequestType != "" {
+		stmt += " AND request_type = ?"
+		params = append(params, listOpts.RequestType)
+	}
+	if listOpts.CommandUUID != "" {
+		stmt += " AND command_uuid = ?"
+		params = append(params, listOpts.CommandUUID)
+	}
+	if listOpts.Status != "" {
+		stmt += " AND status = ?"
+		params = append(params, listOpts.Status)
+	}
+	stmt, params = appendListOptionsWithCursorToSQL(stmt, params, &listOpts.ListOptions)
+
+	var results []*mdmlab.MDMAppleCommandQueueItem
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &results, stmt, params...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list apple mdm command queue")
+	}
+	return results, nil
+}
+
+func (ds *Datastore) ListMDMAppleHostsWithPendingCommands(
+	ctx context.Context,
+	tmFilter mdmlab.TeamFilter,
+	hostUUIDs []string,
+	commandUUID string,
+	lastSeenBefore *time.Time,
+) ([]*mdmlab.Host, error) {
+	stmt := `
+SELECT DISTINCT
+    h.id,
+    h.uuid,
+    h.hostname,
+    h.team_id
+FROM
+    nano_enrollment_queue q
+    INNER JOIN nano_enrollments e
+        ON e.id = q.id
+    INNER JOIN hosts h
+        ON h.uuid = e.device_id
+    LEFT JOIN nano_command_results r
+        ON r.command_uuid = q.command_uuid AND r.id = q.id
+WHERE
+    q.active = 1 AND
+    e.enabled = 1 AND
+    (r.status IS NULL OR r.status = 'NotNow') AND
+    ` + ds.whereFilterHostsByTeams(tmFilter, "h")
+
+	var args []interface{}
+	if len(hostUUIDs) > 0 {
+		stmt += " AND h.uuid IN (?)"
+		args = append(args, hostUUIDs)
+	}
+	if commandUUID != "" {
+		stmt += " AND q.command_uuid = ?"
+		args = append(args, commandUUID)
+	}
+	if lastSeenBefore != nil {
+		stmt += " AND e.last_seen_at < ?"
+		args = append(args, *lastSeenBefore)
+	}
+	stmt += " ORDER BY h.id"
+
+	if len(hostUUIDs) > 0 {
+		var err error
+		stmt, args, err = sqlx.In(stmt, args...)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "building IN statement for hosts with pending commands")
+		}
+	}
+
+	var hosts []*mdmlab.Host
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &hosts, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list apple hosts with pending commands")
+	}
+	return hosts, nil
+}
+
 func (ds *Datastore) NewMDMAppleInstaller(ctx context.Context, name string, size int64, manifest string, installer []byte, urlToken string) (*mdmlab.MDMAppleInstaller, error) {
 	res, err := ds.writer(ctx).ExecContext(
 		ctx,
@@ -995,6 +1114,10 @@ func insertMDMAppleHostDB(
 		return ctxerr.Wrap(ctx, err, "ingest mdm apple host upsert display names")
 	}
 
+	if err := restoreHostRecoveryLockDB(ctx, tx, mdmHost.UUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "ingest mdm apple host restore recovery lock")
+	}
+
 	if err := upsertMDMAppleHostLabelMembershipDB(ctx, tx, logger, *mdmHost); err != nil {
 		return ctxerr.Wrap(ctx, err, "ingest mdm apple host upsert label membership")
 	}
@@ -1666,6 +1789,9 @@ INSERT INTO hosts (
 		if err := upsertMDMAppleHostMDMInfoDB(ctx, tx, ac, true, host.ID); err != nil {
 			return ctxerr.Wrap(ctx, err, "ingest mdm apple host upsert MDM info")
 		}
+		if err := restoreHostRecoveryLockDB(ctx, tx, host.UUID); err != nil {
+			return ctxerr.Wrap(ctx, err, "restore pending dep host recovery lock")
+		}
 
 		return nil
 	})
@@ -4183,6 +4309,363 @@ WHERE h.uuid = ?
 	return nil
 }
 
+func (ds *Datastore) SetHostMDMAppleDeviceLocation(ctx context.Context, loc *mdmlab.HostMDMAppleDeviceLocation) error {
+	const stmt = `
+INSERT INTO host_mdm_apple_device_locations (
+	host_uuid,
+	command_uuid,
+	latitude,
+	longitude,
+	altitude,
+	horizontal_accuracy,
+	vertical_accuracy,
+	located_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+	command_uuid = VALUES(command_uuid),
+	latitude = VALUES(latitude),
+	longitude = VALUES(longitude),
+	altitude = VALUES(altitude),
+	horizontal_accuracy = VALUES(horizontal_accuracy),
+	vertical_accuracy = VALUES(vertical_accuracy),
+	located_at = VALUES(located_at)`
+
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, loc.HostUUID, loc.CommandUUID, loc.Latitude, loc.Longitude,
+		loc.Altitude, loc.HorizontalAccuracy, loc.VerticalAccuracy, loc.LocatedAt); err != nil {
+		return ctxerr.Wrap(ctx, err, "upserting host device location")
+	}
+	return nil
+}
+
+func (ds *Datastore) GetHostMDMAppleDeviceLocation(ctx context.Context, hostUUID string) (*mdmlab.HostMDMAppleDeviceLocation, error) {
+	const stmt = `
+SELECT
+	host_uuid,
+	command_uuid,
+	latitude,
+	longitude,
+	altitude,
+	horizontal_accuracy,
+	vertical_accuracy,
+	located_at,
+	updated_at
+FROM
+	host_mdm_apple_device_locations
+WHERE
+	host_uuid = ?`
+
+	var loc mdmlab.HostMDMAppleDeviceLocation
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &loc, stmt, hostUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("HostMDMAppleDeviceLocation").WithName(hostUUID))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get host device location")
+	}
+	return &loc, nil
+}
+
+func (ds *Datastore) ListHostsPendingRecoveryLockRotation(ctx context.Context, teamID *uint, limit int) ([]*mdmlab.HostRecoveryLockTarget, error) {
+	const stmt = `
+SELECT
+	h.id AS host_id,
+	h.uuid AS host_uuid,
+	h.cpu_type
+FROM
+	hosts h
+	JOIN nano_enrollments ne ON ne.id = h.uuid
+	JOIN host_mdm hm ON hm.host_id = h.id
+	LEFT OUTER JOIN host_mdm_apple_recovery_locks hrl ON hrl.host_uuid = h.uuid
+WHERE
+	h.platform = 'darwin' AND
+	h.cpu_type != '' AND
+	%s AND
+	ne.enabled = 1 AND
+	ne.type = 'Device' AND
+	hm.enrolled = 1 AND
+	(
+		hrl.host_uuid IS NULL OR
+		(hrl.status IN (?, ?) AND hrl.rotate_at IS NOT NULL AND hrl.rotate_at <= NOW())
+	)
+ORDER BY
+	h.id
+LIMIT ?`
+
+	teamFilter := "h.team_id IS NULL"
+	args := []any{mdmlab.MDMDeliveryVerified, mdmlab.MDMDeliveryFailed, limit}
+	if teamID != nil {
+		teamFilter = "h.team_id = ?"
+		args = append([]any{*teamID}, args...)
+	}
+
+	var targets []*mdmlab.HostRecoveryLockTarget
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &targets, fmt.Sprintf(stmt, teamFilter), args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list hosts pending recovery lock rotation")
+	}
+	return targets, nil
+}
+
+func (ds *Datastore) SetHostRecoveryLockPending(ctx context.Context, hostUUID, passwordType, password, commandUUID string, rotateAt *time.Time) error {
+	const stmt = `
+INSERT INTO host_mdm_apple_recovery_locks
+	(host_uuid, password_type, pending_encrypted_password, status, detail, command_uuid, verify_command_uuid, rotate_at)
+VALUES
+	(?, ?, ?, ?, '', ?, NULL, ?)
+ON DUPLICATE KEY UPDATE
+	password_type = VALUES(password_type),
+	pending_encrypted_password = VALUES(pending_encrypted_password),
+	status = VALUES(status),
+	detail = '',
+	command_uuid = VALUES(command_uuid),
+	verify_command_uuid = NULL,
+	rotate_at = VALUES(rotate_at)`
+
+	encrypted, err := encrypt([]byte(password), ds.serverPrivateKey)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "encrypt recovery lock password with datastore.serverPrivateKey")
+	}
+
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, hostUUID, passwordType, encrypted, mdmlab.MDMDeliveryPending, commandUUID, rotateAt); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host recovery lock pending")
+	}
+	return nil
+}
+
+func (ds *Datastore) SetHostRecoveryLockVerifying(ctx context.Context, hostUUID, verifyCommandUUID string) error {
+	const stmt = `
+UPDATE host_mdm_apple_recovery_locks
+SET
+	encrypted_password = COALESCE(pending_encrypted_password, encrypted_password),
+	pending_encrypted_password = NULL,
+	status = ?,
+	verify_command_uuid = ?
+WHERE
+	host_uuid = ?`
+
+	// the host acknowledged the command, so it may be using the new password
+	// even if the verification fails.
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, mdmlab.MDMDeliveryVerifying, verifyCommandUUID, hostUUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host recovery lock verifying")
+	}
+	return nil
+}
+
+func (ds *Datastore) SetHostRecoveryLockVerified(ctx context.Context, hostUUID string) error {
+	const stmt = `
+UPDATE host_mdm_apple_recovery_locks
+SET
+	encrypted_password = COALESCE(pending_encrypted_password, encrypted_password),
+	pending_encrypted_password = NULL,
+	status = ?,
+	detail = ''
+WHERE
+	host_uuid = ?`
+
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, mdmlab.MDMDeliveryVerified, hostUUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host recovery lock verified")
+	}
+	return nil
+}
+
+func (ds *Datastore) SetHostRecoveryLockFailed(ctx context.Context, hostUUID, detail string, retryAt time.Time) error {
+	const stmt = `
+UPDATE host_mdm_apple_recovery_locks
+SET
+	pending_encrypted_password = NULL,
+	status = ?,
+	detail = ?,
+	rotate_at = ?
+WHERE
+	host_uuid = ?`
+
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, mdmlab.MDMDeliveryFailed, detail, retryAt, hostUUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host recovery lock failed")
+	}
+	return nil
+}
+
+func (ds *Datastore) GetHostRecoveryLock(ctx context.Context, hostUUID string) (*mdmlab.HostRecoveryLock, error) {
+	const stmt = `
+SELECT
+	host_uuid,
+	password_type,
+	encrypted_password,
+	pending_encrypted_password,
+	status,
+	COALESCE(detail, '') AS detail,
+	command_uuid,
+	verify_command_uuid,
+	rotate_at,
+	revealed_at,
+	updated_at
+FROM
+	host_mdm_apple_recovery_locks
+WHERE
+	host_uuid = ?`
+
+	var row struct {
+		mdmlab.HostRecoveryLock
+		EncryptedPassword        []byte `db:"encrypted_password"`
+		PendingEncryptedPassword []byte `db:"pending_encrypted_password"`
+	}
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &row, stmt, hostUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("HostRecoveryLock").WithName(hostUUID))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get host recovery lock")
+	}
+
+	lock := row.HostRecoveryLock
+	if len(row.EncryptedPassword) > 0 {
+		decrypted, err := decrypt(row.EncryptedPassword, ds.serverPrivateKey)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "decrypt recovery lock password with datastore.serverPrivateKey")
+		}
+		lock.Password = string(decrypted)
+	}
+	if len(row.PendingEncryptedPassword) > 0 {
+		decrypted, err := decrypt(row.PendingEncryptedPassword, ds.serverPrivateKey)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "decrypt pending recovery lock password with datastore.serverPrivateKey")
+		}
+		lock.PendingPassword = string(decrypted)
+	}
+	return &lock, nil
+}
+
+func (ds *Datastore) SetHostRecoveryLockRevealed(ctx context.Context, hostUUID string, rotateAt time.Time) error {
+	const stmt = `
+UPDATE host_mdm_apple_recovery_locks
+SET
+	revealed_at = CURRENT_TIMESTAMP(6),
+	rotate_at = IF(rotate_at IS NULL OR rotate_at > ?, ?, rotate_at)
+WHERE
+	host_uuid = ?`
+
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, rotateAt, rotateAt, hostUUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host recovery lock revealed")
+	}
+	return nil
+}
+
+// archiveHostRecoveryLocksDB keeps the escrowed recovery lock of the hosts
+// being deleted, so that it can be restored if they enroll again. Only the
+// escrowed password is archived, a pending one may not have been set on the
+// host.
+func archiveHostRecoveryLocksDB(ctx context.Context, tx sqlx.ExtContext, hostUUIDs []string) error {
+	const archiveStmt = `
+INSERT INTO host_mdm_apple_recovery_locks_archive
+	(host_uuid, password_type, encrypted_password, rotate_at, revealed_at)
+SELECT
+	host_uuid, password_type, encrypted_password, rotate_at, revealed_at
+FROM
+	host_mdm_apple_recovery_locks
+WHERE
+	host_uuid IN (?) AND
+	encrypted_password IS NOT NULL
+ON DUPLICATE KEY UPDATE
+	password_type = VALUES(password_type),
+	encrypted_password = VALUES(encrypted_password),
+	rotate_at = VALUES(rotate_at),
+	revealed_at = VALUES(revealed_at),
+	created_at = CURRENT_TIMESTAMP(6)`
+
+	stmt, args, err := sqlx.In(archiveStmt, hostUUIDs)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build archive host recovery locks statement")
+	}
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "archive host recovery locks")
+	}
+	return nil
+}
+
+// restoreHostRecoveryLockDB restores the archived recovery lock of a host
+// that enrolls again after being deleted, unless the host already has one.
+func restoreHostRecoveryLockDB(ctx context.Context, tx sqlx.ExtContext, hostUUID string) error {
+	if hostUUID == "" {
+		return nil
+	}
+
+	const insertStmt = `
+INSERT INTO host_mdm_apple_recovery_locks
+	(host_uuid, password_type, encrypted_password, status, detail, command_uuid, rotate_at, revealed_at)
+SELECT
+	host_uuid, password_type, encrypted_password, ?, '', '', rotate_at, revealed_at
+FROM
+	host_mdm_apple_recovery_locks_archive
+WHERE
+	host_uuid = ?
+ON DUPLICATE KEY UPDATE
+	host_uuid = host_mdm_apple_recovery_locks.host_uuid`
+
+	if _, err := tx.ExecContext(ctx, insertStmt, mdmlab.MDMDeliveryVerified, hostUUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "restore host recovery lock")
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM host_mdm_apple_recovery_locks_archive WHERE host_uuid = ?`, hostUUID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete archived host recovery lock")
+	}
+	return nil
+}
+
+// redactedMDMAppleCommandTemplate is the payload stored in place of a
+// redacted command, formatted with the request type and command UUID.
+const redactedMDMAppleCommandTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Command</key>
+	<dict>
+		<key>RequestType</key>
+		<string>%s</string>
+	</dict>
+	<key>CommandUUID</key>
+	<string>%s</string>
+</dict>
+</plist>`
+
+func (ds *Datastore) RedactMDMAppleCommandPayload(ctx context.Context, commandUUID string) error {
+	// the command is still pending for a host if it is active in its queue and
+	// the host didn't report a result yet, or asked to retry it later.
+	const pendingStmt = `
+SELECT EXISTS (
+	SELECT 1
+	FROM
+		nano_enrollment_queue neq
+		LEFT JOIN nano_command_results ncr
+			ON ncr.id = neq.id AND ncr.command_uuid = neq.command_uuid
+	WHERE
+		neq.command_uuid = ? AND
+		neq.active = 1 AND
+		(ncr.status IS NULL OR ncr.status = 'NotNow')
+)`
+
+	const updateStmt = `UPDATE nano_commands SET command = ? WHERE command_uuid = ?`
+
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		var pending bool
+		if err := sqlx.GetContext(ctx, tx, &pending, pendingStmt, commandUUID); err != nil {
+			return ctxerr.Wrap(ctx, err, "check pending command")
+		}
+		if pending {
+			return nil
+		}
+
+		var requestType string
+		if err := sqlx.GetContext(ctx, tx, &requestType, `SELECT request_type FROM nano_commands WHERE command_uuid = ?`, commandUUID); err != nil {
+			if err == sql.ErrNoRows {
+				return ctxerr.Wrap(ctx, notFound("MDMAppleCommand").WithName(commandUUID))
+			}
+			return ctxerr.Wrap(ctx, err, "get command request type")
+		}
+
+		redacted := fmt.Sprintf(redactedMDMAppleCommandTemplate, requestType, commandUUID)
+		if _, err := tx.ExecContext(ctx, updateStmt, redacted, commandUUID); err != nil {
+			return ctxerr.Wrap(ctx, err, "redact command payload")
+		}
+		return nil
+	})
+}
+
 func (ds *Datastore) batchSetMDMAppleDeclarations(ctx context.Context, tx sqlx.ExtContext, tmID *uint,
 	incomingDeclarations []*mdmlab.MDMAppleDeclaration) (updatedDB bool, err error) {
 
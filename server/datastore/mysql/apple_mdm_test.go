@@ -63,6 +63,7 @@ func TestMDMApple(t *testing.T) {
 		{"TestBulkUpsertMDMAppleConfigProfiles", testBulkUpsertMDMAppleConfigProfile},
 		{"TestMDMAppleBootstrapPackageCRUD", testMDMAppleBootstrapPackageCRUD},
 		{"TestListMDMAppleCommands", testListMDMAppleCommands},
+		{"TestMDMAppleCommandQueue", testMDMAppleCommandQueue},
 		{"TestMDMAppleSetupAssistant", testMDMAppleSetupAssistant},
 		{"TestMDMAppleEnrollmentProfile", testMDMAppleEnrollmentProfile},
 		{"TestListMDMAppleSerials", testListMDMAppleSerials},
@@ -93,6 +94,8 @@ func TestMDMApple(t *testing.T) {
 		{"AppleMDMSetBatchAsyncLastSeenAt", testAppleMDMSetBatchAsyncLastSeenAt},
 		{"TestMDMAppleProfileLabels", testMDMAppleProfileLabels},
 		{"AggregateMacOSSettingsAllPlatforms", testAggregateMacOSSettingsAllPlatforms},
+		{"HostRecoveryLock", testHostRecoveryLock},
+		{"RedactMDMAppleCommandPayload", testRedactMDMAppleCommandPayload},
 	}
 
 	for _, c := range cases {
@@ -7590,3 +7593,404 @@ func testAggregateMacOSSettingsAllPlatforms(t *testing.T, ds *Datastore) {
 	require.EqualValues(t, 0, res.Verifying)
 	require.EqualValues(t, 0, res.Verified)
 }
+
+func testHostRecoveryLock(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	tm, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	newHost := func(name, cpuType string, teamID *uint) *mdmlab.Host {
+		h, err := ds.NewHost(ctx, &mdmlab.Host{
+			Hostname:      name,
+			OsqueryHostID: ptr.String(name),
+			NodeKey:       ptr.String(name),
+			UUID:          name + "-uuid",
+			TeamID:        teamID,
+			Platform:      "darwin",
+			CPUType:       cpuType,
+		})
+		require.NoError(t, err)
+		nanoEnrollAndSetHostMDMData(t, ds, h, false)
+		return h
+	}
+	armHost := newHost("arm", "arm64e", nil)
+	intelHost := newHost("intel", "x86_64h", nil)
+	teamHost := newHost("team", "arm64e", &tm.ID)
+	// hosts with unknown CPU type are skipped
+	newHost("unknown", "", nil)
+
+	targets, err := ds.ListHostsPendingRecoveryLockRotation(ctx, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, armHost.UUID, targets[0].HostUUID)
+	require.Equal(t, mdmlab.RecoveryLockPasswordTypeRecoveryLock, targets[0].PasswordType())
+	require.Equal(t, intelHost.UUID, targets[1].HostUUID)
+	require.Equal(t, mdmlab.RecoveryLockPasswordTypeFirmwarePassword, targets[1].PasswordType())
+
+	targets, err = ds.ListHostsPendingRecoveryLockRotation(ctx, &tm.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, teamHost.UUID, targets[0].HostUUID)
+
+	_, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// set a pending password, the host is not listed anymore
+	err = ds.SetHostRecoveryLockPending(ctx, armHost.UUID, mdmlab.RecoveryLockPasswordTypeRecoveryLock, "pw1", "cmd1", nil)
+	require.NoError(t, err)
+	targets, err = ds.ListHostsPendingRecoveryLockRotation(ctx, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, intelHost.UUID, targets[0].HostUUID)
+
+	lock, err := ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.MDMDeliveryPending, lock.Status)
+	require.Equal(t, "cmd1", lock.CommandUUID)
+	require.Empty(t, lock.Password)
+	require.Equal(t, "pw1", lock.PendingPassword)
+
+	// the password is stored encrypted
+	var raw []byte
+	err = sqlx.GetContext(ctx, ds.reader(ctx), &raw, `SELECT pending_encrypted_password FROM host_mdm_apple_recovery_locks WHERE host_uuid = ?`, armHost.UUID)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "pw1")
+
+	err = ds.SetHostRecoveryLockVerifying(ctx, armHost.UUID, "verify1")
+	require.NoError(t, err)
+	err = ds.SetHostRecoveryLockVerified(ctx, armHost.UUID)
+	require.NoError(t, err)
+
+	lock, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.MDMDeliveryVerified, lock.Status)
+	require.NotNil(t, lock.VerifyCommandUUID)
+	require.Equal(t, "verify1", *lock.VerifyCommandUUID)
+	require.Equal(t, "pw1", lock.Password)
+	require.Empty(t, lock.PendingPassword)
+	require.Nil(t, lock.RotateAt)
+	require.Nil(t, lock.RevealedAt)
+
+	// revealing the password schedules a rotation
+	err = ds.SetHostRecoveryLockRevealed(ctx, armHost.UUID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	lock, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.NotNil(t, lock.RevealedAt)
+	require.NotNil(t, lock.RotateAt)
+
+	targets, err = ds.ListHostsPendingRecoveryLockRotation(ctx, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	// a failed rotation keeps the verified password and is retried later
+	err = ds.SetHostRecoveryLockPending(ctx, armHost.UUID, mdmlab.RecoveryLockPasswordTypeRecoveryLock, "pw2", "cmd2", nil)
+	require.NoError(t, err)
+	err = ds.SetHostRecoveryLockFailed(ctx, armHost.UUID, "some error", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	lock, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.MDMDeliveryFailed, lock.Status)
+	require.Equal(t, "some error", lock.Detail)
+	require.Equal(t, "pw1", lock.Password)
+	require.Empty(t, lock.PendingPassword)
+	require.Nil(t, lock.VerifyCommandUUID)
+
+	targets, err = ds.ListHostsPendingRecoveryLockRotation(ctx, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, intelHost.UUID, targets[0].HostUUID)
+
+	// a rotation acknowledged by the host keeps the new password even if the
+	// verification fails
+	err = ds.SetHostRecoveryLockPending(ctx, armHost.UUID, mdmlab.RecoveryLockPasswordTypeRecoveryLock, "pw3", "cmd3", nil)
+	require.NoError(t, err)
+	err = ds.SetHostRecoveryLockVerifying(ctx, armHost.UUID, "verify3")
+	require.NoError(t, err)
+
+	lock, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.MDMDeliveryVerifying, lock.Status)
+	require.Equal(t, "pw3", lock.Password)
+	require.Empty(t, lock.PendingPassword)
+
+	err = ds.SetHostRecoveryLockFailed(ctx, armHost.UUID, "not set", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	lock, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.MDMDeliveryFailed, lock.Status)
+	require.Equal(t, "pw3", lock.Password)
+	require.Empty(t, lock.PendingPassword)
+
+	// deleting the host archives its escrowed recovery lock
+	err = ds.DeleteHost(ctx, armHost.ID)
+	require.NoError(t, err)
+	_, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.True(t, mdmlab.IsNotFound(err))
+	var archived int
+	err = sqlx.GetContext(ctx, ds.reader(ctx), &archived, `SELECT COUNT(*) FROM host_mdm_apple_recovery_locks_archive WHERE host_uuid = ?`, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	// hosts without an escrowed password are not archived
+	err = ds.SetHostRecoveryLockPending(ctx, intelHost.UUID, mdmlab.RecoveryLockPasswordTypeFirmwarePassword, "pw4", "cmd4", nil)
+	require.NoError(t, err)
+	err = ds.DeleteHost(ctx, intelHost.ID)
+	require.NoError(t, err)
+	err = sqlx.GetContext(ctx, ds.reader(ctx), &archived, `SELECT COUNT(*) FROM host_mdm_apple_recovery_locks_archive WHERE host_uuid = ?`, intelHost.UUID)
+	require.NoError(t, err)
+	require.Zero(t, archived)
+
+	// the recovery lock is restored when the host enrolls again
+	_, err = ds.EnrollOrbit(ctx, true, mdmlab.OrbitHostInfo{
+		HardwareUUID:   armHost.UUID,
+		HardwareSerial: "arm-serial",
+		Hostname:       armHost.Hostname,
+		Platform:       "darwin",
+	}, "arm-orbit-key", nil)
+	require.NoError(t, err)
+
+	lock, err = ds.GetHostRecoveryLock(ctx, armHost.UUID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.MDMDeliveryVerified, lock.Status)
+	require.Equal(t, mdmlab.RecoveryLockPasswordTypeRecoveryLock, lock.PasswordType)
+	require.Equal(t, "pw3", lock.Password)
+	require.Empty(t, lock.PendingPassword)
+	err = sqlx.GetContext(ctx, ds.reader(ctx), &archived, `SELECT COUNT(*) FROM host_mdm_apple_recovery_locks_archive WHERE host_uuid = ?`, armHost.UUID)
+	require.NoError(t, err)
+	require.Zero(t, archived)
+}
+
+func testMDMAppleCommandQueue(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	hosts := make([]*mdmlab.Host, 3)
+	for i := 0; i < 3; i++ {
+		h, err := ds.NewHost(ctx, &mdmlab.Host{
+			Hostname:      fmt.Sprintf("test-host%d-name", i),
+			OsqueryHostID: ptr.String(fmt.Sprintf("osquery-%d", i)),
+			NodeKey:       ptr.String(fmt.Sprintf("nodekey-%d", i)),
+			UUID:          fmt.Sprintf("test-uuid-%d", i),
+			Platform:      "darwin",
+		})
+		require.NoError(t, err)
+		nanoEnroll(t, ds, h, false)
+		hosts[i] = h
+	}
+	tm1, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	err = ds.AddHostsToTeam(ctx, &tm1.ID, []uint{hosts[2].ID})
+	require.NoError(t, err)
+
+	commander, storage := createMDMAppleCommanderAndStorage(t, ds)
+
+	res, err := ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, &mdmlab.MDMAppleCommandQueueListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	cmdUUID := uuid.New().String()
+	rawCmd := createRawAppleCmd("ListApps", cmdUUID)
+	err = commander.EnqueueCommand(ctx, []string{hosts[0].UUID, hosts[1].UUID, hosts[2].UUID}, rawCmd)
+	require.NoError(t, err)
+
+	// hosts[0] acknowledges the command, hosts[1] responds NotNow twice
+	reportResult := func(h *mdmlab.Host, status string) {
+		err := storage.StoreCommandReport(&mdm.Request{
+			EnrollID: &mdm.EnrollID{ID: h.UUID},
+			Context:  ctx,
+		}, &mdm.CommandResults{
+			CommandUUID: cmdUUID,
+			Status:      status,
+			Raw:         []byte(rawCmd),
+		})
+		require.NoError(t, err)
+	}
+	reportResult(hosts[0], "Acknowledged")
+	reportResult(hosts[1], "NotNow")
+	reportResult(hosts[1], "NotNow")
+
+	res, err = ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, &mdmlab.MDMAppleCommandQueueListOptions{
+		ListOptions: mdmlab.ListOptions{OrderKey: "host_id"},
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	require.Equal(t, hosts[0].UUID, res[0].HostUUID)
+	require.Equal(t, mdmlab.MDMAppleCommandQueueStatusAcknowledged, res[0].Status)
+	require.Zero(t, res[0].NotNowCount)
+	require.Nil(t, res[0].NotNowAt)
+	require.Equal(t, hosts[1].UUID, res[1].HostUUID)
+	require.Equal(t, mdmlab.MDMAppleCommandQueueStatusNotNow, res[1].Status)
+	require.Equal(t, 2, res[1].NotNowCount)
+	require.NotNil(t, res[1].NotNowAt)
+	require.Equal(t, hosts[2].UUID, res[2].HostUUID)
+	require.Equal(t, mdmlab.MDMAppleCommandQueueStatusPending, res[2].Status)
+	require.Equal(t, tm1.ID, *res[2].TeamID)
+	require.Equal(t, "ListApps", res[2].RequestType)
+
+	// filter by status
+	res, err = ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, &mdmlab.MDMAppleCommandQueueListOptions{
+		Status: mdmlab.MDMAppleCommandQueueStatusNotNow,
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, hosts[1].UUID, res[0].HostUUID)
+
+	// filter by host identifier, request type and command
+	res, err = ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, &mdmlab.MDMAppleCommandQueueListOptions{
+		HostIdentifier: hosts[2].Hostname,
+		RequestType:    "ListApps",
+		CommandUUID:    cmdUUID,
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, hosts[2].UUID, res[0].HostUUID)
+
+	res, err = ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, &mdmlab.MDMAppleCommandQueueListOptions{
+		RequestType: "InstallProfile",
+	})
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	// team filter
+	u1 := &mdmlab.User{Teams: []mdmlab.UserTeam{{Team: *tm1, Role: mdmlab.RoleMaintainer}}}
+	res, err = ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: u1}, &mdmlab.MDMAppleCommandQueueListOptions{})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, hosts[2].UUID, res[0].HostUUID)
+
+	// only hosts[1] and hosts[2] have pending commands
+	pending, err := ds.ListMDMAppleHostsWithPendingCommands(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, nil, "", nil)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, hosts[1].UUID, pending[0].UUID)
+	require.Equal(t, hosts[2].UUID, pending[1].UUID)
+
+	pending, err = ds.ListMDMAppleHostsWithPendingCommands(ctx, mdmlab.TeamFilter{User: test.UserAdmin},
+		[]string{hosts[0].UUID, hosts[2].UUID}, cmdUUID, nil)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, hosts[2].UUID, pending[0].UUID)
+
+	// all hosts checked in recently, none is stale
+	pending, err = ds.ListMDMAppleHostsWithPendingCommands(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, nil, "",
+		ptr.Time(time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// enqueue a command of an excluded request type
+	lockUUID := uuid.New().String()
+	err = commander.EnqueueCommand(ctx, []string{hosts[1].UUID}, createRawAppleCmd("DeviceLock", lockUUID))
+	require.NoError(t, err)
+
+	// cancel the pending commands, the acknowledged one and the excluded one
+	// are left untouched
+	n, err := storage.CancelPendingCommands(ctx, []string{hosts[0].UUID, hosts[1].UUID, hosts[2].UUID}, "", []string{"DeviceLock"})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+
+	res, err = ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, &mdmlab.MDMAppleCommandQueueListOptions{})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.ElementsMatch(t, []string{hosts[0].UUID, hosts[1].UUID}, []string{res[0].HostUUID, res[1].HostUUID})
+
+	pending, err = ds.ListMDMAppleHostsWithPendingCommands(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, nil, "", nil)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, hosts[1].UUID, pending[0].UUID)
+
+	n, err = storage.CancelPendingCommands(ctx, []string{hosts[1].UUID}, lockUUID, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	pending, err = ds.ListMDMAppleHostsWithPendingCommands(ctx, mdmlab.TeamFilter{User: test.UserAdmin}, nil, "", nil)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func testRedactMDMAppleCommandPayload(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	hosts := make([]*mdmlab.Host, 2)
+	for i := range hosts {
+		h, err := ds.NewHost(ctx, &mdmlab.Host{
+			Hostname:      fmt.Sprintf("redact-host%d", i),
+			OsqueryHostID: ptr.String(fmt.Sprintf("redact-osquery-%d", i)),
+			NodeKey:       ptr.String(fmt.Sprintf("redact-nodekey-%d", i)),
+			UUID:          fmt.Sprintf("redact-uuid-%d", i),
+			Platform:      "darwin",
+		})
+		require.NoError(t, err)
+		nanoEnroll(t, ds, h, false)
+		hosts[i] = h
+	}
+
+	commander, storage := createMDMAppleCommanderAndStorage(t, ds)
+
+	cmdUUID := uuid.New().String()
+	rawCmd := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Command</key>
+	<dict>
+		<key>NewPassword</key>
+		<string>secret-password</string>
+		<key>RequestType</key>
+		<string>SetRecoveryLock</string>
+	</dict>
+	<key>CommandUUID</key>
+	<string>%s</string>
+</dict>
+</plist>`, cmdUUID)
+	err := commander.EnqueueCommand(ctx, []string{hosts[0].UUID, hosts[1].UUID}, rawCmd)
+	require.NoError(t, err)
+
+	getPayload := func() string {
+		var payload string
+		err := sqlx.GetContext(ctx, ds.reader(ctx), &payload, `SELECT command FROM nano_commands WHERE command_uuid = ?`, cmdUUID)
+		require.NoError(t, err)
+		return payload
+	}
+	report := func(h *mdmlab.Host, status string) {
+		err := storage.StoreCommandReport(&mdm.Request{
+			EnrollID: &mdm.EnrollID{ID: h.UUID},
+			Context:  ctx,
+		}, &mdm.CommandResults{
+			CommandUUID: cmdUUID,
+			Status:      status,
+			Raw:         []byte(rawCmd),
+		})
+		require.NoError(t, err)
+	}
+
+	// the command is still pending for both hosts
+	err = ds.RedactMDMAppleCommandPayload(ctx, cmdUUID)
+	require.NoError(t, err)
+	require.Contains(t, getPayload(), "secret-password")
+
+	// the command is still pending for the second host
+	report(hosts[0], "Acknowledged")
+	report(hosts[1], "NotNow")
+	err = ds.RedactMDMAppleCommandPayload(ctx, cmdUUID)
+	require.NoError(t, err)
+	require.Contains(t, getPayload(), "secret-password")
+
+	// all hosts processed the command
+	report(hosts[1], "Error")
+	err = ds.RedactMDMAppleCommandPayload(ctx, cmdUUID)
+	require.NoError(t, err)
+	payload := getPayload()
+	require.NotContains(t, payload, "secret-password")
+	require.Contains(t, payload, "<string>SetRecoveryLock</string>")
+	require.Contains(t, payload, cmdUUID)
+
+	// the request type is still available
+	rt, err := ds.GetMDMAppleCommandRequestType(ctx, cmdUUID)
+	require.NoError(t, err)
+	require.Equal(t, "SetRecoveryLock", rt)
+
+	err = ds.RedactMDMAppleCommandPayload(ctx, "no-such-command")
+	require.True(t, mdmlab.IsNotFound(err))
+}
@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+// customCVEMatchingRuleRow is the database representation of a custom CVE matching rule, the CVEs
+// are stored as a JSON array.
+type customCVEMatchingRuleRow struct {
+	mdmlab.CustomCVEMatchingRule
+	CVEsJSON json.RawMessage `db:"cves"`
+}
+
+func (ds *Datastore) NewCustomCVEMatchingRule(ctx context.Context, rule *mdmlab.CustomCVEMatchingRule) (*mdmlab.CustomCVEMatchingRule, error) {
+	var id uint
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		var err error
+		id, err = insertCustomCVEMatchingRuleDB(ctx, tx, rule)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return customCVEMatchingRuleByID(ctx, ds.writer(ctx), id)
+}
+
+func insertCustomCVEMatchingRuleDB(ctx context.Context, tx sqlx.ExtContext, rule *mdmlab.CustomCVEMatchingRule) (uint, error) {
+	cves, err := json.Marshal(rule.CVEs)
+	if err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "marshal custom cve matching rule cves")
+	}
+
+	const stmt = `
+		INSERT INTO custom_cve_matching_rules (name_like_match, source_match, cves, resolved_in_version)
+		VALUES (?, ?, ?, ?)`
+	res, err := tx.ExecContext(ctx, stmt, rule.NameLikeMatch, rule.SourceMatch, cves, rule.ResolvedInVersion)
+	if err != nil {
+		if IsDuplicate(err) {
+			return 0, ctxerr.Wrap(ctx, alreadyExists("CustomCVEMatchingRule",
+				fmt.Sprintf("%s (%s) < %s", rule.NameLikeMatch, rule.SourceMatch, rule.ResolvedInVersion)))
+		}
+		return 0, ctxerr.Wrap(ctx, err, "insert custom cve matching rule")
+	}
+	id, _ := res.LastInsertId()
+	return uint(id), nil //nolint:gosec // dismiss G115
+}
+
+func (ds *Datastore) CustomCVEMatchingRule(ctx context.Context, id uint) (*mdmlab.CustomCVEMatchingRule, error) {
+	return customCVEMatchingRuleByID(ctx, ds.reader(ctx), id)
+}
+
+func customCVEMatchingRuleByID(ctx context.Context, q sqlx.QueryerContext, id uint) (*mdmlab.CustomCVEMatchingRule, error) {
+	const stmt = `
+		SELECT id, name_like_match, source_match, cves, resolved_in_version, created_at, updated_at
+		FROM custom_cve_matching_rules
+		WHERE id = ?`
+	var row customCVEMatchingRuleRow
+	if err := sqlx.GetContext(ctx, q, &row, stmt, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ctxerr.Wrap(ctx, notFound("CustomCVEMatchingRule").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get custom cve matching rule")
+	}
+	return row.toRule(ctx)
+}
+
+func (ds *Datastore) ListCustomCVEMatchingRules(ctx context.Context) ([]*mdmlab.CustomCVEMatchingRule, error) {
+	const stmt = `
+		SELECT id, name_like_match, source_match, cves, resolved_in_version, created_at, updated_at
+		FROM custom_cve_matching_rules
+		ORDER BY id`
+	var rows []customCVEMatchingRuleRow
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rows, stmt); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list custom cve matching rules")
+	}
+
+	rules := make([]*mdmlab.CustomCVEMatchingRule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := row.toRule(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (ds *Datastore) DeleteCustomCVEMatchingRule(ctx context.Context, id uint) error {
+	res, err := ds.writer(ctx).ExecContext(ctx, `DELETE FROM custom_cve_matching_rules WHERE id = ?`, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete custom cve matching rule")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("CustomCVEMatchingRule").WithID(id))
+	}
+	return nil
+}
+
+func (ds *Datastore) BatchSetCustomCVEMatchingRules(ctx context.Context, rules []*mdmlab.CustomCVEMatchingRule) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM custom_cve_matching_rules`); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete custom cve matching rules")
+		}
+		for _, rule := range rules {
+			if _, err := insertCustomCVEMatchingRuleDB(ctx, tx, rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (row customCVEMatchingRuleRow) toRule(ctx context.Context) (*mdmlab.CustomCVEMatchingRule, error) {
+	rule := row.CustomCVEMatchingRule
+	if err := json.Unmarshal(row.CVEsJSON, &rule.CVEs); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "unmarshal custom cve matching rule cves")
+	}
+	return &rule, nil
+}
@@ -0,0 +1,128 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomCVEMatchingRules(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CreateListDelete", testCustomCVEMatchingRulesCreateListDelete},
+		{"BatchSet", testCustomCVEMatchingRulesBatchSet},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testCustomCVEMatchingRulesCreateListDelete(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	rules, err := ds.ListCustomCVEMatchingRules(ctx)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	r1, err := ds.NewCustomCVEMatchingRule(ctx, &mdmlab.CustomCVEMatchingRule{
+		NameLikeMatch:     "Acme Agent%",
+		SourceMatch:       "deb_packages",
+		CVEs:              []string{"CVE-2024-0001", "CVE-2024-0002"},
+		ResolvedInVersion: "2.0.1",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, r1.ID)
+	require.Equal(t, []string{"CVE-2024-0001", "CVE-2024-0002"}, r1.CVEs)
+	require.False(t, r1.CreatedAt.IsZero())
+
+	// same name, source and version is a duplicate
+	_, err = ds.NewCustomCVEMatchingRule(ctx, &mdmlab.CustomCVEMatchingRule{
+		NameLikeMatch:     "Acme Agent%",
+		SourceMatch:       "deb_packages",
+		CVEs:              []string{"CVE-2024-0003"},
+		ResolvedInVersion: "2.0.1",
+	})
+	var existsErr mdmlab.AlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	r2, err := ds.NewCustomCVEMatchingRule(ctx, &mdmlab.CustomCVEMatchingRule{
+		NameLikeMatch:     "Acme Agent%",
+		CVEs:              []string{"CVE-2024-0003"},
+		ResolvedInVersion: "2.0.1",
+	})
+	require.NoError(t, err)
+
+	rules, err = ds.ListCustomCVEMatchingRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, r1.ID, rules[0].ID)
+	require.Equal(t, "deb_packages", rules[0].SourceMatch)
+	require.Equal(t, r2.ID, rules[1].ID)
+	require.Empty(t, rules[1].SourceMatch)
+	require.Equal(t, []string{"CVE-2024-0003"}, rules[1].CVEs)
+
+	rule, err := ds.CustomCVEMatchingRule(ctx, r2.ID)
+	require.NoError(t, err)
+	require.Equal(t, r2, rule)
+
+	require.NoError(t, ds.DeleteCustomCVEMatchingRule(ctx, r1.ID))
+	err = ds.DeleteCustomCVEMatchingRule(ctx, r1.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+	_, err = ds.CustomCVEMatchingRule(ctx, r1.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	rules, err = ds.ListCustomCVEMatchingRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, r2.ID, rules[0].ID)
+}
+
+func testCustomCVEMatchingRulesBatchSet(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	_, err := ds.NewCustomCVEMatchingRule(ctx, &mdmlab.CustomCVEMatchingRule{
+		NameLikeMatch:     "Old rule",
+		CVEs:              []string{"CVE-2023-0001"},
+		ResolvedInVersion: "1.0",
+	})
+	require.NoError(t, err)
+
+	err = ds.BatchSetCustomCVEMatchingRules(ctx, []*mdmlab.CustomCVEMatchingRule{
+		{NameLikeMatch: "Acme Agent%", SourceMatch: "programs", CVEs: []string{"CVE-2024-0001"}, ResolvedInVersion: "2.0"},
+		{NameLikeMatch: "Acme Tools%", SourceMatch: "rpm_packages", CVEs: []string{"CVE-2024-0002"}, ResolvedInVersion: "3.1"},
+	})
+	require.NoError(t, err)
+
+	rules, err := ds.ListCustomCVEMatchingRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "Acme Agent%", rules[0].NameLikeMatch)
+	require.Equal(t, "Acme Tools%", rules[1].NameLikeMatch)
+
+	// duplicates in the batch fail without modifying the existing rules
+	err = ds.BatchSetCustomCVEMatchingRules(ctx, []*mdmlab.CustomCVEMatchingRule{
+		{NameLikeMatch: "Dup", CVEs: []string{"CVE-2024-0001"}, ResolvedInVersion: "2.0"},
+		{NameLikeMatch: "Dup", CVEs: []string{"CVE-2024-0002"}, ResolvedInVersion: "2.0"},
+	})
+	require.Error(t, err)
+
+	rules, err = ds.ListCustomCVEMatchingRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	// an empty batch removes all rules
+	require.NoError(t, ds.BatchSetCustomCVEMatchingRules(ctx, nil))
+	rules, err = ds.ListCustomCVEMatchingRules(ctx)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+}
@@ -0,0 +1,273 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	microsoft_mdm "github.com/it-laborato/MDM_Lab/server/mdm/microsoft"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+func (ds *Datastore) ListHostsToRefetchCertificates(ctx context.Context, interval time.Duration, limit int) ([]*mdmlab.HostCertificateRefetchTarget, error) {
+	// Apple hosts must have an active device enrollment in nano, Windows hosts
+	// an enrolled device in mdm_windows_enrollments. In both cases, hosts for
+	// which a refetch is already in progress are skipped.
+	const stmt = `
+SELECT
+	h.id AS host_id,
+	h.uuid AS host_uuid,
+	h.platform
+FROM
+	hosts h
+	LEFT JOIN host_certificate_syncs hcs
+		ON hcs.host_id = h.id
+	LEFT JOIN host_mdm_commands hmc
+		ON hmc.host_id = h.id AND hmc.command_type = ?
+WHERE
+	TRIM(h.uuid) != '' AND
+	hmc.host_id IS NULL AND
+	(hcs.synced_at IS NULL OR hcs.synced_at < NOW() - INTERVAL ? SECOND) AND
+	(
+		(
+			h.platform IN ('darwin', 'ios', 'ipados') AND
+			EXISTS (
+				SELECT 1 FROM nano_enrollments ne
+				WHERE ne.id = h.uuid AND ne.enabled = 1 AND ne.type IN ('Device', 'User Enrollment (Device)')
+			)
+		) OR (
+			h.platform = 'windows' AND
+			EXISTS (
+				SELECT 1 FROM mdm_windows_enrollments mwe
+				WHERE mwe.host_uuid = h.uuid AND mwe.device_state = ?
+			)
+		)
+	)
+ORDER BY
+	hcs.synced_at IS NOT NULL, hcs.synced_at, h.id
+LIMIT ?`
+
+	var hosts []*mdmlab.HostCertificateRefetchTarget
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &hosts, stmt,
+		mdmlab.RefetchCertificatesCommandUUIDPrefix, interval.Seconds(), microsoft_mdm.MDMDeviceStateEnrolled, limit); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list hosts to refetch certificates")
+	}
+	return hosts, nil
+}
+
+func (ds *Datastore) ReplaceHostCertificates(ctx context.Context, hostID uint, source mdmlab.HostCertificateSource, certs []*mdmlab.HostCertificate) error {
+	// the managed_by_mdmlab and expiration_notified_at columns are
+	// intentionally not updated for existing certificates, they are tracked
+	// separately.
+	const insStmt = `
+INSERT INTO host_certificates (
+	host_id, sha1_sum, common_name, subject, issuer, serial_number,
+	not_valid_before, not_valid_after, source
+) VALUES %s
+ON DUPLICATE KEY UPDATE
+	common_name = VALUES(common_name),
+	subject = VALUES(subject),
+	issuer = VALUES(issuer),
+	serial_number = VALUES(serial_number),
+	not_valid_before = VALUES(not_valid_before),
+	not_valid_after = VALUES(not_valid_after),
+	source = VALUES(source),
+	updated_at = CURRENT_TIMESTAMP(6)`
+
+	const delAllStmt = `DELETE FROM host_certificates WHERE host_id = ? AND source = ?`
+
+	const delStmt = `DELETE FROM host_certificates WHERE host_id = ? AND source = ? AND sha1_sum NOT IN (?)`
+
+	const syncStmt = `
+INSERT INTO host_certificate_syncs (host_id, synced_at)
+VALUES (?, CURRENT_TIMESTAMP(6))
+ON DUPLICATE KEY UPDATE synced_at = VALUES(synced_at)`
+
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if len(certs) == 0 {
+			if _, err := tx.ExecContext(ctx, delAllStmt, hostID, source); err != nil {
+				return ctxerr.Wrap(ctx, err, "delete host certificates")
+			}
+		} else {
+			values := strings.TrimSuffix(strings.Repeat("(?,?,?,?,?,?,?,?,?),", len(certs)), ",")
+			args := make([]any, 0, len(certs)*9)
+			sums := make([]string, 0, len(certs))
+			for _, c := range certs {
+				args = append(args, hostID, c.SHA1Sum, c.CommonName, c.Subject, c.Issuer, c.SerialNumber,
+					c.NotValidBefore, c.NotValidAfter, source)
+				sums = append(sums, c.SHA1Sum)
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(insStmt, values), args...); err != nil {
+				return ctxerr.Wrap(ctx, err, "upsert host certificates")
+			}
+
+			stmt, args, err := sqlx.In(delStmt, hostID, source, sums)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "build delete host certificates query")
+			}
+			if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+				return ctxerr.Wrap(ctx, err, "delete stale host certificates")
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, syncStmt, hostID); err != nil {
+			return ctxerr.Wrap(ctx, err, "update host certificates sync timestamp")
+		}
+		return nil
+	})
+}
+
+func (ds *Datastore) SetHostCertificatesManaged(ctx context.Context, hostID uint, source mdmlab.HostCertificateSource, sha1Sums []string) error {
+	stmt := `UPDATE host_certificates SET managed_by_mdmlab = 0 WHERE host_id = ? AND source = ?`
+	args := []any{hostID, source}
+	if len(sha1Sums) > 0 {
+		var err error
+		stmt, args, err = sqlx.In(`UPDATE host_certificates SET managed_by_mdmlab = (sha1_sum IN (?)) WHERE host_id = ? AND source = ?`,
+			sha1Sums, hostID, source)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "build update managed host certificates query")
+		}
+	}
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "update managed host certificates")
+	}
+	return nil
+}
+
+const hostCertificateColumns = `
+	hc.id,
+	hc.host_id,
+	hc.sha1_sum,
+	hc.common_name,
+	hc.subject,
+	hc.issuer,
+	hc.serial_number,
+	hc.not_valid_before,
+	hc.not_valid_after,
+	hc.managed_by_mdmlab,
+	hc.source,
+	hc.updated_at`
+
+func (ds *Datastore) ListHostCertificates(ctx context.Context, hostID uint, opt mdmlab.ListOptions) ([]*mdmlab.HostCertificate, *mdmlab.PaginationMetadata, error) {
+	listStmt := `
+SELECT * FROM (
+	SELECT ` + hostCertificateColumns + `
+	FROM host_certificates hc
+	WHERE hc.host_id = ?
+) AS certs WHERE TRUE`
+
+	args := []any{hostID}
+	listStmt, args = searchLike(listStmt, args, opt.MatchQuery, "common_name", "subject", "issuer", "serial_number", "sha1_sum")
+	stmt, args := appendListOptionsWithCursorToSQL(listStmt, args, &opt)
+
+	var certs []*mdmlab.HostCertificate
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &certs, stmt, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list host certificates")
+	}
+
+	var metaData *mdmlab.PaginationMetadata
+	if opt.IncludeMetadata {
+		metaData = &mdmlab.PaginationMetadata{HasPreviousResults: opt.Page > 0}
+		if len(certs) > int(opt.PerPage) { //nolint:gosec // dismiss G115
+			metaData.HasNextResults = true
+			certs = certs[:len(certs)-1]
+		}
+	}
+	return certs, metaData, nil
+}
+
+func (ds *Datastore) SearchHostCertificates(ctx context.Context, filter mdmlab.TeamFilter, opt mdmlab.HostCertificateListOptions) ([]*mdmlab.HostCertificateWithHost, *mdmlab.PaginationMetadata, error) {
+	var (
+		where []string
+		args  []any
+	)
+	where = append(where, ds.whereFilterHostsByTeams(filter, "h"))
+	if opt.TeamID != nil {
+		if *opt.TeamID == 0 {
+			where = append(where, "h.team_id IS NULL")
+		} else {
+			where = append(where, "h.team_id = ?")
+			args = append(args, *opt.TeamID)
+		}
+	}
+	if opt.ExpiresWithinDays != nil {
+		where = append(where, "hc.not_valid_after <= NOW() + INTERVAL ? DAY")
+		args = append(args, *opt.ExpiresWithinDays)
+	}
+	if opt.ManagedByMDMlab != nil {
+		where = append(where, "hc.managed_by_mdmlab = ?")
+		args = append(args, *opt.ManagedByMDMlab)
+	}
+
+	listStmt := `
+SELECT * FROM (
+	SELECT ` + hostCertificateColumns + `,
+		COALESCE(hdn.display_name, '') AS host_display_name,
+		h.team_id AS host_team_id
+	FROM host_certificates hc
+	INNER JOIN hosts h
+		ON h.id = hc.host_id
+	LEFT JOIN host_display_names hdn
+		ON hdn.host_id = h.id
+	WHERE ` + strings.Join(where, " AND ") + `
+) AS certs WHERE TRUE`
+
+	listStmt, args = searchLike(listStmt, args, opt.ListOptions.MatchQuery, "common_name", "subject", "issuer", "serial_number", "sha1_sum", "host_display_name")
+	stmt, args := appendListOptionsWithCursorToSQL(listStmt, args, &opt.ListOptions)
+
+	var certs []*mdmlab.HostCertificateWithHost
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &certs, stmt, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "search host certificates")
+	}
+
+	var metaData *mdmlab.PaginationMetadata
+	if opt.ListOptions.IncludeMetadata {
+		metaData = &mdmlab.PaginationMetadata{HasPreviousResults: opt.ListOptions.Page > 0}
+		if len(certs) > int(opt.ListOptions.PerPage) { //nolint:gosec // dismiss G115
+			metaData.HasNextResults = true
+			certs = certs[:len(certs)-1]
+		}
+	}
+	return certs, metaData, nil
+}
+
+func (ds *Datastore) ListExpiringHostCertificatesToNotify(ctx context.Context, within time.Duration, limit int) ([]*mdmlab.HostCertificateWithHost, error) {
+	const stmt = `
+SELECT ` + hostCertificateColumns + `,
+	COALESCE(hdn.display_name, '') AS host_display_name,
+	h.team_id AS host_team_id
+FROM host_certificates hc
+INNER JOIN hosts h
+	ON h.id = hc.host_id
+LEFT JOIN host_display_names hdn
+	ON hdn.host_id = h.id
+WHERE
+	hc.expiration_notified_at IS NULL AND
+	hc.not_valid_after > NOW() AND
+	hc.not_valid_after <= NOW() + INTERVAL ? SECOND
+ORDER BY hc.not_valid_after, hc.id
+LIMIT ?`
+
+	var certs []*mdmlab.HostCertificateWithHost
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &certs, stmt, within.Seconds(), limit); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list expiring host certificates")
+	}
+	return certs, nil
+}
+
+func (ds *Datastore) SetHostCertificatesExpirationNotified(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	stmt, args, err := sqlx.In(`UPDATE host_certificates SET expiration_notified_at = CURRENT_TIMESTAMP(6) WHERE id IN (?)`, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build update expiration notified query")
+	}
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "update host certificates expiration notified")
+	}
+	return nil
+}
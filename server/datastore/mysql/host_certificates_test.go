@@ -0,0 +1,286 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	microsoft_mdm "github.com/it-laborato/MDM_Lab/server/mdm/microsoft"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostCertificates(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"ReplaceAndList", testHostCertificatesReplaceAndList},
+		{"ListHostsToRefetch", testHostCertificatesListHostsToRefetch},
+		{"Search", testHostCertificatesSearch},
+		{"ExpiringToNotify", testHostCertificatesExpiringToNotify},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func newTestHostCertificate(sum, cn string, notAfter time.Time) *mdmlab.HostCertificate {
+	return &mdmlab.HostCertificate{
+		SHA1Sum:        sum,
+		CommonName:     cn,
+		Subject:        "CN=" + cn,
+		Issuer:         "CN=issuer",
+		SerialNumber:   "01",
+		NotValidBefore: notAfter.Add(-365 * 24 * time.Hour).Truncate(time.Second),
+		NotValidAfter:  notAfter.Truncate(time.Second),
+	}
+}
+
+func testHostCertificatesReplaceAndList(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	h := test.NewHost(t, ds, "host1", "1.1.1.1", "key1", "uuid1", now)
+
+	certs, _, err := ds.ListHostCertificates(ctx, h.ID, mdmlab.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, certs)
+
+	err = ds.ReplaceHostCertificates(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, []*mdmlab.HostCertificate{
+		newTestHostCertificate("a", "cert-a", now.Add(24*time.Hour)),
+		newTestHostCertificate("b", "cert-b", now.Add(48*time.Hour)),
+	})
+	require.NoError(t, err)
+
+	err = ds.SetHostCertificatesManaged(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, []string{"b"})
+	require.NoError(t, err)
+
+	certs, meta, err := ds.ListHostCertificates(ctx, h.ID, mdmlab.ListOptions{OrderKey: "common_name", IncludeMetadata: true})
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	require.False(t, meta.HasNextResults)
+	require.Equal(t, "cert-a", certs[0].CommonName)
+	require.False(t, certs[0].ManagedByMDMlab)
+	require.Equal(t, "cert-b", certs[1].CommonName)
+	require.True(t, certs[1].ManagedByMDMlab)
+	require.Equal(t, mdmlab.HostCertificateSourceAppleMDM, certs[1].Source)
+
+	// replacing keeps the managed flag of existing certificates and removes
+	// those not reported anymore
+	err = ds.ReplaceHostCertificates(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, []*mdmlab.HostCertificate{
+		newTestHostCertificate("b", "cert-b-renamed", now.Add(48*time.Hour)),
+		newTestHostCertificate("c", "cert-c", now.Add(72*time.Hour)),
+	})
+	require.NoError(t, err)
+
+	certs, _, err = ds.ListHostCertificates(ctx, h.ID, mdmlab.ListOptions{OrderKey: "common_name"})
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	require.Equal(t, "cert-b-renamed", certs[0].CommonName)
+	require.True(t, certs[0].ManagedByMDMlab)
+	require.Equal(t, "cert-c", certs[1].CommonName)
+	require.False(t, certs[1].ManagedByMDMlab)
+
+	// matching query
+	certs, _, err = ds.ListHostCertificates(ctx, h.ID, mdmlab.ListOptions{MatchQuery: "cert-c"})
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	require.Equal(t, "c", certs[0].SHA1Sum)
+
+	// no certificate marked as managed anymore
+	err = ds.SetHostCertificatesManaged(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, nil)
+	require.NoError(t, err)
+	certs, _, err = ds.ListHostCertificates(ctx, h.ID, mdmlab.ListOptions{})
+	require.NoError(t, err)
+	for _, c := range certs {
+		require.False(t, c.ManagedByMDMlab)
+	}
+
+	// replacing with an empty list removes all certificates
+	err = ds.ReplaceHostCertificates(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, nil)
+	require.NoError(t, err)
+	certs, _, err = ds.ListHostCertificates(ctx, h.ID, mdmlab.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, certs)
+}
+
+func testHostCertificatesListHostsToRefetch(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// not enrolled in MDM
+	test.NewHost(t, ds, "host0", "1.1.1.1", "key0", "uuid0", now)
+
+	hMac := test.NewHost(t, ds, "host1", "1.1.1.1", "key1", "uuid1", now)
+	nanoEnroll(t, ds, hMac, false)
+
+	hWin := test.NewHost(t, ds, "host2", "1.1.1.1", "key2", "uuid2", now, test.WithPlatform("windows"))
+	err := ds.MDMWindowsInsertEnrolledDevice(ctx, &mdmlab.MDMWindowsEnrolledDevice{
+		MDMDeviceID:            "device2",
+		MDMHardwareID:          "hardware2",
+		MDMDeviceState:         microsoft_mdm.MDMDeviceStateEnrolled,
+		MDMDeviceType:          "CIMClient_Windows",
+		MDMDeviceName:          "DESKTOP-1C3ARC1",
+		MDMEnrollType:          "ProgrammaticEnrollment",
+		MDMEnrollProtoVersion:  "5.0",
+		MDMEnrollClientVersion: "10.0.19045.2965",
+		HostUUID:               hWin.UUID,
+	})
+	require.NoError(t, err)
+
+	hosts, err := ds.ListHostsToRefetchCertificates(ctx, time.Hour, 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*mdmlab.HostCertificateRefetchTarget{
+		{HostID: hMac.ID, HostUUID: hMac.UUID, Platform: "darwin"},
+		{HostID: hWin.ID, HostUUID: hWin.UUID, Platform: "windows"},
+	}, hosts)
+
+	hosts, err = ds.ListHostsToRefetchCertificates(ctx, time.Hour, 1)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+
+	// a refetch is in progress for the macOS host
+	err = ds.AddHostMDMCommands(ctx, []mdmlab.HostMDMCommand{{HostID: hMac.ID, CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix}})
+	require.NoError(t, err)
+	// the Windows host was just refetched
+	err = ds.ReplaceHostCertificates(ctx, hWin.ID, mdmlab.HostCertificateSourceWindowsMDM, nil)
+	require.NoError(t, err)
+
+	hosts, err = ds.ListHostsToRefetchCertificates(ctx, time.Hour, 10)
+	require.NoError(t, err)
+	require.Empty(t, hosts)
+
+	// refetch completed for the macOS host, the Windows one is due again
+	err = ds.RemoveHostMDMCommand(ctx, mdmlab.HostMDMCommand{HostID: hMac.ID, CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix})
+	require.NoError(t, err)
+	ExecAdhocSQL(t, ds, func(q sqlx.ExtContext) error {
+		_, err := q.ExecContext(ctx, `UPDATE host_certificate_syncs SET synced_at = NOW() - INTERVAL 2 HOUR WHERE host_id = ?`, hWin.ID)
+		return err
+	})
+
+	hosts, err = ds.ListHostsToRefetchCertificates(ctx, time.Hour, 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*mdmlab.HostCertificateRefetchTarget{
+		{HostID: hMac.ID, HostUUID: hMac.UUID, Platform: "darwin"},
+		{HostID: hWin.ID, HostUUID: hWin.UUID, Platform: "windows"},
+	}, hosts)
+}
+
+func testHostCertificatesSearch(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	h1 := test.NewHost(t, ds, "host1", "1.1.1.1", "key1", "uuid1", now)
+	h2 := test.NewHost(t, ds, "host2", "1.1.1.1", "key2", "uuid2", now, test.WithTeamID(team.ID))
+
+	err = ds.ReplaceHostCertificates(ctx, h1.ID, mdmlab.HostCertificateSourceAppleMDM, []*mdmlab.HostCertificate{
+		newTestHostCertificate("a", "cert-a", now.Add(24*time.Hour)),
+		newTestHostCertificate("b", "cert-b", now.Add(60*24*time.Hour)),
+	})
+	require.NoError(t, err)
+	err = ds.SetHostCertificatesManaged(ctx, h1.ID, mdmlab.HostCertificateSourceAppleMDM, []string{"a"})
+	require.NoError(t, err)
+	err = ds.ReplaceHostCertificates(ctx, h2.ID, mdmlab.HostCertificateSourceWindowsMDM, []*mdmlab.HostCertificate{
+		newTestHostCertificate("c", "cert-c", now.Add(-24*time.Hour)),
+	})
+	require.NoError(t, err)
+
+	admin := &mdmlab.User{GlobalRole: ptr.String(mdmlab.RoleAdmin)}
+	teamUser := &mdmlab.User{Teams: []mdmlab.UserTeam{{Team: *team, Role: mdmlab.RoleObserver}}}
+
+	sums := func(certs []*mdmlab.HostCertificateWithHost) []string {
+		var res []string
+		for _, c := range certs {
+			res = append(res, c.SHA1Sum)
+		}
+		return res
+	}
+
+	cases := []struct {
+		desc   string
+		filter mdmlab.TeamFilter
+		opts   mdmlab.HostCertificateListOptions
+		want   []string
+	}{
+		{"all", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{}, []string{"a", "b", "c"}},
+		{"team user", mdmlab.TeamFilter{User: teamUser, IncludeObserver: true}, mdmlab.HostCertificateListOptions{}, []string{"c"}},
+		{"no team", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{TeamID: ptr.Uint(0)}, []string{"a", "b"}},
+		{"team", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{TeamID: &team.ID}, []string{"c"}},
+		{"expiring", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{ExpiresWithinDays: ptr.Uint(30)}, []string{"a", "c"}},
+		{"managed", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{ManagedByMDMlab: ptr.Bool(true)}, []string{"a"}},
+		{"query", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{ListOptions: mdmlab.ListOptions{MatchQuery: "cert-b"}}, []string{"b"}},
+		{"host name", mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{ListOptions: mdmlab.ListOptions{MatchQuery: "host2"}}, []string{"c"}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			c.opts.ListOptions.OrderKey = "sha1_sum"
+			certs, _, err := ds.SearchHostCertificates(ctx, c.filter, c.opts)
+			require.NoError(t, err)
+			require.Equal(t, c.want, sums(certs))
+		})
+	}
+
+	certs, meta, err := ds.SearchHostCertificates(ctx, mdmlab.TeamFilter{User: admin}, mdmlab.HostCertificateListOptions{
+		ListOptions: mdmlab.ListOptions{OrderKey: "sha1_sum", PerPage: 2, IncludeMetadata: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, sums(certs))
+	require.True(t, meta.HasNextResults)
+	require.Equal(t, "host1", certs[0].HostDisplayName)
+	require.Nil(t, certs[0].HostTeamID)
+}
+
+func testHostCertificatesExpiringToNotify(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	h := test.NewHost(t, ds, "host1", "1.1.1.1", "key1", "uuid1", now)
+	err := ds.ReplaceHostCertificates(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, []*mdmlab.HostCertificate{
+		newTestHostCertificate("a", "cert-a", now.Add(24*time.Hour)),
+		newTestHostCertificate("b", "cert-b", now.Add(60*24*time.Hour)),
+		newTestHostCertificate("c", "cert-c", now.Add(-24*time.Hour)),
+	})
+	require.NoError(t, err)
+
+	certs, err := ds.ListExpiringHostCertificatesToNotify(ctx, 30*24*time.Hour, 10)
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	require.Equal(t, "a", certs[0].SHA1Sum)
+	require.Equal(t, "host1", certs[0].HostDisplayName)
+
+	err = ds.SetHostCertificatesExpirationNotified(ctx, []uint{certs[0].ID})
+	require.NoError(t, err)
+
+	certs, err = ds.ListExpiringHostCertificatesToNotify(ctx, 30*24*time.Hour, 10)
+	require.NoError(t, err)
+	require.Empty(t, certs)
+
+	// still notified after the inventory is refreshed
+	err = ds.ReplaceHostCertificates(ctx, h.ID, mdmlab.HostCertificateSourceAppleMDM, []*mdmlab.HostCertificate{
+		newTestHostCertificate("a", "cert-a", now.Add(24*time.Hour)),
+		newTestHostCertificate("b", "cert-b", now.Add(60*24*time.Hour)),
+	})
+	require.NoError(t, err)
+
+	certs, err = ds.ListExpiringHostCertificatesToNotify(ctx, 30*24*time.Hour, 10)
+	require.NoError(t, err)
+	require.Empty(t, certs)
+
+	certs, err = ds.ListExpiringHostCertificatesToNotify(ctx, 90*24*time.Hour, 10)
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	require.Equal(t, "b", certs[0].SHA1Sum)
+}
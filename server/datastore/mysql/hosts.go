@@ -548,6 +548,12 @@ var hostRefs = []string{
 	"host_activities",
 	"host_mdm_actions",
 	"host_calendar_events",
+	"host_certificates",
+	"host_certificate_syncs",
+	"host_vulnerabilities",
+	"software_blocklist_removals",
+	"software_requests",
+	"vulnerability_suppressions",
 }
 
 // NOTE: The following tables are explicity excluded from hostRefs list and accordingly are not
@@ -569,6 +575,8 @@ var additionalHostRefsByUUID = map[string]string{
 	"host_mdm_apple_declarations":           "host_uuid",
 	"host_mdm_apple_awaiting_configuration": "host_uuid",
 	"setup_experience_status_results":       "host_uuid",
+	"host_mdm_apple_device_locations":       "host_uuid",
+	"host_mdm_apple_recovery_locks":         "host_uuid",
 }
 
 // additionalHostRefsSoftDelete are tables that reference a host but for which
@@ -638,6 +646,12 @@ func deleteHosts(ctx context.Context, tx sqlx.ExtContext, hostIDs []uint) error
 
 	// no point trying the uuid-based tables if the host's uuid is missing
 	if len(hostUUIDs) != 0 {
+		// the escrowed recovery lock is the only copy of the password set on
+		// the host, archive it before it gets deleted below.
+		if err := archiveHostRecoveryLocksDB(ctx, tx, hostUUIDs); err != nil {
+			return err
+		}
+
 		for table, col := range additionalHostRefsByUUID {
 			stmt, args, err := sqlx.In(fmt.Sprintf("DELETE FROM `%s` WHERE `%s` IN (?)", table, col), hostUUIDs)
 			if err != nil {
@@ -2056,6 +2070,10 @@ func (ds *Datastore) EnrollOrbit(ctx context.Context, isMDMEnabled bool, hostInf
 			}
 			host.ID = uint(hostID)
 
+			if err := restoreHostRecoveryLockDB(ctx, tx, hostInfo.HardwareUUID); err != nil {
+				return ctxerr.Wrap(ctx, err, "orbit enroll error restoring recovery lock")
+			}
+
 		default:
 			return ctxerr.Wrap(ctx, err, "orbit enroll error selecting host details")
 		}
@@ -2114,6 +2132,10 @@ func (ds *Datastore) EnrollHost(ctx context.Context, isMDMEnabled bool, osqueryH
 				return ctxerr.Wrap(ctx, err, "insert host_display_names")
 			}
 			hostID = uint(lastInsertID)
+
+			if err := restoreHostRecoveryLockDB(ctx, tx, hardwareUUID); err != nil {
+				return ctxerr.Wrap(ctx, err, "restore recovery lock")
+			}
 		default:
 			hostID = enrolledHostInfo.ID
 
@@ -5440,21 +5462,26 @@ func (ds *Datastore) UpdateHostIssuesVulnerabilities(ctx context.Context) error
 		criticalVulnerabilitiesCountStmt := `
 		SELECT combined.host_id, COUNT(*) as count
 		FROM (
-			SELECT host_id, sc.cve
+			SELECT hs.host_id, sc.cve
 			FROM host_software hs
 			INNER JOIN software_cve sc ON sc.software_id = hs.software_id
+			INNER JOIN software s ON s.id = sc.software_id
 			INNER JOIN cve_meta cm ON cm.cve = sc.cve
-			WHERE host_id IN (?)
+			LEFT JOIN hosts hsup ON hsup.id = hs.host_id
+			WHERE hs.host_id IN (?)
 			AND cm.cvss_score > ?
+			AND NOT ` + vulnerabilitySuppressedCondition("sc.cve", "hsup.team_id", "hs.host_id", "s.title_id") + `
 
 			UNION
 
-			SELECT host_id, osv.cve
+			SELECT hos.host_id, osv.cve
 			FROM host_operating_system hos
 			INNER JOIN operating_system_vulnerabilities osv ON osv.operating_system_id = hos.os_id
 			INNER JOIN cve_meta cm ON cm.cve = osv.cve
-			WHERE host_id IN (?)
+			LEFT JOIN hosts hsup ON hsup.id = hos.host_id
+			WHERE hos.host_id IN (?)
 			AND cm.cvss_score > ?
+			AND NOT ` + vulnerabilitySuppressedCondition("osv.cve", "hsup.team_id", "hos.host_id", "NULL") + `
 		) combined
 		INNER JOIN cve_meta cm ON cm.cve = combined.cve
 		GROUP BY combined.host_id
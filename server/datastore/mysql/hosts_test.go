@@ -7022,6 +7022,22 @@ func testHostsDeleteHosts(t *testing.T, ds *Datastore) {
 			`, host.ID, calendarEventID)
 	require.NoError(t, err)
 
+	// Add a certificate for the host.
+	err = ds.ReplaceHostCertificates(context.Background(), host.ID, mdmlab.HostCertificateSourceAppleMDM, []*mdmlab.HostCertificate{
+		{SHA1Sum: "abc", CommonName: "cert", NotValidBefore: time.Now(), NotValidAfter: time.Now().Add(24 * time.Hour)},
+	})
+	require.NoError(t, err)
+
+	// Track a vulnerability of the host.
+	_, err = ds.writer(context.Background()).Exec(`INSERT INTO host_vulnerabilities (host_id, cve) VALUES (?, 'CVE-2024-0001')`, host.ID)
+	require.NoError(t, err)
+
+	// Track the removal of blocklisted software from the host.
+	blocklistRule, err := ds.NewSoftwareBlocklistRule(context.Background(), &mdmlab.SoftwareBlocklistRule{Name: "uTorrent.app"}, nil)
+	require.NoError(t, err)
+	err = ds.NewSoftwareBlocklistRemoval(context.Background(), blocklistRule.ID, host.ID, uuid.NewString())
+	require.NoError(t, err)
+
 	softwareInstaller, _, err := ds.MatchOrCreateSoftwareInstaller(context.Background(), &mdmlab.UploadSoftwareInstallerPayload{
 		InstallScript:   "",
 		PreInstallQuery: "",
@@ -7033,10 +7049,36 @@ func testHostsDeleteHosts(t *testing.T, ds *Datastore) {
 	_, err = ds.InsertSoftwareInstallRequest(context.Background(), host.ID, softwareInstaller, false, nil)
 	require.NoError(t, err)
 
+	// Request software from the host.
+	_, err = ds.NewSoftwareRequest(context.Background(), &mdmlab.SoftwareRequest{HostID: host.ID, SoftwareTitleID: 1, SoftwareTitle: "ChocolateRain", Justification: "needed"})
+	require.NoError(t, err)
+
+	// Suppress a vulnerability on the host.
+	_, err = ds.NewVulnerabilitySuppression(context.Background(), &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-1234",
+		HostID:        &host.ID,
+		Justification: mdmlab.VEXJustificationVulnerableCodeNotInExecutePath,
+	})
+	require.NoError(t, err)
+
 	// Add an awaiting configuration entry
 	err = ds.SetHostAwaitingConfiguration(ctx, host.UUID, false)
 	require.NoError(t, err)
 
+	// Record the device location reported by lost mode
+	err = ds.SetHostMDMAppleDeviceLocation(ctx, &mdmlab.HostMDMAppleDeviceLocation{
+		HostUUID:    host.UUID,
+		CommandUUID: uuid.NewString(),
+		Latitude:    37.33,
+		Longitude:   -122.01,
+		LocatedAt:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	// Escrow a recovery lock password
+	err = ds.SetHostRecoveryLockPending(ctx, host.UUID, mdmlab.RecoveryLockPasswordTypeRecoveryLock, "password", uuid.NewString(), nil)
+	require.NoError(t, err)
+
 	// Add a setup experience status result
 	err = ds.SetSetupExperienceScript(ctx, &mdmlab.Script{Name: "test.sh", ScriptContents: "echo foo"})
 	require.NoError(t, err)
@@ -10035,6 +10077,26 @@ func testUpdateHostIssues(t *testing.T, ds *Datastore) {
 		assert.Zero(t, hostIssue.TotalIssuesCount, "host issue: %+v", hostIssue)
 	}
 	assert.True(t, hostIssueFound)
+
+	// suppressed vulnerabilities are not counted as issues
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-100",
+		HostID:        &hosts[1].ID,
+		Justification: mdmlab.VEXJustificationVulnerableCodeNotInExecutePath,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, ds.UpdateHostIssuesVulnerabilities(ctx))
+	issues = nil
+	assert.NoError(
+		t, sqlx.SelectContext(
+			ctx, ds.reader(ctx), &issues,
+			"SELECT host_id, failing_policies_count, critical_vulnerabilities_count, total_issues_count from host_issues ORDER BY host_id",
+		),
+	)
+	for _, hostIssue := range issues {
+		assert.Zero(t, *hostIssue.CriticalVulnerabilitiesCount, "host issue: %+v", hostIssue)
+		assert.Zero(t, hostIssue.TotalIssuesCount, "host issue: %+v", hostIssue)
+	}
 }
 
 func testListUpcomingHostMaintenanceWindows(t *testing.T, ds *Datastore) {
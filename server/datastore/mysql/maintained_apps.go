@@ -126,8 +126,17 @@ func (ds *Datastore) ListAvailableMDMlabMaintainedApps(ctx context.Context, team
 				OR
 				(va.platform = fla.platform AND vat.global_or_team_id = ?)
 			)
+		) AND NOT EXISTS (
+			-- Linux apps have no bundle identifier
+			SELECT
+				1
+			FROM
+				software_installers si
+			WHERE
+				si.mdmlab_library_app_id = fla.id
+			AND si.global_or_team_id = ?
 		)`
-		args = []any{teamID, teamID}
+		args = []any{teamID, teamID, teamID}
 	} else {
 		stmt += `WHERE TRUE`
 	}
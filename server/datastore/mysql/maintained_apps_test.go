@@ -58,7 +58,7 @@ func testUpsertMaintainedApps(t *testing.T, ds *Datastore) {
 		Token:        "figma",
 		InstallerURL: "https://desktop.figma.com/mac-arm/Figma-999.9.9.zip",
 		Version:      "999.9.9",
-		Platform:     mdmlab.MacOSPlatform,
+		Platform:     string(mdmlab.MacOSPlatform),
 	})
 	require.NoError(t, err)
 
@@ -102,7 +102,7 @@ func testListAvailableApps(t *testing.T, ds *Datastore) {
 		Name:             "Maintained1",
 		Token:            "maintained1",
 		Version:          "1.0.0",
-		Platform:         mdmlab.MacOSPlatform,
+		Platform:         string(mdmlab.MacOSPlatform),
 		InstallerURL:     "http://example.com/main1",
 		SHA256:           "DEADBEEF",
 		BundleIdentifier: "mdmlab.maintained1",
@@ -115,7 +115,7 @@ func testListAvailableApps(t *testing.T, ds *Datastore) {
 		Name:             "Maintained2",
 		Token:            "maintained2",
 		Version:          "1.0.0",
-		Platform:         mdmlab.MacOSPlatform,
+		Platform:         string(mdmlab.MacOSPlatform),
 		InstallerURL:     "http://example.com/main1",
 		SHA256:           "DEADBEEF",
 		BundleIdentifier: "mdmlab.maintained2",
@@ -127,7 +127,7 @@ func testListAvailableApps(t *testing.T, ds *Datastore) {
 		Name:             "Maintained3",
 		Token:            "maintained3",
 		Version:          "1.0.0",
-		Platform:         mdmlab.MacOSPlatform,
+		Platform:         string(mdmlab.MacOSPlatform),
 		InstallerURL:     "http://example.com/main1",
 		SHA256:           "DEADBEEF",
 		BundleIdentifier: "mdmlab.maintained3",
@@ -0,0 +1,43 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250124101530, Down_20250124101530)
+}
+
+func Up_20250124101530(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE host_mdm_actions
+		ADD COLUMN lost_mode_ref VARCHAR(36) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+		ADD COLUMN lost_mode_disable_ref VARCHAR(36) COLLATE utf8mb4_unicode_ci DEFAULT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to add lost mode columns to host_mdm_actions: %w", err)
+	}
+
+	_, err = tx.Exec(`
+CREATE TABLE IF NOT EXISTS host_mdm_apple_device_locations (
+  host_uuid VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  command_uuid VARCHAR(127) COLLATE utf8mb4_unicode_ci NOT NULL,
+  latitude DOUBLE NOT NULL,
+  longitude DOUBLE NOT NULL,
+  altitude DOUBLE DEFAULT NULL,
+  horizontal_accuracy DOUBLE DEFAULT NULL,
+  vertical_accuracy DOUBLE DEFAULT NULL,
+  located_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (host_uuid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create host_mdm_apple_device_locations table: %w", err)
+	}
+
+	return nil
+}
+
+func Down_20250124101530(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250124101530(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO host_mdm_actions (host_id, lock_ref) VALUES (1, 'lock-uuid')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	var refs struct {
+		LockRef            *string `db:"lock_ref"`
+		LostModeRef        *string `db:"lost_mode_ref"`
+		LostModeDisableRef *string `db:"lost_mode_disable_ref"`
+	}
+	require.NoError(t, db.Get(&refs, `SELECT lock_ref, lost_mode_ref, lost_mode_disable_ref FROM host_mdm_actions WHERE host_id = 1`))
+	require.NotNil(t, refs.LockRef)
+	require.Equal(t, "lock-uuid", *refs.LockRef)
+	require.Nil(t, refs.LostModeRef)
+	require.Nil(t, refs.LostModeDisableRef)
+
+	execNoErr(t, db, `UPDATE host_mdm_actions SET lost_mode_ref = 'lost-uuid' WHERE host_id = 1`)
+	execNoErr(t, db, `INSERT INTO host_mdm_apple_device_locations (host_uuid, command_uuid, latitude, longitude) VALUES ('abc', 'loc-uuid', 45.5, -73.6)`)
+
+	var lat, lng float64
+	require.NoError(t, db.QueryRow(`SELECT latitude, longitude FROM host_mdm_apple_device_locations WHERE host_uuid = 'abc'`).Scan(&lat, &lng))
+	require.Equal(t, 45.5, lat)
+	require.Equal(t, -73.6, lng)
+}
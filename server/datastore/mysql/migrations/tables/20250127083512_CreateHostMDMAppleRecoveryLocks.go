@@ -0,0 +1,38 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250127083512, Down_20250127083512)
+}
+
+func Up_20250127083512(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS host_mdm_apple_recovery_locks (
+  host_uuid VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  password_type VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  encrypted_password BLOB DEFAULT NULL,
+  pending_encrypted_password BLOB DEFAULT NULL,
+  status VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  detail TEXT COLLATE utf8mb4_unicode_ci,
+  command_uuid VARCHAR(127) COLLATE utf8mb4_unicode_ci NOT NULL,
+  verify_command_uuid VARCHAR(127) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  rotate_at TIMESTAMP NULL DEFAULT NULL,
+  revealed_at TIMESTAMP(6) NULL DEFAULT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (host_uuid),
+  KEY idx_host_mdm_apple_recovery_locks_status_rotate_at (status, rotate_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create host_mdm_apple_recovery_locks table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250127083512(tx *sql.Tx) error {
+	return nil
+}
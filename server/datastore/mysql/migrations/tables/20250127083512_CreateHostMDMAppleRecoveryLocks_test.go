@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250127083512(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO host_mdm_apple_recovery_locks (host_uuid, password_type, pending_encrypted_password, status, command_uuid)
+		VALUES ('abc', 'recovery_lock', 'enc', 'pending', 'cmd-uuid')`)
+
+	var lock struct {
+		Status            string  `db:"status"`
+		EncryptedPassword []byte  `db:"encrypted_password"`
+		VerifyCommandUUID *string `db:"verify_command_uuid"`
+	}
+	require.NoError(t, db.Get(&lock, `SELECT status, encrypted_password, verify_command_uuid FROM host_mdm_apple_recovery_locks WHERE host_uuid = 'abc'`))
+	require.Equal(t, "pending", lock.Status)
+	require.Nil(t, lock.EncryptedPassword)
+	require.Nil(t, lock.VerifyCommandUUID)
+}
@@ -0,0 +1,53 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250129093021, Down_20250129093021)
+}
+
+func Up_20250129093021(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS host_certificates (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  host_id INT UNSIGNED NOT NULL,
+  sha1_sum CHAR(40) COLLATE utf8mb4_unicode_ci NOT NULL,
+  common_name VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  subject VARCHAR(1024) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  issuer VARCHAR(1024) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  serial_number VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  not_valid_before DATETIME(6) NOT NULL,
+  not_valid_after DATETIME(6) NOT NULL,
+  managed_by_mdmlab TINYINT(1) NOT NULL DEFAULT '0',
+  source VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  expiration_notified_at TIMESTAMP(6) NULL DEFAULT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_host_certificates_host_id_sha1_sum (host_id, sha1_sum),
+  KEY idx_host_certificates_not_valid_after (not_valid_after)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create host_certificates table: %w", err)
+	}
+
+	// host_certificate_syncs tracks when the certificate inventory of a host
+	// was last refreshed, as a host may legitimately have no certificates.
+	_, err = tx.Exec(`
+CREATE TABLE IF NOT EXISTS host_certificate_syncs (
+  host_id INT UNSIGNED NOT NULL,
+  synced_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (host_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create host_certificate_syncs table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250129093021(tx *sql.Tx) error {
+	return nil
+}
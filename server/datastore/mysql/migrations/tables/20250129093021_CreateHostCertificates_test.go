@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250129093021(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO host_certificates (host_id, sha1_sum, common_name, not_valid_before, not_valid_after, source)
+		VALUES (1, 'abc', 'cert', NOW(), NOW() + INTERVAL 1 DAY, 'apple_mdm')`)
+
+	// the same certificate cannot be inserted twice for a host
+	_, err := db.Exec(`INSERT INTO host_certificates (host_id, sha1_sum, common_name, not_valid_before, not_valid_after, source)
+		VALUES (1, 'abc', 'cert', NOW(), NOW() + INTERVAL 1 DAY, 'apple_mdm')`)
+	require.Error(t, err)
+
+	var managed bool
+	require.NoError(t, db.Get(&managed, `SELECT managed_by_mdmlab FROM host_certificates WHERE host_id = 1 AND sha1_sum = 'abc'`))
+	require.False(t, managed)
+
+	execNoErr(t, db, `INSERT INTO host_certificate_syncs (host_id) VALUES (1)`)
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250131102045, Down_20250131102045)
+}
+
+func Up_20250131102045(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS custom_cve_matching_rules (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name_like_match VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  source_match VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  cves JSON NOT NULL,
+  resolved_in_version VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_custom_cve_matching_rules_unique (name_like_match, source_match, resolved_in_version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create custom_cve_matching_rules table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250131102045(tx *sql.Tx) error {
+	return nil
+}
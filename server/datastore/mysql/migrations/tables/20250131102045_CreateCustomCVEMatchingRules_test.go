@@ -0,0 +1,26 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250131102045(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO custom_cve_matching_rules (name_like_match, source_match, cves, resolved_in_version)
+		VALUES ('Acme Agent%', 'deb_packages', '["CVE-2024-0001"]', '2.0.1')`)
+
+	// the same rule cannot be inserted twice
+	_, err := db.Exec(`INSERT INTO custom_cve_matching_rules (name_like_match, source_match, cves, resolved_in_version)
+		VALUES ('Acme Agent%', 'deb_packages', '["CVE-2024-0002"]', '2.0.1')`)
+	require.Error(t, err)
+
+	var cves string
+	require.NoError(t, db.Get(&cves, `SELECT cves FROM custom_cve_matching_rules WHERE name_like_match = 'Acme Agent%'`))
+	require.JSONEq(t, `["CVE-2024-0001"]`, cves)
+}
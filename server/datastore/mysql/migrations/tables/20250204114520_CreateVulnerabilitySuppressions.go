@@ -0,0 +1,43 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250204114520, Down_20250204114520)
+}
+
+func Up_20250204114520(tx *sql.Tx) error {
+	// scope_key identifies the scope of the suppression (global, a team, a software title or a
+	// host) so that a CVE can only be suppressed once per scope, as the nullable scope columns
+	// cannot be part of a unique key.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS vulnerability_suppressions (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  cve VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  scope_key VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  team_id INT UNSIGNED NULL,
+  software_title_id INT UNSIGNED NULL,
+  host_id INT UNSIGNED NULL,
+  justification VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  comment TEXT COLLATE utf8mb4_unicode_ci NOT NULL,
+  author_id INT UNSIGNED NULL,
+  expires_at TIMESTAMP NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_vulnerability_suppressions_cve_scope (cve, scope_key),
+  KEY idx_vulnerability_suppressions_team_id (team_id),
+  KEY idx_vulnerability_suppressions_host_id (host_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create vulnerability_suppressions table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250204114520(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250204114520(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO vulnerability_suppressions (cve, scope_key, justification, comment)
+		VALUES ('CVE-2024-0001', 'global', 'vulnerable_code_not_in_execute_path', '')`)
+	execNoErr(t, db, `INSERT INTO vulnerability_suppressions (cve, scope_key, team_id, justification, comment)
+		VALUES ('CVE-2024-0001', 'team/1', 1, 'component_not_present', 'not installed')`)
+
+	// the same CVE cannot be suppressed twice for the same scope
+	_, err := db.Exec(`INSERT INTO vulnerability_suppressions (cve, scope_key, justification, comment)
+		VALUES ('CVE-2024-0001', 'global', 'component_not_present', '')`)
+	require.Error(t, err)
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM vulnerability_suppressions WHERE expires_at IS NULL`))
+	require.Equal(t, 2, count)
+}
@@ -0,0 +1,38 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250207091530, Down_20250207091530)
+}
+
+func Up_20250207091530(tx *sql.Tx) error {
+	// host_vulnerabilities keeps track of when a CVE was first detected and
+	// when it was resolved on each host, it is populated by the vulnerabilities
+	// cron so existing vulnerabilities are considered first detected on its
+	// first run after the upgrade.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS host_vulnerabilities (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  host_id INT UNSIGNED NOT NULL,
+  cve VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  first_detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  resolved_at TIMESTAMP NULL,
+  sla_breach_notified_at TIMESTAMP NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_host_vulnerabilities_host_id_cve (host_id, cve),
+  KEY idx_host_vulnerabilities_cve (cve),
+  KEY idx_host_vulnerabilities_resolved_at (resolved_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create host_vulnerabilities table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250207091530(tx *sql.Tx) error {
+	return nil
+}
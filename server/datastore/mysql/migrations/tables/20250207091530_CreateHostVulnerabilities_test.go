@@ -0,0 +1,25 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250207091530(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO host_vulnerabilities (host_id, cve) VALUES (1, 'CVE-2024-0001')`)
+	execNoErr(t, db, `INSERT INTO host_vulnerabilities (host_id, cve) VALUES (2, 'CVE-2024-0001')`)
+
+	// a CVE is tracked once per host
+	_, err := db.Exec(`INSERT INTO host_vulnerabilities (host_id, cve) VALUES (1, 'CVE-2024-0001')`)
+	require.Error(t, err)
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM host_vulnerabilities WHERE resolved_at IS NULL AND first_detected_at IS NOT NULL`))
+	require.Equal(t, 2, count)
+}
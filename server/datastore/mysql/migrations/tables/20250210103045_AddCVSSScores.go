@@ -0,0 +1,45 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250210103045, Down_20250210103045)
+}
+
+func Up_20250210103045(tx *sql.Tx) error {
+	// cve_cvss_scores stores all the CVSS scores of a CVE (v2, v3.x and v4.0,
+	// reported by NVD or by the CNA), cve_meta.cvss_score is the preferred one.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS cve_cvss_scores (
+  cve VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  version VARCHAR(8) COLLATE utf8mb4_unicode_ci NOT NULL,
+  source VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  is_primary TINYINT(1) NOT NULL DEFAULT 0,
+  base_score DOUBLE NOT NULL,
+  vector_string VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  attack_vector VARCHAR(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  PRIMARY KEY (cve, version, source)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create cve_cvss_scores table: %w", err)
+	}
+
+	_, err = tx.Exec(`
+ALTER TABLE cve_meta
+  ADD COLUMN cvss_version VARCHAR(8) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  ADD COLUMN cvss_source VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  ADD COLUMN cvss_vector VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  ADD COLUMN attack_vector VARCHAR(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  ADD INDEX idx_cve_meta_attack_vector (attack_vector)`)
+	if err != nil {
+		return fmt.Errorf("failed to add cvss columns to cve_meta: %w", err)
+	}
+	return nil
+}
+
+func Down_20250210103045(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250210103045(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO cve_meta (cve, cvss_score) VALUES ('CVE-2024-0001', 9.8)`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	var meta struct {
+		CVSSVersion  string `db:"cvss_version"`
+		AttackVector string `db:"attack_vector"`
+	}
+	require.NoError(t, db.Get(&meta, `SELECT cvss_version, attack_vector FROM cve_meta WHERE cve = 'CVE-2024-0001'`))
+	require.Empty(t, meta.CVSSVersion)
+	require.Empty(t, meta.AttackVector)
+
+	execNoErr(t, db, `INSERT INTO cve_cvss_scores (cve, version, source, is_primary, base_score, vector_string, attack_vector)
+		VALUES ('CVE-2024-0001', '3.1', 'nvd@nist.gov', 1, 9.8, 'CVSS:3.1/AV:N', 'network')`)
+	execNoErr(t, db, `INSERT INTO cve_cvss_scores (cve, version, source, is_primary, base_score, vector_string, attack_vector)
+		VALUES ('CVE-2024-0001', '4.0', 'nvd@nist.gov', 1, 9.3, 'CVSS:4.0/AV:N', 'network')`)
+
+	// a score is stored once per version and source
+	_, err := db.Exec(`INSERT INTO cve_cvss_scores (cve, version, source, base_score) VALUES ('CVE-2024-0001', '3.1', 'nvd@nist.gov', 1)`)
+	require.Error(t, err)
+}
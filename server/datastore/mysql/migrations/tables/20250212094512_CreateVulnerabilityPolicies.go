@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250212094512, Down_20250212094512)
+}
+
+func Up_20250212094512(tx *sql.Tx) error {
+	// vulnerability_policies links the policies created to detect the
+	// vulnerable versions of a software title to the CVE they detect.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS vulnerability_policies (
+  policy_id INT UNSIGNED NOT NULL,
+  cve VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  software_title_id INT UNSIGNED DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (policy_id),
+  KEY idx_vulnerability_policies_cve (cve),
+  CONSTRAINT fk_vulnerability_policies_policy_id FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create vulnerability_policies table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250212094512(tx *sql.Tx) error {
+	return nil
+}
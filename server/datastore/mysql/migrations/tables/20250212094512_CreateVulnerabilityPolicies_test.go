@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250212094512(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	policyID := execNoErrLastID(t, db, `INSERT INTO policies (name, query, description, checksum) VALUES ('p1', 'SELECT 1', '', 'abcdefghijklmnop')`)
+
+	execNoErr(t, db, `INSERT INTO vulnerability_policies (policy_id, cve, software_title_id) VALUES (?, 'CVE-2024-0001', 1)`, policyID)
+
+	// a policy detects a single CVE
+	_, err := db.Exec(`INSERT INTO vulnerability_policies (policy_id, cve) VALUES (?, 'CVE-2024-0002')`, policyID)
+	require.Error(t, err)
+
+	// deleting the policy deletes the link
+	execNoErr(t, db, `DELETE FROM policies WHERE id = ?`, policyID)
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM vulnerability_policies`))
+	require.Zero(t, count)
+}
@@ -0,0 +1,44 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250214101530, Down_20250214101530)
+}
+
+func Up_20250214101530(tx *sql.Tx) error {
+	// software_installer_rollouts stores the staged rollout of a software
+	// installer version, stages is the JSON list of rings (percentage of hosts
+	// or label) with their soak time.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_installer_rollouts (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  software_installer_id INT UNSIGNED NOT NULL,
+  version VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  stages JSON NOT NULL,
+  success_threshold_percent TINYINT UNSIGNED NOT NULL,
+  max_failed_installs INT UNSIGNED NOT NULL,
+  status VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  status_reason VARCHAR(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  current_stage INT UNSIGNED NOT NULL DEFAULT 0,
+  stage_started_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  started_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_software_installer_rollouts_installer_id (software_installer_id),
+  KEY idx_software_installer_rollouts_status (status),
+  CONSTRAINT fk_software_installer_rollouts_installer_id FOREIGN KEY (software_installer_id) REFERENCES software_installers (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create software_installer_rollouts table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250214101530(tx *sql.Tx) error {
+	return nil
+}
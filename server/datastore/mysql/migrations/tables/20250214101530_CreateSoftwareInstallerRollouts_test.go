@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250214101530(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO script_contents (id, md5_checksum, contents) VALUES (1, 'checksum', 'script content')`)
+	installerID := execNoErrLastID(t, db, `INSERT INTO software_installers
+		(filename, version, platform, install_script_content_id, uninstall_script_content_id, storage_id, package_ids)
+		VALUES ('foo.pkg', '1.0', 'darwin', 1, 1, 'storage-id', '')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO software_installer_rollouts
+		(software_installer_id, version, stages, success_threshold_percent, max_failed_installs, status)
+		VALUES (?, '1.0', '[{"percent": 10, "soak_minutes": 60}]', 95, 5, 'in_progress')`, installerID)
+
+	// an installer has a single rollout
+	_, err := db.Exec(`INSERT INTO software_installer_rollouts
+		(software_installer_id, version, stages, success_threshold_percent, max_failed_installs, status)
+		VALUES (?, '1.0', '[]', 95, 5, 'in_progress')`, installerID)
+	require.Error(t, err)
+
+	// deleting the installer deletes the rollout
+	execNoErr(t, db, `DELETE FROM software_installers WHERE id = ?`, installerID)
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_installer_rollouts`))
+	require.Zero(t, count)
+}
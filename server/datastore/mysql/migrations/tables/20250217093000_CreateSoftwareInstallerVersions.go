@@ -0,0 +1,102 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250217093000, Down_20250217093000)
+}
+
+func Up_20250217093000(tx *sql.Tx) error {
+	// software_installer_versions keeps the history of the packages (and their
+	// scripts) uploaded for a software installer, the software_installers row
+	// being the latest one.
+	if _, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_installer_versions (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  software_installer_id INT UNSIGNED NOT NULL,
+  version VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  filename VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  extension VARCHAR(32) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  storage_id VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  package_ids TEXT COLLATE utf8mb4_unicode_ci NOT NULL,
+  url VARCHAR(4095) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  pre_install_query TEXT COLLATE utf8mb4_unicode_ci,
+  install_script_content_id INT UNSIGNED NOT NULL,
+  post_install_script_content_id INT UNSIGNED DEFAULT NULL,
+  uninstall_script_content_id INT UNSIGNED NOT NULL,
+  user_id INT UNSIGNED DEFAULT NULL,
+  user_name VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  user_email VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  uploaded_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  KEY idx_software_installer_versions_installer_id (software_installer_id),
+  CONSTRAINT fk_software_installer_versions_installer_id FOREIGN KEY (software_installer_id) REFERENCES software_installers (id) ON DELETE CASCADE,
+  CONSTRAINT fk_software_installer_versions_install_script FOREIGN KEY (install_script_content_id) REFERENCES script_contents (id) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT fk_software_installer_versions_post_install_script FOREIGN KEY (post_install_script_content_id) REFERENCES script_contents (id) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT fk_software_installer_versions_uninstall_script FOREIGN KEY (uninstall_script_content_id) REFERENCES script_contents (id) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT fk_software_installer_versions_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`); err != nil {
+		return fmt.Errorf("failed to create software_installer_versions table: %w", err)
+	}
+
+	// software_installer_version_labels targets a version to the members of
+	// labels, e.g. the latest version to beta testers.
+	if _, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_installer_version_labels (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  software_installer_version_id INT UNSIGNED NOT NULL,
+  label_id INT UNSIGNED NOT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_software_installer_version_labels_version_label (software_installer_version_id, label_id),
+  KEY idx_software_installer_version_labels_label_id (label_id),
+  CONSTRAINT fk_software_installer_version_labels_version_id FOREIGN KEY (software_installer_version_id) REFERENCES software_installer_versions (id) ON DELETE CASCADE,
+  CONSTRAINT fk_software_installer_version_labels_label_id FOREIGN KEY (label_id) REFERENCES labels (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`); err != nil {
+		return fmt.Errorf("failed to create software_installer_version_labels table: %w", err)
+	}
+
+	// no foreign key for the pinned version, the versions are deleted in
+	// cascade with the installer and the pin is cleared when the version is
+	// deleted.
+	if _, err := tx.Exec(`
+		ALTER TABLE software_installers
+		ADD COLUMN pinned_version_id INT UNSIGNED DEFAULT NULL
+	`); err != nil {
+		return fmt.Errorf("failed to add pinned_version_id to software_installers: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		ALTER TABLE host_software_installs
+		ADD COLUMN software_installer_version_id INT UNSIGNED DEFAULT NULL,
+		ADD CONSTRAINT fk_host_software_installs_installer_version_id FOREIGN KEY (software_installer_version_id) REFERENCES software_installer_versions (id) ON DELETE SET NULL
+	`); err != nil {
+		return fmt.Errorf("failed to add software_installer_version_id to host_software_installs: %w", err)
+	}
+
+	// the current package of the existing installers is their first version
+	if _, err := tx.Exec(`
+		INSERT INTO software_installer_versions (
+			software_installer_id, version, filename, extension, storage_id, package_ids, url, pre_install_query,
+			install_script_content_id, post_install_script_content_id, uninstall_script_content_id,
+			user_id, user_name, user_email, uploaded_at
+		)
+		SELECT
+			id, version, filename, extension, storage_id, package_ids, url, pre_install_query,
+			install_script_content_id, post_install_script_content_id, uninstall_script_content_id,
+			user_id, user_name, user_email, uploaded_at
+		FROM software_installers
+	`); err != nil {
+		return fmt.Errorf("failed to insert the versions of the existing software installers: %w", err)
+	}
+
+	return nil
+}
+
+func Down_20250217093000(tx *sql.Tx) error {
+	return nil
+}
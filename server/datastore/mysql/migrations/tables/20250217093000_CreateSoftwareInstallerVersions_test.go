@@ -0,0 +1,44 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250217093000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO script_contents (id, md5_checksum, contents) VALUES (1, 'checksum', 'script content')`)
+	installerID := execNoErrLastID(t, db, `INSERT INTO software_installers
+		(filename, version, platform, install_script_content_id, uninstall_script_content_id, storage_id, package_ids)
+		VALUES ('foo.pkg', '1.0', 'darwin', 1, 1, 'storage-id', 'com.foo')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	// the existing installer has a first version
+	var versions []struct {
+		ID       uint   `db:"id"`
+		Version  string `db:"version"`
+		Filename string `db:"filename"`
+		Storage  string `db:"storage_id"`
+	}
+	require.NoError(t, db.Select(&versions, `SELECT id, version, filename, storage_id FROM software_installer_versions WHERE software_installer_id = ?`, installerID))
+	require.Len(t, versions, 1)
+	require.Equal(t, "1.0", versions[0].Version)
+	require.Equal(t, "foo.pkg", versions[0].Filename)
+	require.Equal(t, "storage-id", versions[0].Storage)
+
+	labelID := execNoErrLastID(t, db, `INSERT INTO labels (name, query) VALUES ('beta', 'SELECT 1')`)
+	execNoErr(t, db, `INSERT INTO software_installer_version_labels (software_installer_version_id, label_id) VALUES (?, ?)`, versions[0].ID, labelID)
+	execNoErr(t, db, `UPDATE software_installers SET pinned_version_id = ? WHERE id = ?`, versions[0].ID, installerID)
+
+	// deleting the installer deletes its versions
+	execNoErr(t, db, `DELETE FROM software_installers WHERE id = ?`, installerID)
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_installer_versions`))
+	require.Zero(t, count)
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_installer_version_labels`))
+	require.Zero(t, count)
+}
@@ -0,0 +1,81 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250218100000, Down_20250218100000)
+}
+
+func Up_20250218100000(tx *sql.Tx) error {
+	// software_blocklist_rules stores the software that must not be installed
+	// on the hosts of a team. A rule matches on the name, bundle identifier
+	// and/or source of the software and on an optional (inclusive) version
+	// range. script_id is the optional script used to remove the software,
+	// when NULL the uninstall script of the title's installer is used.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_blocklist_rules (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  team_id INT UNSIGNED DEFAULT NULL,
+  global_or_team_id INT UNSIGNED NOT NULL DEFAULT 0,
+  name VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  bundle_identifier VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  source VARCHAR(64) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  min_version VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  max_version VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  script_id INT UNSIGNED DEFAULT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  KEY idx_software_blocklist_rules_global_or_team_id (global_or_team_id),
+  CONSTRAINT fk_software_blocklist_rules_team_id FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+  CONSTRAINT fk_software_blocklist_rules_script_id FOREIGN KEY (script_id) REFERENCES scripts (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create software_blocklist_rules table: %w", err)
+	}
+
+	// software_blocklist_rule_labels stores the labels whose members are
+	// excepted from a rule.
+	_, err = tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_blocklist_rule_labels (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  rule_id INT UNSIGNED NOT NULL,
+  label_id INT UNSIGNED NOT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_software_blocklist_rule_labels_rule_label (rule_id, label_id),
+  KEY label_id (label_id),
+  CONSTRAINT fk_software_blocklist_rule_labels_rule_id FOREIGN KEY (rule_id) REFERENCES software_blocklist_rules (id) ON DELETE CASCADE,
+  CONSTRAINT fk_software_blocklist_rule_labels_label_id FOREIGN KEY (label_id) REFERENCES labels (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create software_blocklist_rule_labels table: %w", err)
+	}
+
+	// software_blocklist_removals stores the removal scripts queued for the
+	// hosts that have blocklisted software, the result is in
+	// host_script_results.
+	_, err = tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_blocklist_removals (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  rule_id INT UNSIGNED NOT NULL,
+  host_id INT UNSIGNED NOT NULL,
+  execution_id VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_software_blocklist_removals_execution_id (execution_id),
+  KEY idx_software_blocklist_removals_rule_host (rule_id, host_id, created_at),
+  CONSTRAINT fk_software_blocklist_removals_rule_id FOREIGN KEY (rule_id) REFERENCES software_blocklist_rules (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create software_blocklist_removals table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250218100000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250218100000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	teamID := execNoErrLastID(t, db, `INSERT INTO teams (name) VALUES ('team1')`)
+	labelID := execNoErrLastID(t, db, `INSERT INTO labels (name, query) VALUES ('label1', 'select 1')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	ruleID := execNoErrLastID(t, db, `INSERT INTO software_blocklist_rules
+		(team_id, global_or_team_id, name, source, max_version) VALUES (?, ?, 'uTorrent.app', 'apps', '3.5.5')`, teamID, teamID)
+	execNoErr(t, db, `INSERT INTO software_blocklist_rule_labels (rule_id, label_id) VALUES (?, ?)`, ruleID, labelID)
+	execNoErr(t, db, `INSERT INTO software_blocklist_removals (rule_id, host_id, execution_id) VALUES (?, 1, 'exec-1')`, ruleID)
+
+	// an execution is recorded once
+	_, err := db.Exec(`INSERT INTO software_blocklist_removals (rule_id, host_id, execution_id) VALUES (?, 2, 'exec-1')`, ruleID)
+	require.Error(t, err)
+
+	// deleting the team deletes the rule, its labels and removals
+	execNoErr(t, db, `DELETE FROM teams WHERE id = ?`, teamID)
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_blocklist_rules`))
+	require.Zero(t, count)
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_blocklist_rule_labels`))
+	require.Zero(t, count)
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_blocklist_removals`))
+	require.Zero(t, count)
+}
@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250219100000, Down_20250219100000)
+}
+
+func Up_20250219100000(tx *sql.Tx) error {
+	// the signature of the package is verified when it is added, the status
+	// is empty for the existing packages.
+	for _, table := range []string{"software_installers", "software_installer_versions"} {
+		if _, err := tx.Exec(fmt.Sprintf(`
+			ALTER TABLE %s
+			ADD COLUMN signature_status VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+			ADD COLUMN signer VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+			ADD COLUMN signer_team_id VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+			ADD COLUMN has_stapled_ticket TINYINT(1) NOT NULL DEFAULT 0
+		`, table)); err != nil {
+			return fmt.Errorf("failed to add signature columns to %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func Down_20250219100000(tx *sql.Tx) error {
+	return nil
+}
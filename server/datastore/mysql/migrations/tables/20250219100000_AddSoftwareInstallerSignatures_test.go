@@ -0,0 +1,41 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250219100000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO script_contents (id, md5_checksum, contents) VALUES (1, 'checksum', 'script content')`)
+	installerID := execNoErrLastID(t, db, `INSERT INTO software_installers
+		(filename, version, platform, install_script_content_id, uninstall_script_content_id, storage_id, package_ids)
+		VALUES ('foo.pkg', '1.0', 'darwin', 1, 1, 'storage-id', 'com.foo')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	type signature struct {
+		SignatureStatus  string `db:"signature_status"`
+		Signer           string `db:"signer"`
+		SignerTeamID     string `db:"signer_team_id"`
+		HasStapledTicket bool   `db:"has_stapled_ticket"`
+	}
+
+	// the existing installer has no signature information
+	var sig signature
+	require.NoError(t, db.Get(&sig, `SELECT signature_status, signer, signer_team_id, has_stapled_ticket FROM software_installers WHERE id = ?`, installerID))
+	require.Equal(t, signature{}, sig)
+
+	execNoErr(t, db, `UPDATE software_installers SET signature_status = 'valid', signer = 'Developer ID Installer: Foo (ABCDE12345)', signer_team_id = 'ABCDE12345', has_stapled_ticket = 1 WHERE id = ?`, installerID)
+	require.NoError(t, db.Get(&sig, `SELECT signature_status, signer, signer_team_id, has_stapled_ticket FROM software_installers WHERE id = ?`, installerID))
+	require.Equal(t, signature{"valid", "Developer ID Installer: Foo (ABCDE12345)", "ABCDE12345", true}, sig)
+
+	execNoErr(t, db, `INSERT INTO software_installer_versions
+		(software_installer_id, version, filename, storage_id, package_ids, install_script_content_id, uninstall_script_content_id, signature_status, signer)
+		VALUES (?, '2.0', 'foo.pkg', 'storage-id-2', 'com.foo', 1, 1, 'untrusted', 'Foo')`, installerID)
+	require.NoError(t, db.Get(&sig, `SELECT signature_status, signer, signer_team_id, has_stapled_ticket FROM software_installer_versions WHERE version = '2.0'`))
+	require.Equal(t, signature{SignatureStatus: "untrusted", Signer: "Foo"}, sig)
+}
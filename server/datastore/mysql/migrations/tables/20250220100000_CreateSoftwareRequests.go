@@ -0,0 +1,50 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250220100000, Down_20250220100000)
+}
+
+func Up_20250220100000(tx *sql.Tx) error {
+	// software_requests stores the requests made by the end users from the
+	// My device page to install software of the catalog of their team. The
+	// name of the title and of the reviewer are stored so that the history is
+	// kept if they are deleted. install_uuid is the installation queued when
+	// the request is approved, its status is in host_software_installs.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_requests (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  host_id INT UNSIGNED NOT NULL,
+  team_id INT UNSIGNED DEFAULT NULL,
+  title_id INT UNSIGNED NOT NULL,
+  software_title_name VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  requested_by VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  justification TEXT COLLATE utf8mb4_unicode_ci NOT NULL,
+  status ENUM('pending', 'approved', 'denied') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'pending',
+  reviewed_by_user_id INT UNSIGNED DEFAULT NULL,
+  reviewed_by_name VARCHAR(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  review_comment TEXT COLLATE utf8mb4_unicode_ci NOT NULL,
+  reviewed_at TIMESTAMP(6) NULL DEFAULT NULL,
+  install_uuid VARCHAR(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  KEY idx_software_requests_host_title (host_id, title_id),
+  KEY idx_software_requests_team_status (team_id, status),
+  KEY idx_software_requests_requested_by (requested_by),
+  CONSTRAINT fk_software_requests_team_id FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+  CONSTRAINT fk_software_requests_reviewed_by_user_id FOREIGN KEY (reviewed_by_user_id) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create software_requests table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250220100000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250220100000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	teamID := execNoErrLastID(t, db, `INSERT INTO teams (name) VALUES ('team1')`)
+	userID := execNoErrLastID(t, db, `INSERT INTO users (name, email, password, salt) VALUES ('admin', 'admin@example.com', 'x', 'x')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	requestID := execNoErrLastID(t, db, `INSERT INTO software_requests
+		(host_id, team_id, title_id, software_title_name, requested_by, justification, review_comment)
+		VALUES (1, ?, 2, 'Figma.app', 'anna@example.com', 'design reviews', '')`, teamID)
+
+	var status string
+	require.NoError(t, db.Get(&status, `SELECT status FROM software_requests WHERE id = ?`, requestID))
+	require.Equal(t, "pending", status)
+
+	execNoErr(t, db, `UPDATE software_requests SET status = 'approved', reviewed_by_user_id = ?, reviewed_by_name = 'admin',
+		reviewed_at = NOW(6), install_uuid = 'install-1' WHERE id = ?`, userID, requestID)
+
+	// deleting the reviewer keeps the request
+	execNoErr(t, db, `DELETE FROM users WHERE id = ?`, userID)
+	var reviewedBy *uint
+	require.NoError(t, db.Get(&reviewedBy, `SELECT reviewed_by_user_id FROM software_requests WHERE id = ?`, requestID))
+	require.Nil(t, reviewedBy)
+
+	// deleting the team deletes its requests
+	execNoErr(t, db, `DELETE FROM teams WHERE id = ?`, teamID)
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_requests`))
+	require.Zero(t, count)
+}
@@ -0,0 +1,43 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250221100000, Down_20250221100000)
+}
+
+func Up_20250221100000(tx *sql.Tx) error {
+	// software_title_licenses stores the license entitlement of a software
+	// title for the hosts of a team, there is at most one license per title
+	// and team. The usage is computed from host_software.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS software_title_licenses (
+  id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  team_id INT UNSIGNED DEFAULT NULL,
+  global_or_team_id INT UNSIGNED NOT NULL DEFAULT 0,
+  title_id INT UNSIGNED NOT NULL,
+  vendor VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  seat_count INT UNSIGNED NOT NULL,
+  expires_at TIMESTAMP NULL DEFAULT NULL,
+  cost DECIMAL(14,2) DEFAULT NULL,
+  unused_after_days INT UNSIGNED NOT NULL DEFAULT 30,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_software_title_licenses_team_title (global_or_team_id, title_id),
+  KEY fk_software_title_licenses_title_id (title_id),
+  CONSTRAINT fk_software_title_licenses_team_id FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+  CONSTRAINT fk_software_title_licenses_title_id FOREIGN KEY (title_id) REFERENCES software_titles (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create software_title_licenses table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250221100000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250221100000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	teamID := execNoErrLastID(t, db, `INSERT INTO teams (name) VALUES ('team1')`)
+	titleID := execNoErrLastID(t, db, `INSERT INTO software_titles (name, source) VALUES ('Figma.app', 'apps')`)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	licenseID := execNoErrLastID(t, db, `INSERT INTO software_title_licenses
+		(team_id, global_or_team_id, title_id, vendor, seat_count, cost) VALUES (?, ?, ?, 'Figma, Inc.', 50, 1234.5)`, teamID, teamID, titleID)
+	execNoErr(t, db, `INSERT INTO software_title_licenses (title_id, seat_count) VALUES (?, 10)`, titleID)
+
+	var unusedAfterDays uint
+	require.NoError(t, db.Get(&unusedAfterDays, `SELECT unused_after_days FROM software_title_licenses WHERE id = ?`, licenseID))
+	require.Equal(t, uint(30), unusedAfterDays)
+
+	// a title has one license per team
+	_, err := db.Exec(`INSERT INTO software_title_licenses (team_id, global_or_team_id, title_id, seat_count) VALUES (?, ?, ?, 5)`, teamID, teamID, titleID)
+	require.Error(t, err)
+
+	// deleting the team deletes its licenses
+	execNoErr(t, db, `DELETE FROM teams WHERE id = ?`, teamID)
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_title_licenses`))
+	require.Equal(t, 1, count)
+
+	// deleting the title deletes its licenses
+	execNoErr(t, db, `DELETE FROM software_titles WHERE id = ?`, titleID)
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM software_title_licenses`))
+	require.Zero(t, count)
+}
@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20250222100000, Down_20250222100000)
+}
+
+func Up_20250222100000(tx *sql.Tx) error {
+	// host_mdm_apple_recovery_locks_archive keeps the escrowed recovery lock of
+	// deleted hosts, so that it can be restored if the host enrolls again.
+	_, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS host_mdm_apple_recovery_locks_archive (
+  host_uuid VARCHAR(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  password_type VARCHAR(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  encrypted_password BLOB NOT NULL,
+  rotate_at TIMESTAMP NULL DEFAULT NULL,
+  revealed_at TIMESTAMP(6) NULL DEFAULT NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  PRIMARY KEY (host_uuid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("failed to create host_mdm_apple_recovery_locks_archive table: %w", err)
+	}
+	return nil
+}
+
+func Down_20250222100000(tx *sql.Tx) error {
+	return nil
+}
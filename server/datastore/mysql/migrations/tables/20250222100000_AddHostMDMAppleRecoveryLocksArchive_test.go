@@ -0,0 +1,25 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20250222100000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	// Apply current migration.
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO host_mdm_apple_recovery_locks_archive (host_uuid, password_type, encrypted_password)
+		VALUES ('abc', 'recovery_lock', 'enc')`)
+
+	var lock struct {
+		PasswordType      string `db:"password_type"`
+		EncryptedPassword []byte `db:"encrypted_password"`
+	}
+	require.NoError(t, db.Get(&lock, `SELECT password_type, encrypted_password FROM host_mdm_apple_recovery_locks_archive WHERE host_uuid = 'abc'`))
+	require.Equal(t, "recovery_lock", lock.PasswordType)
+	require.Equal(t, []byte("enc"), lock.EncryptedPassword)
+}
@@ -178,6 +178,59 @@ func (s *NanoMDMStorage) EnqueueDeviceWipeCommand(ctx context.Context, host *mdm
 	}, s.logger)
 }
 
+// EnqueueEnableLostModeCommand enqueues an EnableLostMode command for the
+// given host and records it as the host's lost mode reference.
+func (s *NanoMDMStorage) EnqueueEnableLostModeCommand(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error {
+	return common_mysql.WithRetryTxx(ctx, s.db, func(tx sqlx.ExtContext) error {
+		if err := enqueueCommandDB(ctx, tx, []string{host.UUID}, cmd); err != nil {
+			return err
+		}
+
+		stmt := `
+			INSERT INTO host_mdm_actions (
+				host_id,
+				lost_mode_ref,
+				mdmlab_platform
+			)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				lost_mode_disable_ref = NULL,
+				lost_mode_ref         = VALUES(lost_mode_ref)`
+
+		if _, err := tx.ExecContext(ctx, stmt, host.ID, cmd.CommandUUID, host.MDMlabPlatform()); err != nil {
+			return ctxerr.Wrap(ctx, err, "modifying host_mdm_actions for EnableLostMode")
+		}
+
+		return nil
+	}, s.logger)
+}
+
+// EnqueueDisableLostModeCommand enqueues a DisableLostMode command for the
+// given host and records it as the host's pending lost mode disable reference.
+func (s *NanoMDMStorage) EnqueueDisableLostModeCommand(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error {
+	return common_mysql.WithRetryTxx(ctx, s.db, func(tx sqlx.ExtContext) error {
+		if err := enqueueCommandDB(ctx, tx, []string{host.UUID}, cmd); err != nil {
+			return err
+		}
+
+		stmt := `
+			INSERT INTO host_mdm_actions (
+				host_id,
+				lost_mode_disable_ref,
+				mdmlab_platform
+			)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				lost_mode_disable_ref = VALUES(lost_mode_disable_ref)`
+
+		if _, err := tx.ExecContext(ctx, stmt, host.ID, cmd.CommandUUID, host.MDMlabPlatform()); err != nil {
+			return ctxerr.Wrap(ctx, err, "modifying host_mdm_actions for DisableLostMode")
+		}
+
+		return nil
+	}, s.logger)
+}
+
 func (s *NanoMDMStorage) GetAllMDMConfigAssetsByName(ctx context.Context, assetNames []mdmlab.MDMAssetName,
 	queryerContext sqlx.QueryerContext) (map[mdmlab.MDMAssetName]mdmlab.MDMConfigAsset, error) {
 	return s.ds.GetAllMDMConfigAssetsByName(ctx, assetNames, queryerContext)
@@ -36,6 +36,10 @@ var (
 var policySearchColumns = []string{"p.name"}
 
 func (ds *Datastore) NewGlobalPolicy(ctx context.Context, authorID *uint, args mdmlab.PolicyPayload) (*mdmlab.Policy, error) {
+	return newGlobalPolicy(ctx, ds.writer(ctx), authorID, args)
+}
+
+func newGlobalPolicy(ctx context.Context, db sqlx.ExtContext, authorID *uint, args mdmlab.PolicyPayload) (*mdmlab.Policy, error) {
 	if args.SoftwareInstallerID != nil {
 		return nil, ctxerr.Wrap(ctx, errSoftwareTitleIDOnGlobalPolicy, "create policy")
 	}
@@ -43,7 +47,7 @@ func (ds *Datastore) NewGlobalPolicy(ctx context.Context, authorID *uint, args m
 		return nil, ctxerr.Wrap(ctx, errScriptIDOnGlobalPolicy, "create policy")
 	}
 	if args.QueryID != nil {
-		q, err := ds.Query(ctx, *args.QueryID)
+		q, err := query(ctx, db, *args.QueryID)
 		if err != nil {
 			return nil, ctxerr.Wrap(ctx, err, "fetching query from id")
 		}
@@ -53,7 +57,7 @@ func (ds *Datastore) NewGlobalPolicy(ctx context.Context, authorID *uint, args m
 	}
 	// We must normalize the name for full Unicode support (Unicode equivalence).
 	nameUnicode := norm.NFC.String(args.Name)
-	res, err := ds.writer(ctx).ExecContext(ctx,
+	res, err := db.ExecContext(ctx,
 		fmt.Sprintf(
 			`INSERT INTO policies (name, query, description, resolution, author_id, platforms, critical, checksum) VALUES (?, ?, ?, ?, ?, ?, ?, %s)`,
 			policiesChecksumComputedColumn(),
@@ -72,7 +76,7 @@ func (ds *Datastore) NewGlobalPolicy(ctx context.Context, authorID *uint, args m
 	if err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "getting last id after inserting policy")
 	}
-	return policyDB(ctx, ds.writer(ctx), uint(lastIdInt64), nil) //nolint:gosec // dismiss G115
+	return policyDB(ctx, db, uint(lastIdInt64), nil) //nolint:gosec // dismiss G115
 }
 
 func policiesChecksumComputedColumn() string {
@@ -860,6 +860,8 @@ func (ds *Datastore) GetHostLockWipeStatus(ctx context.Context, host *mdmlab.Hos
 			wipe_ref,
 			unlock_ref,
 			unlock_pin,
+			lost_mode_ref,
+			lost_mode_disable_ref,
 			mdmlab_platform
 		FROM
 			host_mdm_actions
@@ -868,11 +870,13 @@ func (ds *Datastore) GetHostLockWipeStatus(ctx context.Context, host *mdmlab.Hos
 `
 
 	var mdmActions struct {
-		LockRef       *string `db:"lock_ref"`
-		WipeRef       *string `db:"wipe_ref"`
-		UnlockRef     *string `db:"unlock_ref"`
-		UnlockPIN     *string `db:"unlock_pin"`
-		MDMlabPlatform string  `db:"mdmlab_platform"`
+		LockRef            *string `db:"lock_ref"`
+		WipeRef            *string `db:"wipe_ref"`
+		UnlockRef          *string `db:"unlock_ref"`
+		UnlockPIN          *string `db:"unlock_pin"`
+		LostModeRef        *string `db:"lost_mode_ref"`
+		LostModeDisableRef *string `db:"lost_mode_disable_ref"`
+		MDMlabPlatform     string  `db:"mdmlab_platform"`
 	}
 	mdmlabPlatform := host.MDMlabPlatform()
 	status := &mdmlab.HostLockWipeStatus{
@@ -933,6 +937,24 @@ func (ds *Datastore) GetHostLockWipeStatus(ctx context.Context, host *mdmlab.Hos
 			status.WipeMDMCommandResult = cmdRes
 		}
 
+		if mdmActions.LostModeRef != nil {
+			cmd, cmdRes, err := ds.getHostMDMAppleCommand(ctx, *mdmActions.LostModeRef, host.UUID)
+			if err != nil {
+				return nil, ctxerr.Wrap(ctx, err, "get lost mode reference")
+			}
+			status.LostModeMDMCommand = cmd
+			status.LostModeMDMCommandResult = cmdRes
+		}
+
+		if mdmActions.LostModeDisableRef != nil {
+			cmd, cmdRes, err := ds.getHostMDMAppleCommand(ctx, *mdmActions.LostModeDisableRef, host.UUID)
+			if err != nil {
+				return nil, ctxerr.Wrap(ctx, err, "get lost mode disable reference")
+			}
+			status.DisableLostModeMDMCommand = cmd
+			status.DisableLostModeMDMCommandResult = cmdRes
+		}
+
 	case "windows", "linux":
 		// lock and unlock references are scripts
 		if mdmActions.LockRef != nil {
@@ -1239,7 +1261,14 @@ func buildHostLockWipeStatusUpdateStmt(refCol string, succeeded bool, joinPart s
 			// around once it's confirmed.
 			stmt += fmt.Sprintf("%slock_ref = NULL, %[1]sunlock_ref = NULL, %[1]sunlock_pin = NULL, %[1]swipe_ref = NULL", alias)
 		case "wipe_ref":
-			stmt += fmt.Sprintf("%slock_ref = NULL, %[1]sunlock_ref = NULL, %[1]sunlock_pin = NULL", alias)
+			stmt += fmt.Sprintf("%slock_ref = NULL, %[1]sunlock_ref = NULL, %[1]sunlock_pin = NULL, "+
+				"%[1]slost_mode_ref = NULL, %[1]slost_mode_disable_ref = NULL", alias)
+		case "lost_mode_ref":
+			stmt += fmt.Sprintf("%slost_mode_disable_ref = NULL", alias)
+		case "lost_mode_disable_ref":
+			// as for unlock, not being in lost mode is the default state so a
+			// successful disable clears both references.
+			stmt += fmt.Sprintf("%slost_mode_ref = NULL, %[1]slost_mode_disable_ref = NULL", alias)
 		}
 	} else {
 		// if the action failed, then we clear the reference to that action itself so
@@ -1261,6 +1290,10 @@ func (ds *Datastore) UpdateHostLockWipeStatusFromAppleMDMResult(ctx context.Cont
 	case "DeviceLock":
 		refCol = "lock_ref"
 		setUnlockRef = true
+	case "EnableLostMode":
+		refCol = "lost_mode_ref"
+	case "DisableLostMode":
+		refCol = "lost_mode_disable_ref"
 	default:
 		return nil
 	}
@@ -1306,6 +1339,10 @@ WHERE
     SELECT 1 FROM software_installers si
     WHERE script_contents.id IN (si.install_script_content_id, si.post_install_script_content_id, si.uninstall_script_content_id)
   )
+  AND NOT EXISTS (
+    SELECT 1 FROM software_installer_versions siv
+    WHERE script_contents.id IN (siv.install_script_content_id, siv.post_install_script_content_id, siv.uninstall_script_content_id)
+  )
   AND NOT EXISTS (
     SELECT 1 FROM mdmlab_library_apps fla
 			WHERE script_contents.id IN (fla.install_script_content_id, fla.uninstall_script_content_id)
@@ -1865,7 +1865,8 @@ DELETE st FROM software_titles st
 	LEFT JOIN software s ON s.title_id = st.id
 	WHERE s.title_id IS NULL AND
 		NOT EXISTS (SELECT 1 FROM software_installers si WHERE si.title_id = st.id) AND
-		NOT EXISTS (SELECT 1 FROM vpp_apps vap WHERE vap.title_id = st.id)`
+		NOT EXISTS (SELECT 1 FROM vpp_apps vap WHERE vap.title_id = st.id) AND
+		NOT EXISTS (SELECT 1 FROM software_title_licenses stl WHERE stl.title_id = st.id)`
 
 		res, err = tx.ExecContext(ctx, cleanupStmt)
 		if err != nil {
@@ -1982,14 +1983,18 @@ func (ds *Datastore) HostsByCVE(ctx context.Context, cve string) ([]mdmlab.HostV
 
 func (ds *Datastore) InsertCVEMeta(ctx context.Context, cveMeta []mdmlab.CVEMeta) error {
 	query := `
-INSERT INTO cve_meta (cve, cvss_score, epss_probability, cisa_known_exploit, published, description)
+INSERT INTO cve_meta (cve, cvss_score, epss_probability, cisa_known_exploit, published, description, cvss_version, cvss_source, cvss_vector, attack_vector)
 VALUES %s
 ON DUPLICATE KEY UPDATE
     cvss_score = VALUES(cvss_score),
     epss_probability = VALUES(epss_probability),
     cisa_known_exploit = VALUES(cisa_known_exploit),
     published = VALUES(published),
-    description = VALUES(description)
+    description = VALUES(description),
+    cvss_version = VALUES(cvss_version),
+    cvss_source = VALUES(cvss_source),
+    cvss_vector = VALUES(cvss_vector),
+    attack_vector = VALUES(attack_vector)
 `
 
 	batchSize := 500
@@ -2001,10 +2006,11 @@ ON DUPLICATE KEY UPDATE
 
 		batch := cveMeta[i:end]
 
-		valuesFrag := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?), ", len(batch)), ", ")
+		valuesFrag := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?), ", len(batch)), ", ")
 		var args []interface{}
 		for _, meta := range batch {
-			args = append(args, meta.CVE, meta.CVSSScore, meta.EPSSProbability, meta.CISAKnownExploit, meta.Published, meta.Description)
+			args = append(args, meta.CVE, meta.CVSSScore, meta.EPSSProbability, meta.CISAKnownExploit, meta.Published, meta.Description,
+				meta.CVSSVersion, meta.CVSSSource, meta.CVSSVector, meta.AttackVector)
 		}
 
 		query := fmt.Sprintf(query, valuesFrag)
@@ -2013,11 +2019,78 @@ ON DUPLICATE KEY UPDATE
 		if err != nil {
 			return ctxerr.Wrap(ctx, err, "insert cve scores")
 		}
+
+		if err := ds.replaceCVSSScores(ctx, batch); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// replaceCVSSScores replaces the CVSS scores of the CVEs that have any in the
+// given batch.
+func (ds *Datastore) replaceCVSSScores(ctx context.Context, batch []mdmlab.CVEMeta) error {
+	const insertStmt = `
+INSERT INTO cve_cvss_scores (cve, version, source, is_primary, base_score, vector_string, attack_vector)
+VALUES %s
+ON DUPLICATE KEY UPDATE
+    is_primary = VALUES(is_primary),
+    base_score = VALUES(base_score),
+    vector_string = VALUES(vector_string),
+    attack_vector = VALUES(attack_vector)
+`
+
+	var (
+		cves      []string
+		args      []interface{}
+		numScores int
+	)
+	for _, meta := range batch {
+		if len(meta.CVSSScores) == 0 {
+			continue
+		}
+		cves = append(cves, meta.CVE)
+		for _, score := range meta.CVSSScores {
+			args = append(args, meta.CVE, score.Version, score.Source, score.Primary, score.BaseScore, score.VectorString, score.AttackVector)
+			numScores++
+		}
+	}
+	if len(cves) == 0 {
+		return nil
+	}
+
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		stmt, stmtArgs, err := sqlx.In(`DELETE FROM cve_cvss_scores WHERE cve IN (?)`, cves)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "build delete cvss scores query")
+		}
+		if _, err := tx.ExecContext(ctx, stmt, stmtArgs...); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete cvss scores")
+		}
+
+		valuesFrag := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?), ", numScores), ", ")
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(insertStmt, valuesFrag), args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert cvss scores")
+		}
+		return nil
+	})
+}
+
+// listCVSSScores returns all the CVSS scores of the CVE.
+func (ds *Datastore) listCVSSScores(ctx context.Context, cve string) ([]mdmlab.CVSSScore, error) {
+	var scores []mdmlab.CVSSScore
+	const stmt = `
+SELECT cve, version, source, is_primary, base_score, vector_string, attack_vector
+FROM cve_cvss_scores
+WHERE cve = ?
+ORDER BY is_primary DESC, version DESC, source`
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &scores, stmt, cve); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list cvss scores")
+	}
+	return scores, nil
+}
+
 func (ds *Datastore) InsertSoftwareVulnerability(
 	ctx context.Context,
 	vuln mdmlab.SoftwareVulnerability,
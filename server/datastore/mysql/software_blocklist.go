@@ -0,0 +1,394 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+const selectSoftwareBlocklistRuleStmt = `
+	SELECT
+		id,
+		team_id,
+		name,
+		bundle_identifier,
+		source,
+		min_version,
+		max_version,
+		script_id,
+		created_at,
+		updated_at
+	FROM software_blocklist_rules`
+
+func (ds *Datastore) NewSoftwareBlocklistRule(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule, labelIDs []uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	const stmt = `
+		INSERT INTO software_blocklist_rules (
+			team_id,
+			global_or_team_id,
+			name,
+			bundle_identifier,
+			source,
+			min_version,
+			max_version,
+			script_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var globalOrTeamID uint
+	if rule.TeamID != nil {
+		globalOrTeamID = *rule.TeamID
+	}
+
+	var ruleID uint
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		res, err := tx.ExecContext(ctx, stmt,
+			softwareBlocklistRuleTeamID(rule),
+			globalOrTeamID,
+			rule.Name,
+			rule.BundleIdentifier,
+			rule.Source,
+			rule.MinVersion,
+			rule.MaxVersion,
+			rule.ScriptID,
+		)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "insert software blocklist rule")
+		}
+		id, _ := res.LastInsertId()
+		ruleID = uint(id) //nolint:gosec // dismiss G115
+		return setSoftwareBlocklistRuleLabels(ctx, tx, ruleID, labelIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ds.getSoftwareBlocklistRule(ctx, ds.writer(ctx), ruleID)
+}
+
+func (ds *Datastore) UpdateSoftwareBlocklistRule(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule, labelIDs []uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	const stmt = `
+		UPDATE software_blocklist_rules SET
+			name = ?,
+			bundle_identifier = ?,
+			source = ?,
+			min_version = ?,
+			max_version = ?,
+			script_id = ?
+		WHERE id = ?`
+
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		res, err := tx.ExecContext(ctx, stmt,
+			rule.Name,
+			rule.BundleIdentifier,
+			rule.Source,
+			rule.MinVersion,
+			rule.MaxVersion,
+			rule.ScriptID,
+			rule.ID,
+		)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "update software blocklist rule")
+		}
+		// the rule may be unchanged, so check that it exists instead of
+		// relying on the number of affected rows.
+		if n, _ := res.RowsAffected(); n == 0 {
+			var exists bool
+			if err := sqlx.GetContext(ctx, tx, &exists, `SELECT 1 FROM software_blocklist_rules WHERE id = ?`, rule.ID); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ctxerr.Wrap(ctx, notFound("SoftwareBlocklistRule").WithID(rule.ID))
+				}
+				return ctxerr.Wrap(ctx, err, "check software blocklist rule exists")
+			}
+		}
+		return setSoftwareBlocklistRuleLabels(ctx, tx, rule.ID, labelIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ds.getSoftwareBlocklistRule(ctx, ds.writer(ctx), rule.ID)
+}
+
+func setSoftwareBlocklistRuleLabels(ctx context.Context, tx sqlx.ExtContext, ruleID uint, labelIDs []uint) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM software_blocklist_rule_labels WHERE rule_id = ?`, ruleID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software blocklist rule labels")
+	}
+	if len(labelIDs) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, 2*len(labelIDs))
+	for _, labelID := range labelIDs {
+		args = append(args, ruleID, labelID)
+	}
+	stmt := fmt.Sprintf(
+		`INSERT INTO software_blocklist_rule_labels (rule_id, label_id) VALUES %s`,
+		strings.TrimSuffix(strings.Repeat("(?,?),", len(labelIDs)), ","),
+	)
+	if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "insert software blocklist rule labels")
+	}
+	return nil
+}
+
+func (ds *Datastore) GetSoftwareBlocklistRule(ctx context.Context, id uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	return ds.getSoftwareBlocklistRule(ctx, ds.reader(ctx), id)
+}
+
+func (ds *Datastore) getSoftwareBlocklistRule(ctx context.Context, q sqlx.QueryerContext, id uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	var rule mdmlab.SoftwareBlocklistRule
+	if err := sqlx.GetContext(ctx, q, &rule, selectSoftwareBlocklistRuleStmt+` WHERE id = ?`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("SoftwareBlocklistRule").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get software blocklist rule")
+	}
+	if err := loadSoftwareBlocklistRulesLabels(ctx, q, []*mdmlab.SoftwareBlocklistRule{&rule}); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (ds *Datastore) ListSoftwareBlocklistRules(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareBlocklistRule, error) {
+	var globalOrTeamID uint
+	if teamID != nil {
+		globalOrTeamID = *teamID
+	}
+
+	var rules []*mdmlab.SoftwareBlocklistRule
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rules,
+		selectSoftwareBlocklistRuleStmt+` WHERE global_or_team_id = ? ORDER BY name, bundle_identifier, id`, globalOrTeamID,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software blocklist rules")
+	}
+	if err := loadSoftwareBlocklistRulesLabels(ctx, ds.reader(ctx), rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (ds *Datastore) ListAllSoftwareBlocklistRules(ctx context.Context) ([]*mdmlab.SoftwareBlocklistRule, error) {
+	var rules []*mdmlab.SoftwareBlocklistRule
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rules, selectSoftwareBlocklistRuleStmt+` ORDER BY id`); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list all software blocklist rules")
+	}
+	if err := loadSoftwareBlocklistRulesLabels(ctx, ds.reader(ctx), rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func loadSoftwareBlocklistRulesLabels(ctx context.Context, q sqlx.QueryerContext, rules []*mdmlab.SoftwareBlocklistRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	const stmt = `
+		SELECT
+			sbrl.rule_id,
+			l.id AS label_id,
+			l.name AS label_name
+		FROM software_blocklist_rule_labels sbrl
+		JOIN labels l ON l.id = sbrl.label_id
+		WHERE sbrl.rule_id IN (?)
+		ORDER BY l.name`
+
+	byID := make(map[uint]*mdmlab.SoftwareBlocklistRule, len(rules))
+	ids := make([]uint, 0, len(rules))
+	for _, r := range rules {
+		r.LabelsExcludeAny = []mdmlab.SoftwareScopeLabel{}
+		byID[r.ID] = r
+		ids = append(ids, r.ID)
+	}
+
+	query, args, err := sqlx.In(stmt, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build software blocklist rule labels query")
+	}
+	var rows []struct {
+		mdmlab.SoftwareScopeLabel
+		RuleID uint `db:"rule_id"`
+	}
+	if err := sqlx.SelectContext(ctx, q, &rows, query, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "load software blocklist rule labels")
+	}
+	for _, row := range rows {
+		row.Exclude = true
+		r := byID[row.RuleID]
+		r.LabelsExcludeAny = append(r.LabelsExcludeAny, row.SoftwareScopeLabel)
+	}
+	return nil
+}
+
+func (ds *Datastore) DeleteSoftwareBlocklistRule(ctx context.Context, id uint) error {
+	res, err := ds.writer(ctx).ExecContext(ctx, `DELETE FROM software_blocklist_rules WHERE id = ?`, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software blocklist rule")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("SoftwareBlocklistRule").WithID(id))
+	}
+	return nil
+}
+
+func (ds *Datastore) ListSoftwareBlocklistRuleHosts(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) ([]*mdmlab.SoftwareBlocklistHost, error) {
+	// the software of the team's hosts matching the name, bundle identifier
+	// and source of the rule, the version range is checked in Go as versions
+	// can't be compared in MySQL.
+	stmt := `
+		SELECT
+			h.id AS host_id,
+			COALESCE(hdn.display_name, '') AS host_display_name,
+			h.platform AS host_platform,
+			s.id AS software_id,
+			s.title_id AS software_title_id,
+			s.name,
+			s.version,
+			s.source,
+			EXISTS (
+				SELECT 1
+				FROM software_blocklist_rule_labels sbrl
+				JOIN label_membership lm ON lm.label_id = sbrl.label_id
+				WHERE sbrl.rule_id = ? AND lm.host_id = h.id
+			) AS excepted
+		FROM software s
+		JOIN host_software hs ON hs.software_id = s.id
+		JOIN hosts h ON h.id = hs.host_id
+		LEFT JOIN host_display_names hdn ON hdn.host_id = h.id
+		WHERE h.team_id <=> ?`
+	args := []any{rule.ID, softwareBlocklistRuleTeamID(rule)}
+	if rule.Name != "" {
+		stmt += ` AND s.name = ?`
+		args = append(args, rule.Name)
+	}
+	if rule.BundleIdentifier != "" {
+		stmt += ` AND s.bundle_identifier = ?`
+		args = append(args, rule.BundleIdentifier)
+	}
+	if rule.Source != "" {
+		stmt += ` AND s.source = ?`
+		args = append(args, rule.Source)
+	}
+	stmt += ` ORDER BY h.id, s.id`
+
+	var rows []*mdmlab.SoftwareBlocklistHost
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rows, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software blocklist rule hosts")
+	}
+
+	hosts := rows[:0]
+	byHostID := make(map[uint][]*mdmlab.SoftwareBlocklistHost)
+	for _, h := range rows {
+		if !rule.MatchesVersion(h.Version) {
+			continue
+		}
+		hosts = append(hosts, h)
+		byHostID[h.HostID] = append(byHostID[h.HostID], h)
+	}
+	if len(hosts) == 0 {
+		return hosts, nil
+	}
+
+	// the last removal of each host, its status is that of the script
+	// execution.
+	const removalsStmt = `
+		SELECT
+			sbr.host_id,
+			sbr.execution_id,
+			sbr.created_at,
+			hsr.exit_code
+		FROM software_blocklist_removals sbr
+		LEFT JOIN host_script_results hsr ON hsr.execution_id = sbr.execution_id
+		WHERE sbr.id IN (
+			SELECT MAX(id) FROM software_blocklist_removals
+			WHERE rule_id = ? AND host_id IN (?)
+			GROUP BY host_id
+		)`
+	hostIDs := make([]uint, 0, len(byHostID))
+	for hostID := range byHostID {
+		hostIDs = append(hostIDs, hostID)
+	}
+	query, args, err := sqlx.In(removalsStmt, rule.ID, hostIDs)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "build software blocklist removals query")
+	}
+	var removals []struct {
+		HostID      uint          `db:"host_id"`
+		ExecutionID string        `db:"execution_id"`
+		CreatedAt   sql.NullTime  `db:"created_at"`
+		ExitCode    sql.NullInt64 `db:"exit_code"`
+	}
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &removals, query, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software blocklist removals")
+	}
+
+	for _, removal := range removals {
+		status := mdmlab.SoftwareBlocklistRemovalPending
+		switch {
+		case !removal.ExitCode.Valid:
+		case removal.ExitCode.Int64 == 0:
+			status = mdmlab.SoftwareBlocklistRemovalSucceeded
+		default:
+			status = mdmlab.SoftwareBlocklistRemovalFailed
+		}
+		for _, h := range byHostID[removal.HostID] {
+			execID, requestedAt := removal.ExecutionID, removal.CreatedAt.Time
+			h.RemovalExecutionID = &execID
+			h.RemovalStatus = &status
+			h.RemovalRequestedAt = &requestedAt
+		}
+	}
+	return hosts, nil
+}
+
+func (ds *Datastore) GetSoftwareBlocklistRuleHostCounts(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) (*mdmlab.SoftwareBlocklistHostCounts, error) {
+	hosts, err := ds.ListSoftwareBlocklistRuleHosts(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var total uint
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &total,
+		`SELECT COUNT(*) FROM hosts WHERE team_id <=> ?`, softwareBlocklistRuleTeamID(rule),
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "count software blocklist rule team hosts")
+	}
+
+	var counts mdmlab.SoftwareBlocklistHostCounts
+	seen := make(map[uint]bool, len(hosts))
+	for _, h := range hosts {
+		if seen[h.HostID] {
+			continue
+		}
+		seen[h.HostID] = true
+		if h.Excepted {
+			counts.Excepted++
+		} else {
+			counts.NonCompliant++
+		}
+	}
+	if n := counts.Excepted + counts.NonCompliant; total > n {
+		counts.Compliant = total - n
+	}
+	return &counts, nil
+}
+
+// softwareBlocklistRuleTeamID returns the team_id of the rule and of its
+// hosts, nil for no team.
+func softwareBlocklistRuleTeamID(rule *mdmlab.SoftwareBlocklistRule) *uint {
+	if rule.TeamID == nil || *rule.TeamID == 0 {
+		return nil
+	}
+	return rule.TeamID
+}
+
+func (ds *Datastore) NewSoftwareBlocklistRemoval(ctx context.Context, ruleID, hostID uint, executionID string) error {
+	const stmt = `INSERT INTO software_blocklist_removals (rule_id, host_id, execution_id) VALUES (?, ?, ?)`
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, ruleID, hostID, executionID); err != nil {
+		return ctxerr.Wrap(ctx, err, "insert software blocklist removal")
+	}
+	return nil
+}
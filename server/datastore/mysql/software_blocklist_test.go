@@ -0,0 +1,188 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareBlocklist(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CRUD", testSoftwareBlocklistCRUD},
+		{"Hosts", testSoftwareBlocklistHosts},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testSoftwareBlocklistCRUD(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	label1, err := ds.NewLabel(ctx, &mdmlab.Label{Name: "label1", Query: "select 1"})
+	require.NoError(t, err)
+	label2, err := ds.NewLabel(ctx, &mdmlab.Label{Name: "label2", Query: "select 1"})
+	require.NoError(t, err)
+	script, err := ds.NewScript(ctx, &mdmlab.Script{Name: "remove.sh", TeamID: &team.ID, ScriptContents: "echo remove"})
+	require.NoError(t, err)
+
+	_, err = ds.GetSoftwareBlocklistRule(ctx, 1)
+	require.True(t, mdmlab.IsNotFound(err))
+	require.True(t, mdmlab.IsNotFound(ds.DeleteSoftwareBlocklistRule(ctx, 1)))
+
+	rule, err := ds.NewSoftwareBlocklistRule(ctx, &mdmlab.SoftwareBlocklistRule{
+		TeamID:     &team.ID,
+		Name:       "uTorrent.app",
+		Source:     "apps",
+		MaxVersion: "3.5.5",
+		ScriptID:   &script.ID,
+	}, []uint{label1.ID})
+	require.NoError(t, err)
+	require.NotZero(t, rule.ID)
+	require.Equal(t, "uTorrent.app", rule.Name)
+	require.Equal(t, "3.5.5", rule.MaxVersion)
+	require.Equal(t, script.ID, *rule.ScriptID)
+	require.Len(t, rule.LabelsExcludeAny, 1)
+	require.Equal(t, label1.Name, rule.LabelsExcludeAny[0].LabelName)
+
+	noTeamRule, err := ds.NewSoftwareBlocklistRule(ctx, &mdmlab.SoftwareBlocklistRule{BundleIdentifier: "com.example.game"}, nil)
+	require.NoError(t, err)
+	require.Empty(t, noTeamRule.LabelsExcludeAny)
+
+	rules, err := ds.ListSoftwareBlocklistRules(ctx, &team.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, rule.ID, rules[0].ID)
+	rules, err = ds.ListSoftwareBlocklistRules(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, noTeamRule.ID, rules[0].ID)
+	rules, err = ds.ListAllSoftwareBlocklistRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	rule.MinVersion = "3.0"
+	rule.ScriptID = nil
+	rule, err = ds.UpdateSoftwareBlocklistRule(ctx, rule, []uint{label2.ID})
+	require.NoError(t, err)
+	require.Equal(t, "3.0", rule.MinVersion)
+	require.Nil(t, rule.ScriptID)
+	require.Len(t, rule.LabelsExcludeAny, 1)
+	require.Equal(t, label2.Name, rule.LabelsExcludeAny[0].LabelName)
+
+	// updating without changes succeeds
+	_, err = ds.UpdateSoftwareBlocklistRule(ctx, rule, []uint{label2.ID})
+	require.NoError(t, err)
+	_, err = ds.UpdateSoftwareBlocklistRule(ctx, &mdmlab.SoftwareBlocklistRule{ID: rule.ID + 100, Name: "foo"}, nil)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// deleting the script of a rule falls back to the uninstall script
+	rule, err = ds.UpdateSoftwareBlocklistRule(ctx, rule, nil)
+	require.NoError(t, err)
+	require.NoError(t, ds.DeleteScript(ctx, script.ID))
+	rule, err = ds.GetSoftwareBlocklistRule(ctx, rule.ID)
+	require.NoError(t, err)
+	require.Nil(t, rule.ScriptID)
+	require.Empty(t, rule.LabelsExcludeAny)
+
+	require.NoError(t, ds.DeleteSoftwareBlocklistRule(ctx, rule.ID))
+	_, err = ds.GetSoftwareBlocklistRule(ctx, rule.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+}
+
+func testSoftwareBlocklistHosts(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	label, err := ds.NewLabel(ctx, &mdmlab.Label{Name: "exceptions", Query: "select 1"})
+	require.NoError(t, err)
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now(), test.WithTeamID(team.ID))
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now(), test.WithTeamID(team.ID))
+	host3 := test.NewHost(t, ds, "host3", "", "h3key", "h3uuid", time.Now(), test.WithTeamID(team.ID))
+	test.NewHost(t, ds, "host4", "", "h4key", "h4uuid", time.Now(), test.WithTeamID(team.ID))
+	noTeamHost := test.NewHost(t, ds, "host5", "", "h5key", "h5uuid", time.Now())
+
+	_, err = ds.UpdateHostSoftware(ctx, host1.ID, []mdmlab.Software{{Name: "uTorrent.app", Version: "3.5.0", Source: "apps"}})
+	require.NoError(t, err)
+	// version above the blocklisted range
+	_, err = ds.UpdateHostSoftware(ctx, host2.ID, []mdmlab.Software{{Name: "uTorrent.app", Version: "3.6.0", Source: "apps"}})
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, host3.ID, []mdmlab.Software{{Name: "uTorrent.app", Version: "3.5.0", Source: "apps"}})
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, noTeamHost.ID, []mdmlab.Software{{Name: "uTorrent.app", Version: "3.5.0", Source: "apps"}})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddLabelsToHost(ctx, host3.ID, []uint{label.ID}))
+
+	rule, err := ds.NewSoftwareBlocklistRule(ctx, &mdmlab.SoftwareBlocklistRule{
+		TeamID:     &team.ID,
+		Name:       "uTorrent.app",
+		Source:     "apps",
+		MaxVersion: "3.5.5",
+	}, []uint{label.ID})
+	require.NoError(t, err)
+
+	hosts, err := ds.ListSoftwareBlocklistRuleHosts(ctx, rule)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	require.Equal(t, host1.ID, hosts[0].HostID)
+	require.Equal(t, "3.5.0", hosts[0].Version)
+	require.False(t, hosts[0].Excepted)
+	require.Nil(t, hosts[0].RemovalStatus)
+	require.Equal(t, host3.ID, hosts[1].HostID)
+	require.True(t, hosts[1].Excepted)
+
+	counts, err := ds.GetSoftwareBlocklistRuleHostCounts(ctx, rule)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareBlocklistHostCounts{Compliant: 2, NonCompliant: 1, Excepted: 1}, *counts)
+
+	// request the removal, it is pending until the script result is received
+	res, err := ds.NewHostScriptExecutionRequest(ctx, &mdmlab.HostScriptRequestPayload{
+		HostID:         host1.ID,
+		ScriptContents: "echo remove",
+		TeamID:         team.ID,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.NewSoftwareBlocklistRemoval(ctx, rule.ID, host1.ID, res.ExecutionID))
+
+	hosts, err = ds.ListSoftwareBlocklistRuleHosts(ctx, rule)
+	require.NoError(t, err)
+	require.Equal(t, res.ExecutionID, *hosts[0].RemovalExecutionID)
+	require.Equal(t, mdmlab.SoftwareBlocklistRemovalPending, *hosts[0].RemovalStatus)
+	require.NotNil(t, hosts[0].RemovalRequestedAt)
+
+	_, _, err = ds.SetHostScriptExecutionResult(ctx, &mdmlab.HostScriptResultPayload{
+		HostID:      host1.ID,
+		ExecutionID: res.ExecutionID,
+		ExitCode:    1,
+	})
+	require.NoError(t, err)
+	hosts, err = ds.ListSoftwareBlocklistRuleHosts(ctx, rule)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareBlocklistRemovalFailed, *hosts[0].RemovalStatus)
+
+	// a rule for no team only matches the hosts in no team
+	noTeamRule, err := ds.NewSoftwareBlocklistRule(ctx, &mdmlab.SoftwareBlocklistRule{Name: "uTorrent.app", TeamID: ptr.Uint(0)}, nil)
+	require.NoError(t, err)
+	hosts, err = ds.ListSoftwareBlocklistRuleHosts(ctx, noTeamRule)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, noTeamHost.ID, hosts[0].HostID)
+}
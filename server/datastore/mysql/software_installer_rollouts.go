@@ -0,0 +1,280 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+const selectSoftwareInstallerRolloutStmt = `
+	SELECT
+		r.id,
+		r.software_installer_id,
+		si.team_id,
+		COALESCE(si.title_id, 0) AS title_id,
+		COALESCE(st.name, '') AS software_title,
+		si.filename,
+		si.version AS installer_version,
+		si.platform,
+		r.version,
+		r.stages,
+		r.success_threshold_percent,
+		r.max_failed_installs,
+		r.status,
+		r.status_reason,
+		r.current_stage,
+		r.stage_started_at,
+		r.started_at,
+		r.created_at,
+		r.updated_at
+	FROM software_installer_rollouts r
+	JOIN software_installers si ON si.id = r.software_installer_id
+	LEFT JOIN software_titles st ON st.id = si.title_id`
+
+func (ds *Datastore) SetSoftwareInstallerRollout(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) (*mdmlab.SoftwareInstallerRollout, error) {
+	// setting the rollout of an installer that already has one restarts it
+	// from the first stage.
+	const stmt = `
+		INSERT INTO software_installer_rollouts (
+			software_installer_id,
+			version,
+			stages,
+			success_threshold_percent,
+			max_failed_installs,
+			status,
+			status_reason,
+			current_stage,
+			stage_started_at,
+			started_at
+		) VALUES (?, ?, ?, ?, ?, ?, NULL, 0, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			version = VALUES(version),
+			stages = VALUES(stages),
+			success_threshold_percent = VALUES(success_threshold_percent),
+			max_failed_installs = VALUES(max_failed_installs),
+			status = VALUES(status),
+			status_reason = NULL,
+			current_stage = 0,
+			stage_started_at = VALUES(stage_started_at),
+			started_at = VALUES(started_at)`
+
+	now := time.Now().UTC()
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt,
+		rollout.SoftwareInstallerID,
+		rollout.Version,
+		rollout.Stages,
+		rollout.SuccessThresholdPercent,
+		rollout.MaxFailedInstalls,
+		mdmlab.SoftwareInstallerRolloutInProgress,
+		now,
+		now,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "set software installer rollout")
+	}
+
+	return ds.getSoftwareInstallerRollout(ctx, ds.writer(ctx), rollout.SoftwareInstallerID)
+}
+
+func (ds *Datastore) GetSoftwareInstallerRollout(ctx context.Context, installerID uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	return ds.getSoftwareInstallerRollout(ctx, ds.reader(ctx), installerID)
+}
+
+func (ds *Datastore) getSoftwareInstallerRollout(ctx context.Context, q sqlx.QueryerContext, installerID uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	var rollout mdmlab.SoftwareInstallerRollout
+	if err := sqlx.GetContext(ctx, q, &rollout, selectSoftwareInstallerRolloutStmt+` WHERE r.software_installer_id = ?`, installerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("SoftwareInstallerRollout").WithID(installerID))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get software installer rollout")
+	}
+	return &rollout, nil
+}
+
+func (ds *Datastore) ListActiveSoftwareInstallerRollouts(ctx context.Context) ([]*mdmlab.SoftwareInstallerRollout, error) {
+	stmt := selectSoftwareInstallerRolloutStmt + ` WHERE r.status IN (?, ?) ORDER BY r.id`
+
+	var rollouts []*mdmlab.SoftwareInstallerRollout
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rollouts, stmt,
+		mdmlab.SoftwareInstallerRolloutInProgress, mdmlab.SoftwareInstallerRolloutPaused,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list active software installer rollouts")
+	}
+	return rollouts, nil
+}
+
+func (ds *Datastore) UpdateSoftwareInstallerRollout(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) error {
+	const stmt = `
+		UPDATE software_installer_rollouts SET
+			version = ?,
+			status = ?,
+			status_reason = ?,
+			current_stage = ?,
+			stage_started_at = ?,
+			started_at = ?
+		WHERE id = ?`
+
+	res, err := ds.writer(ctx).ExecContext(ctx, stmt,
+		rollout.Version,
+		rollout.Status,
+		rollout.StatusReason,
+		rollout.CurrentStage,
+		rollout.StageStartedAt,
+		rollout.StartedAt,
+		rollout.ID,
+	)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "update software installer rollout")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// the row may be unchanged, make sure it exists
+		if _, err := ds.getSoftwareInstallerRollout(ctx, ds.writer(ctx), rollout.SoftwareInstallerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) DeleteSoftwareInstallerRollout(ctx context.Context, installerID uint) error {
+	res, err := ds.writer(ctx).ExecContext(ctx, `DELETE FROM software_installer_rollouts WHERE software_installer_id = ?`, installerID)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software installer rollout")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("SoftwareInstallerRollout").WithID(installerID))
+	}
+	return nil
+}
+
+func (ds *Datastore) GetSoftwareInstallerRolloutStats(ctx context.Context, installerID uint, since time.Time) (*mdmlab.SoftwareInstallerRolloutStats, error) {
+	const stmt = `
+		SELECT
+			COALESCE(SUM(execution_status = 'installed'), 0) AS installed,
+			COALESCE(SUM(execution_status = 'failed_install'), 0) AS failed,
+			COALESCE(SUM(execution_status = 'pending_install'), 0) AS pending
+		FROM host_software_installs
+		WHERE
+			software_installer_id = ? AND
+			uninstall = 0 AND
+			host_deleted_at IS NULL AND
+			created_at >= ?`
+
+	var stats mdmlab.SoftwareInstallerRolloutStats
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &stats, stmt, installerID, since); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software installer rollout stats")
+	}
+	return &stats, nil
+}
+
+// softwareInstallerRolloutStagesCondition returns the SQL condition matching
+// the hosts (aliased h) targeted by the stages, and its arguments. It returns
+// FALSE if there are no stages.
+func softwareInstallerRolloutStagesCondition(installerID uint, stages []mdmlab.SoftwareInstallerRolloutStage) (string, []any) {
+	var (
+		maxPercent uint
+		labelIDs   []uint
+	)
+	for _, stage := range stages {
+		if stage.Percent != nil && *stage.Percent > maxPercent {
+			maxPercent = *stage.Percent
+		}
+		if stage.LabelID != nil {
+			labelIDs = append(labelIDs, *stage.LabelID)
+		}
+	}
+
+	var (
+		conds []string
+		args  []any
+	)
+	if maxPercent > 0 {
+		// must match mdmlab.SoftwareInstallerRolloutBucket
+		conds = append(conds, `CRC32(CONCAT(?, ':', h.id)) % 100 < ?`)
+		args = append(args, installerID, maxPercent)
+	}
+	if len(labelIDs) > 0 {
+		conds = append(conds, fmt.Sprintf(
+			`EXISTS (SELECT 1 FROM label_membership lm WHERE lm.host_id = h.id AND lm.label_id IN (%s))`,
+			strings.TrimSuffix(strings.Repeat("?,", len(labelIDs)), ","),
+		))
+		for _, id := range labelIDs {
+			args = append(args, id)
+		}
+	}
+	if len(conds) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
+func (ds *Datastore) IsSoftwareInstallerRolloutScoped(ctx context.Context, installerID, hostID uint) (bool, error) {
+	rollout, err := ds.GetSoftwareInstallerRollout(ctx, installerID)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			// installers without a rollout are available to all hosts
+			return true, nil
+		}
+		return false, err
+	}
+	if rollout.Status == mdmlab.SoftwareInstallerRolloutCompleted {
+		return true, nil
+	}
+
+	cond, args := softwareInstallerRolloutStagesCondition(installerID, rollout.ReachedStages())
+	stmt := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM hosts h WHERE h.id = ? AND %s)`, cond)
+	args = append([]any{hostID}, args...)
+
+	var scoped bool
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &scoped, stmt, args...); err != nil {
+		return false, ctxerr.Wrap(ctx, err, "is software installer rollout scoped")
+	}
+	return scoped, nil
+}
+
+func (ds *Datastore) ListSoftwareInstallerRolloutHostsToInstall(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) ([]*mdmlab.SoftwareInstallerRolloutHost, error) {
+	stages := rollout.ReachedStages()
+	if len(stages) == 0 {
+		return nil, nil
+	}
+
+	cond, condArgs := softwareInstallerRolloutStagesCondition(rollout.SoftwareInstallerID, stages)
+	if rollout.Status == mdmlab.SoftwareInstallerRolloutCompleted {
+		cond, condArgs = "TRUE", nil
+	}
+
+	// hosts failing a policy that automatically installs the software, with
+	// fleetd, and without an install request since the start of the rollout.
+	stmt := fmt.Sprintf(`
+		SELECT
+			h.id AS host_id,
+			h.platform,
+			MIN(p.id) AS policy_id
+		FROM hosts h
+		JOIN policy_membership pm ON pm.host_id = h.id AND pm.passes = 0
+		JOIN policies p ON p.id = pm.policy_id AND p.software_installer_id = ?
+		WHERE
+			h.orbit_node_key IS NOT NULL AND h.orbit_node_key != '' AND
+			COALESCE(h.team_id, 0) = COALESCE(p.team_id, 0) AND
+			%s AND
+			NOT EXISTS (
+				SELECT 1 FROM host_software_installs hsi
+				WHERE hsi.host_id = h.id AND hsi.software_installer_id = ? AND hsi.created_at >= ?
+			)
+		GROUP BY h.id, h.platform
+		ORDER BY h.id`, cond)
+	args := []any{rollout.SoftwareInstallerID}
+	args = append(args, condArgs...)
+	args = append(args, rollout.SoftwareInstallerID, rollout.StartedAt)
+
+	var hosts []*mdmlab.SoftwareInstallerRolloutHost
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &hosts, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software installer rollout hosts to install")
+	}
+	return hosts, nil
+}
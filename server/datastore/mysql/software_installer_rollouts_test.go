@@ -0,0 +1,258 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareInstallerRollouts(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CRUD", testSoftwareInstallerRolloutsCRUD},
+		{"Scope", testSoftwareInstallerRolloutsScope},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func createRolloutSoftwareInstaller(t *testing.T, ds *Datastore, teamID uint, userID uint) uint {
+	ctx := context.Background()
+
+	tfr, err := mdmlab.NewTempFileReader(bytes.NewReader([]byte("installer")), t.TempDir)
+	require.NoError(t, err)
+	installerID, _, err := ds.MatchOrCreateSoftwareInstaller(ctx, &mdmlab.UploadSoftwareInstallerPayload{
+		InstallScript:   "install",
+		InstallerFile:   tfr,
+		StorageID:       "storage1",
+		Filename:        "installer.pkg",
+		Title:           "ins0",
+		Version:         "1.0",
+		Source:          "apps",
+		Platform:        "darwin",
+		TeamID:          &teamID,
+		UserID:          userID,
+		ValidatedLabels: &mdmlab.LabelIdentsWithScope{},
+	})
+	require.NoError(t, err)
+	return installerID
+}
+
+func testSoftwareInstallerRolloutsCRUD(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	installerID := createRolloutSoftwareInstaller(t, ds, team.ID, user.ID)
+
+	_, err = ds.GetSoftwareInstallerRollout(ctx, installerID)
+	require.True(t, mdmlab.IsNotFound(err))
+	require.True(t, mdmlab.IsNotFound(ds.DeleteSoftwareInstallerRollout(ctx, installerID)))
+
+	rollout, err := ds.SetSoftwareInstallerRollout(ctx, &mdmlab.SoftwareInstallerRollout{
+		SoftwareInstallerID: installerID,
+		Version:             "1.0",
+		Stages: mdmlab.SoftwareInstallerRolloutStages{
+			{Percent: ptr.Uint(10), SoakMinutes: 60},
+			{Percent: ptr.Uint(100)},
+		},
+		SuccessThresholdPercent: 90,
+		MaxFailedInstalls:       3,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, rollout.ID)
+	require.Equal(t, installerID, rollout.SoftwareInstallerID)
+	require.Equal(t, &team.ID, rollout.TeamID)
+	require.NotZero(t, rollout.TitleID)
+	require.Equal(t, "ins0", rollout.SoftwareTitle)
+	require.Equal(t, "installer.pkg", rollout.Package)
+	require.Equal(t, "1.0", rollout.InstallerVersion)
+	require.Equal(t, "darwin", rollout.InstallerPlatform)
+	require.Equal(t, mdmlab.SoftwareInstallerRolloutInProgress, rollout.Status)
+	require.Zero(t, rollout.CurrentStage)
+	require.Len(t, rollout.Stages, 2)
+	require.Equal(t, uint(10), *rollout.Stages[0].Percent)
+	require.Equal(t, uint(60), rollout.Stages[0].SoakMinutes)
+	require.Equal(t, uint(90), rollout.SuccessThresholdPercent)
+	require.Equal(t, uint(3), rollout.MaxFailedInstalls)
+
+	// advance and pause it
+	rollout.CurrentStage = 1
+	rollout.Status = mdmlab.SoftwareInstallerRolloutPaused
+	rollout.StatusReason = ptr.String("too many failures")
+	require.NoError(t, ds.UpdateSoftwareInstallerRollout(ctx, rollout))
+
+	got, err := ds.GetSoftwareInstallerRollout(ctx, installerID)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), got.CurrentStage)
+	require.Equal(t, mdmlab.SoftwareInstallerRolloutPaused, got.Status)
+	require.Equal(t, "too many failures", *got.StatusReason)
+
+	// updating with the same values is not an error
+	require.NoError(t, ds.UpdateSoftwareInstallerRollout(ctx, got))
+
+	active, err := ds.ListActiveSoftwareInstallerRollouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, rollout.ID, active[0].ID)
+
+	// setting the rollout again restarts it
+	rollout, err = ds.SetSoftwareInstallerRollout(ctx, &mdmlab.SoftwareInstallerRollout{
+		SoftwareInstallerID:     installerID,
+		Version:                 "1.0",
+		Stages:                  mdmlab.SoftwareInstallerRolloutStages{{Percent: ptr.Uint(50)}},
+		SuccessThresholdPercent: 95,
+		MaxFailedInstalls:       5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, got.ID, rollout.ID)
+	require.Zero(t, rollout.CurrentStage)
+	require.Nil(t, rollout.StatusReason)
+	require.Equal(t, mdmlab.SoftwareInstallerRolloutInProgress, rollout.Status)
+	require.Len(t, rollout.Stages, 1)
+
+	// completed rollouts are not active
+	rollout.Status = mdmlab.SoftwareInstallerRolloutCompleted
+	require.NoError(t, ds.UpdateSoftwareInstallerRollout(ctx, rollout))
+	active, err = ds.ListActiveSoftwareInstallerRollouts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, active)
+
+	require.NoError(t, ds.DeleteSoftwareInstallerRollout(ctx, installerID))
+	_, err = ds.GetSoftwareInstallerRollout(ctx, installerID)
+	require.True(t, mdmlab.IsNotFound(err))
+}
+
+func testSoftwareInstallerRolloutsScope(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	installerID := createRolloutSoftwareInstaller(t, ds, team.ID, user.ID)
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now(), test.WithTeamID(team.ID))
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now(), test.WithTeamID(team.ID))
+	// host3 has no fleetd, it is never listed
+	host3 := test.NewHost(t, ds, "host3", "", "h3key", "h3uuid", time.Now(), test.WithTeamID(team.ID))
+	ExecAdhocSQL(t, ds, func(q sqlx.ExtContext) error {
+		_, err := q.ExecContext(ctx, `UPDATE hosts SET orbit_node_key = CONCAT('orbit', id) WHERE id IN (?, ?)`, host1.ID, host2.ID)
+		return err
+	})
+
+	label, err := ds.NewLabel(ctx, &mdmlab.Label{Name: "canary", Query: "SELECT 1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddLabelsToHost(ctx, host1.ID, []uint{label.ID}))
+
+	policy, err := ds.NewTeamPolicy(ctx, team.ID, &user.ID, mdmlab.PolicyPayload{
+		Name:                "p1",
+		Query:               "SELECT 1;",
+		SoftwareInstallerID: &installerID,
+	})
+	require.NoError(t, err)
+	ExecAdhocSQL(t, ds, func(q sqlx.ExtContext) error {
+		_, err := q.ExecContext(ctx, `INSERT INTO policy_membership (policy_id, host_id, passes) VALUES (?, ?, 0), (?, ?, 0), (?, ?, 0)`,
+			policy.ID, host1.ID, policy.ID, host2.ID, policy.ID, host3.ID)
+		return err
+	})
+
+	// without a rollout, all hosts are in scope
+	scoped, err := ds.IsSoftwareInstallerRolloutScoped(ctx, installerID, host2.ID)
+	require.NoError(t, err)
+	require.True(t, scoped)
+
+	rollout, err := ds.SetSoftwareInstallerRollout(ctx, &mdmlab.SoftwareInstallerRollout{
+		SoftwareInstallerID: installerID,
+		Version:             "1.0",
+		Stages: mdmlab.SoftwareInstallerRolloutStages{
+			{LabelID: &label.ID, SoakMinutes: 60},
+			{Percent: ptr.Uint(100)},
+		},
+		SuccessThresholdPercent: 95,
+		MaxFailedInstalls:       5,
+	})
+	require.NoError(t, err)
+
+	assertScoped := func(hostID uint, want bool) {
+		scoped, err := ds.IsSoftwareInstallerRolloutScoped(ctx, installerID, hostID)
+		require.NoError(t, err)
+		require.Equal(t, want, scoped)
+	}
+	assertHostsToInstall := func(want ...uint) {
+		hosts, err := ds.ListSoftwareInstallerRolloutHostsToInstall(ctx, rollout)
+		require.NoError(t, err)
+		var got []uint
+		for _, h := range hosts {
+			require.Equal(t, policy.ID, h.PolicyID)
+			require.Equal(t, "darwin", h.Platform)
+			got = append(got, h.HostID)
+		}
+		require.Equal(t, want, got)
+	}
+
+	// first stage only targets the label members
+	assertScoped(host1.ID, true)
+	assertScoped(host2.ID, false)
+	assertHostsToInstall(host1.ID)
+
+	// hosts with an install request are not listed anymore
+	installUUID, err := ds.InsertSoftwareInstallRequest(ctx, host1.ID, installerID, false, &policy.ID)
+	require.NoError(t, err)
+	assertHostsToInstall()
+
+	stats, err := ds.GetSoftwareInstallerRolloutStats(ctx, installerID, rollout.StartedAt)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareInstallerRolloutStats{Pending: 1}, *stats)
+
+	require.NoError(t, ds.SetHostSoftwareInstallResult(ctx, &mdmlab.HostSoftwareInstallResultPayload{
+		HostID:                host1.ID,
+		InstallUUID:           installUUID,
+		InstallScriptExitCode: ptr.Int(1),
+	}))
+	stats, err = ds.GetSoftwareInstallerRolloutStats(ctx, installerID, rollout.StartedAt)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareInstallerRolloutStats{Failed: 1}, *stats)
+
+	// installs requested before the rollout started are not counted
+	stats, err = ds.GetSoftwareInstallerRolloutStats(ctx, installerID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareInstallerRolloutStats{}, *stats)
+
+	// second stage targets all hosts
+	rollout.CurrentStage = 1
+	require.NoError(t, ds.UpdateSoftwareInstallerRollout(ctx, rollout))
+	assertScoped(host1.ID, true)
+	assertScoped(host2.ID, true)
+	assertHostsToInstall(host2.ID)
+
+	// paused rollouts target no host
+	rollout.Status = mdmlab.SoftwareInstallerRolloutPaused
+	require.NoError(t, ds.UpdateSoftwareInstallerRollout(ctx, rollout))
+	assertScoped(host1.ID, false)
+	assertScoped(host2.ID, false)
+	assertHostsToInstall()
+
+	// completed rollouts target all hosts
+	rollout.Status = mdmlab.SoftwareInstallerRolloutCompleted
+	require.NoError(t, ds.UpdateSoftwareInstallerRollout(ctx, rollout))
+	assertScoped(host2.ID, true)
+	assertScoped(host3.ID, true)
+	assertHostsToInstall(host2.ID)
+}
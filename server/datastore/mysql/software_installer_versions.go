@@ -0,0 +1,334 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+// softwareInstallerVersionScriptsMatch is the SQL condition matching a
+// version (aliased siv) whose scripts are those of the installer (aliased si).
+const softwareInstallerVersionScriptsMatch = `
+	siv.install_script_content_id = si.install_script_content_id AND
+	siv.uninstall_script_content_id = si.uninstall_script_content_id AND
+	siv.post_install_script_content_id <=> si.post_install_script_content_id AND
+	COALESCE(siv.pre_install_query, '') = COALESCE(si.pre_install_query, '')`
+
+// softwareInstallerVersionIsCurrent is the SQL condition matching the current
+// version (aliased siv) of the installer (aliased si).
+const softwareInstallerVersionIsCurrent = `siv.storage_id = si.storage_id AND` + softwareInstallerVersionScriptsMatch
+
+const selectSoftwareInstallerVersionsStmt = `
+	SELECT
+		siv.id,
+		siv.software_installer_id,
+		siv.version,
+		siv.filename,
+		siv.storage_id,
+		siv.user_name,
+		siv.user_email,
+		siv.uploaded_at,
+		siv.signature_status,
+		siv.signer,
+		siv.signer_team_id,
+		siv.has_stapled_ticket,
+		(` + softwareInstallerVersionIsCurrent + `) AS is_current,
+		(si.pinned_version_id <=> siv.id) AS is_pinned
+	FROM software_installer_versions siv
+	JOIN software_installers si ON si.id = siv.software_installer_id
+	WHERE siv.software_installer_id = ?`
+
+// recordSoftwareInstallerVersionDB adds the current package and scripts of the
+// installer to its versions, unless they already are one of its versions.
+func recordSoftwareInstallerVersionDB(ctx context.Context, tx sqlx.ExtContext, installerID uint) error {
+	const stmt = `
+		INSERT INTO software_installer_versions (
+			software_installer_id, version, filename, extension, storage_id, package_ids, url, pre_install_query,
+			install_script_content_id, post_install_script_content_id, uninstall_script_content_id,
+			user_id, user_name, user_email, uploaded_at, signature_status, signer, signer_team_id, has_stapled_ticket
+		)
+		SELECT
+			si.id, si.version, si.filename, si.extension, si.storage_id, si.package_ids, si.url, si.pre_install_query,
+			si.install_script_content_id, si.post_install_script_content_id, si.uninstall_script_content_id,
+			si.user_id, si.user_name, si.user_email, si.uploaded_at, si.signature_status, si.signer, si.signer_team_id, si.has_stapled_ticket
+		FROM software_installers si
+		WHERE
+			si.id = ? AND
+			NOT EXISTS (
+				SELECT 1 FROM software_installer_versions siv
+				WHERE siv.software_installer_id = si.id AND ` + softwareInstallerVersionIsCurrent + `
+			)`
+
+	if _, err := tx.ExecContext(ctx, stmt, installerID); err != nil {
+		return ctxerr.Wrap(ctx, err, "record software installer version")
+	}
+	return nil
+}
+
+func (ds *Datastore) ListSoftwareInstallerVersions(ctx context.Context, installerID uint) ([]*mdmlab.SoftwareInstallerVersion, error) {
+	var versions []*mdmlab.SoftwareInstallerVersion
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &versions, selectSoftwareInstallerVersionsStmt+` ORDER BY siv.id DESC`, installerID); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software installer versions")
+	}
+	if err := ds.loadSoftwareInstallerVersionsLabels(ctx, versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (ds *Datastore) GetSoftwareInstallerVersion(ctx context.Context, installerID, versionID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+	return ds.getSoftwareInstallerVersion(ctx, ds.reader(ctx), installerID, versionID)
+}
+
+func (ds *Datastore) getSoftwareInstallerVersion(ctx context.Context, q sqlx.QueryerContext, installerID, versionID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+	var version mdmlab.SoftwareInstallerVersion
+	if err := sqlx.GetContext(ctx, q, &version, selectSoftwareInstallerVersionsStmt+` AND siv.id = ?`, installerID, versionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("SoftwareInstallerVersion").WithID(versionID))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get software installer version")
+	}
+	if err := ds.loadSoftwareInstallerVersionsLabels(ctx, []*mdmlab.SoftwareInstallerVersion{&version}); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (ds *Datastore) loadSoftwareInstallerVersionsLabels(ctx context.Context, versions []*mdmlab.SoftwareInstallerVersion) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	const stmt = `
+		SELECT
+			sivl.software_installer_version_id AS version_id,
+			l.id AS label_id,
+			l.name AS label_name
+		FROM software_installer_version_labels sivl
+		JOIN labels l ON l.id = sivl.label_id
+		WHERE sivl.software_installer_version_id IN (?)
+		ORDER BY l.name`
+
+	byID := make(map[uint]*mdmlab.SoftwareInstallerVersion, len(versions))
+	ids := make([]uint, 0, len(versions))
+	for _, v := range versions {
+		v.LabelsIncludeAny = []mdmlab.SoftwareScopeLabel{}
+		byID[v.ID] = v
+		ids = append(ids, v.ID)
+	}
+
+	query, args, err := sqlx.In(stmt, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build software installer version labels query")
+	}
+	var rows []struct {
+		mdmlab.SoftwareScopeLabel
+		VersionID uint `db:"version_id"`
+	}
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rows, query, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "load software installer version labels")
+	}
+	for _, row := range rows {
+		v := byID[row.VersionID]
+		v.LabelsIncludeAny = append(v.LabelsIncludeAny, row.SoftwareScopeLabel)
+	}
+	return nil
+}
+
+func (ds *Datastore) PinSoftwareInstallerVersion(ctx context.Context, installerID uint, versionID *uint) error {
+	if versionID != nil {
+		if _, err := ds.getSoftwareInstallerVersion(ctx, ds.writer(ctx), installerID, *versionID); err != nil {
+			return err
+		}
+	}
+
+	res, err := ds.writer(ctx).ExecContext(ctx, `UPDATE software_installers SET pinned_version_id = ? WHERE id = ?`, versionID, installerID)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "pin software installer version")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// the pin may be unchanged, make sure the installer exists
+		var exists bool
+		if err := sqlx.GetContext(ctx, ds.writer(ctx), &exists, `SELECT 1 FROM software_installers WHERE id = ?`, installerID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ctxerr.Wrap(ctx, notFound("SoftwareInstaller").WithID(installerID))
+			}
+			return ctxerr.Wrap(ctx, err, "check software installer exists")
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) RollbackSoftwareInstallerVersion(ctx context.Context, installerID, versionID uint) error {
+	const (
+		checkModifiedStmt = `
+			SELECT
+				si.storage_id != siv.storage_id AS is_package_modified,
+				NOT (` + softwareInstallerVersionScriptsMatch + `) AS is_metadata_modified
+			FROM software_installers si
+			JOIN software_installer_versions siv ON siv.software_installer_id = si.id
+			WHERE si.id = ? AND siv.id = ?`
+
+		rollbackStmt = `
+			UPDATE software_installers si
+			JOIN software_installer_versions siv ON siv.software_installer_id = si.id
+			SET
+				si.version = siv.version,
+				si.filename = siv.filename,
+				si.extension = siv.extension,
+				si.storage_id = siv.storage_id,
+				si.package_ids = siv.package_ids,
+				si.url = siv.url,
+				si.pre_install_query = siv.pre_install_query,
+				si.install_script_content_id = siv.install_script_content_id,
+				si.post_install_script_content_id = siv.post_install_script_content_id,
+				si.uninstall_script_content_id = siv.uninstall_script_content_id,
+				si.uploaded_at = siv.uploaded_at,
+				si.signature_status = siv.signature_status,
+				si.signer = siv.signer,
+				si.signer_team_id = siv.signer_team_id,
+				si.has_stapled_ticket = siv.has_stapled_ticket,
+				si.pinned_version_id = NULL
+			WHERE si.id = ? AND siv.id = ?`
+	)
+
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		var modified struct {
+			IsPackageModified  bool `db:"is_package_modified"`
+			IsMetadataModified bool `db:"is_metadata_modified"`
+		}
+		if err := sqlx.GetContext(ctx, tx, &modified, checkModifiedStmt, installerID, versionID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ctxerr.Wrap(ctx, notFound("SoftwareInstallerVersion").WithID(versionID))
+			}
+			return ctxerr.Wrap(ctx, err, "check software installer version changes")
+		}
+
+		if _, err := tx.ExecContext(ctx, rollbackStmt, installerID, versionID); err != nil {
+			return ctxerr.Wrap(ctx, err, "rollback software installer version")
+		}
+
+		return ds.runInstallerUpdateSideEffectsInTransaction(ctx, tx, installerID, modified.IsMetadataModified, modified.IsPackageModified)
+	})
+}
+
+func (ds *Datastore) SetSoftwareInstallerVersionLabels(ctx context.Context, installerID, versionID uint, labelIDs []uint) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if _, err := ds.getSoftwareInstallerVersion(ctx, tx, installerID, versionID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM software_installer_version_labels WHERE software_installer_version_id = ?`, versionID); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete software installer version labels")
+		}
+		if len(labelIDs) == 0 {
+			return nil
+		}
+
+		args := make([]any, 0, 2*len(labelIDs))
+		for _, labelID := range labelIDs {
+			args = append(args, versionID, labelID)
+		}
+		stmt := fmt.Sprintf(
+			`INSERT INTO software_installer_version_labels (software_installer_version_id, label_id) VALUES %s`,
+			strings.TrimSuffix(strings.Repeat("(?,?),", len(labelIDs)), ","),
+		)
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert software installer version labels")
+		}
+		return nil
+	})
+}
+
+var errDeleteCurrentSoftwareInstallerVersion = &mdmlab.ConflictError{Message: "Couldn't delete. This is the current version of the software. Please roll back to another version and try again."}
+
+func (ds *Datastore) DeleteSoftwareInstallerVersion(ctx context.Context, installerID, versionID uint) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		version, err := ds.getSoftwareInstallerVersion(ctx, tx, installerID, versionID)
+		if err != nil {
+			return err
+		}
+		if version.Current {
+			return ctxerr.Wrap(ctx, errDeleteCurrentSoftwareInstallerVersion, "delete software installer version")
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE software_installers SET pinned_version_id = NULL WHERE id = ? AND pinned_version_id = ?`, installerID, versionID); err != nil {
+			return ctxerr.Wrap(ctx, err, "unpin deleted software installer version")
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM software_installer_versions WHERE id = ?`, versionID); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete software installer version")
+		}
+		return nil
+	})
+}
+
+// getSoftwareInstallerVersionForHost returns the version of the installer to
+// install on the host: the latest version targeted to a label of the host,
+// else the pinned version. It returns nil if the host gets the current
+// version.
+func (ds *Datastore) getSoftwareInstallerVersionForHost(ctx context.Context, q sqlx.QueryerContext, installerID, hostID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+	const (
+		labelStmt = `
+			SELECT siv.id, siv.software_installer_id, siv.version, siv.filename, siv.storage_id
+			FROM software_installer_versions siv
+			WHERE
+				siv.software_installer_id = ? AND
+				EXISTS (
+					SELECT 1 FROM software_installer_version_labels sivl
+					JOIN label_membership lm ON lm.label_id = sivl.label_id AND lm.host_id = ?
+					WHERE sivl.software_installer_version_id = siv.id
+				)
+			ORDER BY siv.id DESC
+			LIMIT 1`
+
+		pinnedStmt = `
+			SELECT siv.id, siv.software_installer_id, siv.version, siv.filename, siv.storage_id
+			FROM software_installers si
+			JOIN software_installer_versions siv ON siv.id = si.pinned_version_id AND siv.software_installer_id = si.id
+			WHERE si.id = ?`
+	)
+
+	var version mdmlab.SoftwareInstallerVersion
+	err := sqlx.GetContext(ctx, q, &version, labelStmt, installerID, hostID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = sqlx.GetContext(ctx, q, &version, pinnedStmt, installerID)
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, ctxerr.Wrap(ctx, err, "get software installer version for host")
+	}
+	return &version, nil
+}
+
+func (ds *Datastore) GetPendingSoftwareInstallerVersion(ctx context.Context, hostID, installerID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+	// the pending installs are executed in the order they were requested
+	const stmt = `
+		SELECT siv.id, siv.software_installer_id, siv.version, siv.filename, siv.storage_id
+		FROM host_software_installs hsi
+		JOIN software_installer_versions siv ON siv.id = hsi.software_installer_version_id
+		WHERE
+			hsi.host_id = ? AND
+			hsi.software_installer_id = ? AND
+			hsi.status = ? AND
+			hsi.id = (
+				SELECT MIN(id) FROM host_software_installs
+				WHERE host_id = hsi.host_id AND software_installer_id = hsi.software_installer_id AND status = ?
+			)`
+
+	var version mdmlab.SoftwareInstallerVersion
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &version, stmt, hostID, installerID, mdmlab.SoftwareInstallPending, mdmlab.SoftwareInstallPending); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get pending software installer version")
+	}
+	return &version, nil
+}
@@ -0,0 +1,249 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareInstallerVersions(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"History", testSoftwareInstallerVersionsHistory},
+		{"HostVersion", testSoftwareInstallerVersionsHostVersion},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func uploadSoftwareInstallerVersion(t *testing.T, ds *Datastore, installerID, titleID, teamID, userID uint, version, storageID, installScript string) {
+	ctx := context.Background()
+
+	tfr, err := mdmlab.NewTempFileReader(bytes.NewReader([]byte(version)), t.TempDir)
+	require.NoError(t, err)
+	err = ds.SaveInstallerUpdates(ctx, &mdmlab.UpdateSoftwareInstallerPayload{
+		TitleID:         titleID,
+		TeamID:          &teamID,
+		InstallerID:     installerID,
+		UserID:          userID,
+		InstallerFile:   tfr,
+		InstallScript:   ptr.String(installScript),
+		PreInstallQuery: ptr.String(""),
+		UninstallScript: ptr.String(""),
+		SelfService:     ptr.Bool(false),
+		StorageID:       storageID,
+		Filename:        "installer-" + version + ".pkg",
+		Version:         version,
+	})
+	require.NoError(t, err)
+}
+
+func testSoftwareInstallerVersionsHistory(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	installerID := createRolloutSoftwareInstaller(t, ds, team.ID, user.ID)
+	meta, err := ds.GetSoftwareInstallerMetadataByID(ctx, installerID)
+	require.NoError(t, err)
+
+	assertVersions := func(want ...string) []*mdmlab.SoftwareInstallerVersion {
+		versions, err := ds.ListSoftwareInstallerVersions(ctx, installerID)
+		require.NoError(t, err)
+		var got []string
+		for _, v := range versions {
+			got = append(got, v.Version)
+		}
+		require.Equal(t, want, got)
+		return versions
+	}
+
+	// the uploaded package is the first version
+	versions := assertVersions("1.0")
+	require.True(t, versions[0].Current)
+	require.False(t, versions[0].Pinned)
+	require.Equal(t, "installer.pkg", versions[0].Filename)
+	require.Equal(t, "Alice", versions[0].UserName)
+	require.Equal(t, "alice@example.com", versions[0].UserEmail)
+	v1 := versions[0]
+
+	// a new package adds a version
+	uploadSoftwareInstallerVersion(t, ds, installerID, *meta.TitleID, team.ID, user.ID, "2.0", "storage2", "install")
+	versions = assertVersions("2.0", "1.0")
+	require.True(t, versions[0].Current)
+	require.False(t, versions[1].Current)
+	v2 := versions[0]
+
+	// saving the same package and scripts again does not add a version
+	uploadSoftwareInstallerVersion(t, ds, installerID, *meta.TitleID, team.ID, user.ID, "2.0", "storage2", "install")
+	assertVersions("2.0", "1.0")
+
+	// but changing the scripts does
+	uploadSoftwareInstallerVersion(t, ds, installerID, *meta.TitleID, team.ID, user.ID, "2.0", "storage2", "install v2")
+	versions = assertVersions("2.0", "2.0", "1.0")
+	require.True(t, versions[0].Current)
+	require.False(t, versions[1].Current)
+	v2b := versions[0]
+
+	// pin a version
+	require.NoError(t, ds.PinSoftwareInstallerVersion(ctx, installerID, &v1.ID))
+	got, err := ds.GetSoftwareInstallerVersion(ctx, installerID, v1.ID)
+	require.NoError(t, err)
+	require.True(t, got.Pinned)
+	require.False(t, got.Current)
+
+	// the pin is kept when a new package is uploaded
+	uploadSoftwareInstallerVersion(t, ds, installerID, *meta.TitleID, team.ID, user.ID, "3.0", "storage3", "install")
+	versions = assertVersions("3.0", "2.0", "2.0", "1.0")
+	require.True(t, versions[3].Pinned)
+
+	// unpin it
+	require.NoError(t, ds.PinSoftwareInstallerVersion(ctx, installerID, nil))
+	got, err = ds.GetSoftwareInstallerVersion(ctx, installerID, v1.ID)
+	require.NoError(t, err)
+	require.False(t, got.Pinned)
+
+	// versions of other installers cannot be pinned
+	otherTeam, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team2"})
+	require.NoError(t, err)
+	otherInstallerID := createRolloutSoftwareInstaller(t, ds, otherTeam.ID, user.ID)
+	err = ds.PinSoftwareInstallerVersion(ctx, otherInstallerID, &v1.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+	_, err = ds.GetSoftwareInstallerVersion(ctx, otherInstallerID, v1.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// roll back to a previous version, it clears the pin
+	require.NoError(t, ds.PinSoftwareInstallerVersion(ctx, installerID, &v1.ID))
+	require.NoError(t, ds.RollbackSoftwareInstallerVersion(ctx, installerID, v2b.ID))
+	meta, err = ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, &team.ID, *meta.TitleID, true)
+	require.NoError(t, err)
+	require.Equal(t, "2.0", meta.Version)
+	require.Equal(t, "storage2", meta.StorageID)
+	require.Equal(t, "installer-2.0.pkg", meta.Name)
+	require.Equal(t, "install v2", meta.InstallScript)
+	versions = assertVersions("3.0", "2.0", "2.0", "1.0")
+	require.False(t, versions[0].Current)
+	require.True(t, versions[1].Current)
+	require.Equal(t, v2b.ID, versions[1].ID)
+	for _, v := range versions {
+		require.False(t, v.Pinned)
+	}
+
+	// the current version cannot be deleted
+	err = ds.DeleteSoftwareInstallerVersion(ctx, installerID, v2b.ID)
+	var conflictErr *mdmlab.ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+
+	// deleting a pinned version unpins it
+	require.NoError(t, ds.PinSoftwareInstallerVersion(ctx, installerID, &v2.ID))
+	require.NoError(t, ds.DeleteSoftwareInstallerVersion(ctx, installerID, v2.ID))
+	versions = assertVersions("3.0", "2.0", "1.0")
+	for _, v := range versions {
+		require.False(t, v.Pinned)
+	}
+	err = ds.DeleteSoftwareInstallerVersion(ctx, installerID, v2.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// the versions are deleted with the installer
+	require.NoError(t, ds.DeleteSoftwareInstaller(ctx, installerID))
+	versions, err = ds.ListSoftwareInstallerVersions(ctx, installerID)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func testSoftwareInstallerVersionsHostVersion(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	installerID := createRolloutSoftwareInstaller(t, ds, team.ID, user.ID)
+	meta, err := ds.GetSoftwareInstallerMetadataByID(ctx, installerID)
+	require.NoError(t, err)
+	uploadSoftwareInstallerVersion(t, ds, installerID, *meta.TitleID, team.ID, user.ID, "2.0", "storage2", "install v2")
+	uploadSoftwareInstallerVersion(t, ds, installerID, *meta.TitleID, team.ID, user.ID, "3.0", "storage3", "install v3")
+
+	versions, err := ds.ListSoftwareInstallerVersions(ctx, installerID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	v3, v2, v1 := versions[0], versions[1], versions[2]
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now(), test.WithTeamID(team.ID))
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now(), test.WithTeamID(team.ID))
+	label, err := ds.NewLabel(ctx, &mdmlab.Label{Name: "beta", Query: "SELECT 1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddLabelsToHost(ctx, host1.ID, []uint{label.ID}))
+
+	// requests the install on the host, checks the version it gets and
+	// completes the install
+	assertHostVersion := func(hostID uint, want *mdmlab.SoftwareInstallerVersion, wantScript string) {
+		installUUID, err := ds.InsertSoftwareInstallRequest(ctx, hostID, installerID, false, nil)
+		require.NoError(t, err)
+
+		got, err := ds.GetPendingSoftwareInstallerVersion(ctx, hostID, installerID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		require.Equal(t, want.ID, got.ID)
+		require.Equal(t, want.StorageID, got.StorageID)
+
+		details, err := ds.GetSoftwareInstallDetails(ctx, installUUID)
+		require.NoError(t, err)
+		require.Equal(t, wantScript, details.InstallScript)
+
+		require.NoError(t, ds.SetHostSoftwareInstallResult(ctx, &mdmlab.HostSoftwareInstallResultPayload{
+			HostID:                hostID,
+			InstallUUID:           installUUID,
+			InstallScriptExitCode: ptr.Int(0),
+		}))
+		got, err = ds.GetPendingSoftwareInstallerVersion(ctx, hostID, installerID)
+		require.NoError(t, err)
+		require.Nil(t, got)
+	}
+
+	// no pin nor label, the hosts get the current version
+	assertHostVersion(host1.ID, v3, "install v3")
+	assertHostVersion(host2.ID, v3, "install v3")
+
+	// the pinned version is installed instead of the current one
+	require.NoError(t, ds.PinSoftwareInstallerVersion(ctx, installerID, &v1.ID))
+	assertHostVersion(host1.ID, v1, "install")
+	assertHostVersion(host2.ID, v1, "install")
+
+	// the label members get the version targeted to the label
+	require.NoError(t, ds.SetSoftwareInstallerVersionLabels(ctx, installerID, v2.ID, []uint{label.ID}))
+	got, err := ds.GetSoftwareInstallerVersion(ctx, installerID, v2.ID)
+	require.NoError(t, err)
+	require.Len(t, got.LabelsIncludeAny, 1)
+	require.Equal(t, label.ID, got.LabelsIncludeAny[0].LabelID)
+	require.Equal(t, "beta", got.LabelsIncludeAny[0].LabelName)
+	assertHostVersion(host1.ID, v2, "install v2")
+	assertHostVersion(host2.ID, v1, "install")
+
+	// the latest targeted version wins
+	require.NoError(t, ds.SetSoftwareInstallerVersionLabels(ctx, installerID, v3.ID, []uint{label.ID}))
+	assertHostVersion(host1.ID, v3, "install v3")
+
+	// clearing the labels
+	require.NoError(t, ds.SetSoftwareInstallerVersionLabels(ctx, installerID, v3.ID, nil))
+	require.NoError(t, ds.SetSoftwareInstallerVersionLabels(ctx, installerID, v2.ID, nil))
+	got, err = ds.GetSoftwareInstallerVersion(ctx, installerID, v2.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.LabelsIncludeAny)
+	assertHostVersion(host1.ID, v1, "install")
+}
@@ -46,7 +46,8 @@ func (ds *Datastore) GetSoftwareInstallDetails(ctx context.Context, executionId
     hsi.execution_id AS execution_id,
     hsi.software_installer_id AS installer_id,
     hsi.self_service AS self_service,
-    COALESCE(si.pre_install_query, '') AS pre_install_condition,
+    COALESCE(siv.storage_id, si.storage_id) AS installer_sha256,
+    COALESCE(IF(siv.id IS NULL, si.pre_install_query, siv.pre_install_query), '') AS pre_install_condition,
     inst.contents AS install_script,
     uninst.contents AS uninstall_script,
     COALESCE(pisnt.contents, '') AS post_install_script
@@ -55,15 +56,18 @@ func (ds *Datastore) GetSoftwareInstallDetails(ctx context.Context, executionId
   INNER JOIN
     software_installers si
     ON hsi.software_installer_id = si.id
+  LEFT OUTER JOIN
+    software_installer_versions siv
+    ON siv.id = hsi.software_installer_version_id
   LEFT OUTER JOIN
     script_contents inst
-    ON inst.id = si.install_script_content_id
+    ON inst.id = COALESCE(siv.install_script_content_id, si.install_script_content_id)
   LEFT OUTER JOIN
     script_contents uninst
-    ON uninst.id = si.uninstall_script_content_id
+    ON uninst.id = COALESCE(siv.uninstall_script_content_id, si.uninstall_script_content_id)
   LEFT OUTER JOIN
     script_contents pisnt
-    ON pisnt.id = si.post_install_script_content_id
+    ON pisnt.id = IF(siv.id IS NULL, si.post_install_script_content_id, siv.post_install_script_content_id)
   WHERE
     hsi.execution_id = ?`
 
@@ -160,8 +164,12 @@ INSERT INTO software_installers (
 	user_id,
 	user_name,
 	user_email,
-	mdmlab_library_app_id
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT name FROM users WHERE id = ?), (SELECT email FROM users WHERE id = ?), ?)`
+	mdmlab_library_app_id,
+	signature_status,
+	signer,
+	signer_team_id,
+	has_stapled_ticket
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT name FROM users WHERE id = ?), (SELECT email FROM users WHERE id = ?), ?, ?, ?, ?, ?)`
 
 		args := []interface{}{
 			tid,
@@ -182,6 +190,10 @@ INSERT INTO software_installers (
 			payload.UserID,
 			payload.UserID,
 			payload.MDMlabLibraryAppID,
+			payload.Signature.SignatureStatus,
+			payload.Signature.Signer,
+			payload.Signature.SignerTeamID,
+			payload.Signature.HasStapledTicket,
 		}
 
 		res, err := tx.ExecContext(ctx, stmt, args...)
@@ -200,6 +212,10 @@ INSERT INTO software_installers (
 			return ctxerr.Wrap(ctx, err, "upsert software installer labels")
 		}
 
+		if err := recordSoftwareInstallerVersionDB(ctx, tx, installerID); err != nil {
+			return err
+		}
+
 		if payload.AutomaticInstall {
 			if err := ds.createAutomaticPolicy(ctx, tx, payload, installerID); err != nil {
 				return ctxerr.Wrap(ctx, err, "create automatic policy")
@@ -215,14 +231,25 @@ INSERT INTO software_installers (
 }
 
 func (ds *Datastore) createAutomaticPolicy(ctx context.Context, tx sqlx.ExtContext, payload *mdmlab.UploadSoftwareInstallerPayload, softwareInstallerID uint) error {
-	generatedPolicyData, err := automatic_policy.Generate(automatic_policy.InstallerMetadata{
-		Title:            payload.Title,
-		Extension:        payload.Extension,
-		BundleIdentifier: payload.BundleIdentifier,
-		PackageIDs:       payload.PackageIDs,
-	})
-	if err != nil {
-		return ctxerr.Wrap(ctx, err, "generate automatic policy query data")
+	var generatedPolicyData *automatic_policy.PolicyData
+	if payload.AutomaticInstallQuery != "" {
+		generatedPolicyData = &automatic_policy.PolicyData{
+			Name:        fmt.Sprintf("[Install software] %s (%s)", payload.Title, payload.Extension),
+			Query:       payload.AutomaticInstallQuery,
+			Platform:    payload.Platform,
+			Description: fmt.Sprintf("Policy triggers automatic install of %s on each host that's missing this software.", payload.Title),
+		}
+	} else {
+		var err error
+		generatedPolicyData, err = automatic_policy.Generate(automatic_policy.InstallerMetadata{
+			Title:            payload.Title,
+			Extension:        payload.Extension,
+			BundleIdentifier: payload.BundleIdentifier,
+			PackageIDs:       payload.PackageIDs,
+		})
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "generate automatic policy query data")
+		}
 	}
 	teamID := mdmlab.PolicyNoTeamID
 	if payload.TeamID != nil {
@@ -419,7 +446,11 @@ func (ds *Datastore) SaveInstallerUpdates(ctx context.Context, payload *mdmlab.U
     self_service = ?,
 	user_id = ?,
 	user_name = (SELECT name FROM users WHERE id = ?),
-	user_email = (SELECT email FROM users WHERE id = ?) %s
+	user_email = (SELECT email FROM users WHERE id = ?),
+	signature_status = ?,
+	signer = ?,
+	signer_team_id = ?,
+	has_stapled_ticket = ? %s
 	WHERE id = ?`, touchUploaded)
 
 		args := []interface{}{
@@ -435,6 +466,10 @@ func (ds *Datastore) SaveInstallerUpdates(ctx context.Context, payload *mdmlab.U
 			payload.UserID,
 			payload.UserID,
 			payload.UserID,
+			payload.Signature.SignatureStatus,
+			payload.Signature.Signer,
+			payload.Signature.SignerTeamID,
+			payload.Signature.HasStapledTicket,
 			payload.InstallerID,
 		}
 
@@ -448,7 +483,7 @@ func (ds *Datastore) SaveInstallerUpdates(ctx context.Context, payload *mdmlab.U
 			}
 		}
 
-		return nil
+		return recordSoftwareInstallerVersionDB(ctx, tx, payload.InstallerID)
 	})
 	if err != nil {
 		return ctxerr.Wrap(ctx, err, "update software installer")
@@ -498,7 +533,12 @@ SELECT
 	si.uploaded_at,
 	COALESCE(st.name, '') AS software_title,
 	si.platform,
-	si.mdmlab_library_app_id
+	si.mdmlab_library_app_id,
+	si.storage_id AS hash_sha256,
+	si.signature_status,
+	si.signer,
+	si.signer_team_id,
+	si.has_stapled_ticket
 FROM
 	software_installers si
 	LEFT OUTER JOIN software_titles st ON st.id = si.title_id
@@ -542,6 +582,11 @@ SELECT
   si.uninstall_script_content_id,
   si.uploaded_at,
   si.self_service,
+  si.storage_id AS hash_sha256,
+  si.signature_status,
+  si.signer,
+  si.signer_team_id,
+  si.has_stapled_ticket,
   COALESCE(st.name, '') AS software_title
   %s
 FROM
@@ -692,20 +737,26 @@ func (ds *Datastore) deletePendingSoftwareInstallsForPolicy(ctx context.Context,
 }
 
 func (ds *Datastore) InsertSoftwareInstallRequest(ctx context.Context, hostID uint, softwareInstallerID uint, selfService bool, policyID *uint) (string, error) {
+	return ds.insertSoftwareInstallRequestDB(ctx, ds.writer(ctx), hostID, softwareInstallerID, selfService, policyID)
+}
+
+func (ds *Datastore) insertSoftwareInstallRequestDB(ctx context.Context, tx sqlx.ExtContext, hostID uint, softwareInstallerID uint, selfService bool,
+	policyID *uint,
+) (string, error) {
 	const (
 		getInstallerStmt = `SELECT filename, "version", title_id, COALESCE(st.name, '[deleted title]') title_name
 			FROM software_installers si LEFT JOIN software_titles st ON si.title_id = st.id WHERE si.id = ?`
 		insertStmt = `
 		  INSERT INTO host_software_installs
-		    (execution_id, host_id, software_installer_id, user_id, self_service, policy_id, installer_filename, version, software_title_id, software_title_name)
-		  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		    (execution_id, host_id, software_installer_id, user_id, self_service, policy_id, installer_filename, version, software_title_id, software_title_name, software_installer_version_id)
+		  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		    `
 		hostExistsStmt = `SELECT 1 FROM hosts WHERE id = ?`
 	)
 
 	// we need to explicitly do this check here because we can't set a FK constraint on the schema
 	var hostExists bool
-	err := sqlx.GetContext(ctx, ds.reader(ctx), &hostExists, hostExistsStmt, hostID)
+	err := sqlx.GetContext(ctx, tx, &hostExists, hostExistsStmt, hostID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", notFound("Host").WithID(hostID)
@@ -720,7 +771,7 @@ func (ds *Datastore) InsertSoftwareInstallRequest(ctx context.Context, hostID ui
 		TitleID   *uint   `db:"title_id"`
 		TitleName *string `db:"title_name"`
 	}
-	if err = sqlx.GetContext(ctx, ds.reader(ctx), &installerDetails, getInstallerStmt, softwareInstallerID); err != nil {
+	if err = sqlx.GetContext(ctx, tx, &installerDetails, getInstallerStmt, softwareInstallerID); err != nil {
 		if err == sql.ErrNoRows {
 			return "", notFound("SoftwareInstaller").WithID(softwareInstallerID)
 		}
@@ -728,12 +779,24 @@ func (ds *Datastore) InsertSoftwareInstallRequest(ctx context.Context, hostID ui
 		return "", ctxerr.Wrap(ctx, err, "getting installer data")
 	}
 
+	// the host may get another version than the current one
+	var versionID *uint
+	version, err := ds.getSoftwareInstallerVersionForHost(ctx, tx, softwareInstallerID, hostID)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "getting installer version for host")
+	}
+	if version != nil {
+		versionID = &version.ID
+		installerDetails.Filename = version.Filename
+		installerDetails.Version = version.Version
+	}
+
 	var userID *uint
 	if ctxUser := authz.UserFromContext(ctx); ctxUser != nil {
 		userID = &ctxUser.ID
 	}
 	installID := uuid.NewString()
-	_, err = ds.writer(ctx).ExecContext(ctx, insertStmt,
+	_, err = tx.ExecContext(ctx, insertStmt,
 		installID,
 		hostID,
 		softwareInstallerID,
@@ -744,6 +807,7 @@ func (ds *Datastore) InsertSoftwareInstallRequest(ctx context.Context, hostID ui
 		installerDetails.Version,
 		installerDetails.TitleID,
 		installerDetails.TitleName,
+		versionID,
 	)
 
 	return installID, ctxerr.Wrap(ctx, err, "inserting new install software request")
@@ -1053,7 +1117,8 @@ func (ds *Datastore) CleanupUnusedSoftwareInstallers(ctx context.Context, softwa
 
 	// get the list of software installers hashes that are in use
 	var storageIDs []string
-	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &storageIDs, `SELECT DISTINCT storage_id FROM software_installers`); err != nil {
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &storageIDs,
+		`SELECT storage_id FROM software_installers UNION SELECT storage_id FROM software_installer_versions`); err != nil {
 		return ctxerr.Wrap(ctx, err, "get list of software installers in use")
 	}
 
@@ -1206,11 +1271,15 @@ INSERT INTO software_installers (
 	user_email,
 	url,
 	package_ids,
+	signature_status,
+	signer,
+	signer_team_id,
+	has_stapled_ticket,
 	install_during_setup
 ) VALUES (
   ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
   (SELECT id FROM software_titles WHERE name = ? AND source = ? AND browser = ''),
-  ?, (SELECT name FROM users WHERE id = ?), (SELECT email FROM users WHERE id = ?), ?, ?, COALESCE(?, false)
+  ?, (SELECT name FROM users WHERE id = ?), (SELECT email FROM users WHERE id = ?), ?, ?, ?, ?, ?, ?, COALESCE(?, false)
 )
 ON DUPLICATE KEY UPDATE
   install_script_content_id = VALUES(install_script_content_id),
@@ -1227,6 +1296,10 @@ ON DUPLICATE KEY UPDATE
   user_name = VALUES(user_name),
   user_email = VALUES(user_email),
   url = VALUES(url),
+  signature_status = VALUES(signature_status),
+  signer = VALUES(signer),
+  signer_team_id = VALUES(signer_team_id),
+  has_stapled_ticket = VALUES(has_stapled_ticket),
   install_during_setup = COALESCE(?, install_during_setup)
 `
 
@@ -1472,6 +1545,10 @@ WHERE
 				installer.UserID,
 				installer.URL,
 				strings.Join(installer.PackageIDs, ","),
+				installer.Signature.SignatureStatus,
+				installer.Signature.Signer,
+				installer.Signature.SignerTeamID,
+				installer.Signature.HasStapledTicket,
 				installer.InstallDuringSetup,
 				installer.InstallDuringSetup,
 			}
@@ -1492,6 +1569,10 @@ WHERE
 				return ctxerr.Wrapf(ctx, err, "load id of new/edited installer with name %q", installer.Filename)
 			}
 
+			if err := recordSoftwareInstallerVersionDB(ctx, tx, installerID); err != nil {
+				return ctxerr.Wrapf(ctx, err, "record version of installer with name %q", installer.Filename)
+			}
+
 			// process the labels associated with that software installer
 			if len(installer.ValidatedLabels.ByName) == 0 {
 				// no label to apply, so just delete all existing labels if any
@@ -40,6 +40,7 @@ func TestSoftwareInstallers(t *testing.T) {
 		{"GetOrGenerateSoftwareInstallerTitleID", testGetOrGenerateSoftwareInstallerTitleID},
 		{"BatchSetSoftwareInstallersScopedViaLabels", testBatchSetSoftwareInstallersScopedViaLabels},
 		{"MatchOrCreateSoftwareInstallerWithAutomaticPolicies", testMatchOrCreateSoftwareInstallerWithAutomaticPolicies},
+		{"SoftwareInstallerSignature", testSoftwareInstallerSignature},
 	}
 
 	for _, c := range cases {
@@ -2023,3 +2024,103 @@ Software won't be installed on Linux hosts with Debian-based distributions becau
 	require.Len(t, team3Policies, 3)
 	require.Equal(t, "[Install software] Something2 (msi) 3", team3Policies[2].Name)
 }
+
+func testSoftwareInstallerSignature(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "host1", "1", "host1key", "host1uuid", time.Now())
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	tfr, err := mdmlab.NewTempFileReader(strings.NewReader("hello"), t.TempDir)
+	require.NoError(t, err)
+
+	developerID := mdmlab.SoftwareInstallerSignature{
+		SignatureStatus: mdmlab.SoftwareInstallerSignatureValid,
+		Signer:          "Developer ID Installer: Foo Inc (ABCDE12345)",
+		SignerTeamID:    "ABCDE12345",
+		HasStapledTicket:       true,
+	}
+	installerID, titleID, err := ds.MatchOrCreateSoftwareInstaller(ctx, &mdmlab.UploadSoftwareInstallerPayload{
+		InstallerFile:    tfr,
+		InstallScript:    "install",
+		UninstallScript:  "uninstall",
+		BundleIdentifier: "com.foo",
+		Extension:        "pkg",
+		StorageID:        "sha256-v1",
+		Filename:         "foo.pkg",
+		Title:            "Foo",
+		Version:          "1.0",
+		Source:           "apps",
+		UserID:           user.ID,
+		ValidatedLabels:  &mdmlab.LabelIdentsWithScope{},
+		Signature:        developerID,
+	})
+	require.NoError(t, err)
+
+	installer, err := ds.GetSoftwareInstallerMetadataByID(ctx, installerID)
+	require.NoError(t, err)
+	require.Equal(t, developerID, installer.SoftwareInstallerSignature)
+	require.Equal(t, "sha256-v1", installer.HashSHA256)
+
+	installer, err = ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, nil, titleID, false)
+	require.NoError(t, err)
+	require.Equal(t, developerID, installer.SoftwareInstallerSignature)
+	require.Equal(t, "sha256-v1", installer.HashSHA256)
+
+	// the install details have the hash of the package to verify
+	execID, err := ds.InsertSoftwareInstallRequest(ctx, host.ID, installerID, false, nil)
+	require.NoError(t, err)
+	details, err := ds.GetSoftwareInstallDetails(ctx, execID)
+	require.NoError(t, err)
+	require.Equal(t, "sha256-v1", details.InstallerSHA256)
+
+	// upload an unsigned package
+	unsigned := mdmlab.SoftwareInstallerSignature{SignatureStatus: mdmlab.SoftwareInstallerSignatureUnsigned}
+	err = ds.SaveInstallerUpdates(ctx, &mdmlab.UpdateSoftwareInstallerPayload{
+		InstallerID:       installerID,
+		TitleID:           titleID,
+		InstallerFile:     tfr,
+		InstallScript:     ptr.String("install"),
+		UninstallScript:   ptr.String("uninstall"),
+		PreInstallQuery:   ptr.String(""),
+		PostInstallScript: ptr.String(""),
+		SelfService:       ptr.Bool(false),
+		StorageID:         "sha256-v2",
+		Filename:          "foo.pkg",
+		Version:           "2.0",
+		PackageIDs:        []string{"com.foo"},
+		UserID:            user.ID,
+		Signature:         unsigned,
+	})
+	require.NoError(t, err)
+
+	installer, err = ds.GetSoftwareInstallerMetadataByID(ctx, installerID)
+	require.NoError(t, err)
+	require.Equal(t, unsigned, installer.SoftwareInstallerSignature)
+	require.Equal(t, "sha256-v2", installer.HashSHA256)
+
+	// each version keeps the signature of its package
+	versions, err := ds.ListSoftwareInstallerVersions(ctx, installerID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	signatures := map[string]mdmlab.SoftwareInstallerSignature{}
+	var v1ID uint
+	for _, v := range versions {
+		signatures[v.Version] = v.SoftwareInstallerSignature
+		if v.Version == "1.0" {
+			v1ID = v.ID
+		}
+	}
+	require.Equal(t, map[string]mdmlab.SoftwareInstallerSignature{"1.0": developerID, "2.0": unsigned}, signatures)
+
+	// the pending install keeps the hash of the version it was requested for
+	details, err = ds.GetSoftwareInstallDetails(ctx, execID)
+	require.NoError(t, err)
+	require.Equal(t, "sha256-v1", details.InstallerSHA256)
+
+	// rolling back restores the signature of the version
+	require.NoError(t, ds.RollbackSoftwareInstallerVersion(ctx, installerID, v1ID))
+	installer, err = ds.GetSoftwareInstallerMetadataByID(ctx, installerID)
+	require.NoError(t, err)
+	require.Equal(t, developerID, installer.SoftwareInstallerSignature)
+	require.Equal(t, "sha256-v1", installer.HashSHA256)
+}
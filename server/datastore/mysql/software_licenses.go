@@ -0,0 +1,249 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+const selectSoftwareLicenseStmt = `
+	SELECT
+		stl.id,
+		stl.team_id,
+		t.name AS team_name,
+		stl.title_id,
+		st.name AS software_title_name,
+		stl.vendor,
+		stl.seat_count,
+		stl.expires_at,
+		stl.cost,
+		stl.unused_after_days,
+		stl.created_at,
+		stl.updated_at
+	FROM software_title_licenses stl
+	JOIN software_titles st ON st.id = stl.title_id
+	LEFT JOIN teams t ON t.id = stl.team_id`
+
+func (ds *Datastore) NewSoftwareLicense(ctx context.Context, license *mdmlab.SoftwareLicense) (*mdmlab.SoftwareLicense, error) {
+	const stmt = `
+		INSERT INTO software_title_licenses (
+			team_id,
+			global_or_team_id,
+			title_id,
+			vendor,
+			seat_count,
+			expires_at,
+			cost,
+			unused_after_days
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var globalOrTeamID uint
+	if license.TeamID != nil {
+		globalOrTeamID = *license.TeamID
+	}
+	// a nil team ID is the team 0 ("no team"), store it as NULL.
+	teamID := license.TeamID
+	if globalOrTeamID == 0 {
+		teamID = nil
+	}
+
+	res, err := ds.writer(ctx).ExecContext(ctx, stmt,
+		teamID,
+		globalOrTeamID,
+		license.SoftwareTitleID,
+		license.Vendor,
+		license.SeatCount,
+		license.ExpiresAt,
+		license.Cost,
+		license.UnusedAfterDays,
+	)
+	if err != nil {
+		if IsDuplicate(err) {
+			return nil, ctxerr.Wrap(ctx, alreadyExists("SoftwareLicense", license.SoftwareTitleID))
+		}
+		if isChildForeignKeyError(err) {
+			return nil, ctxerr.Wrap(ctx, notFound("SoftwareTitle").WithID(license.SoftwareTitleID))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "insert software license")
+	}
+	id, _ := res.LastInsertId()
+	return ds.getSoftwareLicense(ctx, ds.writer(ctx), uint(id)) //nolint:gosec // dismiss G115
+}
+
+func (ds *Datastore) UpdateSoftwareLicense(ctx context.Context, license *mdmlab.SoftwareLicense) (*mdmlab.SoftwareLicense, error) {
+	const stmt = `
+		UPDATE software_title_licenses SET
+			vendor = ?,
+			seat_count = ?,
+			expires_at = ?,
+			cost = ?,
+			unused_after_days = ?
+		WHERE id = ?`
+
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt,
+		license.Vendor,
+		license.SeatCount,
+		license.ExpiresAt,
+		license.Cost,
+		license.UnusedAfterDays,
+		license.ID,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "update software license")
+	}
+	// the license may be unchanged, so its existence is checked by getting
+	// it instead of relying on the number of affected rows.
+	return ds.getSoftwareLicense(ctx, ds.writer(ctx), license.ID)
+}
+
+func (ds *Datastore) GetSoftwareLicense(ctx context.Context, id uint) (*mdmlab.SoftwareLicense, error) {
+	return ds.getSoftwareLicense(ctx, ds.reader(ctx), id)
+}
+
+func (ds *Datastore) getSoftwareLicense(ctx context.Context, q sqlx.QueryerContext, id uint) (*mdmlab.SoftwareLicense, error) {
+	var license mdmlab.SoftwareLicense
+	if err := sqlx.GetContext(ctx, q, &license, selectSoftwareLicenseStmt+` WHERE stl.id = ?`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("SoftwareLicense").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get software license")
+	}
+	if err := loadSoftwareLicensesUsage(ctx, q, []*mdmlab.SoftwareLicense{&license}); err != nil {
+		return nil, err
+	}
+	return &license, nil
+}
+
+func (ds *Datastore) ListSoftwareLicenses(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error) {
+	var globalOrTeamID uint
+	if teamID != nil {
+		globalOrTeamID = *teamID
+	}
+
+	var licenses []*mdmlab.SoftwareLicense
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &licenses,
+		selectSoftwareLicenseStmt+` WHERE stl.global_or_team_id = ? ORDER BY st.name, stl.id`, globalOrTeamID,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software licenses")
+	}
+	if err := loadSoftwareLicensesUsage(ctx, ds.reader(ctx), licenses); err != nil {
+		return nil, err
+	}
+	return licenses, nil
+}
+
+func (ds *Datastore) ListAllSoftwareLicenses(ctx context.Context) ([]*mdmlab.SoftwareLicense, error) {
+	var licenses []*mdmlab.SoftwareLicense
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &licenses,
+		selectSoftwareLicenseStmt+` ORDER BY stl.global_or_team_id, st.name, stl.id`,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list all software licenses")
+	}
+	if err := loadSoftwareLicensesUsage(ctx, ds.reader(ctx), licenses); err != nil {
+		return nil, err
+	}
+	return licenses, nil
+}
+
+// loadSoftwareLicensesUsage sets the usage of the licenses, the installs are
+// the hosts of the team of the license with any version of the title. A host
+// with several versions counts once, with the most recent last opened time.
+func loadSoftwareLicensesUsage(ctx context.Context, q sqlx.QueryerContext, licenses []*mdmlab.SoftwareLicense) error {
+	if len(licenses) == 0 {
+		return nil
+	}
+
+	const stmt = `
+		SELECT
+			license_id,
+			COUNT(*) AS installs,
+			COALESCE(SUM(last_opened_at >= unused_before), 0) AS used,
+			COALESCE(SUM(last_opened_at < unused_before), 0) AS unused,
+			COALESCE(SUM(last_opened_at IS NULL), 0) AS unknown
+		FROM (
+			SELECT
+				stl.id AS license_id,
+				DATE_SUB(?, INTERVAL stl.unused_after_days DAY) AS unused_before,
+				MAX(hs.last_opened_at) AS last_opened_at
+			FROM software_title_licenses stl
+			JOIN software s ON s.title_id = stl.title_id
+			JOIN host_software hs ON hs.software_id = s.id
+			JOIN hosts h ON h.id = hs.host_id AND COALESCE(h.team_id, 0) = stl.global_or_team_id
+			WHERE stl.id IN (?)
+			GROUP BY stl.id, stl.unused_after_days, hs.host_id
+		) license_hosts
+		GROUP BY license_id`
+
+	now := time.Now().UTC()
+	byID := make(map[uint]*mdmlab.SoftwareLicense, len(licenses))
+	ids := make([]uint, 0, len(licenses))
+	for _, license := range licenses {
+		byID[license.ID] = license
+		ids = append(ids, license.ID)
+		// licenses without installs are not returned by the query.
+		license.SetUsage(mdmlab.SoftwareLicenseUsage{}, now)
+	}
+
+	query, args, err := sqlx.In(stmt, now, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build software licenses usage query")
+	}
+	var rows []struct {
+		LicenseID uint `db:"license_id"`
+		mdmlab.SoftwareLicenseUsage
+	}
+	if err := sqlx.SelectContext(ctx, q, &rows, query, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "get software licenses usage")
+	}
+	for _, row := range rows {
+		if license := byID[row.LicenseID]; license != nil {
+			license.SetUsage(row.SoftwareLicenseUsage, now)
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) DeleteSoftwareLicense(ctx context.Context, id uint) error {
+	res, err := ds.writer(ctx).ExecContext(ctx, `DELETE FROM software_title_licenses WHERE id = ?`, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software license")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("SoftwareLicense").WithID(id))
+	}
+	return nil
+}
+
+func (ds *Datastore) ListSoftwareLicenseHosts(ctx context.Context, license *mdmlab.SoftwareLicense) ([]*mdmlab.SoftwareLicenseHost, error) {
+	const stmt = `
+		SELECT
+			h.id AS host_id,
+			COALESCE(hdn.display_name, '') AS host_display_name,
+			MAX(hs.last_opened_at) AS last_opened_at
+		FROM software s
+		JOIN host_software hs ON hs.software_id = s.id
+		JOIN hosts h ON h.id = hs.host_id
+		LEFT JOIN host_display_names hdn ON hdn.host_id = h.id
+		WHERE s.title_id = ? AND COALESCE(h.team_id, 0) = ?
+		GROUP BY h.id, hdn.display_name
+		ORDER BY h.id`
+
+	var globalOrTeamID uint
+	if license.TeamID != nil {
+		globalOrTeamID = *license.TeamID
+	}
+
+	var hosts []*mdmlab.SoftwareLicenseHost
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &hosts, stmt, license.SoftwareTitleID, globalOrTeamID); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software license hosts")
+	}
+	now := time.Now().UTC()
+	for _, h := range hosts {
+		h.UsageStatus = license.UsageStatus(h.LastOpenedAt, now)
+	}
+	return hosts, nil
+}
@@ -0,0 +1,208 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareLicenses(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CRUD", testSoftwareLicensesCRUD},
+		{"Usage", testSoftwareLicensesUsage},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+// newSoftwareLicenseTitle adds the software to the host and returns the ID of
+// its title.
+func newSoftwareLicenseTitle(t *testing.T, ds *Datastore, hostID uint, software ...mdmlab.Software) uint {
+	ctx := context.Background()
+
+	_, err := ds.UpdateHostSoftware(ctx, hostID, software)
+	require.NoError(t, err)
+	require.NoError(t, ds.ReconcileSoftwareTitles(ctx))
+
+	var titleID uint
+	require.NoError(t, sqlx.GetContext(ctx, ds.reader(ctx), &titleID,
+		`SELECT id FROM software_titles WHERE name = ? AND source = ?`, software[0].Name, software[0].Source))
+	return titleID
+}
+
+func testSoftwareLicensesCRUD(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	host := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	titleID := newSoftwareLicenseTitle(t, ds, host.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps"})
+
+	_, err = ds.GetSoftwareLicense(ctx, 1)
+	require.True(t, mdmlab.IsNotFound(err))
+	require.True(t, mdmlab.IsNotFound(ds.DeleteSoftwareLicense(ctx, 1)))
+
+	expiresAt := time.Now().UTC().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	license, err := ds.NewSoftwareLicense(ctx, &mdmlab.SoftwareLicense{
+		TeamID:          &team.ID,
+		SoftwareTitleID: titleID,
+		Vendor:          "Figma, Inc.",
+		SeatCount:       10,
+		ExpiresAt:       &expiresAt,
+		Cost:            ptr.Float64(1234.5),
+		UnusedAfterDays: 30,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, license.ID)
+	require.Equal(t, team.ID, *license.TeamID)
+	require.Equal(t, team.Name, *license.TeamName)
+	require.Equal(t, "Figma.app", license.SoftwareTitle)
+	require.Equal(t, uint(10), license.SeatCount)
+	require.Equal(t, expiresAt, license.ExpiresAt.UTC())
+	require.Equal(t, 1234.5, *license.Cost)
+	require.NotNil(t, license.Usage)
+	require.Zero(t, license.Usage.Installs)
+	require.Equal(t, 10, license.Usage.AvailableSeats)
+
+	// a title has one license per team
+	_, err = ds.NewSoftwareLicense(ctx, &mdmlab.SoftwareLicense{TeamID: &team.ID, SoftwareTitleID: titleID, SeatCount: 5, UnusedAfterDays: 30})
+	var existsErr *existsError
+	require.ErrorAs(t, err, &existsErr)
+	_, err = ds.NewSoftwareLicense(ctx, &mdmlab.SoftwareLicense{SoftwareTitleID: titleID + 100, SeatCount: 5, UnusedAfterDays: 30})
+	require.True(t, mdmlab.IsNotFound(err))
+
+	noTeamLicense, err := ds.NewSoftwareLicense(ctx, &mdmlab.SoftwareLicense{TeamID: ptr.Uint(0), SoftwareTitleID: titleID, SeatCount: 5, UnusedAfterDays: 30})
+	require.NoError(t, err)
+	require.Nil(t, noTeamLicense.TeamID)
+	require.Nil(t, noTeamLicense.TeamName)
+	require.Nil(t, noTeamLicense.Cost)
+
+	licenses, err := ds.ListSoftwareLicenses(ctx, &team.ID)
+	require.NoError(t, err)
+	require.Len(t, licenses, 1)
+	require.Equal(t, license.ID, licenses[0].ID)
+	licenses, err = ds.ListSoftwareLicenses(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, licenses, 1)
+	require.Equal(t, noTeamLicense.ID, licenses[0].ID)
+	licenses, err = ds.ListAllSoftwareLicenses(ctx)
+	require.NoError(t, err)
+	require.Len(t, licenses, 2)
+	require.Equal(t, noTeamLicense.ID, licenses[0].ID)
+	require.Equal(t, license.ID, licenses[1].ID)
+
+	license.Vendor = "Figma"
+	license.SeatCount = 20
+	license.ExpiresAt = nil
+	license.Cost = nil
+	license.UnusedAfterDays = 60
+	license, err = ds.UpdateSoftwareLicense(ctx, license)
+	require.NoError(t, err)
+	require.Equal(t, "Figma", license.Vendor)
+	require.Equal(t, uint(20), license.SeatCount)
+	require.Nil(t, license.ExpiresAt)
+	require.Nil(t, license.Cost)
+	require.Equal(t, uint(60), license.UnusedAfterDays)
+	_, err = ds.UpdateSoftwareLicense(ctx, &mdmlab.SoftwareLicense{ID: license.ID + 100})
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// the licensed title is not cleaned up when no host has the software
+	_, err = ds.UpdateHostSoftware(ctx, host.ID, nil)
+	require.NoError(t, err)
+	require.NoError(t, ds.SyncHostsSoftware(ctx, time.Now()))
+	require.NoError(t, ds.ReconcileSoftwareTitles(ctx))
+	license, err = ds.GetSoftwareLicense(ctx, license.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Figma.app", license.SoftwareTitle)
+
+	require.NoError(t, ds.DeleteSoftwareLicense(ctx, license.ID))
+	_, err = ds.GetSoftwareLicense(ctx, license.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// deleting the team deletes its licenses
+	_, err = ds.NewSoftwareLicense(ctx, &mdmlab.SoftwareLicense{TeamID: &team.ID, SoftwareTitleID: titleID, SeatCount: 5, UnusedAfterDays: 30})
+	require.NoError(t, err)
+	require.NoError(t, ds.DeleteTeam(ctx, team.ID))
+	licenses, err = ds.ListAllSoftwareLicenses(ctx)
+	require.NoError(t, err)
+	require.Len(t, licenses, 1)
+}
+
+func testSoftwareLicensesUsage(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	used, unused := now.Add(-24*time.Hour), now.Add(-60*24*time.Hour)
+
+	// host1 has two versions of the software, the most recently opened
+	// counts
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", now, test.WithTeamID(team.ID))
+	titleID := newSoftwareLicenseTitle(t, ds, host1.ID,
+		mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: &unused},
+		mdmlab.Software{Name: "Figma.app", Version: "1.1", Source: "apps", LastOpenedAt: &used},
+	)
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", now, test.WithTeamID(team.ID))
+	newSoftwareLicenseTitle(t, ds, host2.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: &unused})
+	host3 := test.NewHost(t, ds, "host3", "", "h3key", "h3uuid", now, test.WithTeamID(team.ID))
+	newSoftwareLicenseTitle(t, ds, host3.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps"})
+	// a host of another team is not counted
+	host4 := test.NewHost(t, ds, "host4", "", "h4key", "h4uuid", now)
+	newSoftwareLicenseTitle(t, ds, host4.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: &used})
+
+	license, err := ds.NewSoftwareLicense(ctx, &mdmlab.SoftwareLicense{
+		TeamID:          &team.ID,
+		SoftwareTitleID: titleID,
+		SeatCount:       2,
+		ExpiresAt:       ptr.Time(now.Add(-time.Hour)),
+		UnusedAfterDays: 30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &mdmlab.SoftwareLicenseUsage{
+		Installs:       3,
+		Used:           1,
+		Unused:         1,
+		Unknown:        1,
+		AvailableSeats: -1,
+		OverDeployed:   true,
+		Expired:        true,
+	}, license.Usage)
+
+	hosts, err := ds.ListSoftwareLicenseHosts(ctx, license)
+	require.NoError(t, err)
+	require.Len(t, hosts, 3)
+	require.Equal(t, host1.ID, hosts[0].HostID)
+	require.Equal(t, host1.DisplayName(), hosts[0].HostDisplayName)
+	require.WithinDuration(t, used, *hosts[0].LastOpenedAt, time.Second)
+	require.Equal(t, mdmlab.SoftwareLicenseUsageUsed, hosts[0].UsageStatus)
+	require.Equal(t, mdmlab.SoftwareLicenseUsageUnused, hosts[1].UsageStatus)
+	require.Nil(t, hosts[2].LastOpenedAt)
+	require.Equal(t, mdmlab.SoftwareLicenseUsageUnknown, hosts[2].UsageStatus)
+
+	// a longer unused period
+	license.UnusedAfterDays = 90
+	license.SeatCount = 5
+	license, err = ds.UpdateSoftwareLicense(ctx, license)
+	require.NoError(t, err)
+	require.Equal(t, uint(2), license.Usage.Used)
+	require.Zero(t, license.Usage.Unused)
+	require.Equal(t, 2, license.Usage.AvailableSeats)
+	require.False(t, license.Usage.OverDeployed)
+}
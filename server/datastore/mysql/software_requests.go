@@ -0,0 +1,234 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+// selectSoftwareRequestsStmt is a derived table so that the list options and
+// filters apply to unambiguous column names.
+const selectSoftwareRequestsStmt = `
+	SELECT * FROM (
+		SELECT
+			sr.id,
+			sr.host_id,
+			COALESCE(hdn.display_name, '') AS host_display_name,
+			sr.team_id,
+			sr.title_id,
+			sr.software_title_name,
+			sr.requested_by,
+			sr.justification,
+			sr.status,
+			sr.reviewed_by_user_id,
+			sr.reviewed_by_name,
+			sr.review_comment,
+			sr.reviewed_at,
+			sr.install_uuid,
+			hsi.execution_status AS install_status,
+			sr.created_at,
+			sr.updated_at
+		FROM software_requests sr
+		LEFT JOIN host_display_names hdn ON hdn.host_id = sr.host_id
+		LEFT JOIN host_software_installs hsi ON hsi.execution_id = sr.install_uuid
+	) software_requests`
+
+func (ds *Datastore) NewSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest) (*mdmlab.SoftwareRequest, error) {
+	const stmt = `
+		INSERT INTO software_requests (
+			host_id,
+			team_id,
+			title_id,
+			software_title_name,
+			requested_by,
+			justification,
+			review_comment
+		) VALUES (?, ?, ?, ?, ?, ?, '')`
+
+	res, err := ds.writer(ctx).ExecContext(ctx, stmt,
+		request.HostID,
+		request.TeamID,
+		request.SoftwareTitleID,
+		request.SoftwareTitle,
+		request.RequestedBy,
+		request.Justification,
+	)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "insert software request")
+	}
+	id, _ := res.LastInsertId()
+	return ds.getSoftwareRequest(ctx, ds.writer(ctx), uint(id)) //nolint:gosec // dismiss G115
+}
+
+func (ds *Datastore) GetSoftwareRequest(ctx context.Context, id uint) (*mdmlab.SoftwareRequest, error) {
+	return ds.getSoftwareRequest(ctx, ds.reader(ctx), id)
+}
+
+func (ds *Datastore) getSoftwareRequest(ctx context.Context, q sqlx.QueryerContext, id uint) (*mdmlab.SoftwareRequest, error) {
+	var request mdmlab.SoftwareRequest
+	if err := sqlx.GetContext(ctx, q, &request, selectSoftwareRequestsStmt+` WHERE id = ?`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("SoftwareRequest").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get software request")
+	}
+	return &request, nil
+}
+
+func (ds *Datastore) ListSoftwareRequests(ctx context.Context, opts mdmlab.SoftwareRequestListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+	stmt := selectSoftwareRequestsStmt + ` WHERE TRUE`
+	var args []any
+	if opts.TeamID != nil {
+		if *opts.TeamID == 0 {
+			stmt += ` AND team_id IS NULL`
+		} else {
+			stmt += ` AND team_id = ?`
+			args = append(args, *opts.TeamID)
+		}
+	}
+	if opts.HostID != nil {
+		stmt += ` AND host_id = ?`
+		args = append(args, *opts.HostID)
+	}
+	if opts.Status != "" {
+		stmt += ` AND status = ?`
+		args = append(args, opts.Status)
+	}
+	if opts.RequestedBy != "" {
+		stmt += ` AND requested_by = ?`
+		args = append(args, opts.RequestedBy)
+	}
+
+	if opts.ListOptions.OrderKey == "" {
+		opts.ListOptions.OrderKey = "id"
+		opts.ListOptions.OrderDirection = mdmlab.OrderDescending
+	}
+	opts.ListOptions.IncludeMetadata = !(opts.ListOptions.UsesCursorPagination())
+	stmt, args = appendListOptionsWithCursorToSQL(stmt, args, &opts.ListOptions)
+
+	var requests []*mdmlab.SoftwareRequest
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &requests, stmt, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list software requests")
+	}
+
+	var metaData *mdmlab.PaginationMetadata
+	if opts.ListOptions.IncludeMetadata {
+		metaData = &mdmlab.PaginationMetadata{HasPreviousResults: opts.ListOptions.Page > 0}
+		if len(requests) > int(opts.ListOptions.PerPage) { //nolint:gosec // dismiss G115
+			metaData.HasNextResults = true
+			requests = requests[:len(requests)-1]
+		}
+	}
+	return requests, metaData, nil
+}
+
+func (ds *Datastore) ReviewSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest) error {
+	return ds.reviewSoftwareRequestDB(ctx, ds.writer(ctx), request)
+}
+
+func (ds *Datastore) ApproveSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest, installerID uint) error {
+	const setInstallStmt = `UPDATE software_requests SET install_uuid = ? WHERE id = ?`
+
+	var installUUID string
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		// the request is claimed before the install is queued, so that the
+		// concurrent approvals of the request don't install the software twice.
+		claim := *request
+		claim.InstallUUID = nil
+		if err := ds.reviewSoftwareRequestDB(ctx, tx, &claim); err != nil {
+			return err
+		}
+
+		var err error
+		installUUID, err = ds.insertSoftwareInstallRequestDB(ctx, tx, request.HostID, installerID, false, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, setInstallStmt, installUUID, request.ID); err != nil {
+			return ctxerr.Wrap(ctx, err, "set software request install")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	request.InstallUUID = &installUUID
+	return nil
+}
+
+func (ds *Datastore) reviewSoftwareRequestDB(ctx context.Context, tx sqlx.ExtContext, request *mdmlab.SoftwareRequest) error {
+	const stmt = `
+		UPDATE software_requests SET
+			status = ?,
+			reviewed_by_user_id = ?,
+			reviewed_by_name = ?,
+			review_comment = ?,
+			reviewed_at = CURRENT_TIMESTAMP(6),
+			install_uuid = ?
+		WHERE id = ? AND status = ?`
+
+	res, err := tx.ExecContext(ctx, stmt,
+		request.Status,
+		request.ReviewedByUserID,
+		request.ReviewedByName,
+		request.ReviewComment,
+		request.InstallUUID,
+		request.ID,
+		mdmlab.SoftwareRequestPending,
+	)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "review software request")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// the request doesn't exist or was already reviewed
+		if _, err := ds.getSoftwareRequest(ctx, tx, request.ID); err != nil {
+			return err
+		}
+		return ctxerr.Wrap(ctx, &mdmlab.ConflictError{Message: "The software request was already reviewed."})
+	}
+	return nil
+}
+
+func (ds *Datastore) ListSoftwareRequestCatalog(ctx context.Context, host *mdmlab.Host) ([]*mdmlab.SoftwareCatalogItem, error) {
+	const catalogStmt = `
+		SELECT
+			st.id AS title_id,
+			st.name,
+			st.source,
+			si.version,
+			si.id AS installer_id
+		FROM software_installers si
+		JOIN software_titles st ON st.id = si.title_id
+		WHERE si.global_or_team_id = ? AND si.self_service = 0 AND si.platform = ?
+		ORDER BY st.name, st.id`
+
+	var globalOrTeamID uint
+	if host.TeamID != nil {
+		globalOrTeamID = *host.TeamID
+	}
+	var items []*mdmlab.SoftwareCatalogItem
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &items, catalogStmt, globalOrTeamID, mdmlab.PlatformFromHost(host.Platform)); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list software request catalog")
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	var lastRequests []*mdmlab.SoftwareRequest
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &lastRequests, selectSoftwareRequestsStmt+`
+		WHERE id IN (SELECT MAX(id) FROM software_requests WHERE host_id = ? GROUP BY title_id)`, host.ID); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list last software requests of host")
+	}
+	byTitle := make(map[uint]*mdmlab.SoftwareRequest, len(lastRequests))
+	for _, r := range lastRequests {
+		byTitle[r.SoftwareTitleID] = r
+	}
+	for _, item := range items {
+		item.LastRequest = byTitle[item.SoftwareTitleID]
+	}
+	return items, nil
+}
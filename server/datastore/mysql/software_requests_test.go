@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareRequests(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CRUD", testSoftwareRequestsCRUD},
+		{"Catalog", testSoftwareRequestCatalog},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testSoftwareRequestsCRUD(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now(), test.WithTeamID(team.ID))
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+	installerID, titleID, err := ds.MatchOrCreateSoftwareInstaller(ctx, &mdmlab.UploadSoftwareInstallerPayload{
+		TeamID:          &team.ID,
+		Filename:        "figma.pkg",
+		Title:           "Figma.app",
+		Version:         "1.0",
+		Source:          "apps",
+		Platform:        "darwin",
+		UserID:          user.ID,
+		ValidatedLabels: &mdmlab.LabelIdentsWithScope{},
+	})
+	require.NoError(t, err)
+
+	_, err = ds.GetSoftwareRequest(ctx, 1)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	request1, err := ds.NewSoftwareRequest(ctx, &mdmlab.SoftwareRequest{
+		HostID:          host1.ID,
+		TeamID:          &team.ID,
+		SoftwareTitleID: titleID,
+		SoftwareTitle:   "Figma.app",
+		RequestedBy:     "anna@example.com",
+		Justification:   "design reviews",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, request1.ID)
+	require.Equal(t, host1.DisplayName(), request1.HostDisplayName)
+	require.Equal(t, mdmlab.SoftwareRequestPending, request1.Status)
+	require.Equal(t, "design reviews", request1.Justification)
+	require.Nil(t, request1.ReviewedAt)
+	require.Nil(t, request1.InstallStatus)
+
+	request2, err := ds.NewSoftwareRequest(ctx, &mdmlab.SoftwareRequest{
+		HostID:          host2.ID,
+		SoftwareTitleID: titleID,
+		SoftwareTitle:   "Figma.app",
+		Justification:   "prototypes",
+	})
+	require.NoError(t, err)
+
+	// list with filters, the most recent first
+	requests, meta, err := ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{})
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	require.Equal(t, request2.ID, requests[0].ID)
+	require.Equal(t, request1.ID, requests[1].ID)
+	require.False(t, meta.HasNextResults)
+
+	requests, meta, err = ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{ListOptions: mdmlab.ListOptions{PerPage: 1}})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.True(t, meta.HasNextResults)
+
+	requests, _, err = ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{TeamID: &team.ID})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, request1.ID, requests[0].ID)
+	requests, _, err = ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{TeamID: ptr.Uint(0)})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, request2.ID, requests[0].ID)
+	requests, _, err = ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{HostID: &host2.ID})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, request2.ID, requests[0].ID)
+	requests, _, err = ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{RequestedBy: "anna@example.com"})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, request1.ID, requests[0].ID)
+
+	// approve the first request, the install is queued with the approval
+	request1.Status = mdmlab.SoftwareRequestApproved
+	request1.ReviewedByUserID = &user.ID
+	request1.ReviewedByName = &user.Name
+	request1.ReviewComment = "ok"
+	require.NoError(t, ds.ApproveSoftwareRequest(ctx, request1, installerID))
+	require.NotNil(t, request1.InstallUUID)
+	installUUID := *request1.InstallUUID
+
+	// a concurrent approval doesn't queue another install
+	err = ds.ApproveSoftwareRequest(ctx, &mdmlab.SoftwareRequest{
+		ID:     request1.ID,
+		HostID: host1.ID,
+		Status: mdmlab.SoftwareRequestApproved,
+	}, installerID)
+	var conflictErr *mdmlab.ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	var installsCount int
+	ExecAdhocSQL(t, ds, func(q sqlx.ExtContext) error {
+		return sqlx.GetContext(ctx, q, &installsCount, `SELECT COUNT(*) FROM host_software_installs WHERE host_id = ?`, host1.ID)
+	})
+	require.Equal(t, 1, installsCount)
+
+	got, err := ds.GetSoftwareRequest(ctx, request1.ID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareRequestApproved, got.Status)
+	require.Equal(t, user.ID, *got.ReviewedByUserID)
+	require.Equal(t, "Alice", *got.ReviewedByName)
+	require.Equal(t, "ok", got.ReviewComment)
+	require.NotNil(t, got.ReviewedAt)
+	require.Equal(t, installUUID, *got.InstallUUID)
+	require.Equal(t, mdmlab.SoftwareInstallPending, *got.InstallStatus)
+
+	// the status of the installation is reported
+	require.NoError(t, ds.SetHostSoftwareInstallResult(ctx, &mdmlab.HostSoftwareInstallResultPayload{
+		HostID:                host1.ID,
+		InstallUUID:           installUUID,
+		InstallScriptExitCode: ptr.Int(0),
+	}))
+	got, err = ds.GetSoftwareRequest(ctx, request1.ID)
+	require.NoError(t, err)
+	require.Equal(t, mdmlab.SoftwareInstalled, *got.InstallStatus)
+
+	// a request is reviewed once
+	request1.Status = mdmlab.SoftwareRequestDenied
+	err = ds.ReviewSoftwareRequest(ctx, request1)
+	require.ErrorAs(t, err, &conflictErr)
+	err = ds.ReviewSoftwareRequest(ctx, &mdmlab.SoftwareRequest{ID: request2.ID + 100, Status: mdmlab.SoftwareRequestDenied})
+	require.True(t, mdmlab.IsNotFound(err))
+
+	requests, _, err = ds.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{Status: mdmlab.SoftwareRequestPending})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, request2.ID, requests[0].ID)
+
+	// the reviewer may be deleted
+	require.NoError(t, ds.DeleteUser(ctx, user.ID))
+	got, err = ds.GetSoftwareRequest(ctx, request1.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.ReviewedByUserID)
+	require.Equal(t, "Alice", *got.ReviewedByName)
+}
+
+func testSoftwareRequestCatalog(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	host := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now(), test.WithTeamID(team.ID), test.WithPlatform("darwin"))
+	noTeamHost := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now(), test.WithPlatform("darwin"))
+
+	newInstaller := func(teamID *uint, title, platform string, selfService bool) uint {
+		_, titleID, err := ds.MatchOrCreateSoftwareInstaller(ctx, &mdmlab.UploadSoftwareInstallerPayload{
+			TeamID:          teamID,
+			Filename:        title + ".pkg",
+			Title:           title,
+			Version:         "1.0",
+			Source:          "apps",
+			Platform:        platform,
+			SelfService:     selfService,
+			UserID:          user.ID,
+			ValidatedLabels: &mdmlab.LabelIdentsWithScope{},
+		})
+		require.NoError(t, err)
+		return titleID
+	}
+	figma := newInstaller(&team.ID, "Figma.app", "darwin", false)
+	newInstaller(&team.ID, "Zoom.app", "darwin", true)
+	newInstaller(&team.ID, "Notepad++", "windows", false)
+	noTeamSlack := newInstaller(nil, "Slack.app", "darwin", false)
+
+	// only the titles of the team of the host for its platform that are not
+	// in self-service
+	items, err := ds.ListSoftwareRequestCatalog(ctx, host)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, figma, items[0].SoftwareTitleID)
+	require.Equal(t, "Figma.app", items[0].Name)
+	require.Equal(t, "1.0", items[0].Version)
+	require.NotZero(t, items[0].InstallerID)
+	require.Nil(t, items[0].LastRequest)
+
+	items, err = ds.ListSoftwareRequestCatalog(ctx, noTeamHost)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, noTeamSlack, items[0].SoftwareTitleID)
+
+	// the last request of the title is returned
+	for _, justification := range []string{"first", "second"} {
+		_, err = ds.NewSoftwareRequest(ctx, &mdmlab.SoftwareRequest{
+			HostID:          host.ID,
+			TeamID:          &team.ID,
+			SoftwareTitleID: figma,
+			SoftwareTitle:   "Figma.app",
+			Justification:   justification,
+		})
+		require.NoError(t, err)
+	}
+	items, err = ds.ListSoftwareRequestCatalog(ctx, host)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].LastRequest)
+	require.Equal(t, "second", items[0].LastRequest.Justification)
+}
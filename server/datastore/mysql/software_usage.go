@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+// softwareTitleHostsStmt selects one row per host and software title with the
+// most recent last opened time of any version of the title installed on the
+// host. The first placeholder is for additional conditions, the second for
+// the team filter of the user.
+const softwareTitleHostsStmt = `
+	SELECT
+		s.title_id,
+		hs.host_id,
+		MAX(hs.last_opened_at) AS last_opened_at
+	FROM host_software hs
+	JOIN software s ON s.id = hs.software_id
+	JOIN hosts h ON h.id = hs.host_id
+	WHERE s.title_id IS NOT NULL AND %s AND %s
+	GROUP BY s.title_id, hs.host_id`
+
+// softwareUsageHostsTeamFilter returns the condition to filter the hosts of
+// the team, 0 for no team, and its arguments.
+func softwareUsageHostsTeamFilter(teamID *uint) (string, []any) {
+	if teamID == nil {
+		return "TRUE", nil
+	}
+	return "COALESCE(h.team_id, 0) = ?", []any{*teamID}
+}
+
+func (ds *Datastore) SoftwareTitleUsage(ctx context.Context, titleID uint, teamID *uint, tmFilter mdmlab.TeamFilter) (*mdmlab.SoftwareTitleUsage, error) {
+	teamCond, teamArgs := softwareUsageHostsTeamFilter(teamID)
+	hostsStmt := fmt.Sprintf(softwareTitleHostsStmt, "s.title_id = ? AND "+teamCond, ds.whereFilterHostsByTeams(tmFilter, "h"))
+
+	stmt := fmt.Sprintf(`
+		SELECT
+			st.id AS software_title_id,
+			st.name,
+			st.source,
+			st.bundle_identifier,
+			COUNT(th.host_id) AS hosts_count,
+			COALESCE(SUM(th.last_opened_at IS NOT NULL), 0) AS usage_reported_count,
+			COALESCE(SUM(th.last_opened_at >= DATE_SUB(?, INTERVAL 30 DAY)), 0) AS opened_last_30_days,
+			COALESCE(SUM(th.last_opened_at >= DATE_SUB(?, INTERVAL 60 DAY)), 0) AS opened_last_60_days,
+			COALESCE(SUM(th.last_opened_at >= DATE_SUB(?, INTERVAL 90 DAY)), 0) AS opened_last_90_days,
+			MAX(th.last_opened_at) AS last_opened_at
+		FROM software_titles st
+		LEFT JOIN (%s) th ON th.title_id = st.id
+		WHERE st.id = ?
+		GROUP BY st.id, st.name, st.source, st.bundle_identifier`, hostsStmt)
+
+	now := time.Now().UTC()
+	args := []any{now, now, now, titleID}
+	args = append(args, teamArgs...)
+	args = append(args, titleID)
+
+	var usage mdmlab.SoftwareTitleUsage
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &usage, stmt, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound("SoftwareTitle").WithID(titleID)
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get software title usage")
+	}
+	return &usage, nil
+}
+
+func (ds *Datastore) ListUnusedSoftware(ctx context.Context, opts mdmlab.UnusedSoftwareListOptions, tmFilter mdmlab.TeamFilter) ([]*mdmlab.UnusedSoftware, *mdmlab.PaginationMetadata, error) {
+	unusedForDays := opts.UnusedForDays
+	if unusedForDays == 0 {
+		unusedForDays = mdmlab.SoftwareUsageDefaultUnusedForDays
+	}
+	teamCond, teamArgs := softwareUsageHostsTeamFilter(opts.TeamID)
+	hostsStmt := fmt.Sprintf(softwareTitleHostsStmt, teamCond, ds.whereFilterHostsByTeams(tmFilter, "h"))
+
+	// software that was never opened on a host is not unused on that host, the
+	// time it was installed is unknown.
+	stmt := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT
+				st.id AS software_title_id,
+				st.name,
+				st.source,
+				st.bundle_identifier,
+				COUNT(*) AS hosts_count,
+				COALESCE(SUM(th.last_opened_at < DATE_SUB(?, INTERVAL ? DAY)), 0) AS unused_hosts_count,
+				MAX(th.last_opened_at) AS last_opened_at
+			FROM (%s) th
+			JOIN software_titles st ON st.id = th.title_id
+			GROUP BY st.id, st.name, st.source, st.bundle_identifier
+		) unused_software
+		WHERE unused_hosts_count > 0`, hostsStmt)
+	args := []any{time.Now().UTC(), unusedForDays}
+	args = append(args, teamArgs...)
+
+	if opts.ListOptions.OrderKey == "" {
+		opts.ListOptions.OrderKey = "unused_hosts_count"
+		opts.ListOptions.OrderDirection = mdmlab.OrderDescending
+	}
+	opts.ListOptions.IncludeMetadata = !(opts.ListOptions.UsesCursorPagination())
+	stmt, args = appendListOptionsWithCursorToSQL(stmt, args, &opts.ListOptions)
+
+	var software []*mdmlab.UnusedSoftware
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &software, stmt, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list unused software")
+	}
+
+	var metaData *mdmlab.PaginationMetadata
+	if opts.ListOptions.IncludeMetadata {
+		metaData = &mdmlab.PaginationMetadata{HasPreviousResults: opts.ListOptions.Page > 0}
+		if len(software) > int(opts.ListOptions.PerPage) { //nolint:gosec // dismiss G115
+			metaData.HasNextResults = true
+			software = software[:len(software)-1]
+		}
+	}
+	return software, metaData, nil
+}
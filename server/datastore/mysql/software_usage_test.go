@@ -0,0 +1,151 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareUsage(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"TitleUsage", testSoftwareTitleUsage},
+		{"ListUnused", testListUnusedSoftware},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testSoftwareTitleUsage(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	globalFilter := mdmlab.TeamFilter{User: test.UserAdmin}
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	days := func(n int) *time.Time { return ptr.Time(now.Add(-time.Duration(n) * 24 * time.Hour)) }
+
+	// host1 has two versions, the most recently opened counts
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", now, test.WithTeamID(team.ID))
+	titleID := newSoftwareLicenseTitle(t, ds, host1.ID,
+		mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: days(100)},
+		mdmlab.Software{Name: "Figma.app", Version: "1.1", Source: "apps", LastOpenedAt: days(1)},
+	)
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", now, test.WithTeamID(team.ID))
+	newSoftwareLicenseTitle(t, ds, host2.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: days(45)})
+	host3 := test.NewHost(t, ds, "host3", "", "h3key", "h3uuid", now)
+	newSoftwareLicenseTitle(t, ds, host3.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: days(75)})
+	host4 := test.NewHost(t, ds, "host4", "", "h4key", "h4uuid", now)
+	newSoftwareLicenseTitle(t, ds, host4.ID, mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps"})
+
+	usage, err := ds.SoftwareTitleUsage(ctx, titleID, nil, globalFilter)
+	require.NoError(t, err)
+	require.Equal(t, titleID, usage.SoftwareTitleID)
+	require.Equal(t, "Figma.app", usage.Name)
+	require.Equal(t, uint(4), usage.HostsCount)
+	require.Equal(t, uint(3), usage.UsageReportedCount)
+	require.Equal(t, uint(1), usage.OpenedLast30Days)
+	require.Equal(t, uint(2), usage.OpenedLast60Days)
+	require.Equal(t, uint(3), usage.OpenedLast90Days)
+	require.WithinDuration(t, *days(1), *usage.LastOpenedAt, time.Second)
+
+	usage, err = ds.SoftwareTitleUsage(ctx, titleID, &team.ID, globalFilter)
+	require.NoError(t, err)
+	require.Equal(t, uint(2), usage.HostsCount)
+	require.Equal(t, uint(2), usage.OpenedLast60Days)
+
+	usage, err = ds.SoftwareTitleUsage(ctx, titleID, ptr.Uint(0), globalFilter)
+	require.NoError(t, err)
+	require.Equal(t, uint(2), usage.HostsCount)
+	require.Equal(t, uint(1), usage.UsageReportedCount)
+	require.Zero(t, usage.OpenedLast60Days)
+	require.Equal(t, uint(1), usage.OpenedLast90Days)
+
+	// a team user only sees the hosts of its teams
+	teamUser := &mdmlab.User{Teams: []mdmlab.UserTeam{{Team: *team, Role: mdmlab.RoleObserver}}}
+	usage, err = ds.SoftwareTitleUsage(ctx, titleID, nil, mdmlab.TeamFilter{User: teamUser, IncludeObserver: true})
+	require.NoError(t, err)
+	require.Equal(t, uint(2), usage.HostsCount)
+
+	_, err = ds.SoftwareTitleUsage(ctx, titleID+100, nil, globalFilter)
+	require.True(t, mdmlab.IsNotFound(err))
+}
+
+func testListUnusedSoftware(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	globalFilter := mdmlab.TeamFilter{User: test.UserAdmin}
+
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	days := func(n int) *time.Time { return ptr.Time(now.Add(-time.Duration(n) * 24 * time.Hour)) }
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", now, test.WithTeamID(team.ID))
+	figmaID := newSoftwareLicenseTitle(t, ds, host1.ID,
+		mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: days(100)},
+		mdmlab.Software{Name: "Slack.app", Version: "1.0", Source: "apps", LastOpenedAt: days(120)},
+		mdmlab.Software{Name: "Zoom.app", Version: "1.0", Source: "apps"},
+	)
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", now, test.WithTeamID(team.ID))
+	newSoftwareLicenseTitle(t, ds, host2.ID,
+		mdmlab.Software{Name: "Figma.app", Version: "1.0", Source: "apps", LastOpenedAt: days(95)},
+		mdmlab.Software{Name: "Slack.app", Version: "1.0", Source: "apps", LastOpenedAt: days(1)},
+	)
+	host3 := test.NewHost(t, ds, "host3", "", "h3key", "h3uuid", now)
+	newSoftwareLicenseTitle(t, ds, host3.ID, mdmlab.Software{Name: "Zoom.app", Version: "1.0", Source: "apps", LastOpenedAt: days(200)})
+
+	// never opened software is not unused
+	unused, meta, err := ds.ListUnusedSoftware(ctx, mdmlab.UnusedSoftwareListOptions{TeamID: &team.ID, UnusedForDays: 90}, globalFilter)
+	require.NoError(t, err)
+	require.False(t, meta.HasNextResults)
+	require.Len(t, unused, 2)
+	require.Equal(t, figmaID, unused[0].SoftwareTitleID)
+	require.Equal(t, uint(2), unused[0].HostsCount)
+	require.Equal(t, uint(2), unused[0].UnusedHostsCount)
+	require.WithinDuration(t, *days(95), *unused[0].LastOpenedAt, time.Second)
+	require.Equal(t, "Slack.app", unused[1].Name)
+	require.Equal(t, uint(2), unused[1].HostsCount)
+	require.Equal(t, uint(1), unused[1].UnusedHostsCount)
+
+	unused, _, err = ds.ListUnusedSoftware(ctx, mdmlab.UnusedSoftwareListOptions{TeamID: &team.ID, UnusedForDays: 110}, globalFilter)
+	require.NoError(t, err)
+	require.Len(t, unused, 1)
+	require.Equal(t, "Slack.app", unused[0].Name)
+
+	unused, _, err = ds.ListUnusedSoftware(ctx, mdmlab.UnusedSoftwareListOptions{TeamID: ptr.Uint(0)}, globalFilter)
+	require.NoError(t, err)
+	require.Len(t, unused, 1)
+	require.Equal(t, "Zoom.app", unused[0].Name)
+	require.Equal(t, uint(1), unused[0].HostsCount)
+
+	// all the teams, paginated
+	opts := mdmlab.UnusedSoftwareListOptions{ListOptions: mdmlab.ListOptions{PerPage: 2, OrderKey: "name"}, UnusedForDays: 90}
+	unused, meta, err = ds.ListUnusedSoftware(ctx, opts, globalFilter)
+	require.NoError(t, err)
+	require.True(t, meta.HasNextResults)
+	require.Len(t, unused, 2)
+	require.Equal(t, "Figma.app", unused[0].Name)
+	require.Equal(t, "Slack.app", unused[1].Name)
+	opts.ListOptions.Page = 1
+	unused, meta, err = ds.ListUnusedSoftware(ctx, opts, globalFilter)
+	require.NoError(t, err)
+	require.False(t, meta.HasNextResults)
+	require.True(t, meta.HasPreviousResults)
+	require.Len(t, unused, 1)
+	require.Equal(t, "Zoom.app", unused[0].Name)
+	require.Equal(t, uint(2), unused[0].HostsCount)
+}
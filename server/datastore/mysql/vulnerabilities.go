@@ -27,6 +27,8 @@ func (ds *Datastore) Vulnerability(ctx context.Context, cve string, teamID *uint
 			cm.cisa_known_exploit,
 			cm.published as cve_published,
 			cm.description,
+			cm.cvss_version,
+			cm.attack_vector,
 			COALESCE(vhc.host_count, 0) as hosts_count,
 			COALESCE(vhc.updated_at, NOW()) as hosts_count_updated_at
 		FROM cve_meta cm
@@ -106,6 +108,13 @@ func (ds *Datastore) Vulnerability(ctx context.Context, cve string, teamID *uint
 		return nil, ctxerr.Wrap(ctx, notFound(fmt.Sprintf("Vulnerability for %s", msg)).WithName(cve))
 	}
 
+	if includeCVEScores {
+		vuln.CVSSScores, err = ds.listCVSSScores(ctx, cve)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &vuln, nil
 }
 
@@ -229,6 +238,8 @@ func (ds *Datastore) ListVulnerabilities(ctx context.Context, opt mdmlab.VulnLis
 			cm.cisa_known_exploit,
 			cm.published as cve_published,
 			cm.description,
+			cm.cvss_version,
+			cm.attack_vector,
 			vhc.host_count as hosts_count,
 			vhc.updated_at as hosts_count_updated_at
 		FROM (
@@ -277,6 +288,11 @@ func (ds *Datastore) ListVulnerabilities(ctx context.Context, opt mdmlab.VulnLis
 		selectStmt += " AND cm.cisa_known_exploit = 1"
 	}
 
+	if opt.AttackVector != "" {
+		selectStmt += " AND cm.attack_vector = ?"
+		args = append(args, opt.AttackVector)
+	}
+
 	if match := opt.ListOptions.MatchQuery; match != "" {
 		selectStmt, args = searchLike(selectStmt, args, match, "vhc.cve")
 	}
@@ -331,6 +347,11 @@ func (ds *Datastore) CountVulnerabilities(ctx context.Context, opt mdmlab.VulnLi
 		selectStmt += " AND cm.cisa_known_exploit = 1"
 	}
 
+	if opt.AttackVector != "" {
+		selectStmt += " AND cm.attack_vector = ?"
+		args = append(args, opt.AttackVector)
+	}
+
 	if match := opt.ListOptions.MatchQuery; match != "" {
 		selectStmt, args = searchLike(selectStmt, args, match, "vhc.cve")
 	}
@@ -456,43 +477,38 @@ func (ds *Datastore) fetchBatchCounts(
 	return counts, nil
 }
 
-// getScopeConfig returns the query configuration for the given scope.
-func getVulnHostCountQuery(scope CountScope) string {
-	switch scope {
-	case GlobalCount:
-		return `
-				SELECT 0 as team_id, 1 as global_stats, combined_results.cve, COUNT(*) AS host_count
-				FROM (
+// vulnerableHostsQuery returns the (cve, host_id) pairs of hosts affected by the CVEs, excluding
+// the vulnerabilities that are suppressed for the host.
+var vulnerableHostsQuery = `
 					SELECT sc.cve, hs.host_id
 					FROM software_cve sc
 					INNER JOIN host_software hs ON sc.software_id = hs.software_id
-					WHERE sc.cve IN (?)
+					INNER JOIN software s ON s.id = sc.software_id
+					LEFT JOIN hosts hsup ON hsup.id = hs.host_id
+					WHERE sc.cve IN (?) AND NOT ` + vulnerabilitySuppressedCondition("sc.cve", "hsup.team_id", "hs.host_id", "s.title_id") + `
 
 					UNION
 
 					SELECT osv.cve, hos.host_id
 					FROM operating_system_vulnerabilities osv
 					INNER JOIN host_operating_system hos ON hos.os_id = osv.operating_system_id
-					WHERE osv.cve IN (?)
-				) AS combined_results
+					LEFT JOIN hosts hsup ON hsup.id = hos.host_id
+					WHERE osv.cve IN (?) AND NOT ` + vulnerabilitySuppressedCondition("osv.cve", "hsup.team_id", "hos.host_id", "NULL") + `
+				`
+
+// getScopeConfig returns the query configuration for the given scope.
+func getVulnHostCountQuery(scope CountScope) string {
+	switch scope {
+	case GlobalCount:
+		return `
+				SELECT 0 as team_id, 1 as global_stats, combined_results.cve, COUNT(*) AS host_count
+				FROM (` + vulnerableHostsQuery + `) AS combined_results
 				GROUP BY cve
 			`
 	case NoTeamCount:
 		return `
 				SELECT 0 as team_id, 0 as global_stats, combined_results.cve, COUNT(*) AS host_count
-				FROM (
-					SELECT sc.cve, hs.host_id
-					FROM software_cve sc
-					INNER JOIN host_software hs ON sc.software_id = hs.software_id
-					WHERE sc.cve IN (?)
-
-					UNION
-
-					SELECT osv.cve, hos.host_id
-					FROM operating_system_vulnerabilities osv
-					INNER JOIN host_operating_system hos ON hos.os_id = osv.operating_system_id
-					WHERE osv.cve IN (?)
-				) AS combined_results
+				FROM (` + vulnerableHostsQuery + `) AS combined_results
 				INNER JOIN hosts h ON combined_results.host_id = h.id
 				WHERE h.team_id IS NULL
 				GROUP BY cve
@@ -500,19 +516,7 @@ func getVulnHostCountQuery(scope CountScope) string {
 	case TeamCount:
 		return `
 				SELECT h.team_id as team_id, 0 as global_stats, combined_results.cve, COUNT(*) AS host_count
-				FROM (
-					SELECT sc.cve, hs.host_id
-					FROM software_cve sc
-					INNER JOIN host_software hs ON sc.software_id = hs.software_id
-					WHERE sc.cve IN (?)
-
-					UNION
-
-					SELECT osv.cve, hos.host_id
-					FROM operating_system_vulnerabilities osv
-					INNER JOIN host_operating_system hos ON hos.os_id = osv.operating_system_id
-					WHERE osv.cve IN (?)
-				) AS combined_results
+				FROM (` + vulnerableHostsQuery + `) AS combined_results
 				INNER JOIN hosts h ON combined_results.host_id = h.id
 				WHERE h.team_id IS NOT NULL
 				GROUP BY h.team_id, combined_results.cve
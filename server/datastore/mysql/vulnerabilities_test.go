@@ -30,6 +30,7 @@ func TestVulnerabilities(t *testing.T) {
 		{"TestListVulnerabilitiesSort", testListVulnerabilitiesSort},
 		{"TestVulnerabilitiesFilters", testVulnerabilitiesFilters},
 		{"TestCountVulnerabilities", testCountVulnerabilities},
+		{"TestVulnerabilitiesCVSSScores", testVulnerabilitiesCVSSScores},
 		{"TestInsertVulnerabilityCounts", testInsertVulnerabilityCounts},
 		{"TestVulnerabilityHostCountBatchInserts", testVulnerabilityHostCountBatchInserts},
 	}
@@ -563,6 +564,63 @@ func testCountVulnerabilities(t *testing.T, ds *Datastore) {
 	require.Equal(t, uint(1), count)
 }
 
+func testVulnerabilitiesCVSSScores(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	seedVulnerabilities(t, ds)
+
+	network := mdmlab.CVEMeta{
+		CVE: "CVE-2020-1234",
+		CVSSScores: []mdmlab.CVSSScore{
+			{Version: "3.1", Source: "nvd@nist.gov", Primary: true, BaseScore: 9.8, VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", AttackVector: mdmlab.CVSSAttackVectorNetwork},
+			{Version: "4.0", Source: "secalert@example.com", BaseScore: 9.3, VectorString: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", AttackVector: mdmlab.CVSSAttackVectorNetwork},
+		},
+	}
+	network.ApplyCVSSScorePreference(mdmlab.CVSSScorePreferenceCNAv4)
+	local := mdmlab.CVEMeta{
+		CVE: "CVE-2020-1235",
+		CVSSScores: []mdmlab.CVSSScore{
+			{Version: "3.1", Source: "nvd@nist.gov", Primary: true, BaseScore: 7.8, VectorString: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", AttackVector: mdmlab.CVSSAttackVectorLocal},
+		},
+	}
+	local.ApplyCVSSScorePreference(mdmlab.CVSSScorePreferenceCNAv4)
+	require.NoError(t, ds.InsertCVEMeta(ctx, []mdmlab.CVEMeta{network, local}))
+
+	// filter by attack vector
+	list, _, err := ds.ListVulnerabilities(ctx, mdmlab.VulnListOptions{IsEE: true, AttackVector: mdmlab.CVSSAttackVectorNetwork})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "CVE-2020-1234", list[0].CVE.CVE)
+	require.NotNil(t, list[0].CVSSScore)
+	require.Equal(t, 9.3, **list[0].CVSSScore)
+	require.Equal(t, "4.0", **list[0].CVSSVersion)
+	require.Equal(t, "network", **list[0].AttackVector)
+
+	count, err := ds.CountVulnerabilities(ctx, mdmlab.VulnListOptions{AttackVector: mdmlab.CVSSAttackVectorLocal})
+	require.NoError(t, err)
+	require.Equal(t, uint(1), count)
+
+	// all the scores are returned for a single vulnerability
+	vuln, err := ds.Vulnerability(ctx, "CVE-2020-1234", nil, true)
+	require.NoError(t, err)
+	require.Len(t, vuln.CVSSScores, 2)
+	require.True(t, vuln.CVSSScores[0].Primary)
+	require.Equal(t, "secalert@example.com", vuln.CVSSScores[1].Source)
+
+	vuln, err = ds.Vulnerability(ctx, "CVE-2020-1234", nil, false)
+	require.NoError(t, err)
+	require.Empty(t, vuln.CVSSScores)
+
+	// scores are replaced on the next insert
+	network.CVSSScores = network.CVSSScores[:1]
+	network.ApplyCVSSScorePreference(mdmlab.CVSSScorePreferenceCNAv4)
+	require.NoError(t, ds.InsertCVEMeta(ctx, []mdmlab.CVEMeta{network}))
+	vuln, err = ds.Vulnerability(ctx, "CVE-2020-1234", nil, true)
+	require.NoError(t, err)
+	require.Len(t, vuln.CVSSScores, 1)
+	require.Equal(t, 9.8, **vuln.CVSSScore)
+	require.Equal(t, "3.1", **vuln.CVSSVersion)
+}
+
 func testInsertVulnerabilityCounts(t *testing.T, ds *Datastore) {
 	windowsOS := mdmlab.OperatingSystem{
 		Name:     "Windows 11 Pro",
@@ -0,0 +1,279 @@
+package mysql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/jmoiron/sqlx"
+)
+
+func (ds *Datastore) ListVulnerableSoftwareTitlesByCVE(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerableSoftwareTitle, error) {
+	type vulnerableSoftwareRow struct {
+		mdmlab.VulnerableSoftware
+		TitleID          *uint   `db:"title_id"`
+		TitleName        *string `db:"title_name"`
+		BundleIdentifier *string `db:"bundle_identifier"`
+	}
+
+	stmt := `
+		SELECT
+			s.id,
+			s.name,
+			s.version,
+			s.source,
+			s.browser,
+			s.title_id,
+			st.name AS title_name,
+			COALESCE(st.bundle_identifier, s.bundle_identifier) AS bundle_identifier,
+			COALESCE(scpe.cpe, '') AS generated_cpe,
+			COALESCE(shc.hosts_count, 0) AS hosts_count,
+			sc.resolved_in_version
+		FROM software s
+		JOIN software_cve sc ON sc.software_id = s.id
+		JOIN software_host_counts shc ON shc.software_id = s.id
+		LEFT JOIN software_titles st ON st.id = s.title_id
+		LEFT JOIN software_cpe scpe ON scpe.software_id = s.id
+		WHERE sc.cve = ? AND shc.hosts_count > 0`
+	args := []any{cve}
+	switch {
+	case teamID != nil && *teamID > 0:
+		stmt += " AND shc.team_id = ? AND shc.global_stats = 0"
+		args = append(args, *teamID)
+	case teamID != nil && *teamID == 0:
+		stmt += " AND shc.team_id = 0 AND shc.global_stats = 0"
+	default:
+		stmt += " AND shc.team_id = 0 AND shc.global_stats = 1"
+	}
+	stmt += " ORDER BY s.name, s.source, s.browser, s.version, s.id"
+
+	var rows []vulnerableSoftwareRow
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rows, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerable software by CVE")
+	}
+
+	// group the vulnerable versions by software title, software that has not
+	// been reconciled with a title yet is grouped by name, source and browser.
+	type titleKey struct {
+		titleID               uint
+		name, source, browser string
+	}
+	var titles []*mdmlab.VulnerableSoftwareTitle
+	byKey := make(map[titleKey]*mdmlab.VulnerableSoftwareTitle)
+	byTitleID := make(map[uint]*mdmlab.VulnerableSoftwareTitle)
+	for _, row := range rows {
+		key := titleKey{name: row.Name, source: row.Source, browser: row.Browser}
+		if row.TitleID != nil {
+			key = titleKey{titleID: *row.TitleID}
+		}
+
+		title, ok := byKey[key]
+		if !ok {
+			title = &mdmlab.VulnerableSoftwareTitle{
+				SoftwareTitleID: row.TitleID,
+				Name:            row.Name,
+				Source:          row.Source,
+				Browser:         row.Browser,
+			}
+			if row.TitleName != nil {
+				title.Name = *row.TitleName
+			}
+			if row.BundleIdentifier != nil {
+				title.BundleIdentifier = *row.BundleIdentifier
+			}
+			byKey[key] = title
+			if row.TitleID != nil {
+				byTitleID[*row.TitleID] = title
+			}
+			titles = append(titles, title)
+		}
+
+		version := row.VulnerableSoftware
+		if version.ResolvedInVersion != nil && *version.ResolvedInVersion == "" {
+			version.ResolvedInVersion = nil
+		}
+		title.Versions = append(title.Versions, &version)
+		title.HostsCount += version.HostsCount
+		if version.ResolvedInVersion != nil &&
+			(title.FixedVersion == nil || mdmlab.CompareVersions(*version.ResolvedInVersion, *title.FixedVersion) > 0) {
+			title.FixedVersion = ptr.String(*version.ResolvedInVersion)
+		}
+	}
+
+	if len(byTitleID) > 0 {
+		remediations, err := ds.listVulnerabilityRemediations(ctx, byTitleID, teamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range remediations {
+			title := byTitleID[r.SoftwareTitleID]
+			if title.FixedVersion != nil {
+				r.FixesVulnerability = ptr.Bool(mdmlab.IsAtLeastVersion(r.Version, *title.FixedVersion))
+			}
+			title.Remediations = append(title.Remediations, r)
+		}
+	}
+
+	sort.SliceStable(titles, func(i, j int) bool {
+		return titles[i].HostsCount > titles[j].HostsCount
+	})
+	return titles, nil
+}
+
+// listVulnerabilityRemediations returns the software packages and App Store
+// apps available to the team (all teams if nil) and the MDMlab-maintained apps
+// of the given software titles.
+func (ds *Datastore) listVulnerabilityRemediations(
+	ctx context.Context,
+	titles map[uint]*mdmlab.VulnerableSoftwareTitle,
+	teamID *uint,
+) ([]*mdmlab.VulnerabilityRemediation, error) {
+	titleIDs := make([]uint, 0, len(titles))
+	for id := range titles {
+		titleIDs = append(titleIDs, id)
+	}
+	sort.Slice(titleIDs, func(i, j int) bool { return titleIDs[i] < titleIDs[j] })
+
+	var result []*mdmlab.VulnerabilityRemediation
+	queries := []struct {
+		typ  mdmlab.VulnerabilityRemediationType
+		stmt string
+		team bool
+	}{
+		{
+			typ: mdmlab.VulnerabilityRemediationSoftwarePackage,
+			stmt: `
+				SELECT si.title_id, si.id AS software_installer_id, si.global_or_team_id AS team_id, si.version
+				FROM software_installers si
+				WHERE si.title_id IN (?)`,
+			team: true,
+		},
+		{
+			typ: mdmlab.VulnerabilityRemediationAppStoreApp,
+			stmt: `
+				SELECT va.title_id, va.adam_id, vat.global_or_team_id AS team_id, va.latest_version AS version
+				FROM vpp_apps va
+				JOIN vpp_apps_teams vat ON vat.adam_id = va.adam_id AND vat.platform = va.platform
+				WHERE va.title_id IN (?)`,
+			team: true,
+		},
+		{
+			typ: mdmlab.VulnerabilityRemediationMDMlabMaintainedApp,
+			stmt: `
+				SELECT st.id AS title_id, fla.id AS mdmlab_maintained_app_id, fla.version
+				FROM fleet_library_apps fla
+				JOIN software_titles st ON st.bundle_identifier = fla.bundle_identifier
+				WHERE st.id IN (?)`,
+		},
+	}
+	for _, q := range queries {
+		stmt, args := q.stmt, []any{titleIDs}
+		if q.team && teamID != nil {
+			if q.typ == mdmlab.VulnerabilityRemediationSoftwarePackage {
+				stmt += " AND si.global_or_team_id = ?"
+			} else {
+				stmt += " AND vat.global_or_team_id = ?"
+			}
+			args = append(args, *teamID)
+		}
+		stmt, args, err := sqlx.In(stmt, args...)
+		if err != nil {
+			return nil, ctxerr.Wrapf(ctx, err, "build %s remediations query", q.typ)
+		}
+
+		var remediations []*mdmlab.VulnerabilityRemediation
+		if err := sqlx.SelectContext(ctx, ds.reader(ctx), &remediations, stmt, args...); err != nil {
+			return nil, ctxerr.Wrapf(ctx, err, "list %s remediations", q.typ)
+		}
+		for _, r := range remediations {
+			r.Type = q.typ
+		}
+		result = append(result, remediations...)
+	}
+	return result, nil
+}
+
+func (ds *Datastore) VulnerabilityHostCountsByTeam(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityTeamHostsCount, error) {
+	stmt := `
+		SELECT
+			vhc.team_id,
+			COALESCE(t.name, 'No team') AS team_name,
+			vhc.host_count,
+			vhc.updated_at
+		FROM vulnerability_host_counts vhc
+		LEFT JOIN teams t ON t.id = vhc.team_id
+		WHERE vhc.cve = ? AND vhc.global_stats = 0 AND vhc.host_count > 0`
+	args := []any{cve}
+	if teamID != nil {
+		stmt += " AND vhc.team_id = ?"
+		args = append(args, *teamID)
+	}
+	stmt += " ORDER BY vhc.host_count DESC, vhc.team_id"
+
+	var counts []*mdmlab.VulnerabilityTeamHostsCount
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &counts, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability host counts by team")
+	}
+	return counts, nil
+}
+
+func (ds *Datastore) ListVulnerabilityPolicies(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityPolicy, error) {
+	stmt := `
+		SELECT
+			vp.policy_id,
+			vp.cve,
+			vp.software_title_id,
+			p.name,
+			p.team_id,
+			COALESCE(ps.passing_host_count, 0) AS passing_host_count,
+			COALESCE(ps.failing_host_count, 0) AS failing_host_count
+		FROM vulnerability_policies vp
+		JOIN policies p ON p.id = vp.policy_id
+		LEFT JOIN policy_stats ps ON ps.policy_id = p.id
+			AND ((p.team_id IS NULL AND ps.inherited_team_id IS NULL) OR (p.team_id IS NOT NULL))
+		WHERE vp.cve = ?`
+	args := []any{cve}
+	if teamID != nil {
+		// global policies also apply to the hosts of the team
+		stmt += " AND (p.team_id IS NULL OR p.team_id = ?)"
+		args = append(args, *teamID)
+	}
+	stmt += " ORDER BY p.name, p.id"
+
+	var policies []*mdmlab.VulnerabilityPolicy
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &policies, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability policies")
+	}
+	return policies, nil
+}
+
+func (ds *Datastore) NewVulnerabilityPolicy(ctx context.Context, teamID *uint, authorID *uint, args mdmlab.PolicyPayload, cve string, softwareTitleID *uint) (*mdmlab.Policy, error) {
+	const stmt = `INSERT INTO vulnerability_policies (policy_id, cve, software_title_id) VALUES (?, ?, ?)`
+
+	var policy *mdmlab.Policy
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		var err error
+		if teamID == nil {
+			policy, err = newGlobalPolicy(ctx, tx, authorID, args)
+		} else {
+			policy, err = newTeamPolicy(ctx, tx, *teamID, authorID, args)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, stmt, policy.ID, cve, softwareTitleID); err != nil {
+			if IsDuplicate(err) {
+				return ctxerr.Wrap(ctx, alreadyExists("VulnerabilityPolicy", cve))
+			}
+			return ctxerr.Wrap(ctx, err, "insert vulnerability policy")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
@@ -0,0 +1,181 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityExposure(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"VulnerableSoftwareAndTeams", testVulnerableSoftwareAndTeams},
+		{"VulnerabilityPolicies", testVulnerabilityPolicies},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testVulnerableSoftwareAndTeams(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+	host3 := test.NewHost(t, ds, "host3", "", "h3key", "h3uuid", time.Now())
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host2.ID, host3.ID}))
+
+	_, err = ds.UpdateHostSoftware(ctx, host1.ID, []mdmlab.Software{
+		{Name: "Chrome", Version: "1.0.0", Source: "apps", BundleIdentifier: "com.google.Chrome"},
+	})
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, host2.ID, []mdmlab.Software{
+		{Name: "Chrome", Version: "1.1.0", Source: "apps", BundleIdentifier: "com.google.Chrome"},
+		{Name: "curl", Version: "7.0.0", Source: "deb_packages"},
+	})
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, host3.ID, []mdmlab.Software{
+		{Name: "Chrome", Version: "1.1.0", Source: "apps", BundleIdentifier: "com.google.Chrome"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.ReconcileSoftwareTitles(ctx))
+	require.NoError(t, ds.SyncHostsSoftware(ctx, time.Now()))
+	require.NoError(t, ds.LoadHostSoftware(ctx, host2, false))
+
+	softwareIDs := make(map[string]uint)
+	for _, s := range host2.Software {
+		softwareIDs[s.Name+"@"+s.Version] = s.ID
+	}
+	require.NoError(t, ds.LoadHostSoftware(ctx, host1, false))
+	softwareIDs["Chrome@1.0.0"] = host1.Software[0].ID
+
+	for key, resolvedIn := range map[string]string{"Chrome@1.0.0": "1.2.0", "Chrome@1.1.0": "1.2.1"} {
+		_, err = ds.InsertSoftwareVulnerability(ctx, mdmlab.SoftwareVulnerability{
+			SoftwareID: softwareIDs[key], CVE: "CVE-2024-0001", ResolvedInVersion: ptr.String(resolvedIn),
+		}, mdmlab.NVDSource)
+		require.NoError(t, err)
+	}
+	_, err = ds.InsertSoftwareVulnerability(ctx, mdmlab.SoftwareVulnerability{
+		SoftwareID: softwareIDs["curl@7.0.0"], CVE: "CVE-2024-0001",
+	}, mdmlab.NVDSource)
+	require.NoError(t, err)
+	require.NoError(t, ds.UpdateVulnerabilityHostCounts(ctx, 5))
+
+	// all teams
+	titles, err := ds.ListVulnerableSoftwareTitlesByCVE(ctx, "CVE-2024-0001", nil)
+	require.NoError(t, err)
+	require.Len(t, titles, 2)
+	require.Equal(t, "Chrome", titles[0].Name)
+	require.NotNil(t, titles[0].SoftwareTitleID)
+	require.Equal(t, "com.google.Chrome", titles[0].BundleIdentifier)
+	require.Equal(t, 3, titles[0].HostsCount)
+	require.Len(t, titles[0].Versions, 2)
+	require.Equal(t, ptr.String("1.2.1"), titles[0].FixedVersion)
+	require.Equal(t, "curl", titles[1].Name)
+	require.Equal(t, 1, titles[1].HostsCount)
+	require.Nil(t, titles[1].FixedVersion)
+
+	// only the team's hosts
+	titles, err = ds.ListVulnerableSoftwareTitlesByCVE(ctx, "CVE-2024-0001", &team.ID)
+	require.NoError(t, err)
+	require.Len(t, titles, 2)
+	require.Equal(t, 2, titles[0].HostsCount)
+	require.Len(t, titles[0].Versions, 1)
+	require.Equal(t, "1.1.0", titles[0].Versions[0].Version)
+
+	// hosts with no team
+	titles, err = ds.ListVulnerableSoftwareTitlesByCVE(ctx, "CVE-2024-0001", ptr.Uint(0))
+	require.NoError(t, err)
+	require.Len(t, titles, 1)
+	require.Equal(t, 1, titles[0].HostsCount)
+
+	// unknown CVE
+	titles, err = ds.ListVulnerableSoftwareTitlesByCVE(ctx, "CVE-2024-9999", nil)
+	require.NoError(t, err)
+	require.Empty(t, titles)
+
+	counts, err := ds.VulnerabilityHostCountsByTeam(ctx, "CVE-2024-0001", nil)
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	require.Equal(t, team.ID, counts[0].TeamID)
+	require.Equal(t, "team1", counts[0].TeamName)
+	require.EqualValues(t, 2, counts[0].HostsCount)
+	require.Zero(t, counts[1].TeamID)
+	require.Equal(t, "No team", counts[1].TeamName)
+	require.EqualValues(t, 1, counts[1].HostsCount)
+
+	counts, err = ds.VulnerabilityHostCountsByTeam(ctx, "CVE-2024-0001", ptr.Uint(0))
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	require.Zero(t, counts[0].TeamID)
+}
+
+func testVulnerabilityPolicies(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	team2, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team2"})
+	require.NoError(t, err)
+
+	global, err := ds.NewVulnerabilityPolicy(ctx, nil, &user.ID, mdmlab.PolicyPayload{Name: "global", Query: "SELECT 1;"}, "CVE-2024-0001", nil)
+	require.NoError(t, err)
+	require.Nil(t, global.TeamID)
+	require.Equal(t, "global", global.Name)
+	team1Policy, err := ds.NewVulnerabilityPolicy(ctx, &team.ID, &user.ID, mdmlab.PolicyPayload{Name: "team1", Query: "SELECT 1;"}, "CVE-2024-0001", ptr.Uint(1))
+	require.NoError(t, err)
+	require.Equal(t, &team.ID, team1Policy.TeamID)
+	_, err = ds.NewVulnerabilityPolicy(ctx, &team2.ID, &user.ID, mdmlab.PolicyPayload{Name: "team2", Query: "SELECT 1;"}, "CVE-2024-0002", nil)
+	require.NoError(t, err)
+
+	// the policy and its link are created in the same transaction
+	_, err = ds.NewVulnerabilityPolicy(ctx, nil, &user.ID, mdmlab.PolicyPayload{Name: "global", Query: "SELECT 1;"}, "CVE-2024-0003", nil)
+	require.Error(t, err)
+	var existsErr mdmlab.AlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+	_, err = ds.NewVulnerabilityPolicy(ctx, ptr.Uint(999), &user.ID, mdmlab.PolicyPayload{Name: "unknown team", Query: "SELECT 1;"}, "CVE-2024-0003", nil)
+	require.Error(t, err)
+	require.True(t, mdmlab.IsNotFound(err))
+	policies, err := ds.ListVulnerabilityPolicies(ctx, "CVE-2024-0003", nil)
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	policies, err = ds.ListVulnerabilityPolicies(ctx, "CVE-2024-0001", nil)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	require.Equal(t, global.ID, policies[0].PolicyID)
+	require.Nil(t, policies[0].TeamID)
+	require.Nil(t, policies[0].SoftwareTitleID)
+	require.Equal(t, team1Policy.ID, policies[1].PolicyID)
+	require.Equal(t, &team.ID, policies[1].TeamID)
+	require.Equal(t, ptr.Uint(1), policies[1].SoftwareTitleID)
+
+	// global policies apply to the team
+	policies, err = ds.ListVulnerabilityPolicies(ctx, "CVE-2024-0001", &team2.ID)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.Equal(t, global.ID, policies[0].PolicyID)
+
+	// deleting the policy deletes the link
+	_, err = ds.DeleteTeamPolicies(ctx, team.ID, []uint{team1Policy.ID})
+	require.NoError(t, err)
+	policies, err = ds.ListVulnerabilityPolicies(ctx, "CVE-2024-0001", nil)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+}
@@ -0,0 +1,291 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+// syncHostVulnerabilitiesBatchSize is the number of host IDs processed by each transaction of
+// SyncHostVulnerabilities. This is a variable so it can be adjusted during unit testing.
+var syncHostVulnerabilitiesBatchSize = uint(10000)
+
+// currentHostVulnerabilitiesStmt selects the CVEs currently affecting each host of a range of host
+// IDs, through its software or its operating system.
+const currentHostVulnerabilitiesStmt = `
+	SELECT hs.host_id, sc.cve
+	FROM host_software hs
+	JOIN software_cve sc ON sc.software_id = hs.software_id
+	WHERE hs.host_id > ? AND hs.host_id <= ?
+	UNION
+	SELECT hos.host_id, osv.cve
+	FROM host_operating_system hos
+	JOIN operating_system_vulnerabilities osv ON osv.operating_system_id = hos.os_id
+	WHERE hos.host_id > ? AND hos.host_id <= ?`
+
+func (ds *Datastore) SyncHostVulnerabilities(ctx context.Context, now time.Time) error {
+	// a vulnerability detected again after being resolved is a new detection, so its SLA starts
+	// over.
+	const insertStmt = `
+		INSERT INTO host_vulnerabilities (host_id, cve, first_detected_at)
+		SELECT cur.host_id, cur.cve, ? FROM (` + currentHostVulnerabilitiesStmt + `) cur
+		ON DUPLICATE KEY UPDATE
+			first_detected_at = IF(resolved_at IS NULL, first_detected_at, VALUES(first_detected_at)),
+			sla_breach_notified_at = IF(resolved_at IS NULL, sla_breach_notified_at, NULL),
+			resolved_at = NULL`
+
+	const resolveStmt = `
+		UPDATE host_vulnerabilities hv
+		SET hv.resolved_at = ?
+		WHERE hv.host_id > ? AND hv.host_id <= ?
+			AND hv.resolved_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM host_software hs
+				JOIN software_cve sc ON sc.software_id = hs.software_id
+				WHERE hs.host_id = hv.host_id AND sc.cve = hv.cve
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM host_operating_system hos
+				JOIN operating_system_vulnerabilities osv ON osv.operating_system_id = hos.os_id
+				WHERE hos.host_id = hv.host_id AND osv.cve = hv.cve
+			)`
+
+	// host vulnerabilities are deleted along with their host, so the range of host IDs covers
+	// every row that may need to be resolved.
+	var minMax struct {
+		Min uint `db:"min"`
+		Max uint `db:"max"`
+	}
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &minMax,
+		`SELECT COALESCE(MIN(id), 1) AS min, COALESCE(MAX(id), 0) AS max FROM hosts`); err != nil {
+		return ctxerr.Wrap(ctx, err, "get min/max host id")
+	}
+
+	for minHostID, maxHostID := minMax.Min-1, minMax.Min-1+syncHostVulnerabilitiesBatchSize; minHostID < minMax.Max; minHostID, maxHostID = maxHostID, maxHostID+syncHostVulnerabilitiesBatchSize {
+		err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+			if _, err := tx.ExecContext(ctx, insertStmt, now, minHostID, maxHostID, minHostID, maxHostID); err != nil {
+				return ctxerr.Wrap(ctx, err, "insert detected host vulnerabilities")
+			}
+			if _, err := tx.ExecContext(ctx, resolveStmt, now, minHostID, maxHostID); err != nil {
+				return ctxerr.Wrap(ctx, err, "resolve host vulnerabilities")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hostVulnerabilitySuppressedCondition is true if the host vulnerability is suppressed globally,
+// for the host's team or for the host. Suppressions of a software title are not considered, as
+// the CVE may affect other software of the host.
+var hostVulnerabilitySuppressedCondition = vulnerabilitySuppressedCondition("hv.cve", "h.team_id", "hv.host_id", "NULL")
+
+// hostVulnerabilitiesTeamFilter returns the condition filtering the host vulnerabilities of the
+// team's hosts, 0 meaning the hosts with no team and nil all hosts.
+func hostVulnerabilitiesTeamFilter(teamID *uint) (string, []interface{}) {
+	switch {
+	case teamID == nil:
+		return "TRUE", nil
+	case *teamID == 0:
+		return "h.team_id IS NULL", nil
+	default:
+		return "h.team_id = ?", []interface{}{*teamID}
+	}
+}
+
+func (ds *Datastore) ListOpenHostVulnerabilities(ctx context.Context, opts mdmlab.HostVulnerabilityListOptions) ([]*mdmlab.HostVulnerability, error) {
+	stmt := `
+		SELECT
+			hv.id, hv.host_id, COALESCE(hdn.display_name, '') AS host_display_name, h.team_id, hv.cve,
+			hv.first_detected_at, hv.resolved_at, cm.cvss_score, cm.epss_probability, cm.cisa_known_exploit
+		FROM host_vulnerabilities hv
+		JOIN hosts h ON h.id = hv.host_id
+		LEFT JOIN host_display_names hdn ON hdn.host_id = hv.host_id
+		LEFT JOIN cve_meta cm ON cm.cve = hv.cve
+		WHERE hv.resolved_at IS NULL AND NOT ` + hostVulnerabilitySuppressedCondition
+
+	teamFilter, args := hostVulnerabilitiesTeamFilter(opts.TeamID)
+	where := []string{teamFilter}
+	if !opts.DetectedBefore.IsZero() {
+		where = append(where, "hv.first_detected_at < ?")
+		args = append(args, opts.DetectedBefore)
+	}
+	if opts.SLABreachNotNotified {
+		where = append(where, "hv.sla_breach_notified_at IS NULL")
+	}
+	stmt += " AND " + strings.Join(where, " AND ") + " ORDER BY hv.first_detected_at, hv.id"
+
+	var vulns []*mdmlab.HostVulnerability
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &vulns, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list open host vulnerabilities")
+	}
+	return vulns, nil
+}
+
+func (ds *Datastore) SetHostVulnerabilitiesSLABreachNotified(ctx context.Context, ids []uint, notifiedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	stmt, args, err := sqlx.In(`UPDATE host_vulnerabilities SET sla_breach_notified_at = ? WHERE id IN (?)`, notifiedAt, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build set host vulnerabilities notified query")
+	}
+	if _, err := ds.writer(ctx).ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host vulnerabilities SLA breach notified")
+	}
+	return nil
+}
+
+// vulnerabilitySLARuleExprs returns the SQL expressions evaluating to the index in rules and the
+// number of days of the strictest SLA rule matching a host vulnerability, or NULL if no rule
+// matches, given the CVE metadata aliased as cm. It mirrors mdmlab.MatchVulnerabilitySLARule. The
+// criteria of the rules are validated numbers, so they are formatted in the expressions.
+func vulnerabilitySLARuleExprs(rules []mdmlab.VulnerabilitySLARule) (indexExpr, daysExpr string) {
+	// the first matching rule by ascending days is the strictest one, ties are resolved by the
+	// order of the rules.
+	order := make([]int, len(rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rules[order[a]].Days < rules[order[b]].Days })
+
+	var index, days strings.Builder
+	index.WriteString("CASE")
+	days.WriteString("CASE")
+	for _, i := range order {
+		r := rules[i]
+		conds := []string{"TRUE"}
+		if r.MinCVSSScore != nil {
+			conds = append(conds, "cm.cvss_score >= "+strconv.FormatFloat(*r.MinCVSSScore, 'f', -1, 64))
+		}
+		if r.MinEPSSProbability != nil {
+			conds = append(conds, "cm.epss_probability >= "+strconv.FormatFloat(*r.MinEPSSProbability, 'f', -1, 64))
+		}
+		if r.CISAKnownExploit != nil {
+			known := 0
+			if *r.CISAKnownExploit {
+				known = 1
+			}
+			conds = append(conds, fmt.Sprintf("COALESCE(cm.cisa_known_exploit, 0) = %d", known))
+		}
+		cond := strings.Join(conds, " AND ")
+		fmt.Fprintf(&index, " WHEN %s THEN %d", cond, i)
+		fmt.Fprintf(&days, " WHEN %s THEN %d", cond, r.Days)
+	}
+	index.WriteString(" END")
+	days.WriteString(" END")
+	return index.String(), days.String()
+}
+
+// overdueHostVulnerabilitiesStmt returns the statement selecting the unresolved host
+// vulnerabilities of the team past the due date of their SLA rule at now, along with the index of
+// the rule and the due date, and its arguments. The rules must not be empty.
+func overdueHostVulnerabilitiesStmt(teamID *uint, rules []mdmlab.VulnerabilitySLARule, now time.Time) (string, []interface{}) {
+	indexExpr, daysExpr := vulnerabilitySLARuleExprs(rules)
+	teamFilter, args := hostVulnerabilitiesTeamFilter(teamID)
+	detectedBefore, _ := mdmlab.VulnerabilitySLAOverdueDetectedBefore(rules, now)
+
+	stmt := `
+		SELECT v.*, DATE_ADD(v.first_detected_at, INTERVAL v.sla_days DAY) AS due_at
+		FROM (
+			SELECT
+				hv.id, hv.host_id, COALESCE(hdn.display_name, '') AS host_display_name, h.team_id, hv.cve,
+				hv.first_detected_at, hv.resolved_at, cm.cvss_score, cm.epss_probability, cm.cisa_known_exploit,
+				` + indexExpr + ` AS sla_rule_index,
+				` + daysExpr + ` AS sla_days
+			FROM host_vulnerabilities hv
+			JOIN hosts h ON h.id = hv.host_id
+			LEFT JOIN host_display_names hdn ON hdn.host_id = hv.host_id
+			LEFT JOIN cve_meta cm ON cm.cve = hv.cve
+			WHERE hv.resolved_at IS NULL AND NOT ` + hostVulnerabilitySuppressedCondition + `
+				AND ` + teamFilter + `
+				AND hv.first_detected_at < ?
+		) v
+		WHERE v.sla_days IS NOT NULL AND DATE_ADD(v.first_detected_at, INTERVAL v.sla_days DAY) < ?`
+	return stmt, append(args, detectedBefore, now)
+}
+
+func (ds *Datastore) ListOverdueHostVulnerabilities(ctx context.Context, opts mdmlab.OverdueHostVulnerabilityListOptions) ([]*mdmlab.OverdueHostVulnerability, *mdmlab.PaginationMetadata, error) {
+	if len(opts.Rules) == 0 {
+		return nil, &mdmlab.PaginationMetadata{}, nil
+	}
+
+	stmt, args := overdueHostVulnerabilitiesStmt(opts.TeamID, opts.Rules, opts.Now)
+
+	var count uint
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &count, `SELECT COUNT(*) FROM (`+stmt+`) overdue`, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "count overdue host vulnerabilities")
+	}
+
+	listOpts := opts.ListOptions
+	if listOpts.OrderKey == "" {
+		listOpts.OrderKey = "first_detected_at"
+	}
+	// cursor-based pagination is not supported, the statement already has a WHERE clause
+	listOpts.After = ""
+	stmt = `SELECT * FROM (` + stmt + `) overdue`
+	stmt, args = appendListOptionsWithCursorToSQL(stmt, args, &listOpts)
+
+	var rows []struct {
+		mdmlab.HostVulnerability
+		SLARuleIndex int       `db:"sla_rule_index"`
+		SLADays      int       `db:"sla_days"`
+		DueAt        time.Time `db:"due_at"`
+	}
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &rows, stmt, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list overdue host vulnerabilities")
+	}
+
+	meta := &mdmlab.PaginationMetadata{HasPreviousResults: listOpts.Page > 0, TotalResults: count}
+	if listOpts.IncludeMetadata && len(rows) > int(listOpts.PerPage) { //nolint:gosec // dismiss G115
+		meta.HasNextResults = true
+		rows = rows[:len(rows)-1]
+	}
+
+	vulns := make([]*mdmlab.OverdueHostVulnerability, 0, len(rows))
+	for _, r := range rows {
+		vulns = append(vulns, &mdmlab.OverdueHostVulnerability{
+			HostVulnerability: r.HostVulnerability,
+			SLARule:           opts.Rules[r.SLARuleIndex].Name,
+			DueAt:             r.DueAt,
+		})
+	}
+	return vulns, meta, nil
+}
+
+func (ds *Datastore) VulnerabilityRemediationMetrics(ctx context.Context, teamID *uint, rules []mdmlab.VulnerabilitySLARule, now time.Time) (*mdmlab.VulnerabilityRemediationMetrics, error) {
+	teamFilter, args := hostVulnerabilitiesTeamFilter(teamID)
+	stmt := `
+		SELECT
+			COALESCE(SUM(hv.resolved_at IS NULL AND NOT ` + hostVulnerabilitySuppressedCondition + `), 0) AS open_count,
+			COUNT(hv.resolved_at) AS resolved_count,
+			COALESCE(AVG(TIMESTAMPDIFF(SECOND, hv.first_detected_at, hv.resolved_at)), 0) AS mean_time_to_remediate
+		FROM host_vulnerabilities hv
+		JOIN hosts h ON h.id = hv.host_id
+		WHERE ` + teamFilter
+
+	var metrics mdmlab.VulnerabilityRemediationMetrics
+	if err := sqlx.GetContext(ctx, ds.reader(ctx), &metrics, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability remediation metrics")
+	}
+	metrics.TeamID = teamID
+
+	if len(rules) > 0 {
+		overdueStmt, overdueArgs := overdueHostVulnerabilitiesStmt(teamID, rules, now)
+		if err := sqlx.GetContext(ctx, ds.reader(ctx), &metrics.OverdueCount,
+			`SELECT COUNT(*) FROM (`+overdueStmt+`) overdue`, overdueArgs...); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "count overdue host vulnerabilities")
+		}
+	}
+	return &metrics, nil
+}
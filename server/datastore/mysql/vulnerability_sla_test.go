@@ -0,0 +1,281 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilitySLA(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"SyncHostVulnerabilities", testSyncHostVulnerabilities},
+		{"ListOverdueHostVulnerabilities", testListOverdueHostVulnerabilities},
+		{"RemediationMetrics", testVulnerabilityRemediationMetrics},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testSyncHostVulnerabilities(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	// sync each host in its own batch
+	batchSizeOrig := syncHostVulnerabilitiesBatchSize
+	syncHostVulnerabilitiesBatchSize = 1
+	t.Cleanup(func() { syncHostVulnerabilitiesBatchSize = batchSizeOrig })
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host2.ID}))
+
+	software := []mdmlab.Software{{Name: "Chrome", Version: "1.0.0", Source: "apps"}}
+	_, err = ds.UpdateHostSoftware(ctx, host1.ID, software)
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, host2.ID, software)
+	require.NoError(t, err)
+	require.NoError(t, ds.LoadHostSoftware(ctx, host1, false))
+	softwareID := host1.Software[0].ID
+
+	_, err = ds.InsertSoftwareVulnerability(ctx, mdmlab.SoftwareVulnerability{SoftwareID: softwareID, CVE: "CVE-2024-0001"}, mdmlab.NVDSource)
+	require.NoError(t, err)
+	require.NoError(t, ds.InsertCVEMeta(ctx, []mdmlab.CVEMeta{
+		{CVE: "CVE-2024-0001", CVSSScore: ptr.Float64(9.8), CISAKnownExploit: ptr.Bool(true)},
+	}))
+
+	day1 := time.Now().UTC().Add(-10 * 24 * time.Hour).Truncate(time.Second)
+	require.NoError(t, ds.SyncHostVulnerabilities(ctx, day1))
+
+	vulns, err := ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{})
+	require.NoError(t, err)
+	require.Len(t, vulns, 2)
+	for _, v := range vulns {
+		require.Equal(t, "CVE-2024-0001", v.CVE)
+		require.Equal(t, day1, v.FirstDetectedAt.UTC())
+		require.Equal(t, ptr.Float64(9.8), v.CVSSScore)
+		require.Equal(t, ptr.Bool(true), v.CISAKnownExploit)
+	}
+
+	// filter by team and detection time
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{TeamID: &team.ID})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, host2.ID, vulns[0].HostID)
+	require.Equal(t, &team.ID, vulns[0].TeamID)
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{TeamID: ptr.Uint(0)})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, host1.ID, vulns[0].HostID)
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{DetectedBefore: day1})
+	require.NoError(t, err)
+	require.Empty(t, vulns)
+
+	// syncing again keeps the first detection time
+	day2 := day1.Add(24 * time.Hour)
+	require.NoError(t, ds.SyncHostVulnerabilities(ctx, day2))
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{SLABreachNotNotified: true})
+	require.NoError(t, err)
+	require.Len(t, vulns, 2)
+	require.Equal(t, day1, vulns[0].FirstDetectedAt.UTC())
+
+	require.NoError(t, ds.SetHostVulnerabilitiesSLABreachNotified(ctx, []uint{vulns[0].ID, vulns[1].ID}, day2))
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{SLABreachNotNotified: true})
+	require.NoError(t, err)
+	require.Empty(t, vulns)
+
+	// the software is removed from host1, its vulnerability is resolved
+	_, err = ds.UpdateHostSoftware(ctx, host1.ID, nil)
+	require.NoError(t, err)
+	day3 := day2.Add(24 * time.Hour)
+	require.NoError(t, ds.SyncHostVulnerabilities(ctx, day3))
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, host2.ID, vulns[0].HostID)
+
+	// it is installed again, the vulnerability is a new detection that was not notified yet
+	_, err = ds.UpdateHostSoftware(ctx, host1.ID, software)
+	require.NoError(t, err)
+	day4 := day3.Add(24 * time.Hour)
+	require.NoError(t, ds.SyncHostVulnerabilities(ctx, day4))
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{SLABreachNotNotified: true})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, host1.ID, vulns[0].HostID)
+	require.Equal(t, day4, vulns[0].FirstDetectedAt.UTC())
+
+	// suppressed vulnerabilities are not listed
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0001", TeamID: &team.ID, Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+	vulns, err = ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, host1.ID, vulns[0].HostID)
+}
+
+func testListOverdueHostVulnerabilities(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host2.ID}))
+
+	require.NoError(t, ds.InsertCVEMeta(ctx, []mdmlab.CVEMeta{
+		{CVE: "CVE-2024-0001", CVSSScore: ptr.Float64(9.8)},
+		{CVE: "CVE-2024-0002", CVSSScore: ptr.Float64(5), CISAKnownExploit: ptr.Bool(true)},
+		{CVE: "CVE-2024-0003", CVSSScore: ptr.Float64(2)},
+	}))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = ds.writer(ctx).ExecContext(ctx, `
+		INSERT INTO host_vulnerabilities (host_id, cve, first_detected_at, resolved_at) VALUES
+			(?, 'CVE-2024-0001', ?, NULL),
+			(?, 'CVE-2024-0002', ?, NULL),
+			(?, 'CVE-2024-0003', ?, NULL),
+			(?, 'CVE-2024-0001', ?, NULL),
+			(?, 'CVE-2024-0002', ?, ?)`,
+		host1.ID, now.Add(-10*24*time.Hour),
+		host1.ID, now.Add(-20*24*time.Hour),
+		host1.ID, now.Add(-100*24*time.Hour),
+		host2.ID, now.Add(-2*24*time.Hour),
+		host2.ID, now.Add(-60*24*time.Hour), now,
+	)
+	require.NoError(t, err)
+
+	// no rules, nothing is overdue
+	vulns, meta, err := ds.ListOverdueHostVulnerabilities(ctx, mdmlab.OverdueHostVulnerabilityListOptions{Now: now})
+	require.NoError(t, err)
+	require.Empty(t, vulns)
+	require.Zero(t, meta.TotalResults)
+
+	// CVE-2024-0003 matches no rule, CVE-2024-0002 matches both rules and the strictest one
+	// applies, the CVE-2024-0001 of host2 is not overdue yet.
+	rules := []mdmlab.VulnerabilitySLARule{
+		{Name: "Exploited", Days: 30, CISAKnownExploit: ptr.Bool(true)},
+		{Name: "High", Days: 7, MinCVSSScore: ptr.Float64(5)},
+	}
+	vulns, meta, err = ds.ListOverdueHostVulnerabilities(ctx, mdmlab.OverdueHostVulnerabilityListOptions{
+		ListOptions: mdmlab.ListOptions{IncludeMetadata: true, PerPage: 10},
+		Rules:       rules,
+		Now:         now,
+	})
+	require.NoError(t, err)
+	require.Len(t, vulns, 2)
+	require.Equal(t, &mdmlab.PaginationMetadata{TotalResults: 2}, meta)
+	require.Equal(t, "CVE-2024-0002", vulns[0].CVE)
+	require.Equal(t, "High", vulns[0].SLARule)
+	require.Equal(t, now.Add(-13*24*time.Hour), vulns[0].DueAt.UTC())
+	require.Equal(t, "CVE-2024-0001", vulns[1].CVE)
+	require.Equal(t, host1.ID, vulns[1].HostID)
+	require.Equal(t, "High", vulns[1].SLARule)
+	require.Equal(t, now.Add(-3*24*time.Hour), vulns[1].DueAt.UTC())
+
+	// paginate
+	vulns, meta, err = ds.ListOverdueHostVulnerabilities(ctx, mdmlab.OverdueHostVulnerabilityListOptions{
+		ListOptions: mdmlab.ListOptions{IncludeMetadata: true, PerPage: 1},
+		Rules:       rules,
+		Now:         now,
+	})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, "CVE-2024-0002", vulns[0].CVE)
+	require.Equal(t, &mdmlab.PaginationMetadata{HasNextResults: true, TotalResults: 2}, meta)
+	vulns, meta, err = ds.ListOverdueHostVulnerabilities(ctx, mdmlab.OverdueHostVulnerabilityListOptions{
+		ListOptions: mdmlab.ListOptions{IncludeMetadata: true, PerPage: 1, Page: 1},
+		Rules:       rules,
+		Now:         now,
+	})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, "CVE-2024-0001", vulns[0].CVE)
+	require.Equal(t, &mdmlab.PaginationMetadata{HasPreviousResults: true, TotalResults: 2}, meta)
+
+	// later on, the vulnerability of host2 is overdue too, but it is the only one of the team
+	vulns, meta, err = ds.ListOverdueHostVulnerabilities(ctx, mdmlab.OverdueHostVulnerabilityListOptions{
+		ListOptions: mdmlab.ListOptions{IncludeMetadata: true, PerPage: 10},
+		TeamID:      &team.ID,
+		Rules:       rules,
+		Now:         now.Add(10 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Equal(t, host2.ID, vulns[0].HostID)
+	require.Equal(t, uint(1), meta.TotalResults)
+}
+
+func testVulnerabilityRemediationMetrics(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host2.ID}))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = ds.writer(ctx).ExecContext(ctx, `
+		INSERT INTO host_vulnerabilities (host_id, cve, first_detected_at, resolved_at) VALUES
+			(?, 'CVE-2024-0001', ?, ?),
+			(?, 'CVE-2024-0002', ?, ?),
+			(?, 'CVE-2024-0003', ?, NULL),
+			(?, 'CVE-2024-0001', ?, NULL)`,
+		host1.ID, now.Add(-48*time.Hour), now,
+		host1.ID, now.Add(-24*time.Hour), now,
+		host1.ID, now,
+		host2.ID, now,
+	)
+	require.NoError(t, err)
+
+	metrics, err := ds.VulnerabilityRemediationMetrics(ctx, nil, nil, now)
+	require.NoError(t, err)
+	require.Nil(t, metrics.TeamID)
+	require.Equal(t, 2, metrics.OpenCount)
+	require.Equal(t, 2, metrics.ResolvedCount)
+	require.Equal(t, float64(36*60*60), metrics.MeanTimeToRemediate)
+
+	metrics, err = ds.VulnerabilityRemediationMetrics(ctx, ptr.Uint(0), nil, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.OpenCount)
+	require.Equal(t, 2, metrics.ResolvedCount)
+
+	metrics, err = ds.VulnerabilityRemediationMetrics(ctx, &team.ID, nil, now)
+	require.NoError(t, err)
+	require.Equal(t, &team.ID, metrics.TeamID)
+	require.Equal(t, 1, metrics.OpenCount)
+	require.Zero(t, metrics.OverdueCount)
+	require.Zero(t, metrics.ResolvedCount)
+	require.Zero(t, metrics.MeanTimeToRemediate)
+
+	// with a SLA rule, the open vulnerabilities are overdue a day after their detection
+	rules := []mdmlab.VulnerabilitySLARule{{Name: "All", Days: 1}}
+	metrics, err = ds.VulnerabilityRemediationMetrics(ctx, nil, rules, now.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.OpenCount)
+	require.Equal(t, 2, metrics.OverdueCount)
+	metrics, err = ds.VulnerabilityRemediationMetrics(ctx, &team.ID, rules, now.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.OverdueCount)
+	metrics, err = ds.VulnerabilityRemediationMetrics(ctx, nil, rules, now)
+	require.NoError(t, err)
+	require.Zero(t, metrics.OverdueCount)
+}
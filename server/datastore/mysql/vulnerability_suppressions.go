@@ -0,0 +1,224 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/jmoiron/sqlx"
+)
+
+// vulnerabilitySuppressedCondition returns an SQL condition that is true if the CVE is suppressed
+// by an active suppression for the host, given the columns (or SQL expressions) containing the
+// CVE, the host's team id, the host id and the software title id.
+func vulnerabilitySuppressedCondition(cveCol, teamIDCol, hostIDCol, titleIDCol string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM vulnerability_suppressions vsup
+		WHERE vsup.cve = %s
+			AND (vsup.expires_at IS NULL OR vsup.expires_at > NOW())
+			AND (vsup.team_id IS NULL OR vsup.team_id = COALESCE(%s, 0))
+			AND (vsup.host_id IS NULL OR vsup.host_id = %s)
+			AND (vsup.software_title_id IS NULL OR vsup.software_title_id = %s)
+	)`, cveCol, teamIDCol, hostIDCol, titleIDCol)
+}
+
+const selectVulnerabilitySuppressionsStmt = `
+	SELECT
+		vs.id, vs.cve, vs.team_id, vs.software_title_id, vs.host_id, vs.justification, vs.comment,
+		vs.author_id, vs.expires_at, vs.created_at, vs.updated_at
+	FROM vulnerability_suppressions vs`
+
+func (ds *Datastore) NewVulnerabilitySuppression(ctx context.Context, suppression *mdmlab.VulnerabilitySuppression) (*mdmlab.VulnerabilitySuppression, error) {
+	const stmt = `
+		INSERT INTO vulnerability_suppressions
+			(cve, scope_key, team_id, software_title_id, host_id, justification, comment, author_id, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := ds.writer(ctx).ExecContext(ctx, stmt,
+		suppression.CVE, suppression.ScopeKey(), suppression.TeamID, suppression.SoftwareTitleID, suppression.HostID,
+		suppression.Justification, suppression.Comment, suppression.AuthorID, suppression.ExpiresAt)
+	if err != nil {
+		if IsDuplicate(err) {
+			return nil, ctxerr.Wrap(ctx, alreadyExists("VulnerabilitySuppression",
+				fmt.Sprintf("%s (%s)", suppression.CVE, suppression.ScopeKey())))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "insert vulnerability suppression")
+	}
+	id, _ := res.LastInsertId()
+	return ds.vulnerabilitySuppressionDB(ctx, ds.writer(ctx), uint(id)) //nolint:gosec // dismiss G115
+}
+
+func (ds *Datastore) VulnerabilitySuppression(ctx context.Context, id uint) (*mdmlab.VulnerabilitySuppression, error) {
+	return ds.vulnerabilitySuppressionDB(ctx, ds.reader(ctx), id)
+}
+
+func (ds *Datastore) vulnerabilitySuppressionDB(ctx context.Context, q sqlx.QueryerContext, id uint) (*mdmlab.VulnerabilitySuppression, error) {
+	var suppression mdmlab.VulnerabilitySuppression
+	if err := sqlx.GetContext(ctx, q, &suppression, selectVulnerabilitySuppressionsStmt+` WHERE vs.id = ?`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ctxerr.Wrap(ctx, notFound("VulnerabilitySuppression").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability suppression")
+	}
+	return &suppression, nil
+}
+
+func (ds *Datastore) ListVulnerabilitySuppressions(ctx context.Context, opts mdmlab.ListVulnerabilitySuppressionsOptions) ([]*mdmlab.VulnerabilitySuppression, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	if opts.CVE != "" {
+		where = append(where, `vs.cve = ?`)
+		args = append(args, opts.CVE)
+	}
+	if !opts.IncludeExpired {
+		where = append(where, `(vs.expires_at IS NULL OR vs.expires_at > NOW())`)
+	}
+	if opts.TeamID != nil {
+		where = append(where, `(
+			(vs.team_id IS NULL AND vs.host_id IS NULL) OR
+			vs.team_id = ? OR
+			vs.host_id IN (SELECT id FROM hosts WHERE COALESCE(team_id, 0) = ?)
+		)`)
+		args = append(args, *opts.TeamID, *opts.TeamID)
+	}
+
+	stmt := selectVulnerabilitySuppressionsStmt
+	if len(where) > 0 {
+		stmt += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	stmt += ` ORDER BY vs.cve, vs.id`
+
+	var suppressions []*mdmlab.VulnerabilitySuppression
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &suppressions, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability suppressions")
+	}
+	return suppressions, nil
+}
+
+func (ds *Datastore) DeleteVulnerabilitySuppression(ctx context.Context, id uint) error {
+	res, err := ds.writer(ctx).ExecContext(ctx, `DELETE FROM vulnerability_suppressions WHERE id = ?`, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete vulnerability suppression")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("VulnerabilitySuppression").WithID(id))
+	}
+	return nil
+}
+
+func (ds *Datastore) UpsertVulnerabilitySuppressions(ctx context.Context, suppressions []*mdmlab.VulnerabilitySuppression) error {
+	if len(suppressions) == 0 {
+		return nil
+	}
+
+	const stmt = `
+		INSERT INTO vulnerability_suppressions
+			(cve, scope_key, team_id, software_title_id, host_id, justification, comment, author_id, expires_at)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+			justification = VALUES(justification),
+			comment = VALUES(comment),
+			author_id = VALUES(author_id),
+			expires_at = VALUES(expires_at)`
+
+	const batchSize = 500
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		for i := 0; i < len(suppressions); i += batchSize {
+			end := i + batchSize
+			if end > len(suppressions) {
+				end = len(suppressions)
+			}
+			batch := suppressions[i:end]
+
+			args := make([]interface{}, 0, len(batch)*9)
+			for _, s := range batch {
+				args = append(args, s.CVE, s.ScopeKey(), s.TeamID, s.SoftwareTitleID, s.HostID,
+					s.Justification, s.Comment, s.AuthorID, s.ExpiresAt)
+			}
+			values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?, ?, ?, ?),", len(batch)), ",")
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(stmt, values), args...); err != nil {
+				return ctxerr.Wrap(ctx, err, "upsert vulnerability suppressions")
+			}
+		}
+		return nil
+	})
+}
+
+func (ds *Datastore) FilterSuppressedSoftwareVulnerabilities(ctx context.Context, vulns []mdmlab.SoftwareVulnerability) ([]mdmlab.SoftwareVulnerability, error) {
+	if len(vulns) == 0 {
+		return vulns, nil
+	}
+
+	var suppressedCVEs []string
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &suppressedCVEs, `
+		SELECT DISTINCT cve FROM vulnerability_suppressions
+		WHERE expires_at IS NULL OR expires_at > NOW()`); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list suppressed cves")
+	}
+	suppressed := make(map[string]struct{}, len(suppressedCVEs))
+	for _, cve := range suppressedCVEs {
+		suppressed[cve] = struct{}{}
+	}
+
+	// only the vulnerabilities of suppressed CVEs need to be checked against the hosts
+	var (
+		candidateCVEs        []string
+		candidateSoftwareIDs []uint
+		seenCVEs             = make(map[string]struct{})
+		seenSoftwareIDs      = make(map[uint]struct{})
+	)
+	for _, v := range vulns {
+		if _, ok := suppressed[v.CVE]; !ok {
+			continue
+		}
+		if _, ok := seenCVEs[v.CVE]; !ok {
+			seenCVEs[v.CVE] = struct{}{}
+			candidateCVEs = append(candidateCVEs, v.CVE)
+		}
+		if _, ok := seenSoftwareIDs[v.SoftwareID]; !ok {
+			seenSoftwareIDs[v.SoftwareID] = struct{}{}
+			candidateSoftwareIDs = append(candidateSoftwareIDs, v.SoftwareID)
+		}
+	}
+	if len(candidateCVEs) == 0 {
+		return vulns, nil
+	}
+
+	// a vulnerability is kept if at least one host with the software installed is not covered
+	// by a suppression.
+	stmt := `
+		SELECT DISTINCT sc.software_id, sc.cve
+		FROM software_cve sc
+		INNER JOIN host_software hs ON hs.software_id = sc.software_id
+		INNER JOIN software s ON s.id = sc.software_id
+		LEFT JOIN hosts h ON h.id = hs.host_id
+		WHERE sc.cve IN (?) AND sc.software_id IN (?) AND NOT ` +
+		vulnerabilitySuppressedCondition("sc.cve", "h.team_id", "hs.host_id", "s.title_id")
+	stmt, args, err := sqlx.In(stmt, candidateCVEs, candidateSoftwareIDs)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "build unsuppressed vulnerabilities query")
+	}
+	var unsuppressed []mdmlab.SoftwareVulnerability
+	if err := sqlx.SelectContext(ctx, ds.reader(ctx), &unsuppressed, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list unsuppressed vulnerabilities")
+	}
+	keep := make(map[string]struct{}, len(unsuppressed))
+	for _, v := range unsuppressed {
+		keep[v.Key()] = struct{}{}
+	}
+
+	filtered := make([]mdmlab.SoftwareVulnerability, 0, len(vulns))
+	for _, v := range vulns {
+		if _, ok := suppressed[v.CVE]; ok {
+			if _, ok := keep[v.Key()]; !ok {
+				continue
+			}
+		}
+		filtered = append(filtered, v)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,266 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilitySuppressions(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CreateListDelete", testVulnerabilitySuppressionsCreateListDelete},
+		{"Upsert", testVulnerabilitySuppressionsUpsert},
+		{"HostCounts", testVulnerabilitySuppressionsHostCounts},
+		{"FilterSoftwareVulnerabilities", testVulnerabilitySuppressionsFilterSoftwareVulnerabilities},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testVulnerabilitySuppressionsCreateListDelete(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host.ID}))
+
+	global, err := ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-0001",
+		Justification: mdmlab.VEXJustificationVulnerableCodeNotInExecutePath,
+		Comment:       "feature disabled",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, global.ID)
+	require.Equal(t, "feature disabled", global.Comment)
+	require.Nil(t, global.ExpiresAt)
+
+	// the same CVE cannot be suppressed twice for the same scope
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-0001",
+		Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	var existsErr mdmlab.AlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	hostSuppression, err := ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-0001",
+		HostID:        ptr.Uint(host.ID),
+		Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ptr.Uint(host.ID), hostSuppression.HostID)
+
+	otherTeam, err := ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-0002",
+		TeamID:        ptr.Uint(team.ID + 1),
+		Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+
+	expired, err := ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-0003",
+		Justification: mdmlab.VEXJustificationComponentNotPresent,
+		ExpiresAt:     ptr.Time(time.Now().Add(-time.Hour)),
+	})
+	require.NoError(t, err)
+
+	idsOf := func(suppressions []*mdmlab.VulnerabilitySuppression) []uint {
+		ids := make([]uint, 0, len(suppressions))
+		for _, s := range suppressions {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+
+	list, err := ds.ListVulnerabilitySuppressions(ctx, mdmlab.ListVulnerabilitySuppressionsOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []uint{global.ID, hostSuppression.ID, otherTeam.ID}, idsOf(list))
+
+	list, err = ds.ListVulnerabilitySuppressions(ctx, mdmlab.ListVulnerabilitySuppressionsOptions{IncludeExpired: true})
+	require.NoError(t, err)
+	require.Equal(t, []uint{global.ID, hostSuppression.ID, otherTeam.ID, expired.ID}, idsOf(list))
+
+	list, err = ds.ListVulnerabilitySuppressions(ctx, mdmlab.ListVulnerabilitySuppressionsOptions{CVE: "CVE-2024-0002"})
+	require.NoError(t, err)
+	require.Equal(t, []uint{otherTeam.ID}, idsOf(list))
+
+	// the team filter includes the global suppressions and the ones of the team's hosts
+	list, err = ds.ListVulnerabilitySuppressions(ctx, mdmlab.ListVulnerabilitySuppressionsOptions{TeamID: &team.ID})
+	require.NoError(t, err)
+	require.Equal(t, []uint{global.ID, hostSuppression.ID}, idsOf(list))
+
+	got, err := ds.VulnerabilitySuppression(ctx, hostSuppression.ID)
+	require.NoError(t, err)
+	require.Equal(t, "CVE-2024-0001", got.CVE)
+
+	require.NoError(t, ds.DeleteVulnerabilitySuppression(ctx, hostSuppression.ID))
+	err = ds.DeleteVulnerabilitySuppression(ctx, hostSuppression.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+	_, err = ds.VulnerabilitySuppression(ctx, hostSuppression.ID)
+	require.True(t, mdmlab.IsNotFound(err))
+}
+
+func testVulnerabilitySuppressionsUpsert(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	_, err := ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE:           "CVE-2024-0001",
+		Justification: mdmlab.VEXJustificationComponentNotPresent,
+		ExpiresAt:     ptr.Time(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+
+	err = ds.UpsertVulnerabilitySuppressions(ctx, []*mdmlab.VulnerabilitySuppression{
+		{CVE: "CVE-2024-0001", Justification: mdmlab.VEXJustificationInlineMitigationsAlreadyExist, Comment: "waf"},
+		{CVE: "CVE-2024-0001", SoftwareTitleID: ptr.Uint(3), Justification: mdmlab.VEXJustificationVulnerableCodeNotPresent},
+	})
+	require.NoError(t, err)
+
+	list, err := ds.ListVulnerabilitySuppressions(ctx, mdmlab.ListVulnerabilitySuppressionsOptions{})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, mdmlab.VEXJustificationInlineMitigationsAlreadyExist, list[0].Justification)
+	require.Equal(t, "waf", list[0].Comment)
+	require.Nil(t, list[0].ExpiresAt)
+	require.Equal(t, ptr.Uint(3), list[1].SoftwareTitleID)
+
+	require.NoError(t, ds.UpsertVulnerabilitySuppressions(ctx, nil))
+}
+
+func testVulnerabilitySuppressionsHostCounts(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+	team, err := ds.NewTeam(ctx, &mdmlab.Team{Name: "team1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host2.ID}))
+
+	software := []mdmlab.Software{{Name: "Chrome", Version: "1.0.0", Source: "apps"}}
+	_, err = ds.UpdateHostSoftware(ctx, host1.ID, software)
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, host2.ID, software)
+	require.NoError(t, err)
+	require.NoError(t, ds.LoadHostSoftware(ctx, host1, false))
+	softwareID := host1.Software[0].ID
+
+	for _, cve := range []string{"CVE-2024-0001", "CVE-2024-0002"} {
+		_, err = ds.InsertSoftwareVulnerability(ctx, mdmlab.SoftwareVulnerability{SoftwareID: softwareID, CVE: cve}, mdmlab.NVDSource)
+		require.NoError(t, err)
+	}
+
+	assertCounts := func(teamID *uint, expected []hostCount) {
+		list, _, err := ds.ListVulnerabilities(ctx, mdmlab.VulnListOptions{TeamID: teamID})
+		require.NoError(t, err)
+		assertHostCounts(t, expected, list)
+	}
+
+	require.NoError(t, ds.UpdateVulnerabilityHostCounts(ctx, 5))
+	assertCounts(nil, []hostCount{{CVE: "CVE-2024-0001", HostCount: 2}, {CVE: "CVE-2024-0002", HostCount: 2}})
+
+	// suppress CVE-2024-0001 for the team and CVE-2024-0002 for host1
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0001", TeamID: &team.ID, Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0002", HostID: &host1.ID, Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ds.UpdateVulnerabilityHostCounts(ctx, 5))
+	assertCounts(nil, []hostCount{{CVE: "CVE-2024-0001", HostCount: 1}, {CVE: "CVE-2024-0002", HostCount: 1}})
+	assertCounts(&team.ID, []hostCount{{CVE: "CVE-2024-0002", HostCount: 1}})
+	assertCounts(ptr.Uint(0), []hostCount{{CVE: "CVE-2024-0001", HostCount: 1}})
+
+	// a global suppression removes the CVE from all counts
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0001", Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.UpdateVulnerabilityHostCounts(ctx, 5))
+	assertCounts(nil, []hostCount{{CVE: "CVE-2024-0002", HostCount: 1}})
+
+	// the software inventory still reports the suppressed vulnerabilities
+	require.NoError(t, ds.LoadHostSoftware(ctx, host2, false))
+	require.Len(t, host2.Software[0].Vulnerabilities, 2)
+}
+
+func testVulnerabilitySuppressionsFilterSoftwareVulnerabilities(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host1 := test.NewHost(t, ds, "host1", "", "h1key", "h1uuid", time.Now())
+	host2 := test.NewHost(t, ds, "host2", "", "h2key", "h2uuid", time.Now())
+
+	_, err := ds.UpdateHostSoftware(ctx, host1.ID, []mdmlab.Software{{Name: "Chrome", Version: "1.0.0", Source: "apps"}})
+	require.NoError(t, err)
+	_, err = ds.UpdateHostSoftware(ctx, host2.ID, []mdmlab.Software{
+		{Name: "Chrome", Version: "1.0.0", Source: "apps"},
+		{Name: "Firefox", Version: "2.0.0", Source: "apps"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.LoadHostSoftware(ctx, host2, false))
+	softwareIDs := make(map[string]uint)
+	for _, s := range host2.Software {
+		softwareIDs[s.Name] = s.ID
+	}
+
+	vulns := []mdmlab.SoftwareVulnerability{
+		{SoftwareID: softwareIDs["Chrome"], CVE: "CVE-2024-0001"},
+		{SoftwareID: softwareIDs["Firefox"], CVE: "CVE-2024-0001"},
+		{SoftwareID: softwareIDs["Chrome"], CVE: "CVE-2024-0002"},
+	}
+	for _, v := range vulns {
+		_, err = ds.InsertSoftwareVulnerability(ctx, v, mdmlab.NVDSource)
+		require.NoError(t, err)
+	}
+
+	// no suppressions
+	filtered, err := ds.FilterSuppressedSoftwareVulnerabilities(ctx, vulns)
+	require.NoError(t, err)
+	require.Equal(t, vulns, filtered)
+
+	// CVE-2024-0001 is suppressed for host2 only, Chrome is still vulnerable on host1
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0001", HostID: &host2.ID, Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+	filtered, err = ds.FilterSuppressedSoftwareVulnerabilities(ctx, vulns)
+	require.NoError(t, err)
+	require.Equal(t, []mdmlab.SoftwareVulnerability{vulns[0], vulns[2]}, filtered)
+
+	// an expired suppression does not apply
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0002", Justification: mdmlab.VEXJustificationComponentNotPresent,
+		ExpiresAt: ptr.Time(time.Now().Add(-time.Minute)),
+	})
+	require.NoError(t, err)
+	filtered, err = ds.FilterSuppressedSoftwareVulnerabilities(ctx, vulns)
+	require.NoError(t, err)
+	require.Equal(t, []mdmlab.SoftwareVulnerability{vulns[0], vulns[2]}, filtered)
+
+	// a global suppression applies to all hosts
+	_, err = ds.NewVulnerabilitySuppression(ctx, &mdmlab.VulnerabilitySuppression{
+		CVE: "CVE-2024-0001", Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.NoError(t, err)
+	filtered, err = ds.FilterSuppressedSoftwareVulnerabilities(ctx, vulns)
+	require.NoError(t, err)
+	require.Equal(t, []mdmlab.SoftwareVulnerability{vulns[2]}, filtered)
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// SoftwareRequestMailer is used to build the email sent to the maintainers of
+// a team when an end user requests software from the My device page.
+type SoftwareRequestMailer struct {
+	*mdmlab.SoftwareRequest
+	BaseURL     template.URL
+	AssetURL    template.URL
+	CurrentYear int
+}
+
+func (m *SoftwareRequestMailer) Message() ([]byte, error) {
+	m.CurrentYear = time.Now().Year()
+	t, err := server.GetTemplate("server/mail/templates/software_request.html", "email_template")
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	if err = t.Execute(&msg, m); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
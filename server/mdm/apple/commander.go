@@ -362,6 +362,186 @@ func (svc *MDMAppleCommander) InstalledApplicationList(ctx context.Context, host
 	return svc.EnqueueCommand(ctx, hostUUIDs, raw)
 }
 
+type restartDevicePayload struct {
+	NotifyUser  bool `plist:",omitempty"`
+	RequestType string
+}
+
+// RestartDevice sends the homonym [command][1] to the given hosts. If
+// notifyUser is true, macOS hosts with a logged-in user display a prompt
+// instead of restarting immediately.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/restart_device
+func (svc *MDMAppleCommander) RestartDevice(ctx context.Context, hostUUIDs []string, uuid string, notifyUser bool) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, restartDevicePayload{
+		RequestType: "RestartDevice",
+		NotifyUser:  notifyUser,
+	})
+}
+
+type requestTypeOnlyPayload struct {
+	RequestType string
+}
+
+// ShutDownDevice sends the homonym [command][1] to the given hosts.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/shut_down_device
+func (svc *MDMAppleCommander) ShutDownDevice(ctx context.Context, hostUUIDs []string, uuid string) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, requestTypeOnlyPayload{RequestType: "ShutDownDevice"})
+}
+
+type enableLostModePayload struct {
+	Footnote    string `plist:",omitempty"`
+	Message     string `plist:",omitempty"`
+	PhoneNumber string `plist:",omitempty"`
+	RequestType string
+}
+
+// EnableLostMode sends the homonym [command][1] to the given host and records
+// it as the host's pending lost mode action.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/enable_lost_mode
+func (svc *MDMAppleCommander) EnableLostMode(ctx context.Context, host *mdmlab.Host, uuid string, opts mdmlab.MDMAppleLostModeOptions) error {
+	cmd, err := decodePayload(uuid, enableLostModePayload{
+		RequestType: "EnableLostMode",
+		Message:     opts.Message,
+		PhoneNumber: opts.PhoneNumber,
+		Footnote:    opts.Footnote,
+	})
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "decoding command")
+	}
+
+	if err := svc.storage.EnqueueEnableLostModeCommand(ctx, host, cmd); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing for EnableLostMode")
+	}
+
+	if err := svc.SendNotifications(ctx, []string{host.UUID}); err != nil {
+		return ctxerr.Wrap(ctx, err, "sending notifications for EnableLostMode")
+	}
+	return nil
+}
+
+// DisableLostMode sends the homonym [command][1] to the given host and records
+// it as the host's pending lost mode action.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/disable_lost_mode
+func (svc *MDMAppleCommander) DisableLostMode(ctx context.Context, host *mdmlab.Host, uuid string) error {
+	cmd, err := decodePayload(uuid, requestTypeOnlyPayload{RequestType: "DisableLostMode"})
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "decoding command")
+	}
+
+	if err := svc.storage.EnqueueDisableLostModeCommand(ctx, host, cmd); err != nil {
+		return ctxerr.Wrap(ctx, err, "enqueuing for DisableLostMode")
+	}
+
+	if err := svc.SendNotifications(ctx, []string{host.UUID}); err != nil {
+		return ctxerr.Wrap(ctx, err, "sending notifications for DisableLostMode")
+	}
+	return nil
+}
+
+// PlayLostModeSound sends the homonym [command][1] to the given hosts. It's
+// only accepted by devices in lost mode.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/play_lost_mode_sound
+func (svc *MDMAppleCommander) PlayLostModeSound(ctx context.Context, hostUUIDs []string, uuid string) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, requestTypeOnlyPayload{RequestType: "PlayLostModeSound"})
+}
+
+// DeviceLocation sends the homonym [command][1] to the given hosts. It's only
+// accepted by devices in lost mode.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/device_location
+func (svc *MDMAppleCommander) DeviceLocation(ctx context.Context, hostUUIDs []string, uuid string) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, requestTypeOnlyPayload{RequestType: "DeviceLocation"})
+}
+
+type setRecoveryLockPayload struct {
+	CurrentPassword string `plist:",omitempty"`
+	NewPassword     string
+	RequestType     string
+}
+
+// SetRecoveryLock sends the homonym [command][1] to the given hosts. An empty
+// newPassword clears the recovery lock, in which case currentPassword must be
+// provided.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/set_recovery_lock
+func (svc *MDMAppleCommander) SetRecoveryLock(ctx context.Context, hostUUIDs []string, uuid, currentPassword, newPassword string) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, setRecoveryLockPayload{
+		RequestType:     "SetRecoveryLock",
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	})
+}
+
+type setFirmwarePasswordPayload struct {
+	AllowOroms      bool   `plist:",omitempty"`
+	CurrentPassword string `plist:",omitempty"`
+	NewPassword     string
+	RequestType     string
+}
+
+// SetFirmwarePassword sends the homonym [command][1] to the given hosts. It's
+// only supported by Intel-based Macs, and the change is applied after the host
+// restarts.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/set_firmware_password
+func (svc *MDMAppleCommander) SetFirmwarePassword(ctx context.Context, hostUUIDs []string, uuid, currentPassword, newPassword string) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, setFirmwarePasswordPayload{
+		RequestType:     "SetFirmwarePassword",
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	})
+}
+
+// SecurityInfo sends the homonym [command][1] to the given hosts.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/security_info
+func (svc *MDMAppleCommander) SecurityInfo(ctx context.Context, hostUUIDs []string, uuid string) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, requestTypeOnlyPayload{RequestType: "SecurityInfo"})
+}
+
+type certificateListPayload struct {
+	ManagedOnly bool `plist:",omitempty"`
+	RequestType string
+}
+
+// CertificateList sends the homonym [command][1] to the given hosts. If
+// managedOnly is true, only the certificates installed by the MDM server are
+// listed.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/certificate_list
+func (svc *MDMAppleCommander) CertificateList(ctx context.Context, hostUUIDs []string, uuid string, managedOnly bool) error {
+	return svc.enqueuePayload(ctx, hostUUIDs, uuid, certificateListPayload{
+		RequestType: "CertificateList",
+		ManagedOnly: managedOnly,
+	})
+}
+
+// decodePayload marshals the typed command payload into a full MDM command
+// plist and decodes it back into a nanomdm command.
+func decodePayload(uuid string, payload any) (*mdm.Command, error) {
+	raw, err := plist.Marshal(commandPayload{
+		CommandUUID: uuid,
+		Command:     payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal command payload plist: %w", err)
+	}
+	return mdm.DecodeCommand(raw)
+}
+
+func (svc *MDMAppleCommander) enqueuePayload(ctx context.Context, hostUUIDs []string, uuid string, payload any) error {
+	cmd, err := decodePayload(uuid, payload)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "decoding command")
+	}
+	return svc.enqueueAndNotify(ctx, hostUUIDs, cmd, mdm.CommandSubtypeNone)
+}
+
 // EnqueueCommand takes care of enqueuing the commands and sending push
 // notifications to the devices.
 //
@@ -430,6 +610,13 @@ func (svc *MDMAppleCommander) BulkDeleteHostUserCommandsWithoutResults(ctx conte
 	return svc.storage.BulkDeleteHostUserCommandsWithoutResults(ctx, commandToIDs)
 }
 
+// CancelPendingCommands calls the storage method with the same name. The
+// commands whose delivery is tracked outside of the queue are never canceled,
+// see mdmlab.MDMAppleNonCancelableRequestTypes.
+func (svc *MDMAppleCommander) CancelPendingCommands(ctx context.Context, hostUUIDs []string, commandUUID string) (int64, error) {
+	return svc.storage.CancelPendingCommands(ctx, hostUUIDs, commandUUID, mdmlab.MDMAppleNonCancelableRequestTypes)
+}
+
 // APNSDeliveryError records an error and the associated host UUIDs in which it
 // occurred.
 type APNSDeliveryError struct {
@@ -159,6 +159,112 @@ func TestMDMAppleCommander(t *testing.T) {
 	mdmStorage.EnqueueDeviceWipeCommandFuncInvoked = false
 	require.True(t, mdmStorage.RetrievePushInfoFuncInvoked)
 	mdmStorage.RetrievePushInfoFuncInvoked = false
+
+	iosHost := &mdmlab.Host{ID: 2, UUID: "A", Platform: "ios"}
+	cmdUUID = uuid.New().String()
+	mdmStorage.EnqueueEnableLostModeCommandFunc = func(ctx context.Context, gotHost *mdmlab.Host, cmd *mdm.Command) error {
+		require.Equal(t, iosHost.ID, gotHost.ID)
+		require.Equal(t, "EnableLostMode", cmd.Command.RequestType)
+		require.Equal(t, cmdUUID, cmd.CommandUUID)
+		var fullCmd micromdm.CommandPayload
+		require.NoError(t, plist.Unmarshal(cmd.Raw, &fullCmd))
+		require.Equal(t, "Call me", fullCmd.Command.EnableLostMode.Message)
+		require.Equal(t, "555-1234", fullCmd.Command.EnableLostMode.PhoneNumber)
+		require.Empty(t, fullCmd.Command.EnableLostMode.Footnote)
+		return nil
+	}
+	err = cmdr.EnableLostMode(ctx, iosHost, cmdUUID, mdmlab.MDMAppleLostModeOptions{Message: "Call me", PhoneNumber: "555-1234"})
+	require.NoError(t, err)
+	require.True(t, mdmStorage.EnqueueEnableLostModeCommandFuncInvoked)
+	mdmStorage.EnqueueEnableLostModeCommandFuncInvoked = false
+	require.True(t, mdmStorage.RetrievePushInfoFuncInvoked)
+	mdmStorage.RetrievePushInfoFuncInvoked = false
+
+	cmdUUID = uuid.New().String()
+	mdmStorage.EnqueueDisableLostModeCommandFunc = func(ctx context.Context, gotHost *mdmlab.Host, cmd *mdm.Command) error {
+		require.Equal(t, iosHost.ID, gotHost.ID)
+		require.Equal(t, "DisableLostMode", cmd.Command.RequestType)
+		require.Equal(t, cmdUUID, cmd.CommandUUID)
+		return nil
+	}
+	err = cmdr.DisableLostMode(ctx, iosHost, cmdUUID)
+	require.NoError(t, err)
+	require.True(t, mdmStorage.EnqueueDisableLostModeCommandFuncInvoked)
+	mdmStorage.EnqueueDisableLostModeCommandFuncInvoked = false
+	require.True(t, mdmStorage.RetrievePushInfoFuncInvoked)
+	mdmStorage.RetrievePushInfoFuncInvoked = false
+
+	cases := []struct {
+		requestType string
+		enqueue     func(cmdUUID string) error
+		check       func(t *testing.T, raw []byte)
+	}{
+		{
+			"RestartDevice",
+			func(cmdUUID string) error { return cmdr.RestartDevice(ctx, hostUUIDs, cmdUUID, true) },
+			func(t *testing.T, raw []byte) { require.Contains(t, string(raw), "<key>NotifyUser</key>") },
+		},
+		{
+			"ShutDownDevice",
+			func(cmdUUID string) error { return cmdr.ShutDownDevice(ctx, hostUUIDs, cmdUUID) },
+			nil,
+		},
+		{
+			"PlayLostModeSound",
+			func(cmdUUID string) error { return cmdr.PlayLostModeSound(ctx, hostUUIDs, cmdUUID) },
+			nil,
+		},
+		{
+			"DeviceLocation",
+			func(cmdUUID string) error { return cmdr.DeviceLocation(ctx, hostUUIDs, cmdUUID) },
+			nil,
+		},
+		{
+			"SetRecoveryLock",
+			func(cmdUUID string) error { return cmdr.SetRecoveryLock(ctx, hostUUIDs, cmdUUID, "", "s3cr3t") },
+			func(t *testing.T, raw []byte) {
+				require.Contains(t, string(raw), "<string>s3cr3t</string>")
+				require.NotContains(t, string(raw), "CurrentPassword")
+			},
+		},
+		{
+			"SetFirmwarePassword",
+			func(cmdUUID string) error { return cmdr.SetFirmwarePassword(ctx, hostUUIDs, cmdUUID, "0ld", "n3w") },
+			func(t *testing.T, raw []byte) {
+				require.Contains(t, string(raw), "<key>CurrentPassword</key>")
+				require.Contains(t, string(raw), "<string>n3w</string>")
+				require.NotContains(t, string(raw), "AllowOroms")
+			},
+		},
+		{
+			"SecurityInfo",
+			func(cmdUUID string) error { return cmdr.SecurityInfo(ctx, hostUUIDs, cmdUUID) },
+			nil,
+		},
+		{
+			"CertificateList",
+			func(cmdUUID string) error { return cmdr.CertificateList(ctx, hostUUIDs, cmdUUID, true) },
+			func(t *testing.T, raw []byte) { require.Contains(t, string(raw), "<key>ManagedOnly</key>") },
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.requestType, func(t *testing.T) {
+			cmdUUID := uuid.New().String()
+			mdmStorage.EnqueueCommandFunc = func(ctx context.Context, id []string, cmd *mdm.CommandWithSubtype) (map[string]error, error) {
+				require.Equal(t, c.requestType, cmd.Command.Command.RequestType)
+				require.Equal(t, cmdUUID, cmd.CommandUUID)
+				if c.check != nil {
+					c.check(t, cmd.Raw)
+				}
+				return nil, nil
+			}
+			require.NoError(t, c.enqueue(cmdUUID))
+			require.True(t, mdmStorage.EnqueueCommandFuncInvoked)
+			mdmStorage.EnqueueCommandFuncInvoked = false
+			require.True(t, mdmStorage.RetrievePushInfoFuncInvoked)
+			mdmStorage.RetrievePushInfoFuncInvoked = false
+		})
+	}
 }
 
 func newMockAPNSPushProviderFactory() (*svcmock.APNSPushProviderFactory, *svcmock.APNSPushProvider) {
@@ -9,6 +9,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math"
+	"math/big"
 	"net/url"
 	"path"
 	"strings"
@@ -76,6 +77,26 @@ func GenerateRandomPin(length int) string {
 	return fmt.Sprintf(f, v)
 }
 
+// recoveryLockAlphabet excludes characters that are easily confused when read
+// from the screen (0/O, 1/l/I).
+const recoveryLockAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GenerateRecoveryLockPassword generates a random password of the given length
+// suitable for a recovery lock or firmware password, which only accept ASCII
+// characters.
+func GenerateRecoveryLockPassword(length int) (string, error) {
+	buf := make([]byte, length)
+	max := big.NewInt(int64(len(recoveryLockAlphabet)))
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("generate random index: %w", err)
+		}
+		buf[i] = recoveryLockAlphabet[n.Int64()]
+	}
+	return string(buf), nil
+}
+
 // FmtErrorChain formats Command error message for macOS MDM v1
 func FmtErrorChain(chain []mdm.ErrorChain) string {
 	var sb strings.Builder
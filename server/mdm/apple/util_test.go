@@ -36,3 +36,17 @@ func TestMDMAppleEnrollURL(t *testing.T) {
 		require.Equal(t, tt.expectedURL, enrollURL)
 	}
 }
+
+func TestGenerateRecoveryLockPassword(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		pw, err := GenerateRecoveryLockPassword(20)
+		require.NoError(t, err)
+		require.Len(t, pw, 20)
+		for _, c := range pw {
+			require.Contains(t, recoveryLockAlphabet, string(c))
+		}
+		require.False(t, seen[pw])
+		seen[pw] = true
+	}
+}
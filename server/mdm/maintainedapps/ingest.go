@@ -30,6 +30,27 @@ type maintainedApp struct {
 	InstallerFormat      string   `json:"installer_format"`
 	PreUninstallScripts  []string `json:"pre_uninstall_scripts"`
 	PostUninstallScripts []string `json:"post_uninstall_scripts"`
+	AutomaticPolicyQuery string   `json:"automatic_policy_query"`
+
+	// Platform is empty for macOS apps, whose metadata comes from the brew
+	// API. Linux apps are downloaded from their vendor and their metadata is
+	// extracted from the package, the fields below are only used for them.
+	Platform string `json:"platform"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	// PackageName is the name of the deb or rpm package.
+	PackageName string `json:"package_name"`
+	// VersionRegex extracts the version of tarballs from their download URL
+	// (after redirects), its first group is the version.
+	VersionRegex string `json:"version_regex"`
+	// InstallDir is the directory where tarballs are extracted and Binaries
+	// the paths in that directory linked into /usr/local/bin.
+	InstallDir string   `json:"install_dir"`
+	Binaries   []string `json:"binaries"`
+}
+
+func (app maintainedApp) isLinux() bool {
+	return app.Platform == "linux"
 }
 
 const baseBrewAPIURL = "https://formulae.brew.sh/api/"
@@ -50,12 +71,50 @@ func Refresh(ctx context.Context, ds mdmlab.Datastore, logger kitlog.Logger) err
 
 	i := ingester{
 		baseURL: baseURL,
-		ds:      ds,
-		logger:  logger,
+		// allow mocking of the Linux apps download servers for tests
+		linuxDownloadURL: os.Getenv("FLEET_DEV_LINUX_APPS_DOWNLOAD_URL"),
+		ds:               ds,
+		logger:           logger,
 	}
 	return i.ingest(ctx, apps)
 }
 
+func loadApp(token string) (*maintainedApp, error) {
+	var apps []maintainedApp
+	if err := json.Unmarshal(appsJSON, &apps); err != nil {
+		return nil, fmt.Errorf("unmarshal embedded apps.json: %w", err)
+	}
+	for _, app := range apps {
+		if app.Identifier == token {
+			return &app, nil
+		}
+	}
+	return nil, nil
+}
+
+// ExtensionForToken returns the extension of the installer of the FMA with
+// the given token (unique identifier). If one can't be found it returns an
+// empty string.
+func ExtensionForToken(token string) (string, error) {
+	app, err := loadApp(token)
+	if err != nil || app == nil {
+		return "", err
+	}
+	formats := strings.Split(app.InstallerFormat, ":")
+	return formats[0], nil
+}
+
+// AutomaticPolicyQuery returns the query of the policy that triggers the
+// install of the FMA with the given token on the hosts missing it. If one
+// can't be found it returns an empty string.
+func AutomaticPolicyQuery(token string) (string, error) {
+	app, err := loadApp(token)
+	if err != nil || app == nil {
+		return "", err
+	}
+	return app.AutomaticPolicyQuery, nil
+}
+
 // ExtensionForBundleIdentifier returns an extension for the given FMA
 // identifier. If one can't be found it returns an empty string.
 //
@@ -68,7 +127,7 @@ func ExtensionForBundleIdentifier(identifier string) (string, error) {
 	}
 
 	for _, app := range apps {
-		if app.BundleIdentifier == identifier {
+		if app.BundleIdentifier != "" && app.BundleIdentifier == identifier {
 			formats := strings.Split(app.InstallerFormat, ":")
 			if len(formats) > 0 {
 				return formats[0], nil
@@ -81,8 +140,11 @@ func ExtensionForBundleIdentifier(identifier string) (string, error) {
 
 type ingester struct {
 	baseURL string
-	ds      mdmlab.Datastore
-	logger  kitlog.Logger
+	// linuxDownloadURL replaces the scheme and host of the download URL of
+	// the Linux apps if set.
+	linuxDownloadURL string
+	ds               mdmlab.Datastore
+	logger           kitlog.Logger
 }
 
 func (i ingester) ingest(ctx context.Context, apps []maintainedApp) error {
@@ -93,12 +155,16 @@ func (i ingester) ingest(ctx context.Context, apps []maintainedApp) error {
 	}
 
 	client := mdmlabhttp.NewClient(mdmlabhttp.WithTimeout(10 * time.Second))
+	downloadClient := mdmlabhttp.NewClient(mdmlabhttp.WithTimeout(InstallerTimeout))
 
 	// run at most 3 concurrent requests to avoid overwhelming the brew API
 	g.SetLimit(3)
 	for _, app := range apps {
 		app := app // capture loop variable, not required in Go 1.23+
 		g.Go(func() error {
+			if app.isLinux() {
+				return i.ingestLinuxOne(ctx, app, downloadClient)
+			}
 			return i.ingestOne(ctx, app, client)
 		})
 	}
@@ -172,11 +238,10 @@ func (i ingester) ingestOne(ctx context.Context, app maintainedApp, client *http
 	uninstallScript := uninstallScriptForApp(&cask)
 
 	_, err = i.ds.UpsertMaintainedApp(ctx, &mdmlab.MaintainedApp{
-		Name:    cask.Name[0],
-		Token:   cask.Token,
-		Version: cask.Version,
-		// for now, maintained apps are always macOS (darwin)
-		Platform:         mdmlab.MacOSPlatform,
+		Name:             cask.Name[0],
+		Token:            cask.Token,
+		Version:          cask.Version,
+		Platform:         string(mdmlab.MacOSPlatform),
 		InstallerURL:     cask.URL,
 		SHA256:           cask.SHA256,
 		BundleIdentifier: app.BundleIdentifier,
@@ -0,0 +1,128 @@
+package maintainedapps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/pkg/file"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// ingestLinuxOne downloads the package of the Linux app from its vendor to
+// get its version and hash, as there is no equivalent of the brew API for
+// Linux packages.
+func (i ingester) ingestLinuxOne(ctx context.Context, app maintainedApp, client *http.Client) error {
+	if app.Name == "" {
+		return ctxerr.Errorf(ctx, "missing name for app %s", app.Identifier)
+	}
+	switch app.InstallerFormat {
+	case "deb", "rpm":
+		if app.PackageName == "" {
+			return ctxerr.Errorf(ctx, "missing package name for app %s", app.Identifier)
+		}
+	case "tarball":
+		if app.VersionRegex == "" || app.InstallDir == "" {
+			return ctxerr.Errorf(ctx, "missing version regex or install dir for app %s", app.Identifier)
+		}
+	default:
+		return ctxerr.Errorf(ctx, "unsupported installer format %q for app %s", app.InstallerFormat, app.Identifier)
+	}
+
+	downloadURL, err := url.Parse(app.URL)
+	if err != nil || app.URL == "" {
+		return ctxerr.Errorf(ctx, "invalid URL for app %s", app.Identifier)
+	}
+	if i.linuxDownloadURL != "" {
+		mockURL, err := url.Parse(i.linuxDownloadURL)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "parse Linux apps download URL")
+		}
+		downloadURL.Scheme = mockURL.Scheme
+		downloadURL.Host = mockURL.Host
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL.String(), nil)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "create http request")
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "execute http request")
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		// same as for the brew API, keep the app as it is in the DB.
+		level.Warn(i.logger).Log("msg", "maintained app package missing", "identifier", app.Identifier)
+		return nil
+	case res.StatusCode >= 300:
+		return ctxerr.Errorf(ctx, "download of %s package returned status %d", app.Identifier, res.StatusCode)
+	}
+
+	tfr, err := mdmlab.NewTempFileReader(res.Body, nil)
+	if err != nil {
+		return ctxerr.Wrapf(ctx, err, "download %s package", app.Identifier)
+	}
+	defer tfr.Close()
+
+	// the vendors usually redirect to the URL of the latest version, which is
+	// what we want to download when the app is added.
+	installerURL := res.Request.URL.String()
+
+	var version, sha string
+	switch app.InstallerFormat {
+	case "deb", "rpm":
+		meta, err := file.ExtractInstallerMetadata(tfr)
+		if err != nil {
+			return ctxerr.Wrapf(ctx, err, "extract metadata of %s package", app.Identifier)
+		}
+		if meta.Extension != app.InstallerFormat {
+			return ctxerr.Errorf(ctx, "package of %s is a %s, expected a %s", app.Identifier, meta.Extension, app.InstallerFormat)
+		}
+		if meta.Name != app.PackageName {
+			return ctxerr.Errorf(ctx, "package of %s is named %q, expected %q", app.Identifier, meta.Name, app.PackageName)
+		}
+		version, sha = meta.Version, hex.EncodeToString(meta.SHASum)
+
+	case "tarball":
+		re, err := regexp.Compile(app.VersionRegex)
+		if err != nil {
+			return ctxerr.Wrapf(ctx, err, "compile version regex for app %s", app.Identifier)
+		}
+		matches := re.FindStringSubmatch(res.Request.URL.Path)
+		if len(matches) < 2 {
+			return ctxerr.Errorf(ctx, "no version found in %s download URL %s", app.Identifier, installerURL)
+		}
+		version = matches[1]
+
+		h := sha256.New()
+		if _, err := io.Copy(h, tfr); err != nil {
+			return ctxerr.Wrapf(ctx, err, "hash %s package", app.Identifier)
+		}
+		sha = hex.EncodeToString(h.Sum(nil))
+	}
+	if version == "" {
+		return ctxerr.Errorf(ctx, "missing version for app %s", app.Identifier)
+	}
+
+	_, err = i.ds.UpsertMaintainedApp(ctx, &mdmlab.MaintainedApp{
+		Name:            app.Name,
+		Token:           app.Identifier,
+		Version:         version,
+		Platform:        app.Platform,
+		InstallerURL:    installerURL,
+		SHA256:          sha,
+		InstallScript:   linuxInstallScriptForApp(app),
+		UninstallScript: linuxUninstallScriptForApp(app),
+	})
+	return ctxerr.Wrap(ctx, err, "upsert maintained app")
+}
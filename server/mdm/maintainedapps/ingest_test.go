@@ -1,14 +1,21 @@
 package maintainedapps
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"path"
 	"strings"
 	"testing"
 
+	"github.com/blakesmith/ar"
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/mock"
 	"github.com/go-kit/log"
@@ -220,3 +227,201 @@ func TestExtensionForBundleIdentifier(t *testing.T) {
 		})
 	}
 }
+
+// buildDeb returns the content of a minimal deb package.
+func buildDeb(t *testing.T, name, version string) []byte {
+	var control bytes.Buffer
+	gw := gzip.NewWriter(&control)
+	tw := tar.NewWriter(gw)
+	controlFile := fmt.Sprintf("Package: %s\nVersion: %s\nArchitecture: amd64\n", name, version)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./control", Mode: 0o644, Size: int64(len(controlFile))}))
+	_, err := tw.Write([]byte(controlFile))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	var deb bytes.Buffer
+	aw := ar.NewWriter(&deb)
+	require.NoError(t, aw.WriteGlobalHeader())
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", control.Bytes()},
+	} {
+		require.NoError(t, aw.WriteHeader(&ar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.content))}))
+		_, err := aw.Write(f.content)
+		require.NoError(t, err)
+	}
+	return deb.Bytes()
+}
+
+func TestIngestLinux(t *testing.T) {
+	deb := buildDeb(t, "code", "1.95.3")
+	debSum := sha256.Sum256(deb)
+	tarball := []byte("not really a tarball")
+	tarballSum := sha256.Sum256(tarball)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/code.deb":
+			http.Redirect(w, r, "/releases/code_1.95.3_amd64.deb", http.StatusFound)
+		case "/releases/code_1.95.3_amd64.deb":
+			_, _ = w.Write(deb)
+		case "/latest/app.tar.xz":
+			http.Redirect(w, r, "/pub/app/releases/131.0/app-131.0.tar.xz", http.StatusFound)
+		case "/pub/app/releases/131.0/app-131.0.tar.xz":
+			_, _ = w.Write(tarball)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	ds := new(mock.Store)
+	var upserted *mdmlab.MaintainedApp
+	ds.UpsertMaintainedAppFunc = func(ctx context.Context, app *mdmlab.MaintainedApp) (*mdmlab.MaintainedApp, error) {
+		upserted = app
+		return app, nil
+	}
+
+	debApp := maintainedApp{
+		Identifier:      "code-deb",
+		Name:            "Code (deb)",
+		Platform:        "linux",
+		InstallerFormat: "deb",
+		PackageName:     "code",
+		// the host is replaced by the one of the test server
+		URL: "https://example.com/latest/code.deb",
+	}
+	tarballApp := maintainedApp{
+		Identifier:      "app-tarball",
+		Name:            "App (tarball)",
+		Platform:        "linux",
+		InstallerFormat: "tarball",
+		URL:             "https://example.com/latest/app.tar.xz",
+		VersionRegex:    "/releases/([^/]+)/",
+		InstallDir:      "/opt/app",
+		Binaries:        []string{"app"},
+	}
+	withChanges := func(app maintainedApp, fn func(app *maintainedApp)) maintainedApp {
+		fn(&app)
+		return app
+	}
+
+	cases := []struct {
+		name    string
+		app     maintainedApp
+		wantErr string
+		want    *mdmlab.MaintainedApp
+	}{
+		{
+			name: "deb",
+			app:  debApp,
+			want: &mdmlab.MaintainedApp{
+				Name:         "Code (deb)",
+				Token:        "code-deb",
+				Version:      "1.95.3",
+				Platform:     "linux",
+				InstallerURL: srv.URL + "/releases/code_1.95.3_amd64.deb",
+				SHA256:       hex.EncodeToString(debSum[:]),
+			},
+		},
+		{
+			name: "tarball",
+			app:  tarballApp,
+			want: &mdmlab.MaintainedApp{
+				Name:         "App (tarball)",
+				Token:        "app-tarball",
+				Version:      "131.0",
+				Platform:     "linux",
+				InstallerURL: srv.URL + "/pub/app/releases/131.0/app-131.0.tar.xz",
+				SHA256:       hex.EncodeToString(tarballSum[:]),
+			},
+		},
+		{
+			name:    "package name mismatch",
+			app:     withChanges(debApp, func(app *maintainedApp) { app.PackageName = "vscode" }),
+			wantErr: `package of code-deb is named "code", expected "vscode"`,
+		},
+		{
+			name:    "format mismatch",
+			app:     withChanges(debApp, func(app *maintainedApp) { app.InstallerFormat = "rpm" }),
+			wantErr: "package of code-deb is a deb, expected a rpm",
+		},
+		{
+			name:    "no version in URL",
+			app:     withChanges(tarballApp, func(app *maintainedApp) { app.VersionRegex = "/versions/([^/]+)/" }),
+			wantErr: "no version found in app-tarball download URL",
+		},
+		{
+			name:    "missing package name",
+			app:     withChanges(debApp, func(app *maintainedApp) { app.PackageName = "" }),
+			wantErr: "missing package name for app code-deb",
+		},
+		{
+			name:    "unsupported format",
+			app:     withChanges(debApp, func(app *maintainedApp) { app.InstallerFormat = "appimage" }),
+			wantErr: `unsupported installer format "appimage" for app code-deb`,
+		},
+		{
+			name:    "download failure",
+			app:     withChanges(debApp, func(app *maintainedApp) { app.URL = "https://example.com/fail" }),
+			wantErr: "download of code-deb package returned status 500",
+		},
+		{
+			name: "not found",
+			app:  withChanges(debApp, func(app *maintainedApp) { app.URL = "https://example.com/missing.deb" }),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			upserted = nil
+			i := ingester{linuxDownloadURL: srv.URL, ds: ds, logger: log.NewNopLogger()}
+
+			err := i.ingest(ctx, []maintainedApp{c.app})
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				require.Nil(t, upserted)
+				return
+			}
+			require.NoError(t, err)
+			if c.want == nil {
+				require.Nil(t, upserted)
+				return
+			}
+			require.NotNil(t, upserted)
+			require.Equal(t, linuxInstallScriptForApp(c.app), upserted.InstallScript)
+			require.Equal(t, linuxUninstallScriptForApp(c.app), upserted.UninstallScript)
+			upserted.InstallScript, upserted.UninstallScript = "", ""
+			require.Equal(t, c.want, upserted)
+		})
+	}
+}
+
+func TestLinuxAppsLookup(t *testing.T) {
+	ext, err := ExtensionForToken("google-chrome-deb")
+	require.NoError(t, err)
+	require.Equal(t, "deb", ext)
+
+	ext, err = ExtensionForToken("1password")
+	require.NoError(t, err)
+	require.Equal(t, "zip", ext)
+
+	ext, err = ExtensionForToken("no-such-app")
+	require.NoError(t, err)
+	require.Empty(t, ext)
+
+	query, err := AutomaticPolicyQuery("zoom-rpm")
+	require.NoError(t, err)
+	require.Contains(t, query, "FROM rpm_packages WHERE name = 'zoom'")
+
+	// Linux apps have no bundle identifier
+	ext, err = ExtensionForBundleIdentifier("")
+	require.NoError(t, err)
+	require.Empty(t, ext)
+}
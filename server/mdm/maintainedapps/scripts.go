@@ -2,6 +2,7 @@ package maintainedapps
 
 import (
 	"fmt"
+	"path"
 	"slices"
 	"sort"
 	"strings"
@@ -105,6 +106,66 @@ func uninstallScriptForApp(cask *brewCask) string {
 	return sb.String()
 }
 
+func linuxInstallScriptForApp(app maintainedApp) string {
+	sb := newScriptBuilder()
+
+	switch app.InstallerFormat {
+	case "deb":
+		sb.Write("# install the package and its dependencies")
+		sb.Write("export DEBIAN_FRONTEND=noninteractive")
+		sb.Write(`apt-get install --assume-yes -f "$INSTALLER_PATH"`)
+
+	case "rpm":
+		sb.Write("# install the package and its dependencies")
+		sb.Write(`dnf install --assumeyes "$INSTALLER_PATH"`)
+
+	case "tarball":
+		sb.AddVariable("APPDIR", fmt.Sprintf(`"%s"`, app.InstallDir))
+		sb.Write("# extract contents, replacing the previous version")
+		sb.Write(`rm -rf "$APPDIR"`)
+		sb.Write(`mkdir -p "$APPDIR"`)
+		sb.Write(`tar -xf "$INSTALLER_PATH" -C "$APPDIR" --strip-components=1`)
+		if len(app.Binaries) > 0 {
+			sb.Write("# link the binaries")
+			for _, binary := range app.Binaries {
+				sb.Writef(`ln -sf "$APPDIR/%s" "/usr/local/bin/%s"`, binary, path.Base(binary))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func linuxUninstallScriptForApp(app maintainedApp) string {
+	sb := newScriptBuilder()
+
+	if len(app.PreUninstallScripts) > 0 {
+		sb.Write(strings.Join(app.PreUninstallScripts, "\n"))
+	}
+
+	switch app.InstallerFormat {
+	case "deb":
+		sb.Write("export DEBIAN_FRONTEND=noninteractive")
+		sb.Writef(`apt-get remove --purge --assume-yes '%s'`, app.PackageName)
+
+	case "rpm":
+		sb.Writef(`dnf remove --assumeyes '%s'`, app.PackageName)
+
+	case "tarball":
+		sb.AddVariable("APPDIR", fmt.Sprintf(`"%s"`, app.InstallDir))
+		for _, binary := range app.Binaries {
+			sb.Writef(`rm -f "/usr/local/bin/%s"`, path.Base(binary))
+		}
+		sb.Write(`rm -rf "$APPDIR"`)
+	}
+
+	if len(app.PostUninstallScripts) > 0 {
+		sb.Write(strings.Join(app.PostUninstallScripts, "\n"))
+	}
+
+	return sb.String()
+}
+
 // priority of uninstall directives is defined by homebrew here:
 // https://github.com/Homebrew/brew/blob/e1ff668957dd8a66304c0290dfa66083e6c7444e/Library/Homebrew/cask/artifact/abstract_uninstall.rb#L18-L30
 const (
@@ -362,7 +423,9 @@ func (s *scriptBuilder) String() string {
 
 	// write any statements
 	if len(s.statements) > 0 {
-		script.WriteString("\n")
+		if len(s.variables)+len(s.functions) > 0 {
+			script.WriteString("\n")
+		}
 		script.WriteString(strings.Join(s.statements, "\n"))
 		script.WriteString("\n")
 	}
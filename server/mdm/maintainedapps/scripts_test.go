@@ -29,6 +29,14 @@ func TestScriptGeneration(t *testing.T) {
 	require.NoError(t, err)
 
 	for _, app := range apps {
+		if app.isLinux() {
+			t.Run(app.Identifier, func(t *testing.T) {
+				assertGoldenMatches(t, app.Identifier+"_install", linuxInstallScriptForApp(app), *update)
+				assertGoldenMatches(t, app.Identifier+"_uninstall", linuxUninstallScriptForApp(app), *update)
+			})
+			continue
+		}
+
 		caskJSON, err := os.ReadFile(filepath.Join("testdata", app.Identifier+".json"))
 		require.NoError(t, err)
 
@@ -0,0 +1,11 @@
+#!/bin/sh
+
+# variables
+APPDIR="/opt/firefox"
+
+# extract contents, replacing the previous version
+rm -rf "$APPDIR"
+mkdir -p "$APPDIR"
+tar -xf "$INSTALLER_PATH" -C "$APPDIR" --strip-components=1
+# link the binaries
+ln -sf "$APPDIR/firefox" "/usr/local/bin/firefox"
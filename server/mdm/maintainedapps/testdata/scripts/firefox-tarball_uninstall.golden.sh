@@ -0,0 +1,7 @@
+#!/bin/sh
+
+# variables
+APPDIR="/opt/firefox"
+
+rm -f "/usr/local/bin/firefox"
+rm -rf "$APPDIR"
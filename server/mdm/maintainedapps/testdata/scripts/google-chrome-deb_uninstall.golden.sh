@@ -0,0 +1,4 @@
+#!/bin/sh
+
+export DEBIAN_FRONTEND=noninteractive
+apt-get remove --purge --assume-yes 'google-chrome-stable'
@@ -0,0 +1,3 @@
+#!/bin/sh
+
+dnf remove --assumeyes 'google-chrome-stable'
@@ -0,0 +1,5 @@
+#!/bin/sh
+
+pkill -x slack || true
+export DEBIAN_FRONTEND=noninteractive
+apt-get remove --purge --assume-yes 'slack-desktop'
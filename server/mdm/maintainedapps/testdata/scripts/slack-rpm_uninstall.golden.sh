@@ -0,0 +1,4 @@
+#!/bin/sh
+
+pkill -x slack || true
+dnf remove --assumeyes 'slack'
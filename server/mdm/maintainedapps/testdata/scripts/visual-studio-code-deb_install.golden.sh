@@ -0,0 +1,5 @@
+#!/bin/sh
+
+# install the package and its dependencies
+export DEBIAN_FRONTEND=noninteractive
+apt-get install --assume-yes -f "$INSTALLER_PATH"
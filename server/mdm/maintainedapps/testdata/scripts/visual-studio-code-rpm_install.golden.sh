@@ -0,0 +1,4 @@
+#!/bin/sh
+
+# install the package and its dependencies
+dnf install --assumeyes "$INSTALLER_PATH"
@@ -0,0 +1,5 @@
+#!/bin/sh
+
+pkill -x zoom || true
+export DEBIAN_FRONTEND=noninteractive
+apt-get remove --purge --assume-yes 'zoom'
@@ -0,0 +1,4 @@
+#!/bin/sh
+
+pkill -x zoom || true
+dnf remove --assumeyes 'zoom'
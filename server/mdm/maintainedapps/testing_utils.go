@@ -28,6 +28,11 @@ func IngestMaintainedApps(t *testing.T, ds mdmlab.Datastore) []mdmlab.Maintained
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := path.Base(r.URL.Path)
+		if token == "/" {
+			// Linux apps downloaded from URLs without a file name
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 		b, err := os.ReadFile(filepath.Join(testdataDir, token))
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -46,6 +51,8 @@ func IngestMaintainedApps(t *testing.T, ds mdmlab.Datastore) []mdmlab.Maintained
 	// this call
 	os.Setenv("FLEET_DEV_BREW_API_URL", srv.URL)
 	defer os.Unsetenv("FLEET_DEV_BREW_API_URL")
+	os.Setenv("FLEET_DEV_LINUX_APPS_DOWNLOAD_URL", srv.URL)
+	defer os.Unsetenv("FLEET_DEV_LINUX_APPS_DOWNLOAD_URL")
 
 	err := Refresh(context.Background(), ds, log.NewNopLogger())
 	require.NoError(t, err)
@@ -111,3 +111,10 @@ func (ms *MultiAllStorage) BulkDeleteHostUserCommandsWithoutResults(ctx context.
 	})
 	return err
 }
+
+func (ms *MultiAllStorage) CancelPendingCommands(ctx context.Context, deviceIDs []string, commandUUID string, excludeRequestTypes []string) (int64, error) {
+	val, err := ms.execStores(ctx, func(s storage.AllStorage) (interface{}, error) {
+		return s.CancelPendingCommands(ctx, deviceIDs, commandUUID, excludeRequestTypes)
+	})
+	return val.(int64), err
+}
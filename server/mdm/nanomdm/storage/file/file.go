@@ -250,3 +250,8 @@ func (s *FileStorage) BulkDeleteHostUserCommandsWithoutResults(_ context.Context
 	// NOT IMPLEMENTED
 	return nil
 }
+
+func (s *FileStorage) CancelPendingCommands(_ context.Context, _ []string, _ string, _ []string) (int64, error) {
+	// NOT IMPLEMENTED
+	return 0, nil
+}
@@ -262,6 +262,65 @@ WHERE
 	return err
 }
 
+// CancelPendingCommands marks as inactive the queued commands that have not
+// been processed yet (no result or a NotNow result) by the given device IDs,
+// including their user-channel enrollments. If commandUUID is not empty, only
+// that command is cancelled. Commands of the excludeRequestTypes are never
+// cancelled. It returns the number of cancelled queue entries.
+func (m *MySQLStorage) CancelPendingCommands(ctx context.Context, deviceIDs []string, commandUUID string, excludeRequestTypes []string) (int64, error) {
+	if len(deviceIDs) == 0 {
+		return 0, nil
+	}
+
+	stmt := `
+UPDATE
+    nano_enrollment_queue AS q
+    INNER JOIN nano_enrollments AS e
+        ON q.id = e.id
+    INNER JOIN nano_commands AS c
+        ON c.command_uuid = q.command_uuid
+    LEFT JOIN nano_command_results r
+        ON r.command_uuid = q.command_uuid AND r.id = q.id
+SET
+    q.active = 0
+WHERE
+    e.device_id IN (?) AND
+    q.active = 1 AND
+    (r.status IS NULL OR r.status = 'NotNow')`
+	if commandUUID != "" {
+		stmt += ` AND q.command_uuid = ?`
+	}
+	if len(excludeRequestTypes) > 0 {
+		stmt += ` AND c.request_type NOT IN (?)`
+	}
+
+	var cancelled int64
+	err := common_mysql.WithRetryTxx(ctx, sqlx.NewDb(m.db, ""), func(tx sqlx.ExtContext) error {
+		cancelled = 0
+		return common_mysql.BatchProcessSimple(deviceIDs, 10000, func(batch []string) error {
+			args := []interface{}{batch}
+			if commandUUID != "" {
+				args = append(args, commandUUID)
+			}
+			if len(excludeRequestTypes) > 0 {
+				args = append(args, excludeRequestTypes)
+			}
+			expanded, args, err := sqlx.In(stmt, args...)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "expanding cancel pending nano commands")
+			}
+			res, err := tx.ExecContext(ctx, expanded, args...)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "cancel pending nano commands")
+			}
+			n, _ := res.RowsAffected()
+			cancelled += n
+			return nil
+		})
+	}, loggerWrapper{m.logger})
+	return cancelled, err
+}
+
 // BulkDeleteHostUserCommandsWithoutResults deletes all commands without results for the given host/user IDs.
 // This is used to clean up the queue when a profile is deleted from MDMlab.
 func (m *MySQLStorage) BulkDeleteHostUserCommandsWithoutResults(ctx context.Context, commandToIDs map[string][]string) error {
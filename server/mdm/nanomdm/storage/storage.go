@@ -29,6 +29,10 @@ type CommandAndReportResultsStore interface {
 	ClearQueue(r *mdm.Request) error
 	// BulkDeleteHostUserCommandsWithoutResults deletes all commands without results for the given host/user IDs.
 	BulkDeleteHostUserCommandsWithoutResults(ctx context.Context, commandToId map[string][]string) error
+	// CancelPendingCommands deactivates the queued commands without a final
+	// result (optionally only commandUUID) for the given device IDs, except
+	// those of the excluded request types.
+	CancelPendingCommands(ctx context.Context, deviceIDs []string, commandUUID string, excludeRequestTypes []string) (int64, error)
 }
 
 type BootstrapTokenStore interface {
@@ -3,6 +3,7 @@ package mdmlab
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 //go:generate go run gen_activity_doc.go "../../docs/Contributing/Audit-logs.md"
@@ -94,6 +95,17 @@ var ActivityDetailsList = []ActivityDetails{
 	ActivityTypeLockedHost{},
 	ActivityTypeUnlockedHost{},
 	ActivityTypeWipedHost{},
+	ActivityTypeRestartedHost{},
+	ActivityTypeShutDownHost{},
+	ActivityTypeEnabledLostMode{},
+	ActivityTypeDisabledLostMode{},
+	ActivityTypePlayedLostModeSound{},
+	ActivityTypeRequestedHostLocation{},
+	ActivityTypeSetRecoveryLock{},
+	ActivityTypeReadHostRecoveryLockPassword{},
+	ActivityTypeHostCertificateExpiring{},
+	ActivityTypeRotatedHostBitLockerRecoveryPassword{},
+	ActivityTypeCanceledMDMAppleCommands{},
 
 	ActivityTypeCreatedDeclarationProfile{},
 	ActivityTypeDeletedDeclarationProfile{},
@@ -111,6 +123,26 @@ var ActivityDetailsList = []ActivityDetails{
 	ActivityAddedAppStoreApp{},
 	ActivityDeletedAppStoreApp{},
 	ActivityInstalledAppStoreApp{},
+	ActivityTypeStartedSoftwareRollout{},
+	ActivityTypeAdvancedSoftwareRollout{},
+	ActivityTypePausedSoftwareRollout{},
+	ActivityTypeResumedSoftwareRollout{},
+	ActivityTypeCompletedSoftwareRollout{},
+	ActivityTypeCanceledSoftwareRollout{},
+	ActivityTypePinnedSoftwareVersion{},
+	ActivityTypeUnpinnedSoftwareVersion{},
+	ActivityTypeRolledBackSoftwareVersion{},
+	ActivityTypeEditedSoftwareVersionLabels{},
+	ActivityTypeDeletedSoftwareVersion{},
+	ActivityTypeCreatedSoftwareBlocklistRule{},
+	ActivityTypeEditedSoftwareBlocklistRule{},
+	ActivityTypeDeletedSoftwareBlocklistRule{},
+	ActivityTypeRequestedSoftware{},
+	ActivityTypeApprovedSoftwareRequest{},
+	ActivityTypeDeniedSoftwareRequest{},
+	ActivityTypeAddedSoftwareLicense{},
+	ActivityTypeEditedSoftwareLicense{},
+	ActivityTypeDeletedSoftwareLicense{},
 
 	ActivityAddedNDESSCEPProxy{},
 	ActivityDeletedNDESSCEPProxy{},
@@ -119,6 +151,13 @@ var ActivityDetailsList = []ActivityDetails{
 	ActivityTypeEnabledActivityAutomations{},
 	ActivityTypeEditedActivityAutomations{},
 	ActivityTypeDisabledActivityAutomations{},
+
+	ActivityTypeCreatedCustomCVEMatchingRule{},
+	ActivityTypeDeletedCustomCVEMatchingRule{},
+	ActivityTypeAppliedSpecCustomCVEMatchingRules{},
+	ActivityTypeCreatedVulnerabilitySuppression{},
+	ActivityTypeDeletedVulnerabilitySuppression{},
+	ActivityTypeImportedVEXDocument{},
 }
 
 type ActivityDetails interface {
@@ -1489,8 +1528,9 @@ func (a ActivityTypeUnlockedHost) Documentation() (activity, details, detailsExa
 }
 
 type ActivityTypeWipedHost struct {
-	HostID          uint   `json:"host_id"`
-	HostDisplayName string `json:"host_display_name"`
+	HostID          uint            `json:"host_id"`
+	HostDisplayName string          `json:"host_display_name"`
+	WindowsWipeType WindowsWipeType `json:"windows_wipe_type,omitempty"`
 }
 
 func (a ActivityTypeWipedHost) ActivityName() string {
@@ -1501,12 +1541,280 @@ func (a ActivityTypeWipedHost) Documentation() (activity, details, detailsExampl
 	return `Generated when a user sends a request to wipe a host.`,
 		`This activity contains the following fields:
 - "host_id": ID of the host.
+- "host_display_name": Display name of the host.
+- "windows_wipe_type": The RemoteWipe CSP node used to wipe the host (Windows only).`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's MacBook Pro"
+}`
+}
+
+type ActivityTypeRestartedHost struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+	NotifyUser      bool   `json:"notify_user"`
+}
+
+func (a ActivityTypeRestartedHost) ActivityName() string {
+	return "restarted_host"
+}
+
+func (a ActivityTypeRestartedHost) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeRestartedHost) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to restart a host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.
+- "notify_user": Whether the logged-in user is prompted before restarting (macOS only).`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's MacBook Pro",
+  "notify_user": false
+}`
+}
+
+type ActivityTypeShutDownHost struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypeShutDownHost) ActivityName() string {
+	return "shut_down_host"
+}
+
+func (a ActivityTypeShutDownHost) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeShutDownHost) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to shut down a host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's MacBook Pro"
+}`
+}
+
+type ActivityTypeEnabledLostMode struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypeEnabledLostMode) ActivityName() string {
+	return "enabled_lost_mode"
+}
+
+func (a ActivityTypeEnabledLostMode) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeEnabledLostMode) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to enable lost mode on an iOS or iPadOS host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's iPhone"
+}`
+}
+
+type ActivityTypeDisabledLostMode struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypeDisabledLostMode) ActivityName() string {
+	return "disabled_lost_mode"
+}
+
+func (a ActivityTypeDisabledLostMode) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeDisabledLostMode) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to disable lost mode on an iOS or iPadOS host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's iPhone"
+}`
+}
+
+type ActivityTypePlayedLostModeSound struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypePlayedLostModeSound) ActivityName() string {
+	return "played_lost_mode_sound"
+}
+
+func (a ActivityTypePlayedLostModeSound) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypePlayedLostModeSound) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to play a sound on an iOS or iPadOS host in lost mode.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's iPhone"
+}`
+}
+
+type ActivityTypeRequestedHostLocation struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypeRequestedHostLocation) ActivityName() string {
+	return "requested_host_location"
+}
+
+func (a ActivityTypeRequestedHostLocation) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeRequestedHostLocation) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user requests the location of an iOS or iPadOS host in lost mode.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's iPhone"
+}`
+}
+
+type ActivityTypeSetRecoveryLock struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+	Cleared         bool   `json:"cleared"`
+}
+
+func (a ActivityTypeSetRecoveryLock) ActivityName() string {
+	return "set_recovery_lock"
+}
+
+func (a ActivityTypeSetRecoveryLock) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeSetRecoveryLock) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to set or clear the recovery lock password of a macOS host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.
+- "cleared": Whether the request removes the recovery lock instead of setting it.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's MacBook Pro",
+  "cleared": false
+}`
+}
+
+type ActivityTypeReadHostRecoveryLockPassword struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypeReadHostRecoveryLockPassword) ActivityName() string {
+	return "read_host_recovery_lock_password"
+}
+
+func (a ActivityTypeReadHostRecoveryLockPassword) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeReadHostRecoveryLockPassword) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user reveals the recovery lock or firmware password escrowed for a macOS host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
 - "host_display_name": Display name of the host.`, `{
   "host_id": 1,
   "host_display_name": "Anna's MacBook Pro"
 }`
 }
 
+type ActivityTypeHostCertificateExpiring struct {
+	HostID          uint      `json:"host_id"`
+	HostDisplayName string    `json:"host_display_name"`
+	CommonName      string    `json:"common_name"`
+	SHA1Sum         string    `json:"sha1_sum"`
+	NotValidAfter   time.Time `json:"not_valid_after"`
+}
+
+func (a ActivityTypeHostCertificateExpiring) ActivityName() string {
+	return "host_certificate_expiring"
+}
+
+func (a ActivityTypeHostCertificateExpiring) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeHostCertificateExpiring) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a certificate installed on a host expires within the window configured in the certificate expiration webhook settings.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.
+- "common_name": Common name of the certificate.
+- "sha1_sum": SHA-1 thumbprint of the certificate.
+- "not_valid_after": Expiration date of the certificate.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's MacBook Pro",
+  "common_name": "anna@example.com",
+  "sha1_sum": "3a0d3d4dc4a9e9b1e1b48e6b3c1b9a96c4f2b0ad",
+  "not_valid_after": "2025-03-01T00:00:00Z"
+}`
+}
+
+type ActivityTypeRotatedHostBitLockerRecoveryPassword struct {
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+}
+
+func (a ActivityTypeRotatedHostBitLockerRecoveryPassword) ActivityName() string {
+	return "rotated_host_bitlocker_recovery_password"
+}
+
+func (a ActivityTypeRotatedHostBitLockerRecoveryPassword) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+func (a ActivityTypeRotatedHostBitLockerRecoveryPassword) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user sends a request to rotate the BitLocker recovery password of a Windows host.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.`, `{
+  "host_id": 1,
+  "host_display_name": "Anna's Dell XPS"
+}`
+}
+
+type ActivityTypeCanceledMDMAppleCommands struct {
+	CommandUUID string `json:"command_uuid,omitempty"`
+	HostCount   int    `json:"host_count"`
+	Canceled    int64  `json:"canceled"`
+}
+
+func (a ActivityTypeCanceledMDMAppleCommands) ActivityName() string {
+	return "canceled_mdm_apple_commands"
+}
+
+func (a ActivityTypeCanceledMDMAppleCommands) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user cancels the pending Apple MDM commands of one or more hosts.`,
+		`This activity contains the following fields:
+- "command_uuid": UUID of the canceled command, empty if all pending commands were canceled.
+- "host_count": Number of hosts targeted by the cancellation.
+- "canceled": Number of queued commands that were canceled.`, `{
+  "command_uuid": "a2064cef-0000-1234-afb9-283e3c1d487e",
+  "host_count": 2,
+  "canceled": 2
+}`
+}
+
 type ActivityTypeCreatedDeclarationProfile struct {
 	ProfileName string  `json:"profile_name"`
 	Identifier  string  `json:"identifier"`
@@ -2030,6 +2338,513 @@ func (a ActivityInstalledAppStoreApp) Documentation() (string, string, string) {
 }`
 }
 
+// ActivitySoftwareRollout contains the details common to the activities of a
+// software installer staged rollout.
+type ActivitySoftwareRollout struct {
+	SoftwareTitle   string  `json:"software_title"`
+	SoftwarePackage string  `json:"software_package"`
+	SoftwareTitleID uint    `json:"software_title_id"`
+	TeamName        *string `json:"team_name"`
+	TeamID          *uint   `json:"team_id"`
+	Version         string  `json:"version"`
+	// Stage is the one-based number of the current stage.
+	Stage       uint `json:"stage"`
+	StagesCount int  `json:"stages_count"`
+}
+
+const activitySoftwareRolloutFields = `- "software_title": Name of the software.
+- "software_package": Filename of the installer.
+- "software_title_id": ID of the software title.
+- "team_name": Name of the team of the installer.` + " `null` " + `for no team.
+- "team_id": ID of the team of the installer.` + " `null` " + `for no team.
+- "version": Version of the installer being rolled out.
+- "stage": Number of the current stage, starting at 1.
+- "stages_count": Number of stages of the rollout.`
+
+const activitySoftwareRolloutExample = `  "software_title": "Falcon.app",
+  "software_package": "FalconSensor-6.44.pkg",
+  "software_title_id": 2234,
+  "team_name": "Workstations",
+  "team_id": 123,
+  "version": "6.44",
+  "stage": 2,
+  "stages_count": 3`
+
+type ActivityTypeStartedSoftwareRollout struct {
+	ActivitySoftwareRollout
+	// FromAutomation is true when the rollout restarted because a new version
+	// of the installer was uploaded.
+	FromAutomation bool `json:"-"`
+}
+
+func (a ActivityTypeStartedSoftwareRollout) ActivityName() string {
+	return "started_software_rollout"
+}
+
+func (a ActivityTypeStartedSoftwareRollout) WasFromAutomation() bool {
+	return a.FromAutomation
+}
+
+func (a ActivityTypeStartedSoftwareRollout) Documentation() (string, string, string) {
+	return `Generated when the staged rollout of a software installer starts, or restarts from the first stage because a new version of the installer was uploaded.`,
+		`This activity contains the following fields:
+` + activitySoftwareRolloutFields, `{
+` + activitySoftwareRolloutExample + `
+}`
+}
+
+type ActivityTypeAdvancedSoftwareRollout struct {
+	ActivitySoftwareRollout
+}
+
+func (a ActivityTypeAdvancedSoftwareRollout) ActivityName() string {
+	return "advanced_software_rollout"
+}
+
+func (a ActivityTypeAdvancedSoftwareRollout) WasFromAutomation() bool {
+	return true
+}
+
+func (a ActivityTypeAdvancedSoftwareRollout) Documentation() (string, string, string) {
+	return `Generated when the staged rollout of a software installer advances to the next stage after its soak time.`,
+		`This activity contains the following fields:
+` + activitySoftwareRolloutFields, `{
+` + activitySoftwareRolloutExample + `
+}`
+}
+
+type ActivityTypePausedSoftwareRollout struct {
+	ActivitySoftwareRollout
+	Reason *string `json:"reason"`
+	// FromAutomation is true when the rollout was paused because of the
+	// number of failed installs.
+	FromAutomation bool `json:"-"`
+}
+
+func (a ActivityTypePausedSoftwareRollout) ActivityName() string {
+	return "paused_software_rollout"
+}
+
+func (a ActivityTypePausedSoftwareRollout) WasFromAutomation() bool {
+	return a.FromAutomation
+}
+
+func (a ActivityTypePausedSoftwareRollout) Documentation() (string, string, string) {
+	return `Generated when the staged rollout of a software installer is paused, by a user or because too many installs failed.`,
+		`This activity contains the following fields:
+` + activitySoftwareRolloutFields + `
+- "reason": Why the rollout was paused.` + " `null` " + `if paused by a user.`, `{
+` + activitySoftwareRolloutExample + `,
+  "reason": "6 failed installs exceed the limit of 5"
+}`
+}
+
+type ActivityTypeResumedSoftwareRollout struct {
+	ActivitySoftwareRollout
+}
+
+func (a ActivityTypeResumedSoftwareRollout) ActivityName() string {
+	return "resumed_software_rollout"
+}
+
+func (a ActivityTypeResumedSoftwareRollout) Documentation() (string, string, string) {
+	return `Generated when a user resumes the paused staged rollout of a software installer.`,
+		`This activity contains the following fields:
+` + activitySoftwareRolloutFields, `{
+` + activitySoftwareRolloutExample + `
+}`
+}
+
+type ActivityTypeCompletedSoftwareRollout struct {
+	ActivitySoftwareRollout
+}
+
+func (a ActivityTypeCompletedSoftwareRollout) ActivityName() string {
+	return "completed_software_rollout"
+}
+
+func (a ActivityTypeCompletedSoftwareRollout) WasFromAutomation() bool {
+	return true
+}
+
+func (a ActivityTypeCompletedSoftwareRollout) Documentation() (string, string, string) {
+	return `Generated when the last stage of the staged rollout of a software installer succeeds, the software is then installed on all hosts in scope.`,
+		`This activity contains the following fields:
+` + activitySoftwareRolloutFields, `{
+` + activitySoftwareRolloutExample + `
+}`
+}
+
+type ActivityTypeCanceledSoftwareRollout struct {
+	ActivitySoftwareRollout
+}
+
+func (a ActivityTypeCanceledSoftwareRollout) ActivityName() string {
+	return "canceled_software_rollout"
+}
+
+func (a ActivityTypeCanceledSoftwareRollout) Documentation() (string, string, string) {
+	return `Generated when a user cancels the staged rollout of a software installer, the software is then installed on all hosts in scope.`,
+		`This activity contains the following fields:
+` + activitySoftwareRolloutFields, `{
+` + activitySoftwareRolloutExample + `
+}`
+}
+
+// ActivitySoftwareVersion contains the details common to the activities of
+// the versions of a software installer.
+type ActivitySoftwareVersion struct {
+	SoftwareTitle   string  `json:"software_title"`
+	SoftwarePackage string  `json:"software_package"`
+	SoftwareTitleID uint    `json:"software_title_id"`
+	TeamName        *string `json:"team_name"`
+	TeamID          *uint   `json:"team_id"`
+	Version         string  `json:"version"`
+}
+
+const activitySoftwareVersionFields = `- "software_title": Name of the software.
+- "software_package": Filename of the installer package of the version.
+- "software_title_id": ID of the software title.
+- "team_name": Name of the team of the installer.` + " `null` " + `for no team.
+- "team_id": ID of the team of the installer.` + " `null` " + `for no team.
+- "version": Version of the installer package.`
+
+const activitySoftwareVersionExample = `  "software_title": "Falcon.app",
+  "software_package": "FalconSensor-6.44.pkg",
+  "software_title_id": 2234,
+  "team_name": "Workstations",
+  "team_id": 123,
+  "version": "6.44"`
+
+type ActivityTypePinnedSoftwareVersion struct {
+	ActivitySoftwareVersion
+}
+
+func (a ActivityTypePinnedSoftwareVersion) ActivityName() string {
+	return "pinned_software_version"
+}
+
+func (a ActivityTypePinnedSoftwareVersion) Documentation() (string, string, string) {
+	return `Generated when a user pins a version of a software installer, it is then installed instead of the latest version.`,
+		`This activity contains the following fields:
+` + activitySoftwareVersionFields, `{
+` + activitySoftwareVersionExample + `
+}`
+}
+
+type ActivityTypeUnpinnedSoftwareVersion struct {
+	ActivitySoftwareVersion
+}
+
+func (a ActivityTypeUnpinnedSoftwareVersion) ActivityName() string {
+	return "unpinned_software_version"
+}
+
+func (a ActivityTypeUnpinnedSoftwareVersion) Documentation() (string, string, string) {
+	return `Generated when a user unpins the pinned version of a software installer.`,
+		`This activity contains the following fields:
+` + activitySoftwareVersionFields, `{
+` + activitySoftwareVersionExample + `
+}`
+}
+
+type ActivityTypeRolledBackSoftwareVersion struct {
+	ActivitySoftwareVersion
+}
+
+func (a ActivityTypeRolledBackSoftwareVersion) ActivityName() string {
+	return "rolled_back_software_version"
+}
+
+func (a ActivityTypeRolledBackSoftwareVersion) Documentation() (string, string, string) {
+	return `Generated when a user rolls a software installer back to a previous version.`,
+		`This activity contains the following fields:
+` + activitySoftwareVersionFields, `{
+` + activitySoftwareVersionExample + `
+}`
+}
+
+type ActivityTypeEditedSoftwareVersionLabels struct {
+	ActivitySoftwareVersion
+	LabelsIncludeAny []ActivitySoftwareLabel `json:"labels_include_any"`
+}
+
+func (a ActivityTypeEditedSoftwareVersionLabels) ActivityName() string {
+	return "edited_software_version_labels"
+}
+
+func (a ActivityTypeEditedSoftwareVersionLabels) Documentation() (string, string, string) {
+	return `Generated when a user changes the labels targeted by a version of a software installer.`,
+		`This activity contains the following fields:
+` + activitySoftwareVersionFields + `
+- "labels_include_any": Hosts that have any label in the array get this version.`, `{
+` + activitySoftwareVersionExample + `,
+  "labels_include_any": [
+    {
+      "name": "Beta testers",
+      "id": 12
+    }
+  ]
+}`
+}
+
+type ActivityTypeDeletedSoftwareVersion struct {
+	ActivitySoftwareVersion
+}
+
+func (a ActivityTypeDeletedSoftwareVersion) ActivityName() string {
+	return "deleted_software_version"
+}
+
+func (a ActivityTypeDeletedSoftwareVersion) Documentation() (string, string, string) {
+	return `Generated when a user deletes a previous version of a software installer.`,
+		`This activity contains the following fields:
+` + activitySoftwareVersionFields, `{
+` + activitySoftwareVersionExample + `
+}`
+}
+
+// ActivitySoftwareBlocklistRule contains the details of the software blocklist
+// rule activities.
+type ActivitySoftwareBlocklistRule struct {
+	RuleID           uint    `json:"rule_id"`
+	TeamName         *string `json:"team_name"`
+	TeamID           *uint   `json:"team_id"`
+	SoftwareName     string  `json:"software_name"`
+	BundleIdentifier string  `json:"bundle_identifier"`
+	Source           string  `json:"source"`
+	MinVersion       string  `json:"min_version"`
+	MaxVersion       string  `json:"max_version"`
+}
+
+const activitySoftwareBlocklistRuleFields = `- "rule_id": ID of the blocklist rule.
+- "team_name": Name of the team of the rule.` + " `null` " + `for no team.
+- "team_id": ID of the team of the rule.` + " `null` " + `for no team.
+- "software_name": Name of the blocklisted software, empty if any name matches.
+- "bundle_identifier": Bundle identifier of the blocklisted software, empty if any bundle identifier matches.
+- "source": Source of the blocklisted software, empty if any source matches.
+- "min_version": Minimum blocklisted version, empty if there is no minimum.
+- "max_version": Maximum blocklisted version, empty if there is no maximum.`
+
+const activitySoftwareBlocklistRuleExample = `  "rule_id": 12,
+  "team_name": "Workstations",
+  "team_id": 123,
+  "software_name": "uTorrent.app",
+  "bundle_identifier": "com.bittorrent.uTorrent",
+  "source": "apps",
+  "min_version": "",
+  "max_version": "3.5.5"`
+
+type ActivityTypeCreatedSoftwareBlocklistRule struct {
+	ActivitySoftwareBlocklistRule
+}
+
+func (a ActivityTypeCreatedSoftwareBlocklistRule) ActivityName() string {
+	return "created_software_blocklist_rule"
+}
+
+func (a ActivityTypeCreatedSoftwareBlocklistRule) Documentation() (string, string, string) {
+	return `Generated when a user blocklists software for a team.`,
+		`This activity contains the following fields:
+` + activitySoftwareBlocklistRuleFields, `{
+` + activitySoftwareBlocklistRuleExample + `
+}`
+}
+
+type ActivityTypeEditedSoftwareBlocklistRule struct {
+	ActivitySoftwareBlocklistRule
+}
+
+func (a ActivityTypeEditedSoftwareBlocklistRule) ActivityName() string {
+	return "edited_software_blocklist_rule"
+}
+
+func (a ActivityTypeEditedSoftwareBlocklistRule) Documentation() (string, string, string) {
+	return `Generated when a user edits a software blocklist rule.`,
+		`This activity contains the following fields:
+` + activitySoftwareBlocklistRuleFields, `{
+` + activitySoftwareBlocklistRuleExample + `
+}`
+}
+
+type ActivityTypeDeletedSoftwareBlocklistRule struct {
+	ActivitySoftwareBlocklistRule
+}
+
+func (a ActivityTypeDeletedSoftwareBlocklistRule) ActivityName() string {
+	return "deleted_software_blocklist_rule"
+}
+
+func (a ActivityTypeDeletedSoftwareBlocklistRule) Documentation() (string, string, string) {
+	return `Generated when a user deletes a software blocklist rule.`,
+		`This activity contains the following fields:
+` + activitySoftwareBlocklistRuleFields, `{
+` + activitySoftwareBlocklistRuleExample + `
+}`
+}
+
+// ActivitySoftwareRequest contains the details of the software request
+// activities.
+type ActivitySoftwareRequest struct {
+	RequestID       uint   `json:"request_id"`
+	HostID          uint   `json:"host_id"`
+	HostDisplayName string `json:"host_display_name"`
+	SoftwareTitle   string `json:"software_title"`
+	RequestedBy     string `json:"requested_by"`
+}
+
+func (a ActivitySoftwareRequest) HostIDs() []uint {
+	return []uint{a.HostID}
+}
+
+const activitySoftwareRequestFields = `- "request_id": ID of the software request.
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.
+- "software_title": Name of the requested software.
+- "requested_by": Email of the end user who requested the software, empty if unknown.`
+
+const activitySoftwareRequestExample = `  "request_id": 42,
+  "host_id": 1,
+  "host_display_name": "Anna's MacBook Pro",
+  "software_title": "Figma.app",
+  "requested_by": "anna@example.com"`
+
+type ActivityTypeRequestedSoftware struct {
+	ActivitySoftwareRequest
+	Justification string `json:"justification"`
+}
+
+func (a ActivityTypeRequestedSoftware) ActivityName() string {
+	return "requested_software"
+}
+
+func (a ActivityTypeRequestedSoftware) Documentation() (string, string, string) {
+	return `Generated when an end user requests software from the My device page.`,
+		`This activity contains the following fields:
+` + activitySoftwareRequestFields + `
+- "justification": Reason given by the end user for the request.`, `{
+` + activitySoftwareRequestExample + `,
+  "justification": "I need it to review the designs of the new website."
+}`
+}
+
+type ActivityTypeApprovedSoftwareRequest struct {
+	ActivitySoftwareRequest
+	Comment     string `json:"comment"`
+	InstallUUID string `json:"install_uuid"`
+}
+
+func (a ActivityTypeApprovedSoftwareRequest) ActivityName() string {
+	return "approved_software_request"
+}
+
+func (a ActivityTypeApprovedSoftwareRequest) Documentation() (string, string, string) {
+	return `Generated when a user approves a software request, the software is installed on the host.`,
+		`This activity contains the following fields:
+` + activitySoftwareRequestFields + `
+- "comment": Comment of the reviewer.
+- "install_uuid": ID of the software installation.`, `{
+` + activitySoftwareRequestExample + `,
+  "comment": "",
+  "install_uuid": "d6cffa75-b5b5-41ef-9230-15073c8a88cf"
+}`
+}
+
+type ActivityTypeDeniedSoftwareRequest struct {
+	ActivitySoftwareRequest
+	Comment string `json:"comment"`
+}
+
+func (a ActivityTypeDeniedSoftwareRequest) ActivityName() string {
+	return "denied_software_request"
+}
+
+func (a ActivityTypeDeniedSoftwareRequest) Documentation() (string, string, string) {
+	return `Generated when a user denies a software request.`,
+		`This activity contains the following fields:
+` + activitySoftwareRequestFields + `
+- "comment": Comment of the reviewer.`, `{
+` + activitySoftwareRequestExample + `,
+  "comment": "Please use the licensed design tool instead."
+}`
+}
+
+// ActivitySoftwareLicense contains the details of the software license
+// activities.
+type ActivitySoftwareLicense struct {
+	LicenseID       uint    `json:"license_id"`
+	TeamName        *string `json:"team_name"`
+	TeamID          *uint   `json:"team_id"`
+	SoftwareTitleID uint    `json:"software_title_id"`
+	SoftwareTitle   string  `json:"software_title"`
+	Vendor          string  `json:"vendor"`
+	SeatCount       uint    `json:"seat_count"`
+}
+
+const activitySoftwareLicenseFields = `- "license_id": ID of the software license.
+- "team_name": Name of the team of the license.` + " `null` " + `for no team.
+- "team_id": ID of the team of the license.` + " `null` " + `for no team.
+- "software_title_id": ID of the licensed software title.
+- "software_title": Name of the licensed software title.
+- "vendor": Vendor of the license.
+- "seat_count": Number of seats of the license.`
+
+const activitySoftwareLicenseExample = `  "license_id": 3,
+  "team_name": "Design",
+  "team_id": 2,
+  "software_title_id": 42,
+  "software_title": "Figma.app",
+  "vendor": "Figma, Inc.",
+  "seat_count": 50`
+
+type ActivityTypeAddedSoftwareLicense struct {
+	ActivitySoftwareLicense
+}
+
+func (a ActivityTypeAddedSoftwareLicense) ActivityName() string {
+	return "added_software_license"
+}
+
+func (a ActivityTypeAddedSoftwareLicense) Documentation() (string, string, string) {
+	return `Generated when a user adds a license entitlement to a software title.`,
+		`This activity contains the following fields:
+` + activitySoftwareLicenseFields, `{
+` + activitySoftwareLicenseExample + `
+}`
+}
+
+type ActivityTypeEditedSoftwareLicense struct {
+	ActivitySoftwareLicense
+}
+
+func (a ActivityTypeEditedSoftwareLicense) ActivityName() string {
+	return "edited_software_license"
+}
+
+func (a ActivityTypeEditedSoftwareLicense) Documentation() (string, string, string) {
+	return `Generated when a user edits the license entitlement of a software title.`,
+		`This activity contains the following fields:
+` + activitySoftwareLicenseFields, `{
+` + activitySoftwareLicenseExample + `
+}`
+}
+
+type ActivityTypeDeletedSoftwareLicense struct {
+	ActivitySoftwareLicense
+}
+
+func (a ActivityTypeDeletedSoftwareLicense) ActivityName() string {
+	return "deleted_software_license"
+}
+
+func (a ActivityTypeDeletedSoftwareLicense) Documentation() (string, string, string) {
+	return `Generated when a user deletes the license entitlement of a software title.`,
+		`This activity contains the following fields:
+` + activitySoftwareLicenseFields, `{
+` + activitySoftwareLicenseExample + `
+}`
+}
+
 type ActivityAddedNDESSCEPProxy struct{}
 
 func (a ActivityAddedNDESSCEPProxy) ActivityName() string {
@@ -2059,3 +2874,153 @@ func (a ActivityEditedNDESSCEPProxy) ActivityName() string {
 func (a ActivityEditedNDESSCEPProxy) Documentation() (activity string, details string, detailsExample string) {
 	return "Generated when NDES SCEP proxy configuration is edited in MDMlab.", `This activity does not contain any detail fields.`, ``
 }
+
+type ActivityTypeCreatedCustomCVEMatchingRule struct {
+	ID                uint     `json:"id"`
+	NameLikeMatch     string   `json:"name_like_match"`
+	SourceMatch       string   `json:"source_match"`
+	CVEs              []string `json:"cves"`
+	ResolvedInVersion string   `json:"resolved_in_version"`
+}
+
+func (a ActivityTypeCreatedCustomCVEMatchingRule) ActivityName() string {
+	return "created_custom_cve_matching_rule"
+}
+
+func (a ActivityTypeCreatedCustomCVEMatchingRule) Documentation() (activity string, details string, detailsExample string) {
+	return `Generated when a user adds a custom CVE matching rule.`,
+		`This activity contains the following fields:
+- "id": ID of the rule.
+- "name_like_match": Software name pattern matched by the rule.
+- "source_match": Software source matched by the rule, empty if it matches any source.
+- "cves": CVEs assigned to the matching software.
+- "resolved_in_version": Software version that resolves the CVEs.`, `{
+  "id": 1,
+  "name_like_match": "Acme Agent%",
+  "source_match": "deb_packages",
+  "cves": ["CVE-2024-12345"],
+  "resolved_in_version": "2.0.1"
+}`
+}
+
+type ActivityTypeDeletedCustomCVEMatchingRule struct {
+	ID                uint     `json:"id"`
+	NameLikeMatch     string   `json:"name_like_match"`
+	SourceMatch       string   `json:"source_match"`
+	CVEs              []string `json:"cves"`
+	ResolvedInVersion string   `json:"resolved_in_version"`
+}
+
+func (a ActivityTypeDeletedCustomCVEMatchingRule) ActivityName() string {
+	return "deleted_custom_cve_matching_rule"
+}
+
+func (a ActivityTypeDeletedCustomCVEMatchingRule) Documentation() (activity string, details string, detailsExample string) {
+	return `Generated when a user deletes a custom CVE matching rule.`,
+		`This activity contains the following fields:
+- "id": ID of the rule.
+- "name_like_match": Software name pattern matched by the rule.
+- "source_match": Software source matched by the rule, empty if it matches any source.
+- "cves": CVEs assigned to the matching software.
+- "resolved_in_version": Software version that resolves the CVEs.`, `{
+  "id": 1,
+  "name_like_match": "Acme Agent%",
+  "source_match": "deb_packages",
+  "cves": ["CVE-2024-12345"],
+  "resolved_in_version": "2.0.1"
+}`
+}
+
+type ActivityTypeAppliedSpecCustomCVEMatchingRules struct {
+	RulesCount int `json:"rules_count"`
+}
+
+func (a ActivityTypeAppliedSpecCustomCVEMatchingRules) ActivityName() string {
+	return "applied_spec_custom_cve_matching_rules"
+}
+
+func (a ActivityTypeAppliedSpecCustomCVEMatchingRules) Documentation() (activity string, details string, detailsExample string) {
+	return `Generated when the custom CVE matching rules are replaced by applying a spec (e.g. via GitOps).`,
+		`This activity contains the following fields:
+- "rules_count": Number of custom CVE matching rules after applying the spec.`, `{
+  "rules_count": 3
+}`
+}
+
+type ActivityTypeCreatedVulnerabilitySuppression struct {
+	ID              uint             `json:"id"`
+	CVE             string           `json:"cve"`
+	TeamID          *uint            `json:"team_id"`
+	SoftwareTitleID *uint            `json:"software_title_id"`
+	HostID          *uint            `json:"host_id"`
+	Justification   VEXJustification `json:"justification"`
+	ExpiresAt       *time.Time       `json:"expires_at"`
+}
+
+func (a ActivityTypeCreatedVulnerabilitySuppression) ActivityName() string {
+	return "created_vulnerability_suppression"
+}
+
+func (a ActivityTypeCreatedVulnerabilitySuppression) Documentation() (activity string, details string, detailsExample string) {
+	return `Generated when a user suppresses a vulnerability (CVE).`,
+		`This activity contains the following fields:
+- "id": ID of the suppression.
+- "cve": The suppressed CVE.
+- "team_id": ID of the team the suppression applies to (0 for "No team"), null if it doesn't apply to a team.
+- "software_title_id": ID of the software title the suppression applies to, null if it doesn't apply to a software title.
+- "host_id": ID of the host the suppression applies to, null if it doesn't apply to a host.
+- "justification": The VEX justification of the suppression.
+- "expires_at": Time after which the suppression no longer applies, null if it doesn't expire.`, `{
+  "id": 1,
+  "cve": "CVE-2024-12345",
+  "team_id": 2,
+  "software_title_id": null,
+  "host_id": null,
+  "justification": "vulnerable_code_not_in_execute_path",
+  "expires_at": "2025-06-30T00:00:00Z"
+}`
+}
+
+type ActivityTypeDeletedVulnerabilitySuppression struct {
+	ID              uint   `json:"id"`
+	CVE             string `json:"cve"`
+	TeamID          *uint  `json:"team_id"`
+	SoftwareTitleID *uint  `json:"software_title_id"`
+	HostID          *uint  `json:"host_id"`
+}
+
+func (a ActivityTypeDeletedVulnerabilitySuppression) ActivityName() string {
+	return "deleted_vulnerability_suppression"
+}
+
+func (a ActivityTypeDeletedVulnerabilitySuppression) Documentation() (activity string, details string, detailsExample string) {
+	return `Generated when a user deletes a vulnerability (CVE) suppression.`,
+		`This activity contains the following fields:
+- "id": ID of the suppression.
+- "cve": The suppressed CVE.
+- "team_id": ID of the team the suppression applied to (0 for "No team"), null if it didn't apply to a team.
+- "software_title_id": ID of the software title the suppression applied to, null if it didn't apply to a software title.
+- "host_id": ID of the host the suppression applied to, null if it didn't apply to a host.`, `{
+  "id": 1,
+  "cve": "CVE-2024-12345",
+  "team_id": null,
+  "software_title_id": 12,
+  "host_id": null
+}`
+}
+
+type ActivityTypeImportedVEXDocument struct {
+	SuppressionsCount int `json:"suppressions_count"`
+}
+
+func (a ActivityTypeImportedVEXDocument) ActivityName() string {
+	return "imported_vex_document"
+}
+
+func (a ActivityTypeImportedVEXDocument) Documentation() (activity string, details string, detailsExample string) {
+	return `Generated when a user imports the "not affected" statements of a VEX document as vulnerability suppressions.`,
+		`This activity contains the following fields:
+- "suppressions_count": Number of vulnerability suppressions created or updated by the import.`, `{
+  "suppressions_count": 4
+}`
+}
@@ -118,6 +118,14 @@ type SMTPSettings struct {
 type VulnerabilitySettings struct {
 	// DatabasesPath is the directory where mdmlab will store the different databases
 	DatabasesPath string `json:"databases_path"`
+	// SLARules are the rules used to compute the date by which a vulnerability
+	// detected on a host must be remediated.
+	SLARules []VulnerabilitySLARule `json:"sla_rules"`
+	// PreferredCVSSScore is the CVSS score (source and version) used for
+	// sorting vulnerabilities and for SLA decisions when a CVE has multiple
+	// scores. Defaults to the NVD CVSS v3.x score. Changes apply the next time
+	// the CVE metadata is loaded by the vulnerabilities cron.
+	PreferredCVSSScore CVSSScorePreference `json:"preferred_cvss_score"`
 }
 
 // MDMAppleABMAssignmentInfo represents an user definition of the association
@@ -338,6 +346,9 @@ type MacOSSettings struct {
 	// (The source of truth for profiles is in MySQL.)
 	CustomSettings                 []MDMProfileSpec `json:"custom_settings"`
 	DeprecatedEnableDiskEncryption *bool            `json:"enable_disk_encryption,omitempty"`
+	// RecoveryLock configures the recovery lock (Apple silicon) or firmware
+	// password (Intel) that MDMlab sets, escrows and rotates on macOS hosts.
+	RecoveryLock *MacOSRecoveryLock `json:"recovery_lock,omitempty"`
 
 	// NOTE: make sure to update the ToMap/FromMap methods when adding/updating fields.
 }
@@ -346,9 +357,39 @@ func (s MacOSSettings) ToMap() map[string]interface{} {
 	return map[string]interface{}{
 		"custom_settings":        s.CustomSettings,
 		"enable_disk_encryption": s.DeprecatedEnableDiskEncryption,
+		"recovery_lock":          s.RecoveryLock,
 	}
 }
 
+// RecoveryLockEnabled returns true if the managed recovery lock is turned on.
+func (s MacOSSettings) RecoveryLockEnabled() bool {
+	return s.RecoveryLock != nil && s.RecoveryLock.Enable
+}
+
+// MacOSRecoveryLock contains the settings of the managed recovery lock. When
+// enabled, MDMlab generates a random password for each macOS host, sets it via
+// MDM and escrows it.
+type MacOSRecoveryLock struct {
+	Enable bool `json:"enable"`
+	// RotationIntervalDays is the number of days after which the password is
+	// rotated. If zero, the password is only rotated after it's revealed.
+	RotationIntervalDays int `json:"rotation_interval_days"`
+}
+
+func (r MacOSRecoveryLock) Validate() error {
+	const maxRotationIntervalDays = 365
+	if r.RotationIntervalDays < 0 || r.RotationIntervalDays > maxRotationIntervalDays {
+		return fmt.Errorf("rotation_interval_days must be an integer between 0 and %d", maxRotationIntervalDays)
+	}
+	return nil
+}
+
+// RotationInterval returns the interval after which the password must be
+// rotated, or zero if it's not rotated on a schedule.
+func (r MacOSRecoveryLock) RotationInterval() time.Duration {
+	return time.Duration(r.RotationIntervalDays) * 24 * time.Hour
+}
+
 // FromMap sets the macOS settings from the provided map, which is the map type
 // from the ApplyTeams spec struct. It returns a map of fields that were set in
 // the map (ie. the key was present even if empty) or an error. If the
@@ -417,6 +458,28 @@ func (s *MacOSSettings) FromMap(m map[string]interface{}) (map[string]bool, erro
 		s.DeprecatedEnableDiskEncryption = ptr.Bool(b)
 	}
 
+	if v, ok := m["recovery_lock"]; ok {
+		set["recovery_lock"] = true
+		if v == nil {
+			s.RecoveryLock = nil
+		} else {
+			// round-trip through JSON to decode the free-form map into the struct
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			var rl MacOSRecoveryLock
+			if err := json.Unmarshal(b, &rl); err != nil {
+				return nil, &json.UnmarshalTypeError{
+					Value: fmt.Sprintf("%T", v),
+					Type:  reflect.TypeOf(rl),
+					Field: "macos_settings.recovery_lock",
+				}
+			}
+			s.RecoveryLock = &rl
+		}
+	}
+
 	return set, nil
 }
 
@@ -509,6 +572,9 @@ type AppConfig struct {
 	// VulnerabilitySettings defines how mdmlab will behave while scanning for vulnerabilities in the host software
 	VulnerabilitySettings VulnerabilitySettings `json:"vulnerability_settings"`
 
+	// SoftwareInstallerSettings defines how mdmlab verifies the software installer packages that are added
+	SoftwareInstallerSettings SoftwareInstallerSettings `json:"software_installer_settings"`
+
 	WebhookSettings WebhookSettings `json:"webhook_settings"`
 	Integrations    Integrations    `json:"integrations"`
 
@@ -606,7 +672,24 @@ func (c *AppConfig) Copy() *AppConfig {
 	}
 
 	// MDMlabDesktop: nothing needs cloning
-	// VulnerabilitySettings: nothing needs cloning
+
+	if c.VulnerabilitySettings.SLARules != nil {
+		clone.VulnerabilitySettings.SLARules = make([]VulnerabilitySLARule, len(c.VulnerabilitySettings.SLARules))
+		for i, r := range c.VulnerabilitySettings.SLARules {
+			clone.VulnerabilitySettings.SLARules[i] = *r.Copy()
+		}
+	}
+
+	if c.SoftwareInstallerSettings.TrustedGPGKeys != nil {
+		clone.SoftwareInstallerSettings.TrustedGPGKeys = make([]string, len(c.SoftwareInstallerSettings.TrustedGPGKeys))
+		copy(clone.SoftwareInstallerSettings.TrustedGPGKeys, c.SoftwareInstallerSettings.TrustedGPGKeys)
+	}
+	if c.SoftwareInstallerSettings.Caches != nil {
+		clone.SoftwareInstallerSettings.Caches = make([]SoftwareInstallerCache, len(c.SoftwareInstallerSettings.Caches))
+		for i, cache := range c.SoftwareInstallerSettings.Caches {
+			clone.SoftwareInstallerSettings.Caches[i] = cache.Copy()
+		}
+	}
 
 	if c.WebhookSettings.FailingPoliciesWebhook.PolicyIDs != nil {
 		clone.WebhookSettings.FailingPoliciesWebhook.PolicyIDs = make([]uint, len(c.WebhookSettings.FailingPoliciesWebhook.PolicyIDs))
@@ -646,6 +729,10 @@ func (c *AppConfig) Copy() *AppConfig {
 		b := *c.MDM.MacOSSettings.DeprecatedEnableDiskEncryption
 		clone.MDM.MacOSSettings.DeprecatedEnableDiskEncryption = &b
 	}
+	if c.MDM.MacOSSettings.RecoveryLock != nil {
+		rl := *c.MDM.MacOSSettings.RecoveryLock
+		clone.MDM.MacOSSettings.RecoveryLock = &rl
+	}
 
 	if c.Scripts.Set {
 		scripts := make([]string, len(c.Scripts.Value))
@@ -796,6 +883,12 @@ type WebhookSettings struct {
 	HostStatusWebhook      HostStatusWebhookSettings      `json:"host_status_webhook"`
 	FailingPoliciesWebhook FailingPoliciesWebhookSettings `json:"failing_policies_webhook"`
 	VulnerabilitiesWebhook VulnerabilitiesWebhookSettings `json:"vulnerabilities_webhook"`
+	// CertificateExpirationWebhook configures the alerts sent for host
+	// certificates that are about to expire.
+	CertificateExpirationWebhook CertificateExpirationWebhookSettings `json:"certificate_expiration_webhook"`
+	// SoftwareRequestsWebhook configures the notifications sent when an end
+	// user requests software from the My device page.
+	SoftwareRequestsWebhook SoftwareRequestsWebhookSettings `json:"software_requests_webhook"`
 	// Interval is the interval for running the webhooks.
 	//
 	// This value currently configures both the host status and failing policies webhooks.
@@ -836,6 +929,30 @@ type VulnerabilitiesWebhookSettings struct {
 	// HostBatchSize allows sending multiple requests in batches of hosts for each vulnerable software found.
 	// A value of 0 means no batching.
 	HostBatchSize int `json:"host_batch_size"`
+	// EnableSLABreachAlerts indicates whether the webhook is also sent when a
+	// vulnerability is not remediated on a host by its SLA due date.
+	EnableSLABreachAlerts bool `json:"enable_sla_breach_alerts"`
+}
+
+// CertificateExpirationWebhookSettings holds the settings for the host
+// certificate expiration webhook.
+type CertificateExpirationWebhookSettings struct {
+	// Enable indicates whether the webhook for expiring certificates is enabled.
+	Enable bool `json:"enable_certificate_expiration_webhook"`
+	// DestinationURL is the webhook's URL.
+	DestinationURL string `json:"destination_url"`
+	// DaysBeforeExpiration is the window, in days, before a certificate's
+	// expiration date during which it is reported as expiring.
+	DaysBeforeExpiration int `json:"days_before_expiration"`
+}
+
+// SoftwareRequestsWebhookSettings holds the settings for the software
+// requests webhook.
+type SoftwareRequestsWebhookSettings struct {
+	// Enable indicates whether the webhook for software requests is enabled.
+	Enable bool `json:"enable_software_requests_webhook"`
+	// DestinationURL is the webhook's URL.
+	DestinationURL string `json:"destination_url"`
 }
 
 func (c *AppConfig) ApplyDefaultsForNewInstalls() {
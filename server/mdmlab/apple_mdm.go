@@ -24,6 +24,95 @@ type MDMAppleCommandIssuer interface {
 	InstallEnterpriseApplication(ctx context.Context, hostUUIDs []string, uuid string, manifestURL string) error
 	InstallApplication(ctx context.Context, hostUUIDs []string, uuid string, adamID string) error
 	DeviceConfigured(ctx context.Context, hostUUID, cmdUUID string) error
+	RestartDevice(ctx context.Context, hostUUIDs []string, uuid string, notifyUser bool) error
+	ShutDownDevice(ctx context.Context, hostUUIDs []string, uuid string) error
+	EnableLostMode(ctx context.Context, host *Host, uuid string, opts MDMAppleLostModeOptions) error
+	DisableLostMode(ctx context.Context, host *Host, uuid string) error
+	PlayLostModeSound(ctx context.Context, hostUUIDs []string, uuid string) error
+	DeviceLocation(ctx context.Context, hostUUIDs []string, uuid string) error
+	SetRecoveryLock(ctx context.Context, hostUUIDs []string, uuid, currentPassword, newPassword string) error
+	SetFirmwarePassword(ctx context.Context, hostUUIDs []string, uuid, currentPassword, newPassword string) error
+	SecurityInfo(ctx context.Context, hostUUIDs []string, uuid string) error
+	CertificateList(ctx context.Context, hostUUIDs []string, uuid string, managedOnly bool) error
+}
+
+// MDMAppleLostModeOptions contains the information displayed on the lock
+// screen of a device in lost mode. At least one of Message or PhoneNumber is
+// required by Apple.
+type MDMAppleLostModeOptions struct {
+	Message     string `json:"message"`
+	PhoneNumber string `json:"phone_number"`
+	Footnote    string `json:"footnote"`
+}
+
+// HostMDMAppleDeviceLocation is the location reported by an Apple device in
+// response to a DeviceLocation MDM command.
+type HostMDMAppleDeviceLocation struct {
+	HostUUID           string    `json:"-" db:"host_uuid"`
+	CommandUUID        string    `json:"-" db:"command_uuid"`
+	Latitude           float64   `json:"latitude" db:"latitude"`
+	Longitude          float64   `json:"longitude" db:"longitude"`
+	Altitude           *float64  `json:"altitude" db:"altitude"`
+	HorizontalAccuracy *float64  `json:"horizontal_accuracy" db:"horizontal_accuracy"`
+	VerticalAccuracy   *float64  `json:"vertical_accuracy" db:"vertical_accuracy"`
+	LocatedAt          time.Time `json:"located_at" db:"located_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Types of the password set by the managed recovery lock, depending on the
+// host's architecture.
+const (
+	// RecoveryLockPasswordTypeRecoveryLock is set via SetRecoveryLock on Apple
+	// silicon Macs.
+	RecoveryLockPasswordTypeRecoveryLock = "recovery_lock"
+	// RecoveryLockPasswordTypeFirmwarePassword is set via SetFirmwarePassword
+	// on Intel Macs.
+	RecoveryLockPasswordTypeFirmwarePassword = "firmware_password"
+)
+
+const (
+	// RecoveryLockRetryInterval is the time after which MDMlab retries to set
+	// the recovery lock of a host after a failure.
+	RecoveryLockRetryInterval = 24 * time.Hour
+	// RecoveryLockRevealRotationDelay is the time after which the recovery
+	// lock of a host is rotated once it has been revealed to a user.
+	RecoveryLockRevealRotationDelay = time.Hour
+)
+
+// HostRecoveryLock is the recovery lock or firmware password escrowed by
+// MDMlab for a macOS host.
+type HostRecoveryLock struct {
+	HostUUID     string `json:"-" db:"host_uuid"`
+	PasswordType string `json:"type" db:"password_type"`
+	// Password is the decrypted password currently set on the host, empty if
+	// it was never verified.
+	Password string `json:"password" db:"-"`
+	// PendingPassword is the decrypted password being set on the host, empty
+	// if there's no rotation in progress.
+	PendingPassword   string            `json:"-" db:"-"`
+	Status            MDMDeliveryStatus `json:"status" db:"status"`
+	Detail            string            `json:"detail" db:"detail"`
+	CommandUUID       string            `json:"-" db:"command_uuid"`
+	VerifyCommandUUID *string           `json:"-" db:"verify_command_uuid"`
+	RotateAt          *time.Time        `json:"rotate_at" db:"rotate_at"`
+	RevealedAt        *time.Time        `json:"revealed_at" db:"revealed_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// HostRecoveryLockTarget is a macOS host for which the managed recovery lock
+// must be set or rotated.
+type HostRecoveryLockTarget struct {
+	HostID   uint   `db:"host_id"`
+	HostUUID string `db:"host_uuid"`
+	CPUType  string `db:"cpu_type"`
+}
+
+// PasswordType returns the type of password supported by the host.
+func (t HostRecoveryLockTarget) PasswordType() string {
+	if strings.HasPrefix(t.CPUType, "arm64") {
+		return RecoveryLockPasswordTypeRecoveryLock
+	}
+	return RecoveryLockPasswordTypeFirmwarePassword
 }
 
 // MDMAppleEnrollmentType is the type for Apple MDM enrollments.
@@ -536,6 +625,132 @@ type MDMAppleCommand struct {
 	TeamID *uint `json:"-" db:"team_id"`
 }
 
+// MDMAppleCommandQueueStatus is the status of an Apple MDM command in a
+// host's nanomdm queue.
+type MDMAppleCommandQueueStatus string
+
+const (
+	// MDMAppleCommandQueueStatusPending is the status of a command that has not
+	// been delivered to the device yet (or that was delivered but the device
+	// has not reported any result).
+	MDMAppleCommandQueueStatusPending MDMAppleCommandQueueStatus = "Pending"
+	// MDMAppleCommandQueueStatusAcknowledged is the status of a command that
+	// the device processed successfully.
+	MDMAppleCommandQueueStatusAcknowledged MDMAppleCommandQueueStatus = "Acknowledged"
+	// MDMAppleCommandQueueStatusError is the status of a command that the
+	// device reported as failed.
+	MDMAppleCommandQueueStatusError MDMAppleCommandQueueStatus = "Error"
+	// MDMAppleCommandQueueStatusCommandFormatError is the status of a command
+	// that the device could not parse.
+	MDMAppleCommandQueueStatusCommandFormatError MDMAppleCommandQueueStatus = "CommandFormatError"
+	// MDMAppleCommandQueueStatusNotNow is the status of a command that the
+	// device could not process at the moment (e.g. because it was locked) and
+	// that will be retried at a later check-in.
+	MDMAppleCommandQueueStatusNotNow MDMAppleCommandQueueStatus = "NotNow"
+)
+
+// IsValid returns true if the status is one of the known queue statuses.
+func (s MDMAppleCommandQueueStatus) IsValid() bool {
+	switch s {
+	case MDMAppleCommandQueueStatusPending,
+		MDMAppleCommandQueueStatusAcknowledged,
+		MDMAppleCommandQueueStatusError,
+		MDMAppleCommandQueueStatusCommandFormatError,
+		MDMAppleCommandQueueStatusNotNow:
+		return true
+	default:
+		return false
+	}
+}
+
+// MDMAppleNonCancelableRequestTypes are the request types of the commands
+// whose delivery is tracked outside of the command queue (configuration
+// profiles, declarations, lock and wipe actions, lost mode, recovery lock
+// and software installs). Canceling them via the command queue would leave
+// that state pending forever, so they can't be canceled.
+var MDMAppleNonCancelableRequestTypes = []string{
+	"InstallProfile",
+	"RemoveProfile",
+	"DeclarativeManagement",
+	"DeviceLock",
+	"EraseDevice",
+	"EnableLostMode",
+	"DisableLostMode",
+	"SetRecoveryLock",
+	"SetFirmwarePassword",
+	"SecurityInfo",
+	"InstallEnterpriseApplication",
+	"InstallApplication",
+}
+
+// IsMDMAppleCommandCancelable returns true if the commands of the given
+// request type can be canceled via the command queue.
+func IsMDMAppleCommandCancelable(requestType string) bool {
+	for _, rt := range MDMAppleNonCancelableRequestTypes {
+		if rt == requestType {
+			return false
+		}
+	}
+	return true
+}
+
+// MDMAppleCommandQueueItem represents an entry of a host's nanomdm command
+// queue, that is a command enqueued for a host along with its current
+// delivery status.
+type MDMAppleCommandQueueItem struct {
+	// HostUUID is the UUID of the host (the device channel enrollment ID).
+	HostUUID string `json:"host_uuid" db:"host_uuid"`
+	// HostID is the MDMlab ID of the host.
+	HostID uint `json:"host_id" db:"host_id"`
+	// Hostname is the hostname of the host.
+	Hostname string `json:"hostname" db:"hostname"`
+	// TeamID is the host's team, null if the host is in no team.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// CommandUUID is the unique identifier of the command.
+	CommandUUID string `json:"command_uuid" db:"command_uuid"`
+	// RequestType is the command's request type.
+	RequestType string `json:"request_type" db:"request_type"`
+	// Status is the command status in the queue of this host.
+	Status MDMAppleCommandQueueStatus `json:"status" db:"status"`
+	// NotNowCount is the number of times the device responded NotNow to
+	// the command.
+	NotNowCount int `json:"not_now_count" db:"not_now_count"`
+	// NotNowAt is the timestamp of the first NotNow response, if any.
+	NotNowAt *time.Time `json:"not_now_at" db:"not_now_at"`
+	// LastSeenAt is the last time the device checked in with the MDM server.
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+	// CreatedAt is the time the command was enqueued for the host.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// UpdatedAt is the last update timestamp of the command result (or the
+	// enqueue time if there is no result yet).
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MDMAppleCommandQueueListOptions are the options to list the entries of the
+// Apple MDM command queues.
+type MDMAppleCommandQueueListOptions struct {
+	ListOptions
+	// HostIdentifier filters the queue of a single host. It can be any of the
+	// host identifiers (hostname, UUID, serial, etc.).
+	HostIdentifier string
+	// RequestType filters by the command's request type.
+	RequestType string
+	// CommandUUID filters by a specific command.
+	CommandUUID string
+	// Status filters by the command status.
+	Status MDMAppleCommandQueueStatus
+}
+
+// MDMAppleCommandQueuePushResult is the result of re-sending push
+// notifications to hosts with pending commands.
+type MDMAppleCommandQueuePushResult struct {
+	// HostUUIDs are the UUIDs of the hosts that were sent a push notification.
+	HostUUIDs []string `json:"host_uuids"`
+	// FailedHostUUIDs are the UUIDs of the hosts for which the push
+	// notification could not be delivered to APNs.
+	FailedHostUUIDs []string `json:"failed_host_uuids"`
+}
+
 // MDMAppleSetupAssistant represents the setup assistant set for a given team
 // or no team.
 type MDMAppleSetupAssistant struct {
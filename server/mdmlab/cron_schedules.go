@@ -28,6 +28,9 @@ const (
 	CronCalendar                    CronScheduleName = "calendar"
 	CronUninstallSoftwareMigration  CronScheduleName = "uninstall_software_migration"
 	CronMaintainedApps              CronScheduleName = "maintained_apps"
+	CronHostCertificatesRefetcher   CronScheduleName = "host_certificates_refetcher"
+	CronSoftwareRollouts            CronScheduleName = "software_rollouts"
+	CronSoftwareBlocklist           CronScheduleName = "software_blocklist"
 )
 
 type CronSchedulesService interface {
@@ -0,0 +1,82 @@
+package mdmlab
+
+import (
+	"strings"
+	"time"
+)
+
+// CustomCVEMatchingRule is an admin-managed rule for assigning a list of CVEs to software, used for
+// addressing false negatives in the vulnerability feeds (e.g. internally packaged software). The
+// rules are evaluated by the CustomSource vulnerability analyzer.
+type CustomCVEMatchingRule struct {
+	ID uint `json:"id" db:"id"`
+	// NameLikeMatch is matched against the software name using a SQL LIKE expression.
+	NameLikeMatch string `json:"name_like_match" db:"name_like_match"`
+	// SourceMatch is matched against the software source (e.g. 'programs', 'deb_packages'), an
+	// empty value matches software from any source.
+	SourceMatch string `json:"source_match" db:"source_match"`
+	// CVEs are the vulnerabilities assigned to the matching software.
+	CVEs []string `json:"cves" db:"-"`
+	// ResolvedInVersion is the software version that resolves the CVEs, only software with a
+	// lower version is matched.
+	ResolvedInVersion string    `json:"resolved_in_version" db:"resolved_in_version"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (r CustomCVEMatchingRule) AuthzType() string {
+	return "custom_cve_matching_rule"
+}
+
+// CustomCVEMatchingRuleSpec is the payload used for creating a custom CVE matching rule, either
+// via the API or via GitOps.
+type CustomCVEMatchingRuleSpec struct {
+	NameLikeMatch     string   `json:"name_like_match"`
+	SourceMatch       string   `json:"source_match"`
+	CVEs              []string `json:"cves"`
+	ResolvedInVersion string   `json:"resolved_in_version"`
+}
+
+// Validate returns an InvalidArgumentError if the spec is missing required fields or contains
+// malformed CVE identifiers.
+func (s *CustomCVEMatchingRuleSpec) Validate() error {
+	invalid := &InvalidArgumentError{}
+	if strings.TrimSpace(s.NameLikeMatch) == "" {
+		invalid.Append("name_like_match", "must be specified")
+	}
+	if strings.TrimSpace(s.ResolvedInVersion) == "" {
+		invalid.Append("resolved_in_version", "must be specified")
+	}
+	if len(s.CVEs) == 0 {
+		invalid.Append("cves", "at least one CVE must be specified")
+	}
+	for _, cve := range s.CVEs {
+		if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(cve)), "CVE-") {
+			invalid.Appendf("cves", "%q is not a valid CVE identifier", cve)
+		}
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return nil
+}
+
+// ToRule returns the rule described by the spec, with its values normalized.
+func (s *CustomCVEMatchingRuleSpec) ToRule() *CustomCVEMatchingRule {
+	cves := make([]string, 0, len(s.CVEs))
+	seen := make(map[string]struct{}, len(s.CVEs))
+	for _, cve := range s.CVEs {
+		cve = strings.ToUpper(strings.TrimSpace(cve))
+		if _, ok := seen[cve]; ok {
+			continue
+		}
+		seen[cve] = struct{}{}
+		cves = append(cves, cve)
+	}
+	return &CustomCVEMatchingRule{
+		NameLikeMatch:     strings.TrimSpace(s.NameLikeMatch),
+		SourceMatch:       strings.TrimSpace(s.SourceMatch),
+		CVEs:              cves,
+		ResolvedInVersion: strings.TrimSpace(s.ResolvedInVersion),
+	}
+}
@@ -0,0 +1,213 @@
+package mdmlab
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CVSSScore is a Common Vulnerability Scoring System (CVSS) base score of a
+// CVE, as reported by a given source. Represents an entry in the
+// `cve_cvss_scores` table.
+type CVSSScore struct {
+	CVE string `json:"-" db:"cve"`
+	// Version is the CVSS version of the score, e.g. "2.0", "3.1" or "4.0".
+	Version string `json:"version" db:"version"`
+	// Source is the identifier of the organization that reported the score,
+	// e.g. "nvd@nist.gov" or the CNA of the CVE.
+	Source string `json:"source" db:"source"`
+	// Primary is true if the score was assessed by NVD, false if it was
+	// reported by the CNA (NVD's "Secondary" scores).
+	Primary      bool    `json:"primary" db:"is_primary"`
+	BaseScore    float64 `json:"base_score" db:"base_score"`
+	VectorString string  `json:"vector_string" db:"vector_string"`
+	// AttackVector is parsed from the VectorString, it is empty if the vector
+	// could not be parsed.
+	AttackVector CVSSAttackVector `json:"attack_vector,omitempty" db:"attack_vector"`
+}
+
+// MajorVersion returns the major CVSS version of the score, e.g. "3" for
+// "3.1".
+func (s CVSSScore) MajorVersion() string {
+	major, _, _ := strings.Cut(s.Version, ".")
+	return major
+}
+
+// CVSSAttackVector is the context by which the exploitation of a vulnerability
+// is possible, as defined by the AV metric of the CVSS vectors.
+type CVSSAttackVector string
+
+const (
+	CVSSAttackVectorNetwork  CVSSAttackVector = "network"
+	CVSSAttackVectorAdjacent CVSSAttackVector = "adjacent_network"
+	CVSSAttackVectorLocal    CVSSAttackVector = "local"
+	CVSSAttackVectorPhysical CVSSAttackVector = "physical"
+)
+
+// IsValid returns true if the attack vector is one of the known values.
+func (v CVSSAttackVector) IsValid() bool {
+	switch v {
+	case CVSSAttackVectorNetwork, CVSSAttackVectorAdjacent, CVSSAttackVectorLocal, CVSSAttackVectorPhysical:
+		return true
+	}
+	return false
+}
+
+// CVSSVector is a parsed CVSS vector string.
+type CVSSVector struct {
+	// Version is the CVSS version of the vector, e.g. "2.0", "3.1" or "4.0".
+	Version string
+	// Metrics are the metric values of the vector by metric name, e.g. "AV" ->
+	// "N".
+	Metrics map[string]string
+}
+
+// AttackVector returns the attack vector of the CVSS vector.
+func (v CVSSVector) AttackVector() CVSSAttackVector {
+	switch v.Metrics["AV"] {
+	case "N":
+		return CVSSAttackVectorNetwork
+	case "A":
+		return CVSSAttackVectorAdjacent
+	case "L":
+		return CVSSAttackVectorLocal
+	case "P":
+		if v.Version != "2.0" {
+			return CVSSAttackVectorPhysical
+		}
+	}
+	return ""
+}
+
+// ParseCVSSVector parses a CVSS v2, v3.x or v4.0 vector string, e.g.
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". CVSS v2 vectors don't have
+// a version prefix, e.g. "AV:N/AC:L/Au:N/C:P/I:P/A:P".
+func ParseCVSSVector(vector string) (*CVSSVector, error) {
+	vector = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(vector), "("), ")")
+	if vector == "" {
+		return nil, errors.New("empty CVSS vector")
+	}
+
+	parts := strings.Split(vector, "/")
+	v := CVSSVector{Version: "2.0", Metrics: make(map[string]string, len(parts))}
+	if version, ok := strings.CutPrefix(parts[0], "CVSS:"); ok {
+		switch version {
+		case "3.0", "3.1", "4.0":
+			v.Version = version
+		default:
+			return nil, fmt.Errorf("unsupported CVSS version %q", version)
+		}
+		parts = parts[1:]
+	}
+
+	for _, part := range parts {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid CVSS metric %q", part)
+		}
+		if _, ok := v.Metrics[name]; ok {
+			return nil, fmt.Errorf("duplicate CVSS metric %q", name)
+		}
+		v.Metrics[name] = value
+	}
+
+	if v.AttackVector() == "" {
+		return nil, fmt.Errorf("invalid or missing CVSS attack vector %q", v.Metrics["AV"])
+	}
+	return &v, nil
+}
+
+// CVSSScorePreference is the CVSS score to use when a CVE has multiple scores
+// (e.g. the NVD and CNA scores, for multiple CVSS versions).
+type CVSSScorePreference string
+
+const (
+	// CVSSScorePreferenceNVDv3 prefers the CVSS v3.x score assessed by NVD.
+	// This is the default.
+	CVSSScorePreferenceNVDv3 CVSSScorePreference = "nvd_v3"
+	// CVSSScorePreferenceNVDv4 prefers the CVSS v4.0 score assessed by NVD.
+	CVSSScorePreferenceNVDv4 CVSSScorePreference = "nvd_v4"
+	// CVSSScorePreferenceCNAv3 prefers the CVSS v3.x score reported by the CNA.
+	CVSSScorePreferenceCNAv3 CVSSScorePreference = "cna_v3"
+	// CVSSScorePreferenceCNAv4 prefers the CVSS v4.0 score reported by the CNA.
+	CVSSScorePreferenceCNAv4 CVSSScorePreference = "cna_v4"
+	// CVSSScorePreferenceHighest uses the highest score of any version and
+	// source.
+	CVSSScorePreferenceHighest CVSSScorePreference = "highest"
+)
+
+// IsValid returns true if the preference is one of the known values (or empty,
+// which means the default).
+func (p CVSSScorePreference) IsValid() bool {
+	switch p {
+	case "", CVSSScorePreferenceNVDv3, CVSSScorePreferenceNVDv4, CVSSScorePreferenceCNAv3,
+		CVSSScorePreferenceCNAv4, CVSSScorePreferenceHighest:
+		return true
+	}
+	return false
+}
+
+// defaultCVSSScoreOrder is the order in which scores are picked if the
+// preferred score is not available, by source (true for NVD) and major CVSS
+// version.
+var defaultCVSSScoreOrder = []struct {
+	primary bool
+	major   string
+}{
+	{true, "3"},
+	{true, "4"},
+	{false, "3"},
+	{false, "4"},
+	{true, "2"},
+	{false, "2"},
+}
+
+// cvssScoreRank returns the rank of the score according to the preference,
+// lower is better.
+func cvssScoreRank(s CVSSScore, pref CVSSScorePreference) int {
+	switch pref {
+	case CVSSScorePreferenceNVDv4:
+		if s.Primary && s.MajorVersion() == "4" {
+			return 0
+		}
+	case CVSSScorePreferenceCNAv3:
+		if !s.Primary && s.MajorVersion() == "3" {
+			return 0
+		}
+	case CVSSScorePreferenceCNAv4:
+		if !s.Primary && s.MajorVersion() == "4" {
+			return 0
+		}
+	}
+	for i, o := range defaultCVSSScoreOrder {
+		if s.Primary == o.primary && s.MajorVersion() == o.major {
+			return i + 1
+		}
+	}
+	return len(defaultCVSSScoreOrder) + 1
+}
+
+// PreferredCVSSScore returns the score to use for the CVE according to the
+// preference, falling back to the other available scores if the preferred one
+// is not available. It returns nil if there are no scores.
+func PreferredCVSSScore(scores []CVSSScore, pref CVSSScorePreference) *CVSSScore {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	sorted := make([]CVSSScore, len(scores))
+	copy(sorted, scores)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if pref == CVSSScorePreferenceHighest && sorted[i].BaseScore != sorted[j].BaseScore {
+			return sorted[i].BaseScore > sorted[j].BaseScore
+		}
+		ri, rj := cvssScoreRank(sorted[i], pref), cvssScoreRank(sorted[j], pref)
+		if ri != rj {
+			return ri < rj
+		}
+		// prefer the latest minor version, e.g. 3.1 over 3.0
+		return sorted[i].Version > sorted[j].Version
+	})
+	return &sorted[0]
+}
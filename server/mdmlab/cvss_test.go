@@ -0,0 +1,86 @@
+package mdmlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCVSSVector(t *testing.T) {
+	cases := []struct {
+		vector        string
+		wantVersion   string
+		wantAttackVec CVSSAttackVector
+		wantErr       string
+	}{
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", "2.0", CVSSAttackVectorNetwork, ""},
+		{"(AV:A/AC:L/Au:N/C:P/I:P/A:P)", "2.0", CVSSAttackVectorAdjacent, ""},
+		{"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", "3.0", CVSSAttackVectorLocal, ""},
+		{"CVSS:3.1/AV:P/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "3.1", CVSSAttackVectorPhysical, ""},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "4.0", CVSSAttackVectorNetwork, ""},
+		{"", "", "", "empty CVSS vector"},
+		{"CVSS:5.0/AV:N", "", "", "unsupported CVSS version"},
+		{"CVSS:3.1/AV:N/AV:L", "", "", "duplicate CVSS metric"},
+		{"CVSS:3.1/AC:L", "", "", "missing CVSS attack vector"},
+		{"CVSS:3.1/AV:X", "", "", "invalid or missing CVSS attack vector"},
+		{"AV:P/AC:L", "", "", "invalid or missing CVSS attack vector"},
+		{"CVSS:3.1/AV", "", "", "invalid CVSS metric"},
+	}
+	for _, c := range cases {
+		t.Run(c.vector, func(t *testing.T) {
+			v, err := ParseCVSSVector(c.vector)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.wantVersion, v.Version)
+			require.Equal(t, c.wantAttackVec, v.AttackVector())
+		})
+	}
+}
+
+func TestPreferredCVSSScore(t *testing.T) {
+	nvdV2 := CVSSScore{Version: "2.0", Source: "nvd@nist.gov", Primary: true, BaseScore: 10}
+	nvdV30 := CVSSScore{Version: "3.0", Source: "nvd@nist.gov", Primary: true, BaseScore: 7.5}
+	nvdV31 := CVSSScore{Version: "3.1", Source: "nvd@nist.gov", Primary: true, BaseScore: 7.8}
+	nvdV4 := CVSSScore{Version: "4.0", Source: "nvd@nist.gov", Primary: true, BaseScore: 8.5}
+	cnaV31 := CVSSScore{Version: "3.1", Source: "secalert@example.com", BaseScore: 5.5}
+	cnaV4 := CVSSScore{Version: "4.0", Source: "secalert@example.com", BaseScore: 6.1}
+
+	all := []CVSSScore{nvdV2, cnaV4, cnaV31, nvdV4, nvdV30, nvdV31}
+	cases := []struct {
+		name   string
+		scores []CVSSScore
+		pref   CVSSScorePreference
+		want   *CVSSScore
+	}{
+		{"no scores", nil, CVSSScorePreferenceNVDv3, nil},
+		{"default", all, "", &nvdV31},
+		{"nvd v3", all, CVSSScorePreferenceNVDv3, &nvdV31},
+		{"nvd v3.0 only", []CVSSScore{nvdV2, nvdV30}, CVSSScorePreferenceNVDv3, &nvdV30},
+		{"nvd v4", all, CVSSScorePreferenceNVDv4, &nvdV4},
+		{"cna v3", all, CVSSScorePreferenceCNAv3, &cnaV31},
+		{"cna v4", all, CVSSScorePreferenceCNAv4, &cnaV4},
+		{"highest", all, CVSSScorePreferenceHighest, &nvdV2},
+		{"cna v4 fallback to nvd v3", []CVSSScore{nvdV2, nvdV31, cnaV31}, CVSSScorePreferenceCNAv4, &nvdV31},
+		{"nvd v3 fallback to nvd v4", []CVSSScore{nvdV2, cnaV31, nvdV4}, CVSSScorePreferenceNVDv3, &nvdV4},
+		{"nvd v3 fallback to cna v3", []CVSSScore{nvdV2, cnaV4, cnaV31}, CVSSScorePreferenceNVDv3, &cnaV31},
+		{"nvd v3 fallback to v2", []CVSSScore{nvdV2}, CVSSScorePreferenceNVDv3, &nvdV2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, PreferredCVSSScore(c.scores, c.pref))
+		})
+	}
+
+	meta := CVEMeta{CVE: "CVE-2024-0001", CVSSScores: all}
+	meta.ApplyCVSSScorePreference(CVSSScorePreferenceCNAv4)
+	require.Equal(t, 6.1, *meta.CVSSScore)
+	require.Equal(t, "4.0", meta.CVSSVersion)
+	require.Equal(t, "secalert@example.com", meta.CVSSSource)
+
+	require.True(t, CVSSScorePreference("").IsValid())
+	require.True(t, CVSSScorePreferenceHighest.IsValid())
+	require.False(t, CVSSScorePreference("nvd_v5").IsValid())
+}
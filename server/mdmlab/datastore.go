@@ -352,6 +352,29 @@ type Datastore interface {
 	RemoveHostMDMCommand(ctx context.Context, command HostMDMCommand) error
 	// CleanupHostMDMCommands removes invalid and stale MDM commands sent to hosts.
 	CleanupHostMDMCommands(ctx context.Context) error
+
+	// ListHostsToRefetchCertificates returns up to limit MDM-enrolled hosts
+	// whose certificate inventory hasn't been refreshed in the given interval
+	// and for which no refetch is in progress.
+	ListHostsToRefetchCertificates(ctx context.Context, interval time.Duration, limit int) ([]*HostCertificateRefetchTarget, error)
+	// ReplaceHostCertificates replaces the certificates of the host reported
+	// by the given source and records that the host's inventory was refreshed.
+	ReplaceHostCertificates(ctx context.Context, hostID uint, source HostCertificateSource, certs []*HostCertificate) error
+	// SetHostCertificatesManaged marks the host's certificates identified by
+	// the SHA-1 sums as delivered by MDMlab, and all others as not.
+	SetHostCertificatesManaged(ctx context.Context, hostID uint, source HostCertificateSource, sha1Sums []string) error
+	// ListHostCertificates lists the certificates installed on the host.
+	ListHostCertificates(ctx context.Context, hostID uint, opt ListOptions) ([]*HostCertificate, *PaginationMetadata, error)
+	// SearchHostCertificates lists the certificates installed on all hosts
+	// visible to the user of the team filter.
+	SearchHostCertificates(ctx context.Context, filter TeamFilter, opt HostCertificateListOptions) ([]*HostCertificateWithHost, *PaginationMetadata, error)
+	// ListExpiringHostCertificatesToNotify returns up to limit certificates
+	// that expire within the given duration and for which no expiration alert
+	// was sent yet.
+	ListExpiringHostCertificatesToNotify(ctx context.Context, within time.Duration, limit int) ([]*HostCertificateWithHost, error)
+	// SetHostCertificatesExpirationNotified records that an expiration alert
+	// was sent for the host certificates.
+	SetHostCertificatesExpirationNotified(ctx context.Context, ids []uint) error
 	// CleanupHostMDMAppleProfiles removes abandoned host MDM Apple profiles entries.
 	CleanupHostMDMAppleProfiles(ctx context.Context) error
 
@@ -618,6 +641,135 @@ type Datastore interface {
 	// given host ID by labels.
 	IsSoftwareInstallerLabelScoped(ctx context.Context, installerID, hostID uint) (bool, error)
 
+	// IsSoftwareInstallerRolloutScoped returns whether or not the given host is
+	// targeted by the reached stages of the staged rollout of the given
+	// installerID. It returns true if the installer has no rollout.
+	IsSoftwareInstallerRolloutScoped(ctx context.Context, installerID, hostID uint) (bool, error)
+
+	// SetSoftwareInstallerRollout creates the staged rollout of a software
+	// installer, or restarts it from the first stage if it already exists.
+	SetSoftwareInstallerRollout(ctx context.Context, rollout *SoftwareInstallerRollout) (*SoftwareInstallerRollout, error)
+	// GetSoftwareInstallerRollout returns the staged rollout of the software
+	// installer.
+	GetSoftwareInstallerRollout(ctx context.Context, installerID uint) (*SoftwareInstallerRollout, error)
+	// ListActiveSoftwareInstallerRollouts returns the rollouts that are in
+	// progress or paused.
+	ListActiveSoftwareInstallerRollouts(ctx context.Context) ([]*SoftwareInstallerRollout, error)
+	// UpdateSoftwareInstallerRollout updates the version, status and current
+	// stage of the rollout.
+	UpdateSoftwareInstallerRollout(ctx context.Context, rollout *SoftwareInstallerRollout) error
+	// DeleteSoftwareInstallerRollout deletes the staged rollout of the software
+	// installer, making it available to all hosts in scope.
+	DeleteSoftwareInstallerRollout(ctx context.Context, installerID uint) error
+	// GetSoftwareInstallerRolloutStats returns the results of the installs of the
+	// software installer requested since the given time.
+	GetSoftwareInstallerRolloutStats(ctx context.Context, installerID uint, since time.Time) (*SoftwareInstallerRolloutStats, error)
+	// ListSoftwareInstallerRolloutHostsToInstall returns the hosts targeted by the
+	// reached stages of the rollout that fail a policy automatically installing
+	// the software and had no install requested since the start of the rollout.
+	ListSoftwareInstallerRolloutHostsToInstall(ctx context.Context, rollout *SoftwareInstallerRollout) ([]*SoftwareInstallerRolloutHost, error)
+
+	// ListSoftwareInstallerVersions returns the versions of the software
+	// installer, the latest first.
+	ListSoftwareInstallerVersions(ctx context.Context, installerID uint) ([]*SoftwareInstallerVersion, error)
+	// GetSoftwareInstallerVersion returns the version of the software installer.
+	GetSoftwareInstallerVersion(ctx context.Context, installerID, versionID uint) (*SoftwareInstallerVersion, error)
+	// PinSoftwareInstallerVersion pins the version of the software installer,
+	// a nil versionID unpins it.
+	PinSoftwareInstallerVersion(ctx context.Context, installerID uint, versionID *uint) error
+	// RollbackSoftwareInstallerVersion makes the version the current version of
+	// the software installer, cancels the pending installs and unpins it.
+	RollbackSoftwareInstallerVersion(ctx context.Context, installerID, versionID uint) error
+	// SetSoftwareInstallerVersionLabels replaces the labels whose members get
+	// the version of the software installer.
+	SetSoftwareInstallerVersionLabels(ctx context.Context, installerID, versionID uint, labelIDs []uint) error
+	// DeleteSoftwareInstallerVersion deletes a previous version of the software
+	// installer, it fails for the current version.
+	DeleteSoftwareInstallerVersion(ctx context.Context, installerID, versionID uint) error
+	// GetPendingSoftwareInstallerVersion returns the version of the software
+	// installer to download for the pending install of the host, or nil if the
+	// host gets the current version.
+	GetPendingSoftwareInstallerVersion(ctx context.Context, hostID, installerID uint) (*SoftwareInstallerVersion, error)
+
+	// NewSoftwareBlocklistRule creates a software blocklist rule, the hosts
+	// members of the labels are excepted from the rule.
+	NewSoftwareBlocklistRule(ctx context.Context, rule *SoftwareBlocklistRule, labelIDs []uint) (*SoftwareBlocklistRule, error)
+	// UpdateSoftwareBlocklistRule replaces the software, version range, removal
+	// script and excepted labels of the rule.
+	UpdateSoftwareBlocklistRule(ctx context.Context, rule *SoftwareBlocklistRule, labelIDs []uint) (*SoftwareBlocklistRule, error)
+	// GetSoftwareBlocklistRule returns the software blocklist rule.
+	GetSoftwareBlocklistRule(ctx context.Context, id uint) (*SoftwareBlocklistRule, error)
+	// ListSoftwareBlocklistRules returns the software blocklist rules of the
+	// team (nil for no team).
+	ListSoftwareBlocklistRules(ctx context.Context, teamID *uint) ([]*SoftwareBlocklistRule, error)
+	// ListAllSoftwareBlocklistRules returns the software blocklist rules of all
+	// teams.
+	ListAllSoftwareBlocklistRules(ctx context.Context) ([]*SoftwareBlocklistRule, error)
+	// DeleteSoftwareBlocklistRule deletes the software blocklist rule.
+	DeleteSoftwareBlocklistRule(ctx context.Context, id uint) error
+	// ListSoftwareBlocklistRuleHosts returns the software matching the rule
+	// in the inventory of the hosts of its team, with the last removal of
+	// each host.
+	ListSoftwareBlocklistRuleHosts(ctx context.Context, rule *SoftwareBlocklistRule) ([]*SoftwareBlocklistHost, error)
+	// GetSoftwareBlocklistRuleHostCounts returns the compliance of the hosts
+	// of the team of the rule.
+	GetSoftwareBlocklistRuleHostCounts(ctx context.Context, rule *SoftwareBlocklistRule) (*SoftwareBlocklistHostCounts, error)
+	// NewSoftwareBlocklistRemoval records the script execution that removes
+	// the blocklisted software from the host.
+	NewSoftwareBlocklistRemoval(ctx context.Context, ruleID, hostID uint, executionID string) error
+
+	// NewSoftwareRequest records the request of a software title by the end
+	// user of a host.
+	NewSoftwareRequest(ctx context.Context, request *SoftwareRequest) (*SoftwareRequest, error)
+	// GetSoftwareRequest returns the software request.
+	GetSoftwareRequest(ctx context.Context, id uint) (*SoftwareRequest, error)
+	// ListSoftwareRequests returns the software requests matching the options,
+	// the most recent first unless an order is specified.
+	ListSoftwareRequests(ctx context.Context, opts SoftwareRequestListOptions) ([]*SoftwareRequest, *PaginationMetadata, error)
+	// ReviewSoftwareRequest records the status, reviewer, comment and
+	// installation of a pending software request. It returns a ConflictError
+	// if the request was already reviewed.
+	ReviewSoftwareRequest(ctx context.Context, request *SoftwareRequest) error
+	// ApproveSoftwareRequest records the approval of a pending software
+	// request and queues the install of the software installer on its host in
+	// a single transaction, it sets the InstallUUID of the request. It returns
+	// a ConflictError if the request was already reviewed.
+	ApproveSoftwareRequest(ctx context.Context, request *SoftwareRequest, installerID uint) error
+	// ListSoftwareRequestCatalog returns the software titles that the end user
+	// of the host can request: the titles with an installer for the platform
+	// of the host in the team of the host that are not available in
+	// self-service. The label scoping of the installers is not applied.
+	ListSoftwareRequestCatalog(ctx context.Context, host *Host) ([]*SoftwareCatalogItem, error)
+
+	// NewSoftwareLicense adds a license entitlement to a software title for the
+	// hosts of a team. It returns an already exists error if the title already
+	// has a license in the team.
+	NewSoftwareLicense(ctx context.Context, license *SoftwareLicense) (*SoftwareLicense, error)
+	// UpdateSoftwareLicense replaces the vendor, seats, expiry, cost and
+	// unused period of the software license.
+	UpdateSoftwareLicense(ctx context.Context, license *SoftwareLicense) (*SoftwareLicense, error)
+	// GetSoftwareLicense returns the software license with its usage.
+	GetSoftwareLicense(ctx context.Context, id uint) (*SoftwareLicense, error)
+	// ListSoftwareLicenses returns the software licenses of the team (nil for
+	// no team, not all the teams) with their usage.
+	ListSoftwareLicenses(ctx context.Context, teamID *uint) ([]*SoftwareLicense, error)
+	// ListAllSoftwareLicenses returns the software licenses of all the teams
+	// with their usage.
+	ListAllSoftwareLicenses(ctx context.Context) ([]*SoftwareLicense, error)
+	// DeleteSoftwareLicense deletes the software license.
+	DeleteSoftwareLicense(ctx context.Context, id uint) error
+	// ListSoftwareLicenseHosts returns the hosts of the team of the license
+	// with any version of the licensed software title installed.
+	ListSoftwareLicenseHosts(ctx context.Context, license *SoftwareLicense) ([]*SoftwareLicenseHost, error)
+
+	// SoftwareTitleUsage returns the usage of the software title by the hosts
+	// of the team (0 for no team, nil for all the teams) that the user of the
+	// filter can see.
+	SoftwareTitleUsage(ctx context.Context, titleID uint, teamID *uint, tmFilter TeamFilter) (*SoftwareTitleUsage, error)
+	// ListUnusedSoftware returns the software titles installed on hosts that
+	// did not open them in the unused period of the options.
+	ListUnusedSoftware(ctx context.Context, opts UnusedSoftwareListOptions, tmFilter TeamFilter) ([]*UnusedSoftware, *PaginationMetadata, error)
+
 	// SetHostSoftwareInstallResult records the result of a software installation
 	// attempt on the host.
 	SetHostSoftwareInstallResult(ctx context.Context, result *HostSoftwareInstallResultPayload) error
@@ -1126,6 +1278,17 @@ type Datastore interface {
 	// executed, based on the provided options.
 	ListMDMAppleCommands(ctx context.Context, tmFilter TeamFilter, listOpts *MDMCommandListOptions) ([]*MDMAppleCommand, error)
 
+	// ListMDMAppleCommandQueue returns the entries of the Apple MDM command
+	// queues of the hosts, with their delivery status and NotNow retry counts.
+	ListMDMAppleCommandQueue(ctx context.Context, tmFilter TeamFilter, listOpts *MDMAppleCommandQueueListOptions) ([]*MDMAppleCommandQueueItem, error)
+
+	// ListMDMAppleHostsWithPendingCommands returns the hosts that have at least
+	// one queued Apple MDM command without a final result (pending or NotNow).
+	// The results can be restricted to the provided host UUIDs, to a specific
+	// command and to hosts that have not checked in since lastSeenBefore.
+	ListMDMAppleHostsWithPendingCommands(ctx context.Context, tmFilter TeamFilter, hostUUIDs []string, commandUUID string,
+		lastSeenBefore *time.Time) ([]*Host, error)
+
 	// NewMDMAppleInstaller creates and stores an Apple installer to MDMlab.
 	NewMDMAppleInstaller(ctx context.Context, name string, size int64, manifest string, installer []byte, urlToken string) (*MDMAppleInstaller, error)
 
@@ -1710,6 +1873,55 @@ type Datastore interface {
 	// available in the Apple MDM protocol.
 	UpdateHostLockWipeStatusFromAppleMDMResult(ctx context.Context, hostUUID, cmdUUID, requestType string, succeeded bool) error
 
+	// SetHostMDMAppleDeviceLocation stores the location reported by an Apple
+	// host in response to a DeviceLocation MDM command, replacing any previous
+	// location for that host.
+	SetHostMDMAppleDeviceLocation(ctx context.Context, loc *HostMDMAppleDeviceLocation) error
+
+	// GetHostMDMAppleDeviceLocation returns the last location reported by the
+	// Apple host with the given UUID, or a not found error if none exists.
+	GetHostMDMAppleDeviceLocation(ctx context.Context, hostUUID string) (*HostMDMAppleDeviceLocation, error)
+
+	// ListHostsPendingRecoveryLockRotation returns up to limit macOS hosts of
+	// the team (or no team if teamID is nil) enrolled in MDMlab MDM that don't
+	// have a managed recovery lock yet, or whose recovery lock is due for
+	// rotation or retry.
+	ListHostsPendingRecoveryLockRotation(ctx context.Context, teamID *uint, limit int) ([]*HostRecoveryLockTarget, error)
+
+	// SetHostRecoveryLockPending encrypts and stores the password being set on
+	// the host via the command with the given UUID. The currently escrowed
+	// password, if any, is kept until the new one is verified. If rotateAt is
+	// not nil, the password will be rotated at that time once verified.
+	SetHostRecoveryLockPending(ctx context.Context, hostUUID, passwordType, password, commandUUID string, rotateAt *time.Time) error
+
+	// SetHostRecoveryLockVerifying promotes the pending recovery lock of the
+	// host, which acknowledged the command that sets it, to be the escrowed
+	// one and records the UUID of the SecurityInfo command sent to verify it.
+	SetHostRecoveryLockVerifying(ctx context.Context, hostUUID, verifyCommandUUID string) error
+
+	// SetHostRecoveryLockVerified promotes the pending recovery lock of the
+	// host to be the escrowed one.
+	SetHostRecoveryLockVerified(ctx context.Context, hostUUID string) error
+
+	// SetHostRecoveryLockFailed discards the pending recovery lock of the host,
+	// if the host didn't acknowledge the command that sets it, and schedules a
+	// retry at the given time.
+	SetHostRecoveryLockFailed(ctx context.Context, hostUUID, detail string, retryAt time.Time) error
+
+	// GetHostRecoveryLock returns the recovery lock escrowed for the host,
+	// with its passwords decrypted, or a not found error if none exists.
+	GetHostRecoveryLock(ctx context.Context, hostUUID string) (*HostRecoveryLock, error)
+
+	// SetHostRecoveryLockRevealed records that the recovery lock of the host
+	// was revealed to a user and schedules its rotation no later than rotateAt.
+	SetHostRecoveryLockRevealed(ctx context.Context, hostUUID string, rotateAt time.Time) error
+
+	// RedactMDMAppleCommandPayload replaces the stored payload of the command
+	// with one that only holds its request type and UUID, so that secrets it
+	// carried (e.g. recovery lock passwords) are not kept in the database. It
+	// is a no-op while the command is still pending for any host.
+	RedactMDMAppleCommandPayload(ctx context.Context, commandUUID string) error
+
 	///////////////////////////////////////////////////////////////////////////////
 	// Software installers
 	//
@@ -1934,6 +2146,97 @@ type Datastore interface {
 	// ExpandEmbeddedSecretsAndUpdatedAt is like ExpandEmbeddedSecrets but also
 	// returns the latest updated_at time of the secrets used in the expansion.
 	ExpandEmbeddedSecretsAndUpdatedAt(ctx context.Context, document string) (string, *time.Time, error)
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Custom CVE matching rules
+
+	// NewCustomCVEMatchingRule creates a new custom CVE matching rule, it returns an AlreadyExists
+	// error if a rule with the same name, source and resolved in version already exists.
+	NewCustomCVEMatchingRule(ctx context.Context, rule *CustomCVEMatchingRule) (*CustomCVEMatchingRule, error)
+
+	// CustomCVEMatchingRule returns the custom CVE matching rule with the given id.
+	CustomCVEMatchingRule(ctx context.Context, id uint) (*CustomCVEMatchingRule, error)
+
+	// ListCustomCVEMatchingRules returns all custom CVE matching rules.
+	ListCustomCVEMatchingRules(ctx context.Context) ([]*CustomCVEMatchingRule, error)
+
+	// DeleteCustomCVEMatchingRule deletes the custom CVE matching rule with the given id.
+	DeleteCustomCVEMatchingRule(ctx context.Context, id uint) error
+
+	// BatchSetCustomCVEMatchingRules replaces all custom CVE matching rules with the provided ones,
+	// used when applying GitOps specs.
+	BatchSetCustomCVEMatchingRules(ctx context.Context, rules []*CustomCVEMatchingRule) error
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Vulnerability suppressions
+
+	// NewVulnerabilitySuppression creates a new vulnerability suppression, it returns an
+	// AlreadyExists error if the CVE is already suppressed for the same scope.
+	NewVulnerabilitySuppression(ctx context.Context, suppression *VulnerabilitySuppression) (*VulnerabilitySuppression, error)
+
+	// VulnerabilitySuppression returns the vulnerability suppression with the given id.
+	VulnerabilitySuppression(ctx context.Context, id uint) (*VulnerabilitySuppression, error)
+
+	// ListVulnerabilitySuppressions returns the vulnerability suppressions matching the options.
+	ListVulnerabilitySuppressions(ctx context.Context, opts ListVulnerabilitySuppressionsOptions) ([]*VulnerabilitySuppression, error)
+
+	// DeleteVulnerabilitySuppression deletes the vulnerability suppression with the given id.
+	DeleteVulnerabilitySuppression(ctx context.Context, id uint) error
+
+	// UpsertVulnerabilitySuppressions creates the provided suppressions, replacing the
+	// justification, comment and expiration of the ones already existing for the same CVE and
+	// scope. Used when importing VEX documents.
+	UpsertVulnerabilitySuppressions(ctx context.Context, suppressions []*VulnerabilitySuppression) error
+
+	// FilterSuppressedSoftwareVulnerabilities returns the provided vulnerabilities, excluding the
+	// ones that are suppressed for all hosts with the vulnerable software installed.
+	FilterSuppressedSoftwareVulnerabilities(ctx context.Context, vulns []SoftwareVulnerability) ([]SoftwareVulnerability, error)
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Vulnerability SLA
+
+	// SyncHostVulnerabilities records the CVEs currently affecting each host through its software
+	// or operating system, detected at the provided time, and marks the previously recorded ones
+	// that no longer affect the host as resolved.
+	SyncHostVulnerabilities(ctx context.Context, now time.Time) error
+
+	// ListOpenHostVulnerabilities returns the unresolved host vulnerabilities matching the options,
+	// excluding the suppressed ones.
+	ListOpenHostVulnerabilities(ctx context.Context, opts HostVulnerabilityListOptions) ([]*HostVulnerability, error)
+
+	// SetHostVulnerabilitiesSLABreachNotified marks the host vulnerabilities as notified of their
+	// SLA breach.
+	SetHostVulnerabilitiesSLABreachNotified(ctx context.Context, ids []uint, notifiedAt time.Time) error
+
+	// ListOverdueHostVulnerabilities returns a page of the unresolved host vulnerabilities past
+	// the due date of their SLA rule, excluding the suppressed ones. The pagination metadata
+	// includes the total number of overdue vulnerabilities.
+	ListOverdueHostVulnerabilities(ctx context.Context, opts OverdueHostVulnerabilityListOptions) ([]*OverdueHostVulnerability, *PaginationMetadata, error)
+
+	// VulnerabilityRemediationMetrics returns the open, overdue (according to the SLA rules at
+	// now) and resolved counts and the mean time to remediate the vulnerabilities of the hosts in
+	// the team (0 for no team, nil for all hosts).
+	VulnerabilityRemediationMetrics(ctx context.Context, teamID *uint, rules []VulnerabilitySLARule, now time.Time) (*VulnerabilityRemediationMetrics, error)
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Vulnerability exposure
+
+	// ListVulnerableSoftwareTitlesByCVE returns the software titles with versions affected by the
+	// CVE installed on the hosts of the team (0 for no team, nil for all hosts), along with the
+	// installers available to update them.
+	ListVulnerableSoftwareTitlesByCVE(ctx context.Context, cve string, teamID *uint) ([]*VulnerableSoftwareTitle, error)
+
+	// VulnerabilityHostCountsByTeam returns the number of hosts affected by the CVE per team (only
+	// the given team if not nil, 0 for no team).
+	VulnerabilityHostCountsByTeam(ctx context.Context, cve string, teamID *uint) ([]*VulnerabilityTeamHostsCount, error)
+
+	// ListVulnerabilityPolicies returns the policies detecting the CVE that apply to the hosts of
+	// the team (0 for no team, nil for all policies).
+	ListVulnerabilityPolicies(ctx context.Context, cve string, teamID *uint) ([]*VulnerabilityPolicy, error)
+
+	// NewVulnerabilityPolicy creates the policy of the team (nil for a global policy) and records
+	// that it detects the vulnerable versions of the software title affected by the CVE.
+	NewVulnerabilityPolicy(ctx context.Context, teamID *uint, authorID *uint, args PolicyPayload, cve string, softwareTitleID *uint) (*Policy, error)
 }
 
 // MDMAppleStore wraps nanomdm's storage and adds methods to deal with
@@ -1943,6 +2246,8 @@ type MDMAppleStore interface {
 	MDMAssetRetriever
 	EnqueueDeviceLockCommand(ctx context.Context, host *Host, cmd *mdm.Command, pin string) error
 	EnqueueDeviceWipeCommand(ctx context.Context, host *Host, cmd *mdm.Command) error
+	EnqueueEnableLostModeCommand(ctx context.Context, host *Host, cmd *mdm.Command) error
+	EnqueueDisableLostModeCommand(ctx context.Context, host *Host, cmd *mdm.Command) error
 }
 
 type MDMAssetRetriever interface {
@@ -0,0 +1,115 @@
+package mdmlab
+
+import (
+	"crypto/sha1" // nolint:gosec // used only to compute the certificate's thumbprint
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HostCertificateSource identifies the mechanism used to report a host's
+// certificate.
+type HostCertificateSource string
+
+const (
+	// HostCertificateSourceAppleMDM is used for certificates reported by the
+	// Apple MDM CertificateList command.
+	HostCertificateSourceAppleMDM HostCertificateSource = "apple_mdm"
+	// HostCertificateSourceWindowsMDM is used for certificates reported by the
+	// Windows CertificateStore CSP.
+	HostCertificateSourceWindowsMDM HostCertificateSource = "windows_mdm"
+)
+
+// HostCertificateRefetchInterval is the interval at which the certificate
+// inventory of MDM-enrolled hosts is refreshed.
+const HostCertificateRefetchInterval = 24 * time.Hour
+
+// HostCertificate represents a certificate installed on a host, as reported
+// via MDM.
+type HostCertificate struct {
+	ID     uint `json:"id" db:"id"`
+	HostID uint `json:"host_id" db:"host_id"`
+	// SHA1Sum is the hex-encoded SHA-1 thumbprint of the DER-encoded
+	// certificate, it uniquely identifies the certificate on a host.
+	SHA1Sum        string    `json:"sha1_sum" db:"sha1_sum"`
+	CommonName     string    `json:"common_name" db:"common_name"`
+	Subject        string    `json:"subject" db:"subject"`
+	Issuer         string    `json:"issuer" db:"issuer"`
+	SerialNumber   string    `json:"serial_number" db:"serial_number"`
+	NotValidBefore time.Time `json:"not_valid_before" db:"not_valid_before"`
+	NotValidAfter  time.Time `json:"not_valid_after" db:"not_valid_after"`
+	// ManagedByMDMlab is true if the certificate was delivered to the host by
+	// MDMlab (e.g. via a configuration profile or as the MDM identity).
+	ManagedByMDMlab bool                  `json:"managed_by_mdmlab" db:"managed_by_mdmlab"`
+	Source          HostCertificateSource `json:"source" db:"source"`
+	UpdatedAt       time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// HostCertificateWithHost is a HostCertificate along with information about
+// the host it is installed on, used when listing certificates across hosts.
+type HostCertificateWithHost struct {
+	HostCertificate
+	HostDisplayName string `json:"host_display_name" db:"host_display_name"`
+	HostTeamID      *uint  `json:"host_team_id" db:"host_team_id"`
+}
+
+// NewHostCertificateFromDER parses the provided DER-encoded certificate and
+// returns the corresponding HostCertificate.
+func NewHostCertificateFromDER(der []byte, source HostCertificateSource) (*HostCertificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate: %w", err)
+	}
+	sum := sha1.Sum(der) // nolint:gosec // thumbprints are SHA-1 by convention
+	var serial string
+	if cert.SerialNumber != nil {
+		serial = strings.ToUpper(cert.SerialNumber.Text(16))
+	}
+	return &HostCertificate{
+		SHA1Sum:        hex.EncodeToString(sum[:]),
+		CommonName:     truncateHostCertificateField(cert.Subject.CommonName, 255),
+		Subject:        truncateHostCertificateField(cert.Subject.String(), 1024),
+		Issuer:         truncateHostCertificateField(cert.Issuer.String(), 1024),
+		SerialNumber:   truncateHostCertificateField(serial, 255),
+		NotValidBefore: cert.NotBefore.UTC(),
+		NotValidAfter:  cert.NotAfter.UTC(),
+		Source:         source,
+	}, nil
+}
+
+// truncateHostCertificateField truncates s to at most n runes, to fit in its
+// database column.
+func truncateHostCertificateField(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// HostCertificateListOptions defines the options to list certificates across
+// hosts.
+type HostCertificateListOptions struct {
+	// ListOptions cursor-based pagination is not supported. MatchQuery matches
+	// the certificate's common name, subject, issuer, serial number and SHA-1
+	// thumbprint.
+	ListOptions ListOptions `url:"list_options"`
+	// TeamID filters the certificates to those installed on hosts of the
+	// team, 0 being "no team".
+	TeamID *uint `query:"team_id,optional"`
+	// ExpiresWithinDays filters the certificates to those that expire within
+	// the number of days (including those already expired).
+	ExpiresWithinDays *uint `query:"expires_within_days,optional"`
+	// ManagedByMDMlab filters the certificates by whether they were delivered
+	// by MDMlab or not.
+	ManagedByMDMlab *bool `query:"managed_by_mdmlab,optional"`
+}
+
+// HostCertificateRefetchTarget is a host for which the certificate inventory
+// must be refetched.
+type HostCertificateRefetchTarget struct {
+	HostID   uint   `db:"host_id"`
+	HostUUID string `db:"host_uuid"`
+	Platform string `db:"platform"`
+}
@@ -476,6 +476,11 @@ type MDMHostData struct {
 	DeviceStatus  *string `json:"device_status,omitempty" db:"-" csv:"-"`
 	PendingAction *string `json:"pending_action,omitempty" db:"-" csv:"-"`
 
+	// DeviceLocation is the last location reported by an Apple host in lost
+	// mode in response to a DeviceLocation MDM command. It is not filled by
+	// all host-returning methods.
+	DeviceLocation *HostMDMAppleDeviceLocation `json:"device_location,omitempty" db:"-" csv:"-"`
+
 	// ConnectedToMDMlab indicates if the host has an active MDM connection
 	// with this MDMlab instance. This boolean is not filled by all
 	// host-returning methods.
@@ -406,6 +406,23 @@ func ValidateEnabledHostStatusIntegrations(webhook HostStatusWebhookSettings, in
 	}
 }
 
+func ValidateEnabledCertificateExpirationWebhook(webhook CertificateExpirationWebhookSettings, invalid *InvalidArgumentError) {
+	if webhook.Enable {
+		if webhook.DestinationURL == "" {
+			invalid.Append("destination_url", "destination_url is required to enable the certificate expiration webhook")
+		}
+		if webhook.DaysBeforeExpiration <= 0 {
+			invalid.Append("days_before_expiration", "days_before_expiration must be > 0 to enable the certificate expiration webhook")
+		}
+	}
+}
+
+func ValidateEnabledSoftwareRequestsWebhook(webhook SoftwareRequestsWebhookSettings, invalid *InvalidArgumentError) {
+	if webhook.Enable && webhook.DestinationURL == "" {
+		invalid.Append("destination_url", "destination_url is required to enable the software requests webhook")
+	}
+}
+
 func ValidateGoogleCalendarIntegrations(intgs []*GoogleCalendarIntegration, invalid *InvalidArgumentError) {
 	if len(intgs) > 1 {
 		invalid.Append("integrations.google_calendar", "integrating with >1 Google Workspace service account is not yet supported.")
@@ -5,14 +5,14 @@ import "time"
 // MaintainedApp represets an app in the MDMlab library of maintained apps,
 // as stored in the mdmlab_library_apps table.
 type MaintainedApp struct {
-	ID               uint                `json:"id" db:"id"`
-	Name             string              `json:"name" db:"name"`
-	Token            string              `json:"-" db:"token"`
-	Version          string              `json:"version" db:"version"`
-	Platform         AppleDevicePlatform `json:"platform" db:"platform"`
-	InstallerURL     string              `json:"-" db:"installer_url"`
-	SHA256           string              `json:"-" db:"sha256"`
-	BundleIdentifier string              `json:"-" db:"bundle_identifier"`
+	ID               uint   `json:"id" db:"id"`
+	Name             string `json:"name" db:"name"`
+	Token            string `json:"-" db:"token"`
+	Version          string `json:"version" db:"version"`
+	Platform         string `json:"platform" db:"platform"`
+	InstallerURL     string `json:"-" db:"installer_url"`
+	SHA256           string `json:"-" db:"sha256"`
+	BundleIdentifier string `json:"-" db:"bundle_identifier"`
 
 	// InstallScript and UninstallScript are not stored directly in the table, they
 	// must be filled via a JOIN on script_contents. On insert/update/upsert, these
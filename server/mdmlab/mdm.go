@@ -796,10 +796,21 @@ func FilterMacOSOnlyProfilesFromIOSIPadOS(profiles []*MDMAppleProfilePayload) []
 }
 
 // RefetchBaseCommandUUIDPrefix and below command prefixes are the prefixes used for MDM commands used to refetch information from iOS/iPadOS devices.
+// The certificates prefixes are used to refetch the certificate inventory of all MDM-enrolled hosts.
 const (
 	RefetchBaseCommandUUIDPrefix   = "REFETCH-"
 	RefetchDeviceCommandUUIDPrefix = RefetchBaseCommandUUIDPrefix + "DEVICE-"
 	RefetchAppsCommandUUIDPrefix   = RefetchBaseCommandUUIDPrefix + "APPS-"
+	// RefetchCertificatesCommandUUIDPrefix is used for the command that lists
+	// all certificates of a host.
+	RefetchCertificatesCommandUUIDPrefix = RefetchBaseCommandUUIDPrefix + "CERTS-"
+	// RefetchManagedCertificatesCommandUUIDPrefix is used for the Apple
+	// command that lists the certificates installed by MDMlab.
+	RefetchManagedCertificatesCommandUUIDPrefix = RefetchBaseCommandUUIDPrefix + "MANAGEDCERTS-"
+	// RefetchCertificatesDataCommandUUIDPrefix is used for the Windows
+	// command that gets the encoded certificates listed by the
+	// RefetchCertificatesCommandUUIDPrefix command.
+	RefetchCertificatesDataCommandUUIDPrefix = RefetchBaseCommandUUIDPrefix + "CERTSDATA-"
 )
 
 // VPPTokenInfo is the representation of the VPP token that we send out via API.
@@ -1442,6 +1442,59 @@ type MDMWindowsCommand struct {
 	UpdatedAt    time.Time `db:"updated_at"`
 }
 
+// List of CSP nodes used by the MDM-based device actions on Windows hosts.
+const (
+	// MDMWindowsRebootLocURI restarts the host immediately.
+	// https://learn.microsoft.com/en-us/windows/client-management/mdm/reboot-csp
+	MDMWindowsRebootLocURI = "./Device/Vendor/MSFT/Reboot/RebootNow"
+	// MDMWindowsRotateBitLockerLocURI generates a new BitLocker recovery
+	// password for the OS and fixed drives, and backs it up to Entra ID.
+	// https://learn.microsoft.com/en-us/windows/client-management/mdm/bitlocker-csp#rotaterotaterecoverypasswords
+	MDMWindowsRotateBitLockerLocURI = "./Device/Vendor/MSFT/BitLocker/Rotate/RotateRecoveryPasswords"
+)
+
+// WindowsWipeType is the RemoteWipe CSP node used to wipe a Windows host.
+// https://learn.microsoft.com/en-us/windows/client-management/mdm/remotewipe-csp
+type WindowsWipeType string
+
+const (
+	// WindowsWipeTypeDoWipe resets the host, a user with physical access can
+	// interrupt the wipe by power cycling the host.
+	WindowsWipeTypeDoWipe WindowsWipeType = "doWipe"
+	// WindowsWipeTypeDoWipeProtected resets the host and keeps retrying until
+	// the wipe completes, it can leave some devices unable to boot.
+	WindowsWipeTypeDoWipeProtected WindowsWipeType = "doWipeProtected"
+	// WindowsWipeTypeDoWipePersistProvisionedData resets the host but keeps
+	// the provisioning data (e.g. provisioning packages) in place.
+	WindowsWipeTypeDoWipePersistProvisionedData WindowsWipeType = "doWipePersistProvisionedData"
+)
+
+// IsValid returns true if the wipe type is one of the supported types.
+func (t WindowsWipeType) IsValid() bool {
+	switch t {
+	case WindowsWipeTypeDoWipe, WindowsWipeTypeDoWipeProtected, WindowsWipeTypeDoWipePersistProvisionedData:
+		return true
+	default:
+		return false
+	}
+}
+
+// LocURI returns the RemoteWipe CSP node that triggers the wipe.
+func (t WindowsWipeType) LocURI() string {
+	return "./Device/Vendor/MSFT/RemoteWipe/" + string(t)
+}
+
+// MDMWipeOptions are the platform-specific options of a wipe request.
+type MDMWipeOptions struct {
+	Windows *MDMWindowsWipeOptions `json:"windows,omitempty"`
+}
+
+// MDMWindowsWipeOptions are the options of a wipe request for a Windows host.
+type MDMWindowsWipeOptions struct {
+	// WipeType defaults to WindowsWipeTypeDoWipeProtected if empty.
+	WipeType WindowsWipeType `json:"wipe_type"`
+}
+
 // GetEncodedBinarySecurityToken returns the base64 form of a input payload
 func GetEncodedBinarySecurityToken(typeID WindowsMDMEnrollmentType, payload string) (string, error) {
 	var pld WindowsMDMAccessTokenPayload
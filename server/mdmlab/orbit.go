@@ -60,6 +60,10 @@ type OrbitConfig struct {
 	//
 	// If UpdateChannels is nil it means the server isn't using/setting this feature.
 	UpdateChannels *OrbitUpdateChannels `json:"update_channels,omitempty"`
+	// SoftwareInstallerCacheURL is the URL of the local cache server from
+	// which the host downloads the software installers, empty if no cache
+	// serves the host's subnet.
+	SoftwareInstallerCacheURL string `json:"software_installer_cache_url,omitempty"`
 }
 
 type OrbitConfigReceiver interface {
@@ -423,6 +423,12 @@ type HostLockWipeStatus struct {
 
 	// Linux uses a script for Wipe
 	WipeScript *HostScriptResult
+
+	// iOS and iPadOS use MDM commands to enable and disable lost mode
+	LostModeMDMCommand              *MDMCommand
+	LostModeMDMCommandResult        *MDMCommandResult
+	DisableLostModeMDMCommand       *MDMCommand
+	DisableLostModeMDMCommandResult *MDMCommandResult
 }
 
 // ScriptResponse is the response type used when applying scripts by batch.
@@ -483,6 +489,24 @@ func (s HostLockWipeStatus) IsUnlocked() bool {
 	return !s.IsLocked() && !s.IsWiped()
 }
 
+func (s HostLockWipeStatus) IsPendingLostMode() bool {
+	// pending lost mode if an MDM command is queued but no result received yet
+	return s.LostModeMDMCommand != nil && s.LostModeMDMCommandResult == nil
+}
+
+func (s HostLockWipeStatus) IsPendingDisableLostMode() bool {
+	// pending disable if an MDM command is queued but no result received yet
+	return s.DisableLostModeMDMCommand != nil && s.DisableLostModeMDMCommandResult == nil
+}
+
+func (s HostLockWipeStatus) IsInLostMode() bool {
+	// this state is regardless of pending disable (it reports whether the host
+	// is in lost mode *now*). A successful DisableLostMode clears the lost mode
+	// reference, see UpdateHostLockWipeStatusFromAppleMDMResult.
+	return s.LostModeMDMCommand != nil && s.LostModeMDMCommandResult != nil &&
+		s.LostModeMDMCommandResult.Status == MDMAppleStatusAcknowledged
+}
+
 func (s HostLockWipeStatus) IsWiped() bool {
 	switch s.HostMDMlabPlatform {
 	case "linux":
@@ -435,6 +435,13 @@ type Service interface {
 	// the specified host.
 	ListHostSoftware(ctx context.Context, hostID uint, opts HostSoftwareTitleListOptions) ([]*HostSoftwareWithInstaller, *PaginationMetadata, error)
 
+	// ListHostCertificates lists the certificates installed on the specified
+	// host, as reported via MDM.
+	ListHostCertificates(ctx context.Context, hostID uint, opts ListOptions) ([]*HostCertificate, *PaginationMetadata, error)
+	// SearchHostCertificates lists the certificates installed on all hosts the
+	// user has access to, as reported via MDM.
+	SearchHostCertificates(ctx context.Context, opts HostCertificateListOptions) ([]*HostCertificateWithHost, *PaginationMetadata, error)
+
 	// /////////////////////////////////////////////////////////////////////////////
 	// AppConfigService provides methods for configuring  the MDMlab application
 
@@ -831,6 +838,24 @@ type Service interface {
 	// the specified options.
 	ListMDMAppleCommands(ctx context.Context, opts *MDMCommandListOptions) ([]*MDMAppleCommand, error)
 
+	// ListMDMAppleCommandQueue returns the entries of the Apple MDM command
+	// queues of the hosts, with their status and NotNow retry counts.
+	ListMDMAppleCommandQueue(ctx context.Context, opts *MDMAppleCommandQueueListOptions) ([]*MDMAppleCommandQueueItem, error)
+
+	// CancelMDMAppleCommands cancels the pending Apple MDM commands of the
+	// provided hosts, or only commandUUID if it is not empty. If no host is
+	// provided, commandUUID is canceled on all hosts where it is pending. It
+	// returns the number of canceled queue entries. Commands whose delivery is
+	// tracked outside of the queue (see MDMAppleNonCancelableRequestTypes)
+	// are never canceled.
+	CancelMDMAppleCommands(ctx context.Context, hostUUIDs []string, commandUUID string) (int64, error)
+
+	// ResendMDMApplePushNotifications sends an APNs push notification to the
+	// hosts that have pending Apple MDM commands, optionally restricted to the
+	// provided hosts and to the hosts that did not check in since
+	// lastSeenBefore.
+	ResendMDMApplePushNotifications(ctx context.Context, hostUUIDs []string, lastSeenBefore *time.Time) (*MDMAppleCommandQueuePushResult, error)
+
 	// UploadMDMAppleInstaller uploads an Apple installer to MDMlab.
 	UploadMDMAppleInstaller(ctx context.Context, name string, size int64, installer io.Reader) (*MDMAppleInstaller, error)
 
@@ -1143,7 +1168,23 @@ type Service interface {
 	// Script-based methods (at least for some platforms, MDM-based for others)
 	LockHost(ctx context.Context, hostID uint, viewPIN bool) (unlockPIN string, err error)
 	UnlockHost(ctx context.Context, hostID uint) (unlockPIN string, err error)
-	WipeHost(ctx context.Context, hostID uint) error
+	WipeHost(ctx context.Context, hostID uint, opts MDMWipeOptions) error
+
+	// MDM-based device actions for Apple hosts, RestartHost also supports
+	// Windows hosts.
+	RestartHost(ctx context.Context, hostID uint, notifyUser bool) error
+	ShutDownHost(ctx context.Context, hostID uint) error
+	EnableHostLostMode(ctx context.Context, hostID uint, opts MDMAppleLostModeOptions) error
+	DisableHostLostMode(ctx context.Context, hostID uint) error
+	PlayHostLostModeSound(ctx context.Context, hostID uint) error
+	RequestHostLocation(ctx context.Context, hostID uint) error
+	SetHostRecoveryLock(ctx context.Context, hostID uint, currentPassword, newPassword string) error
+	// GetHostRecoveryLockPassword reveals the recovery lock or firmware
+	// password escrowed for the macOS host, and schedules its rotation.
+	GetHostRecoveryLockPassword(ctx context.Context, hostID uint) (*HostRecoveryLock, error)
+
+	// MDM-based device actions for Windows hosts
+	RotateHostBitLockerRecoveryPassword(ctx context.Context, hostID uint) error
 
 	///////////////////////////////////////////////////////////////////////////////
 	// Software installers
@@ -1158,6 +1199,113 @@ type Service interface {
 	DownloadSoftwareInstaller(ctx context.Context, skipAuthz bool, alt string, titleID uint,
 		teamID *uint) (*DownloadSoftwareInstallerPayload, error)
 	OrbitDownloadSoftwareInstaller(ctx context.Context, installerID uint) (*DownloadSoftwareInstallerPayload, error)
+	// GetSoftwareInstallerCacheTokenMetadata returns the install authorized by
+	// the software installer cache token, without consuming the token.
+	GetSoftwareInstallerCacheTokenMetadata(ctx context.Context, token string) (*SoftwareInstallerCacheTokenMetadata, error)
+	// DownloadSoftwareInstallerWithCacheToken consumes the software installer
+	// cache token and returns the installer it authorizes.
+	DownloadSoftwareInstallerWithCacheToken(ctx context.Context, token string) (*DownloadSoftwareInstallerPayload, error)
+
+	// GetSoftwareInstallerRollout returns the staged rollout of the software
+	// installer of the title and team, with the results of its installs.
+	GetSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*SoftwareInstallerRollout, error)
+	// SetSoftwareInstallerRollout starts the staged rollout of the software
+	// installer, or restarts it from the first stage.
+	SetSoftwareInstallerRollout(ctx context.Context, payload *SoftwareInstallerRolloutPayload) (*SoftwareInstallerRollout, error)
+	// PauseSoftwareInstallerRollout stops queuing automatic installs of the
+	// software installer until the rollout is resumed.
+	PauseSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*SoftwareInstallerRollout, error)
+	// ResumeSoftwareInstallerRollout resumes a paused rollout at its current
+	// stage.
+	ResumeSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*SoftwareInstallerRollout, error)
+	// DeleteSoftwareInstallerRollout cancels the staged rollout of the software
+	// installer, making it available to all hosts in scope.
+	DeleteSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) error
+
+	// ListSoftwareInstallerVersions returns the versions of the software
+	// installer of the title and team, the latest first.
+	ListSoftwareInstallerVersions(ctx context.Context, titleID uint, teamID *uint) ([]*SoftwareInstallerVersion, error)
+	// PinSoftwareInstallerVersion pins a version of the software installer, it
+	// is then installed instead of the current version, even when a new
+	// version is uploaded.
+	PinSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error
+	// UnpinSoftwareInstallerVersion unpins the pinned version of the software
+	// installer.
+	UnpinSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint) error
+	// RollbackSoftwareInstallerVersion makes a previous version the current
+	// version of the software installer.
+	RollbackSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error
+	// SetSoftwareInstallerVersionLabels targets a version of the software
+	// installer to the hosts that are members of any of the labels.
+	SetSoftwareInstallerVersionLabels(ctx context.Context, titleID uint, teamID *uint, versionID uint, labelsIncludeAny []string) (*SoftwareInstallerVersion, error)
+	// DeleteSoftwareInstallerVersion deletes a previous version of the
+	// software installer.
+	DeleteSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error
+
+	// ListSoftwareBlocklistRules returns the software blocklist rules of the
+	// team (nil for no team).
+	ListSoftwareBlocklistRules(ctx context.Context, teamID *uint) ([]*SoftwareBlocklistRule, error)
+	// GetSoftwareBlocklistRule returns the software blocklist rule with the
+	// compliance of the hosts of its team.
+	GetSoftwareBlocklistRule(ctx context.Context, id uint) (*SoftwareBlocklistRule, error)
+	// CreateSoftwareBlocklistRule blocklists software for a team, it is
+	// removed from the hosts that are not members of the excepted labels.
+	CreateSoftwareBlocklistRule(ctx context.Context, payload *SoftwareBlocklistRulePayload) (*SoftwareBlocklistRule, error)
+	// ModifySoftwareBlocklistRule replaces the software, version range,
+	// removal script and excepted labels of the rule.
+	ModifySoftwareBlocklistRule(ctx context.Context, id uint, payload *SoftwareBlocklistRulePayload) (*SoftwareBlocklistRule, error)
+	// DeleteSoftwareBlocklistRule deletes the software blocklist rule.
+	DeleteSoftwareBlocklistRule(ctx context.Context, id uint) error
+	// ListSoftwareBlocklistRuleHosts returns the hosts of the team of the rule
+	// with the blocklisted software and the status of its removal.
+	ListSoftwareBlocklistRuleHosts(ctx context.Context, id uint) ([]*SoftwareBlocklistHost, error)
+	// ListDeviceSoftwareCatalog returns the software that the end user of the
+	// device-authenticated host can request.
+	ListDeviceSoftwareCatalog(ctx context.Context, host *Host) ([]*SoftwareCatalogItem, error)
+	// RequestDeviceSoftware records the request of a software title by the end
+	// user of the device-authenticated host and notifies the maintainers of
+	// the team of the host.
+	RequestDeviceSoftware(ctx context.Context, host *Host, titleID uint, justification string) (*SoftwareRequest, error)
+	// ListDeviceSoftwareRequests returns the software requests of the
+	// device-authenticated host.
+	ListDeviceSoftwareRequests(ctx context.Context, host *Host, opts ListOptions) ([]*SoftwareRequest, *PaginationMetadata, error)
+	// ListSoftwareRequests returns the software requests matching the options.
+	ListSoftwareRequests(ctx context.Context, opts SoftwareRequestListOptions) ([]*SoftwareRequest, *PaginationMetadata, error)
+	// ApproveSoftwareRequest approves a pending software request and installs
+	// the software on the host of the request.
+	ApproveSoftwareRequest(ctx context.Context, id uint, comment string) (*SoftwareRequest, error)
+	// DenySoftwareRequest denies a pending software request.
+	DenySoftwareRequest(ctx context.Context, id uint, comment string) (*SoftwareRequest, error)
+	// ListSoftwareLicenses returns the software licenses of the team (nil for
+	// no team, not all the teams) with their usage.
+	ListSoftwareLicenses(ctx context.Context, teamID *uint) ([]*SoftwareLicense, error)
+	// ListSoftwareLicensesReport returns the software licenses of the team
+	// with their usage for the procurement report, or those of all the teams
+	// if teamID is nil, in which case the report also totals the titles
+	// licensed in several teams.
+	ListSoftwareLicensesReport(ctx context.Context, teamID *uint) ([]*SoftwareLicense, error)
+	// GetSoftwareLicense returns the software license with the ID.
+	GetSoftwareLicense(ctx context.Context, id uint) (*SoftwareLicense, error)
+	// CreateSoftwareLicense adds a license entitlement to a software title for
+	// the hosts of a team.
+	CreateSoftwareLicense(ctx context.Context, payload *SoftwareLicensePayload) (*SoftwareLicense, error)
+	// ModifySoftwareLicense replaces the vendor, seats, expiry, cost and unused
+	// period of the software license, its team and title can't be changed.
+	ModifySoftwareLicense(ctx context.Context, id uint, payload *SoftwareLicensePayload) (*SoftwareLicense, error)
+	// DeleteSoftwareLicense deletes the software license.
+	DeleteSoftwareLicense(ctx context.Context, id uint) error
+	// ListSoftwareLicenseHosts returns the hosts of the team of the license
+	// with the licensed software installed, filtered by usage status if set.
+	ListSoftwareLicenseHosts(ctx context.Context, id uint, status SoftwareLicenseUsageStatus) ([]*SoftwareLicenseHost, error)
+	// SoftwareTitleUsage returns the usage of the software title by the hosts
+	// of the team (0 for no team, nil for all the teams of the user).
+	SoftwareTitleUsage(ctx context.Context, titleID uint, teamID *uint) (*SoftwareTitleUsage, error)
+	// ListUnusedSoftware returns the software titles installed on hosts that
+	// did not open them in the unused period of the options.
+	ListUnusedSoftware(ctx context.Context, opts UnusedSoftwareListOptions) ([]*UnusedSoftware, *PaginationMetadata, error)
+	// SoftwareTitleUnusedPolicy returns a policy that fails on the hosts where
+	// the software title was not opened in the last days.
+	SoftwareTitleUnusedPolicy(ctx context.Context, titleID uint, days uint) (*SoftwareUnusedPolicy, error)
 
 	////////////////////////////////////////////////////////////////////////////////
 	// Setup Experience
@@ -1181,7 +1329,7 @@ type Service interface {
 	// MDMlab-maintained apps
 
 	// AddMDMlabMaintainedApp adds a MDMlab-maintained app to the given team.
-	AddMDMlabMaintainedApp(ctx context.Context, teamID *uint, appID uint, installScript, preInstallQuery, postInstallScript, uninstallScript string, selfService bool, labelsIncludeAny, labelsExcludeAny []string) (uint, error)
+	AddMDMlabMaintainedApp(ctx context.Context, teamID *uint, appID uint, installScript, preInstallQuery, postInstallScript, uninstallScript string, selfService, automaticInstall bool, labelsIncludeAny, labelsExcludeAny []string) (uint, error)
 	// ListMDMlabMaintainedApps lists MDMlab-maintained apps available to a specific team
 	ListMDMlabMaintainedApps(ctx context.Context, teamID *uint, opts ListOptions) ([]MaintainedApp, *PaginationMetadata, error)
 	// GetMDMlabMaintainedApp returns a MDMlab-maintained app by ID
@@ -1198,6 +1346,77 @@ type Service interface {
 
 	// CreateSecretVariables creates secret variables for scripts and profiles.
 	CreateSecretVariables(ctx context.Context, secretVariables []SecretVariable, dryRun bool) error
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Custom CVE matching rules
+
+	// NewCustomCVEMatchingRule creates a custom CVE matching rule, applied on the next vulnerability
+	// processing run.
+	NewCustomCVEMatchingRule(ctx context.Context, spec CustomCVEMatchingRuleSpec) (*CustomCVEMatchingRule, error)
+
+	// ListCustomCVEMatchingRules returns all custom CVE matching rules.
+	ListCustomCVEMatchingRules(ctx context.Context) ([]*CustomCVEMatchingRule, error)
+
+	// DeleteCustomCVEMatchingRule deletes a custom CVE matching rule.
+	DeleteCustomCVEMatchingRule(ctx context.Context, id uint) error
+
+	// ApplyCustomCVEMatchingRulesSpecs replaces all custom CVE matching rules with the provided
+	// specs, used by GitOps.
+	ApplyCustomCVEMatchingRulesSpecs(ctx context.Context, specs []*CustomCVEMatchingRuleSpec, opts ApplySpecOptions) error
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Vulnerability suppressions
+
+	// NewVulnerabilitySuppression suppresses a CVE globally, for a team, a software title or a
+	// host.
+	NewVulnerabilitySuppression(ctx context.Context, payload VulnerabilitySuppressionPayload) (*VulnerabilitySuppression, error)
+
+	// ListVulnerabilitySuppressions returns the vulnerability suppressions matching the options.
+	ListVulnerabilitySuppressions(ctx context.Context, opts ListVulnerabilitySuppressionsOptions) ([]*VulnerabilitySuppression, error)
+
+	// DeleteVulnerabilitySuppression deletes a vulnerability suppression.
+	DeleteVulnerabilitySuppression(ctx context.Context, id uint) error
+
+	// ImportVEXDocument creates (or updates) a vulnerability suppression for each "not affected"
+	// statement of the OpenVEX or CycloneDX document. It returns the number of suppressions.
+	ImportVEXDocument(ctx context.Context, document []byte) (int, error)
+
+	// ExportVEXDocument returns the active vulnerability suppressions as a VEX document in the
+	// given format ("openvex" or "cyclonedx").
+	ExportVEXDocument(ctx context.Context, format string) ([]byte, error)
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Software bill of materials
+
+	// HostSBOM returns the software bill of materials of the host in the given format ("cyclonedx"
+	// or "spdx").
+	HostSBOM(ctx context.Context, hostID uint, format string) ([]byte, error)
+
+	// TeamSBOM returns the software bill of materials of the hosts in the team in the given format
+	// ("cyclonedx" or "spdx"). A nil teamID includes all hosts and 0 the hosts with no team.
+	TeamSBOM(ctx context.Context, teamID *uint, format string) ([]byte, error)
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Vulnerability SLA
+
+	// ListOverdueVulnerabilities returns a page of the vulnerabilities affecting the hosts of the
+	// team (0 for no team, nil for all hosts) past their SLA due date.
+	ListOverdueVulnerabilities(ctx context.Context, teamID *uint, opts ListOptions) ([]*OverdueHostVulnerability, *PaginationMetadata, error)
+
+	// VulnerabilityRemediationMetrics returns the remediation metrics of the vulnerabilities
+	// affecting the hosts of the team (0 for no team, nil for all hosts).
+	VulnerabilityRemediationMetrics(ctx context.Context, teamID *uint) (*VulnerabilityRemediationMetrics, error)
+
+	// /////////////////////////////////////////////////////////////////////////////
+	// Vulnerability exposure
+
+	// VulnerabilityExposure returns the software titles, OS versions, teams and policies affected
+	// by the CVE, for the hosts of the team (0 for no team, nil for all hosts).
+	VulnerabilityExposure(ctx context.Context, cve string, teamID *uint) (*VulnerabilityExposure, error)
+
+	// NewVulnerabilityPolicy creates a policy that fails on the hosts with a version of the
+	// software title vulnerable to the CVE installed.
+	NewVulnerabilityPolicy(ctx context.Context, payload VulnerabilityPolicyPayload) (*Policy, error)
 }
 
 type KeyValueStore interface {
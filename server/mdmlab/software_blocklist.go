@@ -0,0 +1,142 @@
+package mdmlab
+
+import (
+	"time"
+)
+
+// SoftwareBlocklistRule is software that must not be installed on the hosts
+// of a team. The software found in the inventory of the hosts that matches
+// the rule is removed by running the removal script of the rule, or the
+// uninstall script of the software title's installer if the rule has no
+// removal script.
+type SoftwareBlocklistRule struct {
+	ID     uint  `json:"id" db:"id"`
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// Name, BundleIdentifier and Source match the software of the inventory,
+	// an empty value matches any software. At least one of Name and
+	// BundleIdentifier is set.
+	Name             string `json:"name" db:"name"`
+	BundleIdentifier string `json:"bundle_identifier" db:"bundle_identifier"`
+	Source           string `json:"source" db:"source"`
+	// MinVersion and MaxVersion are the inclusive bounds of the blocklisted
+	// versions, an empty value means that there is no bound.
+	MinVersion string `json:"min_version" db:"min_version"`
+	MaxVersion string `json:"max_version" db:"max_version"`
+	// ScriptID is the saved script that removes the software. When nil, the
+	// uninstall script of the installer of the software title is used.
+	ScriptID *uint `json:"script_id" db:"script_id"`
+	// LabelsExcludeAny are the labels whose members are excepted from the
+	// rule.
+	LabelsExcludeAny []SoftwareScopeLabel `json:"labels_exclude_any" db:"-"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+
+	// HostCounts is only set when getting a single rule.
+	HostCounts *SoftwareBlocklistHostCounts `json:"host_counts,omitempty" db:"-"`
+}
+
+// MatchesVersion returns whether the version is in the blocklisted range of
+// the rule.
+func (r *SoftwareBlocklistRule) MatchesVersion(version string) bool {
+	if r.MinVersion != "" && CompareVersions(version, r.MinVersion) < 0 {
+		return false
+	}
+	if r.MaxVersion != "" && CompareVersions(version, r.MaxVersion) > 0 {
+		return false
+	}
+	return true
+}
+
+// ActivityDetails returns the details of the blocklist rule activities,
+// teamName is the name of the team of the rule (nil for no team).
+func (r *SoftwareBlocklistRule) ActivityDetails(teamName *string) ActivitySoftwareBlocklistRule {
+	return ActivitySoftwareBlocklistRule{
+		RuleID:           r.ID,
+		TeamName:         teamName,
+		TeamID:           r.TeamID,
+		SoftwareName:     r.Name,
+		BundleIdentifier: r.BundleIdentifier,
+		Source:           r.Source,
+		MinVersion:       r.MinVersion,
+		MaxVersion:       r.MaxVersion,
+	}
+}
+
+// SoftwareBlocklistRulePayload is the payload to create or replace a software
+// blocklist rule.
+type SoftwareBlocklistRulePayload struct {
+	TeamID           *uint
+	Name             string
+	BundleIdentifier string
+	Source           string
+	MinVersion       string
+	MaxVersion       string
+	ScriptID         *uint
+	LabelsExcludeAny []string
+}
+
+// Validate checks the software and version range of the rule.
+func (p *SoftwareBlocklistRulePayload) Validate() error {
+	if p.Name == "" && p.BundleIdentifier == "" {
+		return NewInvalidArgumentError("name", "one of name or bundle_identifier is required")
+	}
+	if p.MinVersion != "" && p.MaxVersion != "" && CompareVersions(p.MinVersion, p.MaxVersion) > 0 {
+		return NewInvalidArgumentError("min_version", "must be lower than or equal to max_version")
+	}
+	return nil
+}
+
+// SoftwareBlocklistRemovalStatus is the status of the removal of blocklisted
+// software from a host.
+type SoftwareBlocklistRemovalStatus string
+
+const (
+	// SoftwareBlocklistRemovalPending means that the removal script did not
+	// run yet.
+	SoftwareBlocklistRemovalPending SoftwareBlocklistRemovalStatus = "pending"
+	// SoftwareBlocklistRemovalSucceeded means that the removal script ran
+	// successfully, the software is still reported until the next software
+	// inventory of the host.
+	SoftwareBlocklistRemovalSucceeded SoftwareBlocklistRemovalStatus = "succeeded"
+	// SoftwareBlocklistRemovalFailed means that the removal script failed.
+	SoftwareBlocklistRemovalFailed SoftwareBlocklistRemovalStatus = "failed"
+)
+
+// SoftwareBlocklistRemovalRetryInterval is the minimum duration between two
+// removals of the blocklisted software of a host.
+const SoftwareBlocklistRemovalRetryInterval = 24 * time.Hour
+
+// SoftwareBlocklistHost is blocklisted software found in the inventory of a
+// host.
+type SoftwareBlocklistHost struct {
+	HostID          uint   `json:"host_id" db:"host_id"`
+	HostDisplayName string `json:"host_display_name" db:"host_display_name"`
+	HostPlatform    string `json:"host_platform" db:"host_platform"`
+	SoftwareID      uint   `json:"software_id" db:"software_id"`
+	SoftwareTitleID *uint  `json:"software_title_id" db:"software_title_id"`
+	Name            string `json:"name" db:"name"`
+	Version         string `json:"version" db:"version"`
+	Source          string `json:"source" db:"source"`
+	// Excepted is true when the host is a member of one of the excepted labels
+	// of the rule, the software is not removed from those hosts.
+	Excepted bool `json:"excepted" db:"excepted"`
+
+	// RemovalExecutionID, RemovalStatus and RemovalRequestedAt are those of
+	// the last removal of the blocklisted software of the host, if any.
+	RemovalExecutionID *string                         `json:"removal_execution_id" db:"removal_execution_id"`
+	RemovalStatus      *SoftwareBlocklistRemovalStatus `json:"removal_status" db:"-"`
+	RemovalRequestedAt *time.Time                      `json:"removal_requested_at" db:"removal_requested_at"`
+}
+
+// SoftwareBlocklistHostCounts is the compliance of the hosts of the team of a
+// blocklist rule.
+type SoftwareBlocklistHostCounts struct {
+	// Compliant is the number of hosts without the blocklisted software.
+	Compliant uint `json:"compliant"`
+	// NonCompliant is the number of hosts with the blocklisted software that
+	// are not excepted.
+	NonCompliant uint `json:"non_compliant"`
+	// Excepted is the number of hosts with the blocklisted software that are
+	// members of an excepted label.
+	Excepted uint `json:"excepted"`
+}
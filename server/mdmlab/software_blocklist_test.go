@@ -0,0 +1,66 @@
+package mdmlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareBlocklistRulePayloadValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload SoftwareBlocklistRulePayload
+		wantErr string
+	}{
+		{
+			name:    "no name nor bundle identifier",
+			payload: SoftwareBlocklistRulePayload{Source: "apps"},
+			wantErr: "one of name or bundle_identifier is required",
+		},
+		{
+			name:    "min version above max version",
+			payload: SoftwareBlocklistRulePayload{Name: "uTorrent.app", MinVersion: "3.0", MaxVersion: "2.9"},
+			wantErr: "must be lower than or equal to max_version",
+		},
+		{
+			name:    "name only",
+			payload: SoftwareBlocklistRulePayload{Name: "uTorrent.app"},
+		},
+		{
+			name:    "bundle identifier and version range",
+			payload: SoftwareBlocklistRulePayload{BundleIdentifier: "com.bittorrent.uTorrent", MinVersion: "1.0", MaxVersion: "1.0"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.payload.Validate()
+			if c.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, c.wantErr)
+		})
+	}
+}
+
+func TestSoftwareBlocklistRuleMatchesVersion(t *testing.T) {
+	cases := []struct {
+		min, max string
+		version  string
+		want     bool
+	}{
+		{"", "", "1.0", true},
+		{"", "", "", true},
+		{"2.0", "", "1.9", false},
+		{"2.0", "", "2.0", true},
+		{"2.0", "", "10.0", true},
+		{"", "3.5.5", "3.5.5", true},
+		{"", "3.5.5", "3.5.6", false},
+		{"1.0", "2.0", "1.5.1", true},
+		{"1.0", "2.0", "2.0.1", false},
+	}
+	for _, c := range cases {
+		rule := SoftwareBlocklistRule{MinVersion: c.min, MaxVersion: c.max}
+		require.Equal(t, c.want, rule.MatchesVersion(c.version), "min=%q max=%q version=%q", c.min, c.max, c.version)
+	}
+}
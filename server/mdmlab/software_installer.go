@@ -78,8 +78,16 @@ type SoftwareInstallDetails struct {
 	PostInstallScript string `json:"post_install_script" db:"post_install_script"`
 	// SelfService indicates the install was initiated by the device user
 	SelfService bool `json:"self_service" db:"self_service"`
+	// InstallerSHA256 is the hex-encoded SHA-256 of the software installer
+	// package, verified by the client after download before running the
+	// install script.
+	InstallerSHA256 string `json:"installer_sha256" db:"installer_sha256"`
 	// SoftwareInstallerURL contains the details to download the software installer from CDN.
 	SoftwareInstallerURL *SoftwareInstallerURL `json:"installer_url,omitempty"`
+	// SoftwareInstallerCacheToken is the short-lived token that authorizes the
+	// download of the installer from the software installer cache, set only if
+	// a cache serves the host's subnet.
+	SoftwareInstallerCacheToken string `json:"installer_cache_token,omitempty"`
 }
 
 type SoftwareInstallerURL struct {
@@ -127,6 +135,12 @@ type SoftwareInstaller struct {
 	PostInstallScriptContentID *uint `json:"-" db:"post_install_script_content_id"`
 	// StorageID is the unique identifier for the software package in the software installer store.
 	StorageID string `json:"-" db:"storage_id"`
+	// HashSHA256 is the hex-encoded SHA-256 of the software package (its
+	// storage ID).
+	HashSHA256 string `json:"hash_sha256" db:"hash_sha256"`
+	// SoftwareInstallerSignature is the signature information of the
+	// software package, verified when it was added.
+	SoftwareInstallerSignature
 	// Status is the status of the software installer package.
 	Status *SoftwareInstallerStatusSummary `json:"status,omitempty" db:"-"`
 	// SoftwareTitle is the title of the software pointed installed by this installer.
@@ -369,6 +383,12 @@ type UploadSoftwareInstallerPayload struct {
 	// is nil if the labels have not been validated.
 	ValidatedLabels  *LabelIdentsWithScope
 	AutomaticInstall bool
+	// AutomaticInstallQuery is the query of the automatic install policy, it
+	// is generated from the installer metadata if empty (used for MDMlab
+	// maintained apps).
+	AutomaticInstallQuery string
+	// Signature is the signature information of the installer file.
+	Signature SoftwareInstallerSignature
 }
 
 type UpdateSoftwareInstallerPayload struct {
@@ -393,6 +413,7 @@ type UpdateSoftwareInstallerPayload struct {
 	Filename          string
 	Version           string
 	PackageIDs        []string
+	Signature         SoftwareInstallerSignature
 	LabelsIncludeAny  []string // names of "include any" labels
 	LabelsExcludeAny  []string // names of "exclude any" labels
 	// ValidatedLabels is a struct that contains the validated labels for the software installer. It
@@ -414,6 +435,12 @@ func SofwareInstallerSourceFromExtensionAndName(ext, name string) (string, error
 		return "deb_packages", nil
 	case "rpm":
 		return "rpm_packages", nil
+	case "snap", "snapref":
+		return "snap_packages", nil
+	case "flatpak", "flatpakref":
+		return "flatpak_packages", nil
+	case "appimage":
+		return "appimage_packages", nil
 	case "exe", "msi":
 		return "programs", nil
 	case "pkg":
@@ -429,7 +456,7 @@ func SofwareInstallerSourceFromExtensionAndName(ext, name string) (string, error
 func SofwareInstallerPlatformFromExtension(ext string) (string, error) {
 	ext = strings.TrimPrefix(ext, ".")
 	switch ext {
-	case "deb", "rpm":
+	case "deb", "rpm", "snap", "snapref", "flatpak", "flatpakref", "appimage":
 		return "linux", nil
 	case "exe", "msi":
 		return "windows", nil
@@ -0,0 +1,88 @@
+package mdmlab
+
+import (
+	"net"
+	"net/url"
+)
+
+// SoftwareInstallerCache is a local cache server (started with `mdmlab
+// software_cache`) that serves the software installers and the bootstrap
+// packages to the hosts of a site, so that they are downloaded only once
+// over the WAN link.
+type SoftwareInstallerCache struct {
+	// URL is the base URL of the cache server, as reachable from the hosts
+	// (e.g. "https://mdmlab-cache.branch1.example.com:8443").
+	URL string `json:"url"`
+	// Subnets are the CIDR ranges of the hosts that download from the cache,
+	// matched against the host's primary IP address.
+	Subnets []string `json:"subnets"`
+}
+
+// SoftwareInstallerCacheTokenMetadata is the install authorized by a software
+// installer cache token. The token is issued to the host with the details of
+// the install and is the only credential sent to the cache.
+type SoftwareInstallerCacheTokenMetadata struct {
+	InstallUUID     string `json:"install_uuid"`
+	InstallerID     uint   `json:"installer_id"`
+	InstallerSHA256 string `json:"installer_sha256"`
+	StorageID       string `json:"storage_id"`
+	Filename        string `json:"filename"`
+}
+
+// Copy returns a deep copy of the cache.
+func (c SoftwareInstallerCache) Copy() SoftwareInstallerCache {
+	if c.Subnets != nil {
+		subnets := make([]string, len(c.Subnets))
+		copy(subnets, c.Subnets)
+		c.Subnets = subnets
+	}
+	return c
+}
+
+// CacheURLForIP returns the URL of the software installer cache to use for a
+// host with the given IP address, or an empty string if no cache serves the
+// host's subnet. If multiple caches match, the one with the most specific
+// subnet is used.
+func (s SoftwareInstallerSettings) CacheURLForIP(ip string) string {
+	hostIP := net.ParseIP(ip)
+	if hostIP == nil {
+		return ""
+	}
+
+	var (
+		cacheURL string
+		bestOnes = -1
+	)
+	for _, c := range s.Caches {
+		for _, subnet := range c.Subnets {
+			_, ipNet, err := net.ParseCIDR(subnet)
+			if err != nil || !ipNet.Contains(hostIP) {
+				continue
+			}
+			if ones, _ := ipNet.Mask.Size(); ones > bestOnes {
+				cacheURL, bestOnes = c.URL, ones
+			}
+		}
+	}
+	return cacheURL
+}
+
+// ValidateSoftwareInstallerCaches appends an error to invalid for each
+// invalid software installer cache.
+func ValidateSoftwareInstallerCaches(caches []SoftwareInstallerCache, invalid *InvalidArgumentError) {
+	for _, c := range caches {
+		u, err := url.Parse(c.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			invalid.Appendf("software_installer_settings.caches.url", "invalid cache URL %q: an https URL is required", c.URL)
+			continue
+		}
+		if len(c.Subnets) == 0 {
+			invalid.Appendf("software_installer_settings.caches.subnets", "cache %q: at least one subnet is required", c.URL)
+		}
+		for _, subnet := range c.Subnets {
+			if _, _, err := net.ParseCIDR(subnet); err != nil {
+				invalid.Appendf("software_installer_settings.caches.subnets", "cache %q: invalid subnet %q", c.URL, subnet)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package mdmlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareInstallerCacheURLForIP(t *testing.T) {
+	settings := SoftwareInstallerSettings{
+		Caches: []SoftwareInstallerCache{
+			{URL: "https://site.example.com", Subnets: []string{"10.0.0.0/8"}},
+			{URL: "https://branch1.example.com", Subnets: []string{"10.1.0.0/16", "fd00:1::/64"}},
+			{URL: "https://invalid.example.com", Subnets: []string{"not-a-subnet"}},
+		},
+	}
+	cases := []struct {
+		ip   string
+		want string
+	}{
+		{"10.2.3.4", "https://site.example.com"},
+		{"10.1.3.4", "https://branch1.example.com"},
+		{"fd00:1::10", "https://branch1.example.com"},
+		{"192.168.1.1", ""},
+		{"", ""},
+		{"not-an-ip", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.ip, func(t *testing.T) {
+			require.Equal(t, c.want, settings.CacheURLForIP(c.ip))
+		})
+	}
+
+	require.Empty(t, SoftwareInstallerSettings{}.CacheURLForIP("10.1.3.4"))
+}
+
+func TestValidateSoftwareInstallerCaches(t *testing.T) {
+	cases := []struct {
+		name    string
+		caches  []SoftwareInstallerCache
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			caches: []SoftwareInstallerCache{{URL: "https://cache.example.com:8443", Subnets: []string{"10.1.0.0/16", "fd00::/8"}}},
+		},
+		{
+			name:    "invalid URL scheme",
+			caches:  []SoftwareInstallerCache{{URL: "ftp://cache.example.com", Subnets: []string{"10.1.0.0/16"}}},
+			wantErr: `invalid cache URL "ftp://cache.example.com"`,
+		},
+		{
+			name:    "http URL",
+			caches:  []SoftwareInstallerCache{{URL: "http://cache.example.com:8080", Subnets: []string{"10.1.0.0/16"}}},
+			wantErr: `invalid cache URL "http://cache.example.com:8080": an https URL is required`,
+		},
+		{
+			name:    "missing URL host",
+			caches:  []SoftwareInstallerCache{{URL: "https://", Subnets: []string{"10.1.0.0/16"}}},
+			wantErr: "invalid cache URL",
+		},
+		{
+			name:    "no subnet",
+			caches:  []SoftwareInstallerCache{{URL: "https://cache.example.com"}},
+			wantErr: "at least one subnet is required",
+		},
+		{
+			name:    "invalid subnet",
+			caches:  []SoftwareInstallerCache{{URL: "https://cache.example.com", Subnets: []string{"10.1.0.0"}}},
+			wantErr: `invalid subnet "10.1.0.0"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			invalid := &InvalidArgumentError{}
+			ValidateSoftwareInstallerCaches(c.caches, invalid)
+			if c.wantErr == "" {
+				require.False(t, invalid.HasErrors())
+				return
+			}
+			require.ErrorContains(t, invalid, c.wantErr)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package mdmlab
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// SoftwareInstallerRolloutStatus is the status of the staged rollout of a
+// software installer.
+type SoftwareInstallerRolloutStatus string
+
+const (
+	// SoftwareInstallerRolloutInProgress means that the automatic installs are
+	// limited to the hosts of the current and previous stages.
+	SoftwareInstallerRolloutInProgress SoftwareInstallerRolloutStatus = "in_progress"
+	// SoftwareInstallerRolloutPaused means that no automatic install is queued
+	// until the rollout is resumed.
+	SoftwareInstallerRolloutPaused SoftwareInstallerRolloutStatus = "paused"
+	// SoftwareInstallerRolloutCompleted means that all the stages succeeded and
+	// the installer is available to all hosts in scope.
+	SoftwareInstallerRolloutCompleted SoftwareInstallerRolloutStatus = "completed"
+)
+
+const (
+	// DefaultSoftwareInstallerRolloutSuccessThreshold is the default minimum
+	// install success rate (percent) required to advance to the next stage.
+	DefaultSoftwareInstallerRolloutSuccessThreshold uint = 95
+	// DefaultSoftwareInstallerRolloutMaxFailedInstalls is the default number of
+	// failed installs above which the rollout is paused.
+	DefaultSoftwareInstallerRolloutMaxFailedInstalls uint = 5
+)
+
+// SoftwareInstallerRolloutStage is a ring of a staged rollout. It targets
+// either a percentage of the hosts or the members of a label. Stages are
+// cumulative: a host targeted by a stage stays targeted by the following
+// ones.
+type SoftwareInstallerRolloutStage struct {
+	// Percent is the percentage of hosts targeted by the stage (1-100).
+	Percent *uint `json:"percent,omitempty"`
+	// LabelID is the label whose members are targeted by the stage.
+	LabelID *uint `json:"label_id,omitempty"`
+	// SoakMinutes is the minimum duration of the stage before advancing to the
+	// next one.
+	SoakMinutes uint `json:"soak_minutes"`
+}
+
+// SoftwareInstallerRolloutStages is the list of stages of a rollout, stored as
+// JSON.
+type SoftwareInstallerRolloutStages []SoftwareInstallerRolloutStage
+
+// Scan implements the sql.Scanner interface
+func (s *SoftwareInstallerRolloutStages) Scan(val interface{}) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case nil: // sql NULL
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+// Value implements the sql.Valuer interface
+func (s SoftwareInstallerRolloutStages) Value() (driver.Value, error) {
+	if s == nil {
+		s = SoftwareInstallerRolloutStages{}
+	}
+	return json.Marshal(s)
+}
+
+// SoftwareInstallerRollout is the staged rollout of a software installer
+// version. Policy automations only install the software on the hosts targeted
+// by the stages reached so far.
+type SoftwareInstallerRollout struct {
+	ID                  uint `json:"-" db:"id"`
+	SoftwareInstallerID uint `json:"software_installer_id" db:"software_installer_id"`
+	// TeamID, TitleID, SoftwareTitle, Package, InstallerVersion and
+	// InstallerPlatform are those of the software installer.
+	TeamID            *uint  `json:"team_id" db:"team_id"`
+	TitleID           uint   `json:"software_title_id" db:"title_id"`
+	SoftwareTitle     string `json:"software_title" db:"software_title"`
+	Package           string `json:"software_package" db:"filename"`
+	InstallerVersion  string `json:"-" db:"installer_version"`
+	InstallerPlatform string `json:"-" db:"platform"`
+	// Version is the installer version being rolled out. The rollout restarts
+	// from the first stage when a new version of the installer is uploaded.
+	Version                 string                         `json:"version" db:"version"`
+	Stages                  SoftwareInstallerRolloutStages `json:"stages" db:"stages"`
+	SuccessThresholdPercent uint                           `json:"success_threshold_percent" db:"success_threshold_percent"`
+	MaxFailedInstalls       uint                           `json:"max_failed_installs" db:"max_failed_installs"`
+	Status                  SoftwareInstallerRolloutStatus `json:"status" db:"status"`
+	// StatusReason explains why the rollout was paused.
+	StatusReason *string `json:"status_reason" db:"status_reason"`
+	// CurrentStage is the zero-based index of the current stage.
+	CurrentStage   uint      `json:"current_stage" db:"current_stage"`
+	StageStartedAt time.Time `json:"stage_started_at" db:"stage_started_at"`
+	// StartedAt is when the rollout of Version started, only the installs
+	// requested since then count towards the success rate.
+	StartedAt time.Time `json:"started_at" db:"started_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Stats *SoftwareInstallerRolloutStats `json:"stats,omitempty" db:"-"`
+}
+
+// SoftwareInstallerRolloutStats are the results of the installs requested
+// since the start of the rollout of the current version.
+type SoftwareInstallerRolloutStats struct {
+	Installed uint `json:"installed" db:"installed"`
+	Failed    uint `json:"failed" db:"failed"`
+	Pending   uint `json:"pending" db:"pending"`
+}
+
+// SuccessRate returns the percentage of successful installs among the
+// completed ones, and false if no install completed yet.
+func (s SoftwareInstallerRolloutStats) SuccessRate() (float64, bool) {
+	done := s.Installed + s.Failed
+	if done == 0 {
+		return 0, false
+	}
+	return float64(s.Installed) * 100 / float64(done), true
+}
+
+// SoftwareInstallerRolloutBucket returns the bucket (0-99) of the host for the
+// rollouts of the installer, a host is targeted by a percentage stage if its
+// bucket is lower than the percentage. The buckets are computed in MySQL as
+// CRC32(CONCAT(installer_id, ':', host_id)) % 100.
+func SoftwareInstallerRolloutBucket(installerID, hostID uint) uint {
+	return uint(crc32.ChecksumIEEE([]byte(fmt.Sprintf("%d:%d", installerID, hostID))) % 100)
+}
+
+// ReachedStages returns the stages whose hosts are targeted, all of them if
+// the rollout is completed and none if it is paused.
+func (r *SoftwareInstallerRollout) ReachedStages() []SoftwareInstallerRolloutStage {
+	switch r.Status {
+	case SoftwareInstallerRolloutCompleted:
+		return r.Stages
+	case SoftwareInstallerRolloutPaused:
+		return nil
+	}
+	if int(r.CurrentStage) >= len(r.Stages) {
+		return r.Stages
+	}
+	return r.Stages[:r.CurrentStage+1]
+}
+
+// SoftwareInstallerRolloutPayload is the payload to start the staged rollout
+// of a software installer.
+type SoftwareInstallerRolloutPayload struct {
+	TitleID                 uint
+	TeamID                  *uint
+	Stages                  []SoftwareInstallerRolloutStage
+	SuccessThresholdPercent *uint
+	MaxFailedInstalls       *uint
+}
+
+// Validate checks the stages and thresholds of the rollout.
+func (p *SoftwareInstallerRolloutPayload) Validate() error {
+	if len(p.Stages) == 0 {
+		return NewInvalidArgumentError("stages", "at least one stage is required")
+	}
+
+	var prevPercent uint
+	for i, stage := range p.Stages {
+		switch {
+		case stage.Percent == nil && stage.LabelID == nil:
+			return NewInvalidArgumentError("stages", fmt.Sprintf("stage %d: one of percent or label_id is required", i+1))
+		case stage.Percent != nil && stage.LabelID != nil:
+			return NewInvalidArgumentError("stages", fmt.Sprintf("stage %d: only one of percent or label_id can be set", i+1))
+		case stage.Percent != nil && (*stage.Percent == 0 || *stage.Percent > 100):
+			return NewInvalidArgumentError("stages", fmt.Sprintf("stage %d: percent must be between 1 and 100", i+1))
+		case stage.Percent != nil && *stage.Percent <= prevPercent:
+			return NewInvalidArgumentError("stages", fmt.Sprintf("stage %d: percent must be greater than the percent of the previous stages", i+1))
+		}
+		if stage.Percent != nil {
+			prevPercent = *stage.Percent
+		}
+	}
+
+	if p.SuccessThresholdPercent != nil && *p.SuccessThresholdPercent > 100 {
+		return NewInvalidArgumentError("success_threshold_percent", "must be between 0 and 100")
+	}
+	return nil
+}
+
+// SoftwareInstallerRolloutHost is a host targeted by the reached stages of a
+// rollout that fails a policy automatically installing the software.
+type SoftwareInstallerRolloutHost struct {
+	HostID   uint   `db:"host_id"`
+	Platform string `db:"platform"`
+	PolicyID uint   `db:"policy_id"`
+}
+
+// ActivityDetails returns the details of the rollout activities, teamName is
+// the name of the team of the installer (nil for no team).
+func (r *SoftwareInstallerRollout) ActivityDetails(teamName *string) ActivitySoftwareRollout {
+	return ActivitySoftwareRollout{
+		SoftwareTitle:   r.SoftwareTitle,
+		SoftwarePackage: r.Package,
+		SoftwareTitleID: r.TitleID,
+		TeamName:        teamName,
+		TeamID:          r.TeamID,
+		Version:         r.Version,
+		Stage:           r.CurrentStage + 1,
+		StagesCount:     len(r.Stages),
+	}
+}
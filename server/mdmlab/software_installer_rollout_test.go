@@ -0,0 +1,140 @@
+package mdmlab
+
+import (
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareInstallerRolloutPayloadValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload SoftwareInstallerRolloutPayload
+		wantErr string
+	}{
+		{
+			name:    "no stages",
+			payload: SoftwareInstallerRolloutPayload{},
+			wantErr: "at least one stage is required",
+		},
+		{
+			name:    "empty stage",
+			payload: SoftwareInstallerRolloutPayload{Stages: []SoftwareInstallerRolloutStage{{SoakMinutes: 10}}},
+			wantErr: "stage 1: one of percent or label_id is required",
+		},
+		{
+			name: "percent and label",
+			payload: SoftwareInstallerRolloutPayload{Stages: []SoftwareInstallerRolloutStage{
+				{Percent: ptr.Uint(10), LabelID: ptr.Uint(1)},
+			}},
+			wantErr: "stage 1: only one of percent or label_id can be set",
+		},
+		{
+			name:    "zero percent",
+			payload: SoftwareInstallerRolloutPayload{Stages: []SoftwareInstallerRolloutStage{{Percent: ptr.Uint(0)}}},
+			wantErr: "stage 1: percent must be between 1 and 100",
+		},
+		{
+			name:    "percent above 100",
+			payload: SoftwareInstallerRolloutPayload{Stages: []SoftwareInstallerRolloutStage{{Percent: ptr.Uint(101)}}},
+			wantErr: "stage 1: percent must be between 1 and 100",
+		},
+		{
+			name: "decreasing percent",
+			payload: SoftwareInstallerRolloutPayload{Stages: []SoftwareInstallerRolloutStage{
+				{Percent: ptr.Uint(50)},
+				{LabelID: ptr.Uint(1)},
+				{Percent: ptr.Uint(50)},
+			}},
+			wantErr: "stage 3: percent must be greater than the percent of the previous stages",
+		},
+		{
+			name: "threshold above 100",
+			payload: SoftwareInstallerRolloutPayload{
+				Stages:                  []SoftwareInstallerRolloutStage{{Percent: ptr.Uint(100)}},
+				SuccessThresholdPercent: ptr.Uint(101),
+			},
+			wantErr: "success_threshold_percent",
+		},
+		{
+			name: "valid",
+			payload: SoftwareInstallerRolloutPayload{
+				Stages: []SoftwareInstallerRolloutStage{
+					{LabelID: ptr.Uint(1), SoakMinutes: 60},
+					{Percent: ptr.Uint(10), SoakMinutes: 60},
+					{Percent: ptr.Uint(100)},
+				},
+				SuccessThresholdPercent: ptr.Uint(90),
+				MaxFailedInstalls:       ptr.Uint(0),
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.payload.Validate()
+			if c.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, c.wantErr)
+		})
+	}
+}
+
+func TestSoftwareInstallerRolloutReachedStages(t *testing.T) {
+	stages := SoftwareInstallerRolloutStages{
+		{LabelID: ptr.Uint(1)},
+		{Percent: ptr.Uint(10)},
+		{Percent: ptr.Uint(100)},
+	}
+
+	r := &SoftwareInstallerRollout{Stages: stages, Status: SoftwareInstallerRolloutInProgress}
+	require.Equal(t, []SoftwareInstallerRolloutStage(stages[:1]), r.ReachedStages())
+
+	r.CurrentStage = 1
+	require.Equal(t, []SoftwareInstallerRolloutStage(stages[:2]), r.ReachedStages())
+
+	r.CurrentStage = 5
+	require.Equal(t, []SoftwareInstallerRolloutStage(stages), r.ReachedStages())
+
+	r.CurrentStage = 0
+	r.Status = SoftwareInstallerRolloutPaused
+	require.Empty(t, r.ReachedStages())
+
+	r.Status = SoftwareInstallerRolloutCompleted
+	require.Equal(t, []SoftwareInstallerRolloutStage(stages), r.ReachedStages())
+}
+
+func TestSoftwareInstallerRolloutStats(t *testing.T) {
+	_, ok := SoftwareInstallerRolloutStats{Pending: 3}.SuccessRate()
+	require.False(t, ok)
+
+	rate, ok := SoftwareInstallerRolloutStats{Installed: 9, Failed: 1, Pending: 10}.SuccessRate()
+	require.True(t, ok)
+	require.Equal(t, 90.0, rate)
+}
+
+func TestSoftwareInstallerRolloutBucket(t *testing.T) {
+	var counts [100]int
+	for hostID := uint(1); hostID <= 10000; hostID++ {
+		b := SoftwareInstallerRolloutBucket(1, hostID)
+		require.Less(t, b, uint(100))
+		counts[b]++
+	}
+	// the hosts are spread across all the buckets
+	for b, n := range counts {
+		require.NotZero(t, n, "bucket %d", b)
+	}
+
+	// the bucket is stable and depends on the installer
+	require.Equal(t, SoftwareInstallerRolloutBucket(1, 42), SoftwareInstallerRolloutBucket(1, 42))
+	var differ bool
+	for installerID := uint(2); installerID < 20; installerID++ {
+		if SoftwareInstallerRolloutBucket(installerID, 42) != SoftwareInstallerRolloutBucket(1, 42) {
+			differ = true
+			break
+		}
+	}
+	require.True(t, differ)
+}
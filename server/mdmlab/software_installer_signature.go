@@ -0,0 +1,60 @@
+package mdmlab
+
+// SoftwareInstallerSignatureStatus is the result of the verification of the
+// signature of a software installer package when it was added.
+type SoftwareInstallerSignatureStatus string
+
+const (
+	// SoftwareInstallerSignatureValid is a signature that matches the package
+	// and was made by a trusted signer: an Apple Developer ID Installer
+	// certificate for .pkg, a code signing certificate issued by a trusted
+	// certificate authority for .msi and .exe and one of the trusted GPG keys
+	// for .deb and .rpm.
+	SoftwareInstallerSignatureValid SoftwareInstallerSignatureStatus = "valid"
+	// SoftwareInstallerSignatureUntrusted is a signature that matches the
+	// package but whose signer is not trusted (e.g. a self-signed certificate
+	// or an unknown GPG key).
+	SoftwareInstallerSignatureUntrusted SoftwareInstallerSignatureStatus = "untrusted"
+	// SoftwareInstallerSignatureInvalid is a signature that doesn't match the
+	// package, i.e. the package was modified after it was signed, or that
+	// can't be parsed.
+	SoftwareInstallerSignatureInvalid SoftwareInstallerSignatureStatus = "invalid"
+	// SoftwareInstallerSignatureUnsigned is a package without signature.
+	SoftwareInstallerSignatureUnsigned SoftwareInstallerSignatureStatus = "unsigned"
+	// SoftwareInstallerSignatureUnsupported is a package of a type whose
+	// signature can't be verified by MDMlab (e.g. .snap or .flatpak).
+	SoftwareInstallerSignatureUnsupported SoftwareInstallerSignatureStatus = "unsupported"
+)
+
+// SoftwareInstallerSignature is the signature information of a software
+// installer package. The status is empty for the packages added before
+// MDMlab verified signatures.
+type SoftwareInstallerSignature struct {
+	SignatureStatus SoftwareInstallerSignatureStatus `json:"signature_status" db:"signature_status"`
+	// Signer is the identity of the signer: the common name of the signing
+	// certificate for .pkg, .msi and .exe (e.g. "Developer ID Installer:
+	// Mozilla Corporation (43AQ936H96)") and the user ID of the GPG key for
+	// .deb and .rpm (or its key ID if the key is not trusted).
+	Signer string `json:"signer" db:"signer"`
+	// SignerTeamID is the Apple Developer team ID of the signer of a .pkg.
+	SignerTeamID string `json:"signer_team_id" db:"signer_team_id"`
+	// HasStapledTicket is true if a notarization ticket is stapled to the .pkg.
+	// The ticket isn't verified, it doesn't mean that the package is notarized
+	// by Apple.
+	HasStapledTicket bool `json:"has_stapled_ticket" db:"has_stapled_ticket"`
+}
+
+// SoftwareInstallerSettings are the settings applied when adding software
+// installer packages.
+type SoftwareInstallerSettings struct {
+	// RequireValidSignature rejects the packages whose signature status is
+	// not "valid". Packages added before the setting was enabled are not
+	// affected.
+	RequireValidSignature bool `json:"require_valid_signature"`
+	// TrustedGPGKeys are the ASCII-armored public keys used to verify the
+	// signature of .deb and .rpm packages.
+	TrustedGPGKeys []string `json:"trusted_gpg_keys"`
+	// Caches are the local cache servers from which the hosts download the
+	// software installers and bootstrap packages, based on their subnet.
+	Caches []SoftwareInstallerCache `json:"caches"`
+}
@@ -0,0 +1,51 @@
+package mdmlab
+
+import "time"
+
+// SoftwareInstallerVersion is a package (with its scripts) uploaded for a
+// software installer. The software installer keeps the history of its
+// packages so that a team can pin one of them, roll back to a previous one or
+// target one to the members of some labels.
+//
+// The version installed on a host is, in order of precedence: the latest
+// version targeted to a label the host is a member of, the pinned version and
+// the current version of the installer.
+type SoftwareInstallerVersion struct {
+	ID                  uint   `json:"id" db:"id"`
+	SoftwareInstallerID uint   `json:"-" db:"software_installer_id"`
+	Version             string `json:"version" db:"version"`
+	// Filename is the filename of the package.
+	Filename   string    `json:"name" db:"filename"`
+	StorageID  string    `json:"-" db:"storage_id"`
+	UserName   string    `json:"uploaded_by_name" db:"user_name"`
+	UserEmail  string    `json:"uploaded_by_email" db:"user_email"`
+	UploadedAt time.Time `json:"uploaded_at" db:"uploaded_at"`
+	// SoftwareInstallerSignature is the signature information of the package.
+	SoftwareInstallerSignature
+	// Current is true for the version of the software installer, i.e. the
+	// latest uploaded version or the one it was rolled back to.
+	Current bool `json:"current" db:"is_current"`
+	// Pinned is true if the team pinned this version, it is then installed
+	// instead of the current version on the hosts not targeted by a label.
+	Pinned bool `json:"pinned" db:"is_pinned"`
+	// LabelsIncludeAny are the labels whose members get this version.
+	LabelsIncludeAny []SoftwareScopeLabel `json:"labels_include_any" db:"-"`
+}
+
+// ActivityDetails returns the details of the version activities, installer is
+// the software installer of the version and teamName the name of its team (nil
+// for no team).
+func (v *SoftwareInstallerVersion) ActivityDetails(installer *SoftwareInstaller, teamName *string) ActivitySoftwareVersion {
+	var titleID uint
+	if installer.TitleID != nil {
+		titleID = *installer.TitleID
+	}
+	return ActivitySoftwareVersion{
+		SoftwareTitle:   installer.SoftwareTitle,
+		SoftwarePackage: v.Filename,
+		SoftwareTitleID: titleID,
+		TeamName:        teamName,
+		TeamID:          installer.TeamID,
+		Version:         v.Version,
+	}
+}
@@ -0,0 +1,158 @@
+package mdmlab
+
+import (
+	"time"
+)
+
+// SoftwareLicenseDefaultUnusedAfterDays is the default number of days after
+// which an install that was not opened is considered unused.
+const SoftwareLicenseDefaultUnusedAfterDays = 30
+
+// SoftwareLicense is the license entitlement of a software title for the hosts
+// of a team. The usage of the license is computed from the software inventory
+// of the hosts of the team. A nil TeamID is the hosts without a team, not all
+// the hosts: a title licensed for the whole organization has a license per
+// team, and the report of all the teams totals them.
+type SoftwareLicense struct {
+	ID       uint    `json:"id" db:"id"`
+	TeamID   *uint   `json:"team_id" db:"team_id"`
+	TeamName *string `json:"team_name" db:"team_name"`
+	// SoftwareTitleID is the software title that is licensed, all the
+	// versions of the title count as installs of the license.
+	SoftwareTitleID uint   `json:"software_title_id" db:"title_id"`
+	SoftwareTitle   string `json:"software_title" db:"software_title_name"`
+	Vendor          string `json:"vendor" db:"vendor"`
+	// SeatCount is the number of hosts on which the software can be
+	// installed.
+	SeatCount uint       `json:"seat_count" db:"seat_count"`
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	// Cost is the cost of the license, in the currency used by the
+	// organization.
+	Cost *float64 `json:"cost" db:"cost"`
+	// UnusedAfterDays is the number of days after which an install that was
+	// not opened is considered unused.
+	UnusedAfterDays uint      `json:"unused_after_days" db:"unused_after_days"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	Usage *SoftwareLicenseUsage `json:"usage" db:"-"`
+}
+
+// Expired returns whether the license is expired at the time.
+func (l *SoftwareLicense) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// UsageStatus returns the usage status of an install of the license that was
+// last opened at lastOpenedAt (nil if the host does not report it).
+func (l *SoftwareLicense) UsageStatus(lastOpenedAt *time.Time, now time.Time) SoftwareLicenseUsageStatus {
+	if lastOpenedAt == nil {
+		return SoftwareLicenseUsageUnknown
+	}
+	if lastOpenedAt.Before(now.AddDate(0, 0, -int(l.UnusedAfterDays))) {
+		return SoftwareLicenseUsageUnused
+	}
+	return SoftwareLicenseUsageUsed
+}
+
+// SetUsage sets the usage of the license from the counts of its installs and
+// flags the over-deployment and the expiry of the license.
+func (l *SoftwareLicense) SetUsage(counts SoftwareLicenseUsage, now time.Time) {
+	counts.AvailableSeats = int(l.SeatCount) - int(counts.Installs) //nolint:gosec // dismiss G115
+	counts.OverDeployed = counts.AvailableSeats < 0
+	counts.Expired = l.Expired(now)
+	l.Usage = &counts
+}
+
+// ActivityDetails returns the details of the license activities.
+func (l *SoftwareLicense) ActivityDetails() ActivitySoftwareLicense {
+	return ActivitySoftwareLicense{
+		LicenseID:       l.ID,
+		TeamName:        l.TeamName,
+		TeamID:          l.TeamID,
+		SoftwareTitleID: l.SoftwareTitleID,
+		SoftwareTitle:   l.SoftwareTitle,
+		Vendor:          l.Vendor,
+		SeatCount:       l.SeatCount,
+	}
+}
+
+// SoftwareLicenseUsage is the usage of a software license by the hosts of its
+// team.
+type SoftwareLicenseUsage struct {
+	// Installs is the number of hosts with any version of the software.
+	Installs uint `json:"installs" db:"installs"`
+	// Used is the number of installs opened in the last UnusedAfterDays days.
+	Used uint `json:"used" db:"used"`
+	// Unused is the number of installs not opened in the last UnusedAfterDays
+	// days.
+	Unused uint `json:"unused" db:"unused"`
+	// Unknown is the number of installs whose last opened time is not
+	// reported by the host.
+	Unknown uint `json:"unknown" db:"unknown"`
+	// AvailableSeats is the number of seats minus the number of installs, it
+	// is negative when the license is over-deployed.
+	AvailableSeats int  `json:"available_seats" db:"-"`
+	OverDeployed   bool `json:"over_deployed" db:"-"`
+	Expired        bool `json:"expired" db:"-"`
+}
+
+// SoftwareLicenseUsageStatus is the usage status of an install of a licensed
+// software.
+type SoftwareLicenseUsageStatus string
+
+const (
+	SoftwareLicenseUsageUsed    SoftwareLicenseUsageStatus = "used"
+	SoftwareLicenseUsageUnused  SoftwareLicenseUsageStatus = "unused"
+	SoftwareLicenseUsageUnknown SoftwareLicenseUsageStatus = "unknown"
+)
+
+// IsValid returns whether the usage status is known.
+func (s SoftwareLicenseUsageStatus) IsValid() bool {
+	switch s {
+	case SoftwareLicenseUsageUsed, SoftwareLicenseUsageUnused, SoftwareLicenseUsageUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// SoftwareLicenseHost is a host of the team of a license with the licensed
+// software installed.
+type SoftwareLicenseHost struct {
+	HostID          uint   `json:"host_id" db:"host_id"`
+	HostDisplayName string `json:"host_display_name" db:"host_display_name"`
+	// LastOpenedAt is the most recent time any version of the software was
+	// opened on the host, nil if the host does not report it.
+	LastOpenedAt *time.Time                 `json:"last_opened_at" db:"last_opened_at"`
+	UsageStatus  SoftwareLicenseUsageStatus `json:"usage_status" db:"-"`
+}
+
+// SoftwareLicensePayload is the payload to create or replace a software
+// license.
+type SoftwareLicensePayload struct {
+	TeamID          *uint
+	SoftwareTitleID uint
+	Vendor          string
+	SeatCount       uint
+	ExpiresAt       *time.Time
+	Cost            *float64
+	UnusedAfterDays *uint
+}
+
+// Validate checks the seats, cost and unused period of the license.
+func (p *SoftwareLicensePayload) Validate() error {
+	if p.SoftwareTitleID == 0 {
+		return NewInvalidArgumentError("software_title_id", "is required")
+	}
+	if p.SeatCount == 0 {
+		return NewInvalidArgumentError("seat_count", "must be greater than 0")
+	}
+	if p.Cost != nil && *p.Cost < 0 {
+		return NewInvalidArgumentError("cost", "must be greater than or equal to 0")
+	}
+	if p.UnusedAfterDays != nil && *p.UnusedAfterDays == 0 {
+		return NewInvalidArgumentError("unused_after_days", "must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package mdmlab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareLicensePayloadValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload SoftwareLicensePayload
+		wantErr string
+	}{
+		{
+			name:    "no software title",
+			payload: SoftwareLicensePayload{SeatCount: 10},
+			wantErr: "software_title_id",
+		},
+		{
+			name:    "no seats",
+			payload: SoftwareLicensePayload{SoftwareTitleID: 1},
+			wantErr: "must be greater than 0",
+		},
+		{
+			name:    "negative cost",
+			payload: SoftwareLicensePayload{SoftwareTitleID: 1, SeatCount: 10, Cost: ptr.Float64(-1)},
+			wantErr: "must be greater than or equal to 0",
+		},
+		{
+			name:    "zero unused period",
+			payload: SoftwareLicensePayload{SoftwareTitleID: 1, SeatCount: 10, UnusedAfterDays: ptr.Uint(0)},
+			wantErr: "must be greater than 0",
+		},
+		{
+			name:    "valid",
+			payload: SoftwareLicensePayload{SoftwareTitleID: 1, SeatCount: 10, Cost: ptr.Float64(1200), UnusedAfterDays: ptr.Uint(60)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.payload.Validate()
+			if c.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, c.wantErr)
+		})
+	}
+}
+
+func TestSoftwareLicenseUsage(t *testing.T) {
+	now := time.Now()
+	license := &SoftwareLicense{SeatCount: 2, UnusedAfterDays: 30}
+
+	require.Equal(t, SoftwareLicenseUsageUnknown, license.UsageStatus(nil, now))
+	require.Equal(t, SoftwareLicenseUsageUsed, license.UsageStatus(ptr.Time(now.AddDate(0, 0, -29)), now))
+	require.Equal(t, SoftwareLicenseUsageUnused, license.UsageStatus(ptr.Time(now.AddDate(0, 0, -31)), now))
+
+	license.SetUsage(SoftwareLicenseUsage{Installs: 1, Used: 1}, now)
+	require.Equal(t, 1, license.Usage.AvailableSeats)
+	require.False(t, license.Usage.OverDeployed)
+	require.False(t, license.Usage.Expired)
+
+	license.ExpiresAt = ptr.Time(now.Add(-time.Hour))
+	license.SetUsage(SoftwareLicenseUsage{Installs: 3, Used: 1, Unused: 1, Unknown: 1}, now)
+	require.Equal(t, -1, license.Usage.AvailableSeats)
+	require.True(t, license.Usage.OverDeployed)
+	require.True(t, license.Usage.Expired)
+	require.Equal(t, uint(1), license.Usage.Unknown)
+}
@@ -0,0 +1,101 @@
+package mdmlab
+
+import (
+	"time"
+)
+
+// SoftwareRequestStatus is the status of the review of a software request.
+type SoftwareRequestStatus string
+
+const (
+	// SoftwareRequestPending means that the request was not reviewed yet.
+	SoftwareRequestPending SoftwareRequestStatus = "pending"
+	// SoftwareRequestApproved means that a maintainer approved the request,
+	// the software is installed on the host of the request.
+	SoftwareRequestApproved SoftwareRequestStatus = "approved"
+	// SoftwareRequestDenied means that a maintainer denied the request.
+	SoftwareRequestDenied SoftwareRequestStatus = "denied"
+)
+
+// IsValid returns whether the status is one of the known statuses.
+func (s SoftwareRequestStatus) IsValid() bool {
+	switch s {
+	case SoftwareRequestPending, SoftwareRequestApproved, SoftwareRequestDenied:
+		return true
+	default:
+		return false
+	}
+}
+
+// SoftwareRequest is a request made by an end user from the My device page
+// to install a software title of the catalog of the team of their host that
+// is not available in self-service.
+type SoftwareRequest struct {
+	ID              uint   `json:"id" db:"id"`
+	HostID          uint   `json:"host_id" db:"host_id"`
+	HostDisplayName string `json:"host_display_name" db:"host_display_name"`
+	// TeamID is the team of the host when the request was made (nil for no
+	// team).
+	TeamID          *uint  `json:"team_id" db:"team_id"`
+	SoftwareTitleID uint   `json:"software_title_id" db:"title_id"`
+	SoftwareTitle   string `json:"software_title" db:"software_title_name"`
+	// RequestedBy is the email of the end user of the host, empty if the
+	// host has no known user.
+	RequestedBy   string                `json:"requested_by" db:"requested_by"`
+	Justification string                `json:"justification" db:"justification"`
+	Status        SoftwareRequestStatus `json:"status" db:"status"`
+
+	// ReviewedByUserID, ReviewedByName, ReviewComment and ReviewedAt are set
+	// when the request is approved or denied.
+	ReviewedByUserID *uint      `json:"reviewed_by_user_id" db:"reviewed_by_user_id"`
+	ReviewedByName   *string    `json:"reviewed_by_name" db:"reviewed_by_name"`
+	ReviewComment    string     `json:"review_comment" db:"review_comment"`
+	ReviewedAt       *time.Time `json:"reviewed_at" db:"reviewed_at"`
+
+	// InstallUUID is the ID of the installation of the software triggered by
+	// the approval of the request, and InstallStatus its status.
+	InstallUUID   *string                  `json:"install_uuid" db:"install_uuid"`
+	InstallStatus *SoftwareInstallerStatus `json:"install_status" db:"install_status"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SoftwareRequestListOptions are the options to list the software requests.
+type SoftwareRequestListOptions struct {
+	ListOptions
+
+	// TeamID filters the requests of the hosts of the team (0 for no team),
+	// all the teams if nil.
+	TeamID *uint
+	// HostID filters the requests of a host.
+	HostID *uint
+	// Status filters the requests by status.
+	Status SoftwareRequestStatus
+	// RequestedBy filters the requests made by an end user, by email.
+	RequestedBy string
+}
+
+// SoftwareCatalogItem is a software title of the team of a host that the end
+// user can request from the My device page.
+type SoftwareCatalogItem struct {
+	SoftwareTitleID uint   `json:"software_title_id" db:"title_id"`
+	Name            string `json:"name" db:"name"`
+	Source          string `json:"source" db:"source"`
+	Version         string `json:"version" db:"version"`
+	InstallerID     uint   `json:"-" db:"installer_id"`
+	// LastRequest is the most recent request of the title for the host, if
+	// any.
+	LastRequest *SoftwareRequest `json:"last_request" db:"-"`
+}
+
+// ActivityDetails returns the details of the software request activities.
+func (r *SoftwareRequest) ActivityDetails() ActivitySoftwareRequest {
+	return ActivitySoftwareRequest{
+		RequestID:       r.ID,
+		HostID:          r.HostID,
+		HostDisplayName: r.HostDisplayName,
+		SoftwareTitle:   r.SoftwareTitle,
+		RequestedBy:     r.RequestedBy,
+	}
+}
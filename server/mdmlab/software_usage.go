@@ -0,0 +1,104 @@
+package mdmlab
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SoftwareUsageDefaultUnusedForDays is the default number of days after which
+// software that was not opened is reported as unused.
+const SoftwareUsageDefaultUnusedForDays = 90
+
+// SoftwareTitleUsage is the usage of a software title by the hosts that have
+// any version of it installed. The usage is computed from the last opened
+// time of the software, which is only reported for some sources (e.g. macOS
+// apps).
+type SoftwareTitleUsage struct {
+	SoftwareTitleID  uint    `json:"software_title_id" db:"software_title_id"`
+	Name             string  `json:"name" db:"name"`
+	Source           string  `json:"source" db:"source"`
+	BundleIdentifier *string `json:"bundle_identifier,omitempty" db:"bundle_identifier"`
+	// HostsCount is the number of hosts with any version of the software.
+	HostsCount uint `json:"hosts_count" db:"hosts_count"`
+	// UsageReportedCount is the number of hosts that report the last opened
+	// time of the software.
+	UsageReportedCount uint `json:"usage_reported_count" db:"usage_reported_count"`
+	// OpenedLast30Days, OpenedLast60Days and OpenedLast90Days are the number of
+	// hosts that opened the software in the last 30, 60 and 90 days.
+	OpenedLast30Days uint `json:"opened_last_30_days" db:"opened_last_30_days"`
+	OpenedLast60Days uint `json:"opened_last_60_days" db:"opened_last_60_days"`
+	OpenedLast90Days uint `json:"opened_last_90_days" db:"opened_last_90_days"`
+	// LastOpenedAt is the most recent time the software was opened on any
+	// host.
+	LastOpenedAt *time.Time `json:"last_opened_at" db:"last_opened_at"`
+}
+
+// UnusedSoftware is a software title installed on hosts that did not open it
+// in the last UnusedForDays days.
+type UnusedSoftware struct {
+	SoftwareTitleID  uint    `json:"software_title_id" db:"software_title_id"`
+	Name             string  `json:"name" db:"name"`
+	Source           string  `json:"source" db:"source"`
+	BundleIdentifier *string `json:"bundle_identifier,omitempty" db:"bundle_identifier"`
+	// HostsCount is the number of hosts with any version of the software.
+	HostsCount uint `json:"hosts_count" db:"hosts_count"`
+	// UnusedHostsCount is the number of hosts that reported the last opened
+	// time of the software and did not open it in the last UnusedForDays
+	// days.
+	UnusedHostsCount uint `json:"unused_hosts_count" db:"unused_hosts_count"`
+	// LastOpenedAt is the most recent time the software was opened on any
+	// host.
+	LastOpenedAt *time.Time `json:"last_opened_at" db:"last_opened_at"`
+}
+
+// UnusedSoftwareListOptions are the options to list the unused software.
+type UnusedSoftwareListOptions struct {
+	ListOptions ListOptions
+	// TeamID filters the hosts of the team, 0 is no team and nil is all the
+	// teams of the user.
+	TeamID *uint
+	// UnusedForDays is the number of days after which software that was not
+	// opened is unused.
+	UnusedForDays uint
+}
+
+// SoftwareUnusedPolicy is a policy that fails on the hosts with the software
+// installed that did not open it in the last N days.
+type SoftwareUnusedPolicy struct {
+	Name        string `json:"name"`
+	Query       string `json:"query"`
+	Description string `json:"description"`
+	Resolution  string `json:"resolution"`
+	Platform    string `json:"platform"`
+}
+
+// NewSoftwareUnusedPolicy generates the policy that fails on the hosts where
+// the software title is installed and was not opened in the last days. Only
+// macOS apps with a bundle identifier are supported, as the apps table is the
+// only one that reports the last opened time. An app that was never opened is
+// not reported as unused, osquery can't tell when it was installed.
+func NewSoftwareUnusedPolicy(title *SoftwareTitle, days uint) (*SoftwareUnusedPolicy, error) {
+	if days == 0 {
+		return nil, NewInvalidArgumentError("days", "must be greater than 0")
+	}
+	if title.Source != "apps" || title.BundleIdentifier == nil || *title.BundleIdentifier == "" {
+		return nil, NewInvalidArgumentError("id", "Usage policies are only supported for macOS apps with a bundle identifier.")
+	}
+
+	bundleIdentifier := strings.ReplaceAll(*title.BundleIdentifier, "'", "''")
+	query := fmt.Sprintf(`SELECT 1 WHERE NOT EXISTS (
+  SELECT 1 FROM apps
+  WHERE bundle_identifier = '%s'
+  AND last_opened_time > 0
+  AND last_opened_time < (CAST(strftime('%%s', 'now') AS INTEGER) - %d)
+);`, bundleIdentifier, days*24*60*60)
+
+	return &SoftwareUnusedPolicy{
+		Name:        fmt.Sprintf("%s unused for %d days", title.Name, days),
+		Query:       query,
+		Description: fmt.Sprintf("Fails on hosts where %s is installed and was not opened in the last %d days.", title.Name, days),
+		Resolution:  fmt.Sprintf("Open %s or uninstall it to reclaim its license.", title.Name),
+		Platform:    "darwin",
+	}, nil
+}
@@ -0,0 +1,34 @@
+package mdmlab
+
+import (
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSoftwareUnusedPolicy(t *testing.T) {
+	_, err := NewSoftwareUnusedPolicy(&SoftwareTitle{Name: "Figma.app", Source: "apps", BundleIdentifier: ptr.String("com.figma.Desktop")}, 0)
+	require.ErrorContains(t, err, "must be greater than 0")
+	_, err = NewSoftwareUnusedPolicy(&SoftwareTitle{Name: "Figma", Source: "programs"}, 30)
+	require.ErrorContains(t, err, "only supported for macOS apps")
+	_, err = NewSoftwareUnusedPolicy(&SoftwareTitle{Name: "Figma.app", Source: "apps"}, 30)
+	require.ErrorContains(t, err, "only supported for macOS apps")
+
+	policy, err := NewSoftwareUnusedPolicy(&SoftwareTitle{Name: "Figma.app", Source: "apps", BundleIdentifier: ptr.String("com.figma.Desktop")}, 30)
+	require.NoError(t, err)
+	require.Equal(t, "Figma.app unused for 30 days", policy.Name)
+	require.Equal(t, "darwin", policy.Platform)
+	require.Equal(t, `SELECT 1 WHERE NOT EXISTS (
+  SELECT 1 FROM apps
+  WHERE bundle_identifier = 'com.figma.Desktop'
+  AND last_opened_time > 0
+  AND last_opened_time < (CAST(strftime('%s', 'now') AS INTEGER) - 2592000)
+);`, policy.Query)
+	require.NoError(t, verifyPolicyQuery(policy.Query))
+
+	// quotes are escaped
+	policy, err = NewSoftwareUnusedPolicy(&SoftwareTitle{Name: "Bob's.app", Source: "apps", BundleIdentifier: ptr.String("com.bob's")}, 90)
+	require.NoError(t, err)
+	require.Contains(t, policy.Query, `bundle_identifier = 'com.bob''s'`)
+}
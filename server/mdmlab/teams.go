@@ -231,6 +231,10 @@ func (t *TeamMDM) Copy() *TeamMDM {
 	if t.MacOSSettings.DeprecatedEnableDiskEncryption != nil {
 		clone.MacOSSettings.DeprecatedEnableDiskEncryption = ptr.Bool(*t.MacOSSettings.DeprecatedEnableDiskEncryption)
 	}
+	if t.MacOSSettings.RecoveryLock != nil {
+		rl := *t.MacOSSettings.RecoveryLock
+		clone.MacOSSettings.RecoveryLock = &rl
+	}
 	if t.WindowsSettings.CustomSettings.Set {
 		windowsSettings := make([]MDMProfileSpec, len(t.WindowsSettings.CustomSettings.Value))
 		for i, mps := range t.WindowsSettings.CustomSettings.Value {
@@ -502,6 +506,9 @@ func TeamSpecFromTeam(t *Team) (*TeamSpec, error) {
 	mdmSpec.WindowsUpdates = t.Config.MDM.WindowsUpdates
 	mdmSpec.MacOSSettings = t.Config.MDM.MacOSSettings.ToMap()
 	delete(mdmSpec.MacOSSettings, "enable_disk_encryption")
+	if t.Config.MDM.MacOSSettings.RecoveryLock == nil {
+		delete(mdmSpec.MacOSSettings, "recovery_lock")
+	}
 	mdmSpec.MacOSSetup = t.Config.MDM.MacOSSetup
 	mdmSpec.EnableDiskEncryption = optjson.SetBool(t.Config.MDM.EnableDiskEncryption)
 	mdmSpec.WindowsSettings = t.Config.MDM.WindowsSettings
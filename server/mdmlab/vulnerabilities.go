@@ -19,6 +19,11 @@ type CVE struct {
 	CVEPublished      **time.Time `json:"cve_published,omitempty" db:"cve_published"`
 	Description       **string    `json:"cve_description,omitempty" db:"description"`
 	ResolvedInVersion **string    `json:"resolved_in_version,omitempty" db:"resolved_in_version"`
+	CVSSVersion       **string    `json:"cvss_version,omitempty" db:"cvss_version"`
+	AttackVector      **string    `json:"cvss_attack_vector,omitempty" db:"attack_vector"`
+	// CVSSScores are all the CVSS scores known for the CVE. Only set when
+	// getting a single vulnerability on the premium tier.
+	CVSSScores []CVSSScore `json:"cvss_scores,omitempty" db:"-"`
 }
 
 type CVEMeta struct {
@@ -38,6 +43,30 @@ type CVEMeta struct {
 	Published *time.Time `db:"published" json:"published,omitempty"`
 	// CVE text description
 	Description string `db:"description" json:"description,omitempty"`
+	// CVSSVersion, CVSSSource, CVSSVector and AttackVector describe the score
+	// used as CVSSScore, picked from CVSSScores according to the configured
+	// CVSSScorePreference.
+	CVSSVersion  string           `db:"cvss_version" json:"cvss_version,omitempty"`
+	CVSSSource   string           `db:"cvss_source" json:"cvss_source,omitempty"`
+	CVSSVector   string           `db:"cvss_vector" json:"cvss_vector,omitempty"`
+	AttackVector CVSSAttackVector `db:"attack_vector" json:"attack_vector,omitempty"`
+	// CVSSScores are all the CVSS scores known for the CVE.
+	CVSSScores []CVSSScore `db:"-" json:"cvss_scores,omitempty"`
+}
+
+// ApplyCVSSScorePreference sets the CVSS score of the CVE to the preferred
+// score from CVSSScores. It leaves the score untouched if there are no
+// CVSSScores.
+func (m *CVEMeta) ApplyCVSSScorePreference(pref CVSSScorePreference) {
+	score := PreferredCVSSScore(m.CVSSScores, pref)
+	if score == nil {
+		return
+	}
+	m.CVSSScore = &score.BaseScore
+	m.CVSSVersion = score.Version
+	m.CVSSSource = score.Source
+	m.CVSSVector = score.VectorString
+	m.AttackVector = score.AttackVector
 }
 
 // SoftwareCPE represents an entry in the `software_cpe` table.
@@ -128,6 +157,8 @@ const (
 	MacOfficeReleaseNotesSource
 	CustomSource
 	GovalDictionarySource
+	DebianOVALSource
+	OSVSource
 )
 
 type VulnerabilityWithMetadata struct {
@@ -145,6 +176,10 @@ type VulnListOptions struct {
 	ValidSortColumns []string
 	TeamID           *uint `query:"team_id,optional"`
 	KnownExploit     bool  `query:"exploit,optional"`
+	// AttackVector filters the vulnerabilities by the attack vector of their
+	// CVSS score, e.g. "network" to list network-exploitable vulnerabilities
+	// only.
+	AttackVector CVSSAttackVector `query:"attack_vector,optional"`
 }
 
 func (opt VulnListOptions) HasValidSortColumn() bool {
@@ -0,0 +1,107 @@
+package mdmlab
+
+import (
+	"time"
+)
+
+// VulnerabilityExposure describes where a CVE affects the hosts: the vulnerable
+// software titles and operating system versions, the number of affected hosts
+// per team and the policies detecting it.
+type VulnerabilityExposure struct {
+	CVE string `json:"cve"`
+	// Exposed is true if at least one host is affected by the CVE.
+	Exposed bool `json:"exposed"`
+	// Vulnerability is nil if the CVE is known to MDMlab but doesn't affect any
+	// host.
+	Vulnerability *VulnerabilityWithMetadata `json:"vulnerability"`
+	Software      []*VulnerableSoftwareTitle `json:"software"`
+	OSVersions    []*VulnerableOS            `json:"os_versions"`
+	// Teams is the number of affected hosts per team, as of the last
+	// vulnerability processing run.
+	Teams    []*VulnerabilityTeamHostsCount `json:"teams"`
+	Policies []*VulnerabilityPolicy         `json:"policies"`
+}
+
+// VulnerableSoftwareTitle is a software title with one or more versions
+// affected by a CVE.
+type VulnerableSoftwareTitle struct {
+	// SoftwareTitleID is nil if the software has not been reconciled with a
+	// title yet.
+	SoftwareTitleID  *uint  `json:"software_title_id" db:"title_id"`
+	Name             string `json:"name" db:"name"`
+	Source           string `json:"source" db:"source"`
+	Browser          string `json:"browser" db:"browser"`
+	BundleIdentifier string `json:"bundle_identifier,omitempty" db:"bundle_identifier"`
+	// HostsCount is the sum of the hosts count of the vulnerable versions, a host
+	// with multiple vulnerable versions installed is counted once per version.
+	HostsCount int `json:"hosts_count" db:"-"`
+	// FixedVersion is the highest version in which the CVE is resolved across
+	// the vulnerable versions, nil if unknown.
+	FixedVersion *string               `json:"fixed_version" db:"-"`
+	Versions     []*VulnerableSoftware `json:"versions" db:"-"`
+	// Remediations are the installers available to update the software title.
+	Remediations []*VulnerabilityRemediation `json:"remediations" db:"-"`
+}
+
+// VulnerabilityRemediationType is the type of installer available to update a
+// vulnerable software title.
+type VulnerabilityRemediationType string
+
+const (
+	VulnerabilityRemediationSoftwarePackage     VulnerabilityRemediationType = "software_package"
+	VulnerabilityRemediationAppStoreApp         VulnerabilityRemediationType = "app_store_app"
+	VulnerabilityRemediationMDMlabMaintainedApp VulnerabilityRemediationType = "mdmlab_maintained_app"
+)
+
+// VulnerabilityRemediation is an installer (software package, App Store app
+// or MDMlab-maintained app) of a version of a vulnerable software title.
+type VulnerabilityRemediation struct {
+	Type VulnerabilityRemediationType `json:"type" db:"-"`
+	// SoftwareTitleID is the vulnerable software title updated by the
+	// installer.
+	SoftwareTitleID       uint    `json:"-" db:"title_id"`
+	SoftwareInstallerID   *uint   `json:"software_installer_id,omitempty" db:"software_installer_id"`
+	AppStoreID            *string `json:"app_store_id,omitempty" db:"adam_id"`
+	MDMlabMaintainedAppID *uint   `json:"mdmlab_maintained_app_id,omitempty" db:"mdmlab_maintained_app_id"`
+	// TeamID is the team the software package or App Store app is available
+	// to, 0 for no team. It is nil for MDMlab-maintained apps, which can be
+	// added to any team.
+	TeamID  *uint  `json:"team_id" db:"team_id"`
+	Version string `json:"version" db:"version"`
+	// FixesVulnerability is true if Version is the fixed version of the
+	// software title or later, nil if the fixed version is unknown.
+	FixesVulnerability *bool `json:"fixes_vulnerability" db:"-"`
+}
+
+// VulnerabilityTeamHostsCount is the number of hosts of a team affected by a
+// CVE.
+type VulnerabilityTeamHostsCount struct {
+	// TeamID is 0 for the hosts with no team.
+	TeamID     uint      `json:"team_id" db:"team_id"`
+	TeamName   string    `json:"team_name" db:"team_name"`
+	HostsCount uint      `json:"hosts_count" db:"host_count"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// VulnerabilityPolicy is a policy that detects the vulnerable versions of a
+// software title.
+type VulnerabilityPolicy struct {
+	PolicyID         uint   `json:"policy_id" db:"policy_id"`
+	Name             string `json:"name" db:"name"`
+	CVE              string `json:"cve" db:"cve"`
+	SoftwareTitleID  *uint  `json:"software_title_id" db:"software_title_id"`
+	TeamID           *uint  `json:"team_id" db:"team_id"`
+	PassingHostCount uint   `json:"passing_host_count" db:"passing_host_count"`
+	FailingHostCount uint   `json:"failing_host_count" db:"failing_host_count"`
+}
+
+// VulnerabilityPolicyPayload is the payload to create a policy that detects
+// the versions of a software title vulnerable to a CVE.
+type VulnerabilityPolicyPayload struct {
+	CVE string
+	// TeamID is the team of the policy, nil for a global policy and 0 for a
+	// policy of the hosts with no team.
+	TeamID          *uint
+	SoftwareTitleID uint
+	Critical        bool
+}
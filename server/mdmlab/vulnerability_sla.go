@@ -0,0 +1,213 @@
+package mdmlab
+
+import (
+	"time"
+)
+
+// VulnerabilitySLARule defines the number of days a vulnerability matching the
+// rule's criteria can remain on a host before it must be remediated. All the
+// criteria set on a rule must match, a rule without criteria matches all
+// vulnerabilities.
+type VulnerabilitySLARule struct {
+	// Name identifies the rule, e.g. "Critical".
+	Name string `json:"name"`
+	// Days is the number of days after the vulnerability was first detected on
+	// a host by which it must be remediated.
+	Days int `json:"days"`
+	// MinCVSSScore matches the vulnerabilities with a CVSS score greater than
+	// or equal to this value.
+	MinCVSSScore *float64 `json:"min_cvss_score,omitempty"`
+	// MinEPSSProbability matches the vulnerabilities with an EPSS probability
+	// greater than or equal to this value.
+	MinEPSSProbability *float64 `json:"min_epss_probability,omitempty"`
+	// CISAKnownExploit matches the vulnerabilities in (true) or not in (false)
+	// the CISA known exploited vulnerabilities catalog.
+	CISAKnownExploit *bool `json:"cisa_known_exploit,omitempty"`
+}
+
+// Copy returns a deep copy of the rule.
+func (r VulnerabilitySLARule) Copy() *VulnerabilitySLARule {
+	if r.MinCVSSScore != nil {
+		v := *r.MinCVSSScore
+		r.MinCVSSScore = &v
+	}
+	if r.MinEPSSProbability != nil {
+		v := *r.MinEPSSProbability
+		r.MinEPSSProbability = &v
+	}
+	if r.CISAKnownExploit != nil {
+		v := *r.CISAKnownExploit
+		r.CISAKnownExploit = &v
+	}
+	return &r
+}
+
+// Matches returns true if the vulnerability with the given metadata matches
+// all the criteria of the rule. Unknown metadata (nil) never matches a
+// criteria.
+func (r VulnerabilitySLARule) Matches(cvssScore, epssProbability *float64, cisaKnownExploit *bool) bool {
+	if r.MinCVSSScore != nil && (cvssScore == nil || *cvssScore < *r.MinCVSSScore) {
+		return false
+	}
+	if r.MinEPSSProbability != nil && (epssProbability == nil || *epssProbability < *r.MinEPSSProbability) {
+		return false
+	}
+	if r.CISAKnownExploit != nil {
+		known := cisaKnownExploit != nil && *cisaKnownExploit
+		if known != *r.CISAKnownExploit {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchVulnerabilitySLARule returns the strictest rule (the one with the
+// fewest days) matching the vulnerability, or nil if no rule matches.
+func MatchVulnerabilitySLARule(rules []VulnerabilitySLARule, cvssScore, epssProbability *float64, cisaKnownExploit *bool) *VulnerabilitySLARule {
+	var match *VulnerabilitySLARule
+	for i, r := range rules {
+		if r.Matches(cvssScore, epssProbability, cisaKnownExploit) && (match == nil || r.Days < match.Days) {
+			match = &rules[i]
+		}
+	}
+	return match
+}
+
+// MinVulnerabilitySLADays returns the smallest number of days of the rules, or
+// 0 if there are no rules.
+func MinVulnerabilitySLADays(rules []VulnerabilitySLARule) int {
+	var days int
+	for _, r := range rules {
+		if days == 0 || r.Days < days {
+			days = r.Days
+		}
+	}
+	return days
+}
+
+// VulnerabilitySLAOverdueDetectedBefore returns the detection time before
+// which a vulnerability may be overdue at now, that is the time of the
+// strictest rule. It returns false if there are no rules.
+func VulnerabilitySLAOverdueDetectedBefore(rules []VulnerabilitySLARule, now time.Time) (time.Time, bool) {
+	days := MinVulnerabilitySLADays(rules)
+	if days <= 0 {
+		return time.Time{}, false
+	}
+	return now.Add(-time.Duration(days) * 24 * time.Hour), true
+}
+
+// OverdueHostVulnerabilities returns the host vulnerabilities that are past
+// the due date of their SLA rule at now. Vulnerabilities matching no rule are
+// never overdue.
+func OverdueHostVulnerabilities(vulns []*HostVulnerability, rules []VulnerabilitySLARule, now time.Time) []*OverdueHostVulnerability {
+	var overdue []*OverdueHostVulnerability
+	for _, v := range vulns {
+		rule := MatchVulnerabilitySLARule(rules, v.CVSSScore, v.EPSSProbability, v.CISAKnownExploit)
+		if rule == nil {
+			continue
+		}
+		dueAt := v.FirstDetectedAt.Add(time.Duration(rule.Days) * 24 * time.Hour)
+		if now.After(dueAt) {
+			overdue = append(overdue, &OverdueHostVulnerability{HostVulnerability: *v, SLARule: rule.Name, DueAt: dueAt})
+		}
+	}
+	return overdue
+}
+
+// ValidateVulnerabilitySLARules appends an error to invalid for each invalid
+// SLA rule.
+func ValidateVulnerabilitySLARules(rules []VulnerabilitySLARule, invalid *InvalidArgumentError) {
+	names := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			invalid.Append("vulnerability_settings.sla_rules.name", "SLA rule name is required")
+		} else if names[r.Name] {
+			invalid.Appendf("vulnerability_settings.sla_rules.name", "duplicate SLA rule name %q", r.Name)
+		}
+		names[r.Name] = true
+		if r.Days <= 0 {
+			invalid.Appendf("vulnerability_settings.sla_rules.days", "SLA rule %q: days must be > 0", r.Name)
+		}
+		if r.MinCVSSScore != nil && (*r.MinCVSSScore < 0 || *r.MinCVSSScore > 10) {
+			invalid.Appendf("vulnerability_settings.sla_rules.min_cvss_score", "SLA rule %q: min_cvss_score must be between 0 and 10", r.Name)
+		}
+		if r.MinEPSSProbability != nil && (*r.MinEPSSProbability < 0 || *r.MinEPSSProbability > 1) {
+			invalid.Appendf("vulnerability_settings.sla_rules.min_epss_probability", "SLA rule %q: min_epss_probability must be between 0 and 1", r.Name)
+		}
+	}
+}
+
+// HostVulnerability tracks a CVE affecting a host, through its software or
+// its operating system, from the time it was first detected until it was
+// resolved.
+type HostVulnerability struct {
+	ID              uint   `json:"-" db:"id"`
+	HostID          uint   `json:"host_id" db:"host_id"`
+	HostDisplayName string `json:"host_display_name" db:"host_display_name"`
+	TeamID          *uint  `json:"team_id" db:"team_id"`
+	CVE             string `json:"cve" db:"cve"`
+	// FirstDetectedAt is the time the CVE was detected on the host. If the CVE
+	// is detected again after being resolved, it is the time of the new
+	// detection.
+	FirstDetectedAt time.Time `json:"first_detected_at" db:"first_detected_at"`
+	// ResolvedAt is the time the CVE was no longer detected on the host, nil
+	// while the host is still vulnerable.
+	ResolvedAt       *time.Time `json:"resolved_at" db:"resolved_at"`
+	CVSSScore        *float64   `json:"cvss_score" db:"cvss_score"`
+	EPSSProbability  *float64   `json:"epss_probability" db:"epss_probability"`
+	CISAKnownExploit *bool      `json:"cisa_known_exploit" db:"cisa_known_exploit"`
+}
+
+// OverdueHostVulnerability is a vulnerability still affecting a host after
+// its SLA due date.
+type OverdueHostVulnerability struct {
+	HostVulnerability
+	// SLARule is the name of the SLA rule that applies to the vulnerability.
+	SLARule string    `json:"sla_rule"`
+	DueAt   time.Time `json:"due_at"`
+}
+
+// HostVulnerabilityListOptions are the options to list the unresolved host
+// vulnerabilities.
+type HostVulnerabilityListOptions struct {
+	// TeamID filters the vulnerabilities of the hosts in the team, 0 means the
+	// hosts with no team and nil all hosts.
+	TeamID *uint
+	// DetectedBefore filters the vulnerabilities first detected before this
+	// time, ignored if zero.
+	DetectedBefore time.Time
+	// SLABreachNotNotified filters the vulnerabilities for which no SLA breach
+	// notification was sent yet.
+	SLABreachNotNotified bool
+}
+
+// OverdueHostVulnerabilityListOptions are the options to list the host
+// vulnerabilities past their SLA due date.
+type OverdueHostVulnerabilityListOptions struct {
+	ListOptions
+	// TeamID filters the vulnerabilities of the hosts in the team, 0 means the
+	// hosts with no team and nil all hosts.
+	TeamID *uint
+	// Rules are the SLA rules that define the due date of the
+	// vulnerabilities.
+	Rules []VulnerabilitySLARule
+	// Now is the time at which the vulnerabilities are overdue.
+	Now time.Time
+}
+
+// VulnerabilityRemediationMetrics summarizes the remediation of the
+// vulnerabilities affecting the hosts of a team (or all hosts).
+type VulnerabilityRemediationMetrics struct {
+	TeamID *uint `json:"team_id"`
+	// OpenCount is the number of vulnerabilities (host and CVE combinations)
+	// still affecting hosts.
+	OpenCount int `json:"open_count" db:"open_count"`
+	// OverdueCount is the number of open vulnerabilities past their SLA due
+	// date.
+	OverdueCount int `json:"overdue_count" db:"-"`
+	// ResolvedCount is the number of vulnerabilities resolved on hosts.
+	ResolvedCount int `json:"resolved_count" db:"resolved_count"`
+	// MeanTimeToRemediate is the average time, in seconds, between the
+	// detection and the resolution of the resolved vulnerabilities.
+	MeanTimeToRemediate float64 `json:"mean_time_to_remediate_seconds" db:"mean_time_to_remediate"`
+}
@@ -0,0 +1,98 @@
+package mdmlab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchVulnerabilitySLARule(t *testing.T) {
+	rules := []VulnerabilitySLARule{
+		{Name: "Default", Days: 90},
+		{Name: "Critical", Days: 14, MinCVSSScore: ptr.Float64(9)},
+		{Name: "Known exploited", Days: 7, CISAKnownExploit: ptr.Bool(true)},
+		{Name: "Likely exploited", Days: 30, MinEPSSProbability: ptr.Float64(0.5), MinCVSSScore: ptr.Float64(7)},
+	}
+
+	cases := []struct {
+		name  string
+		cvss  *float64
+		epss  *float64
+		kev   *bool
+		match string
+	}{
+		{"no metadata", nil, nil, nil, "Default"},
+		{"critical", ptr.Float64(9.8), nil, ptr.Bool(false), "Critical"},
+		{"critical and known exploited", ptr.Float64(9.8), nil, ptr.Bool(true), "Known exploited"},
+		{"likely exploited", ptr.Float64(7.5), ptr.Float64(0.6), nil, "Likely exploited"},
+		{"unlikely exploited", ptr.Float64(7.5), ptr.Float64(0.1), nil, "Default"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule := MatchVulnerabilitySLARule(rules, c.cvss, c.epss, c.kev)
+			require.NotNil(t, rule)
+			require.Equal(t, c.match, rule.Name)
+		})
+	}
+
+	require.Nil(t, MatchVulnerabilitySLARule(rules[1:], nil, nil, nil))
+	require.Nil(t, MatchVulnerabilitySLARule(nil, ptr.Float64(10), nil, nil))
+}
+
+func TestOverdueHostVulnerabilities(t *testing.T) {
+	now := time.Date(2025, 2, 7, 12, 0, 0, 0, time.UTC)
+	rules := []VulnerabilitySLARule{
+		{Name: "Critical", Days: 14, MinCVSSScore: ptr.Float64(9)},
+		{Name: "High", Days: 30, MinCVSSScore: ptr.Float64(7)},
+	}
+
+	before, ok := VulnerabilitySLAOverdueDetectedBefore(rules, now)
+	require.True(t, ok)
+	require.Equal(t, now.Add(-14*24*time.Hour), before)
+	_, ok = VulnerabilitySLAOverdueDetectedBefore(nil, now)
+	require.False(t, ok)
+
+	vulns := []*HostVulnerability{
+		{HostID: 1, CVE: "CVE-2024-0001", CVSSScore: ptr.Float64(9.8), FirstDetectedAt: now.Add(-15 * 24 * time.Hour)},
+		{HostID: 1, CVE: "CVE-2024-0002", CVSSScore: ptr.Float64(7.5), FirstDetectedAt: now.Add(-15 * 24 * time.Hour)},
+		{HostID: 2, CVE: "CVE-2024-0003", CVSSScore: ptr.Float64(4), FirstDetectedAt: now.Add(-365 * 24 * time.Hour)},
+		{HostID: 2, CVE: "CVE-2024-0004", CVSSScore: ptr.Float64(8), FirstDetectedAt: now.Add(-31 * 24 * time.Hour)},
+	}
+	overdue := OverdueHostVulnerabilities(vulns, rules, now)
+	require.Len(t, overdue, 2)
+	require.Equal(t, "CVE-2024-0001", overdue[0].CVE)
+	require.Equal(t, "Critical", overdue[0].SLARule)
+	require.Equal(t, now.Add(-24*time.Hour), overdue[0].DueAt)
+	require.Equal(t, "CVE-2024-0004", overdue[1].CVE)
+	require.Equal(t, "High", overdue[1].SLARule)
+}
+
+func TestValidateVulnerabilitySLARules(t *testing.T) {
+	invalid := &InvalidArgumentError{}
+	ValidateVulnerabilitySLARules([]VulnerabilitySLARule{
+		{Name: "Critical", Days: 14, MinCVSSScore: ptr.Float64(9)},
+		{Name: "Likely exploited", Days: 30, MinEPSSProbability: ptr.Float64(0.5)},
+	}, invalid)
+	require.False(t, invalid.HasErrors())
+
+	cases := []struct {
+		rule VulnerabilitySLARule
+		err  string
+	}{
+		{VulnerabilitySLARule{Days: 14}, "SLA rule name is required"},
+		{VulnerabilitySLARule{Name: "Critical"}, `SLA rule "Critical": days must be > 0`},
+		{VulnerabilitySLARule{Name: "Critical", Days: 14, MinCVSSScore: ptr.Float64(11)}, "min_cvss_score must be between 0 and 10"},
+		{VulnerabilitySLARule{Name: "Critical", Days: 14, MinEPSSProbability: ptr.Float64(2)}, "min_epss_probability must be between 0 and 1"},
+	}
+	for _, c := range cases {
+		invalid := &InvalidArgumentError{}
+		ValidateVulnerabilitySLARules([]VulnerabilitySLARule{c.rule}, invalid)
+		require.ErrorContains(t, invalid, c.err)
+	}
+
+	invalid = &InvalidArgumentError{}
+	ValidateVulnerabilitySLARules([]VulnerabilitySLARule{{Name: "a", Days: 1}, {Name: "a", Days: 2}}, invalid)
+	require.ErrorContains(t, invalid, `duplicate SLA rule name "a"`)
+}
@@ -0,0 +1,181 @@
+package mdmlab
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// VEXJustification is the reason why a vulnerability does not affect a product, using the
+// justification values defined by OpenVEX.
+type VEXJustification string
+
+const (
+	VEXJustificationComponentNotPresent                         VEXJustification = "component_not_present"
+	VEXJustificationVulnerableCodeNotPresent                    VEXJustification = "vulnerable_code_not_present"
+	VEXJustificationVulnerableCodeNotInExecutePath              VEXJustification = "vulnerable_code_not_in_execute_path"
+	VEXJustificationVulnerableCodeCannotBeControlledByAdversary VEXJustification = "vulnerable_code_cannot_be_controlled_by_adversary"
+	VEXJustificationInlineMitigationsAlreadyExist               VEXJustification = "inline_mitigations_already_exist"
+)
+
+// IsValid returns true if the justification is one of the supported values.
+func (j VEXJustification) IsValid() bool {
+	switch j {
+	case VEXJustificationComponentNotPresent,
+		VEXJustificationVulnerableCodeNotPresent,
+		VEXJustificationVulnerableCodeNotInExecutePath,
+		VEXJustificationVulnerableCodeCannotBeControlledByAdversary,
+		VEXJustificationInlineMitigationsAlreadyExist:
+		return true
+	}
+	return false
+}
+
+var suppressionCVERegex = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// VulnerabilitySuppression is a "not affected" statement for a CVE. Suppressed CVEs are excluded
+// from the vulnerability host counts and from the vulnerability automations, but they are still
+// reported in the software inventory.
+//
+// A suppression applies to a single scope: globally (no scope field set), to the hosts of a team
+// (TeamID, 0 meaning "No team"), to a software title (SoftwareTitleID) or to a host (HostID).
+type VulnerabilitySuppression struct {
+	ID              uint             `json:"id" db:"id"`
+	CVE             string           `json:"cve" db:"cve"`
+	TeamID          *uint            `json:"team_id" db:"team_id"`
+	SoftwareTitleID *uint            `json:"software_title_id" db:"software_title_id"`
+	HostID          *uint            `json:"host_id" db:"host_id"`
+	Justification   VEXJustification `json:"justification" db:"justification"`
+	// Comment is a free-form explanation of why the vulnerability does not apply, exported as
+	// the VEX impact statement.
+	Comment string `json:"comment" db:"comment"`
+	// ExpiresAt is the time after which the suppression no longer applies, nil means it never
+	// expires.
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	AuthorID  *uint      `json:"author_id" db:"author_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+func (s VulnerabilitySuppression) AuthzType() string {
+	return "vulnerability_suppression"
+}
+
+// Scope keys of the vulnerability suppressions, also used as the product identifiers of the
+// exported VEX documents.
+const (
+	VulnerabilitySuppressionScopeGlobal        = "global"
+	VulnerabilitySuppressionScopeTeam          = "team"
+	VulnerabilitySuppressionScopeSoftwareTitle = "software_title"
+	VulnerabilitySuppressionScopeHost          = "host"
+)
+
+// ScopeKey returns the string identifying the scope of the suppression, e.g. "global" or
+// "host/12".
+func (s *VulnerabilitySuppression) ScopeKey() string {
+	switch {
+	case s.TeamID != nil:
+		return fmt.Sprintf("%s/%d", VulnerabilitySuppressionScopeTeam, *s.TeamID)
+	case s.SoftwareTitleID != nil:
+		return fmt.Sprintf("%s/%d", VulnerabilitySuppressionScopeSoftwareTitle, *s.SoftwareTitleID)
+	case s.HostID != nil:
+		return fmt.Sprintf("%s/%d", VulnerabilitySuppressionScopeHost, *s.HostID)
+	default:
+		return VulnerabilitySuppressionScopeGlobal
+	}
+}
+
+// SetScopeFromKey sets the scope fields of the suppression from a key returned by ScopeKey.
+func (s *VulnerabilitySuppression) SetScopeFromKey(key string) error {
+	s.TeamID, s.SoftwareTitleID, s.HostID = nil, nil, nil
+	if key == VulnerabilitySuppressionScopeGlobal {
+		return nil
+	}
+
+	kind, rawID, ok := strings.Cut(key, "/")
+	if !ok {
+		return fmt.Errorf("invalid suppression scope %q", key)
+	}
+	var id uint
+	if _, err := fmt.Sscanf(rawID, "%d", &id); err != nil || fmt.Sprint(id) != rawID {
+		return fmt.Errorf("invalid suppression scope %q", key)
+	}
+	switch kind {
+	case VulnerabilitySuppressionScopeTeam:
+		s.TeamID = &id
+	case VulnerabilitySuppressionScopeSoftwareTitle:
+		s.SoftwareTitleID = &id
+	case VulnerabilitySuppressionScopeHost:
+		s.HostID = &id
+	default:
+		return fmt.Errorf("invalid suppression scope %q", key)
+	}
+	return nil
+}
+
+// IsExpired returns true if the suppression no longer applies at the given time.
+func (s *VulnerabilitySuppression) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(now)
+}
+
+// VulnerabilitySuppressionPayload is the payload used for creating a vulnerability suppression.
+type VulnerabilitySuppressionPayload struct {
+	CVE             string           `json:"cve"`
+	TeamID          *uint            `json:"team_id"`
+	SoftwareTitleID *uint            `json:"software_title_id"`
+	HostID          *uint            `json:"host_id"`
+	Justification   VEXJustification `json:"justification"`
+	Comment         string           `json:"comment"`
+	ExpiresAt       *time.Time       `json:"expires_at"`
+}
+
+// ToSuppression returns the suppression described by the payload, with the CVE normalized.
+func (p *VulnerabilitySuppressionPayload) ToSuppression() *VulnerabilitySuppression {
+	return &VulnerabilitySuppression{
+		CVE:             strings.ToUpper(strings.TrimSpace(p.CVE)),
+		TeamID:          p.TeamID,
+		SoftwareTitleID: p.SoftwareTitleID,
+		HostID:          p.HostID,
+		Justification:   p.Justification,
+		Comment:         p.Comment,
+		ExpiresAt:       p.ExpiresAt,
+	}
+}
+
+// Validate returns an InvalidArgumentError if the suppression is not valid at the given time.
+func (s *VulnerabilitySuppression) Validate(now time.Time) error {
+	invalid := &InvalidArgumentError{}
+	if !suppressionCVERegex.MatchString(s.CVE) {
+		invalid.Appendf("cve", "%q is not a valid CVE identifier", s.CVE)
+	}
+	var scopes int
+	for _, id := range []*uint{s.TeamID, s.SoftwareTitleID, s.HostID} {
+		if id != nil {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		invalid.Append("scope", "only one of team_id, software_title_id and host_id can be specified")
+	}
+	if !s.Justification.IsValid() {
+		invalid.Appendf("justification", "%q is not a valid justification", s.Justification)
+	}
+	if s.IsExpired(now) {
+		invalid.Append("expires_at", "must be in the future")
+	}
+	if invalid.HasErrors() {
+		return invalid
+	}
+	return nil
+}
+
+// ListVulnerabilitySuppressionsOptions are the filters available when listing the vulnerability
+// suppressions.
+type ListVulnerabilitySuppressionsOptions struct {
+	CVE string `query:"cve,optional"`
+	// TeamID filters the suppressions that apply to the hosts of the team, that is global,
+	// software title, team and host suppressions for hosts in the team.
+	TeamID         *uint `query:"team_id,optional"`
+	IncludeExpired bool  `query:"include_expired,optional"`
+}
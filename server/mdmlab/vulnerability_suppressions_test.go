@@ -0,0 +1,68 @@
+package mdmlab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilitySuppressionScopeKey(t *testing.T) {
+	cases := []struct {
+		suppression VulnerabilitySuppression
+		key         string
+	}{
+		{VulnerabilitySuppression{}, "global"},
+		{VulnerabilitySuppression{TeamID: ptr.Uint(0)}, "team/0"},
+		{VulnerabilitySuppression{TeamID: ptr.Uint(3)}, "team/3"},
+		{VulnerabilitySuppression{SoftwareTitleID: ptr.Uint(12)}, "software_title/12"},
+		{VulnerabilitySuppression{HostID: ptr.Uint(7)}, "host/7"},
+	}
+	for _, c := range cases {
+		t.Run(c.key, func(t *testing.T) {
+			require.Equal(t, c.key, c.suppression.ScopeKey())
+
+			var s VulnerabilitySuppression
+			require.NoError(t, s.SetScopeFromKey(c.key))
+			require.Equal(t, c.suppression.TeamID, s.TeamID)
+			require.Equal(t, c.suppression.SoftwareTitleID, s.SoftwareTitleID)
+			require.Equal(t, c.suppression.HostID, s.HostID)
+		})
+	}
+
+	for _, key := range []string{"", "team", "team/", "team/abc", "team/-1", "team/01", "label/1"} {
+		var s VulnerabilitySuppression
+		require.Error(t, s.SetScopeFromKey(key), key)
+	}
+}
+
+func TestVulnerabilitySuppressionValidate(t *testing.T) {
+	now := time.Now()
+
+	valid := VulnerabilitySuppression{CVE: "CVE-2024-12345", Justification: VEXJustificationComponentNotPresent}
+	require.NoError(t, valid.Validate(now))
+
+	valid.ExpiresAt = ptr.Time(now.Add(time.Hour))
+	require.NoError(t, valid.Validate(now))
+
+	cases := []struct {
+		desc   string
+		modify func(s *VulnerabilitySuppression)
+		errMsg string
+	}{
+		{"invalid cve", func(s *VulnerabilitySuppression) { s.CVE = "cve-2024" }, "not a valid CVE identifier"},
+		{"multiple scopes", func(s *VulnerabilitySuppression) {
+			s.TeamID, s.HostID = ptr.Uint(1), ptr.Uint(1)
+		}, "only one of team_id, software_title_id and host_id"},
+		{"invalid justification", func(s *VulnerabilitySuppression) { s.Justification = "because" }, "not a valid justification"},
+		{"expired", func(s *VulnerabilitySuppression) { s.ExpiresAt = ptr.Time(now.Add(-time.Hour)) }, "must be in the future"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			s := VulnerabilitySuppression{CVE: "CVE-2024-12345", Justification: VEXJustificationComponentNotPresent}
+			c.modify(&s)
+			require.ErrorContains(t, s.Validate(now), c.errMsg)
+		})
+	}
+}
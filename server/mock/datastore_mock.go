@@ -265,6 +265,20 @@ type RemoveHostMDMCommandFunc func(ctx context.Context, command mdmlab.HostMDMCo
 
 type CleanupHostMDMCommandsFunc func(ctx context.Context) error
 
+type ListHostsToRefetchCertificatesFunc func(ctx context.Context, interval time.Duration, limit int) ([]*mdmlab.HostCertificateRefetchTarget, error)
+
+type ReplaceHostCertificatesFunc func(ctx context.Context, hostID uint, source mdmlab.HostCertificateSource, certs []*mdmlab.HostCertificate) error
+
+type SetHostCertificatesManagedFunc func(ctx context.Context, hostID uint, source mdmlab.HostCertificateSource, sha1Sums []string) error
+
+type ListHostCertificatesFunc func(ctx context.Context, hostID uint, opt mdmlab.ListOptions) ([]*mdmlab.HostCertificate, *mdmlab.PaginationMetadata, error)
+
+type SearchHostCertificatesFunc func(ctx context.Context, filter mdmlab.TeamFilter, opt mdmlab.HostCertificateListOptions) ([]*mdmlab.HostCertificateWithHost, *mdmlab.PaginationMetadata, error)
+
+type ListExpiringHostCertificatesToNotifyFunc func(ctx context.Context, within time.Duration, limit int) ([]*mdmlab.HostCertificateWithHost, error)
+
+type SetHostCertificatesExpirationNotifiedFunc func(ctx context.Context, ids []uint) error
+
 type CleanupHostMDMAppleProfilesFunc func(ctx context.Context) error
 
 type IsHostConnectedToMDMlabMDMFunc func(ctx context.Context, host *mdmlab.Host) (bool, error)
@@ -457,6 +471,84 @@ type ListHostSoftwareFunc func(ctx context.Context, host *mdmlab.Host, opts mdml
 
 type IsSoftwareInstallerLabelScopedFunc func(ctx context.Context, installerID uint, hostID uint) (bool, error)
 
+type IsSoftwareInstallerRolloutScopedFunc func(ctx context.Context, installerID uint, hostID uint) (bool, error)
+
+type SetSoftwareInstallerRolloutFunc func(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) (*mdmlab.SoftwareInstallerRollout, error)
+
+type GetSoftwareInstallerRolloutFunc func(ctx context.Context, installerID uint) (*mdmlab.SoftwareInstallerRollout, error)
+
+type ListActiveSoftwareInstallerRolloutsFunc func(ctx context.Context) ([]*mdmlab.SoftwareInstallerRollout, error)
+
+type UpdateSoftwareInstallerRolloutFunc func(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) error
+
+type DeleteSoftwareInstallerRolloutFunc func(ctx context.Context, installerID uint) error
+
+type GetSoftwareInstallerRolloutStatsFunc func(ctx context.Context, installerID uint, since time.Time) (*mdmlab.SoftwareInstallerRolloutStats, error)
+
+type ListSoftwareInstallerRolloutHostsToInstallFunc func(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) ([]*mdmlab.SoftwareInstallerRolloutHost, error)
+
+type ListSoftwareInstallerVersionsFunc func(ctx context.Context, installerID uint) ([]*mdmlab.SoftwareInstallerVersion, error)
+
+type GetSoftwareInstallerVersionFunc func(ctx context.Context, installerID uint, versionID uint) (*mdmlab.SoftwareInstallerVersion, error)
+
+type PinSoftwareInstallerVersionFunc func(ctx context.Context, installerID uint, versionID *uint) error
+
+type RollbackSoftwareInstallerVersionFunc func(ctx context.Context, installerID uint, versionID uint) error
+
+type SetSoftwareInstallerVersionLabelsFunc func(ctx context.Context, installerID uint, versionID uint, labelIDs []uint) error
+
+type DeleteSoftwareInstallerVersionFunc func(ctx context.Context, installerID uint, versionID uint) error
+
+type GetPendingSoftwareInstallerVersionFunc func(ctx context.Context, hostID uint, installerID uint) (*mdmlab.SoftwareInstallerVersion, error)
+
+type NewSoftwareBlocklistRuleFunc func(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule, labelIDs []uint) (*mdmlab.SoftwareBlocklistRule, error)
+
+type UpdateSoftwareBlocklistRuleFunc func(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule, labelIDs []uint) (*mdmlab.SoftwareBlocklistRule, error)
+
+type GetSoftwareBlocklistRuleFunc func(ctx context.Context, id uint) (*mdmlab.SoftwareBlocklistRule, error)
+
+type ListSoftwareBlocklistRulesFunc func(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareBlocklistRule, error)
+
+type ListAllSoftwareBlocklistRulesFunc func(ctx context.Context) ([]*mdmlab.SoftwareBlocklistRule, error)
+
+type DeleteSoftwareBlocklistRuleFunc func(ctx context.Context, id uint) error
+
+type ListSoftwareBlocklistRuleHostsFunc func(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) ([]*mdmlab.SoftwareBlocklistHost, error)
+
+type GetSoftwareBlocklistRuleHostCountsFunc func(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) (*mdmlab.SoftwareBlocklistHostCounts, error)
+
+type NewSoftwareBlocklistRemovalFunc func(ctx context.Context, ruleID uint, hostID uint, executionID string) error
+
+type NewSoftwareRequestFunc func(ctx context.Context, request *mdmlab.SoftwareRequest) (*mdmlab.SoftwareRequest, error)
+
+type GetSoftwareRequestFunc func(ctx context.Context, id uint) (*mdmlab.SoftwareRequest, error)
+
+type ListSoftwareRequestsFunc func(ctx context.Context, opts mdmlab.SoftwareRequestListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error)
+
+type ReviewSoftwareRequestFunc func(ctx context.Context, request *mdmlab.SoftwareRequest) error
+
+type ApproveSoftwareRequestFunc func(ctx context.Context, request *mdmlab.SoftwareRequest, installerID uint) error
+
+type ListSoftwareRequestCatalogFunc func(ctx context.Context, host *mdmlab.Host) ([]*mdmlab.SoftwareCatalogItem, error)
+
+type NewSoftwareLicenseFunc func(ctx context.Context, license *mdmlab.SoftwareLicense) (*mdmlab.SoftwareLicense, error)
+
+type UpdateSoftwareLicenseFunc func(ctx context.Context, license *mdmlab.SoftwareLicense) (*mdmlab.SoftwareLicense, error)
+
+type GetSoftwareLicenseFunc func(ctx context.Context, id uint) (*mdmlab.SoftwareLicense, error)
+
+type ListSoftwareLicensesFunc func(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error)
+
+type ListAllSoftwareLicensesFunc func(ctx context.Context) ([]*mdmlab.SoftwareLicense, error)
+
+type DeleteSoftwareLicenseFunc func(ctx context.Context, id uint) error
+
+type ListSoftwareLicenseHostsFunc func(ctx context.Context, license *mdmlab.SoftwareLicense) ([]*mdmlab.SoftwareLicenseHost, error)
+
+type SoftwareTitleUsageFunc func(ctx context.Context, titleID uint, teamID *uint, tmFilter mdmlab.TeamFilter) (*mdmlab.SoftwareTitleUsage, error)
+
+type ListUnusedSoftwareFunc func(ctx context.Context, opts mdmlab.UnusedSoftwareListOptions, tmFilter mdmlab.TeamFilter) ([]*mdmlab.UnusedSoftware, *mdmlab.PaginationMetadata, error)
+
 type SetHostSoftwareInstallResultFunc func(ctx context.Context, result *mdmlab.HostSoftwareInstallResultPayload) error
 
 type UploadedSoftwareExistsFunc func(ctx context.Context, bundleIdentifier string, teamID *uint) (bool, error)
@@ -779,6 +871,10 @@ type GetMDMAppleCommandResultsFunc func(ctx context.Context, commandUUID string)
 
 type ListMDMAppleCommandsFunc func(ctx context.Context, tmFilter mdmlab.TeamFilter, listOpts *mdmlab.MDMCommandListOptions) ([]*mdmlab.MDMAppleCommand, error)
 
+type ListMDMAppleCommandQueueFunc func(ctx context.Context, tmFilter mdmlab.TeamFilter, listOpts *mdmlab.MDMAppleCommandQueueListOptions) ([]*mdmlab.MDMAppleCommandQueueItem, error)
+
+type ListMDMAppleHostsWithPendingCommandsFunc func(ctx context.Context, tmFilter mdmlab.TeamFilter, hostUUIDs []string, commandUUID string, lastSeenBefore *time.Time) ([]*mdmlab.Host, error)
+
 type NewMDMAppleInstallerFunc func(ctx context.Context, name string, size int64, manifest string, installer []byte, urlToken string) (*mdmlab.MDMAppleInstaller, error)
 
 type MDMAppleInstallerFunc func(ctx context.Context, token string) (*mdmlab.MDMAppleInstaller, error)
@@ -1083,6 +1179,26 @@ type WipeHostViaWindowsMDMFunc func(ctx context.Context, host *mdmlab.Host, cmd
 
 type UpdateHostLockWipeStatusFromAppleMDMResultFunc func(ctx context.Context, hostUUID string, cmdUUID string, requestType string, succeeded bool) error
 
+type SetHostMDMAppleDeviceLocationFunc func(ctx context.Context, loc *mdmlab.HostMDMAppleDeviceLocation) error
+
+type GetHostMDMAppleDeviceLocationFunc func(ctx context.Context, hostUUID string) (*mdmlab.HostMDMAppleDeviceLocation, error)
+
+type ListHostsPendingRecoveryLockRotationFunc func(ctx context.Context, teamID *uint, limit int) ([]*mdmlab.HostRecoveryLockTarget, error)
+
+type SetHostRecoveryLockPendingFunc func(ctx context.Context, hostUUID string, passwordType string, password string, commandUUID string, rotateAt *time.Time) error
+
+type SetHostRecoveryLockVerifyingFunc func(ctx context.Context, hostUUID string, verifyCommandUUID string) error
+
+type SetHostRecoveryLockVerifiedFunc func(ctx context.Context, hostUUID string) error
+
+type SetHostRecoveryLockFailedFunc func(ctx context.Context, hostUUID string, detail string, retryAt time.Time) error
+
+type GetHostRecoveryLockFunc func(ctx context.Context, hostUUID string) (*mdmlab.HostRecoveryLock, error)
+
+type SetHostRecoveryLockRevealedFunc func(ctx context.Context, hostUUID string, rotateAt time.Time) error
+
+type RedactMDMAppleCommandPayloadFunc func(ctx context.Context, commandUUID string) error
+
 type GetIncludedHostIDMapForSoftwareInstallerFunc func(ctx context.Context, installerID uint) (map[uint]struct{}, error)
 
 type GetExcludedHostIDMapForSoftwareInstallerFunc func(ctx context.Context, installerID uint) (map[uint]struct{}, error)
@@ -1207,6 +1323,46 @@ type ExpandEmbeddedSecretsFunc func(ctx context.Context, document string) (strin
 
 type ExpandEmbeddedSecretsAndUpdatedAtFunc func(ctx context.Context, document string) (string, *time.Time, error)
 
+type NewCustomCVEMatchingRuleFunc func(ctx context.Context, rule *mdmlab.CustomCVEMatchingRule) (*mdmlab.CustomCVEMatchingRule, error)
+
+type CustomCVEMatchingRuleFunc func(ctx context.Context, id uint) (*mdmlab.CustomCVEMatchingRule, error)
+
+type ListCustomCVEMatchingRulesFunc func(ctx context.Context) ([]*mdmlab.CustomCVEMatchingRule, error)
+
+type DeleteCustomCVEMatchingRuleFunc func(ctx context.Context, id uint) error
+
+type BatchSetCustomCVEMatchingRulesFunc func(ctx context.Context, rules []*mdmlab.CustomCVEMatchingRule) error
+
+type NewVulnerabilitySuppressionFunc func(ctx context.Context, suppression *mdmlab.VulnerabilitySuppression) (*mdmlab.VulnerabilitySuppression, error)
+
+type VulnerabilitySuppressionFunc func(ctx context.Context, id uint) (*mdmlab.VulnerabilitySuppression, error)
+
+type ListVulnerabilitySuppressionsFunc func(ctx context.Context, opts mdmlab.ListVulnerabilitySuppressionsOptions) ([]*mdmlab.VulnerabilitySuppression, error)
+
+type DeleteVulnerabilitySuppressionFunc func(ctx context.Context, id uint) error
+
+type UpsertVulnerabilitySuppressionsFunc func(ctx context.Context, suppressions []*mdmlab.VulnerabilitySuppression) error
+
+type FilterSuppressedSoftwareVulnerabilitiesFunc func(ctx context.Context, vulns []mdmlab.SoftwareVulnerability) ([]mdmlab.SoftwareVulnerability, error)
+
+type SyncHostVulnerabilitiesFunc func(ctx context.Context, now time.Time) error
+
+type ListOpenHostVulnerabilitiesFunc func(ctx context.Context, opts mdmlab.HostVulnerabilityListOptions) ([]*mdmlab.HostVulnerability, error)
+
+type SetHostVulnerabilitiesSLABreachNotifiedFunc func(ctx context.Context, ids []uint, notifiedAt time.Time) error
+
+type ListOverdueHostVulnerabilitiesFunc func(ctx context.Context, opts mdmlab.OverdueHostVulnerabilityListOptions) ([]*mdmlab.OverdueHostVulnerability, *mdmlab.PaginationMetadata, error)
+
+type VulnerabilityRemediationMetricsFunc func(ctx context.Context, teamID *uint, rules []mdmlab.VulnerabilitySLARule, now time.Time) (*mdmlab.VulnerabilityRemediationMetrics, error)
+
+type ListVulnerableSoftwareTitlesByCVEFunc func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerableSoftwareTitle, error)
+
+type VulnerabilityHostCountsByTeamFunc func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityTeamHostsCount, error)
+
+type ListVulnerabilityPoliciesFunc func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityPolicy, error)
+
+type NewVulnerabilityPolicyFunc func(ctx context.Context, teamID *uint, authorID *uint, args mdmlab.PolicyPayload, cve string, softwareTitleID *uint) (*mdmlab.Policy, error)
+
 type DataStore struct {
 	HealthCheckFunc        HealthCheckFunc
 	HealthCheckFuncInvoked bool
@@ -1574,6 +1730,27 @@ type DataStore struct {
 	CleanupHostMDMCommandsFunc        CleanupHostMDMCommandsFunc
 	CleanupHostMDMCommandsFuncInvoked bool
 
+	ListHostsToRefetchCertificatesFunc        ListHostsToRefetchCertificatesFunc
+	ListHostsToRefetchCertificatesFuncInvoked bool
+
+	ReplaceHostCertificatesFunc        ReplaceHostCertificatesFunc
+	ReplaceHostCertificatesFuncInvoked bool
+
+	SetHostCertificatesManagedFunc        SetHostCertificatesManagedFunc
+	SetHostCertificatesManagedFuncInvoked bool
+
+	ListHostCertificatesFunc        ListHostCertificatesFunc
+	ListHostCertificatesFuncInvoked bool
+
+	SearchHostCertificatesFunc        SearchHostCertificatesFunc
+	SearchHostCertificatesFuncInvoked bool
+
+	ListExpiringHostCertificatesToNotifyFunc        ListExpiringHostCertificatesToNotifyFunc
+	ListExpiringHostCertificatesToNotifyFuncInvoked bool
+
+	SetHostCertificatesExpirationNotifiedFunc        SetHostCertificatesExpirationNotifiedFunc
+	SetHostCertificatesExpirationNotifiedFuncInvoked bool
+
 	CleanupHostMDMAppleProfilesFunc        CleanupHostMDMAppleProfilesFunc
 	CleanupHostMDMAppleProfilesFuncInvoked bool
 
@@ -1862,6 +2039,123 @@ type DataStore struct {
 	IsSoftwareInstallerLabelScopedFunc        IsSoftwareInstallerLabelScopedFunc
 	IsSoftwareInstallerLabelScopedFuncInvoked bool
 
+	IsSoftwareInstallerRolloutScopedFunc        IsSoftwareInstallerRolloutScopedFunc
+	IsSoftwareInstallerRolloutScopedFuncInvoked bool
+
+	SetSoftwareInstallerRolloutFunc        SetSoftwareInstallerRolloutFunc
+	SetSoftwareInstallerRolloutFuncInvoked bool
+
+	GetSoftwareInstallerRolloutFunc        GetSoftwareInstallerRolloutFunc
+	GetSoftwareInstallerRolloutFuncInvoked bool
+
+	ListActiveSoftwareInstallerRolloutsFunc        ListActiveSoftwareInstallerRolloutsFunc
+	ListActiveSoftwareInstallerRolloutsFuncInvoked bool
+
+	UpdateSoftwareInstallerRolloutFunc        UpdateSoftwareInstallerRolloutFunc
+	UpdateSoftwareInstallerRolloutFuncInvoked bool
+
+	DeleteSoftwareInstallerRolloutFunc        DeleteSoftwareInstallerRolloutFunc
+	DeleteSoftwareInstallerRolloutFuncInvoked bool
+
+	GetSoftwareInstallerRolloutStatsFunc        GetSoftwareInstallerRolloutStatsFunc
+	GetSoftwareInstallerRolloutStatsFuncInvoked bool
+
+	ListSoftwareInstallerRolloutHostsToInstallFunc        ListSoftwareInstallerRolloutHostsToInstallFunc
+	ListSoftwareInstallerRolloutHostsToInstallFuncInvoked bool
+
+	ListSoftwareInstallerVersionsFunc        ListSoftwareInstallerVersionsFunc
+	ListSoftwareInstallerVersionsFuncInvoked bool
+
+	GetSoftwareInstallerVersionFunc        GetSoftwareInstallerVersionFunc
+	GetSoftwareInstallerVersionFuncInvoked bool
+
+	PinSoftwareInstallerVersionFunc        PinSoftwareInstallerVersionFunc
+	PinSoftwareInstallerVersionFuncInvoked bool
+
+	RollbackSoftwareInstallerVersionFunc        RollbackSoftwareInstallerVersionFunc
+	RollbackSoftwareInstallerVersionFuncInvoked bool
+
+	SetSoftwareInstallerVersionLabelsFunc        SetSoftwareInstallerVersionLabelsFunc
+	SetSoftwareInstallerVersionLabelsFuncInvoked bool
+
+	DeleteSoftwareInstallerVersionFunc        DeleteSoftwareInstallerVersionFunc
+	DeleteSoftwareInstallerVersionFuncInvoked bool
+
+	GetPendingSoftwareInstallerVersionFunc        GetPendingSoftwareInstallerVersionFunc
+	GetPendingSoftwareInstallerVersionFuncInvoked bool
+
+	NewSoftwareBlocklistRuleFunc        NewSoftwareBlocklistRuleFunc
+	NewSoftwareBlocklistRuleFuncInvoked bool
+
+	UpdateSoftwareBlocklistRuleFunc        UpdateSoftwareBlocklistRuleFunc
+	UpdateSoftwareBlocklistRuleFuncInvoked bool
+
+	GetSoftwareBlocklistRuleFunc        GetSoftwareBlocklistRuleFunc
+	GetSoftwareBlocklistRuleFuncInvoked bool
+
+	ListSoftwareBlocklistRulesFunc        ListSoftwareBlocklistRulesFunc
+	ListSoftwareBlocklistRulesFuncInvoked bool
+
+	ListAllSoftwareBlocklistRulesFunc        ListAllSoftwareBlocklistRulesFunc
+	ListAllSoftwareBlocklistRulesFuncInvoked bool
+
+	DeleteSoftwareBlocklistRuleFunc        DeleteSoftwareBlocklistRuleFunc
+	DeleteSoftwareBlocklistRuleFuncInvoked bool
+
+	ListSoftwareBlocklistRuleHostsFunc        ListSoftwareBlocklistRuleHostsFunc
+	ListSoftwareBlocklistRuleHostsFuncInvoked bool
+
+	GetSoftwareBlocklistRuleHostCountsFunc        GetSoftwareBlocklistRuleHostCountsFunc
+	GetSoftwareBlocklistRuleHostCountsFuncInvoked bool
+
+	NewSoftwareBlocklistRemovalFunc        NewSoftwareBlocklistRemovalFunc
+	NewSoftwareBlocklistRemovalFuncInvoked bool
+
+	NewSoftwareRequestFunc        NewSoftwareRequestFunc
+	NewSoftwareRequestFuncInvoked bool
+
+	GetSoftwareRequestFunc        GetSoftwareRequestFunc
+	GetSoftwareRequestFuncInvoked bool
+
+	ListSoftwareRequestsFunc        ListSoftwareRequestsFunc
+	ListSoftwareRequestsFuncInvoked bool
+
+	ReviewSoftwareRequestFunc        ReviewSoftwareRequestFunc
+	ReviewSoftwareRequestFuncInvoked bool
+
+	ApproveSoftwareRequestFunc        ApproveSoftwareRequestFunc
+	ApproveSoftwareRequestFuncInvoked bool
+
+	ListSoftwareRequestCatalogFunc        ListSoftwareRequestCatalogFunc
+	ListSoftwareRequestCatalogFuncInvoked bool
+
+	NewSoftwareLicenseFunc        NewSoftwareLicenseFunc
+	NewSoftwareLicenseFuncInvoked bool
+
+	UpdateSoftwareLicenseFunc        UpdateSoftwareLicenseFunc
+	UpdateSoftwareLicenseFuncInvoked bool
+
+	GetSoftwareLicenseFunc        GetSoftwareLicenseFunc
+	GetSoftwareLicenseFuncInvoked bool
+
+	ListSoftwareLicensesFunc        ListSoftwareLicensesFunc
+	ListSoftwareLicensesFuncInvoked bool
+
+	ListAllSoftwareLicensesFunc        ListAllSoftwareLicensesFunc
+	ListAllSoftwareLicensesFuncInvoked bool
+
+	DeleteSoftwareLicenseFunc        DeleteSoftwareLicenseFunc
+	DeleteSoftwareLicenseFuncInvoked bool
+
+	ListSoftwareLicenseHostsFunc        ListSoftwareLicenseHostsFunc
+	ListSoftwareLicenseHostsFuncInvoked bool
+
+	SoftwareTitleUsageFunc        SoftwareTitleUsageFunc
+	SoftwareTitleUsageFuncInvoked bool
+
+	ListUnusedSoftwareFunc        ListUnusedSoftwareFunc
+	ListUnusedSoftwareFuncInvoked bool
+
 	SetHostSoftwareInstallResultFunc        SetHostSoftwareInstallResultFunc
 	SetHostSoftwareInstallResultFuncInvoked bool
 
@@ -2345,6 +2639,12 @@ type DataStore struct {
 	ListMDMAppleCommandsFunc        ListMDMAppleCommandsFunc
 	ListMDMAppleCommandsFuncInvoked bool
 
+	ListMDMAppleCommandQueueFunc        ListMDMAppleCommandQueueFunc
+	ListMDMAppleCommandQueueFuncInvoked bool
+
+	ListMDMAppleHostsWithPendingCommandsFunc        ListMDMAppleHostsWithPendingCommandsFunc
+	ListMDMAppleHostsWithPendingCommandsFuncInvoked bool
+
 	NewMDMAppleInstallerFunc        NewMDMAppleInstallerFunc
 	NewMDMAppleInstallerFuncInvoked bool
 
@@ -2801,6 +3101,36 @@ type DataStore struct {
 	UpdateHostLockWipeStatusFromAppleMDMResultFunc        UpdateHostLockWipeStatusFromAppleMDMResultFunc
 	UpdateHostLockWipeStatusFromAppleMDMResultFuncInvoked bool
 
+	SetHostMDMAppleDeviceLocationFunc        SetHostMDMAppleDeviceLocationFunc
+	SetHostMDMAppleDeviceLocationFuncInvoked bool
+
+	GetHostMDMAppleDeviceLocationFunc        GetHostMDMAppleDeviceLocationFunc
+	GetHostMDMAppleDeviceLocationFuncInvoked bool
+
+	ListHostsPendingRecoveryLockRotationFunc        ListHostsPendingRecoveryLockRotationFunc
+	ListHostsPendingRecoveryLockRotationFuncInvoked bool
+
+	SetHostRecoveryLockPendingFunc        SetHostRecoveryLockPendingFunc
+	SetHostRecoveryLockPendingFuncInvoked bool
+
+	SetHostRecoveryLockVerifyingFunc        SetHostRecoveryLockVerifyingFunc
+	SetHostRecoveryLockVerifyingFuncInvoked bool
+
+	SetHostRecoveryLockVerifiedFunc        SetHostRecoveryLockVerifiedFunc
+	SetHostRecoveryLockVerifiedFuncInvoked bool
+
+	SetHostRecoveryLockFailedFunc        SetHostRecoveryLockFailedFunc
+	SetHostRecoveryLockFailedFuncInvoked bool
+
+	GetHostRecoveryLockFunc        GetHostRecoveryLockFunc
+	GetHostRecoveryLockFuncInvoked bool
+
+	SetHostRecoveryLockRevealedFunc        SetHostRecoveryLockRevealedFunc
+	SetHostRecoveryLockRevealedFuncInvoked bool
+
+	RedactMDMAppleCommandPayloadFunc        RedactMDMAppleCommandPayloadFunc
+	RedactMDMAppleCommandPayloadFuncInvoked bool
+
 	GetIncludedHostIDMapForSoftwareInstallerFunc        GetIncludedHostIDMapForSoftwareInstallerFunc
 	GetIncludedHostIDMapForSoftwareInstallerFuncInvoked bool
 
@@ -2987,6 +3317,66 @@ type DataStore struct {
 	ExpandEmbeddedSecretsAndUpdatedAtFunc        ExpandEmbeddedSecretsAndUpdatedAtFunc
 	ExpandEmbeddedSecretsAndUpdatedAtFuncInvoked bool
 
+	NewCustomCVEMatchingRuleFunc        NewCustomCVEMatchingRuleFunc
+	NewCustomCVEMatchingRuleFuncInvoked bool
+
+	CustomCVEMatchingRuleFunc        CustomCVEMatchingRuleFunc
+	CustomCVEMatchingRuleFuncInvoked bool
+
+	ListCustomCVEMatchingRulesFunc        ListCustomCVEMatchingRulesFunc
+	ListCustomCVEMatchingRulesFuncInvoked bool
+
+	DeleteCustomCVEMatchingRuleFunc        DeleteCustomCVEMatchingRuleFunc
+	DeleteCustomCVEMatchingRuleFuncInvoked bool
+
+	BatchSetCustomCVEMatchingRulesFunc        BatchSetCustomCVEMatchingRulesFunc
+	BatchSetCustomCVEMatchingRulesFuncInvoked bool
+
+	NewVulnerabilitySuppressionFunc        NewVulnerabilitySuppressionFunc
+	NewVulnerabilitySuppressionFuncInvoked bool
+
+	VulnerabilitySuppressionFunc        VulnerabilitySuppressionFunc
+	VulnerabilitySuppressionFuncInvoked bool
+
+	ListVulnerabilitySuppressionsFunc        ListVulnerabilitySuppressionsFunc
+	ListVulnerabilitySuppressionsFuncInvoked bool
+
+	DeleteVulnerabilitySuppressionFunc        DeleteVulnerabilitySuppressionFunc
+	DeleteVulnerabilitySuppressionFuncInvoked bool
+
+	UpsertVulnerabilitySuppressionsFunc        UpsertVulnerabilitySuppressionsFunc
+	UpsertVulnerabilitySuppressionsFuncInvoked bool
+
+	FilterSuppressedSoftwareVulnerabilitiesFunc        FilterSuppressedSoftwareVulnerabilitiesFunc
+	FilterSuppressedSoftwareVulnerabilitiesFuncInvoked bool
+
+	SyncHostVulnerabilitiesFunc        SyncHostVulnerabilitiesFunc
+	SyncHostVulnerabilitiesFuncInvoked bool
+
+	ListOpenHostVulnerabilitiesFunc        ListOpenHostVulnerabilitiesFunc
+	ListOpenHostVulnerabilitiesFuncInvoked bool
+
+	SetHostVulnerabilitiesSLABreachNotifiedFunc        SetHostVulnerabilitiesSLABreachNotifiedFunc
+	SetHostVulnerabilitiesSLABreachNotifiedFuncInvoked bool
+
+	ListOverdueHostVulnerabilitiesFunc        ListOverdueHostVulnerabilitiesFunc
+	ListOverdueHostVulnerabilitiesFuncInvoked bool
+
+	VulnerabilityRemediationMetricsFunc        VulnerabilityRemediationMetricsFunc
+	VulnerabilityRemediationMetricsFuncInvoked bool
+
+	ListVulnerableSoftwareTitlesByCVEFunc        ListVulnerableSoftwareTitlesByCVEFunc
+	ListVulnerableSoftwareTitlesByCVEFuncInvoked bool
+
+	VulnerabilityHostCountsByTeamFunc        VulnerabilityHostCountsByTeamFunc
+	VulnerabilityHostCountsByTeamFuncInvoked bool
+
+	ListVulnerabilityPoliciesFunc        ListVulnerabilityPoliciesFunc
+	ListVulnerabilityPoliciesFuncInvoked bool
+
+	NewVulnerabilityPolicyFunc        NewVulnerabilityPolicyFunc
+	NewVulnerabilityPolicyFuncInvoked bool
+
 	mu sync.Mutex
 }
 
@@ -3844,6 +4234,55 @@ func (s *DataStore) CleanupHostMDMCommands(ctx context.Context) error {
 	return s.CleanupHostMDMCommandsFunc(ctx)
 }
 
+func (s *DataStore) ListHostsToRefetchCertificates(ctx context.Context, interval time.Duration, limit int) ([]*mdmlab.HostCertificateRefetchTarget, error) {
+	s.mu.Lock()
+	s.ListHostsToRefetchCertificatesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListHostsToRefetchCertificatesFunc(ctx, interval, limit)
+}
+
+func (s *DataStore) ReplaceHostCertificates(ctx context.Context, hostID uint, source mdmlab.HostCertificateSource, certs []*mdmlab.HostCertificate) error {
+	s.mu.Lock()
+	s.ReplaceHostCertificatesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ReplaceHostCertificatesFunc(ctx, hostID, source, certs)
+}
+
+func (s *DataStore) SetHostCertificatesManaged(ctx context.Context, hostID uint, source mdmlab.HostCertificateSource, sha1Sums []string) error {
+	s.mu.Lock()
+	s.SetHostCertificatesManagedFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostCertificatesManagedFunc(ctx, hostID, source, sha1Sums)
+}
+
+func (s *DataStore) ListHostCertificates(ctx context.Context, hostID uint, opt mdmlab.ListOptions) ([]*mdmlab.HostCertificate, *mdmlab.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListHostCertificatesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListHostCertificatesFunc(ctx, hostID, opt)
+}
+
+func (s *DataStore) SearchHostCertificates(ctx context.Context, filter mdmlab.TeamFilter, opt mdmlab.HostCertificateListOptions) ([]*mdmlab.HostCertificateWithHost, *mdmlab.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.SearchHostCertificatesFuncInvoked = true
+	s.mu.Unlock()
+	return s.SearchHostCertificatesFunc(ctx, filter, opt)
+}
+
+func (s *DataStore) ListExpiringHostCertificatesToNotify(ctx context.Context, within time.Duration, limit int) ([]*mdmlab.HostCertificateWithHost, error) {
+	s.mu.Lock()
+	s.ListExpiringHostCertificatesToNotifyFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListExpiringHostCertificatesToNotifyFunc(ctx, within, limit)
+}
+
+func (s *DataStore) SetHostCertificatesExpirationNotified(ctx context.Context, ids []uint) error {
+	s.mu.Lock()
+	s.SetHostCertificatesExpirationNotifiedFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostCertificatesExpirationNotifiedFunc(ctx, ids)
+}
+
 func (s *DataStore) CleanupHostMDMAppleProfiles(ctx context.Context) error {
 	s.mu.Lock()
 	s.CleanupHostMDMAppleProfilesFuncInvoked = true
@@ -4516,6 +4955,279 @@ func (s *DataStore) IsSoftwareInstallerLabelScoped(ctx context.Context, installe
 	return s.IsSoftwareInstallerLabelScopedFunc(ctx, installerID, hostID)
 }
 
+func (s *DataStore) IsSoftwareInstallerRolloutScoped(ctx context.Context, installerID uint, hostID uint) (bool, error) {
+	s.mu.Lock()
+	s.IsSoftwareInstallerRolloutScopedFuncInvoked = true
+	s.mu.Unlock()
+	return s.IsSoftwareInstallerRolloutScopedFunc(ctx, installerID, hostID)
+}
+
+func (s *DataStore) SetSoftwareInstallerRollout(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) (*mdmlab.SoftwareInstallerRollout, error) {
+	s.mu.Lock()
+	s.SetSoftwareInstallerRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetSoftwareInstallerRolloutFunc(ctx, rollout)
+}
+
+func (s *DataStore) GetSoftwareInstallerRollout(ctx context.Context, installerID uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	s.mu.Lock()
+	s.GetSoftwareInstallerRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareInstallerRolloutFunc(ctx, installerID)
+}
+
+func (s *DataStore) ListActiveSoftwareInstallerRollouts(ctx context.Context) ([]*mdmlab.SoftwareInstallerRollout, error) {
+	s.mu.Lock()
+	s.ListActiveSoftwareInstallerRolloutsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListActiveSoftwareInstallerRolloutsFunc(ctx)
+}
+
+func (s *DataStore) UpdateSoftwareInstallerRollout(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) error {
+	s.mu.Lock()
+	s.UpdateSoftwareInstallerRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpdateSoftwareInstallerRolloutFunc(ctx, rollout)
+}
+
+func (s *DataStore) DeleteSoftwareInstallerRollout(ctx context.Context, installerID uint) error {
+	s.mu.Lock()
+	s.DeleteSoftwareInstallerRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteSoftwareInstallerRolloutFunc(ctx, installerID)
+}
+
+func (s *DataStore) GetSoftwareInstallerRolloutStats(ctx context.Context, installerID uint, since time.Time) (*mdmlab.SoftwareInstallerRolloutStats, error) {
+	s.mu.Lock()
+	s.GetSoftwareInstallerRolloutStatsFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareInstallerRolloutStatsFunc(ctx, installerID, since)
+}
+
+func (s *DataStore) ListSoftwareInstallerRolloutHostsToInstall(ctx context.Context, rollout *mdmlab.SoftwareInstallerRollout) ([]*mdmlab.SoftwareInstallerRolloutHost, error) {
+	s.mu.Lock()
+	s.ListSoftwareInstallerRolloutHostsToInstallFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareInstallerRolloutHostsToInstallFunc(ctx, rollout)
+}
+
+func (s *DataStore) ListSoftwareInstallerVersions(ctx context.Context, installerID uint) ([]*mdmlab.SoftwareInstallerVersion, error) {
+	s.mu.Lock()
+	s.ListSoftwareInstallerVersionsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareInstallerVersionsFunc(ctx, installerID)
+}
+
+func (s *DataStore) GetSoftwareInstallerVersion(ctx context.Context, installerID uint, versionID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+	s.mu.Lock()
+	s.GetSoftwareInstallerVersionFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareInstallerVersionFunc(ctx, installerID, versionID)
+}
+
+func (s *DataStore) PinSoftwareInstallerVersion(ctx context.Context, installerID uint, versionID *uint) error {
+	s.mu.Lock()
+	s.PinSoftwareInstallerVersionFuncInvoked = true
+	s.mu.Unlock()
+	return s.PinSoftwareInstallerVersionFunc(ctx, installerID, versionID)
+}
+
+func (s *DataStore) RollbackSoftwareInstallerVersion(ctx context.Context, installerID uint, versionID uint) error {
+	s.mu.Lock()
+	s.RollbackSoftwareInstallerVersionFuncInvoked = true
+	s.mu.Unlock()
+	return s.RollbackSoftwareInstallerVersionFunc(ctx, installerID, versionID)
+}
+
+func (s *DataStore) SetSoftwareInstallerVersionLabels(ctx context.Context, installerID uint, versionID uint, labelIDs []uint) error {
+	s.mu.Lock()
+	s.SetSoftwareInstallerVersionLabelsFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetSoftwareInstallerVersionLabelsFunc(ctx, installerID, versionID, labelIDs)
+}
+
+func (s *DataStore) DeleteSoftwareInstallerVersion(ctx context.Context, installerID uint, versionID uint) error {
+	s.mu.Lock()
+	s.DeleteSoftwareInstallerVersionFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteSoftwareInstallerVersionFunc(ctx, installerID, versionID)
+}
+
+func (s *DataStore) GetPendingSoftwareInstallerVersion(ctx context.Context, hostID uint, installerID uint) (*mdmlab.SoftwareInstallerVersion, error) {
+	s.mu.Lock()
+	s.GetPendingSoftwareInstallerVersionFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetPendingSoftwareInstallerVersionFunc(ctx, hostID, installerID)
+}
+
+func (s *DataStore) NewSoftwareBlocklistRule(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule, labelIDs []uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	s.mu.Lock()
+	s.NewSoftwareBlocklistRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewSoftwareBlocklistRuleFunc(ctx, rule, labelIDs)
+}
+
+func (s *DataStore) UpdateSoftwareBlocklistRule(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule, labelIDs []uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	s.mu.Lock()
+	s.UpdateSoftwareBlocklistRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpdateSoftwareBlocklistRuleFunc(ctx, rule, labelIDs)
+}
+
+func (s *DataStore) GetSoftwareBlocklistRule(ctx context.Context, id uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	s.mu.Lock()
+	s.GetSoftwareBlocklistRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareBlocklistRuleFunc(ctx, id)
+}
+
+func (s *DataStore) ListSoftwareBlocklistRules(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareBlocklistRule, error) {
+	s.mu.Lock()
+	s.ListSoftwareBlocklistRulesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareBlocklistRulesFunc(ctx, teamID)
+}
+
+func (s *DataStore) ListAllSoftwareBlocklistRules(ctx context.Context) ([]*mdmlab.SoftwareBlocklistRule, error) {
+	s.mu.Lock()
+	s.ListAllSoftwareBlocklistRulesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListAllSoftwareBlocklistRulesFunc(ctx)
+}
+
+func (s *DataStore) DeleteSoftwareBlocklistRule(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	s.DeleteSoftwareBlocklistRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteSoftwareBlocklistRuleFunc(ctx, id)
+}
+
+func (s *DataStore) ListSoftwareBlocklistRuleHosts(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) ([]*mdmlab.SoftwareBlocklistHost, error) {
+	s.mu.Lock()
+	s.ListSoftwareBlocklistRuleHostsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareBlocklistRuleHostsFunc(ctx, rule)
+}
+
+func (s *DataStore) GetSoftwareBlocklistRuleHostCounts(ctx context.Context, rule *mdmlab.SoftwareBlocklistRule) (*mdmlab.SoftwareBlocklistHostCounts, error) {
+	s.mu.Lock()
+	s.GetSoftwareBlocklistRuleHostCountsFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareBlocklistRuleHostCountsFunc(ctx, rule)
+}
+
+func (s *DataStore) NewSoftwareBlocklistRemoval(ctx context.Context, ruleID uint, hostID uint, executionID string) error {
+	s.mu.Lock()
+	s.NewSoftwareBlocklistRemovalFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewSoftwareBlocklistRemovalFunc(ctx, ruleID, hostID, executionID)
+}
+
+func (s *DataStore) NewSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest) (*mdmlab.SoftwareRequest, error) {
+	s.mu.Lock()
+	s.NewSoftwareRequestFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewSoftwareRequestFunc(ctx, request)
+}
+
+func (s *DataStore) GetSoftwareRequest(ctx context.Context, id uint) (*mdmlab.SoftwareRequest, error) {
+	s.mu.Lock()
+	s.GetSoftwareRequestFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareRequestFunc(ctx, id)
+}
+
+func (s *DataStore) ListSoftwareRequests(ctx context.Context, opts mdmlab.SoftwareRequestListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListSoftwareRequestsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareRequestsFunc(ctx, opts)
+}
+
+func (s *DataStore) ReviewSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest) error {
+	s.mu.Lock()
+	s.ReviewSoftwareRequestFuncInvoked = true
+	s.mu.Unlock()
+	return s.ReviewSoftwareRequestFunc(ctx, request)
+}
+
+func (s *DataStore) ApproveSoftwareRequest(ctx context.Context, request *mdmlab.SoftwareRequest, installerID uint) error {
+	s.mu.Lock()
+	s.ApproveSoftwareRequestFuncInvoked = true
+	s.mu.Unlock()
+	return s.ApproveSoftwareRequestFunc(ctx, request, installerID)
+}
+
+func (s *DataStore) ListSoftwareRequestCatalog(ctx context.Context, host *mdmlab.Host) ([]*mdmlab.SoftwareCatalogItem, error) {
+	s.mu.Lock()
+	s.ListSoftwareRequestCatalogFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareRequestCatalogFunc(ctx, host)
+}
+
+func (s *DataStore) NewSoftwareLicense(ctx context.Context, license *mdmlab.SoftwareLicense) (*mdmlab.SoftwareLicense, error) {
+	s.mu.Lock()
+	s.NewSoftwareLicenseFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewSoftwareLicenseFunc(ctx, license)
+}
+
+func (s *DataStore) UpdateSoftwareLicense(ctx context.Context, license *mdmlab.SoftwareLicense) (*mdmlab.SoftwareLicense, error) {
+	s.mu.Lock()
+	s.UpdateSoftwareLicenseFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpdateSoftwareLicenseFunc(ctx, license)
+}
+
+func (s *DataStore) GetSoftwareLicense(ctx context.Context, id uint) (*mdmlab.SoftwareLicense, error) {
+	s.mu.Lock()
+	s.GetSoftwareLicenseFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetSoftwareLicenseFunc(ctx, id)
+}
+
+func (s *DataStore) ListSoftwareLicenses(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error) {
+	s.mu.Lock()
+	s.ListSoftwareLicensesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareLicensesFunc(ctx, teamID)
+}
+
+func (s *DataStore) ListAllSoftwareLicenses(ctx context.Context) ([]*mdmlab.SoftwareLicense, error) {
+	s.mu.Lock()
+	s.ListAllSoftwareLicensesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListAllSoftwareLicensesFunc(ctx)
+}
+
+func (s *DataStore) DeleteSoftwareLicense(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	s.DeleteSoftwareLicenseFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteSoftwareLicenseFunc(ctx, id)
+}
+
+func (s *DataStore) ListSoftwareLicenseHosts(ctx context.Context, license *mdmlab.SoftwareLicense) ([]*mdmlab.SoftwareLicenseHost, error) {
+	s.mu.Lock()
+	s.ListSoftwareLicenseHostsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareLicenseHostsFunc(ctx, license)
+}
+
+func (s *DataStore) SoftwareTitleUsage(ctx context.Context, titleID uint, teamID *uint, tmFilter mdmlab.TeamFilter) (*mdmlab.SoftwareTitleUsage, error) {
+	s.mu.Lock()
+	s.SoftwareTitleUsageFuncInvoked = true
+	s.mu.Unlock()
+	return s.SoftwareTitleUsageFunc(ctx, titleID, teamID, tmFilter)
+}
+
+func (s *DataStore) ListUnusedSoftware(ctx context.Context, opts mdmlab.UnusedSoftwareListOptions, tmFilter mdmlab.TeamFilter) ([]*mdmlab.UnusedSoftware, *mdmlab.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListUnusedSoftwareFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListUnusedSoftwareFunc(ctx, opts, tmFilter)
+}
+
 func (s *DataStore) SetHostSoftwareInstallResult(ctx context.Context, result *mdmlab.HostSoftwareInstallResultPayload) error {
 	s.mu.Lock()
 	s.SetHostSoftwareInstallResultFuncInvoked = true
@@ -5643,6 +6355,20 @@ func (s *DataStore) ListMDMAppleCommands(ctx context.Context, tmFilter mdmlab.Te
 	return s.ListMDMAppleCommandsFunc(ctx, tmFilter, listOpts)
 }
 
+func (s *DataStore) ListMDMAppleCommandQueue(ctx context.Context, tmFilter mdmlab.TeamFilter, listOpts *mdmlab.MDMAppleCommandQueueListOptions) ([]*mdmlab.MDMAppleCommandQueueItem, error) {
+	s.mu.Lock()
+	s.ListMDMAppleCommandQueueFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListMDMAppleCommandQueueFunc(ctx, tmFilter, listOpts)
+}
+
+func (s *DataStore) ListMDMAppleHostsWithPendingCommands(ctx context.Context, tmFilter mdmlab.TeamFilter, hostUUIDs []string, commandUUID string, lastSeenBefore *time.Time) ([]*mdmlab.Host, error) {
+	s.mu.Lock()
+	s.ListMDMAppleHostsWithPendingCommandsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListMDMAppleHostsWithPendingCommandsFunc(ctx, tmFilter, hostUUIDs, commandUUID, lastSeenBefore)
+}
+
 func (s *DataStore) NewMDMAppleInstaller(ctx context.Context, name string, size int64, manifest string, installer []byte, urlToken string) (*mdmlab.MDMAppleInstaller, error) {
 	s.mu.Lock()
 	s.NewMDMAppleInstallerFuncInvoked = true
@@ -6707,6 +7433,76 @@ func (s *DataStore) UpdateHostLockWipeStatusFromAppleMDMResult(ctx context.Conte
 	return s.UpdateHostLockWipeStatusFromAppleMDMResultFunc(ctx, hostUUID, cmdUUID, requestType, succeeded)
 }
 
+func (s *DataStore) SetHostMDMAppleDeviceLocation(ctx context.Context, loc *mdmlab.HostMDMAppleDeviceLocation) error {
+	s.mu.Lock()
+	s.SetHostMDMAppleDeviceLocationFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostMDMAppleDeviceLocationFunc(ctx, loc)
+}
+
+func (s *DataStore) GetHostMDMAppleDeviceLocation(ctx context.Context, hostUUID string) (*mdmlab.HostMDMAppleDeviceLocation, error) {
+	s.mu.Lock()
+	s.GetHostMDMAppleDeviceLocationFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetHostMDMAppleDeviceLocationFunc(ctx, hostUUID)
+}
+
+func (s *DataStore) ListHostsPendingRecoveryLockRotation(ctx context.Context, teamID *uint, limit int) ([]*mdmlab.HostRecoveryLockTarget, error) {
+	s.mu.Lock()
+	s.ListHostsPendingRecoveryLockRotationFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListHostsPendingRecoveryLockRotationFunc(ctx, teamID, limit)
+}
+
+func (s *DataStore) SetHostRecoveryLockPending(ctx context.Context, hostUUID string, passwordType string, password string, commandUUID string, rotateAt *time.Time) error {
+	s.mu.Lock()
+	s.SetHostRecoveryLockPendingFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostRecoveryLockPendingFunc(ctx, hostUUID, passwordType, password, commandUUID, rotateAt)
+}
+
+func (s *DataStore) SetHostRecoveryLockVerifying(ctx context.Context, hostUUID string, verifyCommandUUID string) error {
+	s.mu.Lock()
+	s.SetHostRecoveryLockVerifyingFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostRecoveryLockVerifyingFunc(ctx, hostUUID, verifyCommandUUID)
+}
+
+func (s *DataStore) SetHostRecoveryLockVerified(ctx context.Context, hostUUID string) error {
+	s.mu.Lock()
+	s.SetHostRecoveryLockVerifiedFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostRecoveryLockVerifiedFunc(ctx, hostUUID)
+}
+
+func (s *DataStore) SetHostRecoveryLockFailed(ctx context.Context, hostUUID string, detail string, retryAt time.Time) error {
+	s.mu.Lock()
+	s.SetHostRecoveryLockFailedFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostRecoveryLockFailedFunc(ctx, hostUUID, detail, retryAt)
+}
+
+func (s *DataStore) GetHostRecoveryLock(ctx context.Context, hostUUID string) (*mdmlab.HostRecoveryLock, error) {
+	s.mu.Lock()
+	s.GetHostRecoveryLockFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetHostRecoveryLockFunc(ctx, hostUUID)
+}
+
+func (s *DataStore) SetHostRecoveryLockRevealed(ctx context.Context, hostUUID string, rotateAt time.Time) error {
+	s.mu.Lock()
+	s.SetHostRecoveryLockRevealedFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostRecoveryLockRevealedFunc(ctx, hostUUID, rotateAt)
+}
+
+func (s *DataStore) RedactMDMAppleCommandPayload(ctx context.Context, commandUUID string) error {
+	s.mu.Lock()
+	s.RedactMDMAppleCommandPayloadFuncInvoked = true
+	s.mu.Unlock()
+	return s.RedactMDMAppleCommandPayloadFunc(ctx, commandUUID)
+}
+
 func (s *DataStore) GetIncludedHostIDMapForSoftwareInstaller(ctx context.Context, installerID uint) (map[uint]struct{}, error) {
 	s.mu.Lock()
 	s.GetIncludedHostIDMapForSoftwareInstallerFuncInvoked = true
@@ -7140,3 +7936,143 @@ func (s *DataStore) ExpandEmbeddedSecretsAndUpdatedAt(ctx context.Context, docum
 	s.mu.Unlock()
 	return s.ExpandEmbeddedSecretsAndUpdatedAtFunc(ctx, document)
 }
+
+func (s *DataStore) NewCustomCVEMatchingRule(ctx context.Context, rule *mdmlab.CustomCVEMatchingRule) (*mdmlab.CustomCVEMatchingRule, error) {
+	s.mu.Lock()
+	s.NewCustomCVEMatchingRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewCustomCVEMatchingRuleFunc(ctx, rule)
+}
+
+func (s *DataStore) CustomCVEMatchingRule(ctx context.Context, id uint) (*mdmlab.CustomCVEMatchingRule, error) {
+	s.mu.Lock()
+	s.CustomCVEMatchingRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.CustomCVEMatchingRuleFunc(ctx, id)
+}
+
+func (s *DataStore) ListCustomCVEMatchingRules(ctx context.Context) ([]*mdmlab.CustomCVEMatchingRule, error) {
+	s.mu.Lock()
+	s.ListCustomCVEMatchingRulesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListCustomCVEMatchingRulesFunc(ctx)
+}
+
+func (s *DataStore) DeleteCustomCVEMatchingRule(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	s.DeleteCustomCVEMatchingRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteCustomCVEMatchingRuleFunc(ctx, id)
+}
+
+func (s *DataStore) BatchSetCustomCVEMatchingRules(ctx context.Context, rules []*mdmlab.CustomCVEMatchingRule) error {
+	s.mu.Lock()
+	s.BatchSetCustomCVEMatchingRulesFuncInvoked = true
+	s.mu.Unlock()
+	return s.BatchSetCustomCVEMatchingRulesFunc(ctx, rules)
+}
+
+func (s *DataStore) NewVulnerabilitySuppression(ctx context.Context, suppression *mdmlab.VulnerabilitySuppression) (*mdmlab.VulnerabilitySuppression, error) {
+	s.mu.Lock()
+	s.NewVulnerabilitySuppressionFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewVulnerabilitySuppressionFunc(ctx, suppression)
+}
+
+func (s *DataStore) VulnerabilitySuppression(ctx context.Context, id uint) (*mdmlab.VulnerabilitySuppression, error) {
+	s.mu.Lock()
+	s.VulnerabilitySuppressionFuncInvoked = true
+	s.mu.Unlock()
+	return s.VulnerabilitySuppressionFunc(ctx, id)
+}
+
+func (s *DataStore) ListVulnerabilitySuppressions(ctx context.Context, opts mdmlab.ListVulnerabilitySuppressionsOptions) ([]*mdmlab.VulnerabilitySuppression, error) {
+	s.mu.Lock()
+	s.ListVulnerabilitySuppressionsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListVulnerabilitySuppressionsFunc(ctx, opts)
+}
+
+func (s *DataStore) DeleteVulnerabilitySuppression(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	s.DeleteVulnerabilitySuppressionFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteVulnerabilitySuppressionFunc(ctx, id)
+}
+
+func (s *DataStore) UpsertVulnerabilitySuppressions(ctx context.Context, suppressions []*mdmlab.VulnerabilitySuppression) error {
+	s.mu.Lock()
+	s.UpsertVulnerabilitySuppressionsFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpsertVulnerabilitySuppressionsFunc(ctx, suppressions)
+}
+
+func (s *DataStore) FilterSuppressedSoftwareVulnerabilities(ctx context.Context, vulns []mdmlab.SoftwareVulnerability) ([]mdmlab.SoftwareVulnerability, error) {
+	s.mu.Lock()
+	s.FilterSuppressedSoftwareVulnerabilitiesFuncInvoked = true
+	s.mu.Unlock()
+	return s.FilterSuppressedSoftwareVulnerabilitiesFunc(ctx, vulns)
+}
+
+func (s *DataStore) SyncHostVulnerabilities(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	s.SyncHostVulnerabilitiesFuncInvoked = true
+	s.mu.Unlock()
+	return s.SyncHostVulnerabilitiesFunc(ctx, now)
+}
+
+func (s *DataStore) ListOpenHostVulnerabilities(ctx context.Context, opts mdmlab.HostVulnerabilityListOptions) ([]*mdmlab.HostVulnerability, error) {
+	s.mu.Lock()
+	s.ListOpenHostVulnerabilitiesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListOpenHostVulnerabilitiesFunc(ctx, opts)
+}
+
+func (s *DataStore) SetHostVulnerabilitiesSLABreachNotified(ctx context.Context, ids []uint, notifiedAt time.Time) error {
+	s.mu.Lock()
+	s.SetHostVulnerabilitiesSLABreachNotifiedFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostVulnerabilitiesSLABreachNotifiedFunc(ctx, ids, notifiedAt)
+}
+
+func (s *DataStore) ListOverdueHostVulnerabilities(ctx context.Context, opts mdmlab.OverdueHostVulnerabilityListOptions) ([]*mdmlab.OverdueHostVulnerability, *mdmlab.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListOverdueHostVulnerabilitiesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListOverdueHostVulnerabilitiesFunc(ctx, opts)
+}
+
+func (s *DataStore) VulnerabilityRemediationMetrics(ctx context.Context, teamID *uint, rules []mdmlab.VulnerabilitySLARule, now time.Time) (*mdmlab.VulnerabilityRemediationMetrics, error) {
+	s.mu.Lock()
+	s.VulnerabilityRemediationMetricsFuncInvoked = true
+	s.mu.Unlock()
+	return s.VulnerabilityRemediationMetricsFunc(ctx, teamID, rules, now)
+}
+
+func (s *DataStore) ListVulnerableSoftwareTitlesByCVE(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerableSoftwareTitle, error) {
+	s.mu.Lock()
+	s.ListVulnerableSoftwareTitlesByCVEFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListVulnerableSoftwareTitlesByCVEFunc(ctx, cve, teamID)
+}
+
+func (s *DataStore) VulnerabilityHostCountsByTeam(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityTeamHostsCount, error) {
+	s.mu.Lock()
+	s.VulnerabilityHostCountsByTeamFuncInvoked = true
+	s.mu.Unlock()
+	return s.VulnerabilityHostCountsByTeamFunc(ctx, cve, teamID)
+}
+
+func (s *DataStore) ListVulnerabilityPolicies(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityPolicy, error) {
+	s.mu.Lock()
+	s.ListVulnerabilityPoliciesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListVulnerabilityPoliciesFunc(ctx, cve, teamID)
+}
+
+func (s *DataStore) NewVulnerabilityPolicy(ctx context.Context, teamID *uint, authorID *uint, args mdmlab.PolicyPayload, cve string, softwareTitleID *uint) (*mdmlab.Policy, error) {
+	s.mu.Lock()
+	s.NewVulnerabilityPolicyFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewVulnerabilityPolicyFunc(ctx, teamID, authorID, args, cve, softwareTitleID)
+}
@@ -31,6 +31,8 @@ type ClearQueueFunc func(r *mdm.Request) error
 
 type BulkDeleteHostUserCommandsWithoutResultsFunc func(ctx context.Context, commandToId map[string][]string) error
 
+type CancelPendingCommandsFunc func(ctx context.Context, deviceIDs []string, commandUUID string, excludeRequestTypes []string) (int64, error)
+
 type StoreBootstrapTokenFunc func(r *mdm.Request, msg *mdm.SetBootstrapToken) error
 
 type RetrieveBootstrapTokenFunc func(r *mdm.Request, msg *mdm.GetBootstrapToken) (*mdm.BootstrapToken, error)
@@ -69,6 +71,10 @@ type EnqueueDeviceLockCommandFunc func(ctx context.Context, host *mdmlab.Host, c
 
 type EnqueueDeviceWipeCommandFunc func(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error
 
+type EnqueueEnableLostModeCommandFunc func(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error
+
+type EnqueueDisableLostModeCommandFunc func(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error
+
 type MDMAppleStore struct {
 	StoreAuthenticateFunc        StoreAuthenticateFunc
 	StoreAuthenticateFuncInvoked bool
@@ -94,6 +100,9 @@ type MDMAppleStore struct {
 	BulkDeleteHostUserCommandsWithoutResultsFunc        BulkDeleteHostUserCommandsWithoutResultsFunc
 	BulkDeleteHostUserCommandsWithoutResultsFuncInvoked bool
 
+	CancelPendingCommandsFunc        CancelPendingCommandsFunc
+	CancelPendingCommandsFuncInvoked bool
+
 	StoreBootstrapTokenFunc        StoreBootstrapTokenFunc
 	StoreBootstrapTokenFuncInvoked bool
 
@@ -151,6 +160,12 @@ type MDMAppleStore struct {
 	EnqueueDeviceWipeCommandFunc        EnqueueDeviceWipeCommandFunc
 	EnqueueDeviceWipeCommandFuncInvoked bool
 
+	EnqueueEnableLostModeCommandFunc        EnqueueEnableLostModeCommandFunc
+	EnqueueEnableLostModeCommandFuncInvoked bool
+
+	EnqueueDisableLostModeCommandFunc        EnqueueDisableLostModeCommandFunc
+	EnqueueDisableLostModeCommandFuncInvoked bool
+
 	mu sync.Mutex
 }
 
@@ -210,6 +225,13 @@ func (fs *MDMAppleStore) BulkDeleteHostUserCommandsWithoutResults(ctx context.Co
 	return fs.BulkDeleteHostUserCommandsWithoutResultsFunc(ctx, commandToId)
 }
 
+func (fs *MDMAppleStore) CancelPendingCommands(ctx context.Context, deviceIDs []string, commandUUID string, excludeRequestTypes []string) (int64, error) {
+	fs.mu.Lock()
+	fs.CancelPendingCommandsFuncInvoked = true
+	fs.mu.Unlock()
+	return fs.CancelPendingCommandsFunc(ctx, deviceIDs, commandUUID, excludeRequestTypes)
+}
+
 func (fs *MDMAppleStore) StoreBootstrapToken(r *mdm.Request, msg *mdm.SetBootstrapToken) error {
 	fs.mu.Lock()
 	fs.StoreBootstrapTokenFuncInvoked = true
@@ -342,3 +364,17 @@ func (fs *MDMAppleStore) EnqueueDeviceWipeCommand(ctx context.Context, host *mdm
 	fs.mu.Unlock()
 	return fs.EnqueueDeviceWipeCommandFunc(ctx, host, cmd)
 }
+
+func (fs *MDMAppleStore) EnqueueEnableLostModeCommand(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error {
+	fs.mu.Lock()
+	fs.EnqueueEnableLostModeCommandFuncInvoked = true
+	fs.mu.Unlock()
+	return fs.EnqueueEnableLostModeCommandFunc(ctx, host, cmd)
+}
+
+func (fs *MDMAppleStore) EnqueueDisableLostModeCommand(ctx context.Context, host *mdmlab.Host, cmd *mdm.Command) error {
+	fs.mu.Lock()
+	fs.EnqueueDisableLostModeCommandFuncInvoked = true
+	fs.mu.Unlock()
+	return fs.EnqueueDisableLostModeCommandFunc(ctx, host, cmd)
+}
@@ -0,0 +1,178 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+const cycloneDXSpecVersion = "1.5"
+
+type cycloneDXDocument struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        cycloneDXMetadata        `json:"metadata"`
+	Components      []cycloneDXComponent     `json:"components"`
+	Vulnerabilities []cycloneDXVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	CPE        string              `json:"cpe,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXVulnerability struct {
+	BOMRef      string             `json:"bom-ref"`
+	ID          string             `json:"id"`
+	Source      cycloneDXSource    `json:"source"`
+	Ratings     []cycloneDXRating  `json:"ratings,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Published   *time.Time         `json:"published,omitempty"`
+	Affects     []cycloneDXAffects `json:"affects"`
+}
+
+type cycloneDXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXRating struct {
+	Source   cycloneDXSource `json:"source"`
+	Score    float64         `json:"score"`
+	Severity string          `json:"severity"`
+	Method   string          `json:"method"`
+}
+
+type cycloneDXAffects struct {
+	Ref string `json:"ref"`
+}
+
+func generateCycloneDX(subject Subject, software []mdmlab.Software, opts Options) ([]byte, error) {
+	subjectType := "platform"
+	if subject.IsHost {
+		subjectType = "device"
+	}
+
+	doc := cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: opts.Now.UTC(),
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{{Type: "application", Name: "mdmlab", Version: opts.ToolVersion}},
+			},
+			Component: cycloneDXComponent{Type: subjectType, Name: subject.Name},
+		},
+		Components: make([]cycloneDXComponent, 0, len(software)),
+	}
+
+	vulns := make(map[string]*cycloneDXVulnerability)
+	for _, s := range software {
+		ref := componentRef(s)
+		componentType := "application"
+		if languagePackageSources[s.Source] {
+			componentType = "library"
+		}
+		doc.Components = append(doc.Components, cycloneDXComponent{
+			Type:       componentType,
+			BOMRef:     ref,
+			Name:       s.Name,
+			Version:    s.Version,
+			PURL:       PURL(s, subject.Distro),
+			CPE:        s.GenerateCPE,
+			Properties: []cycloneDXProperty{{Name: "mdmlab:source", Value: s.Source}},
+		})
+
+		for _, cve := range s.Vulnerabilities {
+			v, ok := vulns[cve.CVE]
+			if !ok {
+				v = newCycloneDXVulnerability(cve)
+				vulns[cve.CVE] = v
+			}
+			v.Affects = append(v.Affects, cycloneDXAffects{Ref: ref})
+		}
+	}
+
+	for _, v := range vulns {
+		doc.Vulnerabilities = append(doc.Vulnerabilities, *v)
+	}
+	sort.Slice(doc.Vulnerabilities, func(i, j int) bool {
+		return doc.Vulnerabilities[i].ID < doc.Vulnerabilities[j].ID
+	})
+
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal CycloneDX document: %w", err)
+	}
+	return b, nil
+}
+
+func newCycloneDXVulnerability(cve mdmlab.CVE) *cycloneDXVulnerability {
+	nvd := cycloneDXSource{Name: "NVD", URL: nvdDetailsURL(cve.CVE)}
+	v := &cycloneDXVulnerability{
+		BOMRef: cve.CVE,
+		ID:     cve.CVE,
+		Source: nvd,
+	}
+	// CVE details are only available on premium, see mdmlab.CVE
+	if cve.CVSSScore != nil && *cve.CVSSScore != nil {
+		score := **cve.CVSSScore
+		v.Ratings = []cycloneDXRating{{Source: nvd, Score: score, Severity: cvssSeverity(score), Method: "CVSSv31"}}
+	}
+	if cve.Description != nil && *cve.Description != nil {
+		v.Description = **cve.Description
+	}
+	if cve.CVEPublished != nil && *cve.CVEPublished != nil {
+		published := (**cve.CVEPublished).UTC()
+		v.Published = &published
+	}
+	return v
+}
+
+// componentRef returns the reference of the software component in the CycloneDX document.
+func componentRef(s mdmlab.Software) string {
+	return fmt.Sprintf("mdmlab:software/%d", s.ID)
+}
+
+// cvssSeverity returns the qualitative severity rating of the CVSS v3 base score.
+func cvssSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	}
+	return "none"
+}
@@ -0,0 +1,82 @@
+package sbom
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// purlTypes maps the software sources to their package URL type, software from other sources
+// (e.g. macOS apps or Windows programs) has no package URL.
+var purlTypes = map[string]string{
+	"deb_packages":      "deb",
+	"rpm_packages":      "rpm",
+	"npm_packages":      "npm",
+	"python_packages":   "pypi",
+	"homebrew_packages": "brew",
+}
+
+// PURL returns the package URL (https://github.com/package-url/purl-spec) of the software, or an
+// empty string if the software source has no package URL type. The distro (e.g. "ubuntu" or
+// "rhel") is used as the namespace of deb and rpm packages, it can be empty if unknown.
+func PURL(s mdmlab.Software, distro string) string {
+	typ, ok := purlTypes[s.Source]
+	if !ok || s.Name == "" {
+		return ""
+	}
+
+	var namespace, name, version string
+	qualifiers := url.Values{}
+	switch typ {
+	case "deb":
+		namespace, name, version = distro, s.Name, s.Version
+		if s.Arch != "" {
+			qualifiers.Set("arch", s.Arch)
+		}
+	case "rpm":
+		namespace, name, version = distro, s.Name, s.Version
+		if s.Release != "" {
+			version += "-" + s.Release
+		}
+		if s.Arch != "" {
+			qualifiers.Set("arch", s.Arch)
+		}
+	case "npm":
+		// scoped packages are reported as "@scope/name"
+		name = s.Name
+		if scope, n, ok := strings.Cut(s.Name, "/"); ok && strings.HasPrefix(scope, "@") {
+			namespace, name = scope, n
+		}
+		version = s.Version
+	case "pypi":
+		// PyPI names are case insensitive and '_' is equivalent to '-'
+		name, version = strings.ReplaceAll(strings.ToLower(s.Name), "_", "-"), s.Version
+	default:
+		name, version = s.Name, s.Version
+	}
+
+	var sb strings.Builder
+	sb.WriteString("pkg:")
+	sb.WriteString(typ)
+	sb.WriteString("/")
+	if namespace != "" {
+		sb.WriteString(purlEscape(strings.ToLower(namespace)))
+		sb.WriteString("/")
+	}
+	sb.WriteString(purlEscape(name))
+	if version != "" {
+		sb.WriteString("@")
+		sb.WriteString(purlEscape(version))
+	}
+	if len(qualifiers) > 0 {
+		sb.WriteString("?")
+		sb.WriteString(qualifiers.Encode())
+	}
+	return sb.String()
+}
+
+// purlEscape percent-encodes a package URL component.
+func purlEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
@@ -0,0 +1,71 @@
+// Package sbom generates software bills of materials (SBOM) from the software inventory of
+// hosts, in the CycloneDX and SPDX JSON formats.
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// Format is a supported SBOM format.
+type Format string
+
+const (
+	FormatCycloneDX Format = "cyclonedx"
+	FormatSPDX      Format = "spdx"
+)
+
+// ParseFormat returns the format matching the provided name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCycloneDX, FormatSPDX:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported SBOM format %q, supported formats are %q and %q", s, FormatCycloneDX, FormatSPDX)
+}
+
+// Subject describes what the SBOM is generated for, a host or a team.
+type Subject struct {
+	// Name is the name of the host or team.
+	Name string
+	// Distro is the Linux distribution of the host (e.g. "ubuntu"), used as namespace of the
+	// package URLs of deb and rpm packages. It is empty for teams.
+	Distro string
+	// IsHost is true if the subject is a single host.
+	IsHost bool
+}
+
+// Options are the options used when generating an SBOM.
+type Options struct {
+	// ToolVersion is the version of MDMlab generating the SBOM.
+	ToolVersion string
+	// Namespace is the base URI used for the SPDX document namespace, e.g. the server URL.
+	Namespace string
+	// Now is the creation time of the SBOM.
+	Now time.Time
+}
+
+// Generate returns the SBOM of the software in the given format.
+func Generate(format Format, subject Subject, software []mdmlab.Software, opts Options) ([]byte, error) {
+	switch format {
+	case FormatCycloneDX:
+		return generateCycloneDX(subject, software, opts)
+	case FormatSPDX:
+		return generateSPDX(subject, software, opts)
+	}
+	return nil, fmt.Errorf("unsupported SBOM format %q", format)
+}
+
+// languagePackageSources are the sources of software that are libraries rather than applications.
+var languagePackageSources = map[string]bool{
+	"npm_packages":    true,
+	"python_packages": true,
+}
+
+// nvdDetailsURL returns the NVD details URL of the CVE.
+func nvdDetailsURL(cve string) string {
+	return "https://nvd.nist.gov/vuln/detail/" + cve
+}
@@ -0,0 +1,122 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPURL(t *testing.T) {
+	cases := []struct {
+		software mdmlab.Software
+		distro   string
+		want     string
+	}{
+		{mdmlab.Software{Name: "openssl", Version: "3.0.2-0ubuntu1.10", Arch: "amd64", Source: "deb_packages"}, "ubuntu", "pkg:deb/ubuntu/openssl@3.0.2-0ubuntu1.10?arch=amd64"},
+		{mdmlab.Software{Name: "curl", Version: "1:7.88.1", Source: "deb_packages"}, "", "pkg:deb/curl@1:7.88.1"},
+		{mdmlab.Software{Name: "bash", Version: "5.1.8", Release: "6.el9", Arch: "x86_64", Source: "rpm_packages"}, "RHEL", "pkg:rpm/rhel/bash@5.1.8-6.el9?arch=x86_64"},
+		{mdmlab.Software{Name: "lodash", Version: "4.17.21", Source: "npm_packages"}, "ubuntu", "pkg:npm/lodash@4.17.21"},
+		{mdmlab.Software{Name: "@babel/core", Version: "7.24.0", Source: "npm_packages"}, "", "pkg:npm/%40babel/core@7.24.0"},
+		{mdmlab.Software{Name: "Typing_Extensions", Version: "4.9.0", Source: "python_packages"}, "", "pkg:pypi/typing-extensions@4.9.0"},
+		{mdmlab.Software{Name: "git", Version: "2.43.0", Source: "homebrew_packages"}, "", "pkg:brew/git@2.43.0"},
+		{mdmlab.Software{Name: "Google Chrome.app", Version: "120.0", Source: "apps"}, "", ""},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, PURL(c.software, c.distro), c.software.Name)
+	}
+}
+
+func testSoftware() []mdmlab.Software {
+	score := ptr.Float64Ptr(9.8)
+	return []mdmlab.Software{
+		{
+			ID:          1,
+			Name:        "openssl",
+			Version:     "3.0.2",
+			Source:      "deb_packages",
+			GenerateCPE: "cpe:2.3:a:openssl:openssl:3.0.2:*:*:*:*:*:*:*",
+			Vulnerabilities: mdmlab.Vulnerabilities{
+				{CVE: "CVE-2024-0002", CVSSScore: score},
+				{CVE: "CVE-2024-0001"},
+			},
+		},
+		{
+			ID:              2,
+			Name:            "lodash",
+			Version:         "4.17.20",
+			Source:          "npm_packages",
+			Vulnerabilities: mdmlab.Vulnerabilities{{CVE: "CVE-2024-0001"}},
+		},
+		{ID: 3, Name: "Slack.app", Version: "4.36", Source: "apps", Vendor: "Slack"},
+	}
+}
+
+func TestGenerateCycloneDX(t *testing.T) {
+	now := time.Date(2025, 2, 7, 10, 0, 0, 0, time.UTC)
+	b, err := Generate(FormatCycloneDX, Subject{Name: "web-1", Distro: "ubuntu", IsHost: true}, testSoftware(), Options{ToolVersion: "4.60.0", Now: now})
+	require.NoError(t, err)
+
+	var doc cycloneDXDocument
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Equal(t, "CycloneDX", doc.BOMFormat)
+	require.Equal(t, "device", doc.Metadata.Component.Type)
+	require.Equal(t, "web-1", doc.Metadata.Component.Name)
+	require.Equal(t, now, doc.Metadata.Timestamp)
+
+	require.Len(t, doc.Components, 3)
+	require.Equal(t, "pkg:deb/ubuntu/openssl@3.0.2", doc.Components[0].PURL)
+	require.Equal(t, "cpe:2.3:a:openssl:openssl:3.0.2:*:*:*:*:*:*:*", doc.Components[0].CPE)
+	require.Equal(t, "application", doc.Components[0].Type)
+	require.Equal(t, "library", doc.Components[1].Type)
+	require.Empty(t, doc.Components[2].PURL)
+
+	// vulnerabilities are sorted and list all the affected components
+	require.Len(t, doc.Vulnerabilities, 2)
+	require.Equal(t, "CVE-2024-0001", doc.Vulnerabilities[0].ID)
+	require.Equal(t, []cycloneDXAffects{{Ref: "mdmlab:software/1"}, {Ref: "mdmlab:software/2"}}, doc.Vulnerabilities[0].Affects)
+	require.Empty(t, doc.Vulnerabilities[0].Ratings)
+	require.Equal(t, "CVE-2024-0002", doc.Vulnerabilities[1].ID)
+	require.Len(t, doc.Vulnerabilities[1].Ratings, 1)
+	require.Equal(t, "critical", doc.Vulnerabilities[1].Ratings[0].Severity)
+}
+
+func TestGenerateSPDX(t *testing.T) {
+	now := time.Date(2025, 2, 7, 10, 0, 0, 0, time.UTC)
+	b, err := Generate(FormatSPDX, Subject{Name: "Workstations"}, testSoftware(), Options{Namespace: "https://mdmlab.example.com/", Now: now})
+	require.NoError(t, err)
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, "2025-02-07T10:00:00Z", doc.CreationInfo.Created)
+	require.Regexp(t, `^https://mdmlab\.example\.com/sbom/Workstations-[0-9a-f-]{36}$`, doc.DocumentNamespace)
+
+	require.Len(t, doc.Packages, 3)
+	require.Len(t, doc.Relationships, 3)
+	require.Equal(t, "SPDXRef-Package-1", doc.Packages[0].SPDXID)
+	require.Equal(t, []spdxExternalRef{
+		{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:deb/openssl@3.0.2"},
+		{ReferenceCategory: "SECURITY", ReferenceType: "cpe23Type", ReferenceLocator: "cpe:2.3:a:openssl:openssl:3.0.2:*:*:*:*:*:*:*"},
+		{ReferenceCategory: "SECURITY", ReferenceType: "advisory", ReferenceLocator: "https://nvd.nist.gov/vuln/detail/CVE-2024-0002"},
+		{ReferenceCategory: "SECURITY", ReferenceType: "advisory", ReferenceLocator: "https://nvd.nist.gov/vuln/detail/CVE-2024-0001"},
+	}, doc.Packages[0].ExternalRefs)
+	require.Equal(t, "Organization: Slack", doc.Packages[2].Supplier)
+	require.Empty(t, doc.Packages[2].ExternalRefs)
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("SPDX")
+	require.NoError(t, err)
+	require.Equal(t, FormatSPDX, f)
+
+	f, err = ParseFormat("cyclonedx")
+	require.NoError(t, err)
+	require.Equal(t, FormatCycloneDX, f)
+
+	_, err = ParseFormat("swid")
+	require.Error(t, err)
+}
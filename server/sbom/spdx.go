@@ -0,0 +1,143 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+const (
+	spdxVersion      = "SPDX-2.3"
+	spdxDocumentID   = "SPDXRef-DOCUMENT"
+	spdxNoAssertion  = "NOASSERTION"
+	spdxDataLicense  = "CC0-1.0"
+	spdxDefaultSpace = "https://mdmlab.invalid/sbom"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	Supplier         string            `json:"supplier,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func generateSPDX(subject Subject, software []mdmlab.Software, opts Options) ([]byte, error) {
+	namespace := strings.TrimSuffix(opts.Namespace, "/")
+	if namespace == "" {
+		namespace = spdxDefaultSpace
+	} else {
+		namespace += "/sbom"
+	}
+
+	creators := []string{"Tool: mdmlab"}
+	if opts.ToolVersion != "" {
+		creators[0] += "-" + opts.ToolVersion
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            spdxDocumentID,
+		Name:              subject.Name,
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", namespace, spdxIDString(subject.Name), uuid.NewString()),
+		CreationInfo: spdxCreationInfo{
+			Created:  opts.Now.UTC().Format(time.RFC3339),
+			Creators: creators,
+		},
+		Packages:      make([]spdxPackage, 0, len(software)),
+		Relationships: make([]spdxRelationship, 0, len(software)),
+	}
+
+	for _, s := range software {
+		pkg := spdxPackage{
+			Name:             s.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", s.ID),
+			VersionInfo:      s.Version,
+			DownloadLocation: spdxNoAssertion,
+			Comment:          "source: " + s.Source,
+		}
+		if s.Vendor != "" {
+			pkg.Supplier = "Organization: " + s.Vendor
+		}
+		if purl := PURL(s, subject.Distro); purl != "" {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  purl,
+			})
+		}
+		if s.GenerateCPE != "" {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "SECURITY",
+				ReferenceType:     "cpe23Type",
+				ReferenceLocator:  s.GenerateCPE,
+			})
+		}
+		for _, cve := range s.Vulnerabilities {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "SECURITY",
+				ReferenceType:     "advisory",
+				ReferenceLocator:  nvdDetailsURL(cve.CVE),
+			})
+		}
+
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal SPDX document: %w", err)
+	}
+	return b, nil
+}
+
+// spdxIDString replaces the characters not allowed in SPDX identifiers and URIs.
+func spdxIDString(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+}
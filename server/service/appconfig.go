@@ -15,6 +15,7 @@ import (
 	"net/url"
 
 	eeservice "github.com/it-laborato/MDM_Lab/ee/server/service"
+	"github.com/it-laborato/MDM_Lab/pkg/file"
 	"github.com/it-laborato/MDM_Lab/pkg/optjson"
 	"github.com/it-laborato/MDM_Lab/pkg/rawjson"
 	"github.com/it-laborato/MDM_Lab/server/authz"
@@ -174,12 +175,13 @@ func getAppConfigEndpoint(ctx context.Context, request interface{}, svc mdmlab.S
 	features := appConfig.Features
 	response := appConfigResponse{
 		AppConfig: mdmlab.AppConfig{
-			OrgInfo:                appConfig.OrgInfo,
-			ServerSettings:         appConfig.ServerSettings,
-			Features:               features,
-			VulnerabilitySettings:  appConfig.VulnerabilitySettings,
-			HostExpirySettings:     appConfig.HostExpirySettings,
-			ActivityExpirySettings: appConfig.ActivityExpirySettings,
+			OrgInfo:                   appConfig.OrgInfo,
+			ServerSettings:            appConfig.ServerSettings,
+			Features:                  features,
+			VulnerabilitySettings:     appConfig.VulnerabilitySettings,
+			SoftwareInstallerSettings: appConfig.SoftwareInstallerSettings,
+			HostExpirySettings:        appConfig.HostExpirySettings,
+			ActivityExpirySettings:    appConfig.ActivityExpirySettings,
 
 			SMTPSettings: smtpSettings,
 			SSOSettings:  ssoSettings,
@@ -511,6 +513,19 @@ func (svc *Service) ModifyAppConfig(ctx context.Context, p []byte, applyOpts mdm
 	mdmlab.ValidateEnabledVulnerabilitiesIntegrations(appConfig.WebhookSettings.VulnerabilitiesWebhook, appConfig.Integrations, invalid)
 	mdmlab.ValidateEnabledFailingPoliciesIntegrations(appConfig.WebhookSettings.FailingPoliciesWebhook, appConfig.Integrations, invalid)
 	mdmlab.ValidateEnabledHostStatusIntegrations(appConfig.WebhookSettings.HostStatusWebhook, invalid)
+	mdmlab.ValidateEnabledCertificateExpirationWebhook(appConfig.WebhookSettings.CertificateExpirationWebhook, invalid)
+	mdmlab.ValidateEnabledSoftwareRequestsWebhook(appConfig.WebhookSettings.SoftwareRequestsWebhook, invalid)
+	mdmlab.ValidateVulnerabilitySLARules(appConfig.VulnerabilitySettings.SLARules, invalid)
+	if !appConfig.VulnerabilitySettings.PreferredCVSSScore.IsValid() {
+		invalid.Appendf("vulnerability_settings.preferred_cvss_score", "invalid preferred CVSS score %q", appConfig.VulnerabilitySettings.PreferredCVSSScore)
+	}
+	if _, err := file.ReadGPGKeyRing(appConfig.SoftwareInstallerSettings.TrustedGPGKeys); err != nil {
+		invalid.Appendf("software_installer_settings.trusted_gpg_keys", "invalid GPG public key: %s", err)
+	}
+	mdmlab.ValidateSoftwareInstallerCaches(appConfig.SoftwareInstallerSettings.Caches, invalid)
+	if appConfig.WebhookSettings.VulnerabilitiesWebhook.EnableSLABreachAlerts && len(appConfig.VulnerabilitySettings.SLARules) == 0 {
+		invalid.Append("enable_sla_breach_alerts", "vulnerability_settings.sla_rules are required to enable the SLA breach alerts")
+	}
 	mdmlab.ValidateEnabledActivitiesWebhook(appConfig.WebhookSettings.ActivitiesWebhook, invalid)
 
 	if err := svc.validateMDM(ctx, license, &oldAppConfig.MDM, &appConfig.MDM, invalid); err != nil {
@@ -998,6 +1013,17 @@ func (svc *Service) validateMDM(
 	if mdm.WindowsMigrationEnabled && !license.IsPremium() {
 		invalid.Append("windows_migration_enabled", ErrMissingLicense.Error())
 	}
+	if rl := mdm.MacOSSettings.RecoveryLock; rl != nil {
+		if rl.Enable && !license.IsPremium() {
+			invalid.Append("macos_settings.recovery_lock", ErrMissingLicense.Error())
+		}
+		if rl.Enable && svc.config.Server.PrivateKey == "" {
+			invalid.Append("macos_settings.recovery_lock", "Missing required private key. Learn how to configure the private key here: https://mdmlabdm.com/learn-more-about/mdmlab-server-private-key")
+		}
+		if err := rl.Validate(); err != nil {
+			invalid.Append("macos_settings.recovery_lock", err.Error())
+		}
+	}
 
 	// we want to use `oldMdm` here as this boolean is set by the mdmlab
 	// server at startup and can't be modified by the user
@@ -1007,6 +1033,11 @@ func (svc *Service) validateMDM(
 				`Couldn't update macos_settings because MDM features aren't turned on in MDMlab. Use mdmlabctl generate mdm-apple and then mdmlab serve with mdm configuration to turn on MDM features.`)
 		}
 
+		if mdm.MacOSSettings.RecoveryLockEnabled() && !oldMdm.MacOSSettings.RecoveryLockEnabled() {
+			invalid.Append("macos_settings.recovery_lock",
+				`Couldn't update macos_settings because MDM features aren't turned on in MDMlab. Use mdmlabctl generate mdm-apple and then mdmlab serve with mdm configuration to turn on MDM features.`)
+		}
+
 		if mdm.MacOSSetup.MacOSSetupAssistant.Value != "" && oldMdm.MacOSSetup.MacOSSetupAssistant.Value != mdm.MacOSSetup.MacOSSetupAssistant.Value {
 			invalid.Append("macos_setup.macos_setup_assistant",
 				`Couldn't update macos_setup because MDM features aren't turned on in MDMlab. Use mdmlabctl generate mdm-apple and then mdmlab serve with mdm configuration to turn on MDM features.`)
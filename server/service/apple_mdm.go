@@ -287,6 +287,253 @@ func (svc *Service) ListMDMAppleCommands(ctx context.Context, opts *mdmlab.MDMCo
 	return results, nil
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// GET /mdm/apple/commands/queue
+////////////////////////////////////////////////////////////////////////////////
+
+type listMDMAppleCommandQueueRequest struct {
+	ListOptions    mdmlab.ListOptions `url:"list_options"`
+	HostIdentifier string             `query:"host_identifier,optional"`
+	RequestType    string             `query:"request_type,optional"`
+	CommandUUID    string             `query:"command_uuid,optional"`
+	Status         string             `query:"status,optional"`
+}
+
+type listMDMAppleCommandQueueResponse struct {
+	Results []*mdmlab.MDMAppleCommandQueueItem `json:"results"`
+	Err     error                              `json:"error,omitempty"`
+}
+
+func (r listMDMAppleCommandQueueResponse) error() error { return r.Err }
+
+func listMDMAppleCommandQueueEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listMDMAppleCommandQueueRequest)
+	results, err := svc.ListMDMAppleCommandQueue(ctx, &mdmlab.MDMAppleCommandQueueListOptions{
+		ListOptions:    req.ListOptions,
+		HostIdentifier: req.HostIdentifier,
+		RequestType:    req.RequestType,
+		CommandUUID:    req.CommandUUID,
+		Status:         mdmlab.MDMAppleCommandQueueStatus(req.Status),
+	})
+	if err != nil {
+		return listMDMAppleCommandQueueResponse{Err: err}, nil
+	}
+	return listMDMAppleCommandQueueResponse{Results: results}, nil
+}
+
+func (svc *Service) ListMDMAppleCommandQueue(ctx context.Context, opts *mdmlab.MDMAppleCommandQueueListOptions) ([]*mdmlab.MDMAppleCommandQueueItem, error) {
+	// first, authorize that the user has the right to list hosts
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, ctxerr.Wrap(ctx, err)
+	}
+
+	if opts.Status != "" && !opts.Status.IsValid() {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("status",
+			"must be one of Pending, Acknowledged, Error, CommandFormatError or NotNow"))
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	results, err := svc.ds.ListMDMAppleCommandQueue(ctx, mdmlab.TeamFilter{
+		User:            vc.User,
+		IncludeObserver: true,
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// as for ListMDMAppleCommands, always verify with our rego authz policy
+	// that the user can view the commands of the returned hosts' teams, and
+	// filter out those that are not allowed.
+	allowedTeams := make(map[uint]bool)
+	var commandAuthz mdmlab.MDMCommandAuthz
+	allowedResults := make([]*mdmlab.MDMAppleCommandQueueItem, 0, len(results))
+	for _, res := range results {
+		var tmID uint
+		if res.TeamID != nil {
+			tmID = *res.TeamID
+		}
+		allowed, checked := allowedTeams[tmID]
+		if !checked {
+			commandAuthz.TeamID = res.TeamID
+			if err := svc.authz.Authorize(ctx, commandAuthz, mdmlab.ActionRead); err != nil {
+				level.Error(svc.logger).Log("err", "unauthorized to view some team commands", "details", err)
+			} else {
+				allowed = true
+			}
+			allowedTeams[tmID] = allowed
+		}
+		if allowed {
+			allowedResults = append(allowedResults, res)
+		}
+	}
+
+	if len(allowedResults) == 0 && opts.HostIdentifier != "" {
+		_, err := svc.ds.HostLiteByIdentifier(ctx, opts.HostIdentifier)
+		var nve mdmlab.NotFoundError
+		if errors.As(err, &nve) {
+			return nil, mdmlab.NewInvalidArgumentError("Invalid Host", mdmlab.HostIdentiferNotFound).WithStatus(http.StatusNotFound)
+		}
+	}
+
+	return allowedResults, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// POST /mdm/apple/commands/cancel
+////////////////////////////////////////////////////////////////////////////////
+
+type cancelMDMAppleCommandsRequest struct {
+	HostUUIDs   []string `json:"host_uuids"`
+	CommandUUID string   `json:"command_uuid"`
+}
+
+type cancelMDMAppleCommandsResponse struct {
+	Canceled int64 `json:"canceled"`
+	Err      error `json:"error,omitempty"`
+}
+
+func (r cancelMDMAppleCommandsResponse) error() error { return r.Err }
+
+func cancelMDMAppleCommandsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*cancelMDMAppleCommandsRequest)
+	n, err := svc.CancelMDMAppleCommands(ctx, req.HostUUIDs, req.CommandUUID)
+	if err != nil {
+		return cancelMDMAppleCommandsResponse{Err: err}, nil
+	}
+	return cancelMDMAppleCommandsResponse{Canceled: n}, nil
+}
+
+func (svc *Service) CancelMDMAppleCommands(ctx context.Context, hostUUIDs []string, commandUUID string) (int64, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionSelectiveList); err != nil {
+		return 0, ctxerr.Wrap(ctx, err)
+	}
+
+	if len(hostUUIDs) == 0 && commandUUID == "" {
+		return 0, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("host_uuids",
+			"at least one host UUID or a command UUID must be provided"))
+	}
+
+	if commandUUID != "" {
+		requestType, err := svc.ds.GetMDMAppleCommandRequestType(ctx, commandUUID)
+		if err != nil && !mdmlab.IsNotFound(err) {
+			return 0, ctxerr.Wrap(ctx, err, "get command request type")
+		}
+		if !mdmlab.IsMDMAppleCommandCancelable(requestType) {
+			return 0, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("command_uuid",
+				fmt.Sprintf("%s commands can't be canceled, their status is managed by MDMlab.", requestType)))
+		}
+	}
+
+	hosts, err := svc.pendingMDMAppleCommandsHosts(ctx, hostUUIDs, commandUUID, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(hosts) == 0 {
+		return 0, nil
+	}
+
+	uuids := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		uuids = append(uuids, h.UUID)
+	}
+	n, err := svc.mdmAppleCommander.CancelPendingCommands(ctx, uuids, commandUUID)
+	if err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "cancel pending commands")
+	}
+
+	if err := svc.NewActivity(ctx, authz.UserFromContext(ctx), mdmlab.ActivityTypeCanceledMDMAppleCommands{
+		CommandUUID: commandUUID,
+		HostCount:   len(hosts),
+		Canceled:    n,
+	}); err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "create activity for canceled commands")
+	}
+	return n, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// POST /mdm/apple/commands/push
+////////////////////////////////////////////////////////////////////////////////
+
+type resendMDMApplePushNotificationsRequest struct {
+	HostUUIDs      []string   `json:"host_uuids"`
+	LastSeenBefore *time.Time `json:"last_seen_before"`
+}
+
+type resendMDMApplePushNotificationsResponse struct {
+	*mdmlab.MDMAppleCommandQueuePushResult
+	Err error `json:"error,omitempty"`
+}
+
+func (r resendMDMApplePushNotificationsResponse) error() error { return r.Err }
+
+func resendMDMApplePushNotificationsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*resendMDMApplePushNotificationsRequest)
+	res, err := svc.ResendMDMApplePushNotifications(ctx, req.HostUUIDs, req.LastSeenBefore)
+	if err != nil {
+		return resendMDMApplePushNotificationsResponse{Err: err}, nil
+	}
+	return resendMDMApplePushNotificationsResponse{MDMAppleCommandQueuePushResult: res}, nil
+}
+
+func (svc *Service) ResendMDMApplePushNotifications(ctx context.Context, hostUUIDs []string, lastSeenBefore *time.Time) (*mdmlab.MDMAppleCommandQueuePushResult, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionSelectiveList); err != nil {
+		return nil, ctxerr.Wrap(ctx, err)
+	}
+
+	hosts, err := svc.pendingMDMAppleCommandsHosts(ctx, hostUUIDs, "", lastSeenBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &mdmlab.MDMAppleCommandQueuePushResult{
+		HostUUIDs:       make([]string, 0, len(hosts)),
+		FailedHostUUIDs: []string{},
+	}
+	for _, h := range hosts {
+		res.HostUUIDs = append(res.HostUUIDs, h.UUID)
+	}
+	if len(res.HostUUIDs) == 0 {
+		return res, nil
+	}
+
+	if err := svc.mdmAppleCommander.SendNotifications(ctx, res.HostUUIDs); err != nil {
+		// a failure to deliver to some of the hosts is reported in the result,
+		// the push was still sent to the others.
+		var apnsErr *apple_mdm.APNSDeliveryError
+		if !errors.As(err, &apnsErr) {
+			return nil, ctxerr.Wrap(ctx, err, "send push notifications")
+		}
+		res.FailedHostUUIDs = apnsErr.FailedUUIDs()
+		level.Info(svc.logger).Log("msg", "failed to deliver some push notifications", "err", err)
+	}
+	return res, nil
+}
+
+// pendingMDMAppleCommandsHosts returns the hosts that have pending Apple MDM
+// commands matching the provided criteria, after verifying that the user can
+// execute MDM commands on all of their teams.
+func (svc *Service) pendingMDMAppleCommandsHosts(ctx context.Context, hostUUIDs []string, commandUUID string,
+	lastSeenBefore *time.Time,
+) ([]*mdmlab.Host, error) {
+	// as in authorizeAllHostsTeams, use a global admin as filter so that the
+	// authorization check is done on all the hosts matching the criteria, not
+	// just the subset the user can view.
+	filter := mdmlab.TeamFilter{User: &mdmlab.User{GlobalRole: ptr.String(mdmlab.RoleAdmin)}}
+	hosts, err := svc.ds.ListMDMAppleHostsWithPendingCommands(ctx, filter, hostUUIDs, commandUUID, lastSeenBefore)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list hosts with pending commands")
+	}
+	if err := svc.authorizeHostsTeams(ctx, hosts, mdmlab.ActionWrite, &mdmlab.MDMCommandAuthz{}); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
 type newMDMAppleConfigProfileRequest struct {
 	TeamID  uint
 	Profile *multipart.FileHeader
@@ -1892,6 +2139,263 @@ func (svc *Service) MDMAppleEraseDevice(ctx context.Context, hostID uint) error
 	return mdmlab.ErrMissingLicense
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Restart a device
+////////////////////////////////////////////////////////////////////////////////
+
+type restartHostRequest struct {
+	HostID     uint `url:"id"`
+	NotifyUser bool `json:"notify_user"`
+}
+
+type restartHostResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r restartHostResponse) error() error { return r.Err }
+
+func (r restartHostResponse) Status() int { return http.StatusNoContent }
+
+func restartHostEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*restartHostRequest)
+	if err := svc.RestartHost(ctx, req.HostID, req.NotifyUser); err != nil {
+		return restartHostResponse{Err: err}, nil
+	}
+	return restartHostResponse{}, nil
+}
+
+func (svc *Service) RestartHost(ctx context.Context, hostID uint, notifyUser bool) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Shut down a device
+////////////////////////////////////////////////////////////////////////////////
+
+type shutDownHostRequest struct {
+	HostID uint `url:"id"`
+}
+
+type shutDownHostResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r shutDownHostResponse) error() error { return r.Err }
+
+func (r shutDownHostResponse) Status() int { return http.StatusNoContent }
+
+func shutDownHostEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*shutDownHostRequest)
+	if err := svc.ShutDownHost(ctx, req.HostID); err != nil {
+		return shutDownHostResponse{Err: err}, nil
+	}
+	return shutDownHostResponse{}, nil
+}
+
+func (svc *Service) ShutDownHost(ctx context.Context, hostID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Enable and disable lost mode
+////////////////////////////////////////////////////////////////////////////////
+
+type enableHostLostModeRequest struct {
+	HostID uint `url:"id"`
+	mdmlab.MDMAppleLostModeOptions
+}
+
+type enableHostLostModeResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r enableHostLostModeResponse) error() error { return r.Err }
+
+func (r enableHostLostModeResponse) Status() int { return http.StatusNoContent }
+
+func enableHostLostModeEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*enableHostLostModeRequest)
+	if err := svc.EnableHostLostMode(ctx, req.HostID, req.MDMAppleLostModeOptions); err != nil {
+		return enableHostLostModeResponse{Err: err}, nil
+	}
+	return enableHostLostModeResponse{}, nil
+}
+
+func (svc *Service) EnableHostLostMode(ctx context.Context, hostID uint, opts mdmlab.MDMAppleLostModeOptions) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+type disableHostLostModeRequest struct {
+	HostID uint `url:"id"`
+}
+
+type disableHostLostModeResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r disableHostLostModeResponse) error() error { return r.Err }
+
+func (r disableHostLostModeResponse) Status() int { return http.StatusNoContent }
+
+func disableHostLostModeEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*disableHostLostModeRequest)
+	if err := svc.DisableHostLostMode(ctx, req.HostID); err != nil {
+		return disableHostLostModeResponse{Err: err}, nil
+	}
+	return disableHostLostModeResponse{}, nil
+}
+
+func (svc *Service) DisableHostLostMode(ctx context.Context, hostID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Play a sound on a device in lost mode
+////////////////////////////////////////////////////////////////////////////////
+
+type playHostLostModeSoundRequest struct {
+	HostID uint `url:"id"`
+}
+
+type playHostLostModeSoundResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r playHostLostModeSoundResponse) error() error { return r.Err }
+
+func (r playHostLostModeSoundResponse) Status() int { return http.StatusNoContent }
+
+func playHostLostModeSoundEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*playHostLostModeSoundRequest)
+	if err := svc.PlayHostLostModeSound(ctx, req.HostID); err != nil {
+		return playHostLostModeSoundResponse{Err: err}, nil
+	}
+	return playHostLostModeSoundResponse{}, nil
+}
+
+func (svc *Service) PlayHostLostModeSound(ctx context.Context, hostID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Request the location of a device in lost mode
+////////////////////////////////////////////////////////////////////////////////
+
+type requestHostLocationRequest struct {
+	HostID uint `url:"id"`
+}
+
+type requestHostLocationResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r requestHostLocationResponse) error() error { return r.Err }
+
+func (r requestHostLocationResponse) Status() int { return http.StatusAccepted }
+
+func requestHostLocationEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*requestHostLocationRequest)
+	if err := svc.RequestHostLocation(ctx, req.HostID); err != nil {
+		return requestHostLocationResponse{Err: err}, nil
+	}
+	return requestHostLocationResponse{}, nil
+}
+
+func (svc *Service) RequestHostLocation(ctx context.Context, hostID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Set or clear the recovery lock of a device
+////////////////////////////////////////////////////////////////////////////////
+
+type setHostRecoveryLockRequest struct {
+	HostID          uint   `url:"id"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type setHostRecoveryLockResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r setHostRecoveryLockResponse) error() error { return r.Err }
+
+func (r setHostRecoveryLockResponse) Status() int { return http.StatusNoContent }
+
+func setHostRecoveryLockEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*setHostRecoveryLockRequest)
+	if err := svc.SetHostRecoveryLock(ctx, req.HostID, req.CurrentPassword, req.NewPassword); err != nil {
+		return setHostRecoveryLockResponse{Err: err}, nil
+	}
+	return setHostRecoveryLockResponse{}, nil
+}
+
+func (svc *Service) SetHostRecoveryLock(ctx context.Context, hostID uint, currentPassword, newPassword string) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Reveal the managed recovery lock password of a device
+////////////////////////////////////////////////////////////////////////////////
+
+type getHostRecoveryLockPasswordRequest struct {
+	HostID uint `url:"id"`
+}
+
+type getHostRecoveryLockPasswordResponse struct {
+	HostID               uint                     `json:"host_id,omitempty"`
+	RecoveryLockPassword *mdmlab.HostRecoveryLock `json:"recovery_lock_password,omitempty"`
+	Err                  error                    `json:"error,omitempty"`
+}
+
+func (r getHostRecoveryLockPasswordResponse) error() error { return r.Err }
+
+func getHostRecoveryLockPasswordEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getHostRecoveryLockPasswordRequest)
+	lock, err := svc.GetHostRecoveryLockPassword(ctx, req.HostID)
+	if err != nil {
+		return getHostRecoveryLockPasswordResponse{Err: err}, nil
+	}
+	return getHostRecoveryLockPasswordResponse{HostID: req.HostID, RecoveryLockPassword: lock}, nil
+}
+
+func (svc *Service) GetHostRecoveryLockPassword(ctx context.Context, hostID uint) (*mdmlab.HostRecoveryLock, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Get profiles assigned to a host
 ////////////////////////////////////////////////////////////////////////////////
@@ -2964,7 +3468,7 @@ func (svc *MDMAppleCheckinAndCommandService) CommandAndReportResults(r *mdm.Requ
 			Detail:        apple_mdm.FmtErrorChain(cmdResult.ErrorChain),
 			OperationType: mdmlab.MDMOperationTypeRemove,
 		})
-	case "DeviceLock", "EraseDevice":
+	case "DeviceLock", "EraseDevice", "EnableLostMode", "DisableLostMode":
 		// call into our datastore to update host_mdm_actions if the status is terminal
 		if cmdResult.Status == mdmlab.MDMAppleStatusAcknowledged ||
 			cmdResult.Status == mdmlab.MDMAppleStatusError ||
@@ -2972,6 +3476,30 @@ func (svc *MDMAppleCheckinAndCommandService) CommandAndReportResults(r *mdm.Requ
 			return nil, svc.ds.UpdateHostLockWipeStatusFromAppleMDMResult(r.Context, cmdResult.UDID, cmdResult.CommandUUID, requestType,
 				cmdResult.Status == mdmlab.MDMAppleStatusAcknowledged)
 		}
+	case "DeviceLocation":
+		if cmdResult.Status != mdmlab.MDMAppleStatusAcknowledged {
+			return nil, nil
+		}
+		loc, err := unmarshalDeviceLocation(cmdResult.Raw)
+		if err != nil {
+			return nil, ctxerr.Wrap(r.Context, err, "unmarshal DeviceLocation result")
+		}
+		loc.HostUUID = cmdResult.UDID
+		loc.CommandUUID = cmdResult.CommandUUID
+		return nil, ctxerr.Wrap(r.Context, svc.ds.SetHostMDMAppleDeviceLocation(r.Context, loc), "store device location")
+	case "SetRecoveryLock", "SetFirmwarePassword":
+		if err := svc.handleRecoveryLockResult(r.Context, cmdResult); err != nil {
+			return nil, err
+		}
+		if cmdResult.Status == mdmlab.MDMAppleStatusNotNow {
+			return nil, nil
+		}
+		// the command holds the passwords in plain text, don't keep them
+		// around once the command has been processed.
+		err := svc.ds.RedactMDMAppleCommandPayload(r.Context, cmdResult.CommandUUID)
+		return nil, ctxerr.Wrap(r.Context, err, "redact recovery lock command")
+	case "SecurityInfo":
+		return nil, svc.handleSecurityInfoResult(r.Context, cmdResult)
 	case "DeclarativeManagement":
 		// set "pending-install" profiles to "verifying" or "failed"
 		// depending on the status of the DeviceManagement command
@@ -3020,6 +3548,140 @@ func (svc *MDMAppleCheckinAndCommandService) CommandAndReportResults(r *mdm.Requ
 	return nil, nil
 }
 
+// unmarshalDeviceLocation parses the response to a [DeviceLocation][1]
+// command.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/devicelocationresponse
+func unmarshalDeviceLocation(raw []byte) (*mdmlab.HostMDMAppleDeviceLocation, error) {
+	var resp struct {
+		Latitude           float64
+		Longitude          float64
+		Altitude           *float64
+		HorizontalAccuracy *float64
+		VerticalAccuracy   *float64
+		Timestamp          string
+	}
+	if err := plist.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+
+	loc := &mdmlab.HostMDMAppleDeviceLocation{
+		Latitude:           resp.Latitude,
+		Longitude:          resp.Longitude,
+		Altitude:           resp.Altitude,
+		HorizontalAccuracy: resp.HorizontalAccuracy,
+		VerticalAccuracy:   resp.VerticalAccuracy,
+		LocatedAt:          time.Now().UTC(),
+	}
+	if resp.Timestamp != "" {
+		ts, err := time.Parse(time.RFC3339, resp.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("parse location timestamp: %w", err)
+		}
+		loc.LocatedAt = ts.UTC()
+	}
+	return loc, nil
+}
+
+// handleRecoveryLockResult processes the result of a command sent to set the
+// managed recovery lock of a host. If the host accepted it, a SecurityInfo
+// command is sent to verify that the password is in place.
+func (svc *MDMAppleCheckinAndCommandService) handleRecoveryLockResult(ctx context.Context, cmdResult *mdm.CommandResults) error {
+	lock, err := svc.ds.GetHostRecoveryLock(ctx, cmdResult.UDID)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			return nil
+		}
+		return ctxerr.Wrap(ctx, err, "get host recovery lock")
+	}
+	if lock.CommandUUID != cmdResult.CommandUUID {
+		// the command was not sent by the managed recovery lock, e.g. it was
+		// sent manually via the API.
+		return nil
+	}
+
+	switch cmdResult.Status {
+	case mdmlab.MDMAppleStatusAcknowledged:
+		verifyUUID := uuid.NewString()
+		if err := svc.ds.SetHostRecoveryLockVerifying(ctx, cmdResult.UDID, verifyUUID); err != nil {
+			return ctxerr.Wrap(ctx, err, "set host recovery lock verifying")
+		}
+		if err := svc.commander.SecurityInfo(ctx, []string{cmdResult.UDID}, verifyUUID); err != nil {
+			return ctxerr.Wrap(ctx, err, "send SecurityInfo to verify recovery lock")
+		}
+	case mdmlab.MDMAppleStatusError, mdmlab.MDMAppleStatusCommandFormatError:
+		err := svc.ds.SetHostRecoveryLockFailed(ctx, cmdResult.UDID, apple_mdm.FmtErrorChain(cmdResult.ErrorChain),
+			time.Now().Add(mdmlab.RecoveryLockRetryInterval))
+		return ctxerr.Wrap(ctx, err, "set host recovery lock failed")
+	}
+	return nil
+}
+
+// handleSecurityInfoResult processes the result of a SecurityInfo command
+// sent to verify the managed recovery lock of a host.
+func (svc *MDMAppleCheckinAndCommandService) handleSecurityInfoResult(ctx context.Context, cmdResult *mdm.CommandResults) error {
+	lock, err := svc.ds.GetHostRecoveryLock(ctx, cmdResult.UDID)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			return nil
+		}
+		return ctxerr.Wrap(ctx, err, "get host recovery lock")
+	}
+	if lock.VerifyCommandUUID == nil || *lock.VerifyCommandUUID != cmdResult.CommandUUID {
+		return nil
+	}
+
+	retryAt := time.Now().Add(mdmlab.RecoveryLockRetryInterval)
+	switch cmdResult.Status {
+	case mdmlab.MDMAppleStatusAcknowledged:
+		info, err := unmarshalSecurityInfo(cmdResult.Raw)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "unmarshal SecurityInfo result")
+		}
+		if !info.hasRecoveryLock(lock.PasswordType) {
+			err := svc.ds.SetHostRecoveryLockFailed(ctx, cmdResult.UDID, "The host reported that the password is not set.", retryAt)
+			return ctxerr.Wrap(ctx, err, "set host recovery lock failed")
+		}
+		return ctxerr.Wrap(ctx, svc.ds.SetHostRecoveryLockVerified(ctx, cmdResult.UDID), "set host recovery lock verified")
+	case mdmlab.MDMAppleStatusError, mdmlab.MDMAppleStatusCommandFormatError:
+		err := svc.ds.SetHostRecoveryLockFailed(ctx, cmdResult.UDID, apple_mdm.FmtErrorChain(cmdResult.ErrorChain), retryAt)
+		return ctxerr.Wrap(ctx, err, "set host recovery lock failed")
+	}
+	return nil
+}
+
+// securityInfo contains the fields of the [SecurityInfo][1] response used to
+// verify the managed recovery lock.
+//
+// [1]: https://developer.apple.com/documentation/devicemanagement/securityinforesponse/securityinfo
+type securityInfo struct {
+	IsRecoveryLockEnabled  bool
+	FirmwarePasswordStatus struct {
+		PasswordExists bool
+		ChangePending  bool
+	}
+}
+
+// hasRecoveryLock returns true if the password of the given type is set. A
+// firmware password change is only applied after the host restarts, so a
+// pending change is considered set.
+func (si securityInfo) hasRecoveryLock(passwordType string) bool {
+	if passwordType == mdmlab.RecoveryLockPasswordTypeRecoveryLock {
+		return si.IsRecoveryLockEnabled
+	}
+	return si.FirmwarePasswordStatus.PasswordExists || si.FirmwarePasswordStatus.ChangePending
+}
+
+func unmarshalSecurityInfo(raw []byte) (*securityInfo, error) {
+	var resp struct {
+		SecurityInfo securityInfo
+	}
+	if err := plist.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.SecurityInfo, nil
+}
+
 func (svc *MDMAppleCheckinAndCommandService) handleRefetch(r *mdm.Request, cmdResult *mdm.CommandResults) (*mdm.Command, error) {
 	ctx := r.Context
 	host, err := svc.ds.HostByIdentifier(ctx, cmdResult.UDID)
@@ -3027,6 +3689,11 @@ func (svc *MDMAppleCheckinAndCommandService) handleRefetch(r *mdm.Request, cmdRe
 		return nil, ctxerr.Wrap(ctx, err, "failed to get host by identifier")
 	}
 
+	if strings.HasPrefix(cmdResult.CommandUUID, mdmlab.RefetchCertificatesCommandUUIDPrefix) ||
+		strings.HasPrefix(cmdResult.CommandUUID, mdmlab.RefetchManagedCertificatesCommandUUIDPrefix) {
+		return nil, svc.handleCertificatesRefetch(ctx, host, cmdResult)
+	}
+
 	if strings.HasPrefix(cmdResult.CommandUUID, mdmlab.RefetchAppsCommandUUIDPrefix) {
 		// We remove pending command first in case there is an error processing the results, so that we don't prevent another refetch.
 		err = svc.ds.RemoveHostMDMCommand(ctx, mdmlab.HostMDMCommand{
@@ -3332,6 +3999,130 @@ func ReconcileAppleDeclarations(
 	return nil
 }
 
+const (
+	// recoveryLockBatchSize is the maximum number of hosts per team for which
+	// the recovery lock is set or rotated on each run of the job.
+	recoveryLockBatchSize = 500
+	// recoveryLockPasswordLength is the length of the generated recovery lock
+	// and firmware passwords.
+	recoveryLockPasswordLength = 20
+)
+
+// ReconcileAppleRecoveryLocks sets a random recovery lock (Apple silicon) or
+// firmware password (Intel) on the macOS hosts of the teams that enforce it,
+// and rotates the ones that are due. The result of the commands is processed
+// in CommandAndReportResults.
+func ReconcileAppleRecoveryLocks(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	commander *apple_mdm.MDMAppleCommander,
+	logger kitlog.Logger,
+) error {
+	appConfig, err := ds.AppConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("reading app config: %w", err)
+	}
+	if !appConfig.MDM.EnabledAndConfigured {
+		return nil
+	}
+
+	type teamRecoveryLock struct {
+		teamID   *uint
+		settings mdmlab.MacOSRecoveryLock
+	}
+	var enforced []teamRecoveryLock
+	if appConfig.MDM.MacOSSettings.RecoveryLockEnabled() {
+		enforced = append(enforced, teamRecoveryLock{settings: *appConfig.MDM.MacOSSettings.RecoveryLock})
+	}
+
+	teams, err := ds.TeamsSummary(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list teams")
+	}
+	for _, tm := range teams {
+		tmConfig, err := ds.TeamMDMConfig(ctx, tm.ID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "get team mdm config")
+		}
+		if tmConfig.MacOSSettings.RecoveryLockEnabled() {
+			enforced = append(enforced, teamRecoveryLock{teamID: ptr.Uint(tm.ID), settings: *tmConfig.MacOSSettings.RecoveryLock})
+		}
+	}
+
+	var count int
+	for _, tm := range enforced {
+		hosts, err := ds.ListHostsPendingRecoveryLockRotation(ctx, tm.teamID, recoveryLockBatchSize)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "list hosts pending recovery lock rotation")
+		}
+		for _, h := range hosts {
+			if err := setHostRecoveryLock(ctx, ds, commander, h, tm.settings.RotationInterval()); err != nil {
+				// log and continue with the other hosts, a failed host is
+				// retried later.
+				level.Error(logger).Log("msg", "failed to set host recovery lock", "host_uuid", h.HostUUID, "err", err)
+				ctxerr.Handle(ctx, err)
+				continue
+			}
+			count++
+		}
+	}
+
+	if count > 0 {
+		level.Info(logger).Log("msg", "sent recovery lock commands", "host_number", count)
+	}
+	return nil
+}
+
+// setHostRecoveryLock generates a new password for the host, escrows it as
+// pending and sends the command to set it.
+func setHostRecoveryLock(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	commander *apple_mdm.MDMAppleCommander,
+	host *mdmlab.HostRecoveryLockTarget,
+	rotationInterval time.Duration,
+) error {
+	var currentPassword string
+	lock, err := ds.GetHostRecoveryLock(ctx, host.HostUUID)
+	switch {
+	case err == nil:
+		currentPassword = lock.Password
+	case !mdmlab.IsNotFound(err):
+		return ctxerr.Wrap(ctx, err, "get host recovery lock")
+	}
+
+	newPassword, err := apple_mdm.GenerateRecoveryLockPassword(recoveryLockPasswordLength)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "generate recovery lock password")
+	}
+
+	var rotateAt *time.Time
+	if rotationInterval > 0 {
+		rotateAt = ptr.Time(time.Now().Add(rotationInterval))
+	}
+
+	// escrow the password before sending the command so that it's never lost
+	// if the host applies it.
+	cmdUUID := uuid.NewString()
+	passwordType := host.PasswordType()
+	if err := ds.SetHostRecoveryLockPending(ctx, host.HostUUID, passwordType, newPassword, cmdUUID, rotateAt); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host recovery lock pending")
+	}
+
+	if passwordType == mdmlab.RecoveryLockPasswordTypeRecoveryLock {
+		err = commander.SetRecoveryLock(ctx, []string{host.HostUUID}, cmdUUID, currentPassword, newPassword)
+	} else {
+		err = commander.SetFirmwarePassword(ctx, []string{host.HostUUID}, cmdUUID, currentPassword, newPassword)
+	}
+	if err != nil {
+		if failErr := ds.SetHostRecoveryLockFailed(ctx, host.HostUUID, err.Error(), time.Now().Add(mdmlab.RecoveryLockRetryInterval)); failErr != nil {
+			return ctxerr.Wrap(ctx, failErr, "set host recovery lock failed")
+		}
+		return ctxerr.Wrap(ctx, err, "enqueue recovery lock command")
+	}
+	return nil
+}
+
 // install/removeTargets are maps from profileUUID -> command uuid and host
 // UUIDs as the underlying MDM services are optimized to send one command to
 // multiple hosts at the same time. Note that the same command uuid is used
@@ -40,6 +40,7 @@ import (
 	nanodep_client "github.com/it-laborato/MDM_Lab/server/mdm/nanodep/client"
 	"github.com/it-laborato/MDM_Lab/server/mdm/nanodep/tokenpki"
 	"github.com/it-laborato/MDM_Lab/server/mdm/nanomdm/mdm"
+	"github.com/it-laborato/MDM_Lab/server/mdm/nanomdm/push"
 	nanomdm_pushsvc "github.com/it-laborato/MDM_Lab/server/mdm/nanomdm/push/service"
 	"github.com/it-laborato/MDM_Lab/server/mock"
 	mdmmock "github.com/it-laborato/MDM_Lab/server/mock/mdm"
@@ -4011,6 +4012,274 @@ func TestUnmarshalAppList(t *testing.T) {
 	assert.ElementsMatch(t, expectedSoftware, software)
 }
 
+func newRecoveryLockTestCommander(t *testing.T) (*apple_mdm.MDMAppleCommander, *mdmmock.MDMAppleStore) {
+	mdmStorage := &mdmmock.MDMAppleStore{}
+	pushFactory, _ := newMockAPNSPushProviderFactory()
+	pusher := nanomdm_pushsvc.New(
+		mdmStorage,
+		mdmStorage,
+		pushFactory,
+		NewNanoMDMLogger(kitlog.NewNopLogger()),
+	)
+	mdmStorage.RetrievePushInfoFunc = func(ctx context.Context, tokens []string) (map[string]*mdm.Push, error) {
+		res := make(map[string]*mdm.Push, len(tokens))
+		for _, t := range tokens {
+			res[t] = &mdm.Push{Token: []byte(t)}
+		}
+		return res, nil
+	}
+	mdmStorage.RetrievePushCertFunc = func(ctx context.Context, topic string) (*tls.Certificate, string, error) {
+		cert, err := tls.LoadX509KeyPair("testdata/server.pem", "testdata/server.key")
+		return &cert, "", err
+	}
+	mdmStorage.IsPushCertStaleFunc = func(ctx context.Context, topic string, staleToken string) (bool, error) {
+		return false, nil
+	}
+	return apple_mdm.NewMDMAppleCommander(mdmStorage, pusher), mdmStorage
+}
+
+func TestReconcileAppleRecoveryLocks(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	cmdr, mdmStorage := newRecoveryLockTestCommander(t)
+
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		appCfg := &mdmlab.AppConfig{}
+		appCfg.MDM.EnabledAndConfigured = true
+		appCfg.MDM.MacOSSettings.RecoveryLock = &mdmlab.MacOSRecoveryLock{Enable: true, RotationIntervalDays: 30}
+		return appCfg, nil
+	}
+	ds.TeamsSummaryFunc = func(ctx context.Context) ([]*mdmlab.TeamSummary, error) {
+		return []*mdmlab.TeamSummary{{ID: 1}, {ID: 2}}, nil
+	}
+	ds.TeamMDMConfigFunc = func(ctx context.Context, teamID uint) (*mdmlab.TeamMDM, error) {
+		tmCfg := &mdmlab.TeamMDM{}
+		if teamID == 2 {
+			tmCfg.MacOSSettings.RecoveryLock = &mdmlab.MacOSRecoveryLock{Enable: true}
+		}
+		return tmCfg, nil
+	}
+	ds.ListHostsPendingRecoveryLockRotationFunc = func(ctx context.Context, teamID *uint, limit int) ([]*mdmlab.HostRecoveryLockTarget, error) {
+		if teamID == nil {
+			return []*mdmlab.HostRecoveryLockTarget{{HostID: 1, HostUUID: "arm-uuid", CPUType: "arm64e"}}, nil
+		}
+		require.EqualValues(t, 2, *teamID)
+		return []*mdmlab.HostRecoveryLockTarget{{HostID: 2, HostUUID: "intel-uuid", CPUType: "x86_64h"}}, nil
+	}
+	ds.GetHostRecoveryLockFunc = func(ctx context.Context, hostUUID string) (*mdmlab.HostRecoveryLock, error) {
+		if hostUUID == "intel-uuid" {
+			return &mdmlab.HostRecoveryLock{HostUUID: hostUUID, Password: "current", Status: mdmlab.MDMDeliveryVerified}, nil
+		}
+		return nil, newNotFoundError()
+	}
+	pendingCmds := make(map[string]string)
+	ds.SetHostRecoveryLockPendingFunc = func(ctx context.Context, hostUUID, passwordType, password, commandUUID string, rotateAt *time.Time) error {
+		require.Len(t, password, recoveryLockPasswordLength)
+		switch hostUUID {
+		case "arm-uuid":
+			require.Equal(t, mdmlab.RecoveryLockPasswordTypeRecoveryLock, passwordType)
+			require.NotNil(t, rotateAt)
+			require.WithinDuration(t, time.Now().Add(30*24*time.Hour), *rotateAt, time.Minute)
+		case "intel-uuid":
+			require.Equal(t, mdmlab.RecoveryLockPasswordTypeFirmwarePassword, passwordType)
+			require.Nil(t, rotateAt)
+		}
+		pendingCmds[commandUUID] = hostUUID
+		return nil
+	}
+	enqueued := make(map[string]string)
+	mdmStorage.EnqueueCommandFunc = func(ctx context.Context, id []string, cmd *mdm.CommandWithSubtype) (map[string]error, error) {
+		require.Len(t, id, 1)
+		require.Equal(t, pendingCmds[cmd.CommandUUID], id[0])
+		enqueued[id[0]] = cmd.Command.Command.RequestType
+		if id[0] == "intel-uuid" {
+			require.Contains(t, string(cmd.Raw), "<string>current</string>")
+		} else {
+			require.NotContains(t, string(cmd.Raw), "CurrentPassword")
+		}
+		return nil, nil
+	}
+
+	err := ReconcileAppleRecoveryLocks(ctx, ds, cmdr, kitlog.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"arm-uuid":   "SetRecoveryLock",
+		"intel-uuid": "SetFirmwarePassword",
+	}, enqueued)
+	require.False(t, ds.SetHostRecoveryLockFailedFuncInvoked)
+
+	// a failure to enqueue the command marks the host as failed
+	enqueued = make(map[string]string)
+	mdmStorage.EnqueueCommandFunc = func(ctx context.Context, id []string, cmd *mdm.CommandWithSubtype) (map[string]error, error) {
+		return nil, errors.New("enqueue failed")
+	}
+	var failed []string
+	ds.SetHostRecoveryLockFailedFunc = func(ctx context.Context, hostUUID, detail string, retryAt time.Time) error {
+		require.Contains(t, detail, "enqueue failed")
+		failed = append(failed, hostUUID)
+		return nil
+	}
+	err = ReconcileAppleRecoveryLocks(ctx, ds, cmdr, kitlog.NewNopLogger())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"arm-uuid", "intel-uuid"}, failed)
+}
+
+func TestMDMAppleRecoveryLockResults(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	cmdr, mdmStorage := newRecoveryLockTestCommander(t)
+	svc := MDMAppleCheckinAndCommandService{ds: ds, commander: cmdr, logger: kitlog.NewNopLogger()}
+
+	const hostUUID = "host-uuid"
+	lock := &mdmlab.HostRecoveryLock{
+		HostUUID:     hostUUID,
+		PasswordType: mdmlab.RecoveryLockPasswordTypeRecoveryLock,
+		CommandUUID:  "set-uuid",
+		Status:       mdmlab.MDMDeliveryPending,
+	}
+	requestTypes := map[string]string{"set-uuid": "SetRecoveryLock", "other-uuid": "SetRecoveryLock"}
+	ds.GetMDMAppleCommandRequestTypeFunc = func(ctx context.Context, commandUUID string) (string, error) {
+		if rt, ok := requestTypes[commandUUID]; ok {
+			return rt, nil
+		}
+		return "SecurityInfo", nil
+	}
+	ds.GetHostRecoveryLockFunc = func(ctx context.Context, uuid string) (*mdmlab.HostRecoveryLock, error) {
+		require.Equal(t, hostUUID, uuid)
+		return lock, nil
+	}
+	ds.SetHostRecoveryLockVerifyingFunc = func(ctx context.Context, uuid, verifyCommandUUID string) error {
+		lock.VerifyCommandUUID = &verifyCommandUUID
+		return nil
+	}
+	ds.SetHostRecoveryLockVerifiedFunc = func(ctx context.Context, uuid string) error {
+		return nil
+	}
+	var failDetail string
+	ds.SetHostRecoveryLockFailedFunc = func(ctx context.Context, uuid, detail string, retryAt time.Time) error {
+		failDetail = detail
+		return nil
+	}
+	var redacted []string
+	ds.RedactMDMAppleCommandPayloadFunc = func(ctx context.Context, commandUUID string) error {
+		redacted = append(redacted, commandUUID)
+		return nil
+	}
+	mdmStorage.EnqueueCommandFunc = func(ctx context.Context, id []string, cmd *mdm.CommandWithSubtype) (map[string]error, error) {
+		require.Equal(t, []string{hostUUID}, id)
+		require.Equal(t, "SecurityInfo", cmd.Command.Command.RequestType)
+		return nil, nil
+	}
+
+	report := func(cmdUUID, status string, raw []byte) {
+		_, err := svc.CommandAndReportResults(&mdm.Request{Context: ctx}, &mdm.CommandResults{
+			Enrollment:  mdm.Enrollment{UDID: hostUUID},
+			CommandUUID: cmdUUID,
+			Status:      status,
+			Raw:         raw,
+		})
+		require.NoError(t, err)
+	}
+	securityInfo := func(recoveryLockEnabled bool) []byte {
+		return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>SecurityInfo</key>
+	<dict>
+		<key>IsRecoveryLockEnabled</key>
+		<%t/>
+	</dict>
+</dict>
+</plist>`, recoveryLockEnabled))
+	}
+
+	// results of commands not sent by the managed recovery lock are ignored,
+	// but their payload is still redacted
+	report("other-uuid", mdmlab.MDMAppleStatusAcknowledged, nil)
+	require.False(t, ds.SetHostRecoveryLockVerifyingFuncInvoked)
+	require.Equal(t, []string{"other-uuid"}, redacted)
+
+	// the payload is kept while the host asks to retry the command later
+	report("set-uuid", mdmlab.MDMAppleStatusNotNow, nil)
+	require.False(t, ds.SetHostRecoveryLockVerifyingFuncInvoked)
+	require.Equal(t, []string{"other-uuid"}, redacted)
+
+	// the acknowledged command triggers a verification
+	report("set-uuid", mdmlab.MDMAppleStatusAcknowledged, nil)
+	require.True(t, ds.SetHostRecoveryLockVerifyingFuncInvoked)
+	require.True(t, mdmStorage.EnqueueCommandFuncInvoked)
+	require.NotNil(t, lock.VerifyCommandUUID)
+	require.Equal(t, []string{"other-uuid", "set-uuid"}, redacted)
+
+	// the host reports the recovery lock as disabled
+	report(*lock.VerifyCommandUUID, mdmlab.MDMAppleStatusAcknowledged, securityInfo(false))
+	require.True(t, ds.SetHostRecoveryLockFailedFuncInvoked)
+	require.False(t, ds.SetHostRecoveryLockVerifiedFuncInvoked)
+	require.Equal(t, "The host reported that the password is not set.", failDetail)
+	ds.SetHostRecoveryLockFailedFuncInvoked = false
+
+	report(*lock.VerifyCommandUUID, mdmlab.MDMAppleStatusAcknowledged, securityInfo(true))
+	require.True(t, ds.SetHostRecoveryLockVerifiedFuncInvoked)
+	require.False(t, ds.SetHostRecoveryLockFailedFuncInvoked)
+
+	// an error result marks the recovery lock as failed
+	_, err := svc.CommandAndReportResults(&mdm.Request{Context: ctx}, &mdm.CommandResults{
+		Enrollment:  mdm.Enrollment{UDID: hostUUID},
+		CommandUUID: "set-uuid",
+		Status:      mdmlab.MDMAppleStatusError,
+		ErrorChain:  []mdm.ErrorChain{{ErrorCode: 12, ErrorDomain: "MCMDMErrorDomain", USEnglishDescription: "The password is incorrect."}},
+	})
+	require.NoError(t, err)
+	require.True(t, ds.SetHostRecoveryLockFailedFuncInvoked)
+	require.Equal(t, "MCMDMErrorDomain (12): The password is incorrect.\n", failDetail)
+}
+
+func TestUnmarshalDeviceLocation(t *testing.T) {
+	raw := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Altitude</key>
+	<real>52.5</real>
+	<key>CommandUUID</key>
+	<string>3b9cf3c4-9a1c-4c5e-8dbb-a3cdb0dbb8d2</string>
+	<key>HorizontalAccuracy</key>
+	<real>15</real>
+	<key>Latitude</key>
+	<real>45.50884</real>
+	<key>Longitude</key>
+	<real>-73.58781</real>
+	<key>Status</key>
+	<string>Acknowledged</string>
+	<key>Timestamp</key>
+	<string>2025-01-23T14:05:12Z</string>
+	<key>UDID</key>
+	<string>00008101-001514810EA3A01E</string>
+</dict>
+</plist>`)
+	loc, err := unmarshalDeviceLocation(raw)
+	require.NoError(t, err)
+	assert.Equal(t, 45.50884, loc.Latitude)
+	assert.Equal(t, -73.58781, loc.Longitude)
+	require.NotNil(t, loc.Altitude)
+	assert.Equal(t, 52.5, *loc.Altitude)
+	require.NotNil(t, loc.HorizontalAccuracy)
+	assert.Equal(t, 15.0, *loc.HorizontalAccuracy)
+	assert.Nil(t, loc.VerticalAccuracy)
+	assert.Equal(t, time.Date(2025, 1, 23, 14, 5, 12, 0, time.UTC), loc.LocatedAt)
+
+	_, err = unmarshalDeviceLocation([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>Latitude</key>
+	<real>1</real>
+	<key>Timestamp</key>
+	<string>not a time</string>
+</dict>
+</plist>`))
+	require.Error(t, err)
+}
+
 func TestCheckMDMAppleEnrollmentWithMinimumOSVersion(t *testing.T) {
 	svc, ctx, ds := setupAppleMDMService(t, &mdmlab.LicenseInfo{Tier: mdmlab.TierPremium})
 
@@ -4253,3 +4522,148 @@ func TestCheckMDMAppleEnrollmentWithMinimumOSVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestMDMAppleCommandQueue(t *testing.T) {
+	ds := new(mock.Store)
+
+	mdmStorage := &mdmmock.MDMAppleStore{}
+	pushFactory, pushProvider := newMockAPNSPushProviderFactory()
+	pusher := nanomdm_pushsvc.New(mdmStorage, mdmStorage, pushFactory, NewNanoMDMLogger(kitlog.NewNopLogger()))
+	mdmStorage.RetrievePushInfoFunc = func(ctx context.Context, tokens []string) (map[string]*mdm.Push, error) {
+		res := make(map[string]*mdm.Push, len(tokens))
+		for _, t := range tokens {
+			res[t] = &mdm.Push{Token: []byte(t), Topic: "topic"}
+		}
+		return res, nil
+	}
+	mdmStorage.RetrievePushCertFunc = func(ctx context.Context, topic string) (*tls.Certificate, string, error) {
+		cert, err := tls.LoadX509KeyPair("testdata/server.pem", "testdata/server.key")
+		return &cert, "", err
+	}
+	mdmStorage.IsPushCertStaleFunc = func(ctx context.Context, topic string, staleToken string) (bool, error) {
+		return false, nil
+	}
+
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{MDMStorage: mdmStorage, MDMPusher: pusher})
+
+	tm1, tm2 := ptr.Uint(1), ptr.Uint(2)
+	ds.ListMDMAppleCommandQueueFunc = func(ctx context.Context, tmFilter mdmlab.TeamFilter,
+		listOpts *mdmlab.MDMAppleCommandQueueListOptions,
+	) ([]*mdmlab.MDMAppleCommandQueueItem, error) {
+		return []*mdmlab.MDMAppleCommandQueueItem{
+			{HostUUID: "uuid-tm1", TeamID: tm1, CommandUUID: "cmd1", Status: mdmlab.MDMAppleCommandQueueStatusNotNow, NotNowCount: 3},
+			{HostUUID: "uuid-tm2", TeamID: tm2, CommandUUID: "cmd1", Status: mdmlab.MDMAppleCommandQueueStatusPending},
+			{HostUUID: "uuid-no-tm", CommandUUID: "cmd1", Status: mdmlab.MDMAppleCommandQueueStatusAcknowledged},
+		}, nil
+	}
+
+	var pendingHosts []*mdmlab.Host
+	var gotLastSeenBefore *time.Time
+	ds.ListMDMAppleHostsWithPendingCommandsFunc = func(ctx context.Context, tmFilter mdmlab.TeamFilter, hostUUIDs []string,
+		commandUUID string, lastSeenBefore *time.Time,
+	) ([]*mdmlab.Host, error) {
+		gotLastSeenBefore = lastSeenBefore
+		return pendingHosts, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+	var canceledActivity *mdmlab.ActivityTypeCanceledMDMAppleCommands
+	ds.NewActivityFunc = func(ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time) error {
+		act, ok := activity.(mdmlab.ActivityTypeCanceledMDMAppleCommands)
+		require.True(t, ok)
+		canceledActivity = &act
+		return nil
+	}
+	var canceledUUIDs []string
+	mdmStorage.CancelPendingCommandsFunc = func(ctx context.Context, deviceIDs []string, commandUUID string, excludeRequestTypes []string) (int64, error) {
+		require.Equal(t, mdmlab.MDMAppleNonCancelableRequestTypes, excludeRequestTypes)
+		canceledUUIDs = deviceIDs
+		return int64(len(deviceIDs)), nil
+	}
+	ds.GetMDMAppleCommandRequestTypeFunc = func(ctx context.Context, commandUUID string) (string, error) {
+		if commandUUID == "profile-cmd" {
+			return "InstallProfile", nil
+		}
+		return "ListApps", nil
+	}
+
+	t.Run("list", func(t *testing.T) {
+		res, err := svc.ListMDMAppleCommandQueue(test.UserContext(ctx, test.UserAdmin), &mdmlab.MDMAppleCommandQueueListOptions{})
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+
+		// a team observer only sees its team's commands
+		res, err = svc.ListMDMAppleCommandQueue(test.UserContext(ctx, test.UserTeamObserverTeam1), &mdmlab.MDMAppleCommandQueueListOptions{})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "uuid-tm1", res[0].HostUUID)
+		require.Equal(t, 3, res[0].NotNowCount)
+
+		_, err = svc.ListMDMAppleCommandQueue(test.UserContext(ctx, test.UserAdmin), &mdmlab.MDMAppleCommandQueueListOptions{Status: "Unknown"})
+		var iae *mdmlab.InvalidArgumentError
+		require.ErrorAs(t, err, &iae)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		_, err := svc.CancelMDMAppleCommands(test.UserContext(ctx, test.UserAdmin), nil, "")
+		var iae *mdmlab.InvalidArgumentError
+		require.ErrorAs(t, err, &iae)
+
+		pendingHosts = []*mdmlab.Host{{UUID: "uuid-tm1", TeamID: tm1}, {UUID: "uuid-tm2", TeamID: tm2}}
+
+		// a team maintainer cannot cancel commands of other teams
+		_, err = svc.CancelMDMAppleCommands(test.UserContext(ctx, test.UserTeamMaintainerTeam1), nil, "cmd1")
+		checkAuthErr(t, true, err)
+		require.False(t, mdmStorage.CancelPendingCommandsFuncInvoked)
+
+		// an observer cannot cancel commands
+		pendingHosts = []*mdmlab.Host{{UUID: "uuid-tm1", TeamID: tm1}}
+		_, err = svc.CancelMDMAppleCommands(test.UserContext(ctx, test.UserTeamObserverTeam1), []string{"uuid-tm1"}, "")
+		checkAuthErr(t, true, err)
+
+		n, err := svc.CancelMDMAppleCommands(test.UserContext(ctx, test.UserTeamMaintainerTeam1), []string{"uuid-tm1"}, "")
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+		require.Equal(t, []string{"uuid-tm1"}, canceledUUIDs)
+		require.NotNil(t, canceledActivity)
+		require.Equal(t, 1, canceledActivity.HostCount)
+		require.EqualValues(t, 1, canceledActivity.Canceled)
+
+		// commands whose status is tracked by MDMlab cannot be canceled
+		mdmStorage.CancelPendingCommandsFuncInvoked = false
+		_, err = svc.CancelMDMAppleCommands(test.UserContext(ctx, test.UserAdmin), nil, "profile-cmd")
+		require.ErrorAs(t, err, &iae)
+		require.ErrorContains(t, err, "InstallProfile commands can't be canceled")
+		require.False(t, mdmStorage.CancelPendingCommandsFuncInvoked)
+	})
+
+	t.Run("push", func(t *testing.T) {
+		pendingHosts = nil
+		res, err := svc.ResendMDMApplePushNotifications(test.UserContext(ctx, test.UserAdmin), nil, nil)
+		require.NoError(t, err)
+		require.Empty(t, res.HostUUIDs)
+
+		pendingHosts = []*mdmlab.Host{{UUID: "uuid-tm1", TeamID: tm1}, {UUID: "uuid-tm2", TeamID: tm2}}
+		pushProvider.PushFunc = func(_ context.Context, pushes []*mdm.Push) (map[string]*push.Response, error) {
+			res := make(map[string]*push.Response, len(pushes))
+			for _, p := range pushes {
+				var err error
+				if string(p.Token) == "uuid-tm2" {
+					err = errors.New("bad device token")
+				}
+				res[p.Token.String()] = &push.Response{Err: err}
+			}
+			return res, nil
+		}
+		lastSeenBefore := time.Now().Add(-time.Hour)
+		res, err = svc.ResendMDMApplePushNotifications(test.UserContext(ctx, test.UserAdmin), nil, &lastSeenBefore)
+		require.NoError(t, err)
+		require.Equal(t, &lastSeenBefore, gotLastSeenBefore)
+		require.ElementsMatch(t, []string{"uuid-tm1", "uuid-tm2"}, res.HostUUIDs)
+		require.Equal(t, []string{"uuid-tm2"}, res.FailedHostUUIDs)
+
+		_, err = svc.ResendMDMApplePushNotifications(test.UserContext(ctx, test.UserTeamAdminTeam1), nil, nil)
+		checkAuthErr(t, true, err)
+	})
+}
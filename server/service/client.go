@@ -1549,6 +1549,24 @@ func (c *Client) DoGitOps(
 			vulnerabilitiesWebhook.(map[string]any)["enable_vulnerabilities_webhook"] = false
 		}
 
+		certificateExpirationWebhook, ok := webhookSettings.(map[string]any)["certificate_expiration_webhook"]
+		if !ok || certificateExpirationWebhook == nil {
+			certificateExpirationWebhook = map[string]any{}
+			webhookSettings.(map[string]any)["certificate_expiration_webhook"] = certificateExpirationWebhook
+		}
+		if _, ok := certificateExpirationWebhook.(map[string]any)["enable_certificate_expiration_webhook"]; !ok {
+			certificateExpirationWebhook.(map[string]any)["enable_certificate_expiration_webhook"] = false
+		}
+
+		softwareRequestsWebhook, ok := webhookSettings.(map[string]any)["software_requests_webhook"]
+		if !ok || softwareRequestsWebhook == nil {
+			softwareRequestsWebhook = map[string]any{}
+			webhookSettings.(map[string]any)["software_requests_webhook"] = softwareRequestsWebhook
+		}
+		if _, ok := softwareRequestsWebhook.(map[string]any)["enable_software_requests_webhook"]; !ok {
+			softwareRequestsWebhook.(map[string]any)["enable_software_requests_webhook"] = false
+		}
+
 		// Ensure mdm config exists
 		mdmConfig, ok := group.AppConfig.(map[string]interface{})["mdm"]
 		if !ok || mdmConfig == nil {
@@ -1803,6 +1821,12 @@ func (c *Client) DoGitOps(
 		}
 	}
 
+	if config.TeamName == nil && config.CustomCVEMatchingRules != nil {
+		if err := c.doGitOpsCustomCVEMatchingRules(config, logFn, dryRun); err != nil {
+			return nil, err
+		}
+	}
+
 	err = c.doGitOpsPolicies(config, teamSoftwareInstallers, teamVPPApps, teamScripts, logFn, dryRun)
 	if err != nil {
 		return nil, err
@@ -2097,6 +2121,19 @@ func (c *Client) doGitOpsPolicies(config *spec.GitOps, teamSoftwareInstallers []
 	return nil
 }
 
+func (c *Client) doGitOpsCustomCVEMatchingRules(config *spec.GitOps, logFn func(format string, args ...interface{}), dryRun bool) error {
+	numRules := len(config.CustomCVEMatchingRules)
+	if err := c.ApplyCustomCVEMatchingRules(config.CustomCVEMatchingRules, mdmlab.ApplySpecOptions{DryRun: dryRun}); err != nil {
+		return fmt.Errorf("applying custom CVE matching rules: %w", err)
+	}
+	if dryRun {
+		logFn("[+] would've applied %d custom CVE matching rules\n", numRules)
+	} else {
+		logFn("[+] applied %d custom CVE matching rules\n", numRules)
+	}
+	return nil
+}
+
 func (c *Client) doGitOpsQueries(config *spec.GitOps, logFn func(format string, args ...interface{}), dryRun bool) error {
 	batchSize := 100
 	// Get the ids and names of current queries to figure out which ones to delete
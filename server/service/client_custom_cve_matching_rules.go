@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// ApplyCustomCVEMatchingRules sends the list of custom CVE matching rules to be applied, replacing
+// all existing rules.
+func (c *Client) ApplyCustomCVEMatchingRules(specs []*mdmlab.CustomCVEMatchingRuleSpec, opts mdmlab.ApplySpecOptions) error {
+	req := applyCustomCVEMatchingRulesSpecsRequest{Specs: specs}
+	verb, path := "POST", "/api/latest/mdmlab/spec/custom_cve_matching_rules"
+	var responseBody applyCustomCVEMatchingRulesSpecsResponse
+	return c.authenticatedRequestWithQuery(req, verb, path, &responseBody, opts.RawQuery())
+}
+
+// ListCustomCVEMatchingRules returns the list of custom CVE matching rules.
+func (c *Client) ListCustomCVEMatchingRules() ([]*mdmlab.CustomCVEMatchingRule, error) {
+	verb, path := "GET", "/api/latest/mdmlab/custom_cve_matching_rules"
+	var responseBody listCustomCVEMatchingRulesResponse
+	err := c.authenticatedRequest(nil, verb, path, &responseBody)
+	return responseBody.Rules, err
+}
@@ -16,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/beevik/etree"
 	"github.com/it-laborato/MDM_Lab/pkg/file"
@@ -278,6 +279,55 @@ func (c *Client) MDMListCommands(opts mdmlab.MDMCommandListOptions) ([]*mdmlab.M
 	return responseBody.Results, nil
 }
 
+func (c *Client) MDMListAppleCommandQueue(opts mdmlab.MDMAppleCommandQueueListOptions) ([]*mdmlab.MDMAppleCommandQueueItem, error) {
+	const defaultCommandsPerPage = 100
+
+	verb, path := http.MethodGet, "/api/latest/mdmlab/mdm/apple/commands/queue"
+
+	perPage := opts.PerPage
+	if perPage == 0 {
+		perPage = defaultCommandsPerPage
+	}
+	query := url.Values{}
+	query.Set("per_page", fmt.Sprint(perPage))
+	query.Set("order_key", "created_at")
+	query.Set("order_direction", "desc")
+	query.Set("host_identifier", opts.HostIdentifier)
+	query.Set("request_type", opts.RequestType)
+	query.Set("command_uuid", opts.CommandUUID)
+	query.Set("status", string(opts.Status))
+
+	var responseBody listMDMAppleCommandQueueResponse
+	err := c.authenticatedRequestWithQuery(nil, verb, path, &responseBody, query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return responseBody.Results, nil
+}
+
+func (c *Client) MDMCancelAppleCommands(hostUUIDs []string, commandUUID string) (int64, error) {
+	verb, path := http.MethodPost, "/api/latest/mdmlab/mdm/apple/commands/cancel"
+
+	request := cancelMDMAppleCommandsRequest{HostUUIDs: hostUUIDs, CommandUUID: commandUUID}
+	var responseBody cancelMDMAppleCommandsResponse
+	if err := c.authenticatedRequest(request, verb, path, &responseBody); err != nil {
+		return 0, fmt.Errorf("cancel commands request: %w", err)
+	}
+	return responseBody.Canceled, nil
+}
+
+func (c *Client) MDMResendApplePushNotifications(hostUUIDs []string, lastSeenBefore *time.Time) (*mdmlab.MDMAppleCommandQueuePushResult, error) {
+	verb, path := http.MethodPost, "/api/latest/mdmlab/mdm/apple/commands/push"
+
+	request := resendMDMApplePushNotificationsRequest{HostUUIDs: hostUUIDs, LastSeenBefore: lastSeenBefore}
+	var responseBody resendMDMApplePushNotificationsResponse
+	if err := c.authenticatedRequest(request, verb, path, &responseBody); err != nil {
+		return nil, fmt.Errorf("resend push notifications request: %w", err)
+	}
+	return responseBody.MDMAppleCommandQueuePushResult, nil
+}
+
 func (c *Client) MDMGetCommandResults(commandUUID string) ([]*mdmlab.MDMCommandResult, error) {
 	verb, path := http.MethodGet, "/api/latest/mdmlab/mdm/commandresults"
 
@@ -378,9 +428,9 @@ func (c *Client) MDMUnlockHost(hostID uint) (string, error) {
 	return response.UnlockPIN, nil
 }
 
-func (c *Client) MDMWipeHost(hostID uint) error {
+func (c *Client) MDMWipeHost(hostID uint, opts mdmlab.MDMWipeOptions) error {
 	var response wipeHostResponse
-	if err := c.authenticatedRequest(nil, "POST", fmt.Sprintf("/api/latest/mdmlab/hosts/%d/wipe", hostID), &response); err != nil {
+	if err := c.authenticatedRequest(opts, "POST", fmt.Sprintf("/api/latest/mdmlab/hosts/%d/wipe", hostID), &response); err != nil {
 		return fmt.Errorf("wipe host request: %w", err)
 	}
 	return nil
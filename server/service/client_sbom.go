@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// GetHostSBOM retrieves the software bill of materials of the host in the given format.
+func (c *Client) GetHostSBOM(hostID uint, format string) ([]byte, error) {
+	return c.getSBOM(fmt.Sprintf("/api/latest/mdmlab/hosts/%d/sbom", hostID), url.Values{"format": {format}})
+}
+
+// GetTeamSBOM retrieves the software bill of materials of the hosts in the team in the given
+// format. A nil teamID includes all hosts.
+func (c *Client) GetTeamSBOM(teamID *uint, format string) ([]byte, error) {
+	query := url.Values{"format": {format}}
+	if teamID != nil {
+		query.Set("team_id", fmt.Sprint(*teamID))
+	}
+	return c.getSBOM("/api/latest/mdmlab/sbom", query)
+}
+
+func (c *Client) getSBOM(path string, query url.Values) ([]byte, error) {
+	response, err := c.AuthenticatedDo("GET", path, query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"get SBOM received status %d: %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read SBOM response body: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/it-laborato/MDM_Lab/server/authz"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// POST /custom_cve_matching_rules
+////////////////////////////////////////////////////////////////////////////////
+
+type createCustomCVEMatchingRuleRequest struct {
+	mdmlab.CustomCVEMatchingRuleSpec
+}
+
+type createCustomCVEMatchingRuleResponse struct {
+	Rule *mdmlab.CustomCVEMatchingRule `json:"rule,omitempty"`
+	Err  error                         `json:"error,omitempty"`
+}
+
+func (r createCustomCVEMatchingRuleResponse) error() error { return r.Err }
+
+func createCustomCVEMatchingRuleEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*createCustomCVEMatchingRuleRequest)
+	rule, err := svc.NewCustomCVEMatchingRule(ctx, req.CustomCVEMatchingRuleSpec)
+	if err != nil {
+		return createCustomCVEMatchingRuleResponse{Err: err}, nil
+	}
+	return createCustomCVEMatchingRuleResponse{Rule: rule}, nil
+}
+
+func (svc *Service) NewCustomCVEMatchingRule(ctx context.Context, spec mdmlab.CustomCVEMatchingRuleSpec) (*mdmlab.CustomCVEMatchingRule, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.CustomCVEMatchingRule{}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "validate custom cve matching rule")
+	}
+
+	rule, err := svc.ds.NewCustomCVEMatchingRule(ctx, spec.ToRule())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create custom cve matching rule")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeCreatedCustomCVEMatchingRule{
+			ID:                rule.ID,
+			NameLikeMatch:     rule.NameLikeMatch,
+			SourceMatch:       rule.SourceMatch,
+			CVEs:              rule.CVEs,
+			ResolvedInVersion: rule.ResolvedInVersion,
+		},
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for custom cve matching rule creation")
+	}
+
+	return rule, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GET /custom_cve_matching_rules
+////////////////////////////////////////////////////////////////////////////////
+
+type listCustomCVEMatchingRulesResponse struct {
+	Rules []*mdmlab.CustomCVEMatchingRule `json:"rules"`
+	Err   error                           `json:"error,omitempty"`
+}
+
+func (r listCustomCVEMatchingRulesResponse) error() error { return r.Err }
+
+func listCustomCVEMatchingRulesEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	rules, err := svc.ListCustomCVEMatchingRules(ctx)
+	if err != nil {
+		return listCustomCVEMatchingRulesResponse{Err: err}, nil
+	}
+	return listCustomCVEMatchingRulesResponse{Rules: rules}, nil
+}
+
+func (svc *Service) ListCustomCVEMatchingRules(ctx context.Context) ([]*mdmlab.CustomCVEMatchingRule, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.CustomCVEMatchingRule{}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	rules, err := svc.ds.ListCustomCVEMatchingRules(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list custom cve matching rules")
+	}
+	return rules, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// DELETE /custom_cve_matching_rules/{id}
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteCustomCVEMatchingRuleRequest struct {
+	ID uint `url:"id"`
+}
+
+type deleteCustomCVEMatchingRuleResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteCustomCVEMatchingRuleResponse) error() error { return r.Err }
+
+func deleteCustomCVEMatchingRuleEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*deleteCustomCVEMatchingRuleRequest)
+	err := svc.DeleteCustomCVEMatchingRule(ctx, req.ID)
+	return deleteCustomCVEMatchingRuleResponse{Err: err}, nil
+}
+
+func (svc *Service) DeleteCustomCVEMatchingRule(ctx context.Context, id uint) error {
+	if err := svc.authz.Authorize(ctx, &mdmlab.CustomCVEMatchingRule{}, mdmlab.ActionWrite); err != nil {
+		return err
+	}
+
+	// Load the rule before deleting it for the activity details.
+	rule, err := svc.ds.CustomCVEMatchingRule(ctx, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get custom cve matching rule")
+	}
+
+	if err := svc.ds.DeleteCustomCVEMatchingRule(ctx, id); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete custom cve matching rule")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeDeletedCustomCVEMatchingRule{
+			ID:                rule.ID,
+			NameLikeMatch:     rule.NameLikeMatch,
+			SourceMatch:       rule.SourceMatch,
+			CVEs:              rule.CVEs,
+			ResolvedInVersion: rule.ResolvedInVersion,
+		},
+	); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for custom cve matching rule deletion")
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// POST /spec/custom_cve_matching_rules
+////////////////////////////////////////////////////////////////////////////////
+
+type applyCustomCVEMatchingRulesSpecsRequest struct {
+	DryRun bool                                `json:"-" query:"dry_run,optional"` // if true, apply validation but do not save changes
+	Specs  []*mdmlab.CustomCVEMatchingRuleSpec `json:"specs"`
+}
+
+type applyCustomCVEMatchingRulesSpecsResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r applyCustomCVEMatchingRulesSpecsResponse) error() error { return r.Err }
+
+func applyCustomCVEMatchingRulesSpecsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*applyCustomCVEMatchingRulesSpecsRequest)
+	err := svc.ApplyCustomCVEMatchingRulesSpecs(ctx, req.Specs, mdmlab.ApplySpecOptions{DryRun: req.DryRun})
+	return applyCustomCVEMatchingRulesSpecsResponse{Err: err}, nil
+}
+
+func (svc *Service) ApplyCustomCVEMatchingRulesSpecs(ctx context.Context, specs []*mdmlab.CustomCVEMatchingRuleSpec, opts mdmlab.ApplySpecOptions) error {
+	if err := svc.authz.Authorize(ctx, &mdmlab.CustomCVEMatchingRule{}, mdmlab.ActionWrite); err != nil {
+		return err
+	}
+
+	rules := make([]*mdmlab.CustomCVEMatchingRule, 0, len(specs))
+	seen := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		if spec == nil {
+			return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("specs", fmt.Sprintf("custom cve matching rule at index %d is empty", i)))
+		}
+		if err := spec.Validate(); err != nil {
+			return ctxerr.Wrap(ctx, err, fmt.Sprintf("validate custom cve matching rule at index %d", i))
+		}
+		rule := spec.ToRule()
+		key := fmt.Sprintf("%s\x00%s\x00%s", rule.NameLikeMatch, rule.SourceMatch, rule.ResolvedInVersion)
+		if j, ok := seen[key]; ok {
+			return ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("specs",
+				fmt.Sprintf("custom cve matching rules at index %d and %d have the same name_like_match, source_match and resolved_in_version", j, i)))
+		}
+		seen[key] = i
+		rules = append(rules, rule)
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+
+	if err := svc.ds.BatchSetCustomCVEMatchingRules(ctx, rules); err != nil {
+		return ctxerr.Wrap(ctx, err, "batch set custom cve matching rules")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeAppliedSpecCustomCVEMatchingRules{RulesCount: len(rules)},
+	); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for custom cve matching rules spec")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCustomCVEMatchingRulesSpecs(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+	ctx = viewer.NewContext(ctx, viewer.Viewer{User: test.UserAdmin})
+
+	var stored []*mdmlab.CustomCVEMatchingRule
+	ds.BatchSetCustomCVEMatchingRulesFunc = func(ctx context.Context, rules []*mdmlab.CustomCVEMatchingRule) error {
+		stored = rules
+		return nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+	ds.NewActivityFunc = func(
+		ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time,
+	) error {
+		return nil
+	}
+
+	spec := func(name, version string, cves ...string) *mdmlab.CustomCVEMatchingRuleSpec {
+		return &mdmlab.CustomCVEMatchingRuleSpec{NameLikeMatch: name, CVEs: cves, ResolvedInVersion: version}
+	}
+
+	// invalid spec
+	err := svc.ApplyCustomCVEMatchingRulesSpecs(ctx, []*mdmlab.CustomCVEMatchingRuleSpec{spec("Acme%", "", "CVE-2024-0001")}, mdmlab.ApplySpecOptions{})
+	require.ErrorContains(t, err, "resolved_in_version")
+	require.False(t, ds.BatchSetCustomCVEMatchingRulesFuncInvoked)
+
+	// duplicate specs
+	err = svc.ApplyCustomCVEMatchingRulesSpecs(ctx, []*mdmlab.CustomCVEMatchingRuleSpec{
+		spec("Acme%", "1.0", "CVE-2024-0001"),
+		spec(" Acme%", "1.0", "CVE-2024-0002"),
+	}, mdmlab.ApplySpecOptions{})
+	require.ErrorContains(t, err, "same name_like_match")
+	require.False(t, ds.BatchSetCustomCVEMatchingRulesFuncInvoked)
+
+	// dry run validates but does not store
+	specs := []*mdmlab.CustomCVEMatchingRuleSpec{spec("Acme%", "1.0", "cve-2024-0001", "CVE-2024-0001 ")}
+	err = svc.ApplyCustomCVEMatchingRulesSpecs(ctx, specs, mdmlab.ApplySpecOptions{DryRun: true})
+	require.NoError(t, err)
+	require.False(t, ds.BatchSetCustomCVEMatchingRulesFuncInvoked)
+
+	err = svc.ApplyCustomCVEMatchingRulesSpecs(ctx, specs, mdmlab.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.True(t, ds.BatchSetCustomCVEMatchingRulesFuncInvoked)
+	require.True(t, ds.NewActivityFuncInvoked)
+	require.Len(t, stored, 1)
+	require.Equal(t, []string{"CVE-2024-0001"}, stored[0].CVEs)
+}
+
+func TestDeleteCustomCVEMatchingRule(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+	ctx = viewer.NewContext(ctx, viewer.Viewer{User: test.UserAdmin})
+
+	ds.CustomCVEMatchingRuleFunc = func(ctx context.Context, id uint) (*mdmlab.CustomCVEMatchingRule, error) {
+		if id != 1 {
+			return nil, newNotFoundError()
+		}
+		return &mdmlab.CustomCVEMatchingRule{ID: 1, NameLikeMatch: "Acme%", CVEs: []string{"CVE-2024-0001"}, ResolvedInVersion: "1.0"}, nil
+	}
+	ds.DeleteCustomCVEMatchingRuleFunc = func(ctx context.Context, id uint) error {
+		require.Equal(t, uint(1), id)
+		return nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+	var deleted *mdmlab.ActivityTypeDeletedCustomCVEMatchingRule
+	ds.NewActivityFunc = func(
+		ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time,
+	) error {
+		act := activity.(mdmlab.ActivityTypeDeletedCustomCVEMatchingRule)
+		deleted = &act
+		return nil
+	}
+
+	// unknown rule
+	err := svc.DeleteCustomCVEMatchingRule(ctx, 2)
+	require.True(t, mdmlab.IsNotFound(err))
+	require.False(t, ds.DeleteCustomCVEMatchingRuleFuncInvoked)
+
+	require.NoError(t, svc.DeleteCustomCVEMatchingRule(ctx, 1))
+	require.True(t, ds.DeleteCustomCVEMatchingRuleFuncInvoked)
+	require.False(t, ds.ListCustomCVEMatchingRulesFuncInvoked)
+	require.NotNil(t, deleted)
+	require.Equal(t, "Acme%", deleted.NameLikeMatch)
+	require.Equal(t, []string{"CVE-2024-0001"}, deleted.CVEs)
+}
@@ -636,6 +636,48 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	ue.POST("/api/_version_/mdmlab/software/package", uploadSoftwareInstallerEndpoint, uploadSoftwareInstallerRequest{})
 	ue.PATCH("/api/_version_/mdmlab/software/titles/{id:[0-9]+}/package", updateSoftwareInstallerEndpoint, updateSoftwareInstallerRequest{})
 	ue.DELETE("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/available_for_install", deleteSoftwareInstallerEndpoint, deleteSoftwareInstallerRequest{})
+	ue.GET("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/rollout", getSoftwareInstallerRolloutEndpoint,
+		getSoftwareInstallerRolloutRequest{})
+	ue.PUT("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/rollout", setSoftwareInstallerRolloutEndpoint,
+		setSoftwareInstallerRolloutRequest{})
+	ue.POST("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/rollout/pause", pauseSoftwareInstallerRolloutEndpoint,
+		getSoftwareInstallerRolloutRequest{})
+	ue.POST("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/rollout/resume", resumeSoftwareInstallerRolloutEndpoint,
+		getSoftwareInstallerRolloutRequest{})
+	ue.DELETE("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/rollout", deleteSoftwareInstallerRolloutEndpoint,
+		getSoftwareInstallerRolloutRequest{})
+	ue.GET("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/versions", listSoftwareInstallerVersionsEndpoint,
+		listSoftwareInstallerVersionsRequest{})
+	ue.POST("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/versions/{version_id:[0-9]+}/pin", pinSoftwareInstallerVersionEndpoint,
+		softwareInstallerVersionRequest{})
+	ue.DELETE("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/versions/pin", unpinSoftwareInstallerVersionEndpoint,
+		listSoftwareInstallerVersionsRequest{})
+	ue.POST("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/versions/{version_id:[0-9]+}/rollback", rollbackSoftwareInstallerVersionEndpoint,
+		softwareInstallerVersionRequest{})
+	ue.PUT("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/versions/{version_id:[0-9]+}/labels", setSoftwareInstallerVersionLabelsEndpoint,
+		setSoftwareInstallerVersionLabelsRequest{})
+	ue.DELETE("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/versions/{version_id:[0-9]+}", deleteSoftwareInstallerVersionEndpoint,
+		softwareInstallerVersionRequest{})
+	ue.GET("/api/_version_/mdmlab/software/blocklist", listSoftwareBlocklistRulesEndpoint, listSoftwareBlocklistRulesRequest{})
+	ue.POST("/api/_version_/mdmlab/software/blocklist", createSoftwareBlocklistRuleEndpoint, createSoftwareBlocklistRuleRequest{})
+	ue.GET("/api/_version_/mdmlab/software/blocklist/{id:[0-9]+}", getSoftwareBlocklistRuleEndpoint, getSoftwareBlocklistRuleRequest{})
+	ue.PUT("/api/_version_/mdmlab/software/blocklist/{id:[0-9]+}", modifySoftwareBlocklistRuleEndpoint, modifySoftwareBlocklistRuleRequest{})
+	ue.DELETE("/api/_version_/mdmlab/software/blocklist/{id:[0-9]+}", deleteSoftwareBlocklistRuleEndpoint, getSoftwareBlocklistRuleRequest{})
+	ue.GET("/api/_version_/mdmlab/software/blocklist/{id:[0-9]+}/hosts", listSoftwareBlocklistRuleHostsEndpoint,
+		getSoftwareBlocklistRuleRequest{})
+	ue.GET("/api/_version_/mdmlab/software/requests", listSoftwareRequestsEndpoint, listSoftwareRequestsRequest{})
+	ue.POST("/api/_version_/mdmlab/software/requests/{id:[0-9]+}/approve", approveSoftwareRequestEndpoint, reviewSoftwareRequestRequest{})
+	ue.POST("/api/_version_/mdmlab/software/requests/{id:[0-9]+}/deny", denySoftwareRequestEndpoint, reviewSoftwareRequestRequest{})
+	ue.GET("/api/_version_/mdmlab/software/licenses", listSoftwareLicensesEndpoint, listSoftwareLicensesRequest{})
+	ue.GET("/api/_version_/mdmlab/software/licenses/report", softwareLicensesReportEndpoint, softwareLicensesReportRequest{})
+	ue.POST("/api/_version_/mdmlab/software/licenses", createSoftwareLicenseEndpoint, createSoftwareLicenseRequest{})
+	ue.GET("/api/_version_/mdmlab/software/licenses/{id:[0-9]+}", getSoftwareLicenseEndpoint, getSoftwareLicenseRequest{})
+	ue.PUT("/api/_version_/mdmlab/software/licenses/{id:[0-9]+}", modifySoftwareLicenseEndpoint, modifySoftwareLicenseRequest{})
+	ue.DELETE("/api/_version_/mdmlab/software/licenses/{id:[0-9]+}", deleteSoftwareLicenseEndpoint, getSoftwareLicenseRequest{})
+	ue.GET("/api/_version_/mdmlab/software/licenses/{id:[0-9]+}/hosts", listSoftwareLicenseHostsEndpoint, listSoftwareLicenseHostsRequest{})
+	ue.GET("/api/_version_/mdmlab/software/titles/{id:[0-9]+}/usage", getSoftwareTitleUsageEndpoint, getSoftwareTitleUsageRequest{})
+	ue.GET("/api/_version_/mdmlab/software/titles/{id:[0-9]+}/unused_policy", getSoftwareTitleUnusedPolicyEndpoint, getSoftwareTitleUnusedPolicyRequest{})
+	ue.GET("/api/_version_/mdmlab/software/unused", listUnusedSoftwareEndpoint, listUnusedSoftwareRequest{})
 	ue.GET("/api/_version_/mdmlab/software/install/{install_uuid}/results", getSoftwareInstallResultsEndpoint,
 		getSoftwareInstallResultsRequest{})
 	// POST /api/_version_/mdmlab/software/batch is asynchronous, meaning it will start the process of software download+upload in the background
@@ -663,6 +705,22 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	// Vulnerabilities
 	ue.GET("/api/_version_/mdmlab/vulnerabilities", listVulnerabilitiesEndpoint, listVulnerabilitiesRequest{})
 	ue.GET("/api/_version_/mdmlab/vulnerabilities/{cve}", getVulnerabilityEndpoint, getVulnerabilityRequest{})
+	ue.GET("/api/_version_/mdmlab/vulnerabilities/{cve}/exposure", getVulnerabilityExposureEndpoint, getVulnerabilityExposureRequest{})
+	ue.POST("/api/_version_/mdmlab/vulnerabilities/{cve}/policies", createVulnerabilityPolicyEndpoint, createVulnerabilityPolicyRequest{})
+
+	ue.POST("/api/_version_/mdmlab/custom_cve_matching_rules", createCustomCVEMatchingRuleEndpoint, createCustomCVEMatchingRuleRequest{})
+	ue.GET("/api/_version_/mdmlab/custom_cve_matching_rules", listCustomCVEMatchingRulesEndpoint, nil)
+	ue.DELETE("/api/_version_/mdmlab/custom_cve_matching_rules/{id:[0-9]+}", deleteCustomCVEMatchingRuleEndpoint, deleteCustomCVEMatchingRuleRequest{})
+	ue.POST("/api/_version_/mdmlab/spec/custom_cve_matching_rules", applyCustomCVEMatchingRulesSpecsEndpoint, applyCustomCVEMatchingRulesSpecsRequest{})
+	ue.POST("/api/_version_/mdmlab/vulnerability_suppressions", createVulnerabilitySuppressionEndpoint, createVulnerabilitySuppressionRequest{})
+	ue.GET("/api/_version_/mdmlab/vulnerability_suppressions", listVulnerabilitySuppressionsEndpoint, listVulnerabilitySuppressionsRequest{})
+	ue.DELETE("/api/_version_/mdmlab/vulnerability_suppressions/{id:[0-9]+}", deleteVulnerabilitySuppressionEndpoint, deleteVulnerabilitySuppressionRequest{})
+	ue.POST("/api/_version_/mdmlab/vulnerability_suppressions/vex", importVEXDocumentEndpoint, importVEXDocumentRequest{})
+	ue.GET("/api/_version_/mdmlab/vulnerability_suppressions/vex", exportVEXDocumentEndpoint, exportVEXDocumentRequest{})
+	ue.GET("/api/_version_/mdmlab/hosts/{id:[0-9]+}/sbom", getHostSBOMEndpoint, getHostSBOMRequest{})
+	ue.GET("/api/_version_/mdmlab/sbom", getTeamSBOMEndpoint, getTeamSBOMRequest{})
+	ue.GET("/api/_version_/mdmlab/vulnerability_sla/overdue", listOverdueVulnerabilitiesEndpoint, listOverdueVulnerabilitiesRequest{})
+	ue.GET("/api/_version_/mdmlab/vulnerability_sla/metrics", getVulnerabilityRemediationMetricsEndpoint, getVulnerabilityRemediationMetricsRequest{})
 
 	// Hosts
 	ue.GET("/api/_version_/mdmlab/host_summary", getHostSummaryEndpoint, getHostSummaryRequest{})
@@ -771,6 +829,8 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	ue.GET("/api/_version_/mdmlab/hosts/{id:[0-9]+}/scripts", getHostScriptDetailsEndpoint, getHostScriptDetailsRequest{})
 	ue.GET("/api/_version_/mdmlab/hosts/{id:[0-9]+}/activities/upcoming", listHostUpcomingActivitiesEndpoint, listHostUpcomingActivitiesRequest{})
 	ue.GET("/api/_version_/mdmlab/hosts/{id:[0-9]+}/activities", listHostPastActivitiesEndpoint, listHostPastActivitiesRequest{})
+	ue.GET("/api/_version_/mdmlab/hosts/{id:[0-9]+}/certificates", listHostCertificatesEndpoint, listHostCertificatesRequest{})
+	ue.GET("/api/_version_/mdmlab/host_certificates", searchHostCertificatesEndpoint, searchHostCertificatesRequest{})
 	ue.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/lock", lockHostEndpoint, lockHostRequest{})
 	ue.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/unlock", unlockHostEndpoint, unlockHostRequest{})
 	ue.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/wipe", wipeHostEndpoint, wipeHostRequest{})
@@ -802,6 +862,9 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	// platform-agnostic POST /mdm/commands/commands. It is still supported
 	// indefinitely for backwards compatibility.
 	mdmAppleMW.GET("/api/_version_/mdmlab/mdm/apple/commands", listMDMAppleCommandsEndpoint, listMDMAppleCommandsRequest{})
+	mdmAppleMW.GET("/api/_version_/mdmlab/mdm/apple/commands/queue", listMDMAppleCommandQueueEndpoint, listMDMAppleCommandQueueRequest{})
+	mdmAppleMW.POST("/api/_version_/mdmlab/mdm/apple/commands/cancel", cancelMDMAppleCommandsEndpoint, cancelMDMAppleCommandsRequest{})
+	mdmAppleMW.POST("/api/_version_/mdmlab/mdm/apple/commands/push", resendMDMApplePushNotificationsEndpoint, resendMDMApplePushNotificationsRequest{})
 	// Deprecated: those /mdm/apple/profiles/... endpoints are now deprecated,
 	// replaced by the platform-agnostic /mdm/profiles/... It is still supported
 	// indefinitely for backwards compatibility.
@@ -892,6 +955,15 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	mdmAppleMW.POST("/api/_version_/mdmlab/mdm/hosts/{id:[0-9]+}/lock", deviceLockEndpoint, deviceLockRequest{})
 	mdmAppleMW.POST("/api/_version_/mdmlab/mdm/hosts/{id:[0-9]+}/wipe", deviceWipeEndpoint, deviceWipeRequest{})
 
+	// Apple MDM device actions
+	mdmAppleMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/shutdown", shutDownHostEndpoint, shutDownHostRequest{})
+	mdmAppleMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/lost_mode", enableHostLostModeEndpoint, enableHostLostModeRequest{})
+	mdmAppleMW.DELETE("/api/_version_/mdmlab/hosts/{id:[0-9]+}/lost_mode", disableHostLostModeEndpoint, disableHostLostModeRequest{})
+	mdmAppleMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/lost_mode/sound", playHostLostModeSoundEndpoint, playHostLostModeSoundRequest{})
+	mdmAppleMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/location", requestHostLocationEndpoint, requestHostLocationRequest{})
+	mdmAppleMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/recovery_lock", setHostRecoveryLockEndpoint, setHostRecoveryLockRequest{})
+	mdmAppleMW.GET("/api/_version_/mdmlab/hosts/{id:[0-9]+}/recovery_lock_password", getHostRecoveryLockPasswordEndpoint, getHostRecoveryLockPasswordRequest{})
+
 	// Deprecated: GET /mdm/hosts/:id/profiles is now deprecated, replaced by
 	// GET /hosts/:id/configuration_profiles.
 	mdmAppleMW.GET("/api/_version_/mdmlab/mdm/hosts/{id:[0-9]+}/profiles", getHostProfilesEndpoint, getHostProfilesRequest{})
@@ -937,6 +1009,13 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 
 	mdmAnyMW := ue.WithCustomMiddleware(mdmConfiguredMiddleware.VerifyAppleOrWindowsMDM())
 
+	// MDM device actions supported on both Apple and Windows hosts
+	mdmAnyMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/restart", restartHostEndpoint, restartHostRequest{})
+
+	// Windows MDM device actions
+	mdmWindowsMW := ue.WithCustomMiddleware(mdmConfiguredMiddleware.VerifyWindowsMDM())
+	mdmWindowsMW.POST("/api/_version_/mdmlab/hosts/{id:[0-9]+}/bitlocker/rotate", rotateHostBitLockerRecoveryPasswordEndpoint, rotateHostBitLockerRecoveryPasswordRequest{})
+
 	// Deprecated: POST /mdm/commands/run is now deprecated, replaced by the
 	// POST /commands/run endpoint.
 	mdmAnyMW.POST("/api/_version_/mdmlab/mdm/commands/run", runMDMCommandEndpoint, runMDMCommandRequest{})
@@ -1088,6 +1167,15 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	de.WithCustomMiddleware(
 		errorLimiter.Limit("install_self_service", desktopQuota),
 	).POST("/api/_version_/mdmlab/device/{token}/software/install/{software_title_id}", submitSelfServiceSoftwareInstall, mdmlabSelfServiceSoftwareInstallRequest{})
+	de.WithCustomMiddleware(
+		errorLimiter.Limit("get_device_software_catalog", desktopQuota),
+	).GET("/api/_version_/mdmlab/device/{token}/software/catalog", getDeviceSoftwareCatalogEndpoint, getDeviceSoftwareCatalogRequest{})
+	de.WithCustomMiddleware(
+		errorLimiter.Limit("request_device_software", desktopQuota),
+	).POST("/api/_version_/mdmlab/device/{token}/software/requests", requestDeviceSoftwareEndpoint, requestDeviceSoftwareRequest{})
+	de.WithCustomMiddleware(
+		errorLimiter.Limit("get_device_software_requests", desktopQuota),
+	).GET("/api/_version_/mdmlab/device/{token}/software/requests", listDeviceSoftwareRequestsEndpoint, listDeviceSoftwareRequestsRequest{})
 
 	// mdm-related endpoints available via device authentication
 	demdm := de.WithCustomMiddleware(mdmConfiguredMiddleware.VerifyAppleMDM())
@@ -1219,6 +1307,13 @@ func attachMDMlabAPIRoutes(r *mux.Router, svc mdmlab.Service, config config.MDMl
 	ne.GET("/api/_version_/mdmlab/software/titles/{title_id:[0-9]+}/package/token/{token}", downloadSoftwareInstallerEndpoint,
 		downloadSoftwareInstallerRequest{})
 
+	// The software installer caches don't hold any credentials, their requests
+	// are authorized by the token issued to the host with the install details.
+	ne.GET("/api/_version_/mdmlab/software_cache/token/{token}", getSoftwareInstallerCacheTokenEndpoint,
+		softwareInstallerCacheTokenRequest{})
+	ne.GET("/api/_version_/mdmlab/software_cache/token/{token}/package", downloadSoftwareInstallerWithCacheTokenEndpoint,
+		softwareInstallerCacheTokenRequest{})
+
 	ne.POST("/api/_version_/mdmlab/perform_required_password_reset", performRequiredPasswordResetEndpoint, performRequiredPasswordResetRequest{})
 	ne.POST("/api/_version_/mdmlab/users", createUserFromInviteEndpoint, createUserRequest{})
 	ne.GET("/api/_version_/mdmlab/invites/{token}", verifyInviteEndpoint, verifyInviteRequest{})
@@ -0,0 +1,497 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/it-laborato/MDM_Lab/server"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/logging"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	apple_mdm "github.com/it-laborato/MDM_Lab/server/mdm/apple"
+	"github.com/it-laborato/MDM_Lab/server/mdm/microsoft/syncml"
+	"github.com/it-laborato/MDM_Lab/server/mdm/nanomdm/mdm"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/micromdm/plist"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GET /hosts/{id}/certificates
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostCertificatesRequest struct {
+	ID          uint               `url:"id"`
+	ListOptions mdmlab.ListOptions `url:"list_options"`
+}
+
+type listHostCertificatesResponse struct {
+	Meta         *mdmlab.PaginationMetadata `json:"meta"`
+	Certificates []*mdmlab.HostCertificate  `json:"certificates"`
+	Err          error                      `json:"error,omitempty"`
+}
+
+func (r listHostCertificatesResponse) error() error { return r.Err }
+
+func listHostCertificatesEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listHostCertificatesRequest)
+	certs, meta, err := svc.ListHostCertificates(ctx, req.ID, req.ListOptions)
+	if err != nil {
+		return listHostCertificatesResponse{Err: err}, nil
+	}
+	if certs == nil {
+		// return empty json array instead of json null
+		certs = []*mdmlab.HostCertificate{}
+	}
+	return listHostCertificatesResponse{Meta: meta, Certificates: certs}, nil
+}
+
+func (svc *Service) ListHostCertificates(ctx context.Context, hostID uint, opt mdmlab.ListOptions) ([]*mdmlab.HostCertificate, *mdmlab.PaginationMetadata, error) {
+	// First ensure the user has access to list hosts, then check the specific
+	// host once team_id is loaded.
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, nil, err
+	}
+	host, err := svc.ds.HostLite(ctx, hostID)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "get host")
+	}
+	// Authorize again with team loaded now that we have team_id
+	if err := svc.authz.Authorize(ctx, host, mdmlab.ActionRead); err != nil {
+		return nil, nil, err
+	}
+
+	// cursor-based pagination is not supported for certificates
+	opt.After = ""
+	if opt.OrderKey == "" {
+		opt.OrderKey = "common_name"
+	}
+	// always include metadata
+	opt.IncludeMetadata = true
+
+	return svc.ds.ListHostCertificates(ctx, hostID, opt)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GET /host_certificates
+////////////////////////////////////////////////////////////////////////////////
+
+type searchHostCertificatesRequest struct {
+	Opts mdmlab.HostCertificateListOptions `url:"host_certificate_options"`
+}
+
+type searchHostCertificatesResponse struct {
+	Meta         *mdmlab.PaginationMetadata        `json:"meta"`
+	Certificates []*mdmlab.HostCertificateWithHost `json:"certificates"`
+	Err          error                             `json:"error,omitempty"`
+}
+
+func (r searchHostCertificatesResponse) error() error { return r.Err }
+
+func searchHostCertificatesEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*searchHostCertificatesRequest)
+	certs, meta, err := svc.SearchHostCertificates(ctx, req.Opts)
+	if err != nil {
+		return searchHostCertificatesResponse{Err: err}, nil
+	}
+	if certs == nil {
+		// return empty json array instead of json null
+		certs = []*mdmlab.HostCertificateWithHost{}
+	}
+	return searchHostCertificatesResponse{Meta: meta, Certificates: certs}, nil
+}
+
+func (svc *Service) SearchHostCertificates(ctx context.Context, opt mdmlab.HostCertificateListOptions) ([]*mdmlab.HostCertificateWithHost, *mdmlab.PaginationMetadata, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{TeamID: opt.TeamID}, mdmlab.ActionList); err != nil {
+		return nil, nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, nil, mdmlab.ErrNoContext
+	}
+	filter := mdmlab.TeamFilter{User: vc.User, IncludeObserver: true}
+
+	// cursor-based pagination is not supported for certificates
+	opt.ListOptions.After = ""
+	if opt.ListOptions.OrderKey == "" {
+		opt.ListOptions.OrderKey = "not_valid_after"
+	}
+	// always include metadata
+	opt.ListOptions.IncludeMetadata = true
+
+	return svc.ds.SearchHostCertificates(ctx, filter, opt)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Certificates inventory refetch
+////////////////////////////////////////////////////////////////////////////////
+
+// hostCertificatesRefetchBatchSize is the maximum number of hosts for which a
+// refetch of the certificate inventory is started on each run.
+const hostCertificatesRefetchBatchSize = 1000
+
+// windowsCertificateStores are the CertificateStore CSP nodes that list the
+// certificates installed on Windows hosts.
+var windowsCertificateStores = []string{
+	"./Device/Vendor/MSFT/CertificateStore/My/System",
+	"./Device/Vendor/MSFT/CertificateStore/Root/System",
+	"./Device/Vendor/MSFT/CertificateStore/CA/System",
+}
+
+// windowsMDMCertificateThumbprintLocURI holds the thumbprint of the
+// certificate the host uses to authenticate with MDMlab.
+const windowsMDMCertificateThumbprintLocURI = "./Device/Vendor/MSFT/CertificateStore/My/WSTEP/CertThumbprint"
+
+// RefetchHostCertificates sends the MDM commands to refresh the certificate
+// inventory of the Apple and Windows hosts that are due for it.
+//
+// On Apple hosts, two CertificateList commands are sent: one that lists all
+// certificates, and one that lists only the certificates installed by
+// MDMlab. On Windows hosts, the CertificateStore CSP is queried for the
+// thumbprints of the installed certificates, the certificates themselves are
+// then requested when the results are processed.
+func RefetchHostCertificates(ctx context.Context, ds mdmlab.Datastore, commander *apple_mdm.MDMAppleCommander, logger kitlog.Logger) error {
+	appCfg, err := ds.AppConfig(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "fetching app config")
+	}
+	if !appCfg.MDM.EnabledAndConfigured && !appCfg.MDM.WindowsEnabledAndConfigured {
+		level.Debug(logger).Log("msg", "mdm is not configured, skipping run")
+		return nil
+	}
+
+	hosts, err := ds.ListHostsToRefetchCertificates(ctx, mdmlab.HostCertificateRefetchInterval, hostCertificatesRefetchBatchSize)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list hosts to refetch certificates")
+	}
+
+	var appleUUIDs, windowsUUIDs []string
+	hostMDMCommands := make([]mdmlab.HostMDMCommand, 0, len(hosts))
+	for _, h := range hosts {
+		switch {
+		case h.Platform == "windows" && appCfg.MDM.WindowsEnabledAndConfigured:
+			windowsUUIDs = append(windowsUUIDs, h.HostUUID)
+		case h.Platform != "windows" && appCfg.MDM.EnabledAndConfigured && commander != nil:
+			appleUUIDs = append(appleUUIDs, h.HostUUID)
+		default:
+			continue
+		}
+		hostMDMCommands = append(hostMDMCommands, mdmlab.HostMDMCommand{
+			HostID:      h.HostID,
+			CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix,
+		})
+	}
+	if len(hostMDMCommands) == 0 {
+		return nil
+	}
+	level.Debug(logger).Log("msg", "sending commands to refetch certificates", "apple", len(appleUUIDs), "windows", len(windowsUUIDs))
+
+	commandUUID := uuid.NewString()
+	if len(appleUUIDs) > 0 {
+		if err := commander.CertificateList(ctx, appleUUIDs, mdmlab.RefetchCertificatesCommandUUIDPrefix+commandUUID, false); err != nil {
+			return ctxerr.Wrap(ctx, err, "send CertificateList commands")
+		}
+		// the managed certificates list is sent last, its result completes
+		// the refetch.
+		if err := commander.CertificateList(ctx, appleUUIDs, mdmlab.RefetchManagedCertificatesCommandUUIDPrefix+commandUUID, true); err != nil {
+			return ctxerr.Wrap(ctx, err, "send managed CertificateList commands")
+		}
+	}
+	if len(windowsUUIDs) > 0 {
+		cmd := buildWindowsCertificatesGetCommand(mdmlab.RefetchCertificatesCommandUUIDPrefix+commandUUID, windowsCertificateStores)
+		if err := ds.MDMWindowsInsertCommandForHosts(ctx, windowsUUIDs, cmd); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert windows certificate list command")
+		}
+	}
+
+	// Add commands to the database to track the commands sent
+	if err := ds.AddHostMDMCommands(ctx, hostMDMCommands); err != nil {
+		return ctxerr.Wrap(ctx, err, "add host mdm commands")
+	}
+	return nil
+}
+
+// buildWindowsCertificatesGetCommand builds a <Get> command for the provided
+// CertificateStore CSP nodes.
+func buildWindowsCertificatesGetCommand(cmdUUID string, locURIs []string) *mdmlab.MDMWindowsCommand {
+	var sb strings.Builder
+	sb.WriteString(`<Get><CmdID>` + cmdUUID + `</CmdID>`)
+	for _, locURI := range locURIs {
+		sb.WriteString(`<Item><Target><LocURI>` + locURI + `</LocURI></Target></Item>`)
+	}
+	sb.WriteString(`</Get>`)
+
+	return &mdmlab.MDMWindowsCommand{
+		CommandUUID:  cmdUUID,
+		RawCommand:   []byte(sb.String()),
+		TargetLocURI: "./Device/Vendor/MSFT/CertificateStore",
+	}
+}
+
+// handleCertificatesRefetch processes the result of a CertificateList command
+// sent to refetch the certificate inventory of an Apple host.
+func (svc *MDMAppleCheckinAndCommandService) handleCertificatesRefetch(ctx context.Context, host *mdmlab.Host, cmdResult *mdm.CommandResults) error {
+	managedOnly := strings.HasPrefix(cmdResult.CommandUUID, mdmlab.RefetchManagedCertificatesCommandUUIDPrefix)
+	failed := cmdResult.Status == mdmlab.MDMAppleStatusError || cmdResult.Status == mdmlab.MDMAppleStatusCommandFormatError
+
+	// The managed certificates list is the last command of the refetch, we
+	// remove the pending command first in case there is an error processing
+	// the results, so that we don't prevent another refetch.
+	if managedOnly || failed {
+		if err := svc.ds.RemoveHostMDMCommand(ctx, mdmlab.HostMDMCommand{
+			HostID:      host.ID,
+			CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix,
+		}); err != nil {
+			return ctxerr.Wrap(ctx, err, "remove refetch certificates command")
+		}
+	}
+	if cmdResult.Status != mdmlab.MDMAppleStatusAcknowledged {
+		return nil
+	}
+
+	certs, err := unmarshalCertificateList(cmdResult.Raw)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "unmarshal certificate list")
+	}
+
+	if managedOnly {
+		sums := make([]string, 0, len(certs))
+		for _, c := range certs {
+			sums = append(sums, c.SHA1Sum)
+		}
+		if err := svc.ds.SetHostCertificatesManaged(ctx, host.ID, mdmlab.HostCertificateSourceAppleMDM, sums); err != nil {
+			return ctxerr.Wrap(ctx, err, "set managed host certificates")
+		}
+		return nil
+	}
+
+	if err := svc.ds.ReplaceHostCertificates(ctx, host.ID, mdmlab.HostCertificateSourceAppleMDM, certs); err != nil {
+		return ctxerr.Wrap(ctx, err, "replace host certificates")
+	}
+	return nil
+}
+
+// unmarshalCertificateList parses the certificates reported in the response
+// to a CertificateList command. Certificates that can't be parsed are
+// ignored.
+func unmarshalCertificateList(raw []byte) ([]*mdmlab.HostCertificate, error) {
+	var resp struct {
+		CertificateList []struct {
+			CommonName string
+			Data       []byte
+			IsIdentity bool
+		}
+	}
+	if err := plist.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+
+	certs := make([]*mdmlab.HostCertificate, 0, len(resp.CertificateList))
+	seen := make(map[string]bool, len(resp.CertificateList))
+	for _, item := range resp.CertificateList {
+		cert, err := mdmlab.NewHostCertificateFromDER(item.Data, mdmlab.HostCertificateSourceAppleMDM)
+		if err != nil || seen[cert.SHA1Sum] {
+			continue
+		}
+		seen[cert.SHA1Sum] = true
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// processHostCertificatesResults processes the results of the commands sent
+// to refetch the certificate inventory of a Windows host.
+func (svc *Service) processHostCertificatesResults(ctx context.Context, deviceID string, syncML mdmlab.EnrichedSyncML) error {
+	var host *mdmlab.HostLite
+	seen := make(map[string]bool)
+	for _, cmdRef := range syncML.CmdRefUUIDs {
+		isList := strings.HasPrefix(cmdRef, mdmlab.RefetchCertificatesCommandUUIDPrefix)
+		isData := strings.HasPrefix(cmdRef, mdmlab.RefetchCertificatesDataCommandUUIDPrefix)
+		if (!isList && !isData) || seen[cmdRef] {
+			continue
+		}
+		seen[cmdRef] = true
+
+		if host == nil {
+			device, err := svc.ds.MDMWindowsGetEnrolledDeviceWithDeviceID(ctx, deviceID)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "get windows enrolled device")
+			}
+			host, err = svc.ds.HostLiteByIdentifier(ctx, device.HostUUID)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "get host by identifier")
+			}
+		}
+		refetchDone := mdmlab.HostMDMCommand{HostID: host.ID, CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix}
+
+		results, ok := syncML.CmdRefUUIDToResults[cmdRef]
+		if !ok {
+			// only a status was received, the refetch is over if it failed
+			if status, ok := syncML.CmdRefUUIDToStatus[cmdRef]; ok && status.Data != nil && *status.Data != syncml.CmdStatusOK {
+				if err := svc.ds.RemoveHostMDMCommand(ctx, refetchDone); err != nil {
+					return ctxerr.Wrap(ctx, err, "remove refetch certificates command")
+				}
+			}
+			continue
+		}
+
+		if isList {
+			locURIs := parseWindowsCertificateStoresResults(results)
+			if len(locURIs) > 0 {
+				locURIs = append(locURIs, windowsMDMCertificateThumbprintLocURI)
+				cmd := buildWindowsCertificatesGetCommand(mdmlab.RefetchCertificatesDataCommandUUIDPrefix+uuid.NewString(), locURIs)
+				if err := svc.ds.MDMWindowsInsertCommandForHosts(ctx, []string{deviceID}, cmd); err != nil {
+					return ctxerr.Wrap(ctx, err, "insert windows certificates data command")
+				}
+				continue
+			}
+			// no certificates installed, nothing else to request
+		}
+
+		certs, managedThumbprint := parseWindowsCertificatesDataResults(results)
+		if err := svc.ds.ReplaceHostCertificates(ctx, host.ID, mdmlab.HostCertificateSourceWindowsMDM, certs); err != nil {
+			return ctxerr.Wrap(ctx, err, "replace host certificates")
+		}
+		var managed []string
+		if managedThumbprint != "" {
+			managed = append(managed, managedThumbprint)
+		}
+		if err := svc.ds.SetHostCertificatesManaged(ctx, host.ID, mdmlab.HostCertificateSourceWindowsMDM, managed); err != nil {
+			return ctxerr.Wrap(ctx, err, "set managed host certificates")
+		}
+		if err := svc.ds.RemoveHostMDMCommand(ctx, refetchDone); err != nil {
+			return ctxerr.Wrap(ctx, err, "remove refetch certificates command")
+		}
+	}
+	return nil
+}
+
+// parseWindowsCertificateStoresResults returns the LocURIs of the encoded
+// certificates listed in the results of a CertificateStore <Get> command.
+// Each store node lists the thumbprints of its certificates separated by "/".
+func parseWindowsCertificateStoresResults(results mdmlab.SyncMLCmd) []string {
+	var locURIs []string
+	seen := make(map[string]bool)
+	for _, item := range results.Items {
+		if item.Source == nil || item.Data == nil {
+			continue
+		}
+		for _, thumbprint := range strings.Split(strings.TrimSpace(item.Data.Content), "/") {
+			thumbprint = strings.ToLower(strings.TrimSpace(thumbprint))
+			if thumbprint == "" || seen[thumbprint] {
+				continue
+			}
+			seen[thumbprint] = true
+			locURIs = append(locURIs, fmt.Sprintf("%s/%s/EncodedCertificate", *item.Source, strings.ToUpper(thumbprint)))
+		}
+	}
+	return locURIs
+}
+
+// parseWindowsCertificatesDataResults parses the certificates reported in
+// the results of a <Get> command for encoded certificates. It also returns
+// the thumbprint of the host's MDM certificate, if reported.
+func parseWindowsCertificatesDataResults(results mdmlab.SyncMLCmd) (certs []*mdmlab.HostCertificate, managedThumbprint string) {
+	seen := make(map[string]bool)
+	for _, item := range results.Items {
+		if item.Source == nil || item.Data == nil {
+			continue
+		}
+		content := strings.TrimSpace(item.Data.Content)
+		if *item.Source == windowsMDMCertificateThumbprintLocURI {
+			managedThumbprint = strings.ToLower(content)
+			continue
+		}
+		if !strings.HasSuffix(*item.Source, "/EncodedCertificate") {
+			continue
+		}
+		der, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			continue
+		}
+		cert, err := mdmlab.NewHostCertificateFromDER(der, mdmlab.HostCertificateSourceWindowsMDM)
+		if err != nil || seen[cert.SHA1Sum] {
+			continue
+		}
+		seen[cert.SHA1Sum] = true
+		certs = append(certs, cert)
+	}
+	return certs, managedThumbprint
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Certificates expiration alerts
+////////////////////////////////////////////////////////////////////////////////
+
+// hostCertificatesExpirationBatchSize is the maximum number of certificates
+// reported in a single certificate expiration webhook request.
+const hostCertificatesExpirationBatchSize = 500
+
+// TriggerHostCertificatesExpirationWebhook sends the certificate expiration
+// webhook and creates an activity for each host certificate that expires
+// within the configured window. Each certificate is reported only once.
+func TriggerHostCertificatesExpirationWebhook(ctx context.Context, ds mdmlab.Datastore, logger kitlog.Logger) error {
+	appConfig, err := ds.AppConfig(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "getting app config")
+	}
+	settings := appConfig.WebhookSettings.CertificateExpirationWebhook
+	if !settings.Enable || settings.DaysBeforeExpiration <= 0 {
+		return nil
+	}
+	within := time.Duration(settings.DaysBeforeExpiration) * 24 * time.Hour
+
+	for {
+		certs, err := ds.ListExpiringHostCertificatesToNotify(ctx, within, hostCertificatesExpirationBatchSize)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "list expiring host certificates")
+		}
+		if len(certs) == 0 {
+			return nil
+		}
+
+		payload := map[string]interface{}{
+			"text": fmt.Sprintf(
+				"%d host certificate(s) expire within %d days. "+
+					"You've been sent this message because the Certificate expiration webhook is enabled in your MDMlab instance.",
+				len(certs), settings.DaysBeforeExpiration,
+			),
+			"data": map[string]interface{}{
+				"days_before_expiration": settings.DaysBeforeExpiration,
+				"certificates":           certs,
+			},
+		}
+		if err := server.PostJSONWithTimeout(ctx, settings.DestinationURL, &payload); err != nil {
+			return ctxerr.Wrapf(ctx, err, "posting to %s", settings.DestinationURL)
+		}
+
+		ids := make([]uint, 0, len(certs))
+		for _, c := range certs {
+			ids = append(ids, c.ID)
+			if err := newActivity(ctx, nil, mdmlab.ActivityTypeHostCertificateExpiring{
+				HostID:          c.HostID,
+				HostDisplayName: c.HostDisplayName,
+				CommonName:      c.CommonName,
+				SHA1Sum:         c.SHA1Sum,
+				NotValidAfter:   c.NotValidAfter,
+			}, ds, logger); err != nil {
+				// the webhook was already sent, keep going so the certificate
+				// is not reported again.
+				logging.WithErr(ctx, ctxerr.Wrap(ctx, err, "create host certificate expiring activity"))
+			}
+		}
+		if err := ds.SetHostCertificatesExpirationNotified(ctx, ids); err != nil {
+			return ctxerr.Wrap(ctx, err, "set host certificates expiration notified")
+		}
+
+		if len(certs) < hostCertificatesExpirationBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" // nolint:gosec // used only to compute the certificate's thumbprint
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/it-laborato/MDM_Lab/server/mdm/nanomdm/mdm"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/micromdm/plist"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHostCertificateDER(t *testing.T, cn string, notAfter time.Time) []byte {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(0xabc),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"MDMlab"}},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	return der
+}
+
+func testHostCertificateSHA1(der []byte) string {
+	sum := sha1.Sum(der) // nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUnmarshalCertificateList(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	der1 := newTestHostCertificateDER(t, "one", notAfter)
+	der2 := newTestHostCertificateDER(t, "two", notAfter)
+
+	type item struct {
+		CommonName string
+		Data       []byte
+		IsIdentity bool
+	}
+	raw, err := plist.Marshal(struct {
+		CertificateList []item
+		CommandUUID     string
+		Status          string
+	}{
+		CertificateList: []item{
+			{CommonName: "one", Data: der1, IsIdentity: true},
+			{CommonName: "two", Data: der2},
+			{CommonName: "dup", Data: der1},
+			{CommonName: "invalid", Data: []byte("not a certificate")},
+		},
+		CommandUUID: "uuid",
+		Status:      "Acknowledged",
+	})
+	require.NoError(t, err)
+
+	certs, err := unmarshalCertificateList(raw)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	require.Equal(t, "one", certs[0].CommonName)
+	require.Equal(t, testHostCertificateSHA1(der1), certs[0].SHA1Sum)
+	require.Equal(t, "ABC", certs[0].SerialNumber)
+	require.Equal(t, notAfter, certs[0].NotValidAfter)
+	require.Equal(t, mdmlab.HostCertificateSourceAppleMDM, certs[0].Source)
+	require.Equal(t, "two", certs[1].CommonName)
+
+	_, err = unmarshalCertificateList([]byte("not a plist"))
+	require.Error(t, err)
+}
+
+func TestParseWindowsCertificatesResults(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	der1 := newTestHostCertificateDER(t, "one", notAfter)
+	der2 := newTestHostCertificateDER(t, "two", notAfter)
+	sum1, sum2 := testHostCertificateSHA1(der1), testHostCertificateSHA1(der2)
+
+	item := func(source, data string) mdmlab.CmdItem {
+		return mdmlab.CmdItem{Source: ptr.String(source), Data: &mdmlab.RawXmlData{Content: data}}
+	}
+
+	locURIs := parseWindowsCertificateStoresResults(mdmlab.SyncMLCmd{Items: []mdmlab.CmdItem{
+		item(windowsCertificateStores[0], strings.ToUpper(sum1)+"/"+sum2),
+		item(windowsCertificateStores[1], sum1),
+		item(windowsCertificateStores[2], ""),
+		{Source: ptr.String(windowsCertificateStores[2])},
+	}})
+	require.Equal(t, []string{
+		windowsCertificateStores[0] + "/" + strings.ToUpper(sum1) + "/EncodedCertificate",
+		windowsCertificateStores[0] + "/" + strings.ToUpper(sum2) + "/EncodedCertificate",
+	}, locURIs)
+
+	certs, managed := parseWindowsCertificatesDataResults(mdmlab.SyncMLCmd{Items: []mdmlab.CmdItem{
+		item(locURIs[0], base64.StdEncoding.EncodeToString(der1)),
+		item(locURIs[1], base64.StdEncoding.EncodeToString(der2)),
+		item(windowsCertificateStores[1]+"/"+strings.ToUpper(sum1)+"/EncodedCertificate", base64.StdEncoding.EncodeToString(der1)),
+		item(windowsCertificateStores[2]+"/ABCD/EncodedCertificate", "not base64!"),
+		item(windowsMDMCertificateThumbprintLocURI, strings.ToUpper(sum2)),
+	}})
+	require.Equal(t, sum2, managed)
+	require.Len(t, certs, 2)
+	require.Equal(t, sum1, certs[0].SHA1Sum)
+	require.Equal(t, "one", certs[0].CommonName)
+	require.Equal(t, mdmlab.HostCertificateSourceWindowsMDM, certs[0].Source)
+	require.Equal(t, sum2, certs[1].SHA1Sum)
+}
+
+func TestRefetchHostCertificates(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	cmdr, mdmStorage := newRecoveryLockTestCommander(t)
+
+	appCfg := &mdmlab.AppConfig{}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return appCfg, nil
+	}
+	ds.ListHostsToRefetchCertificatesFunc = func(ctx context.Context, interval time.Duration, limit int) ([]*mdmlab.HostCertificateRefetchTarget, error) {
+		require.Equal(t, mdmlab.HostCertificateRefetchInterval, interval)
+		return []*mdmlab.HostCertificateRefetchTarget{
+			{HostID: 1, HostUUID: "mac-uuid", Platform: "darwin"},
+			{HostID: 2, HostUUID: "ios-uuid", Platform: "ios"},
+			{HostID: 3, HostUUID: "win-uuid", Platform: "windows"},
+		}, nil
+	}
+	var appleCmds []string
+	mdmStorage.EnqueueCommandFunc = func(ctx context.Context, id []string, cmd *mdm.CommandWithSubtype) (map[string]error, error) {
+		require.ElementsMatch(t, []string{"mac-uuid", "ios-uuid"}, id)
+		require.Equal(t, "CertificateList", cmd.Command.Command.RequestType)
+		appleCmds = append(appleCmds, cmd.CommandUUID)
+		if strings.HasPrefix(cmd.CommandUUID, mdmlab.RefetchManagedCertificatesCommandUUIDPrefix) {
+			require.Contains(t, string(cmd.Raw), "<key>ManagedOnly</key>")
+		} else {
+			require.NotContains(t, string(cmd.Raw), "ManagedOnly")
+		}
+		return nil, nil
+	}
+	var windowsCmd *mdmlab.MDMWindowsCommand
+	ds.MDMWindowsInsertCommandForHostsFunc = func(ctx context.Context, hostUUIDs []string, cmd *mdmlab.MDMWindowsCommand) error {
+		require.Equal(t, []string{"win-uuid"}, hostUUIDs)
+		windowsCmd = cmd
+		return nil
+	}
+	var tracked []mdmlab.HostMDMCommand
+	ds.AddHostMDMCommandsFunc = func(ctx context.Context, commands []mdmlab.HostMDMCommand) error {
+		tracked = append(tracked, commands...)
+		return nil
+	}
+	reset := func() {
+		appleCmds, windowsCmd, tracked = nil, nil, nil
+		ds.ListHostsToRefetchCertificatesFuncInvoked = false
+	}
+
+	// MDM disabled, nothing to do
+	require.NoError(t, RefetchHostCertificates(ctx, ds, cmdr, kitlog.NewNopLogger()))
+	require.False(t, ds.ListHostsToRefetchCertificatesFuncInvoked)
+
+	// only Apple MDM enabled
+	reset()
+	appCfg.MDM.EnabledAndConfigured = true
+	require.NoError(t, RefetchHostCertificates(ctx, ds, cmdr, kitlog.NewNopLogger()))
+	require.Len(t, appleCmds, 2)
+	require.True(t, strings.HasPrefix(appleCmds[0], mdmlab.RefetchCertificatesCommandUUIDPrefix))
+	require.True(t, strings.HasPrefix(appleCmds[1], mdmlab.RefetchManagedCertificatesCommandUUIDPrefix))
+	require.Nil(t, windowsCmd)
+	require.ElementsMatch(t, []mdmlab.HostMDMCommand{
+		{HostID: 1, CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix},
+		{HostID: 2, CommandType: mdmlab.RefetchCertificatesCommandUUIDPrefix},
+	}, tracked)
+
+	// Apple and Windows MDM enabled
+	reset()
+	appCfg.MDM.WindowsEnabledAndConfigured = true
+	require.NoError(t, RefetchHostCertificates(ctx, ds, cmdr, kitlog.NewNopLogger()))
+	require.Len(t, appleCmds, 2)
+	require.NotNil(t, windowsCmd)
+	require.True(t, strings.HasPrefix(windowsCmd.CommandUUID, mdmlab.RefetchCertificatesCommandUUIDPrefix))
+	for _, store := range windowsCertificateStores {
+		require.Contains(t, string(windowsCmd.RawCommand), "<LocURI>"+store+"</LocURI>")
+	}
+	require.Len(t, tracked, 3)
+}
+
+func TestTriggerHostCertificatesExpirationWebhook(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	appCfg := &mdmlab.AppConfig{}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return appCfg, nil
+	}
+	notAfter := time.Now().Add(48 * time.Hour).UTC()
+	pending := []*mdmlab.HostCertificateWithHost{
+		{HostCertificate: mdmlab.HostCertificate{ID: 1, HostID: 10, CommonName: "one", SHA1Sum: "a", NotValidAfter: notAfter}, HostDisplayName: "host10"},
+		{HostCertificate: mdmlab.HostCertificate{ID: 2, HostID: 11, CommonName: "two", SHA1Sum: "b", NotValidAfter: notAfter}, HostDisplayName: "host11"},
+	}
+	ds.ListExpiringHostCertificatesToNotifyFunc = func(ctx context.Context, within time.Duration, limit int) ([]*mdmlab.HostCertificateWithHost, error) {
+		require.Equal(t, 3*24*time.Hour, within)
+		return pending, nil
+	}
+	ds.SetHostCertificatesExpirationNotifiedFunc = func(ctx context.Context, ids []uint) error {
+		require.Equal(t, []uint{1, 2}, ids)
+		pending = nil
+		return nil
+	}
+	var activities []mdmlab.ActivityTypeHostCertificateExpiring
+	ds.NewActivityFunc = func(ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time) error {
+		require.Nil(t, user)
+		act, ok := activity.(mdmlab.ActivityTypeHostCertificateExpiring)
+		require.True(t, ok)
+		activities = append(activities, act)
+		return nil
+	}
+
+	// webhook disabled
+	require.NoError(t, TriggerHostCertificatesExpirationWebhook(ctx, ds, kitlog.NewNopLogger()))
+	require.False(t, ds.ListExpiringHostCertificatesToNotifyFuncInvoked)
+	require.Empty(t, requests)
+
+	appCfg.WebhookSettings.CertificateExpirationWebhook = mdmlab.CertificateExpirationWebhookSettings{
+		Enable:               true,
+		DestinationURL:       srv.URL,
+		DaysBeforeExpiration: 3,
+	}
+	require.NoError(t, TriggerHostCertificatesExpirationWebhook(ctx, ds, kitlog.NewNopLogger()))
+	require.True(t, ds.SetHostCertificatesExpirationNotifiedFuncInvoked)
+	require.Len(t, requests, 1)
+	data := requests[0]["data"].(map[string]any)
+	require.EqualValues(t, 3, data["days_before_expiration"])
+	require.Len(t, data["certificates"], 2)
+	require.Len(t, activities, 2)
+	require.Equal(t, uint(10), activities[0].HostID)
+	require.Equal(t, "host10", activities[0].HostDisplayName)
+	require.Equal(t, "one", activities[0].CommonName)
+
+	// nothing left to notify
+	ds.SetHostCertificatesExpirationNotifiedFuncInvoked = false
+	require.NoError(t, TriggerHostCertificatesExpirationWebhook(ctx, ds, kitlog.NewNopLogger()))
+	require.False(t, ds.SetHostCertificatesExpirationNotifiedFuncInvoked)
+	require.Len(t, requests, 1)
+}
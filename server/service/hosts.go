@@ -1315,6 +1315,8 @@ func (svc *Service) getHostDetails(ctx context.Context, host *mdmlab.Host, opts
 		host.MDM.DeviceStatus = ptr.String("wiped")
 	case mdmActions.IsLocked():
 		host.MDM.DeviceStatus = ptr.String("locked")
+	case mdmActions.IsInLostMode():
+		host.MDM.DeviceStatus = ptr.String("lost_mode")
 	}
 
 	// pending action, if any
@@ -1325,6 +1327,18 @@ func (svc *Service) getHostDetails(ctx context.Context, host *mdmlab.Host, opts
 		host.MDM.PendingAction = ptr.String("unlock")
 	case mdmActions.IsPendingWipe():
 		host.MDM.PendingAction = ptr.String("wipe")
+	case mdmActions.IsPendingLostMode():
+		host.MDM.PendingAction = ptr.String("enable_lost_mode")
+	case mdmActions.IsPendingDisableLostMode():
+		host.MDM.PendingAction = ptr.String("disable_lost_mode")
+	}
+
+	if mdmActions.IsInLostMode() {
+		loc, err := svc.ds.GetHostMDMAppleDeviceLocation(ctx, host.UUID)
+		if err != nil && !mdmlab.IsNotFound(err) {
+			return nil, ctxerr.Wrap(ctx, err, "get host device location")
+		}
+		host.MDM.DeviceLocation = loc
 	}
 
 	host.Policies = policies
@@ -2345,6 +2359,38 @@ func (svc *Service) decryptForMDMPlatform(ctx context.Context, host *mdmlab.Host
 	return key, nil
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Rotate BitLocker recovery password
+////////////////////////////////////////////////////////////////////////////////
+
+type rotateHostBitLockerRecoveryPasswordRequest struct {
+	HostID uint `url:"id"`
+}
+
+type rotateHostBitLockerRecoveryPasswordResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r rotateHostBitLockerRecoveryPasswordResponse) error() error { return r.Err }
+
+func (r rotateHostBitLockerRecoveryPasswordResponse) Status() int { return http.StatusNoContent }
+
+func rotateHostBitLockerRecoveryPasswordEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*rotateHostBitLockerRecoveryPasswordRequest)
+	if err := svc.RotateHostBitLockerRecoveryPassword(ctx, req.HostID); err != nil {
+		return rotateHostBitLockerRecoveryPasswordResponse{Err: err}, nil
+	}
+	return rotateHostBitLockerRecoveryPasswordResponse{}, nil
+}
+
+func (svc *Service) RotateHostBitLockerRecoveryPassword(ctx context.Context, hostID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Host Health
 ////////////////////////////////////////////////////////////////////////////////
@@ -1830,14 +1830,107 @@ func TestLockUnlockWipeHostAuth(t *testing.T) {
 				return &mdmlab.HostLockWipeStatus{}, nil
 			}
 
-			err = svc.WipeHost(ctx, globalHostID)
+			err = svc.WipeHost(ctx, globalHostID, mdmlab.MDMWipeOptions{})
 			checkAuthErr(t, tt.shouldFailGlobalWrite, err)
-			err = svc.WipeHost(ctx, teamHostID)
+			err = svc.WipeHost(ctx, teamHostID, mdmlab.MDMWipeOptions{})
 			checkAuthErr(t, tt.shouldFailTeamWrite, err)
 		})
 	}
 }
 
+func TestWindowsMDMHostActions(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: &mdmlab.LicenseInfo{Tier: mdmlab.TierPremium}})
+	ctx = viewer.NewContext(ctx, viewer.Viewer{User: &mdmlab.User{GlobalRole: ptr.String(mdmlab.RoleAdmin)}})
+
+	appCfg := &mdmlab.AppConfig{}
+	appCfg.MDM.WindowsEnabledAndConfigured = true
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return appCfg, nil
+	}
+	hosts := map[uint]*mdmlab.Host{
+		1: {ID: 1, UUID: "win-uuid", Platform: "windows"},
+		2: {ID: 2, UUID: "mac-uuid", Platform: "darwin"},
+	}
+	ds.HostLiteFunc = func(ctx context.Context, hostID uint) (*mdmlab.Host, error) {
+		return hosts[hostID], nil
+	}
+	ds.IsHostConnectedToMDMlabMDMFunc = func(ctx context.Context, host *mdmlab.Host) (bool, error) {
+		return true, nil
+	}
+	ds.GetHostLockWipeStatusFunc = func(ctx context.Context, host *mdmlab.Host) (*mdmlab.HostLockWipeStatus, error) {
+		return &mdmlab.HostLockWipeStatus{HostMDMlabPlatform: host.MDMlabPlatform()}, nil
+	}
+	var cmds []*mdmlab.MDMWindowsCommand
+	ds.MDMWindowsInsertCommandForHostsFunc = func(ctx context.Context, hostUUIDs []string, cmd *mdmlab.MDMWindowsCommand) error {
+		require.Equal(t, []string{"win-uuid"}, hostUUIDs)
+		cmds = append(cmds, cmd)
+		return nil
+	}
+	ds.WipeHostViaWindowsMDMFunc = func(ctx context.Context, host *mdmlab.Host, cmd *mdmlab.MDMWindowsCommand) error {
+		cmds = append(cmds, cmd)
+		return nil
+	}
+	var activities []mdmlab.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time) error {
+		activities = append(activities, activity)
+		return nil
+	}
+	reset := func() { cmds, activities = nil, nil }
+
+	t.Run("restart", func(t *testing.T) {
+		reset()
+		require.NoError(t, svc.RestartHost(ctx, 1, true))
+		require.Len(t, cmds, 1)
+		require.Equal(t, mdmlab.MDMWindowsRebootLocURI, cmds[0].TargetLocURI)
+		require.Contains(t, string(cmds[0].RawCommand), "<CmdID>"+cmds[0].CommandUUID+"</CmdID>")
+		require.Contains(t, string(cmds[0].RawCommand), "<LocURI>"+mdmlab.MDMWindowsRebootLocURI+"</LocURI>")
+		require.Equal(t, []mdmlab.ActivityDetails{mdmlab.ActivityTypeRestartedHost{HostID: 1}}, activities)
+
+		// Apple MDM is not configured
+		err := svc.RestartHost(ctx, 2, false)
+		require.ErrorContains(t, err, mdmlab.AppleMDMNotConfiguredMessage)
+	})
+
+	t.Run("wipe", func(t *testing.T) {
+		reset()
+		require.NoError(t, svc.WipeHost(ctx, 1, mdmlab.MDMWipeOptions{}))
+		require.NoError(t, svc.WipeHost(ctx, 1, mdmlab.MDMWipeOptions{Windows: &mdmlab.MDMWindowsWipeOptions{WipeType: mdmlab.WindowsWipeTypeDoWipePersistProvisionedData}}))
+		require.Len(t, cmds, 2)
+		require.Equal(t, "./Device/Vendor/MSFT/RemoteWipe/doWipeProtected", cmds[0].TargetLocURI)
+		require.Equal(t, "./Device/Vendor/MSFT/RemoteWipe/doWipePersistProvisionedData", cmds[1].TargetLocURI)
+		require.Contains(t, string(cmds[1].RawCommand), "<LocURI>./Device/Vendor/MSFT/RemoteWipe/doWipePersistProvisionedData</LocURI>")
+		require.Equal(t, []mdmlab.ActivityDetails{
+			mdmlab.ActivityTypeWipedHost{HostID: 1, WindowsWipeType: mdmlab.WindowsWipeTypeDoWipeProtected},
+			mdmlab.ActivityTypeWipedHost{HostID: 1, WindowsWipeType: mdmlab.WindowsWipeTypeDoWipePersistProvisionedData},
+		}, activities)
+
+		err := svc.WipeHost(ctx, 1, mdmlab.MDMWipeOptions{Windows: &mdmlab.MDMWindowsWipeOptions{WipeType: "doNothing"}})
+		require.ErrorContains(t, err, "Unsupported wipe type: doNothing")
+
+		appCfg.MDM.EnabledAndConfigured = true
+		defer func() { appCfg.MDM.EnabledAndConfigured = false }()
+		err = svc.WipeHost(ctx, 2, mdmlab.MDMWipeOptions{Windows: &mdmlab.MDMWindowsWipeOptions{WipeType: mdmlab.WindowsWipeTypeDoWipe}})
+		require.ErrorContains(t, err, "The wipe type can only be set for Windows hosts.")
+	})
+
+	t.Run("rotate bitlocker recovery password", func(t *testing.T) {
+		reset()
+		require.NoError(t, svc.RotateHostBitLockerRecoveryPassword(ctx, 1))
+		require.Len(t, cmds, 1)
+		require.Equal(t, mdmlab.MDMWindowsRotateBitLockerLocURI, cmds[0].TargetLocURI)
+		require.Equal(t, []mdmlab.ActivityDetails{mdmlab.ActivityTypeRotatedHostBitLockerRecoveryPassword{HostID: 1}}, activities)
+
+		err := svc.RotateHostBitLockerRecoveryPassword(ctx, 2)
+		require.ErrorContains(t, err, "Unsupported host platform: darwin")
+
+		appCfg.MDM.WindowsEnabledAndConfigured = false
+		defer func() { appCfg.MDM.WindowsEnabledAndConfigured = true }()
+		err = svc.RotateHostBitLockerRecoveryPassword(ctx, 1)
+		require.ErrorContains(t, err, mdmlab.WindowsMDMNotConfiguredMessage)
+	})
+}
+
 func TestBulkOperationFilterValidation(t *testing.T) {
 	ds := new(mock.Store)
 	svc, ctx := newTestService(t, ds, nil, nil)
@@ -539,8 +539,16 @@ func (svc *Service) RunMDMCommand(ctx context.Context, rawBase64Cmd string, host
 }
 
 var appleMDMPremiumCommands = map[string]bool{
-	"EraseDevice": true,
-	"DeviceLock":  true,
+	"EraseDevice":         true,
+	"DeviceLock":          true,
+	"RestartDevice":       true,
+	"ShutDownDevice":      true,
+	"EnableLostMode":      true,
+	"DisableLostMode":     true,
+	"PlayLostModeSound":   true,
+	"DeviceLocation":      true,
+	"SetRecoveryLock":     true,
+	"SetFirmwarePassword": true,
 }
 
 func (svc *Service) enqueueAppleMDMCommand(ctx context.Context, rawXMLCmd []byte, deviceIDs []string) (result *mdmlab.CommandEnqueueResult, err error) {
@@ -976,7 +984,16 @@ func (svc *Service) authorizeAllHostsTeams(ctx context.Context, hostUUIDs []stri
 	if err != nil {
 		return nil, err
 	}
+	if err := svc.authorizeHostsTeams(ctx, hosts, authzAction, authorizer); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
 
+// authorizeHostsTeams authorizes the context user to execute the specified
+// authzAction with the specified authorizer for all the teams of the provided
+// hosts.
+func (svc *Service) authorizeHostsTeams(ctx context.Context, hosts []*mdmlab.Host, authzAction any, authorizer mdmlab.TeamIDSetter) error {
 	// collect the team IDs and verify that the user has access to run commands
 	// on all affected teams.
 	teamIDs := make(map[uint]bool, len(hosts))
@@ -996,10 +1013,10 @@ func (svc *Service) authorizeAllHostsTeams(ctx context.Context, hostUUIDs []stri
 		authorizer.SetTeamID(authzTeamID)
 
 		if err := svc.authz.Authorize(ctx, authorizer, authzAction); err != nil {
-			return nil, ctxerr.Wrap(ctx, err)
+			return ctxerr.Wrap(ctx, err)
 		}
 	}
-	return hosts, nil
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
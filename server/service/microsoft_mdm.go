@@ -1512,6 +1512,12 @@ func (svc *Service) processIncomingMDMCmds(ctx context.Context, deviceID string,
 		if err := svc.ds.MDMWindowsSaveResponse(ctx, deviceID, enrichedSyncML); err != nil {
 			return nil, fmt.Errorf("store incoming msgs: %w", err)
 		}
+
+		// errors processing the certificate inventory must not fail the
+		// session, the refetch is retried later on.
+		if err := svc.processHostCertificatesResults(ctx, deviceID, enrichedSyncML); err != nil {
+			logging.WithErr(ctx, ctxerr.Wrap(ctx, err, "process host certificates results"))
+		}
 	}
 
 	// Iterate over the operations and process them
@@ -403,6 +403,8 @@ func (svc *Service) GetOrbitConfig(ctx context.Context) (mdmlab.OrbitConfig, err
 			Notifications:    notifs,
 			NudgeConfig:      nudgeConfig,
 			UpdateChannels:   updateChannels,
+
+			SoftwareInstallerCacheURL: appConfig.SoftwareInstallerSettings.CacheURLForIP(host.PrimaryIP),
 		}, nil
 	}
 
@@ -478,6 +480,8 @@ func (svc *Service) GetOrbitConfig(ctx context.Context) (mdmlab.OrbitConfig, err
 		Notifications:    notifs,
 		NudgeConfig:      nudgeConfig,
 		UpdateChannels:   updateChannels,
+
+		SoftwareInstallerCacheURL: appConfig.SoftwareInstallerSettings.CacheURLForIP(host.PrimaryIP),
 	}, nil
 }
 
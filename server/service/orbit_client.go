@@ -413,6 +413,65 @@ func (oc *OrbitClient) DownloadSoftwareInstaller(installerID uint, downloadDirec
 	return resp.GetFilePath(), nil
 }
 
+// softwareInstallerCacheDownloadRequest is the body of the software installer
+// download request sent to the software installer caches. The node key is
+// never sent to a cache, the download is authorized by the cache token issued
+// by the MDMlab server with the install details.
+type softwareInstallerCacheDownloadRequest struct {
+	InstallerID uint   `json:"installer_id"`
+	CacheToken  string `json:"cache_token"`
+}
+
+// DownloadSoftwareInstallerFromCache downloads the software installer from the
+// software installer cache at cacheURL, the download is authorized by the
+// cache token of the install. The cache certificate must be trusted with the
+// same root CA as the MDMlab server.
+func (oc *OrbitClient) DownloadSoftwareInstallerFromCache(cacheURL string, cacheToken string, installerID uint, downloadDirectory string) (string, error) {
+	verb, path := "POST", "/api/mdmlab/orbit/software_install/package"
+	u, err := url.Parse(cacheURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing cache URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("invalid cache URL %q: an https URL is required", u.Redacted())
+	}
+	u.Path = path
+	u.RawQuery = "alt=media"
+
+	bodyBytes, err := json.Marshal(&softwareInstallerCacheDownloadRequest{
+		InstallerID: installerID,
+		CacheToken:  cacheToken,
+	})
+	if err != nil {
+		return "", fmt.Errorf("making request json marshalling : %w", err)
+	}
+	request, err := http.NewRequest(verb, u.String(), bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return "", err
+	}
+	// errors of the cache are not recorded as errors of the server, and
+	// don't invalidate the node key.
+	response, err := oc.http.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", verb, u.Redacted(), err)
+	}
+	defer response.Body.Close()
+
+	// the response is not handled with parseResponse, as the cache doesn't
+	// send the capabilities of the server.
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s received status %w", verb, path, &statusCodeErr{
+			code: response.StatusCode,
+			body: extractServerErrorText(response.Body),
+		})
+	}
+	resp := FileResponse{DestPath: downloadDirectory}
+	if err := resp.Handle(response); err != nil {
+		return "", fmt.Errorf("%s %s error with custom body handler contents: %w", verb, path, err)
+	}
+	return resp.GetFilePath(), nil
+}
+
 type NullFileResponse struct{}
 
 func (f *NullFileResponse) Handle(resp *http.Response) error {
@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -179,3 +183,46 @@ func TestExecuteConfigReceiversInterrupt(t *testing.T) {
 		require.Fail(t, "receiver interrupt cancel didn't work")
 	}
 }
+
+func TestDownloadSoftwareInstallerFromCache(t *testing.T) {
+	cache := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/mdmlab/orbit/software_install/package", r.URL.Path)
+		require.Equal(t, "media", r.URL.Query().Get("alt"))
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		// the node key of the host is never sent to the cache
+		require.NotContains(t, req, "orbit_node_key")
+		require.EqualValues(t, 1, req["installer_id"])
+		if req["cache_token"] != "token-1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Disposition", `attachment;filename="app.pkg"`)
+		_, _ = w.Write([]byte("installer"))
+	}))
+	defer cache.Close()
+
+	oc := &OrbitClient{
+		baseClient:  &baseClient{http: cache.Client()},
+		TestNodeKey: "node-key",
+	}
+	oc.serverCapabilities.PopulateFromString(string(mdmlab.CapabilityOrbitEndpoints))
+
+	dir := t.TempDir()
+	path, err := oc.DownloadSoftwareInstallerFromCache(cache.URL, "token-1", 1, dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "app.pkg"), path)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "installer", string(b))
+
+	_, err = oc.DownloadSoftwareInstallerFromCache(cache.URL, "token-2", 1, dir)
+	require.ErrorContains(t, err, "received status 403")
+
+	// the installers are only downloaded from caches over HTTPS
+	_, err = oc.DownloadSoftwareInstallerFromCache("http://cache.example.com", "token-1", 1, dir)
+	require.ErrorContains(t, err, "an https URL is required")
+
+	// the responses of the cache don't change the capabilities of the server
+	require.True(t, oc.GetServerCapabilities().Has(mdmlab.CapabilityOrbitEndpoints))
+}
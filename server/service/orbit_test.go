@@ -622,6 +622,68 @@ func TestGetOrbitConfigNudge(t *testing.T) {
 	})
 }
 
+func TestGetOrbitConfigSoftwareInstallerCache(t *testing.T) {
+	ds := new(mock.Store)
+	license := &mdmlab.LicenseInfo{Tier: mdmlab.TierPremium}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+	host := &mdmlab.Host{
+		OsqueryHostID: ptr.String("test"),
+		ID:            1,
+		Platform:      "windows",
+		PrimaryIP:     "10.1.2.3",
+	}
+
+	ds.TeamAgentOptionsFunc = func(ctx context.Context, id uint) (*json.RawMessage, error) {
+		return ptr.RawMessage(json.RawMessage(`{}`)), nil
+	}
+	ds.TeamMDMConfigFunc = func(ctx context.Context, teamID uint) (*mdmlab.TeamMDM, error) {
+		return &mdmlab.TeamMDM{}, nil
+	}
+	ds.ListPendingHostScriptExecutionsFunc = func(ctx context.Context, hostID uint, onlyShowInternal bool) ([]*mdmlab.HostScriptResult, error) {
+		return nil, nil
+	}
+	ds.ListPendingSoftwareInstallsFunc = func(ctx context.Context, hostID uint) ([]string, error) {
+		return nil, nil
+	}
+	ds.IsHostConnectedToMDMlabMDMFunc = func(ctx context.Context, host *mdmlab.Host) (bool, error) {
+		return false, nil
+	}
+	ds.GetHostMDMFunc = func(ctx context.Context, hostID uint) (*mdmlab.HostMDM, error) {
+		return nil, nil
+	}
+	appCfg := &mdmlab.AppConfig{
+		SoftwareInstallerSettings: mdmlab.SoftwareInstallerSettings{
+			Caches: []mdmlab.SoftwareInstallerCache{
+				{URL: "https://cache1.example.com", Subnets: []string{"10.1.0.0/16"}},
+				{URL: "https://cache2.example.com", Subnets: []string{"192.168.0.0/24"}},
+			},
+		},
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return appCfg, nil
+	}
+
+	ctx = test.HostContext(ctx, host)
+
+	// no-team
+	cfg, err := svc.GetOrbitConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "https://cache1.example.com", cfg.SoftwareInstallerCacheURL)
+
+	// with team
+	host.TeamID = ptr.Uint(1)
+	host.PrimaryIP = "192.168.0.10"
+	cfg, err = svc.GetOrbitConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "https://cache2.example.com", cfg.SoftwareInstallerCacheURL)
+
+	// no cache for the host's subnet
+	host.PrimaryIP = "172.16.0.1"
+	cfg, err = svc.GetOrbitConfig(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cfg.SoftwareInstallerCacheURL)
+}
+
 func TestGetSoftwareInstallDetails(t *testing.T) {
 	t.Run("hosts can't get each others installers", func(t *testing.T) {
 		ds := new(mock.Store)
@@ -633,6 +695,9 @@ func TestGetSoftwareInstallDetails(t *testing.T) {
 				HostID: 1,
 			}, nil
 		}
+		ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+			return &mdmlab.AppConfig{}, nil
+		}
 
 		ds.GetHostMDMFunc = func(ctx context.Context, hostID uint) (*mdmlab.HostMDM, error) {
 			return &mdmlab.HostMDM{
@@ -656,6 +721,8 @@ func TestGetSoftwareInstallDetails(t *testing.T) {
 		d1, err := svc.GetSoftwareInstallDetails(goodCtx, "")
 		require.NoError(t, err)
 		require.Equal(t, uint(1), d1.HostID)
+		// no cache serves the host
+		require.Empty(t, d1.SoftwareInstallerCacheToken)
 
 		d2, err := svc.GetSoftwareInstallDetails(badCtx, "")
 		require.Error(t, err)
@@ -1252,6 +1252,9 @@ func preProcessSoftwareResults(
 	vsCodeExtensionsExtraQuery := hostDetailQueryPrefix + "software_vscode_extensions"
 	preProcessSoftwareExtraResults(vsCodeExtensionsExtraQuery, host.ID, results, statuses, messages, osquery_utils.DetailQuery{}, logger)
 
+	universalPackagesExtraQuery := hostDetailQueryPrefix + "software_linux_universal_packages"
+	preProcessSoftwareExtraResults(universalPackagesExtraQuery, host.ID, results, statuses, messages, osquery_utils.DetailQuery{}, logger)
+
 	for name, query := range overrides {
 		fullQueryName := hostDetailQueryPrefix + "software_" + name
 		preProcessSoftwareExtraResults(fullQueryName, host.ID, results, statuses, messages, query, logger)
@@ -1814,6 +1817,16 @@ func (svc *Service) processSoftwareForNewlyFailingPolicies(
 			level.Debug(logger).Log("msg", "not marking policy as failed since software is out of scope for host")
 			continue
 		}
+		inRollout, err := svc.ds.IsSoftwareInstallerRolloutScoped(ctx, failingPolicyWithInstaller.InstallerID, hostID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "checking if software installer rollout is scoped to host")
+		}
+		if !inRollout {
+			// The install is queued by the rollouts cron once the host is targeted
+			// by a stage of the rollout.
+			level.Debug(logger).Log("msg", "host not yet targeted by the software installer rollout")
+			continue
+		}
 		hostLastInstall, err := svc.ds.GetHostLastInstallData(ctx, hostID, installerMetadata.InstallerID)
 		if err != nil {
 			return ctxerr.Wrap(ctx, err, "get host last install data")
@@ -1060,6 +1060,7 @@ func verifyDiscovery(t *testing.T, queries, discovery map[string]string) {
 		hostDetailQueryPrefix + "kubequery_info":             {},
 		hostDetailQueryPrefix + "orbit_info":                 {},
 		hostDetailQueryPrefix + "software_vscode_extensions": {},
+		hostDetailQueryPrefix + "software_linux_universal_packages": {},
 		hostDetailQueryPrefix + "software_macos_firefox":     {},
 		hostDetailQueryPrefix + "battery":                    {},
 		hostDetailQueryPrefix + "software_macos_codesign":    {},
@@ -3653,6 +3654,24 @@ func TestPreProcessSoftwareResults(t *testing.T) {
 		"last_opened_at":    "",
 		"installed_path":    "/some/zoobar/path",
 	}
+	debPackage := map[string]string{
+		"name":           "curl",
+		"version":        "8.5.0",
+		"source":         "deb_packages",
+		"installed_path": "",
+	}
+	snapPackage := map[string]string{
+		"name":           "firefox",
+		"version":        "131.0.3-1",
+		"source":         "snap_packages",
+		"installed_path": "/snap/firefox/current",
+	}
+	flatpakPackage := map[string]string{
+		"name":           "org.gnome.Calculator",
+		"version":        "47.1",
+		"source":         "flatpak_packages",
+		"installed_path": "/var/lib/flatpak/app/org.gnome.Calculator",
+	}
 	someRow := map[string]string{
 		"1": "1",
 	}
@@ -3726,6 +3745,31 @@ func TestPreProcessSoftwareResults(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "linux software query works and there are universal packages in extra",
+
+			statusesIn: map[string]mdmlab.OsqueryStatus{
+				hostDetailQueryPrefix + "software_linux":                    mdmlab.StatusOK,
+				hostDetailQueryPrefix + "software_linux_universal_packages": mdmlab.StatusOK,
+			},
+			resultsIn: mdmlab.OsqueryDistributedQueryResults{
+				hostDetailQueryPrefix + "software_linux": []map[string]string{
+					debPackage,
+				},
+				hostDetailQueryPrefix + "software_linux_universal_packages": []map[string]string{
+					snapPackage,
+					flatpakPackage,
+				},
+			},
+
+			resultsOut: mdmlab.OsqueryDistributedQueryResults{
+				hostDetailQueryPrefix + "software_linux": []map[string]string{
+					debPackage,
+					snapPackage,
+					flatpakPackage,
+				},
+			},
+		},
 		{
 			name: "software query and extra works and there are no vscode extensions",
 
@@ -942,6 +942,29 @@ FROM cached_users CROSS JOIN vscode_extensions USING (uid)`),
 	// the results of this query are appended to the results of the other software queries.
 }
 
+// softwareLinuxUniversalPackages collects the snap, flatpak and AppImage
+// packages from the universal_packages table of mdmlabd on a separate query
+// because the table is not available on hosts without mdmlabd (or with older
+// versions of it).
+var softwareLinuxUniversalPackages = DetailQuery{
+	Query: `
+SELECT
+  name AS name,
+  version AS version,
+  '' AS extension_id,
+  '' AS browser,
+  source || '_packages' AS source,
+  '' AS release,
+  '' AS vendor,
+  '' AS arch,
+  path AS installed_path
+FROM universal_packages`,
+	Platforms: mdmlab.HostLinuxOSs,
+	Discovery: discoveryTable("universal_packages"),
+	// Has no IngestFunc, DirectIngestFunc or DirectTaskIngestFunc because
+	// the results of this query are appended to the results of the other software queries.
+}
+
 var scheduledQueryStats = DetailQuery{
 	Query: `
 			SELECT *,
@@ -2216,6 +2239,7 @@ func GetDetailQueries(
 		generatedMap["software_windows"] = softwareWindows
 		generatedMap["software_chrome"] = softwareChrome
 		generatedMap["software_vscode_extensions"] = softwareVSCodeExtensions
+		generatedMap["software_linux_universal_packages"] = softwareLinuxUniversalPackages
 
 		for key, query := range SoftwareOverrideQueries {
 			generatedMap["software_"+key] = query
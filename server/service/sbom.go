@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/sbom"
+	"github.com/it-laborato/MDM_Lab/server/version"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get host SBOM
+/////////////////////////////////////////////////////////////////////////////////
+
+type getHostSBOMRequest struct {
+	ID     uint   `url:"id"`
+	Format string `query:"format,optional"`
+}
+
+func getHostSBOMEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getHostSBOMRequest)
+	format := sbomFormatOrDefault(req.Format)
+	b, err := svc.HostSBOM(ctx, req.ID, format)
+	if err != nil {
+		return downloadFileResponse{Err: err}, nil
+	}
+	return downloadFileResponse{
+		content:     b,
+		filename:    fmt.Sprintf("%s host %d.%s.json", time.Now().Format(time.DateOnly), req.ID, format),
+		contentType: "application/json",
+	}, nil
+}
+
+func (svc *Service) HostSBOM(ctx context.Context, hostID uint, format string) ([]byte, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, err
+	}
+
+	host, err := svc.ds.HostLite(ctx, hostID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get host")
+	}
+	if err := svc.authz.Authorize(ctx, host, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	sbomFormat, err := sbom.ParseFormat(format)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("format", err.Error()))
+	}
+
+	opts, err := svc.sbomSoftwareListOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.HostID = &host.ID
+	software, _, err := svc.ds.ListSoftware(ctx, opts)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list host software")
+	}
+
+	subject := sbom.Subject{Name: host.DisplayName(), IsHost: true}
+	if mdmlab.IsLinux(host.Platform) {
+		// the platform reported by osquery is the distribution ID, e.g. "ubuntu" or "rhel"
+		subject.Distro = host.Platform
+	}
+	return svc.generateSBOM(ctx, sbomFormat, subject, software)
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get team SBOM
+/////////////////////////////////////////////////////////////////////////////////
+
+type getTeamSBOMRequest struct {
+	TeamID *uint  `query:"team_id,optional"`
+	Format string `query:"format,optional"`
+}
+
+func getTeamSBOMEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getTeamSBOMRequest)
+	format := sbomFormatOrDefault(req.Format)
+	b, err := svc.TeamSBOM(ctx, req.TeamID, format)
+	if err != nil {
+		return downloadFileResponse{Err: err}, nil
+	}
+	scope := "all teams"
+	if req.TeamID != nil {
+		scope = fmt.Sprintf("team %d", *req.TeamID)
+	}
+	return downloadFileResponse{
+		content:     b,
+		filename:    fmt.Sprintf("%s %s.%s.json", time.Now().Format(time.DateOnly), scope, format),
+		contentType: "application/json",
+	}, nil
+}
+
+func (svc *Service) TeamSBOM(ctx context.Context, teamID *uint, format string) ([]byte, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.AuthzSoftwareInventory{
+		TeamID: teamID,
+	}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	sbomFormat, err := sbom.ParseFormat(format)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("format", err.Error()))
+	}
+
+	subject := sbom.Subject{Name: "All teams"}
+	if teamID != nil {
+		if *teamID == 0 {
+			subject.Name = "No team"
+		} else {
+			team, err := svc.ds.Team(ctx, *teamID)
+			if err != nil {
+				return nil, ctxerr.Wrap(ctx, err, "get team")
+			}
+			subject.Name = team.Name
+		}
+	}
+
+	opts, err := svc.sbomSoftwareListOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.TeamID = teamID
+	software, _, err := svc.ds.ListSoftware(ctx, opts)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list team software")
+	}
+
+	return svc.generateSBOM(ctx, sbomFormat, subject, software)
+}
+
+// sbomSoftwareListOptions returns the options used to list the software included in an SBOM, the
+// details of the vulnerabilities are only included in premium.
+func (svc *Service) sbomSoftwareListOptions(ctx context.Context) (mdmlab.SoftwareListOptions, error) {
+	lic, err := svc.License(ctx)
+	if err != nil {
+		return mdmlab.SoftwareListOptions{}, ctxerr.Wrap(ctx, err, "get license")
+	}
+	return mdmlab.SoftwareListOptions{
+		ListOptions:      mdmlab.ListOptions{OrderKey: "name", OrderDirection: mdmlab.OrderAscending},
+		IncludeCVEScores: lic.IsPremium(),
+	}, nil
+}
+
+func (svc *Service) generateSBOM(ctx context.Context, format sbom.Format, subject sbom.Subject, software []mdmlab.Software) ([]byte, error) {
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get app config")
+	}
+
+	b, err := sbom.Generate(format, subject, software, sbom.Options{
+		ToolVersion: version.Version().Version,
+		Namespace:   appConfig.ServerSettings.ServerURL,
+		Now:         time.Now(),
+	})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "generate SBOM")
+	}
+	return b, nil
+}
+
+func sbomFormatOrDefault(format string) string {
+	if format == "" {
+		return string(sbom.FormatCycloneDX)
+	}
+	return format
+}
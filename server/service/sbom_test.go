@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSBOM(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	ds.HostLiteFunc = func(ctx context.Context, hostID uint) (*mdmlab.Host, error) {
+		return &mdmlab.Host{ID: hostID, TeamID: ptr.Uint(1), Hostname: "web-1", Platform: "ubuntu"}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*mdmlab.Team, error) {
+		return &mdmlab.Team{ID: tid, Name: "Servers"}, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{ServerSettings: mdmlab.ServerSettings{ServerURL: "https://mdmlab.example.com"}}, nil
+	}
+	var listOpts mdmlab.SoftwareListOptions
+	ds.ListSoftwareFunc = func(ctx context.Context, opt mdmlab.SoftwareListOptions) ([]mdmlab.Software, *mdmlab.PaginationMetadata, error) {
+		listOpts = opt
+		return []mdmlab.Software{
+			{
+				ID: 1, Name: "openssl", Version: "3.0.2", Source: "deb_packages",
+				Vulnerabilities: mdmlab.Vulnerabilities{{CVE: "CVE-2024-0001"}},
+			},
+		}, nil, nil
+	}
+
+	adminCtx := viewer.NewContext(ctx, viewer.Viewer{User: test.UserAdmin})
+	b, err := svc.HostSBOM(adminCtx, 3, "cyclonedx")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Uint(3), listOpts.HostID)
+	require.False(t, listOpts.IncludeCVEScores)
+
+	var doc struct {
+		BOMFormat  string `json:"bomFormat"`
+		Components []struct {
+			PURL string `json:"purl"`
+		} `json:"components"`
+		Vulnerabilities []struct {
+			ID string `json:"id"`
+		} `json:"vulnerabilities"`
+	}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Equal(t, "CycloneDX", doc.BOMFormat)
+	require.Len(t, doc.Components, 1)
+	require.Equal(t, "pkg:deb/ubuntu/openssl@3.0.2", doc.Components[0].PURL)
+	require.Len(t, doc.Vulnerabilities, 1)
+
+	b, err = svc.TeamSBOM(adminCtx, ptr.Uint(1), "spdx")
+	require.NoError(t, err)
+	require.Equal(t, ptr.Uint(1), listOpts.TeamID)
+	require.Nil(t, listOpts.HostID)
+	require.Contains(t, string(b), `"name": "Servers"`)
+	require.Contains(t, string(b), "https://mdmlab.example.com/sbom/Servers-")
+
+	_, err = svc.TeamSBOM(adminCtx, nil, "swid")
+	var invalidArgErr *mdmlab.InvalidArgumentError
+	require.ErrorAs(t, err, &invalidArgErr)
+
+	// team users can only export the SBOM of their team
+	teamCtx := viewer.NewContext(ctx, viewer.Viewer{User: test.UserTeamObserverTeam1})
+	_, err = svc.HostSBOM(teamCtx, 3, "spdx")
+	require.NoError(t, err)
+	_, err = svc.TeamSBOM(teamCtx, ptr.Uint(1), "spdx")
+	require.NoError(t, err)
+	_, err = svc.TeamSBOM(teamCtx, ptr.Uint(2), "spdx")
+	checkAuthErr(t, true, err)
+	_, err = svc.TeamSBOM(teamCtx, nil, "spdx")
+	checkAuthErr(t, true, err)
+
+	teamCtx = viewer.NewContext(ctx, viewer.Viewer{User: test.UserTeamObserverTeam2})
+	_, err = svc.HostSBOM(teamCtx, 3, "spdx")
+	checkAuthErr(t, true, err)
+
+	// no user
+	_, err = svc.HostSBOM(ctx, 3, "spdx")
+	checkAuthErr(t, true, err)
+}
@@ -1033,7 +1033,8 @@ func (svc *Service) UnlockHost(ctx context.Context, hostID uint) (string, error)
 ////////////////////////////////////////////////////////////////////////////////
 
 type wipeHostRequest struct {
-	HostID uint `url:"id"`
+	HostID  uint                          `url:"id"`
+	Windows *mdmlab.MDMWindowsWipeOptions `json:"windows"`
 }
 
 type wipeHostResponse struct {
@@ -1045,13 +1046,13 @@ func (r wipeHostResponse) error() error { return r.Err }
 
 func wipeHostEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
 	req := request.(*wipeHostRequest)
-	if err := svc.WipeHost(ctx, req.HostID); err != nil {
+	if err := svc.WipeHost(ctx, req.HostID, mdmlab.MDMWipeOptions{Windows: req.Windows}); err != nil {
 		return wipeHostResponse{Err: err}, nil
 	}
 	return wipeHostResponse{}, nil
 }
 
-func (svc *Service) WipeHost(ctx context.Context, hostID uint) error {
+func (svc *Service) WipeHost(ctx context.Context, hostID uint, opts mdmlab.MDMWipeOptions) error {
 	// skipauth: No authorization check needed due to implementation returning
 	// only license error.
 	svc.authz.SkipAuthorization(ctx)
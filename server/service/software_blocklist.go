@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// List software blocklist rules
+/////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareBlocklistRulesRequest struct {
+	TeamID *uint `query:"team_id,optional"`
+}
+
+type listSoftwareBlocklistRulesResponse struct {
+	Rules []*mdmlab.SoftwareBlocklistRule `json:"rules"`
+	Err   error                           `json:"error,omitempty"`
+}
+
+func (r listSoftwareBlocklistRulesResponse) error() error { return r.Err }
+
+func listSoftwareBlocklistRulesEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listSoftwareBlocklistRulesRequest)
+	rules, err := svc.ListSoftwareBlocklistRules(ctx, req.TeamID)
+	if err != nil {
+		return listSoftwareBlocklistRulesResponse{Err: err}, nil
+	}
+	if rules == nil {
+		rules = []*mdmlab.SoftwareBlocklistRule{}
+	}
+	return listSoftwareBlocklistRulesResponse{Rules: rules}, nil
+}
+
+func (svc *Service) ListSoftwareBlocklistRules(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareBlocklistRule, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get, delete software blocklist rule
+/////////////////////////////////////////////////////////////////////////////////
+
+type getSoftwareBlocklistRuleRequest struct {
+	ID uint `url:"id"`
+}
+
+type getSoftwareBlocklistRuleResponse struct {
+	Rule *mdmlab.SoftwareBlocklistRule `json:"rule,omitempty"`
+	Err  error                         `json:"error,omitempty"`
+}
+
+func (r getSoftwareBlocklistRuleResponse) error() error { return r.Err }
+
+func getSoftwareBlocklistRuleEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareBlocklistRuleRequest)
+	rule, err := svc.GetSoftwareBlocklistRule(ctx, req.ID)
+	if err != nil {
+		return getSoftwareBlocklistRuleResponse{Err: err}, nil
+	}
+	return getSoftwareBlocklistRuleResponse{Rule: rule}, nil
+}
+
+type deleteSoftwareBlocklistRuleResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteSoftwareBlocklistRuleResponse) error() error { return r.Err }
+func (r deleteSoftwareBlocklistRuleResponse) Status() int  { return http.StatusNoContent }
+
+func deleteSoftwareBlocklistRuleEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareBlocklistRuleRequest)
+	if err := svc.DeleteSoftwareBlocklistRule(ctx, req.ID); err != nil {
+		return deleteSoftwareBlocklistRuleResponse{Err: err}, nil
+	}
+	return deleteSoftwareBlocklistRuleResponse{}, nil
+}
+
+func (svc *Service) GetSoftwareBlocklistRule(ctx context.Context, id uint) (*mdmlab.SoftwareBlocklistRule, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) DeleteSoftwareBlocklistRule(ctx context.Context, id uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Create, modify software blocklist rule
+/////////////////////////////////////////////////////////////////////////////////
+
+type createSoftwareBlocklistRuleRequest struct {
+	TeamID           *uint    `json:"team_id"`
+	Name             string   `json:"name"`
+	BundleIdentifier string   `json:"bundle_identifier"`
+	Source           string   `json:"source"`
+	MinVersion       string   `json:"min_version"`
+	MaxVersion       string   `json:"max_version"`
+	ScriptID         *uint    `json:"script_id"`
+	LabelsExcludeAny []string `json:"labels_exclude_any"`
+}
+
+func (r *createSoftwareBlocklistRuleRequest) payload() *mdmlab.SoftwareBlocklistRulePayload {
+	return &mdmlab.SoftwareBlocklistRulePayload{
+		TeamID:           r.TeamID,
+		Name:             strings.TrimSpace(r.Name),
+		BundleIdentifier: strings.TrimSpace(r.BundleIdentifier),
+		Source:           strings.TrimSpace(r.Source),
+		MinVersion:       strings.TrimSpace(r.MinVersion),
+		MaxVersion:       strings.TrimSpace(r.MaxVersion),
+		ScriptID:         r.ScriptID,
+		LabelsExcludeAny: r.LabelsExcludeAny,
+	}
+}
+
+func createSoftwareBlocklistRuleEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*createSoftwareBlocklistRuleRequest)
+	rule, err := svc.CreateSoftwareBlocklistRule(ctx, req.payload())
+	if err != nil {
+		return getSoftwareBlocklistRuleResponse{Err: err}, nil
+	}
+	return getSoftwareBlocklistRuleResponse{Rule: rule}, nil
+}
+
+type modifySoftwareBlocklistRuleRequest struct {
+	ID uint `url:"id"`
+	createSoftwareBlocklistRuleRequest
+}
+
+func modifySoftwareBlocklistRuleEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*modifySoftwareBlocklistRuleRequest)
+	rule, err := svc.ModifySoftwareBlocklistRule(ctx, req.ID, req.payload())
+	if err != nil {
+		return getSoftwareBlocklistRuleResponse{Err: err}, nil
+	}
+	return getSoftwareBlocklistRuleResponse{Rule: rule}, nil
+}
+
+func (svc *Service) CreateSoftwareBlocklistRule(ctx context.Context, payload *mdmlab.SoftwareBlocklistRulePayload) (*mdmlab.SoftwareBlocklistRule, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) ModifySoftwareBlocklistRule(ctx context.Context, id uint, payload *mdmlab.SoftwareBlocklistRulePayload) (*mdmlab.SoftwareBlocklistRule, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// List software blocklist rule hosts
+/////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareBlocklistRuleHostsResponse struct {
+	Hosts []*mdmlab.SoftwareBlocklistHost `json:"hosts"`
+	Err   error                           `json:"error,omitempty"`
+}
+
+func (r listSoftwareBlocklistRuleHostsResponse) error() error { return r.Err }
+
+func listSoftwareBlocklistRuleHostsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareBlocklistRuleRequest)
+	hosts, err := svc.ListSoftwareBlocklistRuleHosts(ctx, req.ID)
+	if err != nil {
+		return listSoftwareBlocklistRuleHostsResponse{Err: err}, nil
+	}
+	if hosts == nil {
+		hosts = []*mdmlab.SoftwareBlocklistHost{}
+	}
+	return listSoftwareBlocklistRuleHostsResponse{Hosts: hosts}, nil
+}
+
+func (svc *Service) ListSoftwareBlocklistRuleHosts(ctx context.Context, id uint) ([]*mdmlab.SoftwareBlocklistHost, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Software blocklist cron
+/////////////////////////////////////////////////////////////////////////////////
+
+// ProcessSoftwareBlocklist removes the blocklisted software from the hosts
+// that are not excepted from the rules. The software is removed by running the
+// removal script of the rule, or the uninstall script of the installer of the
+// software title. The removal is retried after
+// mdmlab.SoftwareBlocklistRemovalRetryInterval if the software is still
+// reported by the host.
+func ProcessSoftwareBlocklist(ctx context.Context, ds mdmlab.Datastore, logger kitlog.Logger, now time.Time) error {
+	rules, err := ds.ListAllSoftwareBlocklistRules(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list software blocklist rules")
+	}
+
+	for _, rule := range rules {
+		logger := kitlog.With(logger, "software_blocklist_rule_id", rule.ID)
+		if err := processSoftwareBlocklistRule(ctx, ds, logger, rule, now); err != nil {
+			// keep processing the other rules
+			level.Error(logger).Log("msg", "process software blocklist rule", "err", err)
+			ctxerr.Handle(ctx, err)
+		}
+	}
+	return nil
+}
+
+func processSoftwareBlocklistRule(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	rule *mdmlab.SoftwareBlocklistRule,
+	now time.Time,
+) error {
+	hosts, err := ds.ListSoftwareBlocklistRuleHosts(ctx, rule)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list software blocklist rule hosts")
+	}
+
+	var script *mdmlab.Script
+	if rule.ScriptID != nil {
+		script, err = ds.Script(ctx, *rule.ScriptID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "get software blocklist rule script")
+		}
+	}
+
+	var teamID uint
+	if rule.TeamID != nil {
+		teamID = *rule.TeamID
+	}
+
+	removed := make(map[uint]bool, len(hosts))
+	for _, host := range hosts {
+		if host.Excepted || removed[host.HostID] {
+			continue
+		}
+		if host.RemovalStatus != nil {
+			if *host.RemovalStatus == mdmlab.SoftwareBlocklistRemovalPending {
+				continue
+			}
+			if host.RemovalRequestedAt != nil && now.Sub(*host.RemovalRequestedAt) < mdmlab.SoftwareBlocklistRemovalRetryInterval {
+				continue
+			}
+		}
+
+		logger := kitlog.With(logger, "host_id", host.HostID, "software_id", host.SoftwareID)
+		var executionID string
+		if script != nil {
+			executionID, err = runSoftwareBlocklistScript(ctx, ds, logger, script, teamID, host)
+		} else {
+			executionID, err = runSoftwareBlocklistUninstall(ctx, ds, logger, rule.TeamID, host)
+		}
+		if err != nil {
+			// a failure on a host must not prevent the removal on the other
+			// hosts, it is retried on the next run.
+			level.Error(logger).Log("msg", "request blocklisted software removal", "err", err)
+			ctxerr.Handle(ctx, err)
+			continue
+		}
+		if executionID == "" {
+			continue
+		}
+
+		if err := ds.NewSoftwareBlocklistRemoval(ctx, rule.ID, host.HostID, executionID); err != nil {
+			err = ctxerr.Wrap(ctx, err, "record software blocklist removal")
+			level.Error(logger).Log("msg", "record blocklisted software removal", "err", err)
+			ctxerr.Handle(ctx, err)
+			continue
+		}
+		// a single removal per host, a script removes all the versions of the
+		// software.
+		removed[host.HostID] = true
+		level.Debug(logger).Log("msg", "blocklisted software removal requested", "execution_id", executionID)
+	}
+	return nil
+}
+
+// runSoftwareBlocklistScript queues the removal script of the rule on the
+// host, it returns an empty execution ID if the script can't run on the host.
+func runSoftwareBlocklistScript(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	script *mdmlab.Script,
+	teamID uint,
+	host *mdmlab.SoftwareBlocklistHost,
+) (string, error) {
+	// skip incompatible scripts
+	hostPlatform := mdmlab.PlatformFromHost(host.HostPlatform)
+	if (hostPlatform == "windows" && strings.HasSuffix(script.Name, ".sh")) ||
+		(hostPlatform != "windows" && strings.HasSuffix(script.Name, ".ps1")) {
+		level.Debug(logger).Log("msg", "script type does not match host platform", "script_id", script.ID)
+		return "", nil
+	}
+
+	pending, err := ds.IsExecutionPendingForHost(ctx, host.HostID, script.ID)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "check whether script is pending execution")
+	}
+	if pending {
+		return "", nil
+	}
+
+	contents, err := ds.GetScriptContents(ctx, script.ID)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "get script contents")
+	}
+	scriptResult, err := ds.NewHostScriptExecutionRequest(ctx, &mdmlab.HostScriptRequestPayload{
+		HostID:          host.HostID,
+		ScriptContents:  string(contents),
+		ScriptContentID: script.ScriptContentID,
+		ScriptID:        &script.ID,
+		TeamID:          teamID,
+		// no user ID as scripts are executed by MDMlab
+	})
+	if err != nil {
+		return "", ctxerr.Wrapf(ctx, err, "insert script run request; host_id=%d, script_id=%d", host.HostID, script.ID)
+	}
+	return scriptResult.ExecutionID, nil
+}
+
+// runSoftwareBlocklistUninstall queues the uninstall of the software title on
+// the host, it returns an empty execution ID if the title has no installer
+// for the host.
+func runSoftwareBlocklistUninstall(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	teamID *uint,
+	host *mdmlab.SoftwareBlocklistHost,
+) (string, error) {
+	if host.SoftwareTitleID == nil {
+		return "", nil
+	}
+
+	installer, err := ds.GetSoftwareInstallerMetadataByTeamAndTitleID(ctx, teamID, *host.SoftwareTitleID, false)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			level.Debug(logger).Log("msg", "no installer to uninstall blocklisted software", "software_title_id", *host.SoftwareTitleID)
+			return "", nil
+		}
+		return "", ctxerr.Wrap(ctx, err, "get software installer of blocklisted software")
+	}
+	if mdmlab.PlatformFromHost(host.HostPlatform) != installer.Platform {
+		return "", nil
+	}
+
+	lastInstall, err := ds.GetHostLastInstallData(ctx, host.HostID, installer.InstallerID)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "get host last install data")
+	}
+	if lastInstall != nil && lastInstall.Status != nil &&
+		(*lastInstall.Status == mdmlab.SoftwareInstallPending || *lastInstall.Status == mdmlab.SoftwareUninstallPending) {
+		return "", nil
+	}
+
+	contents, err := ds.GetAnyScriptContents(ctx, installer.UninstallScriptContentID)
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "get uninstall script contents")
+	}
+	var scriptTeamID uint
+	if teamID != nil {
+		scriptTeamID = *teamID
+	}
+	scriptResult, err := ds.NewInternalScriptExecutionRequest(ctx, &mdmlab.HostScriptRequestPayload{
+		HostID:          host.HostID,
+		ScriptContents:  string(contents),
+		ScriptContentID: installer.UninstallScriptContentID,
+		TeamID:          scriptTeamID,
+	})
+	if err != nil {
+		return "", ctxerr.Wrap(ctx, err, "create uninstall script execution request")
+	}
+	if err := ds.InsertSoftwareUninstallRequest(ctx, scriptResult.ExecutionID, host.HostID, installer.InstallerID); err != nil {
+		return "", ctxerr.Wrap(ctx, err, "insert software uninstall request")
+	}
+	return scriptResult.ExecutionID, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSoftwareBlocklist(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	now := time.Now().UTC()
+
+	var rule *mdmlab.SoftwareBlocklistRule
+	ds.ListAllSoftwareBlocklistRulesFunc = func(ctx context.Context) ([]*mdmlab.SoftwareBlocklistRule, error) {
+		return []*mdmlab.SoftwareBlocklistRule{rule}, nil
+	}
+
+	pending := mdmlab.SoftwareBlocklistRemovalPending
+	failed := mdmlab.SoftwareBlocklistRemovalFailed
+	recent, old := now.Add(-time.Hour), now.Add(-25*time.Hour)
+	ds.ListSoftwareBlocklistRuleHostsFunc = func(ctx context.Context, r *mdmlab.SoftwareBlocklistRule) ([]*mdmlab.SoftwareBlocklistHost, error) {
+		return []*mdmlab.SoftwareBlocklistHost{
+			// two versions of the software on the same host
+			{HostID: 10, HostPlatform: "darwin", SoftwareID: 1, SoftwareTitleID: ptr.Uint(3), Version: "1.0"},
+			{HostID: 10, HostPlatform: "darwin", SoftwareID: 2, SoftwareTitleID: ptr.Uint(3), Version: "1.1"},
+			{HostID: 11, HostPlatform: "windows", SoftwareID: 1, SoftwareTitleID: ptr.Uint(3)},
+			{HostID: 12, HostPlatform: "darwin", SoftwareID: 1, SoftwareTitleID: ptr.Uint(3), Excepted: true},
+			{HostID: 13, HostPlatform: "darwin", SoftwareID: 1, SoftwareTitleID: ptr.Uint(3), RemovalStatus: &pending, RemovalRequestedAt: &old},
+			{HostID: 14, HostPlatform: "darwin", SoftwareID: 1, SoftwareTitleID: ptr.Uint(3), RemovalStatus: &failed, RemovalRequestedAt: &recent},
+			{HostID: 15, HostPlatform: "darwin", SoftwareID: 1, SoftwareTitleID: ptr.Uint(3), RemovalStatus: &failed, RemovalRequestedAt: &old},
+		}, nil
+	}
+
+	var removals []uint
+	ds.NewSoftwareBlocklistRemovalFunc = func(ctx context.Context, ruleID, hostID uint, executionID string) error {
+		require.Equal(t, rule.ID, ruleID)
+		require.NotEmpty(t, executionID)
+		removals = append(removals, hostID)
+		return nil
+	}
+	process := func() {
+		removals = nil
+		require.NoError(t, ProcessSoftwareBlocklist(ctx, ds, kitlog.NewNopLogger(), now))
+	}
+
+	t.Run("removal script", func(t *testing.T) {
+		rule = &mdmlab.SoftwareBlocklistRule{ID: 1, TeamID: ptr.Uint(1), Name: "uTorrent.app", ScriptID: ptr.Uint(5)}
+		ds.ScriptFunc = func(ctx context.Context, id uint) (*mdmlab.Script, error) {
+			return &mdmlab.Script{ID: id, Name: "remove-utorrent.sh", TeamID: ptr.Uint(1), ScriptContentID: 6}, nil
+		}
+		ds.IsExecutionPendingForHostFunc = func(ctx context.Context, hostID, scriptID uint) (bool, error) {
+			return false, nil
+		}
+		ds.GetScriptContentsFunc = func(ctx context.Context, id uint) ([]byte, error) {
+			return []byte("rm -rf /Applications/uTorrent.app"), nil
+		}
+		var requests []*mdmlab.HostScriptRequestPayload
+		ds.NewHostScriptExecutionRequestFunc = func(ctx context.Context, request *mdmlab.HostScriptRequestPayload) (*mdmlab.HostScriptResult, error) {
+			requests = append(requests, request)
+			return &mdmlab.HostScriptResult{ExecutionID: "exec"}, nil
+		}
+
+		process()
+		// the script runs once per host, not on hosts of another platform,
+		// excepted, with a pending removal or a recent failed removal.
+		require.Equal(t, []uint{10, 15}, removals)
+		require.Len(t, requests, 2)
+		require.Equal(t, uint(5), *requests[0].ScriptID)
+		require.Equal(t, uint(6), requests[0].ScriptContentID)
+		require.Equal(t, uint(1), requests[0].TeamID)
+	})
+
+	t.Run("installer uninstall script", func(t *testing.T) {
+		rule = &mdmlab.SoftwareBlocklistRule{ID: 2, TeamID: ptr.Uint(1), Name: "uTorrent.app"}
+		ds.GetSoftwareInstallerMetadataByTeamAndTitleIDFunc = func(ctx context.Context, teamID *uint, titleID uint, withScriptContents bool) (*mdmlab.SoftwareInstaller, error) {
+			require.Equal(t, uint(1), *teamID)
+			require.Equal(t, uint(3), titleID)
+			return &mdmlab.SoftwareInstaller{InstallerID: 4, Platform: "darwin", UninstallScriptContentID: 7}, nil
+		}
+		ds.GetHostLastInstallDataFunc = func(ctx context.Context, hostID, installerID uint) (*mdmlab.HostLastInstallData, error) {
+			if hostID == 15 {
+				status := mdmlab.SoftwareUninstallPending
+				return &mdmlab.HostLastInstallData{Status: &status}, nil
+			}
+			return nil, nil
+		}
+		ds.GetAnyScriptContentsFunc = func(ctx context.Context, id uint) ([]byte, error) {
+			require.Equal(t, uint(7), id)
+			return []byte("uninstall"), nil
+		}
+		ds.NewInternalScriptExecutionRequestFunc = func(ctx context.Context, request *mdmlab.HostScriptRequestPayload) (*mdmlab.HostScriptResult, error) {
+			return &mdmlab.HostScriptResult{ExecutionID: "uninstall-exec"}, nil
+		}
+		var uninstalls []uint
+		ds.InsertSoftwareUninstallRequestFunc = func(ctx context.Context, executionID string, hostID, installerID uint) error {
+			require.Equal(t, "uninstall-exec", executionID)
+			require.Equal(t, uint(4), installerID)
+			uninstalls = append(uninstalls, hostID)
+			return nil
+		}
+
+		process()
+		// the windows host has no installer for its platform and the
+		// uninstall of host 15 is already pending.
+		require.Equal(t, []uint{10}, removals)
+		require.Equal(t, []uint{10}, uninstalls)
+	})
+
+	t.Run("no installer", func(t *testing.T) {
+		rule = &mdmlab.SoftwareBlocklistRule{ID: 3, Name: "uTorrent.app"}
+		ds.GetSoftwareInstallerMetadataByTeamAndTitleIDFunc = func(ctx context.Context, teamID *uint, titleID uint, withScriptContents bool) (*mdmlab.SoftwareInstaller, error) {
+			return nil, newNotFoundError()
+		}
+
+		process()
+		require.Empty(t, removals)
+	})
+
+	t.Run("host failure", func(t *testing.T) {
+		rule = &mdmlab.SoftwareBlocklistRule{ID: 4, TeamID: ptr.Uint(1), Name: "uTorrent.app", ScriptID: ptr.Uint(5)}
+		ds.NewHostScriptExecutionRequestFunc = func(ctx context.Context, request *mdmlab.HostScriptRequestPayload) (*mdmlab.HostScriptResult, error) {
+			if request.HostID == 10 {
+				return nil, errors.New("queue script")
+			}
+			return &mdmlab.HostScriptResult{ExecutionID: "exec"}, nil
+		}
+
+		// the failure on a host doesn't prevent the removal on the others.
+		process()
+		require.Equal(t, []uint{15}, removals)
+	})
+}
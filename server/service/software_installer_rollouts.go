@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get, pause, resume and delete software installer rollout
+/////////////////////////////////////////////////////////////////////////////////
+
+type getSoftwareInstallerRolloutRequest struct {
+	TitleID uint  `url:"title_id"`
+	TeamID  *uint `query:"team_id"`
+}
+
+type getSoftwareInstallerRolloutResponse struct {
+	Rollout *mdmlab.SoftwareInstallerRollout `json:"rollout,omitempty"`
+	Err     error                            `json:"error,omitempty"`
+}
+
+func (r getSoftwareInstallerRolloutResponse) error() error { return r.Err }
+
+func getSoftwareInstallerRolloutEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareInstallerRolloutRequest)
+	rollout, err := svc.GetSoftwareInstallerRollout(ctx, req.TitleID, req.TeamID)
+	if err != nil {
+		return getSoftwareInstallerRolloutResponse{Err: err}, nil
+	}
+	return getSoftwareInstallerRolloutResponse{Rollout: rollout}, nil
+}
+
+func pauseSoftwareInstallerRolloutEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareInstallerRolloutRequest)
+	rollout, err := svc.PauseSoftwareInstallerRollout(ctx, req.TitleID, req.TeamID)
+	if err != nil {
+		return getSoftwareInstallerRolloutResponse{Err: err}, nil
+	}
+	return getSoftwareInstallerRolloutResponse{Rollout: rollout}, nil
+}
+
+func resumeSoftwareInstallerRolloutEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareInstallerRolloutRequest)
+	rollout, err := svc.ResumeSoftwareInstallerRollout(ctx, req.TitleID, req.TeamID)
+	if err != nil {
+		return getSoftwareInstallerRolloutResponse{Err: err}, nil
+	}
+	return getSoftwareInstallerRolloutResponse{Rollout: rollout}, nil
+}
+
+type deleteSoftwareInstallerRolloutResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteSoftwareInstallerRolloutResponse) error() error { return r.Err }
+func (r deleteSoftwareInstallerRolloutResponse) Status() int  { return http.StatusNoContent }
+
+func deleteSoftwareInstallerRolloutEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareInstallerRolloutRequest)
+	if err := svc.DeleteSoftwareInstallerRollout(ctx, req.TitleID, req.TeamID); err != nil {
+		return deleteSoftwareInstallerRolloutResponse{Err: err}, nil
+	}
+	return deleteSoftwareInstallerRolloutResponse{}, nil
+}
+
+func (svc *Service) GetSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) PauseSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) ResumeSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareInstallerRollout, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) DeleteSoftwareInstallerRollout(ctx context.Context, titleID uint, teamID *uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Set software installer rollout
+/////////////////////////////////////////////////////////////////////////////////
+
+type setSoftwareInstallerRolloutRequest struct {
+	TitleID                 uint                                   `url:"title_id"`
+	TeamID                  *uint                                  `json:"team_id"`
+	Stages                  []mdmlab.SoftwareInstallerRolloutStage `json:"stages"`
+	SuccessThresholdPercent *uint                                  `json:"success_threshold_percent"`
+	MaxFailedInstalls       *uint                                  `json:"max_failed_installs"`
+}
+
+func setSoftwareInstallerRolloutEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*setSoftwareInstallerRolloutRequest)
+	rollout, err := svc.SetSoftwareInstallerRollout(ctx, &mdmlab.SoftwareInstallerRolloutPayload{
+		TitleID:                 req.TitleID,
+		TeamID:                  req.TeamID,
+		Stages:                  req.Stages,
+		SuccessThresholdPercent: req.SuccessThresholdPercent,
+		MaxFailedInstalls:       req.MaxFailedInstalls,
+	})
+	if err != nil {
+		return getSoftwareInstallerRolloutResponse{Err: err}, nil
+	}
+	return getSoftwareInstallerRolloutResponse{Rollout: rollout}, nil
+}
+
+func (svc *Service) SetSoftwareInstallerRollout(ctx context.Context, payload *mdmlab.SoftwareInstallerRolloutPayload) (*mdmlab.SoftwareInstallerRollout, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Rollouts cron
+/////////////////////////////////////////////////////////////////////////////////
+
+// ProcessSoftwareInstallerRollouts moves the staged rollouts forward: it
+// restarts the rollouts whose installer has a new version, pauses the ones
+// with too many failed installs, advances the ones whose current stage soaked
+// long enough with a good success rate and queues the installs for the hosts
+// targeted by the stages reached so far. An activity is created on each
+// status or stage change.
+func ProcessSoftwareInstallerRollouts(ctx context.Context, ds mdmlab.Datastore, logger kitlog.Logger, now time.Time) error {
+	rollouts, err := ds.ListActiveSoftwareInstallerRollouts(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list active software installer rollouts")
+	}
+
+	for _, rollout := range rollouts {
+		logger := kitlog.With(logger,
+			"software_installer_id", rollout.SoftwareInstallerID,
+			"software_title_id", rollout.TitleID,
+		)
+		if err := processSoftwareInstallerRollout(ctx, ds, logger, rollout, now); err != nil {
+			// keep processing the other rollouts
+			level.Error(logger).Log("msg", "process software installer rollout", "err", err)
+			ctxerr.Handle(ctx, err)
+		}
+	}
+	return nil
+}
+
+func processSoftwareInstallerRollout(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	rollout *mdmlab.SoftwareInstallerRollout,
+	now time.Time,
+) error {
+	var activity func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails
+
+	switch {
+	case rollout.Version != rollout.InstallerVersion:
+		// a new version of the installer was uploaded, roll it out from the start
+		rollout.Version = rollout.InstallerVersion
+		rollout.Status = mdmlab.SoftwareInstallerRolloutInProgress
+		rollout.StatusReason = nil
+		rollout.CurrentStage = 0
+		rollout.StageStartedAt = now
+		rollout.StartedAt = now
+		activity = func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails {
+			return mdmlab.ActivityTypeStartedSoftwareRollout{ActivitySoftwareRollout: details, FromAutomation: true}
+		}
+
+	case rollout.Status == mdmlab.SoftwareInstallerRolloutPaused:
+		return nil
+
+	default:
+		stats, err := ds.GetSoftwareInstallerRolloutStats(ctx, rollout.SoftwareInstallerID, rollout.StartedAt)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "get software installer rollout stats")
+		}
+
+		if stats.Failed > rollout.MaxFailedInstalls {
+			reason := fmt.Sprintf("%d failed installs exceed the limit of %d", stats.Failed, rollout.MaxFailedInstalls)
+			rollout.Status = mdmlab.SoftwareInstallerRolloutPaused
+			rollout.StatusReason = &reason
+			activity = func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails {
+				return mdmlab.ActivityTypePausedSoftwareRollout{ActivitySoftwareRollout: details, Reason: &reason, FromAutomation: true}
+			}
+			break
+		}
+
+		var soak time.Duration
+		if int(rollout.CurrentStage) < len(rollout.Stages) {
+			soak = time.Duration(rollout.Stages[rollout.CurrentStage].SoakMinutes) * time.Minute
+		}
+		if now.Sub(rollout.StageStartedAt) < soak {
+			break
+		}
+		if rate, ok := stats.SuccessRate(); ok && rate < float64(rollout.SuccessThresholdPercent) {
+			level.Debug(logger).Log("msg", "success rate below threshold, not advancing", "success_rate", rate)
+			break
+		}
+
+		if int(rollout.CurrentStage)+1 < len(rollout.Stages) {
+			rollout.CurrentStage++
+			rollout.StageStartedAt = now
+			activity = func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails {
+				return mdmlab.ActivityTypeAdvancedSoftwareRollout{ActivitySoftwareRollout: details}
+			}
+		} else {
+			rollout.Status = mdmlab.SoftwareInstallerRolloutCompleted
+			activity = func(details mdmlab.ActivitySoftwareRollout) mdmlab.ActivityDetails {
+				return mdmlab.ActivityTypeCompletedSoftwareRollout{ActivitySoftwareRollout: details}
+			}
+		}
+	}
+
+	if activity != nil {
+		if err := ds.UpdateSoftwareInstallerRollout(ctx, rollout); err != nil {
+			return ctxerr.Wrap(ctx, err, "update software installer rollout")
+		}
+
+		var teamName *string
+		if rollout.TeamID != nil && *rollout.TeamID != 0 {
+			team, err := ds.Team(ctx, *rollout.TeamID)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "get team of software installer rollout")
+			}
+			teamName = &team.Name
+		}
+		if err := newActivity(ctx, nil, activity(rollout.ActivityDetails(teamName)), ds, logger); err != nil {
+			return ctxerr.Wrap(ctx, err, "create software installer rollout activity")
+		}
+		level.Info(logger).Log("msg", "software installer rollout updated", "status", rollout.Status, "stage", rollout.CurrentStage+1)
+	}
+
+	if rollout.Status == mdmlab.SoftwareInstallerRolloutPaused {
+		return nil
+	}
+	return queueSoftwareInstallerRolloutInstalls(ctx, ds, logger, rollout)
+}
+
+// queueSoftwareInstallerRolloutInstalls queues the installs of the hosts that
+// were skipped by the policy automations because they were not targeted by the
+// rollout yet.
+func queueSoftwareInstallerRolloutInstalls(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	rollout *mdmlab.SoftwareInstallerRollout,
+) error {
+	hosts, err := ds.ListSoftwareInstallerRolloutHostsToInstall(ctx, rollout)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list software installer rollout hosts to install")
+	}
+
+	for _, host := range hosts {
+		if mdmlab.PlatformFromHost(host.Platform) != rollout.InstallerPlatform {
+			continue
+		}
+		scoped, err := ds.IsSoftwareInstallerLabelScoped(ctx, rollout.SoftwareInstallerID, host.HostID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "checking if software installer is label scoped to host")
+		}
+		if !scoped {
+			continue
+		}
+		lastInstall, err := ds.GetHostLastInstallData(ctx, host.HostID, rollout.SoftwareInstallerID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "get host last install data")
+		}
+		if lastInstall != nil && lastInstall.Status != nil && *lastInstall.Status == mdmlab.SoftwareInstallPending {
+			continue
+		}
+
+		installUUID, err := ds.InsertSoftwareInstallRequest(ctx, host.HostID, rollout.SoftwareInstallerID, false, ptr.Uint(host.PolicyID))
+		if err != nil {
+			return ctxerr.Wrapf(ctx, err,
+				"insert software install request: host_id=%d, software_installer_id=%d",
+				host.HostID, rollout.SoftwareInstallerID,
+			)
+		}
+		level.Debug(logger).Log("msg", "rollout install request sent", "host_id", host.HostID, "install_uuid", installUUID)
+	}
+	return nil
+}
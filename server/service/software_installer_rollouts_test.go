@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSoftwareInstallerRollouts(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	now := time.Now().UTC()
+
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*mdmlab.Team, error) {
+		return &mdmlab.Team{ID: tid, Name: "team1"}, nil
+	}
+
+	var rollout *mdmlab.SoftwareInstallerRollout
+	newRollout := func() *mdmlab.SoftwareInstallerRollout {
+		return &mdmlab.SoftwareInstallerRollout{
+			ID:                      1,
+			SoftwareInstallerID:     2,
+			TeamID:                  ptr.Uint(1),
+			TitleID:                 3,
+			SoftwareTitle:           "Chrome",
+			Package:                 "chrome.pkg",
+			InstallerVersion:        "2.0",
+			InstallerPlatform:       "darwin",
+			Version:                 "2.0",
+			Stages:                  mdmlab.SoftwareInstallerRolloutStages{{Percent: ptr.Uint(10), SoakMinutes: 60}, {Percent: ptr.Uint(100)}},
+			SuccessThresholdPercent: 90,
+			MaxFailedInstalls:       2,
+			Status:                  mdmlab.SoftwareInstallerRolloutInProgress,
+			StageStartedAt:          now.Add(-2 * time.Hour),
+			StartedAt:               now.Add(-2 * time.Hour),
+		}
+	}
+	ds.ListActiveSoftwareInstallerRolloutsFunc = func(ctx context.Context) ([]*mdmlab.SoftwareInstallerRollout, error) {
+		return []*mdmlab.SoftwareInstallerRollout{rollout}, nil
+	}
+	var updated *mdmlab.SoftwareInstallerRollout
+	ds.UpdateSoftwareInstallerRolloutFunc = func(ctx context.Context, r *mdmlab.SoftwareInstallerRollout) error {
+		updated = r
+		return nil
+	}
+	var stats mdmlab.SoftwareInstallerRolloutStats
+	ds.GetSoftwareInstallerRolloutStatsFunc = func(ctx context.Context, installerID uint, since time.Time) (*mdmlab.SoftwareInstallerRolloutStats, error) {
+		require.Equal(t, rollout.StartedAt, since)
+		return &stats, nil
+	}
+	var activities []mdmlab.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time) error {
+		require.Nil(t, user)
+		activities = append(activities, activity)
+		return nil
+	}
+	ds.ListSoftwareInstallerRolloutHostsToInstallFunc = func(ctx context.Context, r *mdmlab.SoftwareInstallerRollout) ([]*mdmlab.SoftwareInstallerRolloutHost, error) {
+		return []*mdmlab.SoftwareInstallerRolloutHost{
+			{HostID: 10, Platform: "darwin", PolicyID: 5},
+			{HostID: 11, Platform: "windows", PolicyID: 5},
+			{HostID: 12, Platform: "darwin", PolicyID: 5},
+			{HostID: 13, Platform: "darwin", PolicyID: 5},
+		}, nil
+	}
+	ds.IsSoftwareInstallerLabelScopedFunc = func(ctx context.Context, installerID, hostID uint) (bool, error) {
+		return hostID != 12, nil
+	}
+	ds.GetHostLastInstallDataFunc = func(ctx context.Context, hostID, installerID uint) (*mdmlab.HostLastInstallData, error) {
+		if hostID == 13 {
+			status := mdmlab.SoftwareInstallPending
+			return &mdmlab.HostLastInstallData{Status: &status}, nil
+		}
+		return nil, nil
+	}
+	var installed []uint
+	ds.InsertSoftwareInstallRequestFunc = func(ctx context.Context, hostID, installerID uint, selfService bool, policyID *uint) (string, error) {
+		require.Equal(t, uint(2), installerID)
+		require.Equal(t, uint(5), *policyID)
+		installed = append(installed, hostID)
+		return "uuid", nil
+	}
+
+	reset := func() {
+		rollout = newRollout()
+		updated = nil
+		activities = nil
+		installed = nil
+		stats = mdmlab.SoftwareInstallerRolloutStats{}
+	}
+	process := func() {
+		require.NoError(t, ProcessSoftwareInstallerRollouts(ctx, ds, kitlog.NewNopLogger(), now))
+	}
+
+	t.Run("advances after the soak time", func(t *testing.T) {
+		reset()
+		stats = mdmlab.SoftwareInstallerRolloutStats{Installed: 19, Failed: 1}
+		process()
+		require.NotNil(t, updated)
+		require.Equal(t, uint(1), updated.CurrentStage)
+		require.Equal(t, now, updated.StageStartedAt)
+		require.Len(t, activities, 1)
+		act, ok := activities[0].(mdmlab.ActivityTypeAdvancedSoftwareRollout)
+		require.True(t, ok)
+		require.Equal(t, uint(2), act.Stage)
+		require.Equal(t, 2, act.StagesCount)
+		require.Equal(t, "team1", *act.TeamName)
+		// hosts on another platform, out of the label scope or with a pending
+		// install are skipped
+		require.Equal(t, []uint{10}, installed)
+	})
+
+	t.Run("waits for the soak time", func(t *testing.T) {
+		reset()
+		rollout.StageStartedAt = now.Add(-time.Minute)
+		process()
+		require.Nil(t, updated)
+		require.Empty(t, activities)
+		require.Equal(t, []uint{10}, installed)
+	})
+
+	t.Run("waits for a good success rate", func(t *testing.T) {
+		reset()
+		stats = mdmlab.SoftwareInstallerRolloutStats{Installed: 8, Failed: 2}
+		process()
+		require.Nil(t, updated)
+		require.Empty(t, activities)
+	})
+
+	t.Run("completes after the last stage", func(t *testing.T) {
+		reset()
+		rollout.CurrentStage = 1
+		process()
+		require.NotNil(t, updated)
+		require.Equal(t, mdmlab.SoftwareInstallerRolloutCompleted, updated.Status)
+		require.Len(t, activities, 1)
+		_, ok := activities[0].(mdmlab.ActivityTypeCompletedSoftwareRollout)
+		require.True(t, ok)
+	})
+
+	t.Run("pauses on failures", func(t *testing.T) {
+		reset()
+		stats = mdmlab.SoftwareInstallerRolloutStats{Installed: 100, Failed: 3}
+		process()
+		require.NotNil(t, updated)
+		require.Equal(t, mdmlab.SoftwareInstallerRolloutPaused, updated.Status)
+		require.Equal(t, "3 failed installs exceed the limit of 2", *updated.StatusReason)
+		require.Len(t, activities, 1)
+		act, ok := activities[0].(mdmlab.ActivityTypePausedSoftwareRollout)
+		require.True(t, ok)
+		require.True(t, act.WasFromAutomation())
+		require.Equal(t, "3 failed installs exceed the limit of 2", *act.Reason)
+		require.Empty(t, installed)
+	})
+
+	t.Run("paused rollouts are skipped", func(t *testing.T) {
+		reset()
+		rollout.Status = mdmlab.SoftwareInstallerRolloutPaused
+		process()
+		require.Nil(t, updated)
+		require.Empty(t, activities)
+		require.Empty(t, installed)
+	})
+
+	t.Run("restarts on a new version", func(t *testing.T) {
+		reset()
+		rollout.Status = mdmlab.SoftwareInstallerRolloutPaused
+		rollout.CurrentStage = 1
+		rollout.InstallerVersion = "3.0"
+		process()
+		require.NotNil(t, updated)
+		require.Equal(t, "3.0", updated.Version)
+		require.Equal(t, mdmlab.SoftwareInstallerRolloutInProgress, updated.Status)
+		require.Zero(t, updated.CurrentStage)
+		require.Equal(t, now, updated.StartedAt)
+		require.Len(t, activities, 1)
+		act, ok := activities[0].(mdmlab.ActivityTypeStartedSoftwareRollout)
+		require.True(t, ok)
+		require.Equal(t, "3.0", act.Version)
+		require.Equal(t, uint(1), act.Stage)
+		require.Equal(t, []uint{10}, installed)
+	})
+}
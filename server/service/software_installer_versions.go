@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// List software installer versions
+/////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareInstallerVersionsRequest struct {
+	TitleID uint  `url:"title_id"`
+	TeamID  *uint `query:"team_id"`
+}
+
+type listSoftwareInstallerVersionsResponse struct {
+	Versions []*mdmlab.SoftwareInstallerVersion `json:"versions"`
+	Err      error                              `json:"error,omitempty"`
+}
+
+func (r listSoftwareInstallerVersionsResponse) error() error { return r.Err }
+
+func listSoftwareInstallerVersionsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listSoftwareInstallerVersionsRequest)
+	versions, err := svc.ListSoftwareInstallerVersions(ctx, req.TitleID, req.TeamID)
+	if err != nil {
+		return listSoftwareInstallerVersionsResponse{Err: err}, nil
+	}
+	return listSoftwareInstallerVersionsResponse{Versions: versions}, nil
+}
+
+func (svc *Service) ListSoftwareInstallerVersions(ctx context.Context, titleID uint, teamID *uint) ([]*mdmlab.SoftwareInstallerVersion, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Pin, unpin, rollback and delete software installer version
+/////////////////////////////////////////////////////////////////////////////////
+
+type softwareInstallerVersionRequest struct {
+	TitleID   uint  `url:"title_id"`
+	VersionID uint  `url:"version_id"`
+	TeamID    *uint `query:"team_id"`
+}
+
+type softwareInstallerVersionResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r softwareInstallerVersionResponse) error() error { return r.Err }
+func (r softwareInstallerVersionResponse) Status() int  { return http.StatusNoContent }
+
+func pinSoftwareInstallerVersionEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*softwareInstallerVersionRequest)
+	if err := svc.PinSoftwareInstallerVersion(ctx, req.TitleID, req.TeamID, req.VersionID); err != nil {
+		return softwareInstallerVersionResponse{Err: err}, nil
+	}
+	return softwareInstallerVersionResponse{}, nil
+}
+
+func unpinSoftwareInstallerVersionEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listSoftwareInstallerVersionsRequest)
+	if err := svc.UnpinSoftwareInstallerVersion(ctx, req.TitleID, req.TeamID); err != nil {
+		return softwareInstallerVersionResponse{Err: err}, nil
+	}
+	return softwareInstallerVersionResponse{}, nil
+}
+
+func rollbackSoftwareInstallerVersionEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*softwareInstallerVersionRequest)
+	if err := svc.RollbackSoftwareInstallerVersion(ctx, req.TitleID, req.TeamID, req.VersionID); err != nil {
+		return softwareInstallerVersionResponse{Err: err}, nil
+	}
+	return softwareInstallerVersionResponse{}, nil
+}
+
+func deleteSoftwareInstallerVersionEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*softwareInstallerVersionRequest)
+	if err := svc.DeleteSoftwareInstallerVersion(ctx, req.TitleID, req.TeamID, req.VersionID); err != nil {
+		return softwareInstallerVersionResponse{Err: err}, nil
+	}
+	return softwareInstallerVersionResponse{}, nil
+}
+
+func (svc *Service) PinSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) UnpinSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) RollbackSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) DeleteSoftwareInstallerVersion(ctx context.Context, titleID uint, teamID *uint, versionID uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Set software installer version labels
+/////////////////////////////////////////////////////////////////////////////////
+
+type setSoftwareInstallerVersionLabelsRequest struct {
+	TitleID          uint     `url:"title_id"`
+	VersionID        uint     `url:"version_id"`
+	TeamID           *uint    `json:"team_id"`
+	LabelsIncludeAny []string `json:"labels_include_any"`
+}
+
+type setSoftwareInstallerVersionLabelsResponse struct {
+	Version *mdmlab.SoftwareInstallerVersion `json:"version,omitempty"`
+	Err     error                            `json:"error,omitempty"`
+}
+
+func (r setSoftwareInstallerVersionLabelsResponse) error() error { return r.Err }
+
+func setSoftwareInstallerVersionLabelsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*setSoftwareInstallerVersionLabelsRequest)
+	version, err := svc.SetSoftwareInstallerVersionLabels(ctx, req.TitleID, req.TeamID, req.VersionID, req.LabelsIncludeAny)
+	if err != nil {
+		return setSoftwareInstallerVersionLabelsResponse{Err: err}, nil
+	}
+	return setSoftwareInstallerVersionLabelsResponse{Version: version}, nil
+}
+
+func (svc *Service) SetSoftwareInstallerVersionLabels(ctx context.Context, titleID uint, teamID *uint, versionID uint, labelsIncludeAny []string) (*mdmlab.SoftwareInstallerVersion, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
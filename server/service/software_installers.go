@@ -449,6 +449,54 @@ func downloadSoftwareInstallerEndpoint(ctx context.Context, request interface{},
 	return orbitDownloadSoftwareInstallerResponse{payload: payload}, nil
 }
 
+type softwareInstallerCacheTokenRequest struct {
+	Token string `url:"token"`
+}
+
+type getSoftwareInstallerCacheTokenResponse struct {
+	Err             error  `json:"error,omitempty"`
+	InstallerID     uint   `json:"installer_id,omitempty"`
+	InstallerSHA256 string `json:"installer_sha256,omitempty"`
+}
+
+func (r getSoftwareInstallerCacheTokenResponse) error() error { return r.Err }
+
+func getSoftwareInstallerCacheTokenEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*softwareInstallerCacheTokenRequest)
+
+	meta, err := svc.GetSoftwareInstallerCacheTokenMetadata(ctx, req.Token)
+	if err != nil {
+		return getSoftwareInstallerCacheTokenResponse{Err: err}, nil
+	}
+	return getSoftwareInstallerCacheTokenResponse{InstallerID: meta.InstallerID, InstallerSHA256: meta.InstallerSHA256}, nil
+}
+
+func downloadSoftwareInstallerWithCacheTokenEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*softwareInstallerCacheTokenRequest)
+
+	payload, err := svc.DownloadSoftwareInstallerWithCacheToken(ctx, req.Token)
+	if err != nil {
+		return orbitDownloadSoftwareInstallerResponse{Err: err}, nil
+	}
+	return orbitDownloadSoftwareInstallerResponse{payload: payload}, nil
+}
+
+func (svc *Service) GetSoftwareInstallerCacheTokenMetadata(ctx context.Context, _ string) (*mdmlab.SoftwareInstallerCacheTokenMetadata, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) DownloadSoftwareInstallerWithCacheToken(ctx context.Context, _ string) (*mdmlab.DownloadSoftwareInstallerPayload, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
 func (svc *Service) GenerateSoftwareInstallerToken(ctx context.Context, _ string, _ uint, _ *uint) (string, error) {
 	// skipauth: No authorization check needed due to implementation returning
 	// only license error.
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	authzctx "github.com/it-laborato/MDM_Lab/server/contexts/authz"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/logging"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// List software licenses
+/////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareLicensesRequest struct {
+	TeamID *uint `query:"team_id,optional"`
+}
+
+type listSoftwareLicensesResponse struct {
+	Licenses []*mdmlab.SoftwareLicense `json:"licenses"`
+	Err      error                     `json:"error,omitempty"`
+}
+
+func (r listSoftwareLicensesResponse) error() error { return r.Err }
+
+func listSoftwareLicensesEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listSoftwareLicensesRequest)
+	licenses, err := svc.ListSoftwareLicenses(ctx, req.TeamID)
+	if err != nil {
+		return listSoftwareLicensesResponse{Err: err}, nil
+	}
+	if licenses == nil {
+		licenses = []*mdmlab.SoftwareLicense{}
+	}
+	return listSoftwareLicensesResponse{Licenses: licenses}, nil
+}
+
+func (svc *Service) ListSoftwareLicenses(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Software licenses report in CSV downloadable file
+/////////////////////////////////////////////////////////////////////////////////
+
+type softwareLicensesReportRequest struct {
+	TeamID *uint  `query:"team_id,optional"`
+	Format string `query:"format"`
+}
+
+// softwareLicenseReportRow is a row of the software licenses CSV report.
+type softwareLicenseReportRow struct {
+	Team           string `csv:"team"`
+	SoftwareTitle  string `csv:"software_title"`
+	Vendor         string `csv:"vendor"`
+	SeatCount      uint   `csv:"seat_count"`
+	Installs       uint   `csv:"installs"`
+	Used           uint   `csv:"used"`
+	Unused         uint   `csv:"unused"`
+	Unknown        uint   `csv:"unknown"`
+	AvailableSeats int    `csv:"available_seats"`
+	OverDeployed   bool   `csv:"over_deployed"`
+	ExpiresAt      string `csv:"expires_at"`
+	Expired        bool   `csv:"expired"`
+	Cost           string `csv:"cost"`
+}
+
+func newSoftwareLicenseReportRow(license *mdmlab.SoftwareLicense) *softwareLicenseReportRow {
+	row := &softwareLicenseReportRow{
+		Team:          "No team",
+		SoftwareTitle: license.SoftwareTitle,
+		Vendor:        license.Vendor,
+		SeatCount:     license.SeatCount,
+	}
+	if license.TeamName != nil {
+		row.Team = *license.TeamName
+	}
+	if license.ExpiresAt != nil {
+		row.ExpiresAt = license.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if license.Cost != nil {
+		row.Cost = strconv.FormatFloat(*license.Cost, 'f', 2, 64)
+	}
+	if usage := license.Usage; usage != nil {
+		row.Installs = usage.Installs
+		row.Used = usage.Used
+		row.Unused = usage.Unused
+		row.Unknown = usage.Unknown
+		row.AvailableSeats = usage.AvailableSeats
+		row.OverDeployed = usage.OverDeployed
+		row.Expired = usage.Expired
+	}
+	return row
+}
+
+// softwareLicenseReportAllTeams is the team of the total rows of the report.
+const softwareLicenseReportAllTeams = "All teams"
+
+// newSoftwareLicenseReportTotalRows returns a row per software title licensed
+// in more than one team, with the seats and usage of its licenses summed up.
+// A license only counts the hosts of its own team, so an organization-wide
+// contract is tracked as one license per team and its totals are in these
+// rows.
+func newSoftwareLicenseReportTotalRows(licenses []*mdmlab.SoftwareLicense) []*softwareLicenseReportRow {
+	var titleIDs []uint
+	byTitle := make(map[uint][]*mdmlab.SoftwareLicense)
+	for _, license := range licenses {
+		if _, ok := byTitle[license.SoftwareTitleID]; !ok {
+			titleIDs = append(titleIDs, license.SoftwareTitleID)
+		}
+		byTitle[license.SoftwareTitleID] = append(byTitle[license.SoftwareTitleID], license)
+	}
+
+	var rows []*softwareLicenseReportRow
+	for _, titleID := range titleIDs {
+		titleLicenses := byTitle[titleID]
+		if len(titleLicenses) < 2 {
+			continue
+		}
+
+		row := &softwareLicenseReportRow{
+			Team:          softwareLicenseReportAllTeams,
+			SoftwareTitle: titleLicenses[0].SoftwareTitle,
+			Vendor:        titleLicenses[0].Vendor,
+		}
+		var cost float64
+		var hasCost bool
+		for _, license := range titleLicenses {
+			if license.Vendor != row.Vendor {
+				row.Vendor = ""
+			}
+			row.SeatCount += license.SeatCount
+			if license.Cost != nil {
+				cost += *license.Cost
+				hasCost = true
+			}
+			if usage := license.Usage; usage != nil {
+				row.Installs += usage.Installs
+				row.Used += usage.Used
+				row.Unused += usage.Unused
+				row.Unknown += usage.Unknown
+			}
+		}
+		row.AvailableSeats = int(row.SeatCount) - int(row.Installs) //nolint:gosec // dismiss G115
+		row.OverDeployed = row.AvailableSeats < 0
+		if hasCost {
+			row.Cost = strconv.FormatFloat(cost, 'f', 2, 64)
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].SoftwareTitle < rows[j].SoftwareTitle })
+	return rows
+}
+
+type softwareLicensesReportResponse struct {
+	Licenses []*mdmlab.SoftwareLicense `json:"-"` // they get rendered explicitly, in csv
+	// AllTeams is set when the report has the licenses of all the teams, the
+	// totals of the titles licensed in several teams are then added.
+	AllTeams bool  `json:"-"`
+	Err      error `json:"error,omitempty"`
+}
+
+func (r softwareLicensesReportResponse) error() error { return r.Err }
+
+func (r softwareLicensesReportResponse) hijackRender(ctx context.Context, w http.ResponseWriter) {
+	rows := make([]*softwareLicenseReportRow, 0, len(r.Licenses))
+	for _, license := range r.Licenses {
+		rows = append(rows, newSoftwareLicenseReportRow(license))
+	}
+	if r.AllTeams {
+		rows = append(rows, newSoftwareLicenseReportTotalRows(r.Licenses)...)
+	}
+
+	var buf bytes.Buffer
+	if err := gocsv.Marshal(rows, &buf); err != nil {
+		logging.WithErr(ctx, err)
+		encodeError(ctx, ctxerr.New(ctx, "failed to generate CSV file"), w)
+		return
+	}
+
+	w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="Software licenses %s.csv"`, time.Now().Format("2006-01-02")))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, &buf); err != nil {
+		logging.WithErr(ctx, err)
+	}
+}
+
+func softwareLicensesReportEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*softwareLicensesReportRequest)
+
+	// for now, only csv format is allowed
+	if req.Format != "csv" {
+		// prevent returning an "unauthorized" error, we want that specific error
+		if az, ok := authzctx.FromContext(ctx); ok {
+			az.SetChecked()
+		}
+		err := ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("format", "unsupported or unspecified report format").
+			WithStatus(http.StatusUnsupportedMediaType))
+		return softwareLicensesReportResponse{Err: err}, nil
+	}
+
+	licenses, err := svc.ListSoftwareLicensesReport(ctx, req.TeamID)
+	if err != nil {
+		return softwareLicensesReportResponse{Err: err}, nil
+	}
+	return softwareLicensesReportResponse{Licenses: licenses, AllTeams: req.TeamID == nil}, nil
+}
+
+func (svc *Service) ListSoftwareLicensesReport(ctx context.Context, teamID *uint) ([]*mdmlab.SoftwareLicense, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get, delete software license
+/////////////////////////////////////////////////////////////////////////////////
+
+type getSoftwareLicenseRequest struct {
+	ID uint `url:"id"`
+}
+
+type getSoftwareLicenseResponse struct {
+	License *mdmlab.SoftwareLicense `json:"license,omitempty"`
+	Err     error                   `json:"error,omitempty"`
+}
+
+func (r getSoftwareLicenseResponse) error() error { return r.Err }
+
+func getSoftwareLicenseEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareLicenseRequest)
+	license, err := svc.GetSoftwareLicense(ctx, req.ID)
+	if err != nil {
+		return getSoftwareLicenseResponse{Err: err}, nil
+	}
+	return getSoftwareLicenseResponse{License: license}, nil
+}
+
+type deleteSoftwareLicenseResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteSoftwareLicenseResponse) error() error { return r.Err }
+func (r deleteSoftwareLicenseResponse) Status() int  { return http.StatusNoContent }
+
+func deleteSoftwareLicenseEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareLicenseRequest)
+	if err := svc.DeleteSoftwareLicense(ctx, req.ID); err != nil {
+		return deleteSoftwareLicenseResponse{Err: err}, nil
+	}
+	return deleteSoftwareLicenseResponse{}, nil
+}
+
+func (svc *Service) GetSoftwareLicense(ctx context.Context, id uint) (*mdmlab.SoftwareLicense, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) DeleteSoftwareLicense(ctx context.Context, id uint) error {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Create, modify software license
+/////////////////////////////////////////////////////////////////////////////////
+
+type createSoftwareLicenseRequest struct {
+	TeamID          *uint      `json:"team_id"`
+	SoftwareTitleID uint       `json:"software_title_id"`
+	Vendor          string     `json:"vendor"`
+	SeatCount       uint       `json:"seat_count"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Cost            *float64   `json:"cost"`
+	UnusedAfterDays *uint      `json:"unused_after_days"`
+}
+
+func (r *createSoftwareLicenseRequest) payload() *mdmlab.SoftwareLicensePayload {
+	return &mdmlab.SoftwareLicensePayload{
+		TeamID:          r.TeamID,
+		SoftwareTitleID: r.SoftwareTitleID,
+		Vendor:          strings.TrimSpace(r.Vendor),
+		SeatCount:       r.SeatCount,
+		ExpiresAt:       r.ExpiresAt,
+		Cost:            r.Cost,
+		UnusedAfterDays: r.UnusedAfterDays,
+	}
+}
+
+func createSoftwareLicenseEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*createSoftwareLicenseRequest)
+	license, err := svc.CreateSoftwareLicense(ctx, req.payload())
+	if err != nil {
+		return getSoftwareLicenseResponse{Err: err}, nil
+	}
+	return getSoftwareLicenseResponse{License: license}, nil
+}
+
+type modifySoftwareLicenseRequest struct {
+	ID uint `url:"id"`
+	createSoftwareLicenseRequest
+}
+
+func modifySoftwareLicenseEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*modifySoftwareLicenseRequest)
+	license, err := svc.ModifySoftwareLicense(ctx, req.ID, req.payload())
+	if err != nil {
+		return getSoftwareLicenseResponse{Err: err}, nil
+	}
+	return getSoftwareLicenseResponse{License: license}, nil
+}
+
+func (svc *Service) CreateSoftwareLicense(ctx context.Context, payload *mdmlab.SoftwareLicensePayload) (*mdmlab.SoftwareLicense, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) ModifySoftwareLicense(ctx context.Context, id uint, payload *mdmlab.SoftwareLicensePayload) (*mdmlab.SoftwareLicense, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// List software license hosts
+/////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareLicenseHostsRequest struct {
+	ID          uint                              `url:"id"`
+	UsageStatus mdmlab.SoftwareLicenseUsageStatus `query:"usage_status,optional"`
+}
+
+type listSoftwareLicenseHostsResponse struct {
+	Hosts []*mdmlab.SoftwareLicenseHost `json:"hosts"`
+	Err   error                         `json:"error,omitempty"`
+}
+
+func (r listSoftwareLicenseHostsResponse) error() error { return r.Err }
+
+func listSoftwareLicenseHostsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listSoftwareLicenseHostsRequest)
+	hosts, err := svc.ListSoftwareLicenseHosts(ctx, req.ID, req.UsageStatus)
+	if err != nil {
+		return listSoftwareLicenseHostsResponse{Err: err}, nil
+	}
+	if hosts == nil {
+		hosts = []*mdmlab.SoftwareLicenseHost{}
+	}
+	return listSoftwareLicenseHostsResponse{Hosts: hosts}, nil
+}
+
+func (svc *Service) ListSoftwareLicenseHosts(ctx context.Context, id uint, status mdmlab.SoftwareLicenseUsageStatus) ([]*mdmlab.SoftwareLicenseHost, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
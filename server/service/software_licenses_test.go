@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftwareLicensesReportRender(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	licenses := []*mdmlab.SoftwareLicense{
+		{
+			TeamName:      ptr.String("Design"),
+			SoftwareTitle: "Figma.app",
+			Vendor:        "Figma, Inc.",
+			SeatCount:     2,
+			ExpiresAt:     &expiresAt,
+			Cost:          ptr.Float64(1234.5),
+		},
+		{SoftwareTitle: "Slack.app", SeatCount: 10},
+	}
+	licenses[0].SetUsage(mdmlab.SoftwareLicenseUsage{Installs: 3, Used: 1, Unused: 1, Unknown: 1}, expiresAt.Add(time.Hour))
+	licenses[1].SetUsage(mdmlab.SoftwareLicenseUsage{}, expiresAt)
+
+	rec := httptest.NewRecorder()
+	softwareLicensesReportResponse{Licenses: licenses}.hijackRender(context.Background(), rec)
+	require.Equal(t, 200, rec.Code)
+	require.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Header().Get("Content-Disposition"), `attachment; filename="Software licenses`)
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{
+			"team", "software_title", "vendor", "seat_count", "installs", "used", "unused", "unknown",
+			"available_seats", "over_deployed", "expires_at", "expired", "cost",
+		},
+		{"Design", "Figma.app", "Figma, Inc.", "2", "3", "1", "1", "1", "-1", "true", "2026-01-31T00:00:00Z", "true", "1234.50"},
+		{"No team", "Slack.app", "", "10", "0", "0", "0", "0", "10", "false", "", "false", ""},
+	}, rows)
+}
+
+func TestSoftwareLicensesReportRenderAllTeams(t *testing.T) {
+	now := time.Now()
+	licenses := []*mdmlab.SoftwareLicense{
+		{SoftwareTitleID: 2, SoftwareTitle: "Slack.app", Vendor: "Slack", SeatCount: 5, Cost: ptr.Float64(100)},
+		{SoftwareTitleID: 1, TeamName: ptr.String("Design"), SoftwareTitle: "Figma.app", Vendor: "Figma, Inc.", SeatCount: 2},
+		{SoftwareTitleID: 2, TeamName: ptr.String("Design"), SoftwareTitle: "Slack.app", Vendor: "Slack", SeatCount: 1, Cost: ptr.Float64(20.5)},
+		{SoftwareTitleID: 2, TeamName: ptr.String("Sales"), SoftwareTitle: "Slack.app", Vendor: "Salesforce", SeatCount: 2},
+	}
+	licenses[0].SetUsage(mdmlab.SoftwareLicenseUsage{Installs: 4, Used: 4}, now)
+	licenses[1].SetUsage(mdmlab.SoftwareLicenseUsage{Installs: 1, Unknown: 1}, now)
+	licenses[2].SetUsage(mdmlab.SoftwareLicenseUsage{Installs: 2, Used: 1, Unused: 1}, now)
+	licenses[3].SetUsage(mdmlab.SoftwareLicenseUsage{Installs: 3, Unknown: 3}, now)
+
+	render := func(allTeams bool) [][]string {
+		rec := httptest.NewRecorder()
+		softwareLicensesReportResponse{Licenses: licenses, AllTeams: allTeams}.hijackRender(context.Background(), rec)
+		require.Equal(t, 200, rec.Code)
+		rows, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		return rows
+	}
+
+	// the report of a team has no total rows
+	rows := render(false)
+	require.Len(t, rows, 5)
+
+	// the titles licensed in several teams are totaled, the vendor is only
+	// kept if it is the same for all the licenses
+	rows = render(true)
+	require.Len(t, rows, 6)
+	require.Equal(t, []string{"All teams", "Slack.app", "", "8", "9", "5", "1", "3", "-1", "true", "", "false", "120.50"}, rows[5])
+}
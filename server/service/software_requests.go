@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	hostctx "github.com/it-laborato/MDM_Lab/server/contexts/host"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// Device software catalog
+/////////////////////////////////////////////////////////////////////////////////
+
+type getDeviceSoftwareCatalogRequest struct {
+	Token string `url:"token"`
+}
+
+func (r *getDeviceSoftwareCatalogRequest) deviceAuthToken() string {
+	return r.Token
+}
+
+type getDeviceSoftwareCatalogResponse struct {
+	Software []*mdmlab.SoftwareCatalogItem `json:"software"`
+	Err      error                         `json:"error,omitempty"`
+}
+
+func (r getDeviceSoftwareCatalogResponse) error() error { return r.Err }
+
+func getDeviceSoftwareCatalogEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	host, ok := hostctx.FromContext(ctx)
+	if !ok {
+		err := ctxerr.Wrap(ctx, mdmlab.NewAuthRequiredError("internal error: missing host from request context"))
+		return getDeviceSoftwareCatalogResponse{Err: err}, nil
+	}
+
+	software, err := svc.ListDeviceSoftwareCatalog(ctx, host)
+	if err != nil {
+		return getDeviceSoftwareCatalogResponse{Err: err}, nil
+	}
+	if software == nil {
+		software = []*mdmlab.SoftwareCatalogItem{}
+	}
+	return getDeviceSoftwareCatalogResponse{Software: software}, nil
+}
+
+func (svc *Service) ListDeviceSoftwareCatalog(ctx context.Context, host *mdmlab.Host) ([]*mdmlab.SoftwareCatalogItem, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Device software requests
+/////////////////////////////////////////////////////////////////////////////////
+
+type requestDeviceSoftwareRequest struct {
+	Token           string `url:"token"`
+	SoftwareTitleID uint   `json:"software_title_id"`
+	Justification   string `json:"justification"`
+}
+
+func (r *requestDeviceSoftwareRequest) deviceAuthToken() string {
+	return r.Token
+}
+
+type softwareRequestResponse struct {
+	Request *mdmlab.SoftwareRequest `json:"request,omitempty"`
+	Err     error                   `json:"error,omitempty"`
+}
+
+func (r softwareRequestResponse) error() error { return r.Err }
+
+func requestDeviceSoftwareEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	host, ok := hostctx.FromContext(ctx)
+	if !ok {
+		err := ctxerr.Wrap(ctx, mdmlab.NewAuthRequiredError("internal error: missing host from request context"))
+		return softwareRequestResponse{Err: err}, nil
+	}
+
+	req := request.(*requestDeviceSoftwareRequest)
+	softwareRequest, err := svc.RequestDeviceSoftware(ctx, host, req.SoftwareTitleID, strings.TrimSpace(req.Justification))
+	if err != nil {
+		return softwareRequestResponse{Err: err}, nil
+	}
+	return softwareRequestResponse{Request: softwareRequest}, nil
+}
+
+func (svc *Service) RequestDeviceSoftware(ctx context.Context, host *mdmlab.Host, titleID uint, justification string) (*mdmlab.SoftwareRequest, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+type listDeviceSoftwareRequestsRequest struct {
+	Token       string             `url:"token"`
+	ListOptions mdmlab.ListOptions `url:"list_options"`
+}
+
+func (r *listDeviceSoftwareRequestsRequest) deviceAuthToken() string {
+	return r.Token
+}
+
+type listSoftwareRequestsResponse struct {
+	Meta     *mdmlab.PaginationMetadata `json:"meta"`
+	Requests []*mdmlab.SoftwareRequest  `json:"requests"`
+	Err      error                      `json:"error,omitempty"`
+}
+
+func (r listSoftwareRequestsResponse) error() error { return r.Err }
+
+func listDeviceSoftwareRequestsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	host, ok := hostctx.FromContext(ctx)
+	if !ok {
+		err := ctxerr.Wrap(ctx, mdmlab.NewAuthRequiredError("internal error: missing host from request context"))
+		return listSoftwareRequestsResponse{Err: err}, nil
+	}
+
+	req := request.(*listDeviceSoftwareRequestsRequest)
+	requests, meta, err := svc.ListDeviceSoftwareRequests(ctx, host, req.ListOptions)
+	if err != nil {
+		return listSoftwareRequestsResponse{Err: err}, nil
+	}
+	if requests == nil {
+		requests = []*mdmlab.SoftwareRequest{}
+	}
+	return listSoftwareRequestsResponse{Meta: meta, Requests: requests}, nil
+}
+
+func (svc *Service) ListDeviceSoftwareRequests(ctx context.Context, host *mdmlab.Host, opts mdmlab.ListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// List software requests
+/////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareRequestsRequest struct {
+	ListOptions mdmlab.ListOptions           `url:"list_options"`
+	TeamID      *uint                        `query:"team_id,optional"`
+	HostID      *uint                        `query:"host_id,optional"`
+	Status      mdmlab.SoftwareRequestStatus `query:"status,optional"`
+	RequestedBy string                       `query:"requested_by,optional"`
+}
+
+func listSoftwareRequestsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listSoftwareRequestsRequest)
+	requests, meta, err := svc.ListSoftwareRequests(ctx, mdmlab.SoftwareRequestListOptions{
+		ListOptions: req.ListOptions,
+		TeamID:      req.TeamID,
+		HostID:      req.HostID,
+		Status:      req.Status,
+		RequestedBy: strings.TrimSpace(req.RequestedBy),
+	})
+	if err != nil {
+		return listSoftwareRequestsResponse{Err: err}, nil
+	}
+	if requests == nil {
+		requests = []*mdmlab.SoftwareRequest{}
+	}
+	return listSoftwareRequestsResponse{Meta: meta, Requests: requests}, nil
+}
+
+func (svc *Service) ListSoftwareRequests(ctx context.Context, opts mdmlab.SoftwareRequestListOptions) ([]*mdmlab.SoftwareRequest, *mdmlab.PaginationMetadata, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, nil, mdmlab.ErrMissingLicense
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Approve, deny software request
+/////////////////////////////////////////////////////////////////////////////////
+
+type reviewSoftwareRequestRequest struct {
+	ID      uint   `url:"id"`
+	Comment string `json:"comment"`
+}
+
+func approveSoftwareRequestEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*reviewSoftwareRequestRequest)
+	softwareRequest, err := svc.ApproveSoftwareRequest(ctx, req.ID, strings.TrimSpace(req.Comment))
+	if err != nil {
+		return softwareRequestResponse{Err: err}, nil
+	}
+	return softwareRequestResponse{Request: softwareRequest}, nil
+}
+
+func denySoftwareRequestEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*reviewSoftwareRequestRequest)
+	softwareRequest, err := svc.DenySoftwareRequest(ctx, req.ID, strings.TrimSpace(req.Comment))
+	if err != nil {
+		return softwareRequestResponse{Err: err}, nil
+	}
+	return softwareRequestResponse{Request: softwareRequest}, nil
+}
+
+func (svc *Service) ApproveSoftwareRequest(ctx context.Context, id uint, comment string) (*mdmlab.SoftwareRequest, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
+
+func (svc *Service) DenySoftwareRequest(ctx context.Context, id uint, comment string) (*mdmlab.SoftwareRequest, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, mdmlab.ErrMissingLicense
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get Software Title Usage
+/////////////////////////////////////////////////////////////////////////////////
+
+type getSoftwareTitleUsageRequest struct {
+	ID     uint  `url:"id"`
+	TeamID *uint `query:"team_id,optional"`
+}
+
+type getSoftwareTitleUsageResponse struct {
+	Usage *mdmlab.SoftwareTitleUsage `json:"usage,omitempty"`
+	Err   error                      `json:"error,omitempty"`
+}
+
+func (r getSoftwareTitleUsageResponse) error() error { return r.Err }
+
+func getSoftwareTitleUsageEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareTitleUsageRequest)
+	usage, err := svc.SoftwareTitleUsage(ctx, req.ID, req.TeamID)
+	if err != nil {
+		return getSoftwareTitleUsageResponse{Err: err}, nil
+	}
+	return getSoftwareTitleUsageResponse{Usage: usage}, nil
+}
+
+func (svc *Service) SoftwareTitleUsage(ctx context.Context, titleID uint, teamID *uint) (*mdmlab.SoftwareTitleUsage, error) {
+	tmFilter, err := svc.authorizeSoftwareUsage(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := svc.ds.SoftwareTitleUsage(ctx, titleID, teamID, tmFilter)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software title usage")
+	}
+	return usage, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// List Unused Software
+/////////////////////////////////////////////////////////////////////////////////
+
+type listUnusedSoftwareRequest struct {
+	ListOptions   mdmlab.ListOptions `url:"list_options"`
+	TeamID        *uint              `query:"team_id,optional"`
+	UnusedForDays uint               `query:"unused_for_days,optional"`
+}
+
+type listUnusedSoftwareResponse struct {
+	UnusedForDays  uint                       `json:"unused_for_days"`
+	UnusedSoftware []*mdmlab.UnusedSoftware   `json:"unused_software"`
+	Meta           *mdmlab.PaginationMetadata `json:"meta,omitempty"`
+	Err            error                      `json:"error,omitempty"`
+}
+
+func (r listUnusedSoftwareResponse) error() error { return r.Err }
+
+func listUnusedSoftwareEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listUnusedSoftwareRequest)
+	opts := mdmlab.UnusedSoftwareListOptions{
+		ListOptions:   req.ListOptions,
+		TeamID:        req.TeamID,
+		UnusedForDays: req.UnusedForDays,
+	}
+	if opts.UnusedForDays == 0 {
+		opts.UnusedForDays = mdmlab.SoftwareUsageDefaultUnusedForDays
+	}
+
+	software, meta, err := svc.ListUnusedSoftware(ctx, opts)
+	if err != nil {
+		return listUnusedSoftwareResponse{Err: err}, nil
+	}
+	if software == nil {
+		software = []*mdmlab.UnusedSoftware{}
+	}
+	return listUnusedSoftwareResponse{UnusedForDays: opts.UnusedForDays, UnusedSoftware: software, Meta: meta}, nil
+}
+
+func (svc *Service) ListUnusedSoftware(ctx context.Context, opts mdmlab.UnusedSoftwareListOptions) ([]*mdmlab.UnusedSoftware, *mdmlab.PaginationMetadata, error) {
+	tmFilter, err := svc.authorizeSoftwareUsage(ctx, opts.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	software, meta, err := svc.ds.ListUnusedSoftware(ctx, opts, tmFilter)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list unused software")
+	}
+	return software, meta, nil
+}
+
+// authorizeSoftwareUsage authorizes reading the software inventory of the team
+// and returns the filter of the teams of the user. The team of the request is
+// not set in the filter as it doesn't handle "no team".
+func (svc *Service) authorizeSoftwareUsage(ctx context.Context, teamID *uint) (mdmlab.TeamFilter, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.AuthzSoftwareInventory{
+		TeamID: teamID,
+	}, mdmlab.ActionRead); err != nil {
+		return mdmlab.TeamFilter{}, err
+	}
+
+	lic, err := svc.License(ctx)
+	if err != nil {
+		return mdmlab.TeamFilter{}, ctxerr.Wrap(ctx, err, "get license")
+	}
+	if teamID != nil && *teamID != 0 && !lic.IsPremium() {
+		return mdmlab.TeamFilter{}, mdmlab.ErrMissingLicense
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return mdmlab.TeamFilter{}, mdmlab.ErrNoContext
+	}
+	return mdmlab.TeamFilter{User: vc.User, IncludeObserver: true}, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get Software Title Unused Policy
+/////////////////////////////////////////////////////////////////////////////////
+
+type getSoftwareTitleUnusedPolicyRequest struct {
+	ID   uint `url:"id"`
+	Days uint `query:"days,optional"`
+}
+
+type getSoftwareTitleUnusedPolicyResponse struct {
+	Policy *mdmlab.SoftwareUnusedPolicy `json:"policy,omitempty"`
+	Err    error                        `json:"error,omitempty"`
+}
+
+func (r getSoftwareTitleUnusedPolicyResponse) error() error { return r.Err }
+
+func getSoftwareTitleUnusedPolicyEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getSoftwareTitleUnusedPolicyRequest)
+	days := req.Days
+	if days == 0 {
+		days = mdmlab.SoftwareUsageDefaultUnusedForDays
+	}
+
+	policy, err := svc.SoftwareTitleUnusedPolicy(ctx, req.ID, days)
+	if err != nil {
+		return getSoftwareTitleUnusedPolicyResponse{Err: err}, nil
+	}
+	return getSoftwareTitleUnusedPolicyResponse{Policy: policy}, nil
+}
+
+func (svc *Service) SoftwareTitleUnusedPolicy(ctx context.Context, titleID uint, days uint) (*mdmlab.SoftwareUnusedPolicy, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+		return nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, mdmlab.ErrNoContext
+	}
+
+	title, err := svc.ds.SoftwareTitleByID(ctx, titleID, nil, mdmlab.TeamFilter{
+		User:            vc.User,
+		IncludeObserver: true,
+	})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get software title")
+	}
+
+	policy, err := mdmlab.NewSoftwareUnusedPolicy(title, days)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "generate unused software policy")
+	}
+	return policy, nil
+}
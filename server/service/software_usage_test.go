@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/license"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceSoftwareUsageAuth(t *testing.T) {
+	ds := new(mock.Store)
+
+	ds.SoftwareTitleUsageFunc = func(ctx context.Context, titleID uint, teamID *uint, tmFilter mdmlab.TeamFilter) (*mdmlab.SoftwareTitleUsage, error) {
+		// the team is not set in the filter, it doesn't support no team.
+		require.Nil(t, tmFilter.TeamID)
+		return &mdmlab.SoftwareTitleUsage{SoftwareTitleID: titleID}, nil
+	}
+	ds.ListUnusedSoftwareFunc = func(ctx context.Context, opts mdmlab.UnusedSoftwareListOptions, tmFilter mdmlab.TeamFilter) ([]*mdmlab.UnusedSoftware, *mdmlab.PaginationMetadata, error) {
+		return nil, &mdmlab.PaginationMetadata{}, nil
+	}
+	ds.SoftwareTitleByIDFunc = func(ctx context.Context, id uint, teamID *uint, tmFilter mdmlab.TeamFilter) (*mdmlab.SoftwareTitle, error) {
+		return &mdmlab.SoftwareTitle{ID: id, Name: "Figma.app", Source: "apps", BundleIdentifier: ptr.String("com.figma.Desktop")}, nil
+	}
+
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	for _, tc := range []struct {
+		name                 string
+		user                 *mdmlab.User
+		shouldFailGlobalRead bool
+		shouldFailTeamRead   bool
+	}{
+		{
+			name:                 "global-admin",
+			user:                 &mdmlab.User{ID: 1, GlobalRole: ptr.String(mdmlab.RoleAdmin)},
+			shouldFailGlobalRead: false,
+			shouldFailTeamRead:   false,
+		},
+		{
+			name:                 "global-observer",
+			user:                 &mdmlab.User{ID: 1, GlobalRole: ptr.String(mdmlab.RoleObserver)},
+			shouldFailGlobalRead: false,
+			shouldFailTeamRead:   false,
+		},
+		{
+			name:                 "team-observer-belongs-to-team",
+			user:                 &mdmlab.User{ID: 1, Teams: []mdmlab.UserTeam{{Team: mdmlab.Team{ID: 1}, Role: mdmlab.RoleObserver}}},
+			shouldFailGlobalRead: true,
+			shouldFailTeamRead:   false,
+		},
+		{
+			name:                 "team-admin-does-not-belong-to-team",
+			user:                 &mdmlab.User{ID: 1, Teams: []mdmlab.UserTeam{{Team: mdmlab.Team{ID: 2}, Role: mdmlab.RoleAdmin}}},
+			shouldFailGlobalRead: true,
+			shouldFailTeamRead:   true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := viewer.NewContext(ctx, viewer.Viewer{User: tc.user})
+			premiumCtx := license.NewContext(ctx, &mdmlab.LicenseInfo{Tier: mdmlab.TierPremium})
+
+			_, err := svc.SoftwareTitleUsage(ctx, 1, nil)
+			checkAuthErr(t, tc.shouldFailGlobalRead, err)
+			_, err = svc.SoftwareTitleUsage(premiumCtx, 1, ptr.Uint(1))
+			checkAuthErr(t, tc.shouldFailTeamRead, err)
+
+			_, _, err = svc.ListUnusedSoftware(ctx, mdmlab.UnusedSoftwareListOptions{})
+			checkAuthErr(t, tc.shouldFailGlobalRead, err)
+			_, _, err = svc.ListUnusedSoftware(premiumCtx, mdmlab.UnusedSoftwareListOptions{TeamID: ptr.Uint(1)})
+			checkAuthErr(t, tc.shouldFailTeamRead, err)
+
+			// the software of a team requires premium
+			if !tc.shouldFailTeamRead {
+				_, err = svc.SoftwareTitleUsage(ctx, 1, ptr.Uint(1))
+				require.ErrorContains(t, err, "Requires MDMlab Premium license")
+				_, _, err = svc.ListUnusedSoftware(ctx, mdmlab.UnusedSoftwareListOptions{TeamID: ptr.Uint(1)})
+				require.ErrorContains(t, err, "Requires MDMlab Premium license")
+			}
+
+			// any user that can list hosts can generate the policy
+			policy, err := svc.SoftwareTitleUnusedPolicy(ctx, 1, 30)
+			require.NoError(t, err)
+			require.Equal(t, "Figma.app unused for 30 days", policy.Name)
+		})
+	}
+}
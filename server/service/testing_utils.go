@@ -690,6 +690,9 @@ func mdmConfigurationRequiredEndpoints() []struct {
 	}{
 		{"POST", "/api/latest/mdmlab/mdm/apple/enqueue", false, false},
 		{"GET", "/api/latest/mdmlab/mdm/apple/commandresults", false, false},
+		{"GET", "/api/latest/mdmlab/mdm/apple/commands/queue", false, false},
+		{"POST", "/api/latest/mdmlab/mdm/apple/commands/cancel", false, false},
+		{"POST", "/api/latest/mdmlab/mdm/apple/commands/push", false, false},
 		{"GET", "/api/latest/mdmlab/mdm/apple/installers/1", false, false},
 		{"DELETE", "/api/latest/mdmlab/mdm/apple/installers/1", false, false},
 		{"GET", "/api/latest/mdmlab/mdm/apple/installers", false, false},
@@ -94,6 +94,15 @@ func (svc *Service) ListVulnerabilities(ctx context.Context, opt mdmlab.VulnList
 		return nil, nil, mdmlab.ErrMissingLicense
 	}
 
+	if opt.AttackVector != "" {
+		if !opt.IsEE {
+			return nil, nil, mdmlab.ErrMissingLicense
+		}
+		if !opt.AttackVector.IsValid() {
+			return nil, nil, badRequest(fmt.Sprintf("invalid attack_vector %q", opt.AttackVector))
+		}
+	}
+
 	vulns, meta, err := svc.ds.ListVulnerabilities(ctx, opt)
 	if err != nil {
 		return nil, nil, err
@@ -50,6 +50,22 @@ func TestListVulnerabilities(t *testing.T) {
 		_, _, err = svc.ListVulnerabilities(ctx, opts)
 		require.NoError(t, err)
 	})
+
+	t.Run("attack vector filter", func(t *testing.T) {
+		// requires premium
+		opts := mdmlab.VulnListOptions{AttackVector: mdmlab.CVSSAttackVectorNetwork}
+		_, _, err := svc.ListVulnerabilities(ctx, opts)
+		require.ErrorIs(t, err, mdmlab.ErrMissingLicense)
+
+		opts.IsEE = true
+		_, _, err = svc.ListVulnerabilities(ctx, opts)
+		require.NoError(t, err)
+
+		opts.AttackVector = "remote"
+		_, _, err = svc.ListVulnerabilities(ctx, opts)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid attack_vector")
+	})
 }
 
 func TestVulnerabilitesAuth(t *testing.T) {
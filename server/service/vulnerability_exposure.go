@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/it-laborato/MDM_Lab/pkg/automatic_policy"
+	"github.com/it-laborato/MDM_Lab/server/authz"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/contexts/license"
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get vulnerability exposure
+/////////////////////////////////////////////////////////////////////////////////
+
+type getVulnerabilityExposureRequest struct {
+	CVE    string `url:"cve"`
+	TeamID *uint  `query:"team_id,optional"`
+}
+
+type getVulnerabilityExposureResponse struct {
+	*mdmlab.VulnerabilityExposure
+	Err error `json:"error,omitempty"`
+}
+
+func (r getVulnerabilityExposureResponse) error() error { return r.Err }
+
+func getVulnerabilityExposureEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getVulnerabilityExposureRequest)
+	exposure, err := svc.VulnerabilityExposure(ctx, req.CVE, req.TeamID)
+	if err != nil {
+		return getVulnerabilityExposureResponse{Err: err}, nil
+	}
+	return getVulnerabilityExposureResponse{VulnerabilityExposure: exposure}, nil
+}
+
+func (svc *Service) VulnerabilityExposure(ctx context.Context, cve string, teamID *uint) (*mdmlab.VulnerabilityExposure, error) {
+	// Vulnerability authorizes the request and validates the CVE and team, it
+	// returns a nil vulnerability if the CVE is known but doesn't affect any
+	// host.
+	vuln, _, err := svc.Vulnerability(ctx, cve, teamID, license.IsPremium(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	exposure := &mdmlab.VulnerabilityExposure{
+		CVE:           cve,
+		Vulnerability: vuln,
+		Software:      []*mdmlab.VulnerableSoftwareTitle{},
+		OSVersions:    []*mdmlab.VulnerableOS{},
+		Teams:         []*mdmlab.VulnerabilityTeamHostsCount{},
+	}
+	if vuln != nil {
+		exposure.CVE = vuln.CVE.CVE
+		exposure.Exposed = vuln.HostsCount > 0
+		vuln.DetailsLink = fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", vuln.CVE.CVE)
+	}
+
+	if exposure.Exposed {
+		software, err := svc.ds.ListVulnerableSoftwareTitlesByCVE(ctx, exposure.CVE, teamID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "list vulnerable software titles")
+		}
+		if software != nil {
+			exposure.Software = software
+		}
+
+		osVersions, _, err := svc.ds.OSVersionsByCVE(ctx, exposure.CVE, teamID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "list vulnerable OS versions")
+		}
+		if osVersions != nil {
+			exposure.OSVersions = osVersions
+		}
+
+		teams, err := svc.ds.VulnerabilityHostCountsByTeam(ctx, exposure.CVE, teamID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "list vulnerability host counts by team")
+		}
+		if teams != nil {
+			exposure.Teams = teams
+		}
+	}
+
+	// policies are listed even if no host is affected anymore, so that they can
+	// be cleaned up.
+	policies, err := svc.ds.ListVulnerabilityPolicies(ctx, exposure.CVE, teamID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability policies")
+	}
+	exposure.Policies = policies
+	if exposure.Policies == nil {
+		exposure.Policies = []*mdmlab.VulnerabilityPolicy{}
+	}
+
+	return exposure, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Create vulnerability policy
+/////////////////////////////////////////////////////////////////////////////////
+
+type createVulnerabilityPolicyRequest struct {
+	CVE             string `url:"cve"`
+	TeamID          *uint  `json:"team_id"`
+	SoftwareTitleID uint   `json:"software_title_id"`
+	Critical        bool   `json:"critical" premium:"true"`
+}
+
+type createVulnerabilityPolicyResponse struct {
+	Policy *mdmlab.Policy `json:"policy,omitempty"`
+	Err    error          `json:"error,omitempty"`
+}
+
+func (r createVulnerabilityPolicyResponse) error() error { return r.Err }
+
+func createVulnerabilityPolicyEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*createVulnerabilityPolicyRequest)
+	policy, err := svc.NewVulnerabilityPolicy(ctx, mdmlab.VulnerabilityPolicyPayload{
+		CVE:             req.CVE,
+		TeamID:          req.TeamID,
+		SoftwareTitleID: req.SoftwareTitleID,
+		Critical:        req.Critical,
+	})
+	if err != nil {
+		return createVulnerabilityPolicyResponse{Err: err}, nil
+	}
+	return createVulnerabilityPolicyResponse{Policy: policy}, nil
+}
+
+func (svc *Service) NewVulnerabilityPolicy(ctx context.Context, payload mdmlab.VulnerabilityPolicyPayload) (*mdmlab.Policy, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.Policy{
+		PolicyData: mdmlab.PolicyData{
+			TeamID: payload.TeamID,
+		},
+	}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	if !cveRegex.MatchString(payload.CVE) {
+		return nil, badRequest("That vulnerability (CVE) is not valid. Try updating your search to use CVE format: \"CVE-YYYY-<4 or more digits>\"")
+	}
+	if payload.SoftwareTitleID == 0 {
+		return nil, badRequest("software_title_id is required")
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("user must be authenticated to create vulnerability policies")
+	}
+
+	// only the vulnerable versions installed on the hosts the policy applies to are
+	// detected, so that the software of other teams is not disclosed.
+	titles, err := svc.ds.ListVulnerableSoftwareTitlesByCVE(ctx, payload.CVE, payload.TeamID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerable software titles")
+	}
+	var title *mdmlab.VulnerableSoftwareTitle
+	for _, t := range titles {
+		if t.SoftwareTitleID != nil && *t.SoftwareTitleID == payload.SoftwareTitleID {
+			title = t
+			break
+		}
+	}
+	if title == nil {
+		return nil, badRequest(fmt.Sprintf("No installed version of software title %d is vulnerable to %s.", payload.SoftwareTitleID, payload.CVE))
+	}
+
+	versions := make([]string, 0, len(title.Versions))
+	for _, v := range title.Versions {
+		versions = append(versions, v.Version)
+	}
+	metadata := automatic_policy.VulnerableSoftwareMetadata{
+		CVE:              payload.CVE,
+		Title:            title.Name,
+		Source:           title.Source,
+		BundleIdentifier: title.BundleIdentifier,
+		Versions:         versions,
+	}
+	if title.FixedVersion != nil {
+		metadata.FixedVersion = *title.FixedVersion
+	}
+	policyData, err := automatic_policy.GenerateVulnerabilityDetection(metadata)
+	if err != nil {
+		if errors.Is(err, automatic_policy.ErrSourceNotSupported) {
+			return nil, badRequest(fmt.Sprintf("Policies can't be generated for software from source %q.", title.Source))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "generate vulnerability policy")
+	}
+
+	p := mdmlab.PolicyPayload{
+		Name:        policyData.Name,
+		Query:       policyData.Query,
+		Description: policyData.Description,
+		Resolution:  policyData.Resolution,
+		Platform:    policyData.Platform,
+		Critical:    payload.Critical,
+	}
+	if err := p.Verify(); err != nil {
+		return nil, ctxerr.Wrap(ctx, &mdmlab.BadRequestError{
+			Message: fmt.Sprintf("policy payload verification: %s", err),
+		})
+	}
+	policy, err := svc.ds.NewVulnerabilityPolicy(ctx, payload.TeamID, ptr.Uint(vc.UserID()), p, payload.CVE, ptr.Uint(payload.SoftwareTitleID))
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create vulnerability policy")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeCreatedPolicy{
+			ID:   policy.ID,
+			Name: policy.Name,
+		},
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for vulnerability policy creation")
+	}
+	return policy, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityExposure(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+	ctx = viewer.NewContext(ctx, viewer.Viewer{User: &mdmlab.User{GlobalRole: ptr.String(mdmlab.RoleAdmin)}})
+
+	var hostsCount uint
+	ds.VulnerabilityFunc = func(ctx context.Context, cve string, teamID *uint, includeCVEScores bool) (*mdmlab.VulnerabilityWithMetadata, error) {
+		if cve == "CVE-2024-9999" {
+			return nil, newNotFoundError()
+		}
+		return &mdmlab.VulnerabilityWithMetadata{CVE: mdmlab.CVE{CVE: cve}, HostsCount: hostsCount}, nil
+	}
+	ds.IsCVEKnownToMDMlabFunc = func(ctx context.Context, cve string) (bool, error) {
+		return false, nil
+	}
+	ds.TeamExistsFunc = func(ctx context.Context, teamID uint) (bool, error) {
+		return true, nil
+	}
+	ds.ListVulnerableSoftwareTitlesByCVEFunc = func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerableSoftwareTitle, error) {
+		return []*mdmlab.VulnerableSoftwareTitle{{SoftwareTitleID: ptr.Uint(1), Name: "Chrome", Source: "apps", HostsCount: 2}}, nil
+	}
+	ds.OSVersionsByCVEFunc = func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerableOS, time.Time, error) {
+		return nil, time.Time{}, nil
+	}
+	ds.VulnerabilityHostCountsByTeamFunc = func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityTeamHostsCount, error) {
+		return []*mdmlab.VulnerabilityTeamHostsCount{{TeamID: 0, TeamName: "No team", HostsCount: 2}}, nil
+	}
+	ds.ListVulnerabilityPoliciesFunc = func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerabilityPolicy, error) {
+		return nil, nil
+	}
+
+	// invalid CVE
+	_, err := svc.VulnerabilityExposure(ctx, "CVE-INVALID", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not valid")
+
+	// unknown CVE
+	_, err = svc.VulnerabilityExposure(ctx, "CVE-2024-9999", nil)
+	require.Error(t, err)
+	require.True(t, mdmlab.IsNotFound(err))
+
+	// known CVE not affecting any host
+	exposure, err := svc.VulnerabilityExposure(ctx, "CVE-2024-0001", nil)
+	require.NoError(t, err)
+	require.False(t, exposure.Exposed)
+	require.Empty(t, exposure.Software)
+	require.NotNil(t, exposure.OSVersions)
+	require.NotNil(t, exposure.Policies)
+	require.False(t, ds.ListVulnerableSoftwareTitlesByCVEFuncInvoked)
+
+	// affected hosts
+	hostsCount = 2
+	exposure, err = svc.VulnerabilityExposure(ctx, "CVE-2024-0001", ptr.Uint(0))
+	require.NoError(t, err)
+	require.True(t, exposure.Exposed)
+	require.Len(t, exposure.Software, 1)
+	require.Len(t, exposure.Teams, 1)
+	require.Empty(t, exposure.OSVersions)
+	require.NotNil(t, exposure.OSVersions)
+	require.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2024-0001", exposure.Vulnerability.DetailsLink)
+}
+
+func TestNewVulnerabilityPolicy(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	var gotListTeamID *uint
+	ds.ListVulnerableSoftwareTitlesByCVEFunc = func(ctx context.Context, cve string, teamID *uint) ([]*mdmlab.VulnerableSoftwareTitle, error) {
+		gotListTeamID = teamID
+		return []*mdmlab.VulnerableSoftwareTitle{
+			{
+				SoftwareTitleID: ptr.Uint(1),
+				Name:            "Google Chrome.app",
+				Source:          "apps",
+				Versions: []*mdmlab.VulnerableSoftware{
+					{Version: "1.0.0"},
+					{Version: "1.1.0"},
+				},
+				FixedVersion: ptr.String("1.2.0"),
+			},
+			{SoftwareTitleID: ptr.Uint(2), Name: "vim", Source: "unsupported"},
+			{Name: "not reconciled", Source: "apps"},
+		}, nil
+	}
+	ds.NewActivityFunc = func(
+		ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time,
+	) error {
+		return nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+	var gotTeamID *uint
+	var gotPayload mdmlab.PolicyPayload
+	ds.NewVulnerabilityPolicyFunc = func(ctx context.Context, teamID *uint, authorID *uint, args mdmlab.PolicyPayload, cve string, softwareTitleID *uint) (*mdmlab.Policy, error) {
+		require.Equal(t, "CVE-2024-0001", cve)
+		require.Equal(t, ptr.Uint(1), softwareTitleID)
+		require.Equal(t, ptr.Uint(1), authorID)
+		gotTeamID = teamID
+		gotPayload = args
+		id := uint(10)
+		if teamID != nil {
+			id = 11
+		}
+		return &mdmlab.Policy{PolicyData: mdmlab.PolicyData{ID: id, Name: args.Name, TeamID: teamID}}, nil
+	}
+
+	// team observers can't create policies
+	teamObserverCtx := viewer.NewContext(ctx, viewer.Viewer{User: &mdmlab.User{
+		ID:    2,
+		Teams: []mdmlab.UserTeam{{Team: mdmlab.Team{ID: 1}, Role: mdmlab.RoleObserver}},
+	}})
+	_, err := svc.NewVulnerabilityPolicy(teamObserverCtx, mdmlab.VulnerabilityPolicyPayload{
+		CVE: "CVE-2024-0001", TeamID: ptr.Uint(1), SoftwareTitleID: 1,
+	})
+	checkAuthErr(t, true, err)
+
+	ctx = viewer.NewContext(ctx, viewer.Viewer{User: &mdmlab.User{ID: 1, GlobalRole: ptr.String(mdmlab.RoleAdmin)}})
+
+	for _, tc := range []struct {
+		name    string
+		payload mdmlab.VulnerabilityPolicyPayload
+		wantErr string
+	}{
+		{"invalid CVE", mdmlab.VulnerabilityPolicyPayload{CVE: "foo", SoftwareTitleID: 1}, "not valid"},
+		{"missing title", mdmlab.VulnerabilityPolicyPayload{CVE: "CVE-2024-0001"}, "software_title_id is required"},
+		{"title not vulnerable", mdmlab.VulnerabilityPolicyPayload{CVE: "CVE-2024-0001", SoftwareTitleID: 3}, "No installed version"},
+		{"unsupported source", mdmlab.VulnerabilityPolicyPayload{CVE: "CVE-2024-0001", SoftwareTitleID: 2}, "can't be generated"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.NewVulnerabilityPolicy(ctx, tc.payload)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+
+	// global policy
+	policy, err := svc.NewVulnerabilityPolicy(ctx, mdmlab.VulnerabilityPolicyPayload{CVE: "CVE-2024-0001", SoftwareTitleID: 1})
+	require.NoError(t, err)
+	require.EqualValues(t, 10, policy.ID)
+	require.Nil(t, gotListTeamID)
+	require.Nil(t, gotTeamID)
+	require.Equal(t, "[Vulnerability] CVE-2024-0001 Google Chrome.app (apps)", gotPayload.Name)
+	require.Equal(t, "darwin", gotPayload.Platform)
+	require.Contains(t, gotPayload.Query, "bundle_short_version IN ('1.0.0', '1.1.0')")
+	require.Equal(t, "Update Google Chrome.app to version 1.2.0 or later.", gotPayload.Resolution)
+	require.True(t, ds.NewActivityFuncInvoked)
+
+	// team policy
+	policy, err = svc.NewVulnerabilityPolicy(ctx, mdmlab.VulnerabilityPolicyPayload{CVE: "CVE-2024-0001", TeamID: ptr.Uint(1), SoftwareTitleID: 1})
+	require.NoError(t, err)
+	require.EqualValues(t, 11, policy.ID)
+	require.Equal(t, ptr.Uint(1), gotListTeamID)
+	require.Equal(t, ptr.Uint(1), gotTeamID)
+	require.Equal(t, "[Vulnerability] CVE-2024-0001 Google Chrome.app (apps)", gotPayload.Name)
+}
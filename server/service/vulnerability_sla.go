@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+/////////////////////////////////////////////////////////////////////////////////
+// List overdue vulnerabilities
+/////////////////////////////////////////////////////////////////////////////////
+
+type listOverdueVulnerabilitiesRequest struct {
+	TeamID      *uint              `query:"team_id,optional"`
+	ListOptions mdmlab.ListOptions `url:"list_options"`
+}
+
+type listOverdueVulnerabilitiesResponse struct {
+	OverdueVulnerabilities []*mdmlab.OverdueHostVulnerability `json:"overdue_vulnerabilities"`
+	Count                  uint                               `json:"count"`
+	Meta                   *mdmlab.PaginationMetadata         `json:"meta"`
+	Err                    error                              `json:"error,omitempty"`
+}
+
+func (r listOverdueVulnerabilitiesResponse) error() error { return r.Err }
+
+func listOverdueVulnerabilitiesEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listOverdueVulnerabilitiesRequest)
+	vulns, meta, err := svc.ListOverdueVulnerabilities(ctx, req.TeamID, req.ListOptions)
+	if err != nil {
+		return listOverdueVulnerabilitiesResponse{Err: err}, nil
+	}
+	if vulns == nil {
+		vulns = []*mdmlab.OverdueHostVulnerability{}
+	}
+	return listOverdueVulnerabilitiesResponse{OverdueVulnerabilities: vulns, Count: meta.TotalResults, Meta: meta}, nil
+}
+
+func (svc *Service) ListOverdueVulnerabilities(ctx context.Context, teamID *uint, opts mdmlab.ListOptions) ([]*mdmlab.OverdueHostVulnerability, *mdmlab.PaginationMetadata, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.AuthzSoftwareInventory{
+		TeamID: teamID,
+	}, mdmlab.ActionRead); err != nil {
+		return nil, nil, err
+	}
+
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "get app config")
+	}
+
+	// cursor-based pagination and matching queries are not supported
+	opts.After = ""
+	opts.MatchQuery = ""
+	// always include metadata
+	opts.IncludeMetadata = true
+
+	vulns, meta, err := svc.ds.ListOverdueHostVulnerabilities(ctx, mdmlab.OverdueHostVulnerabilityListOptions{
+		ListOptions: opts,
+		TeamID:      teamID,
+		Rules:       appConfig.VulnerabilitySettings.SLARules,
+		Now:         time.Now(),
+	})
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list overdue host vulnerabilities")
+	}
+	return vulns, meta, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Get vulnerability remediation metrics
+/////////////////////////////////////////////////////////////////////////////////
+
+type getVulnerabilityRemediationMetricsRequest struct {
+	TeamID *uint `query:"team_id,optional"`
+}
+
+type getVulnerabilityRemediationMetricsResponse struct {
+	*mdmlab.VulnerabilityRemediationMetrics
+	Err error `json:"error,omitempty"`
+}
+
+func (r getVulnerabilityRemediationMetricsResponse) error() error { return r.Err }
+
+func getVulnerabilityRemediationMetricsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*getVulnerabilityRemediationMetricsRequest)
+	metrics, err := svc.VulnerabilityRemediationMetrics(ctx, req.TeamID)
+	if err != nil {
+		return getVulnerabilityRemediationMetricsResponse{Err: err}, nil
+	}
+	return getVulnerabilityRemediationMetricsResponse{VulnerabilityRemediationMetrics: metrics}, nil
+}
+
+func (svc *Service) VulnerabilityRemediationMetrics(ctx context.Context, teamID *uint) (*mdmlab.VulnerabilityRemediationMetrics, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.AuthzSoftwareInventory{
+		TeamID: teamID,
+	}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get app config")
+	}
+
+	metrics, err := svc.ds.VulnerabilityRemediationMetrics(ctx, teamID, appConfig.VulnerabilitySettings.SLARules, time.Now())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability remediation metrics")
+	}
+	return metrics, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilitySLA(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	rules := []mdmlab.VulnerabilitySLARule{
+		{Name: "Critical", Days: 14, MinCVSSScore: ptr.Float64(9)},
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{VulnerabilitySettings: mdmlab.VulnerabilitySettings{SLARules: rules}}, nil
+	}
+	var gotOpts mdmlab.OverdueHostVulnerabilityListOptions
+	ds.ListOverdueHostVulnerabilitiesFunc = func(ctx context.Context, opts mdmlab.OverdueHostVulnerabilityListOptions) ([]*mdmlab.OverdueHostVulnerability, *mdmlab.PaginationMetadata, error) {
+		gotOpts = opts
+		old := time.Now().Add(-30 * 24 * time.Hour)
+		return []*mdmlab.OverdueHostVulnerability{
+			{
+				HostVulnerability: mdmlab.HostVulnerability{HostID: 1, CVE: "CVE-2024-0001", CVSSScore: ptr.Float64(9.8), FirstDetectedAt: old},
+				SLARule:           "Critical",
+				DueAt:             old.Add(14 * 24 * time.Hour),
+			},
+		}, &mdmlab.PaginationMetadata{HasNextResults: true, TotalResults: 3}, nil
+	}
+	var gotRules []mdmlab.VulnerabilitySLARule
+	ds.VulnerabilityRemediationMetricsFunc = func(ctx context.Context, teamID *uint, rules []mdmlab.VulnerabilitySLARule, now time.Time) (*mdmlab.VulnerabilityRemediationMetrics, error) {
+		gotRules = rules
+		return &mdmlab.VulnerabilityRemediationMetrics{TeamID: teamID, OpenCount: 2, OverdueCount: 1, ResolvedCount: 3, MeanTimeToRemediate: 3600}, nil
+	}
+
+	adminCtx := viewer.NewContext(ctx, viewer.Viewer{User: test.UserAdmin})
+	overdue, meta, err := svc.ListOverdueVulnerabilities(adminCtx, nil, mdmlab.ListOptions{Page: 1, PerPage: 1, After: "x"})
+	require.NoError(t, err)
+	require.Len(t, overdue, 1)
+	require.Equal(t, "CVE-2024-0001", overdue[0].CVE)
+	require.Equal(t, "Critical", overdue[0].SLARule)
+	require.Equal(t, uint(3), meta.TotalResults)
+	require.True(t, meta.HasNextResults)
+	require.Nil(t, gotOpts.TeamID)
+	require.Equal(t, rules, gotOpts.Rules)
+	require.Equal(t, uint(1), gotOpts.Page)
+	require.Equal(t, uint(1), gotOpts.PerPage)
+	require.Empty(t, gotOpts.After)
+	require.True(t, gotOpts.IncludeMetadata)
+	require.WithinDuration(t, time.Now(), gotOpts.Now, time.Minute)
+
+	metrics, err := svc.VulnerabilityRemediationMetrics(adminCtx, ptr.Uint(1))
+	require.NoError(t, err)
+	require.Equal(t, ptr.Uint(1), metrics.TeamID)
+	require.Equal(t, 2, metrics.OpenCount)
+	require.Equal(t, 1, metrics.OverdueCount)
+	require.Equal(t, 3, metrics.ResolvedCount)
+	require.Equal(t, rules, gotRules)
+
+	// team users can only get the vulnerabilities of their team
+	teamCtx := viewer.NewContext(ctx, viewer.Viewer{User: test.UserTeamObserverTeam1})
+	_, _, err = svc.ListOverdueVulnerabilities(teamCtx, ptr.Uint(1), mdmlab.ListOptions{})
+	require.NoError(t, err)
+	_, _, err = svc.ListOverdueVulnerabilities(teamCtx, nil, mdmlab.ListOptions{})
+	checkAuthErr(t, true, err)
+	_, err = svc.VulnerabilityRemediationMetrics(teamCtx, ptr.Uint(2))
+	checkAuthErr(t, true, err)
+
+	// no user
+	_, err = svc.VulnerabilityRemediationMetrics(ctx, nil)
+	checkAuthErr(t, true, err)
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/authz"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/vex"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// POST /vulnerability_suppressions
+////////////////////////////////////////////////////////////////////////////////
+
+type createVulnerabilitySuppressionRequest struct {
+	mdmlab.VulnerabilitySuppressionPayload
+}
+
+type createVulnerabilitySuppressionResponse struct {
+	Suppression *mdmlab.VulnerabilitySuppression `json:"suppression,omitempty"`
+	Err         error                            `json:"error,omitempty"`
+}
+
+func (r createVulnerabilitySuppressionResponse) error() error { return r.Err }
+
+func createVulnerabilitySuppressionEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*createVulnerabilitySuppressionRequest)
+	suppression, err := svc.NewVulnerabilitySuppression(ctx, req.VulnerabilitySuppressionPayload)
+	if err != nil {
+		return createVulnerabilitySuppressionResponse{Err: err}, nil
+	}
+	return createVulnerabilitySuppressionResponse{Suppression: suppression}, nil
+}
+
+func (svc *Service) NewVulnerabilitySuppression(ctx context.Context, payload mdmlab.VulnerabilitySuppressionPayload) (*mdmlab.VulnerabilitySuppression, error) {
+	suppression := payload.ToSuppression()
+
+	// authorize with the team of the hosts affected by the suppression, software title
+	// suppressions apply to all teams.
+	authzTeamID := suppression.TeamID
+	if suppression.HostID != nil {
+		// authorize the read of the host before exposing whether it exists
+		if err := svc.authz.Authorize(ctx, &mdmlab.Host{}, mdmlab.ActionList); err != nil {
+			return nil, err
+		}
+		host, err := svc.ds.HostLite(ctx, *suppression.HostID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "get host for vulnerability suppression")
+		}
+		authzTeamID = host.TeamID
+	}
+	if authzTeamID != nil && *authzTeamID == 0 {
+		// "No team" is only accessible to global users
+		authzTeamID = nil
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.VulnerabilitySuppression{TeamID: authzTeamID}, mdmlab.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	if err := suppression.Validate(time.Now()); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "validate vulnerability suppression")
+	}
+	if err := svc.validateVulnerabilitySuppressionScope(ctx, suppression); err != nil {
+		return nil, err
+	}
+
+	if user := authz.UserFromContext(ctx); user != nil {
+		suppression.AuthorID = &user.ID
+	}
+	suppression, err := svc.ds.NewVulnerabilitySuppression(ctx, suppression)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create vulnerability suppression")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeCreatedVulnerabilitySuppression{
+			ID:              suppression.ID,
+			CVE:             suppression.CVE,
+			TeamID:          suppression.TeamID,
+			SoftwareTitleID: suppression.SoftwareTitleID,
+			HostID:          suppression.HostID,
+			Justification:   suppression.Justification,
+			ExpiresAt:       suppression.ExpiresAt,
+		},
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for vulnerability suppression creation")
+	}
+
+	return suppression, nil
+}
+
+// validateVulnerabilitySuppressionScope checks that the team or software title of the
+// suppression exists.
+func (svc *Service) validateVulnerabilitySuppressionScope(ctx context.Context, suppression *mdmlab.VulnerabilitySuppression) error {
+	if suppression.TeamID != nil && *suppression.TeamID != 0 {
+		exists, err := svc.ds.TeamExists(ctx, *suppression.TeamID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "checking if team exists")
+		} else if !exists {
+			return mdmlab.NewInvalidArgumentError("team_id", fmt.Sprintf("team %d does not exist", *suppression.TeamID)).
+				WithStatus(http.StatusNotFound)
+		}
+	}
+	if suppression.SoftwareTitleID != nil {
+		// use a global admin as filter to check if the software title exists in any team
+		filter := mdmlab.TeamFilter{User: &mdmlab.User{GlobalRole: ptr.String(mdmlab.RoleAdmin)}}
+		if _, err := svc.ds.SoftwareTitleByID(ctx, *suppression.SoftwareTitleID, nil, filter); err != nil {
+			if mdmlab.IsNotFound(err) {
+				return mdmlab.NewInvalidArgumentError("software_title_id",
+					fmt.Sprintf("software title %d does not exist", *suppression.SoftwareTitleID)).WithStatus(http.StatusNotFound)
+			}
+			return ctxerr.Wrap(ctx, err, "get software title for vulnerability suppression")
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GET /vulnerability_suppressions
+////////////////////////////////////////////////////////////////////////////////
+
+type listVulnerabilitySuppressionsRequest struct {
+	mdmlab.ListVulnerabilitySuppressionsOptions
+}
+
+type listVulnerabilitySuppressionsResponse struct {
+	Suppressions []*mdmlab.VulnerabilitySuppression `json:"suppressions"`
+	Err          error                              `json:"error,omitempty"`
+}
+
+func (r listVulnerabilitySuppressionsResponse) error() error { return r.Err }
+
+func listVulnerabilitySuppressionsEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*listVulnerabilitySuppressionsRequest)
+	suppressions, err := svc.ListVulnerabilitySuppressions(ctx, req.ListVulnerabilitySuppressionsOptions)
+	if err != nil {
+		return listVulnerabilitySuppressionsResponse{Err: err}, nil
+	}
+	return listVulnerabilitySuppressionsResponse{Suppressions: suppressions}, nil
+}
+
+func (svc *Service) ListVulnerabilitySuppressions(ctx context.Context, opts mdmlab.ListVulnerabilitySuppressionsOptions) ([]*mdmlab.VulnerabilitySuppression, error) {
+	authzTeamID := opts.TeamID
+	if authzTeamID != nil && *authzTeamID == 0 {
+		authzTeamID = nil
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.VulnerabilitySuppression{TeamID: authzTeamID}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	if opts.CVE != "" {
+		if !cveRegex.MatchString(opts.CVE) {
+			return nil, badRequest("That vulnerability (CVE) is not valid. Try updating your search to use CVE format: \"CVE-YYYY-<4 or more digits>\"")
+		}
+		opts.CVE = strings.ToUpper(opts.CVE)
+	}
+
+	suppressions, err := svc.ds.ListVulnerabilitySuppressions(ctx, opts)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability suppressions")
+	}
+	return suppressions, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// DELETE /vulnerability_suppressions/{id}
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteVulnerabilitySuppressionRequest struct {
+	ID uint `url:"id"`
+}
+
+type deleteVulnerabilitySuppressionResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteVulnerabilitySuppressionResponse) error() error { return r.Err }
+
+func deleteVulnerabilitySuppressionEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*deleteVulnerabilitySuppressionRequest)
+	err := svc.DeleteVulnerabilitySuppression(ctx, req.ID)
+	return deleteVulnerabilitySuppressionResponse{Err: err}, nil
+}
+
+func (svc *Service) DeleteVulnerabilitySuppression(ctx context.Context, id uint) error {
+	suppression, err := svc.authorizeVulnerabilitySuppressionByID(ctx, id, mdmlab.ActionWrite)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeleteVulnerabilitySuppression(ctx, id); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete vulnerability suppression")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeDeletedVulnerabilitySuppression{
+			ID:              suppression.ID,
+			CVE:             suppression.CVE,
+			TeamID:          suppression.TeamID,
+			SoftwareTitleID: suppression.SoftwareTitleID,
+			HostID:          suppression.HostID,
+		},
+	); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for vulnerability suppression deletion")
+	}
+
+	return nil
+}
+
+func (svc *Service) authorizeVulnerabilitySuppressionByID(ctx context.Context, id uint, authzAction string) (*mdmlab.VulnerabilitySuppression, error) {
+	// first, get the suppression because we don't know which team it applies to.
+	suppression, err := svc.ds.VulnerabilitySuppression(ctx, id)
+	if err != nil {
+		if mdmlab.IsNotFound(err) {
+			// authorize with a global suppression as a fallback, returning a 404 without
+			// authorization would leak the existing/non existing ids.
+			if err := svc.authz.Authorize(ctx, &mdmlab.VulnerabilitySuppression{}, authzAction); err != nil {
+				return nil, err
+			}
+		}
+		svc.authz.SkipAuthorization(ctx)
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability suppression")
+	}
+
+	// host suppressions are authorized with the team of the host.
+	teamID := suppression.TeamID
+	if suppression.HostID != nil {
+		host, err := svc.ds.HostLite(ctx, *suppression.HostID)
+		switch {
+		case err == nil:
+			teamID = host.TeamID
+		case !mdmlab.IsNotFound(err):
+			svc.authz.SkipAuthorization(ctx)
+			return nil, ctxerr.Wrap(ctx, err, "get host for vulnerability suppression")
+		}
+	}
+	if teamID != nil && *teamID == 0 {
+		// "No team" is only accessible to global users
+		teamID = nil
+	}
+	if err := svc.authz.Authorize(ctx, &mdmlab.VulnerabilitySuppression{TeamID: teamID}, authzAction); err != nil {
+		return nil, err
+	}
+	return suppression, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// POST /vulnerability_suppressions/vex
+////////////////////////////////////////////////////////////////////////////////
+
+// maxVEXDocumentSize is the maximum size of an imported VEX document.
+const maxVEXDocumentSize int64 = 10 * 1024 * 1024
+
+type importVEXDocumentRequest struct {
+	Document []byte
+}
+
+func (req *importVEXDocumentRequest) DecodeBody(ctx context.Context, r io.Reader, u url.Values, c []*x509.Certificate) error {
+	b, err := io.ReadAll(io.LimitReader(r, maxVEXDocumentSize+1))
+	if err != nil {
+		return &mdmlab.BadRequestError{Message: "failed to read VEX document", InternalErr: err}
+	}
+	if int64(len(b)) > maxVEXDocumentSize {
+		return &mdmlab.BadRequestError{Message: "VEX document exceeds maximum accepted size"}
+	}
+	req.Document = bytes.TrimSpace(b)
+	return nil
+}
+
+type importVEXDocumentResponse struct {
+	SuppressionsCount int   `json:"suppressions_count"`
+	Err               error `json:"error,omitempty"`
+}
+
+func (r importVEXDocumentResponse) error() error { return r.Err }
+
+func importVEXDocumentEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*importVEXDocumentRequest)
+	n, err := svc.ImportVEXDocument(ctx, req.Document)
+	if err != nil {
+		return importVEXDocumentResponse{Err: err}, nil
+	}
+	return importVEXDocumentResponse{SuppressionsCount: n}, nil
+}
+
+func (svc *Service) ImportVEXDocument(ctx context.Context, document []byte) (int, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.VulnerabilitySuppression{}, mdmlab.ActionWrite); err != nil {
+		return 0, err
+	}
+
+	suppressions, err := vex.Parse(document)
+	if err != nil {
+		return 0, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("document", err.Error()))
+	}
+
+	now := time.Now()
+	var authorID *uint
+	if user := authz.UserFromContext(ctx); user != nil {
+		authorID = &user.ID
+	}
+	for i, s := range suppressions {
+		if err := s.Validate(now); err != nil {
+			return 0, ctxerr.Wrap(ctx, err, fmt.Sprintf("validate suppression %d of VEX document", i))
+		}
+		if err := svc.validateVulnerabilitySuppressionScope(ctx, s); err != nil {
+			return 0, err
+		}
+		s.AuthorID = authorID
+	}
+
+	if err := svc.ds.UpsertVulnerabilitySuppressions(ctx, suppressions); err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "upsert vulnerability suppressions")
+	}
+
+	if err := svc.NewActivity(
+		ctx,
+		authz.UserFromContext(ctx),
+		mdmlab.ActivityTypeImportedVEXDocument{SuppressionsCount: len(suppressions)},
+	); err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "create activity for VEX document import")
+	}
+
+	return len(suppressions), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GET /vulnerability_suppressions/vex
+////////////////////////////////////////////////////////////////////////////////
+
+type exportVEXDocumentRequest struct {
+	Format string `query:"format,optional"`
+}
+
+func exportVEXDocumentEndpoint(ctx context.Context, request interface{}, svc mdmlab.Service) (errorer, error) {
+	req := request.(*exportVEXDocumentRequest)
+	format := req.Format
+	if format == "" {
+		format = string(vex.FormatOpenVEX)
+	}
+	b, err := svc.ExportVEXDocument(ctx, format)
+	if err != nil {
+		return downloadFileResponse{Err: err}, nil
+	}
+	return downloadFileResponse{
+		content:     b,
+		filename:    fmt.Sprintf("%s %s.vex.json", time.Now().Format(time.DateOnly), format),
+		contentType: "application/json",
+	}, nil
+}
+
+func (svc *Service) ExportVEXDocument(ctx context.Context, format string) ([]byte, error) {
+	if err := svc.authz.Authorize(ctx, &mdmlab.VulnerabilitySuppression{}, mdmlab.ActionRead); err != nil {
+		return nil, err
+	}
+
+	vexFormat, err := vex.ParseFormat(format)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, mdmlab.NewInvalidArgumentError("format", err.Error()))
+	}
+
+	suppressions, err := svc.ds.ListVulnerabilitySuppressions(ctx, mdmlab.ListVulnerabilitySuppressionsOptions{})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability suppressions")
+	}
+
+	var author string
+	if user := authz.UserFromContext(ctx); user != nil {
+		author = user.Email
+	}
+	b, err := vex.Export(vexFormat, suppressions, author, time.Now())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "export VEX document")
+	}
+	return b, nil
+}
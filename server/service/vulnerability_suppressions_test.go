@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/viewer"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/it-laborato/MDM_Lab/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilitySuppressions(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	ds.HostLiteFunc = func(ctx context.Context, hostID uint) (*mdmlab.Host, error) {
+		return &mdmlab.Host{ID: hostID, TeamID: ptr.Uint(1)}, nil
+	}
+	ds.TeamExistsFunc = func(ctx context.Context, teamID uint) (bool, error) {
+		return teamID == 1, nil
+	}
+	ds.NewVulnerabilitySuppressionFunc = func(ctx context.Context, s *mdmlab.VulnerabilitySuppression) (*mdmlab.VulnerabilitySuppression, error) {
+		s.ID = 1
+		return s, nil
+	}
+	var upserted []*mdmlab.VulnerabilitySuppression
+	ds.UpsertVulnerabilitySuppressionsFunc = func(ctx context.Context, suppressions []*mdmlab.VulnerabilitySuppression) error {
+		upserted = suppressions
+		return nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
+	ds.NewActivityFunc = func(
+		ctx context.Context, user *mdmlab.User, activity mdmlab.ActivityDetails, details []byte, createdAt time.Time,
+	) error {
+		return nil
+	}
+
+	payload := mdmlab.VulnerabilitySuppressionPayload{
+		CVE:           "cve-2024-0001",
+		HostID:        ptr.Uint(3),
+		Justification: mdmlab.VEXJustificationVulnerableCodeNotInExecutePath,
+	}
+
+	// team users can suppress vulnerabilities of the hosts of their team
+	teamCtx := viewer.NewContext(ctx, viewer.Viewer{User: test.UserTeamMaintainerTeam1})
+	s, err := svc.NewVulnerabilitySuppression(teamCtx, payload)
+	require.NoError(t, err)
+	require.Equal(t, "CVE-2024-0001", s.CVE)
+	require.Equal(t, ptr.Uint(test.UserTeamMaintainerTeam1.ID), s.AuthorID)
+
+	// but not globally
+	_, err = svc.NewVulnerabilitySuppression(teamCtx, mdmlab.VulnerabilitySuppressionPayload{
+		CVE: "CVE-2024-0001", Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	checkAuthErr(t, true, err)
+
+	adminCtx := viewer.NewContext(ctx, viewer.Viewer{User: test.UserAdmin})
+	_, err = svc.NewVulnerabilitySuppression(adminCtx, mdmlab.VulnerabilitySuppressionPayload{
+		CVE: "CVE-2024-0001", TeamID: ptr.Uint(2), Justification: mdmlab.VEXJustificationComponentNotPresent,
+	})
+	require.ErrorContains(t, err, "team 2 does not exist")
+
+	_, err = svc.ImportVEXDocument(teamCtx, []byte(`{}`))
+	checkAuthErr(t, true, err)
+
+	_, err = svc.ImportVEXDocument(adminCtx, []byte(`{"bomFormat": "SPDX"}`))
+	require.ErrorContains(t, err, "unsupported VEX document")
+	require.False(t, ds.UpsertVulnerabilitySuppressionsFuncInvoked)
+
+	n, err := svc.ImportVEXDocument(adminCtx, []byte(`{
+		"@context": "https://openvex.dev/ns/v0.2.0",
+		"statements": [{
+			"vulnerability": {"name": "CVE-2024-0002"},
+			"products": [{"@id": "mdmlab:team/1"}, {"@id": "mdmlab:global"}],
+			"status": "not_affected",
+			"justification": "component_not_present"
+		}]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Len(t, upserted, 2)
+	require.Equal(t, ptr.Uint(test.UserAdmin.ID), upserted[0].AuthorID)
+}
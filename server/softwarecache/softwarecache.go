@@ -0,0 +1,409 @@
+// Package softwarecache implements the local cache server started with
+// `mdmlab software_cache` on a designated host of a site. It serves the
+// software installers to orbit and the bootstrap packages to the macOS hosts
+// of the site, so that each package is downloaded only once from the MDMlab
+// server over the WAN link.
+//
+// The cache doesn't hold any credentials: the software installer requests of
+// the hosts are authorized by short-lived tokens issued by the MDMlab server
+// for a single install, and every package is verified against the SHA-256
+// hash known by the server before it is stored.
+package softwarecache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	installersDir = "installers"
+	bootstrapDir  = "bootstrap"
+	tmpDir        = "tmp"
+
+	// maxRequestBodySize is the maximum size of the body of the requests made
+	// by orbit, which only hold the cache token and the installer identifier.
+	maxRequestBodySize = 64 * 1024
+)
+
+// Cache is the software installer cache, its Handler serves the same
+// download endpoints as the MDMlab server.
+type Cache struct {
+	upstream *url.URL
+	client   *http.Client
+	dir      string
+	maxSize  int64
+	logger   kitlog.Logger
+
+	group   singleflight.Group
+	pruneMu sync.Mutex
+}
+
+// New returns a cache of the packages of the MDMlab server at serverURL,
+// stored in dir. If maxSize is > 0, the least recently used packages are
+// removed when the size of the cache exceeds maxSize bytes.
+func New(serverURL string, dir string, maxSize int64, client *http.Client, logger kitlog.Logger) (*Cache, error) {
+	upstream, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing server URL: %w", err)
+	}
+	if upstream.Scheme != "https" && upstream.Scheme != "http" {
+		return nil, fmt.Errorf("invalid server URL %q", serverURL)
+	}
+	for _, d := range []string{installersDir, bootstrapDir, tmpDir} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			return nil, fmt.Errorf("creating cache directory: %w", err)
+		}
+	}
+	return &Cache{
+		upstream: upstream,
+		client:   client,
+		dir:      dir,
+		maxSize:  maxSize,
+		logger:   logger,
+	}, nil
+}
+
+// Handler returns the HTTP handler of the cache.
+func (c *Cache) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/mdmlab/orbit/software_install/package", c.serveSoftwareInstaller)
+	mux.HandleFunc("GET /api/latest/mdmlab/mdm/bootstrap", c.serveBootstrapPackage)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// downloadInstallerRequest is the body of the software installer download
+// request of orbit. The cache token is issued by the MDMlab server with the
+// details of the install, it authorizes the download of its installer only.
+type downloadInstallerRequest struct {
+	InstallerID uint   `json:"installer_id"`
+	CacheToken  string `json:"cache_token"`
+}
+
+// cacheToken is the installer that a cache token authorizes to download.
+type cacheToken struct {
+	InstallerID     uint   `json:"installer_id"`
+	InstallerSHA256 string `json:"installer_sha256"`
+}
+
+func (c *Cache) serveSoftwareInstaller(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("alt") != "media" {
+		http.Error(w, "only alt=media is supported", http.StatusBadRequest)
+		return
+	}
+	var req downloadInstallerRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.CacheToken == "" {
+		http.Error(w, "cache_token is required", http.StatusBadRequest)
+		return
+	}
+
+	// the token is checked with the MDMlab server, which gives the hash of the
+	// installer that the host must receive.
+	details, err := c.cacheToken(r.Context(), req.CacheToken)
+	if err != nil {
+		c.writeError(w, "get cache token", err)
+		return
+	}
+	if details.InstallerID != req.InstallerID {
+		http.Error(w, "installer_id doesn't match the install", http.StatusForbidden)
+		return
+	}
+	if !isSHA256(details.InstallerSHA256) {
+		// the installer was added before MDMlab computed the hashes, it can't
+		// be verified and is never cached.
+		http.Error(w, "installer hash is unknown", http.StatusBadGateway)
+		return
+	}
+
+	path, err := c.get(installersDir, details.InstallerSHA256, details.InstallerSHA256, func() (*http.Response, error) {
+		// the token is redeemed by the download, a token is valid for a single
+		// download from the MDMlab server.
+		return c.upstreamRequest(context.Background(), http.MethodGet, cacheTokenPath(req.CacheToken)+"/package", "", nil)
+	})
+	if err != nil {
+		c.writeError(w, "get software installer", err)
+		return
+	}
+	c.serveFile(w, r, path)
+}
+
+func (c *Cache) serveBootstrapPackage(w http.ResponseWriter, r *http.Request) {
+	token, sum := r.URL.Query().Get("token"), r.URL.Query().Get("sha256")
+	if token == "" || !isSHA256(sum) {
+		http.Error(w, "token and sha256 are required", http.StatusBadRequest)
+		return
+	}
+
+	// the token is the only authentication of the bootstrap package download,
+	// packages are stored by token so that they are served only to the hosts
+	// that know it.
+	tokenSum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(tokenSum[:])
+	path, err := c.get(bootstrapDir, key, sum, func() (*http.Response, error) {
+		query := url.Values{"token": []string{token}}
+		return c.upstreamRequest(context.Background(), http.MethodGet, "/api/latest/mdmlab/mdm/bootstrap", query.Encode(), nil)
+	})
+	if err != nil {
+		c.writeError(w, "get bootstrap package", err)
+		return
+	}
+	c.serveFile(w, r, path)
+}
+
+// cacheToken returns the installer that the cache token authorizes to
+// download, requested to the MDMlab server.
+func (c *Cache) cacheToken(ctx context.Context, token string) (*cacheToken, error) {
+	resp, err := c.upstreamRequest(ctx, http.MethodGet, cacheTokenPath(token), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamStatusError{code: resp.StatusCode}
+	}
+
+	var details cacheToken
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return nil, fmt.Errorf("decoding cache token: %w", err)
+	}
+	return &details, nil
+}
+
+// cacheTokenPath returns the escaped path of the cache token endpoint of the
+// MDMlab server.
+func cacheTokenPath(token string) string {
+	return "/api/latest/mdmlab/software_cache/token/" + url.PathEscape(token)
+}
+
+func (c *Cache) upstreamRequest(ctx context.Context, method, path, rawQuery string, body []byte) (*http.Response, error) {
+	u := *c.upstream
+	// the paths are escaped, they hold the cache tokens.
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		return nil, err
+	}
+	u.Path, u.RawPath = unescaped, path
+	u.RawQuery = rawQuery
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.client.Do(req)
+}
+
+// get returns the path of the cached package identified by key in the
+// directory of kind, downloading it with download if it is not cached. The
+// downloaded package is stored only if its SHA-256 hash is wantSHA256.
+// Concurrent requests of the same package wait for a single download.
+func (c *Cache) get(kind, key, wantSHA256 string, download func() (*http.Response, error)) (string, error) {
+	if path, ok := c.lookup(kind, key); ok {
+		return path, nil
+	}
+	v, err, _ := c.group.Do(kind+"/"+key, func() (any, error) {
+		if path, ok := c.lookup(kind, key); ok {
+			return path, nil
+		}
+		path, err := c.store(kind, key, wantSHA256, download)
+		if err != nil {
+			return "", err
+		}
+		level.Info(c.logger).Log("msg", "cached package", "kind", kind, "path", path)
+		c.prune()
+		return path, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// lookup returns the path of the cached package identified by key. Each
+// package is stored alone in the directory named after its key, with the
+// file name sent by the MDMlab server.
+func (c *Cache) lookup(kind, key string) (string, bool) {
+	entryDir := filepath.Join(c.dir, kind, key)
+	entries, err := os.ReadDir(entryDir)
+	if err != nil || len(entries) != 1 || !entries[0].Type().IsRegular() {
+		return "", false
+	}
+	path := filepath.Join(entryDir, entries[0].Name())
+
+	// the modification time is the last use of the package, for pruning.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return path, true
+}
+
+func (c *Cache) store(kind, key, wantSHA256 string, download func() (*http.Response, error)) (string, error) {
+	resp, err := download()
+	if err != nil {
+		return "", fmt.Errorf("downloading package: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", upstreamStatusError{code: resp.StatusCode}
+	}
+
+	filename := "package"
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		filename = filepath.Base(params["filename"])
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, tmpDir), "download-")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), resp.Body); err != nil {
+		return "", fmt.Errorf("downloading package: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("writing package: %w", err)
+	}
+	if gotSHA256 := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(gotSHA256, wantSHA256) {
+		return "", fmt.Errorf("package hash mismatch: expected %s, got %s", wantSHA256, gotSHA256)
+	}
+
+	entryDir := filepath.Join(c.dir, kind, key)
+	if err := os.MkdirAll(entryDir, 0o755); err != nil {
+		return "", fmt.Errorf("creating package directory: %w", err)
+	}
+	path := filepath.Join(entryDir, filename)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("storing package: %w", err)
+	}
+	return path, nil
+}
+
+// prune removes the least recently used packages until the size of the
+// cache is under its maximum size.
+func (c *Cache) prune() {
+	if c.maxSize <= 0 {
+		return
+	}
+	c.pruneMu.Lock()
+	defer c.pruneMu.Unlock()
+
+	type cachedPackage struct {
+		dir     string
+		size    int64
+		lastUse time.Time
+	}
+	var (
+		packages []cachedPackage
+		total    int64
+	)
+	for _, kind := range []string{installersDir, bootstrapDir} {
+		paths, err := filepath.Glob(filepath.Join(c.dir, kind, "*", "*"))
+		if err != nil {
+			continue
+		}
+		for _, p := range paths {
+			info, err := os.Stat(p)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			packages = append(packages, cachedPackage{dir: filepath.Dir(p), size: info.Size(), lastUse: info.ModTime()})
+			total += info.Size()
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool { return packages[i].lastUse.Before(packages[j].lastUse) })
+	for _, p := range packages {
+		// the most recent package is always kept, even if it is bigger than the
+		// maximum size.
+		if total <= c.maxSize || p.dir == packages[len(packages)-1].dir {
+			break
+		}
+		if err := os.RemoveAll(p.dir); err != nil {
+			level.Error(c.logger).Log("msg", "removing cached package", "dir", p.dir, "err", err)
+			continue
+		}
+		level.Info(c.logger).Log("msg", "removed least recently used package", "dir", p.dir)
+		total -= p.size
+	}
+}
+
+func (c *Cache) serveFile(w http.ResponseWriter, r *http.Request, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		c.writeError(w, "open cached package", err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		c.writeError(w, "stat cached package", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment;filename="%s"`, filepath.Base(path)))
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+// writeError writes the error response, the client errors of the MDMlab
+// server (e.g. an invalid token) are forwarded to the host.
+func (c *Cache) writeError(w http.ResponseWriter, msg string, err error) {
+	var statusErr upstreamStatusError
+	if errors.As(err, &statusErr) && statusErr.code >= 400 && statusErr.code < 500 {
+		http.Error(w, http.StatusText(statusErr.code), statusErr.code)
+		return
+	}
+	level.Error(c.logger).Log("msg", msg, "err", err)
+	http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+}
+
+// upstreamStatusError is the unexpected status code of a response of the
+// MDMlab server.
+type upstreamStatusError struct {
+	code int
+}
+
+func (e upstreamStatusError) Error() string {
+	return fmt.Sprintf("MDMlab server responded with status %d", e.code)
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
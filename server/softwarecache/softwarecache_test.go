@@ -0,0 +1,234 @@
+package softwarecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeServer struct {
+	installer      []byte
+	installerHash  string
+	bootstrap      []byte
+	downloadsCount atomic.Int32
+
+	// redeemed are the cache tokens used to download the installer, the cache
+	// tokens are valid if they start with "token-".
+	mu       sync.Mutex
+	redeemed map[string]bool
+}
+
+func (fs *fakeServer) validToken(token string) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return strings.HasPrefix(token, "token-") && !fs.redeemed[token]
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	fs := &fakeServer{
+		installer: []byte("installer content"),
+		bootstrap: []byte("bootstrap content"),
+		redeemed:  make(map[string]bool),
+	}
+	sum := sha256.Sum256(fs.installer)
+	fs.installerHash = hex.EncodeToString(sum[:])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/latest/mdmlab/software_cache/token/{token}", func(w http.ResponseWriter, r *http.Request) {
+		if !fs.validToken(r.PathValue("token")) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"installer_id": 1, "installer_sha256": %q}`, fs.installerHash)
+	})
+	mux.HandleFunc("GET /api/latest/mdmlab/software_cache/token/{token}/package", func(w http.ResponseWriter, r *http.Request) {
+		token := r.PathValue("token")
+		if !fs.validToken(token) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fs.mu.Lock()
+		fs.redeemed[token] = true
+		fs.mu.Unlock()
+		fs.downloadsCount.Add(1)
+		w.Header().Set("Content-Disposition", `attachment;filename="app.pkg"`)
+		_, _ = w.Write(fs.installer)
+	})
+	mux.HandleFunc("GET /api/latest/mdmlab/mdm/bootstrap", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "bootstrap-token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fs.downloadsCount.Add(1)
+		w.Header().Set("Content-Disposition", `attachment;filename="bootstrap.pkg"`)
+		_, _ = w.Write(fs.bootstrap)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return fs, srv
+}
+
+func newTestCache(t *testing.T, serverURL string, maxSize int64) (*Cache, *httptest.Server) {
+	cache, err := New(serverURL, t.TempDir(), maxSize, http.DefaultClient, kitlog.NewNopLogger())
+	require.NoError(t, err)
+	srv := httptest.NewServer(cache.Handler())
+	t.Cleanup(srv.Close)
+	return cache, srv
+}
+
+func downloadInstaller(t *testing.T, cacheURL, cacheToken string, installerID uint) *http.Response {
+	body, err := json.Marshal(downloadInstallerRequest{CacheToken: cacheToken, InstallerID: installerID})
+	require.NoError(t, err)
+	resp, err := http.Post(cacheURL+"/api/mdmlab/orbit/software_install/package?alt=media", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestSoftwareInstaller(t *testing.T) {
+	fs, upstream := newFakeServer(t)
+	_, cacheSrv := newTestCache(t, upstream.URL, 0)
+
+	// the first download is forwarded to the server
+	resp := downloadInstaller(t, cacheSrv.URL, "token-1", 1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, `attachment;filename="app.pkg"`, resp.Header.Get("Content-Disposition"))
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, fs.installer, b)
+	require.EqualValues(t, 1, fs.downloadsCount.Load())
+
+	// the next ones are served from the cache, the tokens are still checked
+	// by the server. The path of the server is escaped.
+	resp = downloadInstaller(t, cacheSrv.URL, "token-2/../?x", 1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, fs.installer, b)
+	require.EqualValues(t, 1, fs.downloadsCount.Load())
+
+	// the download is always authorized by the server
+	resp = downloadInstaller(t, cacheSrv.URL, "invalid", 1)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = downloadInstaller(t, cacheSrv.URL, "token-1", 1)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = downloadInstaller(t, cacheSrv.URL, "token-3", 2)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = downloadInstaller(t, cacheSrv.URL, "", 1)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.EqualValues(t, 1, fs.downloadsCount.Load())
+}
+
+func TestSoftwareInstallerConcurrentDownloads(t *testing.T) {
+	fs, upstream := newFakeServer(t)
+	_, cacheSrv := newTestCache(t, upstream.URL, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, _ := json.Marshal(downloadInstallerRequest{CacheToken: fmt.Sprintf("token-%d", i), InstallerID: 1})
+			resp, err := http.Post(cacheSrv.URL+"/api/mdmlab/orbit/software_install/package?alt=media", "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK || !bytes.Equal(fs.installer, b) {
+				t.Errorf("unexpected response: %d %s", resp.StatusCode, b)
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.LessOrEqual(t, fs.downloadsCount.Load(), int32(2))
+}
+
+func TestSoftwareInstallerHashMismatch(t *testing.T) {
+	fs, upstream := newFakeServer(t)
+	cache, cacheSrv := newTestCache(t, upstream.URL, 0)
+
+	// the server sends a package that doesn't match the hash of the install
+	fs.installer = []byte("tampered content")
+	resp := downloadInstaller(t, cacheSrv.URL, "token-1", 1)
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	// nothing is stored
+	_, ok := cache.lookup(installersDir, fs.installerHash)
+	require.False(t, ok)
+	tmpFiles, err := os.ReadDir(filepath.Join(cache.dir, tmpDir))
+	require.NoError(t, err)
+	require.Empty(t, tmpFiles)
+}
+
+func TestBootstrapPackage(t *testing.T) {
+	fs, upstream := newFakeServer(t)
+	_, cacheSrv := newTestCache(t, upstream.URL, 0)
+	sum := sha256.Sum256(fs.bootstrap)
+	bootstrapHash := hex.EncodeToString(sum[:])
+
+	get := func(token, hash string) *http.Response {
+		resp, err := http.Get(fmt.Sprintf("%s/api/latest/mdmlab/mdm/bootstrap?token=%s&sha256=%s", cacheSrv.URL, token, hash))
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := get("bootstrap-token", bootstrapHash)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, `attachment;filename="bootstrap.pkg"`, resp.Header.Get("Content-Disposition"))
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, fs.bootstrap, b)
+	}
+	require.EqualValues(t, 1, fs.downloadsCount.Load())
+
+	// the token is checked by the server, even if the package is cached
+	resp := get("other-token", bootstrapHash)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// the hash is required
+	resp = get("bootstrap-token", "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestPrune(t *testing.T) {
+	fs, upstream := newFakeServer(t)
+	cache, cacheSrv := newTestCache(t, upstream.URL, int64(len(fs.installer)))
+
+	resp := downloadInstaller(t, cacheSrv.URL, "token-1", 1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	installerPath, ok := cache.lookup(installersDir, fs.installerHash)
+	require.True(t, ok)
+
+	// make the installer the least recently used package
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(installerPath, old, old))
+
+	sum := sha256.Sum256(fs.bootstrap)
+	bootstrapResp, err := http.Get(fmt.Sprintf("%s/api/latest/mdmlab/mdm/bootstrap?token=bootstrap-token&sha256=%s", cacheSrv.URL, hex.EncodeToString(sum[:])))
+	require.NoError(t, err)
+	defer bootstrapResp.Body.Close()
+	require.Equal(t, http.StatusOK, bootstrapResp.StatusCode)
+
+	// the installer was removed to keep the cache under its maximum size
+	_, ok = cache.lookup(installersDir, fs.installerHash)
+	require.False(t, ok)
+}
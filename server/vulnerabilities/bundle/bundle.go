@@ -0,0 +1,515 @@
+// Package bundle implements the offline vulnerability data bundles, signed
+// archives of all the vulnerability feeds (NVD, CPE, OVAL, goval-dictionary,
+// MSRC, Mac Office and OSV) used to process vulnerabilities on servers without
+// internet access.
+//
+// A bundle is a gzipped tar archive containing, in this order, a JSON
+// manifest describing the bundled files, the Ed25519 signature of the
+// manifest and the files themselves under the "data/" directory.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion is the version of the bundle format created by this
+	// package. Bundles with a newer format version are rejected on import.
+	FormatVersion = 1
+
+	// Extension is the file extension of the bundles.
+	Extension = ".tar.gz"
+
+	// StateFileName is the name of the file, stored in the vulnerability
+	// databases path, that holds the manifest of the last imported bundle.
+	StateFileName = "vuln_bundle_manifest.json"
+
+	manifestName     = "manifest.json"
+	signatureName    = "manifest.sig"
+	dataDir          = "data/"
+	stagingDirPrefix = ".vuln_bundle_import-"
+
+	maxManifestSize = 32 << 20
+)
+
+// ErrNotNewer is returned when importing a bundle that is not newer than the
+// last imported bundle, to prevent rolling back the vulnerability data.
+var ErrNotNewer = errors.New("bundle is not newer than the last imported bundle")
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	FormatVersion int `json:"format_version"`
+	// Version is a free-form version of the bundle, it defaults to the
+	// creation timestamp.
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+}
+
+// File is a file contained in a bundle.
+type File struct {
+	// Path is the slash-separated path of the file relative to the
+	// vulnerability databases path.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// ModTime is preserved on import because the analyzers use the
+	// modification time to find the most recent feeds.
+	ModTime time.Time `json:"mod_time"`
+}
+
+// CreateOptions are the options used to create a bundle.
+type CreateOptions struct {
+	// Version of the bundle, defaults to the creation timestamp.
+	Version string
+	// CreatedAt defaults to the current time.
+	CreatedAt  time.Time
+	PrivateKey ed25519.PrivateKey
+}
+
+// Create writes to w a bundle of all the files contained in srcDir, signed
+// with the provided private key.
+func Create(w io.Writer, srcDir string, opts CreateOptions) (*Manifest, error) {
+	if len(opts.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key")
+	}
+
+	createdAt := opts.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		Version:       opts.Version,
+		CreatedAt:     createdAt.UTC().Truncate(time.Second),
+	}
+	if manifest.Version == "" {
+		manifest.Version = manifest.CreatedAt.Format("20060102150405")
+	}
+
+	err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), stagingDirPrefix) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || (d.Name() == StateFileName && filepath.Dir(p) == filepath.Clean(srcDir)) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, File{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			SHA256:  sum,
+			ModTime: info.ModTime().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", srcDir, err)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("no files found in %s", srcDir)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(opts.PrivateKey, manifestJSON))
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	if err := writeTarEntry(tw, manifestName, manifest.CreatedAt, manifestJSON); err != nil {
+		return nil, err
+	}
+	if err := writeTarEntry(tw, signatureName, manifest.CreatedAt, []byte(signature)); err != nil {
+		return nil, err
+	}
+	for _, f := range manifest.Files {
+		if err := writeTarFile(tw, srcDir, f); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("close tar: %w", err)
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, fmt.Errorf("close gzip: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, modTime time.Time, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return fmt.Errorf("write %s header: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, srcDir string, f File) error {
+	fp, err := os.Open(filepath.Join(srcDir, filepath.FromSlash(f.Path)))
+	if err != nil {
+		return fmt.Errorf("open %s: %w", f.Path, err)
+	}
+	defer fp.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    dataDir + f.Path,
+		Mode:    0o644,
+		Size:    f.Size,
+		ModTime: f.ModTime,
+	}); err != nil {
+		return fmt.Errorf("write %s header: %w", f.Path, err)
+	}
+
+	// the file must not change between the time it was hashed and the time it
+	// is archived, otherwise the bundle would fail to import.
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), io.LimitReader(fp, f.Size)); err != nil {
+		return fmt.Errorf("write %s: %w", f.Path, err)
+	}
+	if hex.EncodeToString(h.Sum(nil)) != f.SHA256 {
+		return fmt.Errorf("file %s changed while creating the bundle", f.Path)
+	}
+	return nil
+}
+
+// ImportOptions are the options used to import a bundle.
+type ImportOptions struct {
+	PublicKey ed25519.PublicKey
+	// Force imports the bundle even if it is not newer than the last imported
+	// bundle.
+	Force bool
+}
+
+// Import validates the bundle at bundlePath and extracts its files into
+// dstDir, the vulnerability databases path. The signature of the manifest is
+// verified before any file is extracted, and the files are only moved into
+// dstDir once all of them match the manifest. Files of the previously
+// imported bundle that are not part of the new bundle are removed.
+func Import(bundlePath string, dstDir string, opts ImportOptions) (*Manifest, error) {
+	if len(opts.PublicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+
+	current, err := CurrentManifest(dstDir)
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("open bundle: %w", err)
+	}
+	defer fp.Close()
+
+	gzr, err := gzip.NewReader(fp)
+	if err != nil {
+		return nil, fmt.Errorf("read gzip: %w", err)
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+
+	manifestJSON, err := readTarEntry(tr, manifestName, maxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := readTarEntry(tr, signatureName, base64.StdEncoding.EncodedLen(ed25519.SignatureSize))
+	if err != nil {
+		return nil, err
+	}
+	rawSignature, err := base64.StdEncoding.DecodeString(string(signature))
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+	if !ed25519.Verify(opts.PublicKey, manifestJSON, rawSignature) {
+		return nil, errors.New("invalid bundle signature")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest: %w", err)
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format version %d", manifest.FormatVersion)
+	}
+	if current != nil && !opts.Force && !manifest.CreatedAt.After(current.CreatedAt) {
+		return nil, fmt.Errorf("bundle %s created at %s, last imported bundle %s created at %s: %w",
+			manifest.Version, manifest.CreatedAt, current.Version, current.CreatedAt, ErrNotNewer)
+	}
+
+	expected := make(map[string]File, len(manifest.Files))
+	for _, f := range manifest.Files {
+		if !validPath(f.Path) {
+			return nil, fmt.Errorf("invalid file path in manifest: %q", f.Path)
+		}
+		if _, ok := expected[f.Path]; ok {
+			return nil, fmt.Errorf("duplicate file in manifest: %q", f.Path)
+		}
+		expected[f.Path] = f
+	}
+
+	if err := os.MkdirAll(dstDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create databases directory: %w", err)
+	}
+	stagingDir, err := os.MkdirTemp(dstDir, stagingDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	extracted := make(map[string]bool, len(expected))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read tar: %w", err)
+		}
+
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		name, ok := strings.CutPrefix(hdr.Name, dataDir)
+		if !ok || hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unexpected bundle entry %q", hdr.Name)
+		}
+		f, ok := expected[name]
+		if !ok || extracted[name] {
+			return nil, fmt.Errorf("bundle entry %q not in manifest", hdr.Name)
+		}
+		if err := extractFile(tr, stagingDir, f); err != nil {
+			return nil, err
+		}
+		extracted[name] = true
+	}
+	if len(extracted) != len(expected) {
+		return nil, fmt.Errorf("bundle is missing %d file(s) listed in the manifest", len(expected)-len(extracted))
+	}
+
+	for _, f := range manifest.Files {
+		dst := filepath.Join(dstDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return nil, fmt.Errorf("create directory for %s: %w", f.Path, err)
+		}
+		if err := os.Rename(filepath.Join(stagingDir, filepath.FromSlash(f.Path)), dst); err != nil {
+			return nil, fmt.Errorf("move %s: %w", f.Path, err)
+		}
+		if err := os.Chtimes(dst, f.ModTime, f.ModTime); err != nil {
+			return nil, fmt.Errorf("set modification time of %s: %w", f.Path, err)
+		}
+	}
+
+	if current != nil {
+		for _, f := range current.Files {
+			if _, ok := expected[f.Path]; ok || !validPath(f.Path) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dstDir, filepath.FromSlash(f.Path))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("remove outdated file %s: %w", f.Path, err)
+			}
+		}
+	}
+
+	if err := writeState(dstDir, manifestJSON); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// ImportLatest imports the most recent bundle found in srcDir (a directory
+// watched for new bundles) into dstDir. Bundles that are not newer than the
+// last imported bundle are skipped. It returns a nil manifest if no bundle
+// was imported.
+func ImportLatest(srcDir string, dstDir string, opts ImportOptions) (*Manifest, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, fmt.Errorf("read bundles directory: %w", err)
+	}
+
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var candidates []candidate
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), Extension) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{path: filepath.Join(srcDir, e.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].modTime.After(candidates[j].modTime)
+	})
+
+	var errs []error
+	for _, c := range candidates {
+		manifest, err := Import(c.path, dstDir, opts)
+		switch {
+		case err == nil:
+			return manifest, nil
+		case errors.Is(err, ErrNotNewer):
+			continue
+		default:
+			errs = append(errs, fmt.Errorf("import %s: %w", filepath.Base(c.path), err))
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// CurrentManifest returns the manifest of the last bundle imported into
+// dstDir, or nil if no bundle was imported.
+func CurrentManifest(dstDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dstDir, StateFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read bundle state: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal bundle state: %w", err)
+	}
+	return &manifest, nil
+}
+
+func writeState(dstDir string, manifestJSON []byte) error {
+	tmp, err := os.CreateTemp(dstDir, StateFileName+".*")
+	if err != nil {
+		return fmt.Errorf("create bundle state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(manifestJSON); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write bundle state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close bundle state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dstDir, StateFileName)); err != nil {
+		return fmt.Errorf("save bundle state: %w", err)
+	}
+	return nil
+}
+
+func readTarEntry(tr *tar.Reader, name string, maxSize int) ([]byte, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	if hdr.Name != name || hdr.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("expected bundle entry %q, got %q", name, hdr.Name)
+	}
+	if hdr.Size > int64(maxSize) {
+		return nil, fmt.Errorf("bundle entry %q too large", name)
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+func extractFile(r io.Reader, stagingDir string, f File) error {
+	dst := filepath.Join(stagingDir, filepath.FromSlash(f.Path))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", f.Path, err)
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", f.Path, err)
+	}
+	defer out.Close()
+
+	// read at most one byte more than expected to detect oversized files
+	// without writing an unbounded amount of data to disk.
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(r, f.Size+1))
+	if err != nil {
+		return fmt.Errorf("extract %s: %w", f.Path, err)
+	}
+	if n != f.Size || hex.EncodeToString(h.Sum(nil)) != f.SHA256 {
+		return fmt.Errorf("file %s does not match the manifest", f.Path)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+func hashFile(p string) (string, error) {
+	fp, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, fp); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// validPath returns true if p is a clean, relative, slash-separated path that
+// does not escape the destination directory and is not reserved by the
+// bundle import.
+func validPath(p string) bool {
+	if p == "" || p == "." || path.IsAbs(p) || path.Clean(p) != p || strings.Contains(p, `\`) {
+		return false
+	}
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return false
+	}
+	first, _, _ := strings.Cut(p, "/")
+	return p != StateFileName && !strings.HasPrefix(first, stagingDirPrefix)
+}
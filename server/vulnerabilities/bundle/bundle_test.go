@@ -0,0 +1,313 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateKeys(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pubPEM, privPEM, err := GenerateKeyPair()
+	require.NoError(t, err)
+	pub, err := ParsePublicKey(pubPEM)
+	require.NoError(t, err)
+	priv, err := ParsePrivateKey(privPEM)
+	require.NoError(t, err)
+	return pub, priv
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string, modTime time.Time) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+		require.NoError(t, os.Chtimes(p, modTime, modTime))
+	}
+}
+
+func createBundle(t *testing.T, srcDir, dstPath string, priv ed25519.PrivateKey, createdAt time.Time) *Manifest {
+	var buf bytes.Buffer
+	m, err := Create(&buf, srcDir, CreateOptions{PrivateKey: priv, CreatedAt: createdAt})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dstPath, buf.Bytes(), 0o644))
+	return m
+}
+
+func TestKeys(t *testing.T) {
+	pubPEM, privPEM, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	pub, err := ParsePublicKey(pubPEM)
+	require.NoError(t, err)
+	priv, err := ParsePrivateKey(privPEM)
+	require.NoError(t, err)
+	require.True(t, pub.Equal(priv.Public()))
+
+	_, err = ParsePublicKey(privPEM)
+	require.Error(t, err)
+	_, err = ParsePrivateKey(pubPEM)
+	require.Error(t, err)
+	_, err = ParsePublicKey([]byte("not a key"))
+	require.Error(t, err)
+}
+
+func TestCreateAndImport(t *testing.T) {
+	pub, priv := generateKeys(t)
+	srcDir, dstDir, bundleDir := t.TempDir(), t.TempDir(), t.TempDir()
+
+	modTime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	writeFiles(t, srcDir, map[string]string{
+		"cpe.sqlite":                 "cpe",
+		"nvdcve-1.1-2024.json.gz":    "nvd",
+		"msrc/fleet_msrc_x.json":     "msrc",
+		StateFileName:                "ignored",
+		stagingDirPrefix + "1/x.txt": "ignored",
+	}, modTime)
+
+	createdAt := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	bundlePath := filepath.Join(bundleDir, "bundle"+Extension)
+	created := createBundle(t, srcDir, bundlePath, priv, createdAt)
+	require.Equal(t, FormatVersion, created.FormatVersion)
+	require.Equal(t, "20240502000000", created.Version)
+	require.Len(t, created.Files, 3)
+
+	imported, err := Import(bundlePath, dstDir, ImportOptions{PublicKey: pub})
+	require.NoError(t, err)
+	require.Equal(t, created, imported)
+
+	for name, content := range map[string]string{
+		"cpe.sqlite":              "cpe",
+		"nvdcve-1.1-2024.json.gz": "nvd",
+		"msrc/fleet_msrc_x.json":  "msrc",
+	} {
+		p := filepath.Join(dstDir, filepath.FromSlash(name))
+		b, err := os.ReadFile(p)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(b))
+		info, err := os.Stat(p)
+		require.NoError(t, err)
+		assert.True(t, modTime.Equal(info.ModTime()), name)
+	}
+
+	current, err := CurrentManifest(dstDir)
+	require.NoError(t, err)
+	require.Equal(t, created, current)
+
+	// no staging directory left behind
+	entries, err := os.ReadDir(dstDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	// importing the same bundle again is a rollback
+	_, err = Import(bundlePath, dstDir, ImportOptions{PublicKey: pub})
+	require.ErrorIs(t, err, ErrNotNewer)
+	_, err = Import(bundlePath, dstDir, ImportOptions{PublicKey: pub, Force: true})
+	require.NoError(t, err)
+
+	// a newer bundle replaces the files and removes the ones it doesn't contain
+	require.NoError(t, os.Remove(filepath.Join(srcDir, "msrc", "fleet_msrc_x.json")))
+	writeFiles(t, srcDir, map[string]string{"cpe.sqlite": "cpe2"}, modTime.Add(time.Hour))
+	newerPath := filepath.Join(bundleDir, "newer"+Extension)
+	createBundle(t, srcDir, newerPath, priv, createdAt.Add(24*time.Hour))
+	_, err = Import(newerPath, dstDir, ImportOptions{PublicKey: pub})
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dstDir, "cpe.sqlite"))
+	require.NoError(t, err)
+	require.Equal(t, "cpe2", string(b))
+	_, err = os.Stat(filepath.Join(dstDir, "msrc", "fleet_msrc_x.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestImportInvalid(t *testing.T) {
+	pub, priv := generateKeys(t)
+	otherPub, _ := generateKeys(t)
+	srcDir, bundleDir := t.TempDir(), t.TempDir()
+	writeFiles(t, srcDir, map[string]string{"a.json": "a", "b.json": "b"}, time.Now())
+
+	bundlePath := filepath.Join(bundleDir, "bundle"+Extension)
+	createBundle(t, srcDir, bundlePath, priv, time.Now())
+
+	t.Run("wrong key", func(t *testing.T) {
+		dstDir := t.TempDir()
+		_, err := Import(bundlePath, dstDir, ImportOptions{PublicKey: otherPub})
+		require.ErrorContains(t, err, "invalid bundle signature")
+		_, err = os.Stat(filepath.Join(dstDir, "a.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	// rewrite the bundle applying fn to each entry
+	rewrite := func(t *testing.T, fn func(hdr *tar.Header, data []byte) (*tar.Header, []byte)) string {
+		f, err := os.Open(bundlePath)
+		require.NoError(t, err)
+		defer f.Close()
+		gzr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		tr := tar.NewReader(gzr)
+
+		var buf bytes.Buffer
+		gzw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gzw)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			hdr, data = fn(hdr, data)
+			if hdr == nil {
+				continue
+			}
+			hdr.Size = int64(len(data))
+			require.NoError(t, tw.WriteHeader(hdr))
+			_, err = tw.Write(data)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gzw.Close())
+
+		p := filepath.Join(t.TempDir(), "modified"+Extension)
+		require.NoError(t, os.WriteFile(p, buf.Bytes(), 0o644))
+		return p
+	}
+
+	cases := []struct {
+		name    string
+		fn      func(hdr *tar.Header, data []byte) (*tar.Header, []byte)
+		wantErr string
+	}{
+		{
+			name: "tampered file",
+			fn: func(hdr *tar.Header, data []byte) (*tar.Header, []byte) {
+				if hdr.Name == dataDir+"a.json" {
+					return hdr, []byte("x")
+				}
+				return hdr, data
+			},
+			wantErr: "does not match the manifest",
+		},
+		{
+			name: "tampered manifest",
+			fn: func(hdr *tar.Header, data []byte) (*tar.Header, []byte) {
+				if hdr.Name == manifestName {
+					return hdr, bytes.Replace(data, []byte("a.json"), []byte("c.json"), 1)
+				}
+				return hdr, data
+			},
+			wantErr: "invalid bundle signature",
+		},
+		{
+			name: "missing file",
+			fn: func(hdr *tar.Header, data []byte) (*tar.Header, []byte) {
+				if hdr.Name == dataDir+"b.json" {
+					return nil, nil
+				}
+				return hdr, data
+			},
+			wantErr: "missing 1 file(s)",
+		},
+		{
+			name: "extra file",
+			fn: func(hdr *tar.Header, data []byte) (*tar.Header, []byte) {
+				if hdr.Name == dataDir+"b.json" {
+					hdr.Name = dataDir + "../evil.json"
+				}
+				return hdr, data
+			},
+			wantErr: "not in manifest",
+		},
+		{
+			name: "missing signature",
+			fn: func(hdr *tar.Header, data []byte) (*tar.Header, []byte) {
+				if hdr.Name == signatureName {
+					return nil, nil
+				}
+				return hdr, data
+			},
+			wantErr: `expected bundle entry "manifest.sig"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dstDir := t.TempDir()
+			_, err := Import(rewrite(t, c.fn), dstDir, ImportOptions{PublicKey: pub})
+			require.ErrorContains(t, err, c.wantErr)
+
+			// nothing is imported
+			entries, err := os.ReadDir(dstDir)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+		})
+	}
+}
+
+func TestImportLatest(t *testing.T) {
+	pub, priv := generateKeys(t)
+	_, otherPriv := generateKeys(t)
+	srcDir, dstDir, watchDir := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFiles(t, srcDir, map[string]string{"a.json": "a"}, time.Now())
+
+	// empty directory
+	m, err := ImportLatest(watchDir, dstDir, ImportOptions{PublicKey: pub})
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	now := time.Now().Truncate(time.Second)
+	older := filepath.Join(watchDir, "older"+Extension)
+	createBundle(t, srcDir, older, priv, now.Add(-time.Hour))
+	require.NoError(t, os.Chtimes(older, now.Add(-time.Hour), now.Add(-time.Hour)))
+	newer := filepath.Join(watchDir, "newer"+Extension)
+	createBundle(t, srcDir, newer, priv, now)
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "notes.txt"), []byte("ignored"), 0o644))
+
+	m, err = ImportLatest(watchDir, dstDir, ImportOptions{PublicKey: pub})
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.True(t, now.Equal(m.CreatedAt))
+
+	// nothing newer to import
+	m, err = ImportLatest(watchDir, dstDir, ImportOptions{PublicKey: pub})
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	// a newer bundle with an invalid signature is reported and not imported
+	untrusted := filepath.Join(watchDir, "untrusted"+Extension)
+	createBundle(t, srcDir, untrusted, otherPriv, now.Add(time.Hour))
+	m, err = ImportLatest(watchDir, dstDir, ImportOptions{PublicKey: pub})
+	require.ErrorContains(t, err, "invalid bundle signature")
+	require.Nil(t, m)
+
+	current, err := CurrentManifest(dstDir)
+	require.NoError(t, err)
+	require.True(t, now.Equal(current.CreatedAt))
+}
+
+func TestValidPath(t *testing.T) {
+	for p, want := range map[string]bool{
+		"a.json":                 true,
+		"msrc/a.json":            true,
+		"":                       false,
+		".":                      false,
+		"..":                     false,
+		"../a.json":              false,
+		"/etc/passwd":            false,
+		"a/../../b":              false,
+		"a//b":                   false,
+		`a\b`:                    false,
+		StateFileName:            false,
+		stagingDirPrefix + "1/a": false,
+	} {
+		assert.Equal(t, want, validPath(p), p)
+	}
+}
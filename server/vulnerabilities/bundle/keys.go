@@ -0,0 +1,91 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	privateKeyPEMType = "PRIVATE KEY"
+	publicKeyPEMType  = "PUBLIC KEY"
+)
+
+// GenerateKeyPair generates a new Ed25519 key pair used to sign and verify
+// bundles, returned PEM-encoded (PKCS #8 for the private key, PKIX for the
+// public key).
+func GenerateKeyPair() (publicKeyPEM []byte, privateKeyPEM []byte, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal public key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: pubDER}),
+		pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: privDER}),
+		nil
+}
+
+// ParsePrivateKey parses a PEM-encoded Ed25519 private key.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != privateKeyPEMType {
+		return nil, errors.New("no PEM private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T, expected Ed25519", key)
+	}
+	return edKey, nil
+}
+
+// ParsePublicKey parses a PEM-encoded Ed25519 public key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != publicKeyPEMType {
+		return nil, errors.New("no PEM public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, expected Ed25519", key)
+	}
+	return edKey, nil
+}
+
+// LoadPrivateKey reads and parses the PEM-encoded Ed25519 private key at path.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	return ParsePrivateKey(data)
+}
+
+// LoadPublicKey reads and parses the PEM-encoded Ed25519 public key at path.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	return ParsePublicKey(data)
+}
@@ -122,6 +122,25 @@ func (r CVEMatchingRules) ValidateAll() error {
 	return nil
 }
 
+// getStoredCVEMatchingRules returns the custom rules managed by admins via the API or GitOps.
+func getStoredCVEMatchingRules(ctx context.Context, ds mdmlab.Datastore) (CVEMatchingRules, error) {
+	stored, err := ds.ListCustomCVEMatchingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make(CVEMatchingRules, 0, len(stored))
+	for _, r := range stored {
+		rules = append(rules, CVEMatchingRule{
+			NameLikeMatch:     r.NameLikeMatch,
+			SourceMatch:       r.SourceMatch,
+			CVEs:              r.CVEs,
+			ResolvedInVersion: r.ResolvedInVersion,
+		})
+	}
+	return rules, nil
+}
+
 // CheckCustomVulnerabilities matches software against custom rules and inserts vulnerabilities
 func CheckCustomVulnerabilities(ctx context.Context, ds mdmlab.Datastore, logger log.Logger, periodicity time.Duration) ([]mdmlab.SoftwareVulnerability, error) {
 	rules := getCVEMatchingRules()
@@ -129,6 +148,20 @@ func CheckCustomVulnerabilities(ctx context.Context, ds mdmlab.Datastore, logger
 		return nil, fmt.Errorf("invalid rules: %w", err)
 	}
 
+	stored, err := getStoredCVEMatchingRules(ctx, ds)
+	if err != nil {
+		return nil, fmt.Errorf("listing stored rules: %w", err)
+	}
+	for i, rule := range stored {
+		// Stored rules are validated when created, an invalid rule should not prevent the
+		// remaining ones from being applied.
+		if err := rule.validate(); err != nil {
+			level.Error(logger).Log("msg", "Invalid stored rule", "ruleIndex", i, "err", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
 	var vulns []mdmlab.SoftwareVulnerability
 	for i, rule := range rules {
 		v, err := rule.match(ctx, ds)
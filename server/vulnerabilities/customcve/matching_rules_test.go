@@ -224,6 +224,10 @@ func TestCheckCustomVulnerabilities(t *testing.T) {
 		},
 	}
 
+	ds.ListCustomCVEMatchingRulesFunc = func(ctx context.Context) ([]*mdmlab.CustomCVEMatchingRule, error) {
+		return nil, nil
+	}
+
 	t.Run("New Vulns return all inserted", func(t *testing.T) {
 		ds.ListSoftwareForVulnDetectionFunc = func(ctx context.Context, filter mdmlab.VulnSoftwareFilter) ([]mdmlab.Software, error) {
 			return sw, nil
@@ -448,4 +452,38 @@ func TestCheckCustomVulnerabilities(t *testing.T) {
 		require.Equal(t, 31, insertCount)
 		require.Len(t, vulns, 0)
 	})
+
+	t.Run("Stored rules are applied", func(t *testing.T) {
+		ds.ListCustomCVEMatchingRulesFunc = func(ctx context.Context) ([]*mdmlab.CustomCVEMatchingRule, error) {
+			return []*mdmlab.CustomCVEMatchingRule{
+				{ID: 1, NameLikeMatch: "Acme Agent", SourceMatch: "deb_packages", CVEs: []string{"CVE-2024-0001"}, ResolvedInVersion: "2.0.1"},
+				// invalid rules are skipped
+				{ID: 2, NameLikeMatch: "Acme Tools", ResolvedInVersion: "1.0"},
+			}, nil
+		}
+		ds.ListSoftwareForVulnDetectionFunc = func(ctx context.Context, filter mdmlab.VulnSoftwareFilter) ([]mdmlab.Software, error) {
+			if filter.Name != "Acme Agent" {
+				return nil, nil
+			}
+			require.Equal(t, "deb_packages", filter.Source)
+			return []mdmlab.Software{
+				{ID: 10, Name: "acme-agent", Version: "2.0.0", Source: "deb_packages"},
+				{ID: 11, Name: "acme-agent", Version: "2.0.1", Source: "deb_packages"},
+			}, nil
+		}
+
+		var inserted []mdmlab.SoftwareVulnerability
+		ds.InsertSoftwareVulnerabilityFunc = func(ctx context.Context, vuln mdmlab.SoftwareVulnerability, source mdmlab.VulnerabilitySource) (bool, error) {
+			require.Equal(t, mdmlab.CustomSource, source)
+			inserted = append(inserted, vuln)
+			return true, nil
+		}
+
+		vulns, err := CheckCustomVulnerabilities(context.Background(), ds, log.NewNopLogger(), 1*time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, []mdmlab.SoftwareVulnerability{
+			{SoftwareID: 10, CVE: "CVE-2024-0001", ResolvedInVersion: ptr.String("2.0.1")},
+		}, vulns)
+		require.Equal(t, inserted, vulns)
+	})
 }
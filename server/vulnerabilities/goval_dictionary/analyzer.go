@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/oval"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/utils"
@@ -34,6 +36,12 @@ func Analyze(
 	if !platform.IsGovalDictionarySupported() {
 		return nil, ErrUnsupportedPlatform
 	}
+	if platform.IsGovalDictionaryOptional() {
+		// Only analyze the platform if its database was found during the sync.
+		if _, err := os.Stat(filepath.Join(vulnPath, platform.ToGovalDictionaryFilename())); err != nil {
+			return nil, ErrUnsupportedPlatform
+		}
+	}
 	db, err := loadDb(platform, vulnPath)
 	if err != nil {
 		return nil, err
@@ -21,12 +21,13 @@ type Database struct {
 }
 
 // Eval evaluates the current goval_dictionary database against an OS version and a list of installed software,
-// returns all software vulnerabilities found. Logs on any errors so we return as many vulnerabilities as we can.
+// returns all software vulnerabilities found. Packages with no architecture (e.g. on SUSE definitions) match
+// any installed architecture. Logs on any errors so we return as many vulnerabilities as we can.
 func (db Database) Eval(software []mdmlab.Software, logger kitlog.Logger) []mdmlab.SoftwareVulnerability {
 	searchStmt := `SELECT packages.version, cves.cve_id 
 		FROM packages join definitions on definitions.id = packages.definition_id
 		JOIN advisories ON advisories.definition_id = definitions.id JOIN cves ON cves.advisory_id = advisories.id
-		WHERE packages.name = ? AND packages.arch = ? ORDER BY cve_id, version`
+		WHERE packages.name = ? AND (packages.arch = ? OR packages.arch = '') ORDER BY cve_id, version`
 	vulnerabilities := make([]mdmlab.SoftwareVulnerability, 0)
 
 	for _, swItem := range software {
@@ -55,7 +56,11 @@ func (db Database) Eval(software []mdmlab.Software, logger kitlog.Logger) []mdml
 				} else {
 					currentVersion = swItem.Version
 				}
-				fixedVersion := strings.Split(fixedVersionWithEpochPrefix, ":")[1]
+				// Not all platforms include the epoch (e.g. SUSE)
+				fixedVersion := fixedVersionWithEpochPrefix
+				if _, v, found := strings.Cut(fixedVersionWithEpochPrefix, ":"); found {
+					fixedVersion = v
+				}
 
 				if utils.Rpmvercmp(currentVersion, fixedVersion) < 0 {
 					vulnerabilities = append(vulnerabilities, mdmlab.SoftwareVulnerability{
@@ -91,3 +91,40 @@ func TestDatabase(t *testing.T) {
 		require.Equal(t, uint(235), vulns[3].SoftwareID)
 	})
 }
+
+func TestDatabaseSUSE(t *testing.T) {
+	// SUSE definitions include no architecture nor epoch
+	sqlite, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbSetupQueries := []string{
+		"CREATE TABLE packages (name TEXT NOT NULL, arch TEXT NOT NULL, version TEXT NOT NULL, definition_id INTEGER NOT NULL)",
+		"CREATE TABLE definitions (id INTEGER NOT NULL PRIMARY KEY)",
+		"CREATE TABLE advisories (id INTEGER NOT NULL PRIMARY KEY, definition_id INTEGER NOT NULL)",
+		"CREATE TABLE cves (cve_id TEXT NOT NULL, advisory_id INTEGER NOT NULL)",
+		"INSERT INTO packages (name, arch, version, definition_id) VALUES ('libopenssl3', '', '3.0.8-150500.5.20.1', 1)",
+		"INSERT INTO definitions (id) VALUES (1)",
+		"INSERT INTO advisories (id, definition_id) VALUES (1, 1)",
+		"INSERT INTO cves (cve_id, advisory_id) VALUES ('CVE-2023-5678', 1)",
+	}
+	for _, query := range dbSetupQueries {
+		if _, err := sqlite.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db := NewDB(sqlite, oval.NewPlatform("sles", "SUSE Linux Enterprise Server 15.5.0"))
+	logger := kitlog.NewNopLogger()
+
+	t.Run("Fixed version", func(t *testing.T) {
+		require.Len(t, db.Eval([]mdmlab.Software{{Name: "libopenssl3", Version: "3.0.8", Release: "150500.5.20.1", Arch: "x86_64"}}, logger), 0)
+	})
+
+	t.Run("Older than fixed version", func(t *testing.T) {
+		vulns := db.Eval([]mdmlab.Software{{Name: "libopenssl3", Version: "3.0.8", Release: "150500.5.14.1", Arch: "x86_64", ID: 42}}, logger)
+		require.Len(t, vulns, 1)
+		require.Equal(t, "CVE-2023-5678", vulns[0].CVE)
+		require.Equal(t, uint(42), vulns[0].SoftwareID)
+		require.Equal(t, "3.0.8-150500.5.20.1", *vulns[0].ResolvedInVersion)
+	})
+}
@@ -1,6 +1,7 @@
 package goval_dictionary
 
 import (
+	"errors"
 	"fmt"
 	"github.com/it-laborato/MDM_Lab/pkg/download"
 	"github.com/it-laborato/MDM_Lab/pkg/mdmlabhttp"
@@ -22,29 +23,43 @@ func Refresh(
 	toDownload := whatToDownload(versions)
 	if len(toDownload) > 0 {
 		level.Debug(logger).Log("msg", "goval_dictionary-sync-downloading")
-		err := Sync(vulnPath, toDownload)
-		if err != nil {
-			return nil, err
-		}
+		return Sync(vulnPath, toDownload)
 	}
 
-	return toDownload, nil
+	return nil, nil
 }
 
-func Sync(dstDir string, platforms []oval.Platform) error {
+// Sync downloads the goval-dictionary databases of the given platforms into dstDir and returns
+// the platforms that were downloaded. The databases of optional platforms (see
+// oval.Platform.IsGovalDictionaryOptional) are skipped if they are not published.
+func Sync(dstDir string, platforms []oval.Platform) ([]oval.Platform, error) {
 	client := mdmlabhttp.NewClient()
 	dwn := downloadDecompressed(client)
 	basePath, err := nvd.GetGitHubCVEAssetPath()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	return syncPlatforms(platforms, dwn, basePath, dstDir)
+}
+
+func syncPlatforms(
+	platforms []oval.Platform,
+	downloader func(string, string) error,
+	basePath string,
+	dstDir string,
+) ([]oval.Platform, error) {
+	downloaded := make([]oval.Platform, 0, len(platforms))
 	for _, platform := range platforms {
-		if err := downloadDatabase(platform, dwn, basePath, dstDir); err != nil {
-			return err
+		if err := downloadDatabase(platform, downloader, basePath, dstDir); err != nil {
+			if platform.IsGovalDictionaryOptional() && errors.Is(err, download.NotFound) {
+				continue
+			}
+			return nil, err
 		}
+		downloaded = append(downloaded, platform)
 	}
-	return nil
+	return downloaded, nil
 }
 
 func downloadDatabase(
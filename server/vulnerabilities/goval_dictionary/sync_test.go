@@ -1,6 +1,9 @@
 package goval_dictionary
 
 import (
+	"errors"
+	"fmt"
+	"github.com/it-laborato/MDM_Lab/pkg/download"
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/oval"
 	"github.com/stretchr/testify/require"
@@ -23,12 +26,60 @@ func TestSync(t *testing.T) {
 					Platform:   "amzn",
 					Name:       "Amazon Linux 2.0.0",
 				},
+				{
+					HostsCount: 1,
+					Platform:   "sles",
+					Name:       "SUSE Linux Enterprise Server 15.5.0",
+				},
+				{
+					HostsCount: 1,
+					Platform:   "opensuse-leap",
+					Name:       "openSUSE Leap 15.5.0",
+				},
+				{
+					HostsCount: 1,
+					Platform:   "rocky",
+					Name:       "Rocky Linux 9.3.0",
+				},
 			},
 		}
 
 		result := whatToDownload(&osVersions)
-		require.Len(t, result, 1)
+		require.Len(t, result, 3)
 		require.Contains(t, result, oval.NewPlatform("amzn", "Amazon Linux 2.0.0"))
+		require.Contains(t, result, oval.Platform("sles_15"))
+		require.Contains(t, result, oval.Platform("opensuse-leap_1505"))
 		require.NotContains(t, result, oval.NewPlatform("ubuntu", "Ubuntu 20.4.0"))
 	})
+	t.Run("#syncPlatforms", func(t *testing.T) {
+		published := map[string]bool{
+			"https://example.com/amzn_02.sqlite3.xz": true,
+			"https://example.com/sles_15.sqlite3.xz": true,
+		}
+		var requested []string
+		downloader := func(u, dstPath string) error {
+			requested = append(requested, u)
+			if !published[u] {
+				return fmt.Errorf("download and extract url %s: %w", u, download.NotFound)
+			}
+			return nil
+		}
+
+		downloaded, err := syncPlatforms(
+			[]oval.Platform{"amzn_02", "sles_15", "opensuse-leap_1505"},
+			downloader, "https://example.com/", t.TempDir(),
+		)
+		require.NoError(t, err)
+		require.Len(t, requested, 3)
+		require.Equal(t, []oval.Platform{"amzn_02", "sles_15"}, downloaded)
+
+		// the database of a non optional platform is required
+		_, err = syncPlatforms([]oval.Platform{"amzn_2023"}, downloader, "https://example.com/", t.TempDir())
+		require.ErrorIs(t, err, download.NotFound)
+
+		_, err = syncPlatforms([]oval.Platform{"sles_12"}, func(string, string) error {
+			return errors.New("network error")
+		}, "https://example.com/", t.TempDir())
+		require.Error(t, err)
+	})
 }
@@ -9,6 +9,7 @@ import (
 type FSAPI interface {
 	MSRCBulletins() ([]MetadataFileName, error)
 	MacOfficeReleaseNotes() ([]MetadataFileName, error)
+	OSVDumps() ([]MetadataFileName, error)
 	Delete(MetadataFileName) error
 }
 
@@ -38,6 +39,11 @@ func (fs FSClient) MacOfficeReleaseNotes() ([]MetadataFileName, error) {
 	return fs.list(macOfficeReleaseNotesPrefix, NewMacOfficeRelNotesMetadata)
 }
 
+// OSVDumps walks 'dir' returning all OSV ecosystem dumps.
+func (fs FSClient) OSVDumps() ([]MetadataFileName, error) {
+	return fs.list(osvFilePrefix, NewOSVMetadata)
+}
+
 func (fs FSClient) list(
 	prefix string,
 	ctor func(filePath string) (MetadataFileName, error),
@@ -77,6 +77,33 @@ func TestFSClient(t *testing.T) {
 		})
 	})
 
+	t.Run("#OSVDumps", func(t *testing.T) {
+		t.Run("returns a list of file matching the OSV file prefix", func(t *testing.T) {
+			path := t.TempDir()
+			sut := NewFSClient(path)
+
+			file1 := filepath.Join(path, fmt.Sprintf("%sWindows_10-2022_10_10.json", mSRCFilePrefix))
+			dump1 := filepath.Join(path, fmt.Sprintf("%snpm-2025_01_30.json", osvFilePrefix))
+			dump2 := filepath.Join(path, fmt.Sprintf("%sPyPI-2025_01_30.json", osvFilePrefix))
+
+			for _, p := range []string{file1, dump1, dump2} {
+				f, err := os.Create(p)
+				require.NoError(t, err)
+				f.Close()
+			}
+
+			r, err := sut.OSVDumps()
+			require.NoError(t, err)
+
+			a, err := NewOSVMetadata(filepath.Base(dump1))
+			require.NoError(t, err)
+			b, err := NewOSVMetadata(filepath.Base(dump2))
+			require.NoError(t, err)
+
+			require.ElementsMatch(t, []MetadataFileName{a, b}, r)
+		})
+	})
+
 	t.Run("#MSRCBulletins", func(t *testing.T) {
 		t.Run("directory does not exists", func(t *testing.T) {
 			sut := NewFSClient("asdf")
@@ -30,6 +30,7 @@ type GitHubAPI interface {
 	Download(string) (string, error)
 	MSRCBulletins(context.Context) (map[MetadataFileName]string, error)
 	MacOfficeReleaseNotes(context.Context) (MetadataFileName, string, error)
+	OSVDumps(context.Context) (map[MetadataFileName]string, error)
 }
 
 type GitHubClient struct {
@@ -91,6 +92,12 @@ func (gh GitHubClient) MacOfficeReleaseNotes(ctx context.Context) (MetadataFileN
 	return MetadataFileName{}, "", nil
 }
 
+// OSVDumps returns a map of 'MetadataFilename' to 'download URL' of the OSV ecosystem dumps stored
+// in our Github NVD repo (https://github.com/mdmlabdm/nvd/releases), there is one asset per ecosystem.
+func (gh GitHubClient) OSVDumps(ctx context.Context) (map[MetadataFileName]string, error) {
+	return gh.list(ctx, osvFilePrefix, NewOSVMetadata)
+}
+
 // list iterates over the latest release in our Github NVD repo
 // (https://github.com/mdmlabdm/nvd/releases) and collects all assets that start with 'prefix',
 // matching assets are collected in a map, where the key is a 'MetadataFileName' built using 'ctor'
@@ -10,6 +10,7 @@ import (
 const (
 	mSRCFilePrefix              = "mdmlab_msrc_"
 	macOfficeReleaseNotesPrefix = "mdmlab_macoffice_release_notes_"
+	osvFilePrefix               = "mdmlab_osv_"
 	fileExt                     = "json"
 	dateLayout                  = "2006_01_02"
 )
@@ -40,6 +41,15 @@ func NewMacOfficeRelNotesMetadata(filename string) (MetadataFileName, error) {
 	return mfn, err
 }
 
+func NewOSVMetadata(filename string) (MetadataFileName, error) {
+	mfn := MetadataFileName{prefix: osvFilePrefix, filename: filename}
+
+	// Check that the filename contains a valid timestamp
+	_, err := mfn.date()
+
+	return mfn, err
+}
+
 func (mfn MetadataFileName) date() (time.Time, error) {
 	parts := strings.Split(mfn.filename, "-")
 
@@ -93,3 +103,9 @@ func MSRCFileName(productName string, date time.Time) string {
 func MacOfficeRelNotesFileName(date time.Time) string {
 	return fmt.Sprintf("%s%s-%d_%02d_%02d.%s", macOfficeReleaseNotesPrefix, "macoffice", date.Year(), date.Month(), date.Day(), fileExt)
 }
+
+// OSVFileName returns the name of the asset containing all the OSV advisories for 'ecosystem'
+// (e.g. 'npm', 'PyPI').
+func OSVFileName(ecosystem string, date time.Time) string {
+	return fmt.Sprintf("%s%s-%d_%02d_%02d.%s", osvFilePrefix, ecosystem, date.Year(), date.Month(), date.Day(), fileExt)
+}
@@ -27,6 +27,16 @@ func TestSecurityBulletinName(t *testing.T) {
 		require.Contains(t, result, strconv.Itoa(now.Day()))
 	})
 
+	t.Run("OSVFileName", func(t *testing.T) {
+		date := time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)
+		result := OSVFileName("PyPI", date)
+		require.Equal(t, "mdmlab_osv_PyPI-2025_01_30.json", result)
+
+		sut, err := NewOSVMetadata(result)
+		require.NoError(t, err)
+		require.Equal(t, "PyPI", sut.ProductName())
+	})
+
 	t.Run("String", func(t *testing.T) {
 		sut, err := NewMSRCMetadata("Windows_10-2022_09_10.json")
 		require.NoError(t, err)
@@ -39,6 +39,10 @@ func (gh ghMock) MacOfficeReleaseNotes(ctx context.Context) (io.MetadataFileName
 	return io.MetadataFileName{}, "", gh.TestData.RemoteListError
 }
 
+func (gh ghMock) OSVDumps(ctx context.Context) (map[io.MetadataFileName]string, error) {
+	return gh.TestData.RemoteList, gh.TestData.RemoteListError
+}
+
 func (gh ghMock) Download(url string) (string, error) {
 	gh.TestData.RemoteDownloaded = append(gh.TestData.RemoteDownloaded, url)
 	return "", gh.TestData.RemoteDownloadError
@@ -54,6 +58,10 @@ func (fs fsMock) MacOfficeReleaseNotes() ([]io.MetadataFileName, error) {
 	return fs.TestData.LocalList, fs.TestData.LocalListError
 }
 
+func (fs fsMock) OSVDumps() ([]io.MetadataFileName, error) {
+	return fs.TestData.LocalList, fs.TestData.LocalListError
+}
+
 func (fs fsMock) Delete(d io.MetadataFileName) error {
 	fs.TestData.LocalDeleted = append(fs.TestData.LocalDeleted, d)
 	return fs.TestData.LocalDeleteError
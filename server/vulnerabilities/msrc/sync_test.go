@@ -35,6 +35,10 @@ func (gh ghMock) MacOfficeReleaseNotes(ctx context.Context) (io.MetadataFileName
 	return io.MetadataFileName{}, "", nil
 }
 
+func (gh ghMock) OSVDumps(ctx context.Context) (map[io.MetadataFileName]string, error) {
+	return gh.TestData.RemoteList, nil
+}
+
 func (gh ghMock) Download(url string) (string, error) {
 	gh.TestData.RemoteDownloaded = append(gh.TestData.RemoteDownloaded, url)
 	return "", nil
@@ -50,6 +54,10 @@ func (fs fsMock) MacOfficeReleaseNotes() ([]io.MetadataFileName, error) {
 	return fs.TestData.LocalList, nil
 }
 
+func (fs fsMock) OSVDumps() ([]io.MetadataFileName, error) {
+	return fs.TestData.LocalList, nil
+}
+
 func (fs fsMock) Delete(d io.MetadataFileName) error {
 	fs.TestData.LocalDeleted = append(fs.TestData.LocalDeleted, d)
 	return nil
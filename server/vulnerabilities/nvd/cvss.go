@@ -0,0 +1,57 @@
+package nvd
+
+import (
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/nvd/tools/cvefeed/nvd/schema"
+)
+
+// nvdSource is the source identifier of the scores assessed by NVD.
+const nvdSource = "nvd@nist.gov"
+
+// cvssScoresFromImpact returns all the CVSS scores of a CVE found in the feed.
+//
+// Feeds synced by previous versions of MDMlab only contain the primary (NVD) CVSS v2 and v3
+// metrics, those are used if the feed doesn't contain all the CVSS metrics.
+func cvssScoresFromImpact(cve string, impact *schema.NVDCVEFeedJSON10DefImpact) []mdmlab.CVSSScore {
+	if impact == nil {
+		return nil
+	}
+
+	var scores []mdmlab.CVSSScore
+	if len(impact.CVSSMetrics) > 0 {
+		seen := make(map[[2]string]bool, len(impact.CVSSMetrics))
+		for _, m := range impact.CVSSMetrics {
+			// a source can only report one score per CVSS version
+			key := [2]string{m.Version, m.Source}
+			if m.Version == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			scores = append(scores, newCVSSScore(cve, m.Version, m.Source, m.Type == "Primary", m.BaseScore, m.VectorString))
+		}
+		return scores
+	}
+
+	if m := impact.BaseMetricV3; m != nil && m.CVSSV3 != nil {
+		scores = append(scores, newCVSSScore(cve, m.CVSSV3.Version, nvdSource, true, m.CVSSV3.BaseScore, m.CVSSV3.VectorString))
+	}
+	if m := impact.BaseMetricV2; m != nil && m.CVSSV2 != nil {
+		scores = append(scores, newCVSSScore(cve, m.CVSSV2.Version, nvdSource, true, m.CVSSV2.BaseScore, m.CVSSV2.VectorString))
+	}
+	return scores
+}
+
+func newCVSSScore(cve, version, source string, primary bool, baseScore float64, vector string) mdmlab.CVSSScore {
+	score := mdmlab.CVSSScore{
+		CVE:          cve,
+		Version:      version,
+		Source:       source,
+		Primary:      primary,
+		BaseScore:    baseScore,
+		VectorString: vector,
+	}
+	if v, err := mdmlab.ParseCVSSVector(vector); err == nil {
+		score.AttackVector = v.AttackVector()
+	}
+	return score
+}
@@ -0,0 +1,51 @@
+package nvd
+
+import (
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/nvd/tools/cvefeed/nvd/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVSSScoresFromImpact(t *testing.T) {
+	require.Nil(t, cvssScoresFromImpact("CVE-2024-0001", nil))
+
+	// feeds synced by previous versions only have the NVD v2 and v3 metrics
+	legacy := &schema.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV2: &schema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			CVSSV2: &schema.CVSSV20{Version: "2.0", BaseScore: 7.5, VectorString: "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		},
+		BaseMetricV3: &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &schema.CVSSV30{Version: "3.1", BaseScore: 9.8, VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		},
+	}
+	require.Equal(t, []mdmlab.CVSSScore{
+		{
+			CVE: "CVE-2024-0001", Version: "3.1", Source: nvdSource, Primary: true, BaseScore: 9.8,
+			VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", AttackVector: mdmlab.CVSSAttackVectorNetwork,
+		},
+		{
+			CVE: "CVE-2024-0001", Version: "2.0", Source: nvdSource, Primary: true, BaseScore: 7.5,
+			VectorString: "AV:N/AC:L/Au:N/C:P/I:P/A:P", AttackVector: mdmlab.CVSSAttackVectorNetwork,
+		},
+	}, cvssScoresFromImpact("CVE-2024-0001", legacy))
+
+	// all the metrics are used when available
+	impact := *legacy
+	impact.CVSSMetrics = []*schema.NVDCVEFeedJSON10DefImpactCVSSMetric{
+		{Source: nvdSource, Type: "Primary", Version: "3.1", BaseScore: 9.8, VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{Source: "secalert@example.com", Type: "Secondary", Version: "3.1", BaseScore: 5.5, VectorString: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N"},
+		{Source: "secalert@example.com", Type: "Secondary", Version: "3.1", BaseScore: 5.0, VectorString: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N"},
+		{Source: "secalert@example.com", Type: "Secondary", Version: "4.0", BaseScore: 6.8, VectorString: "CVSS:4.0/AV:P/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+		{Source: "secalert@example.com", Type: "Secondary", Version: "4.0", BaseScore: 6.8, VectorString: "invalid"},
+	}
+	scores := cvssScoresFromImpact("CVE-2024-0001", &impact)
+	require.Len(t, scores, 3)
+	require.True(t, scores[0].Primary)
+	require.Equal(t, 5.5, scores[1].BaseScore)
+	require.Equal(t, mdmlab.CVSSAttackVectorLocal, scores[1].AttackVector)
+	require.False(t, scores[2].Primary)
+	require.Equal(t, "4.0", scores[2].Version)
+	require.Equal(t, mdmlab.CVSSAttackVectorPhysical, scores[2].AttackVector)
+}
@@ -198,6 +198,12 @@ func LoadCVEMeta(ctx context.Context, logger log.Logger, vulnPath string, ds mdm
 		return fmt.Errorf("get nvd cve feeds: %w", err)
 	}
 
+	appConfig, err := ds.AppConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("get app config: %w", err)
+	}
+	cvssPreference := appConfig.VulnerabilitySettings.PreferredCVSSScore
+
 	metaMap := make(map[string]mdmlab.CVEMeta)
 
 	for _, file := range files {
@@ -225,6 +231,8 @@ func LoadCVEMeta(ctx context.Context, logger log.Logger, vulnPath string, ds mdm
 			if schema.Impact.BaseMetricV3 != nil {
 				meta.CVSSScore = &schema.Impact.BaseMetricV3.CVSSV3.BaseScore
 			}
+			meta.CVSSScores = cvssScoresFromImpact(cve, schema.Impact)
+			meta.ApplyCVSSScorePreference(cvssPreference)
 
 			if published, err := time.Parse(publishedDateFmt, schema.PublishedDate); err != nil {
 				level.Error(logger).Log("msg", "failed to parse published data", "cve", cve, "published_date", schema.PublishedDate, "err", err)
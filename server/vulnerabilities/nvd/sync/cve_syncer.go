@@ -5,6 +5,7 @@ package nvdsync
 
 import (
 	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -45,6 +46,9 @@ type CVE struct {
 	debug            bool
 	WaitTimeForRetry time.Duration
 	MaxTryAttempts   int
+
+	// cvssV40Metrics holds the CVSS v4.0 metrics (by CVE ID) received during a sync.
+	cvssV40Metrics map[string][]*schema.NVDCVEFeedJSON10DefImpactCVSSMetric
 }
 
 var (
@@ -203,6 +207,9 @@ func (s *CVE) updateYearFile(year int, cves []nvdapi.CVEItem) error {
 			continue
 		}
 		legacyCVE := convertAPI20CVEToLegacy(cve.CVE, s.logger)
+		if v40Metrics, ok := s.cvssV40Metrics[legacyCVE.CVE.CVEDataMeta.ID]; ok {
+			legacyCVE.Impact.CVSSMetrics = append(legacyCVE.Impact.CVSSMetrics, v40Metrics...)
+		}
 		newLegacyCVEs[legacyCVE.CVE.CVEDataMeta.ID] = legacyCVE
 	}
 
@@ -321,6 +328,8 @@ type httpClient struct {
 	ctx context.Context
 
 	debug bool
+	// onResponseBody, if set, is called with the body of every successful response.
+	onResponseBody func(body []byte)
 }
 
 // Do implements common.HTTPClient.
@@ -339,6 +348,16 @@ func (c *httpClient) Do(request *http.Request) (*http.Response, error) {
 		fmt.Fprintf(os.Stderr, "%s (%s) response: %+v\n", time.Now(), time.Since(start), response)
 	}
 
+	if c.onResponseBody != nil && response.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.onResponseBody(body)
+		response.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
 	return response, err
 }
 
@@ -348,7 +367,23 @@ func (s *CVE) getHTTPClient(ctx context.Context, debug bool) common.HTTPClient {
 		Client: s.client,
 		ctx:    ctx,
 
-		debug: debug,
+		debug:          debug,
+		onResponseBody: s.storeCVSSV40Metrics,
+	}
+}
+
+// storeCVSSV40Metrics stores the CVSS v4.0 metrics found in a NVD API 2.0 response.
+func (s *CVE) storeCVSSV40Metrics(body []byte) {
+	metrics, err := parseAPI20CVSSV40Metrics(body)
+	if err != nil {
+		level.Debug(s.logger).Log("msg", "failed to parse CVSS v4.0 metrics", "err", err)
+		return
+	}
+	if s.cvssV40Metrics == nil {
+		s.cvssV40Metrics = make(map[string][]*schema.NVDCVEFeedJSON10DefImpactCVSSMetric)
+	}
+	for cve, m := range metrics {
+		s.cvssV40Metrics[cve] = m
 	}
 }
 
@@ -373,6 +408,8 @@ func (s *CVE) sync(ctx context.Context, lastModStartDate *string) (newLastModSta
 	if lastModStartDate != nil {
 		lastModEndDate = ptr.String(now)
 	}
+	s.cvssV40Metrics = make(map[string][]*schema.NVDCVEFeedJSON10DefImpactCVSSMetric)
+	defer func() { s.cvssV40Metrics = nil }()
 
 	// Environment variable NETWORK_TEST_NVD_CVE_START_IDX is set only in tests
 	// (to reduce test duration time).
@@ -451,6 +488,9 @@ func (s *CVE) sync(ctx context.Context, lastModStartDate *string) (newLastModSta
 			level.Debug(s.logger).Log("msg", "updated file", "year", yearWithMostVulns, "duration", updateDuration, "vulns", maxVulnsInYear)
 
 			vulnerabilitiesReceived -= maxVulnsInYear
+			for _, vuln := range cvesByYear[yearWithMostVulns] {
+				delete(s.cvssV40Metrics, *vuln.CVE.ID)
+			}
 			delete(cvesByYear, yearWithMostVulns)
 		}
 
@@ -1053,6 +1093,7 @@ func convertAPI20CVEToLegacy(cve nvdapi.CVE, logger log.Logger) *schema.NVDCVEFe
 		Impact: &schema.NVDCVEFeedJSON10DefImpact{
 			BaseMetricV2: baseMetricV2,
 			BaseMetricV3: baseMetricV3,
+			CVSSMetrics:  convertAPI20CVSSMetrics(cve.Metrics),
 		},
 		LastModifiedDate: lastModified,
 		PublishedDate:    publishedDate,
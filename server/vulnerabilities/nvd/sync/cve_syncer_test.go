@@ -93,6 +93,10 @@ func compareVulnerabilities(v1 schema.NVDCVEFeedJSON10DefCVEItem, v2 schema.NVDC
 		// These fields mostly match, but sometimes differ.
 		v.CVE.CVEDataMeta.ASSIGNER = ""
 		v.CVE.Problemtype = nil
+		// The legacy feeds don't include all the CVSS metrics.
+		if v.Impact != nil {
+			v.Impact.CVSSMetrics = nil
+		}
 	}
 
 	clearDifferingFields(&v1)
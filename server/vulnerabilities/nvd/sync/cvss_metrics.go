@@ -0,0 +1,97 @@
+package nvdsync
+
+import (
+	"encoding/json"
+
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/nvd/tools/cvefeed/nvd/schema"
+	"github.com/pandatix/nvdapi/v2"
+)
+
+// api20CVSSV40Response holds the CVSS v4.0 metrics of a NVD API 2.0 CVE response.
+//
+// The github.com/pandatix/nvdapi package doesn't yet support CVSS v4.0 metrics, thus
+// we parse them separately from the raw response body.
+type api20CVSSV40Response struct {
+	Vulnerabilities []struct {
+		CVE struct {
+			ID      string `json:"id"`
+			Metrics struct {
+				CVSSMetricV40 []struct {
+					Source   string `json:"source"`
+					Type     string `json:"type"`
+					CVSSData struct {
+						Version      string  `json:"version"`
+						VectorString string  `json:"vectorString"`
+						BaseScore    float64 `json:"baseScore"`
+						BaseSeverity string  `json:"baseSeverity"`
+					} `json:"cvssData"`
+				} `json:"cvssMetricV40"`
+			} `json:"metrics"`
+		} `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// parseAPI20CVSSV40Metrics returns the CVSS v4.0 metrics found in the given NVD API 2.0
+// response body, by CVE ID.
+func parseAPI20CVSSV40Metrics(body []byte) (map[string][]*schema.NVDCVEFeedJSON10DefImpactCVSSMetric, error) {
+	var response api20CVSSV40Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string][]*schema.NVDCVEFeedJSON10DefImpactCVSSMetric)
+	for _, vuln := range response.Vulnerabilities {
+		for _, m := range vuln.CVE.Metrics.CVSSMetricV40 {
+			metrics[vuln.CVE.ID] = append(metrics[vuln.CVE.ID], &schema.NVDCVEFeedJSON10DefImpactCVSSMetric{
+				Source:       m.Source,
+				Type:         m.Type,
+				Version:      m.CVSSData.Version,
+				VectorString: m.CVSSData.VectorString,
+				BaseScore:    m.CVSSData.BaseScore,
+				BaseSeverity: m.CVSSData.BaseSeverity,
+			})
+		}
+	}
+	return metrics, nil
+}
+
+// convertAPI20CVSSMetrics returns all the CVSS v2, v3.0 and v3.1 metrics of a CVE (primary and
+// secondary), so that scores reported by CNAs are kept along with the NVD scores.
+func convertAPI20CVSSMetrics(metrics *nvdapi.Metrics) []*schema.NVDCVEFeedJSON10DefImpactCVSSMetric {
+	if metrics == nil {
+		return nil
+	}
+
+	var result []*schema.NVDCVEFeedJSON10DefImpactCVSSMetric
+	for _, m := range metrics.CVSSMetricV2 {
+		result = append(result, &schema.NVDCVEFeedJSON10DefImpactCVSSMetric{
+			Source:       m.Source,
+			Type:         string(m.Type),
+			Version:      m.CVSSData.Version,
+			VectorString: m.CVSSData.VectorString,
+			BaseScore:    m.CVSSData.BaseScore,
+			BaseSeverity: derefPtr(m.BaseSeverity),
+		})
+	}
+	for _, m := range metrics.CVSSMetricV30 {
+		result = append(result, &schema.NVDCVEFeedJSON10DefImpactCVSSMetric{
+			Source:       m.Source,
+			Type:         string(m.Type),
+			Version:      m.CVSSData.Version,
+			VectorString: m.CVSSData.VectorString,
+			BaseScore:    m.CVSSData.BaseScore,
+			BaseSeverity: m.CVSSData.BaseSeverity,
+		})
+	}
+	for _, m := range metrics.CVSSMetricV31 {
+		result = append(result, &schema.NVDCVEFeedJSON10DefImpactCVSSMetric{
+			Source:       m.Source,
+			Type:         string(m.Type),
+			Version:      m.CVSSData.Version,
+			VectorString: m.CVSSData.VectorString,
+			BaseScore:    m.CVSSData.BaseScore,
+			BaseSeverity: m.CVSSData.BaseSeverity,
+		})
+	}
+	return result
+}
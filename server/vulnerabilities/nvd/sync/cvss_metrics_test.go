@@ -0,0 +1,50 @@
+package nvdsync
+
+import (
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/nvd/tools/cvefeed/nvd/schema"
+	"github.com/pandatix/nvdapi/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPI20CVSSV40Metrics(t *testing.T) {
+	body := []byte(`{"resultsPerPage":2,"startIndex":0,"totalResults":2,"vulnerabilities":[
+{"cve":{"id":"CVE-2024-0001","metrics":{
+	"cvssMetricV40":[{"source":"secalert@example.com","type":"Secondary","cvssData":{"version":"4.0","vectorString":"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N","baseScore":9.3,"baseSeverity":"CRITICAL"}}],
+	"cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"}}]}}},
+{"cve":{"id":"CVE-2024-0002","metrics":{}}}
+]}`)
+
+	metrics, err := parseAPI20CVSSV40Metrics(body)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]*schema.NVDCVEFeedJSON10DefImpactCVSSMetric{
+		"CVE-2024-0001": {{
+			Source:       "secalert@example.com",
+			Type:         "Secondary",
+			Version:      "4.0",
+			VectorString: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+			BaseScore:    9.3,
+			BaseSeverity: "CRITICAL",
+		}},
+	}, metrics)
+
+	_, err = parseAPI20CVSSV40Metrics([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestConvertAPI20CVSSMetrics(t *testing.T) {
+	require.Nil(t, convertAPI20CVSSMetrics(nil))
+
+	metrics := &nvdapi.Metrics{
+		CVSSMetricV31: []nvdapi.CVSSMetricV31{
+			{Source: "nvd@nist.gov", Type: "Primary", CVSSData: nvdapi.CVSSV31{Version: "3.1", VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", BaseScore: 9.8, BaseSeverity: "CRITICAL"}},
+			{Source: "secalert@example.com", Type: "Secondary", CVSSData: nvdapi.CVSSV31{Version: "3.1", VectorString: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", BaseScore: 5.5, BaseSeverity: "MEDIUM"}},
+		},
+	}
+	converted := convertAPI20CVSSMetrics(metrics)
+	require.Len(t, converted, 2)
+	require.Equal(t, "Primary", converted[0].Type)
+	require.Equal(t, "secalert@example.com", converted[1].Source)
+	require.Equal(t, 5.5, converted[1].BaseScore)
+}
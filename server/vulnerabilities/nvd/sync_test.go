@@ -40,6 +40,9 @@ func TestDownloadCISAKnownExploitsFeed(t *testing.T) {
 
 func TestLoadCVEMeta(t *testing.T) {
 	ds := new(mock.Store)
+	ds.AppConfigFunc = func(ctx context.Context) (*mdmlab.AppConfig, error) {
+		return &mdmlab.AppConfig{}, nil
+	}
 
 	var cveMeta []mdmlab.CVEMeta
 	ds.InsertCVEMetaFunc = func(ctx context.Context, x []mdmlab.CVEMeta) error {
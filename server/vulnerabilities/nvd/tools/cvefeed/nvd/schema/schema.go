@@ -231,6 +231,24 @@ type NVDCVEFeedJSON10DefImpactBaseMetricV3 struct {
 type NVDCVEFeedJSON10DefImpact struct {
 	BaseMetricV2 *NVDCVEFeedJSON10DefImpactBaseMetricV2 `json:"baseMetricV2,omitempty"`
 	BaseMetricV3 *NVDCVEFeedJSON10DefImpactBaseMetricV3 `json:"baseMetricV3,omitempty"`
+	// CVSSMetrics is not part of the legacy feed format. It holds all the CVSS
+	// metrics reported by the NVD API 2.0 (all versions, including v4.0, and
+	// all sources, including the CNA scores).
+	CVSSMetrics []*NVDCVEFeedJSON10DefImpactCVSSMetric `json:"cvssMetrics,omitempty"`
+}
+
+// NVDCVEFeedJSON10DefImpactCVSSMetric is a CVSS score of a vulnerability along
+// with the source that reported it.
+type NVDCVEFeedJSON10DefImpactCVSSMetric struct {
+	// Source is the identifier of the organization that reported the score
+	// (e.g. "nvd@nist.gov").
+	Source string `json:"source"`
+	// Type is either "Primary" (NVD) or "Secondary" (CNA).
+	Type         string  `json:"type"`
+	Version      string  `json:"version"`
+	VectorString string  `json:"vectorString"`
+	BaseScore    float64 `json:"baseScore"`
+	BaseSeverity string  `json:"baseSeverity,omitempty"`
 }
 
 // NVDCVEFeedJSON10DefCVEItem was auto-generated.
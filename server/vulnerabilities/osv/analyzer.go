@@ -0,0 +1,199 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/io"
+)
+
+// advisory is a single affected package entry of an OSV vulnerability.
+type advisory struct {
+	identifiers []string
+	affected    Affected
+}
+
+// advisories indexes all advisories of an ecosystem by normalized package name.
+type advisories map[string][]advisory
+
+// getLatestDumps returns the most recent dump (based on the date in the filename) of each ecosystem
+// contained in 'vulnPath'.
+func getLatestDumps(vulnPath string) (map[string]io.MetadataFileName, error) {
+	fs := io.NewFSClient(vulnPath)
+
+	files, err := fs.OSVDumps()
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]io.MetadataFileName)
+	for _, f := range files {
+		if l, ok := latest[f.ProductName()]; !ok || l.Before(f) {
+			latest[f.ProductName()] = f
+		}
+	}
+	return latest, nil
+}
+
+// loadAdvisories parses the OSV dump located at 'path', a JSON array of OSV vulnerabilities, and
+// indexes the advisories affecting packages of 'eco'.
+func loadAdvisories(path string, eco ecosystem) (advisories, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var vulns []Vulnerability
+	if err := json.NewDecoder(f).Decode(&vulns); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", filepath.Base(path), err)
+	}
+
+	result := make(advisories)
+	for _, v := range vulns {
+		if v.Withdrawn != nil {
+			continue
+		}
+		ids := v.Identifiers()
+		for _, a := range v.Affected {
+			if a.Package.Ecosystem != eco.name {
+				continue
+			}
+			name := eco.normalize(a.Package.Name)
+			result[name] = append(result[name], advisory{identifiers: ids, affected: a})
+		}
+	}
+	return result, nil
+}
+
+// collectVulnerabilities compares 'software' against all advisories for its package, returning all
+// detected vulnerabilities.
+func collectVulnerabilities(
+	software *mdmlab.Software,
+	eco ecosystem,
+	advs advisories,
+) []mdmlab.SoftwareVulnerability {
+	var vulns []mdmlab.SoftwareVulnerability
+	for _, adv := range advs[eco.normalize(software.Name)] {
+		ok, fixed := adv.affected.Affects(software.Version, eco.cmp)
+		if !ok {
+			continue
+		}
+
+		var resolvedInVersion *string
+		if fixed != "" {
+			v := fixed
+			resolvedInVersion = &v
+		}
+
+		for _, id := range adv.identifiers {
+			vulns = append(vulns, mdmlab.SoftwareVulnerability{
+				SoftwareID:        software.ID,
+				CVE:               id,
+				ResolvedInVersion: resolvedInVersion,
+			})
+		}
+	}
+	return vulns
+}
+
+// analyzeEcosystem detects vulnerabilities on all software belonging to 'eco', returning the ones
+// that were newly inserted.
+func analyzeEcosystem(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	eco ecosystem,
+	advs advisories,
+) ([]mdmlab.SoftwareVulnerability, error) {
+	queryParams := mdmlab.SoftwareIterQueryOptions{IncludedSources: []string{eco.source}}
+	iter, err := ds.AllSoftwareIterator(ctx, queryParams)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var inserted []mdmlab.SoftwareVulnerability
+	for iter.Next() {
+		software, err := iter.Value()
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "getting software from iterator")
+		}
+
+		// Dedup vulnerabilities reported by more than one advisory (e.g. a GHSA and a PYSEC entry
+		// aliasing the same CVE).
+		detected := make(map[string]mdmlab.SoftwareVulnerability)
+		for _, v := range collectVulnerabilities(software, eco, advs) {
+			detected[v.Key()] = v
+		}
+
+		for _, v := range detected {
+			ok, err := ds.InsertSoftwareVulnerability(ctx, v, mdmlab.OSVSource)
+			if err != nil {
+				return nil, ctxerr.Wrap(ctx, err, "inserting osv vulnerability")
+			}
+			if ok {
+				inserted = append(inserted, v)
+			}
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("iter: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// Analyze uses the most recent OSV dump of each supported ecosystem in 'vulnPath' for detecting
+// vulnerabilities on language packages (npm, PyPI). Vulnerabilities no longer detected are removed
+// once they are older than twice 'periodicity'.
+func Analyze(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	vulnPath string,
+	collectVulns bool,
+	periodicity time.Duration,
+) ([]mdmlab.SoftwareVulnerability, error) {
+	dumps, err := getLatestDumps(vulnPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dumps) == 0 {
+		return nil, nil
+	}
+
+	var vulnerabilities []mdmlab.SoftwareVulnerability
+	for _, eco := range ecosystems {
+		dump, ok := dumps[eco.name]
+		if !ok {
+			continue
+		}
+
+		advs, err := loadAdvisories(filepath.Join(vulnPath, dump.String()), eco)
+		if err != nil {
+			return nil, err
+		}
+
+		inserted, err := analyzeEcosystem(ctx, ds, eco, advs)
+		if err != nil {
+			return nil, err
+		}
+
+		if collectVulns {
+			vulnerabilities = append(vulnerabilities, inserted...)
+		}
+	}
+
+	if err := ds.DeleteOutOfDateVulnerabilities(ctx, mdmlab.OSVSource, 2*periodicity); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "deleting out of date osv vulnerabilities")
+	}
+
+	return vulnerabilities, nil
+}
@@ -0,0 +1,162 @@
+package osv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/io"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSoftwareIterator struct {
+	index     int
+	softwares []*mdmlab.Software
+}
+
+func (f *fakeSoftwareIterator) Next() bool {
+	return f.index < len(f.softwares)
+}
+
+func (f *fakeSoftwareIterator) Value() (*mdmlab.Software, error) {
+	s := f.softwares[f.index]
+	f.index++
+	return s, nil
+}
+
+func (f *fakeSoftwareIterator) Err() error   { return nil }
+func (f *fakeSoftwareIterator) Close() error { return nil }
+
+const npmDump = `[
+  {
+    "id": "GHSA-35jh-r3h4-6jhm",
+    "aliases": ["CVE-2021-23337"],
+    "affected": [{
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-jf85-cpcp-j695",
+    "aliases": [],
+    "affected": [{
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.12"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-withdrawn",
+    "withdrawn": "2024-01-01T00:00:00Z",
+    "aliases": ["CVE-2024-0001"],
+    "affected": [{
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+    }]
+  }
+]`
+
+const pypiDump = `[
+  {
+    "id": "PYSEC-2023-74",
+    "aliases": ["CVE-2023-32681", "GHSA-j8r2-6x86-q33q"],
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "requests"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-j8r2-6x86-q33q",
+    "aliases": ["CVE-2023-32681", "PYSEC-2023-74"],
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "requests"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-9wx4-h78v-vm56",
+    "aliases": ["CVE-2024-35195"],
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "Requests"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.32.0"}]}]
+    }]
+  }
+]`
+
+func TestAnalyzer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Analyze", func(t *testing.T) {
+		t.Run("when using wrong path", func(t *testing.T) {
+			vulns, err := Analyze(ctx, nil, "some bad path", false, time.Hour)
+			require.Empty(t, vulns)
+			require.Error(t, err)
+		})
+
+		t.Run("when no dumps on path", func(t *testing.T) {
+			vulnDir := t.TempDir()
+			vulns, err := Analyze(ctx, nil, vulnDir, false, time.Hour)
+			require.Empty(t, vulns)
+			require.NoError(t, err)
+		})
+
+		t.Run("detects vulnerabilities on language packages", func(t *testing.T) {
+			vulnDir := t.TempDir()
+			now := time.Now()
+			// Out of date dumps should be ignored.
+			require.NoError(t, os.WriteFile(filepath.Join(vulnDir, io.OSVFileName("npm", now.AddDate(0, 0, -1))), []byte(`[{"id": "GHSA-old", "affected": [{"package": {"ecosystem": "npm", "name": "lodash"}, "versions": ["4.17.11"]}]}]`), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(vulnDir, io.OSVFileName("npm", now)), []byte(npmDump), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(vulnDir, io.OSVFileName("PyPI", now)), []byte(pypiDump), 0o644))
+
+			software := map[string][]*mdmlab.Software{
+				"npm_packages": {
+					{ID: 1, Name: "lodash", Version: "4.17.11", Source: "npm_packages"},
+					{ID: 2, Name: "lodash", Version: "4.17.21", Source: "npm_packages"},
+				},
+				"python_packages": {
+					{ID: 3, Name: "requests", Version: "2.28.1", Source: "python_packages"},
+					{ID: 4, Name: "Requests", Version: "2.31.0", Source: "python_packages"},
+					{ID: 5, Name: "urllib3", Version: "1.26.0", Source: "python_packages"},
+				},
+			}
+
+			ds := new(mock.Store)
+			ds.AllSoftwareIteratorFunc = func(ctx context.Context, q mdmlab.SoftwareIterQueryOptions) (mdmlab.SoftwareIterator, error) {
+				require.Len(t, q.IncludedSources, 1)
+				return &fakeSoftwareIterator{softwares: software[q.IncludedSources[0]]}, nil
+			}
+			var inserted []mdmlab.SoftwareVulnerability
+			ds.InsertSoftwareVulnerabilityFunc = func(ctx context.Context, vuln mdmlab.SoftwareVulnerability, source mdmlab.VulnerabilitySource) (bool, error) {
+				require.Equal(t, mdmlab.OSVSource, source)
+				inserted = append(inserted, vuln)
+				return true, nil
+			}
+			ds.DeleteOutOfDateVulnerabilitiesFunc = func(ctx context.Context, source mdmlab.VulnerabilitySource, duration time.Duration) error {
+				require.Equal(t, mdmlab.OSVSource, source)
+				require.Equal(t, 2*time.Hour, duration)
+				return nil
+			}
+
+			vulns, err := Analyze(ctx, ds, vulnDir, true, time.Hour)
+			require.NoError(t, err)
+			require.True(t, ds.DeleteOutOfDateVulnerabilitiesFuncInvoked)
+			require.ElementsMatch(t, inserted, vulns)
+
+			fixed := func(v string) *string { return &v }
+			require.ElementsMatch(t, []mdmlab.SoftwareVulnerability{
+				{SoftwareID: 1, CVE: "CVE-2021-23337", ResolvedInVersion: fixed("4.17.21")},
+				{SoftwareID: 1, CVE: "GHSA-35jh-r3h4-6jhm", ResolvedInVersion: fixed("4.17.21")},
+				{SoftwareID: 1, CVE: "GHSA-jf85-cpcp-j695", ResolvedInVersion: fixed("4.17.12")},
+				{SoftwareID: 3, CVE: "CVE-2023-32681", ResolvedInVersion: fixed("2.31.0")},
+				{SoftwareID: 3, CVE: "GHSA-j8r2-6x86-q33q", ResolvedInVersion: fixed("2.31.0")},
+				{SoftwareID: 3, CVE: "CVE-2024-35195", ResolvedInVersion: fixed("2.32.0")},
+				{SoftwareID: 3, CVE: "GHSA-9wx4-h78v-vm56", ResolvedInVersion: fixed("2.32.0")},
+				{SoftwareID: 4, CVE: "CVE-2024-35195", ResolvedInVersion: fixed("2.32.0")},
+				{SoftwareID: 4, CVE: "GHSA-9wx4-h78v-vm56", ResolvedInVersion: fixed("2.32.0")},
+			}, vulns)
+		})
+	})
+}
@@ -0,0 +1,215 @@
+package osv
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Vulnerability is the subset of the OSV schema (https://ossf.github.io/osv-schema/) used for detecting
+// vulnerable language packages.
+type Vulnerability struct {
+	ID        string     `json:"id"`
+	Aliases   []string   `json:"aliases"`
+	Withdrawn *time.Time `json:"withdrawn"`
+	Affected  []Affected `json:"affected"`
+}
+
+// Affected describes the versions of a single package affected by a vulnerability.
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Range is a list of events describing when a package became vulnerable and when it was fixed.
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event holds a single version in the timeline of a range, only one of its fields is set.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+func (e Event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	default:
+		return e.Limit
+	}
+}
+
+// Identifiers returns the identifiers used for recording the vulnerability against a package, these
+// are the CVEs followed by the GHSA identifiers the vulnerability is known by, so that it can be
+// looked up by either of them. If the vulnerability has neither, its own OSV identifier is used.
+func (v Vulnerability) Identifiers() []string {
+	ids := append([]string{v.ID}, v.Aliases...)
+
+	var cves, ghsas []string
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		switch {
+		case strings.HasPrefix(id, "CVE-"):
+			cves = append(cves, id)
+		case strings.HasPrefix(id, "GHSA-"):
+			ghsas = append(ghsas, id)
+		}
+	}
+	if len(cves)+len(ghsas) > 0 {
+		return append(cves, ghsas...)
+	}
+
+	return []string{v.ID}
+}
+
+// Affects returns whether 'version' is affected, alongside with the version that fixes the
+// vulnerability if known.
+func (a Affected) Affects(version string, cmp versionCmp) (bool, string) {
+	for _, r := range a.Ranges {
+		// GIT ranges use commit hashes, which can't be evaluated against a package version.
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if ok, fixed := r.affects(version, cmp); ok {
+			return true, fixed
+		}
+	}
+
+	// Explicitly enumerated versions cover packages whose ranges could not be evaluated.
+	for _, v := range a.Versions {
+		if v == version {
+			return true, ""
+		}
+	}
+
+	return false, ""
+}
+
+// affects evaluates the range following the algorithm described in the OSV schema: events are sorted
+// by version and 'version' is vulnerable if the last event not greater than it introduced the
+// vulnerability.
+func (r Range) affects(version string, cmp versionCmp) (bool, string) {
+	events := make([]Event, len(r.Events))
+	copy(events, r.Events)
+
+	var cmpErr error
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].version(), events[j].version()
+		// '0' is used for denoting that all versions are affected.
+		if a == "0" || b == "0" {
+			return a == "0" && b != "0"
+		}
+		c, err := cmp(a, b)
+		if err != nil {
+			cmpErr = err
+		}
+		return c < 0
+	})
+	if cmpErr != nil {
+		return false, ""
+	}
+
+	var vulnerable bool
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" {
+				vulnerable = true
+				continue
+			}
+			c, err := cmp(version, e.Introduced)
+			if err != nil {
+				return false, ""
+			}
+			if c >= 0 {
+				vulnerable = true
+			}
+		case e.Fixed != "":
+			c, err := cmp(version, e.Fixed)
+			if err != nil {
+				return false, ""
+			}
+			if c >= 0 {
+				vulnerable = false
+			}
+		case e.LastAffected != "":
+			c, err := cmp(version, e.LastAffected)
+			if err != nil {
+				return false, ""
+			}
+			if c > 0 {
+				vulnerable = false
+			}
+		}
+	}
+
+	if !vulnerable {
+		return false, ""
+	}
+
+	// The fix is the first 'fixed' event after 'version'.
+	for _, e := range events {
+		if e.Fixed == "" {
+			continue
+		}
+		if c, err := cmp(version, e.Fixed); err == nil && c < 0 {
+			return true, e.Fixed
+		}
+	}
+	return true, ""
+}
+
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName normalizes a Python package name as described in
+// https://packaging.python.org/en/latest/specifications/name-normalization/.
+func normalizePyPIName(name string) string {
+	return pypiNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// ecosystem maps a software source to the OSV ecosystem publishing advisories for it.
+type ecosystem struct {
+	// name is the name of the OSV ecosystem (e.g. 'npm').
+	name string
+	// source is the software source as reported by osquery (e.g. 'npm_packages').
+	source string
+	cmp    versionCmp
+	// normalize returns the canonical form of a package name, used for matching software names
+	// against advisories.
+	normalize func(string) string
+}
+
+var ecosystems = []ecosystem{
+	{name: "npm", source: "npm_packages", cmp: semverCmp, normalize: strings.TrimSpace},
+	{name: "PyPI", source: "python_packages", cmp: pep440Cmp, normalize: normalizePyPIName},
+}
+
+func supportedEcosystem(name string) bool {
+	for _, e := range ecosystems {
+		if e.name == name {
+			return true
+		}
+	}
+	return false
+}
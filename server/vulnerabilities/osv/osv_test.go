@@ -0,0 +1,144 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifiers(t *testing.T) {
+	cases := []struct {
+		name     string
+		vuln     Vulnerability
+		expected []string
+	}{
+		{
+			name:     "uses the CVE aliases and the GHSA id",
+			vuln:     Vulnerability{ID: "GHSA-p6mc-m468-83gw", Aliases: []string{"CVE-2020-8203", "CVE-2020-28500"}},
+			expected: []string{"CVE-2020-8203", "CVE-2020-28500", "GHSA-p6mc-m468-83gw"},
+		},
+		{
+			name:     "uses the CVE id and the GHSA alias",
+			vuln:     Vulnerability{ID: "CVE-2023-1234", Aliases: []string{"GHSA-xxxx-xxxx-xxxx"}},
+			expected: []string{"CVE-2023-1234", "GHSA-xxxx-xxxx-xxxx"},
+		},
+		{
+			name:     "ignores duplicated aliases",
+			vuln:     Vulnerability{ID: "PYSEC-2023-74", Aliases: []string{"CVE-2023-32681", "GHSA-j8r2-6x86-q33q", "CVE-2023-32681"}},
+			expected: []string{"CVE-2023-32681", "GHSA-j8r2-6x86-q33q"},
+		},
+		{
+			name:     "falls back to the GHSA alias",
+			vuln:     Vulnerability{ID: "PYSEC-2021-1", Aliases: []string{"GHSA-2222-3333-4444"}},
+			expected: []string{"GHSA-2222-3333-4444"},
+		},
+		{
+			name:     "falls back to the id",
+			vuln:     Vulnerability{ID: "MAL-2024-1"},
+			expected: []string{"MAL-2024-1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, c.vuln.Identifiers())
+		})
+	}
+}
+
+func TestAffects(t *testing.T) {
+	t.Run("SEMVER ranges", func(t *testing.T) {
+		sut := Affected{
+			Package: Package{Ecosystem: "npm", Name: "lodash"},
+			Ranges: []Range{{
+				Type: "SEMVER",
+				// Events are not necessarily sorted.
+				Events: []Event{
+					{Introduced: "4.0.0"},
+					{Fixed: "4.17.21"},
+					{Introduced: "0"},
+					{Fixed: "3.10.2"},
+				},
+			}},
+		}
+
+		cases := []struct {
+			version  string
+			affected bool
+			fixed    string
+		}{
+			{"1.0.0", true, "3.10.2"},
+			{"3.10.2", false, ""},
+			{"3.99.0", false, ""},
+			{"4.0.0", true, "4.17.21"},
+			{"4.17.20", true, "4.17.21"},
+			{"4.17.21", false, ""},
+			{"5.0.0", false, ""},
+			{"not a version", false, ""},
+		}
+
+		for _, c := range cases {
+			affected, fixed := sut.Affects(c.version, semverCmp)
+			require.Equal(t, c.affected, affected, c.version)
+			require.Equal(t, c.fixed, fixed, c.version)
+		}
+	})
+
+	t.Run("ECOSYSTEM ranges with last_affected", func(t *testing.T) {
+		sut := Affected{
+			Package: Package{Ecosystem: "PyPI", Name: "requests"},
+			Ranges: []Range{{
+				Type:   "ECOSYSTEM",
+				Events: []Event{{Introduced: "2.1"}, {LastAffected: "2.3.0"}},
+			}},
+		}
+
+		affected, fixed := sut.Affects("2.3", pep440Cmp)
+		require.True(t, affected)
+		require.Empty(t, fixed)
+
+		affected, _ = sut.Affects("2.3.0.post1", pep440Cmp)
+		require.False(t, affected)
+
+		affected, _ = sut.Affects("2.0", pep440Cmp)
+		require.False(t, affected)
+	})
+
+	t.Run("GIT ranges are ignored", func(t *testing.T) {
+		sut := Affected{
+			Ranges: []Range{{
+				Type:   "GIT",
+				Events: []Event{{Introduced: "0"}, {Fixed: "8b1a7a5ed8d3d6a8b1b0ca1e7e9f6d6c7c2a4b1e"}},
+			}},
+		}
+
+		affected, _ := sut.Affects("1.0.0", semverCmp)
+		require.False(t, affected)
+	})
+
+	t.Run("enumerated versions", func(t *testing.T) {
+		sut := Affected{
+			Ranges: []Range{{
+				Type:   "GIT",
+				Events: []Event{{Introduced: "0"}},
+			}},
+			Versions: []string{"0.9", "1.0"},
+		}
+
+		affected, fixed := sut.Affects("1.0", pep440Cmp)
+		require.True(t, affected)
+		require.Empty(t, fixed)
+
+		affected, _ = sut.Affects("1.1", pep440Cmp)
+		require.False(t, affected)
+	})
+}
+
+func TestNormalizePyPIName(t *testing.T) {
+	require.Equal(t, "friendly-bard", normalizePyPIName("Friendly-Bard"))
+	require.Equal(t, "friendly-bard", normalizePyPIName("FRIENDLY-BARD"))
+	require.Equal(t, "friendly-bard", normalizePyPIName("friendly.bard"))
+	require.Equal(t, "friendly-bard", normalizePyPIName("friendly_bard"))
+	require.Equal(t, "friendly-bard", normalizePyPIName("friendly--bard"))
+	require.Equal(t, "friendly-bard", normalizePyPIName("FrIeNdLy-._.-bArD"))
+}
@@ -0,0 +1,94 @@
+package osv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/it-laborato/MDM_Lab/pkg/mdmlabhttp"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/io"
+)
+
+// dumpsDelta returns what ecosystem dumps should be downloaded from GH and what dumps should be
+// removed from the local file system. Only dumps for supported ecosystems are considered.
+func dumpsDelta(
+	local []io.MetadataFileName,
+	remote []io.MetadataFileName,
+) (
+	[]io.MetadataFileName,
+	[]io.MetadataFileName,
+) {
+	var toDownload []io.MetadataFileName
+	var toDelete []io.MetadataFileName
+	for _, r := range remote {
+		if !supportedEcosystem(r.ProductName()) {
+			continue
+		}
+
+		upToDate := false
+		for _, l := range local {
+			if l.ProductName() != r.ProductName() {
+				continue
+			}
+			if l.Before(r) {
+				toDelete = append(toDelete, l)
+			} else {
+				upToDate = true
+			}
+		}
+		if !upToDate {
+			toDownload = append(toDownload, r)
+		}
+	}
+	return toDownload, toDelete
+}
+
+// SyncFromGithub keeps the local OSV ecosystem dumps (contained in dstDir) in sync with the ones
+// published in Github.
+func SyncFromGithub(ctx context.Context, dstDir string) error {
+	client := mdmlabhttp.NewGithubClient()
+	rep := github.NewClient(client).Repositories
+	gh := io.NewGitHubClient(client, rep, dstDir)
+	fs := io.NewFSClient(dstDir)
+
+	if err := sync(ctx, fs, gh); err != nil {
+		return fmt.Errorf("osv sync: %w", err)
+	}
+
+	return nil
+}
+
+func sync(
+	ctx context.Context,
+	fsClient io.FSAPI,
+	ghClient io.GitHubAPI,
+) error {
+	remoteURLs, err := ghClient.OSVDumps(ctx)
+	if err != nil {
+		return err
+	}
+
+	var remote []io.MetadataFileName
+	for r := range remoteURLs {
+		remote = append(remote, r)
+	}
+
+	local, err := fsClient.OSVDumps()
+	if err != nil {
+		return err
+	}
+
+	toDownload, toDelete := dumpsDelta(local, remote)
+	for _, d := range toDownload {
+		if _, err := ghClient.Download(remoteURLs[d]); err != nil {
+			return err
+		}
+	}
+	for _, d := range toDelete {
+		if err := fsClient.Delete(d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package osv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/io"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetadataFile(t *testing.T, name string) io.MetadataFileName {
+	mfn, err := io.NewOSVMetadata(name)
+	require.NoError(t, err)
+	return mfn
+}
+
+type testData struct {
+	RemoteList          map[io.MetadataFileName]string
+	RemoteListError     error
+	RemoteDownloaded    []string
+	RemoteDownloadError error
+	LocalList           []io.MetadataFileName
+	LocalListError      error
+	LocalDeleted        []io.MetadataFileName
+	LocalDeleteError    error
+}
+
+type ghMock struct{ TestData *testData }
+
+func (gh ghMock) MSRCBulletins(ctx context.Context) (map[io.MetadataFileName]string, error) {
+	return nil, nil
+}
+
+func (gh ghMock) MacOfficeReleaseNotes(ctx context.Context) (io.MetadataFileName, string, error) {
+	return io.MetadataFileName{}, "", nil
+}
+
+func (gh ghMock) OSVDumps(ctx context.Context) (map[io.MetadataFileName]string, error) {
+	return gh.TestData.RemoteList, gh.TestData.RemoteListError
+}
+
+func (gh ghMock) Download(url string) (string, error) {
+	gh.TestData.RemoteDownloaded = append(gh.TestData.RemoteDownloaded, url)
+	return "", gh.TestData.RemoteDownloadError
+}
+
+type fsMock struct{ TestData *testData }
+
+func (fs fsMock) MSRCBulletins() ([]io.MetadataFileName, error) {
+	return nil, nil
+}
+
+func (fs fsMock) MacOfficeReleaseNotes() ([]io.MetadataFileName, error) {
+	return nil, nil
+}
+
+func (fs fsMock) OSVDumps() ([]io.MetadataFileName, error) {
+	return fs.TestData.LocalList, fs.TestData.LocalListError
+}
+
+func (fs fsMock) Delete(d io.MetadataFileName) error {
+	fs.TestData.LocalDeleted = append(fs.TestData.LocalDeleted, d)
+	return fs.TestData.LocalDeleteError
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("#dumpsDelta", func(t *testing.T) {
+		remoteNpm := newMetadataFile(t, "mdmlab_osv_npm-2025_01_30.json")
+		remotePyPI := newMetadataFile(t, "mdmlab_osv_PyPI-2025_01_30.json")
+		remoteGo := newMetadataFile(t, "mdmlab_osv_Go-2025_01_30.json")
+		remote := []io.MetadataFileName{remoteNpm, remotePyPI, remoteGo}
+
+		t.Run("without local dumps", func(t *testing.T) {
+			toDownload, toDelete := dumpsDelta(nil, remote)
+			require.ElementsMatch(t, []io.MetadataFileName{remoteNpm, remotePyPI}, toDownload)
+			require.Empty(t, toDelete)
+		})
+
+		t.Run("with out of date local dumps", func(t *testing.T) {
+			local := []io.MetadataFileName{
+				newMetadataFile(t, "mdmlab_osv_npm-2025_01_30.json"),
+				newMetadataFile(t, "mdmlab_osv_PyPI-2025_01_20.json"),
+				newMetadataFile(t, "mdmlab_osv_PyPI-2025_01_10.json"),
+			}
+			toDownload, toDelete := dumpsDelta(local, remote)
+			require.ElementsMatch(t, []io.MetadataFileName{remotePyPI}, toDownload)
+			require.ElementsMatch(t, local[1:], toDelete)
+		})
+
+		t.Run("with up to date and stale local dumps", func(t *testing.T) {
+			local := []io.MetadataFileName{
+				newMetadataFile(t, "mdmlab_osv_npm-2025_01_30.json"),
+				newMetadataFile(t, "mdmlab_osv_npm-2025_01_01.json"),
+			}
+			toDownload, toDelete := dumpsDelta(local, remote[:1])
+			require.Empty(t, toDownload)
+			require.ElementsMatch(t, local[1:], toDelete)
+		})
+	})
+
+	t.Run("#sync", func(t *testing.T) {
+		remote := newMetadataFile(t, "mdmlab_osv_npm-2025_01_30.json")
+
+		t.Run("on GH error", func(t *testing.T) {
+			testData := testData{RemoteListError: errors.New("some error")}
+			err := sync(ctx, fsMock{TestData: &testData}, ghMock{TestData: &testData})
+			require.ErrorContains(t, err, "some error")
+		})
+
+		t.Run("on FS error", func(t *testing.T) {
+			testData := testData{
+				RemoteList:     map[io.MetadataFileName]string{remote: "http://someurl.com"},
+				LocalListError: errors.New("some error"),
+			}
+			err := sync(ctx, fsMock{TestData: &testData}, ghMock{TestData: &testData})
+			require.ErrorContains(t, err, "some error")
+		})
+
+		t.Run("on error when downloading GH asset", func(t *testing.T) {
+			testData := testData{
+				RemoteList:          map[io.MetadataFileName]string{remote: "http://someurl.com"},
+				RemoteDownloadError: errors.New("some error"),
+			}
+			err := sync(ctx, fsMock{TestData: &testData}, ghMock{TestData: &testData})
+			require.ErrorContains(t, err, "some error")
+		})
+
+		t.Run("when nothing published on GH", func(t *testing.T) {
+			testData := testData{
+				LocalList: []io.MetadataFileName{newMetadataFile(t, "mdmlab_osv_npm-2025_01_10.json")},
+			}
+			err := sync(ctx, fsMock{TestData: &testData}, ghMock{TestData: &testData})
+			require.NoError(t, err)
+			require.Empty(t, testData.RemoteDownloaded)
+			require.Empty(t, testData.LocalDeleted)
+		})
+
+		t.Run("when local copy is out of date", func(t *testing.T) {
+			local := newMetadataFile(t, "mdmlab_osv_npm-2025_01_10.json")
+			testData := testData{
+				RemoteList: map[io.MetadataFileName]string{remote: "http://someurl.com"},
+				LocalList:  []io.MetadataFileName{local},
+			}
+			err := sync(ctx, fsMock{TestData: &testData}, ghMock{TestData: &testData})
+			require.NoError(t, err)
+			require.Equal(t, []string{"http://someurl.com"}, testData.RemoteDownloaded)
+			require.Equal(t, []io.MetadataFileName{local}, testData.LocalDeleted)
+		})
+
+		t.Run("on error when deleting", func(t *testing.T) {
+			testData := testData{
+				RemoteList:       map[io.MetadataFileName]string{remote: "http://someurl.com"},
+				LocalList:        []io.MetadataFileName{newMetadataFile(t, "mdmlab_osv_npm-2025_01_10.json")},
+				LocalDeleteError: errors.New("some error"),
+			}
+			err := sync(ctx, fsMock{TestData: &testData}, ghMock{TestData: &testData})
+			require.ErrorContains(t, err, "some error")
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package osv
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// versionCmp compares two versions of a package, returns -1 if a < b, 0 if a == b and 1 if a > b.
+type versionCmp func(a, b string) (int, error)
+
+// semverCmp compares versions following the semver spec, used by npm.
+func semverCmp(a, b string) (int, error) {
+	verA, err := semver.NewVersion(a)
+	if err != nil {
+		return 0, fmt.Errorf("invalid semver version %q: %w", a, err)
+	}
+	verB, err := semver.NewVersion(b)
+	if err != nil {
+		return 0, fmt.Errorf("invalid semver version %q: %w", b, err)
+	}
+	return verA.Compare(verB), nil
+}
+
+var pep440Pattern = regexp.MustCompile(
+	`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` + // epoch and release segments
+		`(?:[-_.]?(a|alpha|b|beta|c|rc|pre|preview)[-_.]?(\d*))?` + // pre-release
+		`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` + // post-release
+		`(?:[-_.]?(dev)[-_.]?(\d*))?` + // development release
+		`(?:\+[a-z0-9]+(?:[-_.][a-z0-9]+)*)?$`, // local version label, ignored
+)
+
+// pep440Version is a parsed Python package version, see https://peps.python.org/pep-0440/.
+type pep440Version struct {
+	epoch   int
+	release []int
+	// pre, post and dev are the sort keys of the pre-release, post-release and development
+	// segments, math.MinInt/math.MaxInt are used for denoting a missing segment.
+	pre  [2]int
+	post int
+	dev  int
+}
+
+func parsePEP440(v string) (pep440Version, error) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return pep440Version{}, fmt.Errorf("invalid PEP 440 version %q", v)
+	}
+
+	// Only numeric segments can reach this point, so errors can be safely ignored.
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	var r pep440Version
+	r.epoch = atoi(m[1])
+	for _, s := range strings.Split(m[2], ".") {
+		r.release = append(r.release, atoi(s))
+	}
+
+	r.post = math.MinInt
+	if m[5] != "" {
+		r.post = atoi(m[5])
+	} else if m[6] != "" {
+		r.post = atoi(m[7])
+	}
+
+	r.dev = math.MaxInt
+	if m[8] != "" {
+		r.dev = atoi(m[9])
+	}
+
+	switch m[3] {
+	case "a", "alpha":
+		r.pre = [2]int{0, atoi(m[4])}
+	case "b", "beta":
+		r.pre = [2]int{1, atoi(m[4])}
+	case "c", "rc", "pre", "preview":
+		r.pre = [2]int{2, atoi(m[4])}
+	default:
+		// A development release of a final release (e.g. 1.0.dev1) sorts before its pre-releases.
+		if r.dev != math.MaxInt && r.post == math.MinInt {
+			r.pre = [2]int{math.MinInt, 0}
+		} else {
+			r.pre = [2]int{math.MaxInt, 0}
+		}
+	}
+
+	return r, nil
+}
+
+// pep440Cmp compares versions following PEP 440, used by PyPI.
+func pep440Cmp(a, b string) (int, error) {
+	verA, err := parsePEP440(a)
+	if err != nil {
+		return 0, err
+	}
+	verB, err := parsePEP440(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if c := cmpInt(verA.epoch, verB.epoch); c != 0 {
+		return c, nil
+	}
+
+	// Release segments are padded with zeros, so 1.0 == 1.0.0
+	for i := 0; i < len(verA.release) || i < len(verB.release); i++ {
+		var x, y int
+		if i < len(verA.release) {
+			x = verA.release[i]
+		}
+		if i < len(verB.release) {
+			y = verB.release[i]
+		}
+		if c := cmpInt(x, y); c != 0 {
+			return c, nil
+		}
+	}
+
+	for _, c := range []int{
+		cmpInt(verA.pre[0], verB.pre[0]),
+		cmpInt(verA.pre[1], verB.pre[1]),
+		cmpInt(verA.post, verB.post),
+		cmpInt(verA.dev, verB.dev),
+	} {
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,78 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemverCmp(t *testing.T) {
+	cases := []struct {
+		a        string
+		expected int
+		b        string
+	}{
+		{"1.0.0", 0, "1.0.0"},
+		{"1.0.0", -1, "1.0.1"},
+		{"1.10.0", 1, "1.9.0"},
+		{"2.0.0-beta.1", -1, "2.0.0"},
+		{"2.0.0-beta.1", -1, "2.0.0-beta.2"},
+		{"v4.17.21", 0, "4.17.21"},
+	}
+
+	for _, c := range cases {
+		r, err := semverCmp(c.a, c.b)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, r, "comparing '%s' vs '%s'", c.a, c.b)
+	}
+
+	_, err := semverCmp("not a version", "1.0.0")
+	require.Error(t, err)
+}
+
+func TestPEP440Cmp(t *testing.T) {
+	const (
+		LESS    = -1
+		EQUAL   = 0
+		GREATER = 1
+	)
+
+	cases := []struct {
+		a        string
+		expected int
+		b        string
+	}{
+		{"1.0", EQUAL, "1.0.0"},
+		{"1.0", LESS, "1.0.1"},
+		{"1.2", LESS, "1.10"},
+		{"2.31.0", GREATER, "2.4.0"},
+		{"1.0.0.1", GREATER, "1.0"},
+		{"1!0.1", GREATER, "2.0"},
+		{"1.0a1", LESS, "1.0"},
+		{"1.0a1", LESS, "1.0b1"},
+		{"1.0b2", LESS, "1.0rc1"},
+		{"1.0rc1", EQUAL, "1.0c1"},
+		{"1.0-RC1", EQUAL, "1.0rc1"},
+		{"1.0.dev1", LESS, "1.0a1"},
+		{"1.0a1.dev1", LESS, "1.0a1"},
+		{"1.0", LESS, "1.0.post1"},
+		{"1.0.post1", EQUAL, "1.0-1"},
+		{"1.0.post1.dev1", LESS, "1.0.post1"},
+		{"1.0.post1", LESS, "1.0.1"},
+		{"1.0+ubuntu1", EQUAL, "1.0"},
+		{"v2.0", EQUAL, "2.0"},
+	}
+
+	for _, c := range cases {
+		r, err := pep440Cmp(c.a, c.b)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, r, "comparing '%s' vs '%s'", c.a, c.b)
+
+		r, err = pep440Cmp(c.b, c.a)
+		require.NoError(t, err)
+		require.Equal(t, -c.expected, r, "comparing '%s' vs '%s'", c.b, c.a)
+	}
+
+	_, err := pep440Cmp("1.0", "banana")
+	require.Error(t, err)
+}
@@ -33,8 +33,11 @@ func Analyze(
 	platform := NewPlatform(ver.Platform, ver.Name)
 
 	source := mdmlab.UbuntuOVALSource
-	if platform.IsRedHat() {
+	switch {
+	case platform.IsRedHat():
 		source = mdmlab.RHELOVALSource
+	case platform.IsDebian():
+		source = mdmlab.DebianOVALSource
 	}
 
 	if !platform.IsSupported() {
@@ -165,5 +168,13 @@ func loadDef(platform Platform, vulnPath string) (oval_parsed.Result, error) {
 		return result, nil
 	}
 
+	if platform.IsDebian() {
+		result := oval_parsed.DebianResult{}
+		if err := json.Unmarshal(payload, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
 	return nil, fmt.Errorf("don't know how to parse file %q for %q platform", latest, platform)
 }
@@ -153,6 +153,26 @@ func assertVulns(
 ) {
 	ctx := context.Background()
 
+	expected := loadExpectedVulns(t, vulnPath, p)
+
+	storedVulns, err := ds.ListSoftwareVulnerabilitiesByHostIDsSource(ctx, []uint{h.ID}, source)
+	require.NoError(t, err)
+
+	uniq := make(map[string]bool)
+	for _, v := range storedVulns[h.ID] {
+		uniq[v.CVE] = true
+	}
+	actual := make([]string, 0, len(uniq))
+	for k := range uniq {
+		actual = append(actual, k)
+	}
+
+	require.ElementsMatch(t, actual, expected)
+}
+
+// loadExpectedVulns returns the CVEs listed in the extracted software_cves fixture of the
+// platform.
+func loadExpectedVulns(t require.TestingT, vulnPath string, p Platform) []string {
 	fPath := filepath.Join(vulnPath, fmt.Sprintf("%s-software_cves.csv", p))
 	f, err := os.Open(fPath)
 	require.NoError(t, err)
@@ -182,19 +202,7 @@ func assertVulns(
 	}
 	require.NotEmpty(t, expected)
 
-	storedVulns, err := ds.ListSoftwareVulnerabilitiesByHostIDsSource(ctx, []uint{h.ID}, source)
-	require.NoError(t, err)
-
-	uniq := make(map[string]bool)
-	for _, v := range storedVulns[h.ID] {
-		uniq[v.CVE] = true
-	}
-	actual := make([]string, 0, len(uniq))
-	for k := range uniq {
-		actual = append(actual, k)
-	}
-
-	require.ElementsMatch(t, actual, expected)
+	return expected
 }
 
 func BenchmarkTestOvalAnalyzer(b *testing.B) {
@@ -281,6 +289,65 @@ func BenchmarkTestOvalAnalyzer(b *testing.B) {
 	})
 }
 
+// TestRHELResultEval evaluates the RHEL fixtures without a datastore, so that changes to the
+// RHEL matching are always checked against real OVAL definitions.
+func TestRHELResultEval(t *testing.T) {
+	systems := []struct {
+		softwareFixtureDir string
+		version            mdmlab.OSVersion
+	}{
+		{filepath.Join("rhel", "software", "0709"), mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux Server 7.9.0"}},
+		{filepath.Join("rhel", "software", "0802"), mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux Server 8.2.0"}},
+		{filepath.Join("rhel", "software", "0804"), mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux 8.4.0"}},
+		{filepath.Join("rhel", "software", "0806"), mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux 8.6.0"}},
+		{filepath.Join("rhel", "software", "0900"), mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux 9.0.0"}},
+	}
+
+	for _, s := range systems {
+		t.Run(s.version.Name, func(t *testing.T) {
+			vulnPath := t.TempDir()
+			p := NewPlatform(s.version.Platform, s.version.Name)
+			extractFixtures(p, "rhel", s.softwareFixtureDir, vulnPath, t)
+
+			def, err := loadDef(p, vulnPath)
+			require.NoError(t, err)
+
+			var fixtures []softwareFixture
+			contents, err := os.ReadFile(filepath.Join(vulnPath, fmt.Sprintf("%s-software.json", p)))
+			require.NoError(t, err)
+			err = json.Unmarshal(contents, &fixtures)
+			require.NoError(t, err)
+
+			software := make([]mdmlab.Software, 0, len(fixtures))
+			for i, fi := range fixtures {
+				software = append(software, mdmlab.Software{
+					ID:      uint(i + 1), //nolint:gosec // dismiss G115
+					Name:    fi.Name,
+					Version: fi.Version,
+					Release: fi.Release,
+					Arch:    fi.Arch,
+				})
+			}
+
+			vulns, err := def.Eval(s.version, software)
+			require.NoError(t, err)
+
+			uniq := make(map[string]bool)
+			for _, v := range vulns {
+				// every reported package is earlier than the version resolving the vulnerability
+				require.NotNil(t, v.ResolvedInVersion, v)
+				uniq[v.CVE] = true
+			}
+			actual := make([]string, 0, len(uniq))
+			for k := range uniq {
+				actual = append(actual, k)
+			}
+
+			require.ElementsMatch(t, actual, loadExpectedVulns(t, vulnPath, p))
+		})
+	}
+}
+
 func TestOvalAnalyzer(t *testing.T) {
 	t.Run("analyzing RHEL software", func(t *testing.T) {
 		ds := mysql.CreateMySQLDS(t)
@@ -365,6 +432,31 @@ func TestOvalAnalyzer(t *testing.T) {
 		}
 	})
 
+	t.Run("analyzing Debian software", func(t *testing.T) {
+		ds := mysql.CreateMySQLDS(t)
+		defer mysql.TruncateTables(t, ds)
+
+		vulnPath := t.TempDir()
+
+		ctx := context.Background()
+
+		systems := []mdmlab.OSVersion{
+			{Platform: "debian", Name: "Debian GNU/Linux 12.5.0"},
+		}
+
+		ovalFixtureDir := "debian"
+		softwareFixtureDir := filepath.Join("debian", "software")
+		for _, v := range systems {
+			withTestFixture(v, ovalFixtureDir, softwareFixtureDir, vulnPath, ds, func(h *mdmlab.Host) {
+				_, err := Analyze(ctx, ds, v, vulnPath, true)
+				require.NoError(t, err)
+
+				p := NewPlatform(v.Platform, v.Name)
+				assertVulns(t, ds, vulnPath, h, p, mdmlab.DebianOVALSource)
+			}, t)
+		}
+	})
+
 	t.Run("#load", func(t *testing.T) {
 		t.Run("invalid vuln path", func(t *testing.T) {
 			platform := NewPlatform("ubuntu", "Ubuntu 20.4.0")
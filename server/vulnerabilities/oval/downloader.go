@@ -13,6 +13,17 @@ const (
 	ovalSourcesFileName = "oval_sources.json"
 )
 
+// upstreamOvalSources contains the OVAL definitions published directly by the distro vendors for
+// platforms that might not be included in the 'oval sources' file.
+var upstreamOvalSources = OvalSources{
+	"debian_11": "https://www.debian.org/security/oval/oval-definitions-bullseye.xml.bz2",
+	"debian_12": "https://www.debian.org/security/oval/oval-definitions-bookworm.xml.bz2",
+	"debian_13": "https://www.debian.org/security/oval/oval-definitions-trixie.xml.bz2",
+	"oracle_07": "https://linux.oracle.com/security/oval/com.oracle.elsa-ol7.xml.bz2",
+	"oracle_08": "https://linux.oracle.com/security/oval/com.oracle.elsa-ol8.xml.bz2",
+	"oracle_09": "https://linux.oracle.com/security/oval/com.oracle.elsa-ol9.xml.bz2",
+}
+
 // OvalSources represents a platform => web url dictionary
 type OvalSources map[Platform]string
 
@@ -40,6 +51,16 @@ func getOvalSources(getter func(string) (io.ReadCloser, error)) (OvalSources, er
 	return sources, nil
 }
 
+// addUpstreamSources adds the OVAL definitions published by the distro vendors for any supported
+// platform not already included in 'sources'.
+func addUpstreamSources(sources OvalSources) {
+	for platform, u := range upstreamOvalSources {
+		if _, ok := sources[platform]; !ok {
+			sources[platform] = u
+		}
+	}
+}
+
 // downloadDefinitions downloads the OVAL definitions for a given 'platform-major os version'.
 // Returns the filepath to the downloaded oval definitions.
 func downloadDefinitions(
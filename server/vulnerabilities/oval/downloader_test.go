@@ -34,3 +34,20 @@ func TestOvalDownloadDefinitionsPlatformNotFound(t *testing.T) {
 	_, err := downloadDefinitions(ovalSources, "rhel-8", dw)
 	require.ErrorContains(t, err, "could not find platform")
 }
+
+func TestOvalAddUpstreamSources(t *testing.T) {
+	ovalSources := OvalSources{
+		"ubuntu_2204": "https://example.com/ubuntu_2204.xml.bz2",
+		"debian_12":   "https://example.com/debian_12.xml.bz2",
+	}
+	addUpstreamSources(ovalSources)
+
+	require.Equal(t, "https://example.com/ubuntu_2204.xml.bz2", ovalSources["ubuntu_2204"])
+	require.Equal(t, "https://example.com/debian_12.xml.bz2", ovalSources["debian_12"])
+	require.Equal(t, "https://www.debian.org/security/oval/oval-definitions-bullseye.xml.bz2", ovalSources["debian_11"])
+	require.Equal(t, "https://linux.oracle.com/security/oval/com.oracle.elsa-ol9.xml.bz2", ovalSources["oracle_09"])
+	for p := range upstreamOvalSources {
+		require.True(t, p.IsSupported(), p)
+		require.Contains(t, ovalSources, p)
+	}
+}
@@ -0,0 +1,14 @@
+package oval_input
+
+// DebianResultXML groups together the different tokens produced from parsing an OVAL file
+// published by the Debian security tracker.
+type DebianResultXML struct {
+	Definitions     []DefinitionXML
+	DpkgInfoTests   []DpkgInfoTestXML
+	DpkgInfoStates  []DpkgInfoStateXML
+	DpkgInfoObjects []PackageInfoTestObjectXML
+	ReleaseTests    []TextFileContent54TestXML
+	ReleaseStates   []TextFileContent54StateXML
+	UnameTests      []UnixUnameTestXML
+	Variables       map[string]ConstantVariableXML
+}
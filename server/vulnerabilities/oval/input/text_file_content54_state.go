@@ -0,0 +1,8 @@
+package oval_input
+
+// TextFileContent54StateXML see
+// https://oval.mitre.org/language/version5.10.1/ovaldefinition/documentation/independent-definitions-schema.html#textfilecontent54_state
+type TextFileContent54StateXML struct {
+	Id            string         `xml:"id,attr"`
+	Subexpression *SimpleTypeXML `xml:"subexpression"`
+}
@@ -0,0 +1,18 @@
+package oval_input
+
+type textFileContent54TestStateXML struct {
+	Id string `xml:"state_ref,attr"`
+}
+
+// TextFileContent54TestXML see
+// https://oval.mitre.org/language/version5.10.1/ovaldefinition/documentation/independent-definitions-schema.html#textfilecontent54_test
+//
+// For Debian, this test is used to make assertions against the installed release, read from
+// /etc/debian_version.
+type TextFileContent54TestXML struct {
+	Id             string                          `xml:"id,attr"`
+	CheckExistence string                          `xml:"check_existence,attr"`
+	Check          string                          `xml:"check,attr"`
+	StateOperator  string                          `xml:"state_operator,attr"`
+	States         []textFileContent54TestStateXML `xml:"state"`
+}
@@ -277,6 +277,32 @@ func mapDpkgInfoTest(i oval_input.DpkgInfoTestXML) (int, *oval_parsed.DpkgInfoTe
 	return id, &tst, nil
 }
 
+// mapTextFileContent54Test maps a TextFileContent54TestXML returning the test id along side the
+// mapped DebianReleaseTest, will error out if the test id can not be parsed.
+func mapTextFileContent54Test(i oval_input.TextFileContent54TestXML) (int, *oval_parsed.DebianReleaseTest, error) {
+	id, err := extractId(i.Id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	tst := oval_parsed.DebianReleaseTest{
+		StateMatch:    oval_parsed.NewStateMatchType(i.Check),
+		StateOperator: oval_parsed.NewOperatorType(i.StateOperator),
+	}
+
+	return id, &tst, nil
+}
+
+// mapTextFileContent54State maps a TextFileContent54StateXML into the value expected for the
+// subexpression captured by the object, will error out if the subexpression is not set.
+func mapTextFileContent54State(sta oval_input.TextFileContent54StateXML) (*oval_parsed.ObjectStateSimpleValue, error) {
+	if sta.Subexpression == nil {
+		return nil, errors.New("only subexpression state definitions are supported")
+	}
+	r := oval_parsed.NewObjectStateSimpleValue(sta.Subexpression.Datatype, sta.Subexpression.Op, sta.Subexpression.Value)
+	return &r, nil
+}
+
 func mapUnixUnameTest(i oval_input.UnixUnameTestXML) (int, *oval_parsed.UnixUnameTest, error) {
 	id, err := extractId(i.Id)
 	if err != nil {
@@ -46,20 +46,44 @@ func getMajorMinorVer(osVersion string) (string, string) {
 }
 
 func format(platform string, major string, minor string) string {
-	if platform == "ubuntu" {
+	switch platform {
+	case "ubuntu", "opensuse-leap":
 		return fmt.Sprintf("%s_%s%s", platform, major, minor)
+	case "opensuse-tumbleweed":
+		// Tumbleweed is a rolling release, its versions are snapshot dates
+		return platform
 	}
-	// RHEL based platforms only use the major version for their OVAL definitions
+	// RHEL based platforms and Debian only use the major version for their OVAL definitions
 	return fmt.Sprintf("%s_%s", platform, major)
 }
 
+// normalizePlatform maps the host platform to the platform of the OVAL definitions used for it:
+// RHEL rebuilds (Rocky, Alma) use the RHEL definitions and Oracle Linux uses the definitions
+// published by Oracle, even when osquery reports it as 'rhel'.
+func normalizePlatform(platform string, osVersion string) string {
+	switch {
+	case platform == "ol",
+		(platform == "rhel" || oval_parsed.IsRHELRebuild(platform)) && strings.HasPrefix(osVersion, "Oracle Linux"):
+		return "oracle"
+	case oval_parsed.IsRHELRebuild(platform):
+		return "rhel"
+	}
+	return platform
+}
+
 // NewPlatform combines the host platform and os version into a string used to match OVAL
 // definitions.
 // Examples:
 // ('ubuntu', 'Ubuntu 20.4.0') => 'ubuntu_2004'.
 // ('rhel', 'CentOS Linux 7.9.2009') => 'rhel_07'.
+// ('debian', 'Debian GNU/Linux 12.5.0') => 'debian_12'.
+// ('rocky', 'Rocky Linux 9.3.0') => 'rhel_09'.
+// ('rhel', 'Oracle Linux Server 8.9.0') => 'oracle_08'.
+// ('sles', 'SUSE Linux Enterprise Server 15.5.0') => 'sles_15'.
+// ('opensuse-leap', 'openSUSE Leap 15.5.0') => 'opensuse-leap_1505'.
 func NewPlatform(hostPlatform, hostOsVersion string) Platform {
-	nPlatform := strings.Trim(strings.ToLower(hostPlatform), " ")
+	hostOsVersion = strings.Trim(hostOsVersion, " ")
+	nPlatform := normalizePlatform(strings.Trim(strings.ToLower(hostPlatform), " "), hostOsVersion)
 	hostOsVersion = oval_parsed.ReplaceFedoraOSVersion(hostOsVersion)
 	major, minor := getMajorMinorVer(strings.Trim(hostOsVersion, " "))
 	return Platform(format(nPlatform, major, minor))
@@ -94,6 +118,12 @@ func (op Platform) IsSupported() bool {
 		"rhel_07",
 		"rhel_08",
 		"rhel_09",
+		"oracle_07",
+		"oracle_08",
+		"oracle_09",
+		"debian_11",
+		"debian_12",
+		"debian_13",
 	}
 	for _, p := range supported {
 		if strings.HasPrefix(string(op), p) {
@@ -109,6 +139,10 @@ func (op Platform) IsGovalDictionarySupported() bool {
 		"amzn_02",
 		"amzn_2022",
 		"amzn_2023",
+		"sles_12",
+		"sles_15",
+		"opensuse-leap_15",
+		"opensuse-tumbleweed",
 	}
 
 	for _, p := range supported {
@@ -119,12 +153,26 @@ func (op Platform) IsGovalDictionarySupported() bool {
 	return false
 }
 
+// IsGovalDictionaryOptional returns whether the goval-dictionary database of the current Platform
+// might not be published, in which case the Platform is only analyzed if its database was found
+// during the sync.
+func (op Platform) IsGovalDictionaryOptional() bool {
+	return strings.HasPrefix(string(op), "sles") || strings.HasPrefix(string(op), "opensuse")
+}
+
 // IsUbuntu checks whether the current Platform targets Ubuntu.
 func (op Platform) IsUbuntu() bool {
 	return strings.HasPrefix(string(op), "ubuntu")
 }
 
+// IsDebian checks whether the current Platform targets Debian.
+func (op Platform) IsDebian() bool {
+	return strings.HasPrefix(string(op), "debian")
+}
+
 // IsRedHat checks whether the current Platform targets Redhat based systems.
 func (op Platform) IsRedHat() bool {
-	return strings.HasPrefix(string(op), "rhel") || strings.HasPrefix(string(op), "amzn")
+	return strings.HasPrefix(string(op), "rhel") ||
+		strings.HasPrefix(string(op), "amzn") ||
+		strings.HasPrefix(string(op), "oracle")
 }
@@ -19,6 +19,17 @@ func TestOvalPlatform(t *testing.T) {
 			{"centos", "CentOS 6.10.0", "centos_06"},
 			{"debian", "Debian GNU/Linux 9.0.0", "debian_09"},
 			{"debian", "Debian GNU/Linux 10.0.0", "debian_10"},
+			{"debian", "Debian GNU/Linux 12.5.0", "debian_12"},
+			{"rocky", "Rocky Linux 9.3.0", "rhel_09"},
+			{"rhel", "Rocky Linux 8.9.0", "rhel_08"},
+			{"almalinux", "AlmaLinux 9.3.0", "rhel_09"},
+			{"rhel", "AlmaLinux 8.9.0", "rhel_08"},
+			{"ol", "Oracle Linux Server 9.3.0", "oracle_09"},
+			{"rhel", "Oracle Linux Server 8.9.0", "oracle_08"},
+			{"sles", "SUSE Linux Enterprise Server 15.5.0", "sles_15"},
+			{"sles", "SUSE Linux Enterprise Server 12.5.0", "sles_12"},
+			{"opensuse-leap", "openSUSE Leap 15.5.0", "opensuse-leap_1505"},
+			{"opensuse-tumbleweed", "openSUSE Tumbleweed 20240101.0.0", "opensuse-tumbleweed"},
 			{"centos", "CentOS Linux 7.9.2009", "centos_07"},
 			{"ubuntu", "Ubuntu 16.4.0", "ubuntu_1604"},
 			{"ubuntu", "Ubuntu 18.4.0", "ubuntu_1804"},
@@ -75,6 +86,52 @@ func TestOvalPlatform(t *testing.T) {
 		}
 	})
 
+	t.Run("IsSupported", func(t *testing.T) {
+		cases := []struct {
+			platform  string
+			osVersion string
+			supported bool
+			debian    bool
+		}{
+			{"ubuntu", "Ubuntu 22.4.0", true, false},
+			{"rhel", "CentOS Linux 7.9.2009", true, false},
+			{"debian", "Debian GNU/Linux 10.0.0", false, true},
+			{"debian", "Debian GNU/Linux 11.9.0", true, true},
+			{"debian", "Debian GNU/Linux 12.5.0", true, true},
+			{"amzn", "Amazon Linux 2.0.0", false, false},
+			{"rocky", "Rocky Linux 9.3.0", true, false},
+			{"rhel", "Oracle Linux Server 8.9.0", true, false},
+			{"sles", "SUSE Linux Enterprise Server 15.5.0", false, false},
+		}
+		for _, c := range cases {
+			plat := NewPlatform(c.platform, c.osVersion)
+			require.Equal(t, c.supported, plat.IsSupported(), c)
+			require.Equal(t, c.debian, plat.IsDebian(), c)
+		}
+	})
+
+	t.Run("IsGovalDictionarySupported", func(t *testing.T) {
+		cases := []struct {
+			platform  string
+			osVersion string
+			supported bool
+			optional  bool
+		}{
+			{"amzn", "Amazon Linux 2023.0.0", true, false},
+			{"sles", "SUSE Linux Enterprise Server 15.5.0", true, true},
+			{"sles", "SUSE Linux Enterprise Server 12.5.0", true, true},
+			{"opensuse-leap", "openSUSE Leap 15.5.0", true, true},
+			{"opensuse-tumbleweed", "openSUSE Tumbleweed 20240101.0.0", true, true},
+			{"rhel", "Oracle Linux Server 8.9.0", false, false},
+			{"ubuntu", "Ubuntu 22.4.0", false, false},
+		}
+		for _, c := range cases {
+			plat := NewPlatform(c.platform, c.osVersion)
+			require.Equal(t, c.supported, plat.IsGovalDictionarySupported(), c)
+			require.Equal(t, c.optional, plat.IsGovalDictionaryOptional(), c)
+		}
+	})
+
 	t.Run("ToGovalDictionaryFilename", func(t *testing.T) {
 		cases := []struct {
 			version  string
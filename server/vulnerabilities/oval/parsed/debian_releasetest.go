@@ -0,0 +1,53 @@
+package oval_parsed
+
+import (
+	"strings"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// DebianReleaseTest is a <textfilecontent54_test> used in the OVAL files published by the Debian
+// security tracker for making assertions against the installed release. Its object captures the
+// major version from /etc/debian_version, which is compared against the states of the test.
+type DebianReleaseTest struct {
+	States        []ObjectStateSimpleValue
+	StateOperator OperatorType
+	StateMatch    StateMatchType
+}
+
+func (t *DebianReleaseTest) Eval(ver mdmlab.OSVersion) (bool, error) {
+	major := debianMajorVersion(ver)
+	if major == "" {
+		return false, nil
+	}
+
+	var results []bool
+	for _, sta := range t.States {
+		rEval, err := sta.Eval(major)
+		if err != nil {
+			return false, err
+		}
+		results = append(results, rEval)
+	}
+
+	// The object of this test matches a single item (the major version), meaning that it will
+	// either match the state (nState = 1) or not (nState = 0)
+	var nState int
+	if t.StateOperator.Eval(results...) {
+		nState = 1
+	}
+
+	return t.StateMatch.Eval(1, nState), nil
+}
+
+// debianMajorVersion returns the major version of the Debian release of the host, e.g. '12' for
+// 'Debian GNU/Linux 12.5.0'.
+func debianMajorVersion(ver mdmlab.OSVersion) string {
+	name := strings.TrimSpace(ver.Name)
+	version := name[strings.LastIndex(name, " ")+1:]
+	major, _, _ := strings.Cut(version, ".")
+	if major == "" || strings.Trim(major, "0123456789") != "" {
+		return ""
+	}
+	return major
+}
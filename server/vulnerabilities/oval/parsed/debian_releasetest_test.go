@@ -0,0 +1,35 @@
+package oval_parsed
+
+import (
+	"testing"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebianReleaseTestEval(t *testing.T) {
+	tst := DebianReleaseTest{
+		States: []ObjectStateSimpleValue{NewObjectStateSimpleValue("", "equals", "12")},
+	}
+
+	testCases := []struct {
+		Name     string
+		Input    string
+		Expected bool
+	}{
+		{Name: "same release", Input: "Debian GNU/Linux 12.5.0", Expected: true},
+		{Name: "major version only", Input: "Debian GNU/Linux 12", Expected: true},
+		{Name: "other release", Input: "Debian GNU/Linux 11.9.0", Expected: false},
+		{Name: "prefix of the release", Input: "Debian GNU/Linux 1.2.0", Expected: false},
+		{Name: "unknown release", Input: "Debian GNU/Linux trixie/sid", Expected: false},
+		{Name: "empty", Input: "", Expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			matches, err := tst.Eval(mdmlab.OSVersion{Platform: "debian", Name: tc.Input})
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, matches)
+		})
+	}
+}
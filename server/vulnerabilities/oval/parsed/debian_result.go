@@ -0,0 +1,110 @@
+package oval_parsed
+
+import (
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/utils"
+)
+
+// DebianResult holds the definitions and tests of an OVAL file published by the Debian security
+// tracker.
+//
+// Limitation: package tests in these files target source package names, while hosts report the
+// names of the installed binary packages and the source package they were built from is not
+// collected. Only binary packages named after their source package (e.g. curl, but not libssl3
+// built from openssl) are matched.
+type DebianResult struct {
+	Definitions  []Definition
+	PackageTests map[int]*DpkgInfoTest
+	// ReleaseTests contains the tests asserting the installed Debian release.
+	ReleaseTests map[int]*DebianReleaseTest
+	// ArchTests contains the ids of the tests asserting that the uname object exists, used by the
+	// Debian security tracker for architecture independent packages. These tests always evaluate
+	// to true. Architecture tests with a state are not included (and evaluate to false) since the
+	// architecture of the host is not known.
+	ArchTests []int
+}
+
+// NewDebianResult is the result of parsing an OVAL file that targets a Debian release.
+// Used to evaluate whether a Debian host is vulnerable based on one or more package tests.
+func NewDebianResult() *DebianResult {
+	return &DebianResult{
+		PackageTests: make(map[int]*DpkgInfoTest),
+		ReleaseTests: make(map[int]*DebianReleaseTest),
+	}
+}
+
+// AddDefinition add a definition to the given result.
+func (r *DebianResult) AddDefinition(def Definition) {
+	r.Definitions = append(r.Definitions, def)
+}
+
+// AddPackageTest adds a package test to the given result.
+func (r *DebianResult) AddPackageTest(id int, tst *DpkgInfoTest) {
+	r.PackageTests[id] = tst
+}
+
+// AddReleaseTest adds a release test to the given result.
+func (r *DebianResult) AddReleaseTest(id int, tst *DebianReleaseTest) {
+	r.ReleaseTests[id] = tst
+}
+
+// AddArchTest adds an architecture test with no state to the given result.
+func (r *DebianResult) AddArchTest(id int) {
+	r.ArchTests = append(r.ArchTests, id)
+}
+
+func (r DebianResult) Eval(ver mdmlab.OSVersion, software []mdmlab.Software) ([]mdmlab.SoftwareVulnerability, error) {
+	// Test Id => Matching software
+	pkgTstResults := make(map[int][]mdmlab.Software)
+	for i, t := range r.PackageTests {
+		// Debian package versions are compared using dpkg's algorithm (debian_evr_string)
+		r, err := t.EvalWithCmp(software, utils.Debvercmp)
+		if err != nil {
+			return nil, err
+		}
+		pkgTstResults[i] = r
+	}
+
+	// Evaluate the tests asserting the installed release and architecture
+	OSTstResults := make(map[int]bool, len(r.ReleaseTests)+len(r.ArchTests))
+	for i, t := range r.ReleaseTests {
+		rEval, err := t.Eval(ver)
+		if err != nil {
+			return nil, err
+		}
+		OSTstResults[i] = rEval
+	}
+	for _, id := range r.ArchTests {
+		OSTstResults[id] = true
+	}
+
+	vuln := make([]mdmlab.SoftwareVulnerability, 0)
+	for _, d := range r.Definitions {
+		if !d.Eval(OSTstResults, pkgTstResults) {
+			continue
+		}
+
+		for _, tId := range d.CollectTestIds() {
+			var resolvedInVersion *string
+			if tst, ok := r.PackageTests[tId]; ok {
+				resolvedInVersion = tst.ResolvedInVersion()
+			}
+			for _, software := range pkgTstResults[tId] {
+				for _, v := range d.CveVulnerabilities() {
+					vuln = append(vuln, mdmlab.SoftwareVulnerability{
+						SoftwareID:        software.ID,
+						CVE:               v,
+						ResolvedInVersion: resolvedInVersion,
+					})
+				}
+			}
+		}
+	}
+
+	return vuln, nil
+}
+
+// EvalKernel is not implemented for Debian.
+func (r DebianResult) EvalKernel(software []mdmlab.Software) ([]mdmlab.SoftwareVulnerability, error) {
+	return nil, nil
+}
@@ -1,6 +1,8 @@
 package oval_parsed
 
 import (
+	"strings"
+
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/utils"
 )
@@ -19,11 +21,17 @@ type DpkgInfoTest struct {
 // If test evaluates to true, returns all Software involved with the test match, otherwise will
 // return nil.
 func (t *DpkgInfoTest) Eval(packages []mdmlab.Software) ([]mdmlab.Software, error) {
+	return t.EvalWithCmp(packages, utils.Rpmvercmp)
+}
+
+// EvalWithCmp works like Eval but uses 'cmp' for comparing the version of the installed packages
+// against the test states.
+func (t *DpkgInfoTest) EvalWithCmp(packages []mdmlab.Software, cmp func(string, string) int) ([]mdmlab.Software, error) {
 	if len(packages) == 0 {
 		return nil, nil
 	}
 
-	no, ns, m, err := t.matches(packages)
+	no, ns, m, err := t.matches(packages, cmp)
 	if err != nil {
 		return nil, err
 	}
@@ -37,12 +45,28 @@ func (t *DpkgInfoTest) Eval(packages []mdmlab.Software) ([]mdmlab.Software, erro
 	return nil, nil
 }
 
+// ResolvedInVersion returns the version that resolves the vulnerability targeted by the test, based
+// on its 'less than' evr states. dpkg reports non-zero epochs as part of the installed version, so
+// only a zero epoch is dropped. Returns nil if the test is not asserting that a package is earlier
+// than a given version.
+func (t *DpkgInfoTest) ResolvedInVersion() *string {
+	for _, s := range t.States {
+		op, evr := s.unpack()
+		if op != LessThan {
+			continue
+		}
+		evr = strings.TrimPrefix(evr, "0:")
+		return &evr
+	}
+	return nil
+}
+
 // Returns:
 //
 //	nObjects: How many items in the set defined by the OVAL Object set exists in the system.
 //	nStates: How many items in the set defined by the OVAL Object set satisfy the state requirements.
 //	Slice with software matching both the object and state criteria.
-func (t *DpkgInfoTest) matches(software []mdmlab.Software) (int, int, []mdmlab.Software, error) {
+func (t *DpkgInfoTest) matches(software []mdmlab.Software, cmp func(string, string) int) (int, int, []mdmlab.Software, error) {
 	var nObjects int
 	var nState int
 	var matches []mdmlab.Software
@@ -54,7 +78,7 @@ func (t *DpkgInfoTest) matches(software []mdmlab.Software) (int, int, []mdmlab.S
 
 				r := make([]bool, 0)
 				for _, s := range t.States {
-					evalR, err := s.Eval(p.Version, utils.Rpmvercmp, false)
+					evalR, err := s.Eval(p.Version, cmp, false)
 					if err != nil {
 						return 0, 0, nil, err
 					}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/vulnerabilities/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,7 +42,7 @@ func TestDpkgInfoTestEvalNoHostList(t *testing.T) {
 				Objects: []string{"firefox", "paint"},
 			}
 
-			nObjects, _, _, _ := sut.matches(packages)
+			nObjects, _, _, _ := sut.matches(packages, utils.Rpmvercmp)
 			require.Equal(t, 2, nObjects)
 		})
 
@@ -71,7 +72,7 @@ func TestDpkgInfoTestEvalNoHostList(t *testing.T) {
 				StateOperator: Or,
 			}
 
-			_, nStates, _, _ := sut.matches(packages)
+			_, nStates, _, _ := sut.matches(packages, utils.Rpmvercmp)
 			require.Equal(t, 1, nStates)
 		})
 	})
@@ -118,7 +118,7 @@ func (sta ObjectInfoState) EvalOSVersion(version mdmlab.OSVersion) (bool, error)
 	// normalize the value.
 	if sta.Name != nil {
 		var nName string
-		if version.Platform == "rhel" || version.Platform == "amzn" {
+		if version.Platform == "rhel" || version.Platform == "amzn" || IsRHELRebuild(version.Platform) {
 			nName = "redhat-release"
 		}
 		rEval, err := sta.Name.Eval(nName)
@@ -130,7 +130,7 @@ func (sta ObjectInfoState) EvalOSVersion(version mdmlab.OSVersion) (bool, error)
 
 	if sta.Version != nil {
 		var pVer string
-		if version.Platform == "rhel" {
+		if version.Platform == "rhel" || IsRHELRebuild(version.Platform) {
 			version := ReplaceFedoraOSVersion(version.Name)
 			pName := strings.Trim(version, " ")
 			pVer = pName[strings.LastIndex(pName, " ")+1:]
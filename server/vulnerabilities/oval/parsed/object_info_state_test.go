@@ -199,6 +199,14 @@ func TestObjectInfoState(t *testing.T) {
 					version:  mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux 9.0.0"},
 					expected: true,
 				},
+				{
+					version:  mdmlab.OSVersion{Platform: "rocky", Name: "Rocky Linux 9.3.0"},
+					expected: true,
+				},
+				{
+					version:  mdmlab.OSVersion{Platform: "almalinux", Name: "AlmaLinux 9.3.0"},
+					expected: true,
+				},
 				{
 					version:  mdmlab.OSVersion{Platform: "ubuntu", Name: "Ubuntu 22.4.0"},
 					expected: false,
@@ -235,6 +243,14 @@ func TestObjectInfoState(t *testing.T) {
 					version:  mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux 9.0.0"},
 					expected: true,
 				},
+				{
+					version:  mdmlab.OSVersion{Platform: "rocky", Name: "Rocky Linux 9.3.0"},
+					expected: true,
+				},
+				{
+					version:  mdmlab.OSVersion{Platform: "almalinux", Name: "AlmaLinux 9.3.0"},
+					expected: true,
+				},
 				{
 					version:  mdmlab.OSVersion{Platform: "ubuntu", Name: "Ubuntu 22.4.0"},
 					expected: false,
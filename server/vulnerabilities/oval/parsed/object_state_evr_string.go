@@ -53,3 +53,19 @@ func (sta ObjectStateEvrString) Eval(ver string, cmp func(string, string) int, i
 
 	return false, fmt.Errorf("can not compute op %q", op)
 }
+
+// ResolvedInVersion returns the evr (without the epoch) tested by 'sta' if 'sta' asserts that the
+// installed version must be less than it, in which case the returned version resolves the
+// vulnerability.
+func (sta ObjectStateEvrString) ResolvedInVersion() (string, bool) {
+	op, evr := sta.unpack()
+	if op != LessThan {
+		return "", false
+	}
+
+	// See comment in Eval about epochs
+	if _, v, found := strings.Cut(evr, ":"); found {
+		evr = v
+	}
+	return evr, true
+}
@@ -46,11 +46,25 @@ func (r RhelResult) Eval(ver mdmlab.OSVersion, software []mdmlab.Software) ([]md
 		}
 
 		for _, tId := range d.CollectTestIds() {
+			tst, ok := r.RpmInfoTests[tId]
+			if !ok {
+				continue
+			}
+
+			// Only tests asserting that a package is earlier than a given version point to the
+			// vulnerable software, other tests (signature keys, installed release packages) can
+			// match unrelated software.
+			resolvedInVersion := tst.ResolvedInVersion()
+			if resolvedInVersion == nil {
+				continue
+			}
+
 			for _, software := range pkgTstResults[tId] {
 				for _, v := range d.CveVulnerabilities() {
 					vuln = append(vuln, mdmlab.SoftwareVulnerability{
-						SoftwareID: software.ID,
-						CVE:        v,
+						SoftwareID:        software.ID,
+						CVE:               v,
+						ResolvedInVersion: resolvedInVersion,
 					})
 				}
 			}
@@ -34,6 +34,21 @@ func (t *RpmInfoTest) Eval(packages []mdmlab.Software) ([]mdmlab.Software, error
 	return nil, nil
 }
 
+// ResolvedInVersion returns the version that resolves the vulnerability targeted by the test, based
+// on its 'less than' evr states. Returns nil if the test is not asserting that a package is earlier
+// than a given version.
+func (t *RpmInfoTest) ResolvedInVersion() *string {
+	for _, s := range t.States {
+		if s.Evr == nil {
+			continue
+		}
+		if v, ok := s.Evr.ResolvedInVersion(); ok {
+			return &v
+		}
+	}
+	return nil
+}
+
 // Returns:
 //
 //	nObjects: How many items in the set defined by the OVAL Object set exists in the system.
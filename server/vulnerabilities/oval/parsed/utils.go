@@ -31,3 +31,13 @@ func ReplaceFedoraOSVersion(version string) string {
 	}
 	return version
 }
+
+// IsRHELRebuild returns whether 'platform' is a RHEL rebuild (binary compatible with RHEL), for
+// which the RHEL OVAL definitions can be used.
+func IsRHELRebuild(platform string) bool {
+	switch platform {
+	case "rocky", "almalinux":
+		return true
+	}
+	return false
+}
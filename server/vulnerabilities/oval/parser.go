@@ -24,6 +24,8 @@ func parseDefinitions(platform Platform, inputFile string, outputFile string) er
 		payload, err = processUbuntuDef(r)
 	case platform.IsRedHat():
 		payload, err = processRhelDef(r)
+	case platform.IsDebian():
+		payload, err = processDebianDef(r)
 	}
 	if err != nil {
 		return fmt.Errorf("oval parser: %w", err)
@@ -490,3 +492,203 @@ func mapToUbuntuResult(xmlResult *oval_input.UbuntuResultXML) (*oval_parsed.Ubun
 
 	return r, nil
 }
+
+// -----------------
+// Debian
+// -----------------
+
+func processDebianDef(r io.Reader) ([]byte, error) {
+	xmlResult, err := parseDebianXML(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing debian xml: %w", err)
+	}
+
+	result, err := mapToDebianResult(xmlResult)
+	if err != nil {
+		return nil, fmt.Errorf("mapping debian result: %w", err)
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling debian result: %w", err)
+	}
+
+	return payload, nil
+}
+
+func parseDebianXML(reader io.Reader) (*oval_input.DebianResultXML, error) {
+	r := &oval_input.DebianResultXML{
+		Variables: make(map[string]oval_input.ConstantVariableXML),
+	}
+	d := xml.NewDecoder(reader)
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return r, nil
+			}
+			return nil, fmt.Errorf("decoding token: %v", err)
+		}
+
+		if t, ok := t.(xml.StartElement); ok {
+			switch t.Name.Local {
+			case "definition":
+				def := oval_input.DefinitionXML{}
+				if err = d.DecodeElement(&def, &t); err != nil {
+					return nil, fmt.Errorf("decoding definition: %w", err)
+				}
+				r.Definitions = append(r.Definitions, def)
+			case "dpkginfo_test":
+				tst := oval_input.DpkgInfoTestXML{}
+				if err = d.DecodeElement(&tst, &t); err != nil {
+					return nil, fmt.Errorf("decoding dpkginfo_test: %w", err)
+				}
+				r.DpkgInfoTests = append(r.DpkgInfoTests, tst)
+			case "dpkginfo_state":
+				sta := oval_input.DpkgInfoStateXML{}
+				if err = d.DecodeElement(&sta, &t); err != nil {
+					return nil, fmt.Errorf("decoding dpkginfo_state: %w", err)
+				}
+				r.DpkgInfoStates = append(r.DpkgInfoStates, sta)
+			case "dpkginfo_object":
+				obj := oval_input.PackageInfoTestObjectXML{}
+				if err = d.DecodeElement(&obj, &t); err != nil {
+					return nil, fmt.Errorf("decoding dpkginfo_object: %w", err)
+				}
+				r.DpkgInfoObjects = append(r.DpkgInfoObjects, obj)
+			case "textfilecontent54_test":
+				tst := oval_input.TextFileContent54TestXML{}
+				if err = d.DecodeElement(&tst, &t); err != nil {
+					return nil, fmt.Errorf("decoding textfilecontent54_test: %w", err)
+				}
+				r.ReleaseTests = append(r.ReleaseTests, tst)
+			case "textfilecontent54_state":
+				sta := oval_input.TextFileContent54StateXML{}
+				if err = d.DecodeElement(&sta, &t); err != nil {
+					return nil, fmt.Errorf("decoding textfilecontent54_state: %w", err)
+				}
+				r.ReleaseStates = append(r.ReleaseStates, sta)
+			case "uname_test":
+				tst := oval_input.UnixUnameTestXML{}
+				if err = d.DecodeElement(&tst, &t); err != nil {
+					return nil, fmt.Errorf("decoding uname_test: %w", err)
+				}
+				r.UnameTests = append(r.UnameTests, tst)
+			case "constant_variable":
+				cVar := oval_input.ConstantVariableXML{}
+				if err = d.DecodeElement(&cVar, &t); err != nil {
+					return nil, fmt.Errorf("decoding constant_variable: %w", err)
+				}
+				r.Variables[cVar.Id] = cVar
+			}
+		}
+	}
+}
+
+// mapToDebianResult maps the tokens parsed from a Debian OVAL file. Package tests in these files
+// target source package names, for packages whose binary name differs from their source name
+// (e.g. libssl3 built from openssl) the test will not match, see oval_parsed.DebianResult.
+func mapToDebianResult(xmlResult *oval_input.DebianResultXML) (*oval_parsed.DebianResult, error) {
+	r := oval_parsed.NewDebianResult()
+
+	staToTst := make(map[string][]int)
+	objToTst := make(map[string][]int)
+	releaseStaToTst := make(map[string][]int)
+
+	for _, d := range xmlResult.Definitions {
+		if len(d.Vulnerabilities) > 0 {
+			def, err := mapDefinition(d)
+			if err != nil {
+				return nil, fmt.Errorf("mapping definition: %w", err)
+			}
+			r.AddDefinition(*def)
+		}
+	}
+
+	for _, t := range xmlResult.ReleaseTests {
+		id, tst, err := mapTextFileContent54Test(t)
+		if err != nil {
+			return nil, fmt.Errorf("mapping textfilecontent54 test: %w", err)
+		}
+
+		for _, sta := range t.States {
+			releaseStaToTst[sta.Id] = append(releaseStaToTst[sta.Id], id)
+		}
+		r.AddReleaseTest(id, tst)
+	}
+
+	for _, s := range xmlResult.ReleaseStates {
+		sta, err := mapTextFileContent54State(s)
+		if err != nil {
+			return nil, fmt.Errorf("mapping textfilecontent54 state: %w", err)
+		}
+		for _, tId := range releaseStaToTst[s.Id] {
+			t, ok := r.ReleaseTests[tId]
+			if ok {
+				t.States = append(t.States, *sta)
+			} else {
+				return nil, fmt.Errorf("test not found: %d", tId)
+			}
+		}
+	}
+
+	for _, t := range xmlResult.UnameTests {
+		// The architecture of the host is not known, so only the tests asserting that the
+		// uname object exists (used for architecture independent packages) can be evaluated.
+		if len(t.States) > 0 {
+			continue
+		}
+		id, err := extractId(t.Id)
+		if err != nil {
+			return nil, fmt.Errorf("extracting uname test id: %w", err)
+		}
+		r.AddArchTest(id)
+	}
+
+	for _, t := range xmlResult.DpkgInfoTests {
+		id, tst, err := mapDpkgInfoTest(t)
+		if err != nil {
+			return nil, fmt.Errorf("mapping dpkg info test: %w", err)
+		}
+
+		objToTst[t.Object.Id] = append(objToTst[t.Object.Id], id)
+		for _, sta := range t.States {
+			staToTst[sta.Id] = append(staToTst[sta.Id], id)
+		}
+		r.AddPackageTest(id, tst)
+	}
+
+	for _, o := range xmlResult.DpkgInfoObjects {
+		obj, err := mapPackageInfoTestObject(o, xmlResult.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("mapping dpkg info object: %w", err)
+		}
+
+		for _, tId := range objToTst[o.Id] {
+			t, ok := r.PackageTests[tId]
+			if ok {
+				t.Objects = obj
+			} else {
+				return nil, fmt.Errorf("test not found: %d", tId)
+			}
+		}
+	}
+
+	for _, s := range xmlResult.DpkgInfoStates {
+		sta, err := mapDpkgInfoState(s)
+		if err != nil {
+			return nil, fmt.Errorf("mapping dpkg info state: %w", err)
+		}
+		for _, tId := range staToTst[s.Id] {
+			t, ok := r.PackageTests[tId]
+			if ok {
+				t.States = append(t.States, *sta)
+			} else {
+				return nil, fmt.Errorf("test not found: %d", tId)
+			}
+		}
+	}
+
+	return r, nil
+}
@@ -1,10 +1,14 @@
 package oval
 
 import (
+	"compress/bzip2"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
 	oval_input "github.com/it-laborato/MDM_Lab/server/vulnerabilities/oval/input"
 	oval_parsed "github.com/it-laborato/MDM_Lab/server/vulnerabilities/oval/parsed"
 	"github.com/stretchr/testify/require"
@@ -724,6 +728,14 @@ func TestOvalParser(t *testing.T) {
 				Platform: "rhel",
 				Name:     "Fedora Linux 21.0.0",
 			},
+			{
+				Platform: "rocky",
+				Name:     "Rocky Linux 7.9.0",
+			},
+			{
+				Platform: "almalinux",
+				Name:     "AlmaLinux 7.9.0",
+			},
 		}
 
 		for _, tCase := range testCases {
@@ -732,4 +744,474 @@ func TestOvalParser(t *testing.T) {
 			require.True(t, rEval, tCase)
 		}
 	})
+
+	t.Run("RHEL OVAL definitions populate the resolved in version", func(t *testing.T) {
+		r := strings.NewReader(rhelOvalXML)
+
+		xmlResult, err := parseRhelXML(r)
+		require.NoError(t, err)
+
+		result, err := mapToRhelResult(xmlResult)
+		require.NoError(t, err)
+
+		// Overwrite the OS version test so that the definition applies to RHEL 9
+		rhel9 := oval_parsed.NewObjectStateSimpleValue("string", "pattern match", `^9[^\d]`)
+		result.RpmVerifyFileTests[20221728047].State.Version = &rhel9
+
+		software := []mdmlab.Software{
+			{ID: 1, Name: "zlib", Version: "1.2.11", Release: "31.el9", Arch: "x86_64"},
+			{ID: 2, Name: "zlib-devel", Version: "1.2.11", Release: "31.el9_0.1", Arch: "x86_64"},
+		}
+
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "rocky", Name: "Rocky Linux 9.0.0"}, software)
+		require.NoError(t, err)
+		require.Len(t, vulns, 1)
+		require.Equal(t, uint(1), vulns[0].SoftwareID)
+		require.Equal(t, "CVE-2018-25032", vulns[0].CVE)
+		require.NotNil(t, vulns[0].ResolvedInVersion)
+		require.Equal(t, "1.2.11-31.el9_0.1", *vulns[0].ResolvedInVersion)
+	})
+
+	t.Run("RHEL OVAL definitions only report packages earlier than the fixed version", func(t *testing.T) {
+		r := strings.NewReader(rhelOvalXML)
+
+		xmlResult, err := parseRhelXML(r)
+		require.NoError(t, err)
+
+		result, err := mapToRhelResult(xmlResult)
+		require.NoError(t, err)
+
+		rhel9 := oval_parsed.NewObjectStateSimpleValue("string", "pattern match", `^9[^\d]`)
+		result.RpmVerifyFileTests[20221728047].State.Version = &rhel9
+
+		// zlib makes the definition vulnerable, zlib-devel is only matched by the test asserting
+		// that it is signed with the Red Hat key, which doesn't make it vulnerable since it is
+		// already at the fixed version.
+		software := []mdmlab.Software{
+			{ID: 1, Name: "zlib", Version: "1.2.11", Release: "31.el9", Arch: "x86_64"},
+			{ID: 2, Name: "zlib-devel", Version: "1.2.11", Release: "31.el9_0.1", Arch: "x86_64"},
+		}
+
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "rhel", Name: "Red Hat Enterprise Linux 9.0.0"}, software)
+		require.NoError(t, err)
+		require.Equal(t, []mdmlab.SoftwareVulnerability{
+			{SoftwareID: 1, CVE: "CVE-2018-25032", ResolvedInVersion: ptr.String("1.2.11-31.el9_0.1")},
+		}, vulns)
+	})
+
+	t.Run("RHEL OVAL definitions are used for Rocky and Alma hosts", func(t *testing.T) {
+		r := strings.NewReader(rhelOvalXML)
+
+		xmlResult, err := parseRhelXML(r)
+		require.NoError(t, err)
+
+		result, err := mapToRhelResult(xmlResult)
+		require.NoError(t, err)
+
+		rhel9 := oval_parsed.NewObjectStateSimpleValue("string", "pattern match", `^9[^\d]`)
+		result.RpmVerifyFileTests[20221728047].State.Version = &rhel9
+
+		software := []mdmlab.Software{
+			{ID: 1, Name: "zlib", Version: "1.2.11", Release: "31.el9", Arch: "x86_64"},
+		}
+
+		for _, ver := range []mdmlab.OSVersion{
+			{Platform: "rocky", Name: "Rocky Linux 9.3.0"},
+			{Platform: "almalinux", Name: "AlmaLinux 9.3.0"},
+			// osquery can report the rebuilds with the 'rhel' platform
+			{Platform: "rhel", Name: "Rocky Linux 9.3.0"},
+			{Platform: "rhel", Name: "AlmaLinux 9.3.0"},
+		} {
+			require.Equal(t, Platform("rhel_09"), NewPlatform(ver.Platform, ver.Name), ver)
+
+			vulns, err := result.Eval(ver, software)
+			require.NoError(t, err)
+			require.Equal(t, []mdmlab.SoftwareVulnerability{
+				{SoftwareID: 1, CVE: "CVE-2018-25032", ResolvedInVersion: ptr.String("1.2.11-31.el9_0.1")},
+			}, vulns, ver)
+		}
+
+		// the definitions don't apply to another major version of the rebuilds
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "rocky", Name: "Rocky Linux 8.9.0"}, software)
+		require.NoError(t, err)
+		require.Empty(t, vulns)
+	})
+}
+
+func TestOracleOvalParser(t *testing.T) {
+	parseExcerpt := func(t *testing.T) *oval_parsed.RhelResult {
+		f, err := os.Open(filepath.Join("..", "testdata", "oracle", "com.oracle.elsa-ol9-excerpt.xml.bz2"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		xmlResult, err := parseRhelXML(bzip2.NewReader(f))
+		require.NoError(t, err)
+
+		result, err := mapToRhelResult(xmlResult)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Oracle Linux hosts use the ELSA definitions", func(t *testing.T) {
+		for _, c := range []struct {
+			platform  string
+			osVersion string
+			expected  Platform
+		}{
+			{"ol", "Oracle Linux Server 7.9.0", "oracle_07"},
+			{"ol", "Oracle Linux Server 8.9.0", "oracle_08"},
+			{"ol", "Oracle Linux Server 9.3.0", "oracle_09"},
+			{"rhel", "Oracle Linux Server 9.3.0", "oracle_09"},
+		} {
+			plat := NewPlatform(c.platform, c.osVersion)
+			require.Equal(t, c.expected, plat, c)
+			require.True(t, plat.IsSupported(), c)
+			// the ELSA feeds are parsed as RHEL definitions
+			require.True(t, plat.IsRedHat(), c)
+		}
+	})
+
+	t.Run("#parseRhelXML", func(t *testing.T) {
+		result := parseExcerpt(t)
+
+		// ELSA references are not reported
+		require.Len(t, result.Definitions, 2)
+		require.Equal(t, []string{"CVE-2018-25032"}, result.Definitions[0].CveVulnerabilities())
+		require.Equal(t, []string{"CVE-2023-3446", "CVE-2023-3817", "CVE-2023-5678", "CVE-2024-0727"}, result.Definitions[1].CveVulnerabilities())
+
+		// the OS is asserted with rpminfo tests on the release package, there are no
+		// rpmverifyfile tests.
+		require.Len(t, result.RpmInfoTests, 13)
+		require.Empty(t, result.RpmVerifyFileTests)
+		require.Equal(t, []string{"oraclelinux-release"}, result.RpmInfoTests[20224584001].Objects)
+		require.Nil(t, result.RpmInfoTests[20224584001].ResolvedInVersion())
+		require.Equal(t, "1.2.11-31.el9_0.1", *result.RpmInfoTests[20224584006].ResolvedInVersion())
+		require.Equal(t, "3.0.7-27.0.1.el9", *result.RpmInfoTests[20242447005].ResolvedInVersion())
+	})
+
+	t.Run("ELSA definitions are evaluated against the installed packages", func(t *testing.T) {
+		result := parseExcerpt(t)
+
+		software := []mdmlab.Software{
+			{ID: 1, Name: "oraclelinux-release", Version: "9.3", Release: "1.0.4.el9", Arch: "x86_64"},
+			{ID: 2, Name: "zlib", Version: "1.2.11", Release: "31.el9", Arch: "x86_64"},
+			{ID: 3, Name: "openssl", Version: "3.0.7", Release: "27.0.1.el9", Arch: "x86_64"},
+			{ID: 4, Name: "openssl-libs", Version: "3.0.7", Release: "24.0.1.el9", Arch: "x86_64"},
+		}
+
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "ol", Name: "Oracle Linux Server 9.3.0"}, software)
+		require.NoError(t, err)
+
+		// the package tests of every arch branch are reported, the analyzer inserts each
+		// vulnerability once.
+		byKey := make(map[string]mdmlab.SoftwareVulnerability)
+		for _, v := range vulns {
+			byKey[v.Key()] = v
+		}
+		var unique []mdmlab.SoftwareVulnerability
+		for _, v := range byKey {
+			unique = append(unique, v)
+		}
+		// the release package and the already fixed openssl are not vulnerable
+		require.ElementsMatch(t, []mdmlab.SoftwareVulnerability{
+			{SoftwareID: 2, CVE: "CVE-2018-25032", ResolvedInVersion: ptr.String("1.2.11-31.el9_0.1")},
+			{SoftwareID: 4, CVE: "CVE-2023-3446", ResolvedInVersion: ptr.String("3.0.7-27.0.1.el9")},
+			{SoftwareID: 4, CVE: "CVE-2023-3817", ResolvedInVersion: ptr.String("3.0.7-27.0.1.el9")},
+			{SoftwareID: 4, CVE: "CVE-2023-5678", ResolvedInVersion: ptr.String("3.0.7-27.0.1.el9")},
+			{SoftwareID: 4, CVE: "CVE-2024-0727", ResolvedInVersion: ptr.String("3.0.7-27.0.1.el9")},
+		}, unique)
+	})
+
+	t.Run("ELSA definitions only apply to their Oracle Linux release", func(t *testing.T) {
+		result := parseExcerpt(t)
+
+		software := []mdmlab.Software{
+			{ID: 1, Name: "oraclelinux-release", Version: "8.9", Release: "1.0.6.el8", Arch: "x86_64"},
+			{ID: 2, Name: "zlib", Version: "1.2.11", Release: "25.el8", Arch: "x86_64"},
+		}
+
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "ol", Name: "Oracle Linux Server 8.9.0"}, software)
+		require.NoError(t, err)
+		require.Empty(t, vulns)
+	})
+}
+
+func TestDebianOvalParser(t *testing.T) {
+	debianOvalXML := `
+<?xml version="1.0" ?>
+<oval_definitions xmlns="http://oval.mitre.org/XMLSchema/oval-definitions-5" xmlns:ind-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#independent" xmlns:linux-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#linux" xmlns:oval="http://oval.mitre.org/XMLSchema/oval-common-5" xmlns:oval-def="http://oval.mitre.org/XMLSchema/oval-definitions-5" xmlns:unix-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#unix" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://oval.mitre.org/XMLSchema/oval-common-5 oval-common-schema.xsd http://oval.mitre.org/XMLSchema/oval-definitions-5 oval-definitions-schema.xsd">
+	<generator>
+		<oval:product_name>Debian</oval:product_name>
+		<oval:schema_version>5.3</oval:schema_version>
+		<oval:timestamp>2024-11-05T03:22:41.188-04:00</oval:timestamp>
+	</generator>
+	<definitions>
+		<definition class="vulnerability" id="oval:org.debian:def:2023567800" version="1">
+			<metadata>
+				<title>CVE-2023-5678 openssl</title>
+				<affected family="unix">
+					<platform>Debian GNU/Linux 12</platform>
+					<product>openssl</product>
+				</affected>
+				<reference ref_id="CVE-2023-5678" ref_url="https://security-tracker.debian.org/tracker/CVE-2023-5678" source="CVE"/>
+				<description>Issue summary: Generating excessively long X9.42 DH keys</description>
+				<debian>
+					<moreinfo>DSA-5764-1</moreinfo>
+				</debian>
+			</metadata>
+			<criteria comment="Platform section" operator="AND">
+				<criterion comment="Debian 12 is installed" test_ref="oval:org.debian.oval:tst:1"/>
+				<criteria comment="Architecture section" operator="OR">
+					<criterion comment="Installed architecture is all" test_ref="oval:org.debian.oval:tst:2"/>
+				</criteria>
+				<criteria comment="Release section" operator="AND">
+					<criterion comment="openssl DPKG is earlier than 0:3.0.11-1~deb12u2" test_ref="oval:org.debian.oval:tst:3"/>
+				</criteria>
+			</criteria>
+		</definition>
+		<definition class="vulnerability" id="oval:org.debian:def:2023385450" version="1">
+			<metadata>
+				<title>CVE-2023-38545 curl</title>
+				<affected family="unix">
+					<platform>Debian GNU/Linux 12</platform>
+					<product>curl</product>
+				</affected>
+				<reference ref_id="CVE-2023-38545" ref_url="https://security-tracker.debian.org/tracker/CVE-2023-38545" source="CVE"/>
+				<description>SOCKS5 heap buffer overflow</description>
+			</metadata>
+			<criteria comment="Platform section" operator="AND">
+				<criterion comment="Debian 12 is installed" test_ref="oval:org.debian.oval:tst:1"/>
+				<criteria comment="Architecture section" operator="OR">
+					<criterion comment="Installed architecture is all" test_ref="oval:org.debian.oval:tst:2"/>
+				</criteria>
+				<criteria comment="Release section" operator="AND">
+					<criterion comment="curl DPKG is earlier than 0:7.88.1-10+deb12u4" test_ref="oval:org.debian.oval:tst:4"/>
+				</criteria>
+			</criteria>
+		</definition>
+		<definition class="vulnerability" id="oval:org.debian:def:2023503870" version="1">
+			<metadata>
+				<title>CVE-2023-50387 bind9</title>
+				<affected family="unix">
+					<platform>Debian GNU/Linux 12</platform>
+					<product>bind9</product>
+				</affected>
+				<reference ref_id="CVE-2023-50387" ref_url="https://security-tracker.debian.org/tracker/CVE-2023-50387" source="CVE"/>
+				<description>KeyTrap</description>
+			</metadata>
+			<criteria comment="Platform section" operator="AND">
+				<criterion comment="Debian 12 is installed" test_ref="oval:org.debian.oval:tst:1"/>
+				<criteria comment="Architecture section" operator="OR">
+					<criterion comment="Installed architecture is all" test_ref="oval:org.debian.oval:tst:2"/>
+				</criteria>
+				<criteria comment="Release section" operator="AND">
+					<criterion comment="bind9 DPKG is earlier than 1:9.18.24-1" test_ref="oval:org.debian.oval:tst:5"/>
+				</criteria>
+			</criteria>
+		</definition>
+	</definitions>
+	<tests>
+		<ind-def:textfilecontent54_test check="all" check_existence="at_least_one_exists" comment="Debian GNU/Linux 12 is installed" id="oval:org.debian.oval:tst:1" version="1">
+			<ind-def:object object_ref="oval:org.debian.oval:obj:1"/>
+			<ind-def:state state_ref="oval:org.debian.oval:ste:1"/>
+		</ind-def:textfilecontent54_test>
+		<unix-def:uname_test check="all" check_existence="all_exist" comment="Installed architecture is all" id="oval:org.debian.oval:tst:2" version="1">
+			<unix-def:object object_ref="oval:org.debian.oval:obj:2"/>
+		</unix-def:uname_test>
+		<linux-def:dpkginfo_test check="all" check_existence="at_least_one_exists" comment="openssl is earlier than 0:3.0.11-1~deb12u2" id="oval:org.debian.oval:tst:3" version="1">
+			<linux-def:object object_ref="oval:org.debian.oval:obj:3"/>
+			<linux-def:state state_ref="oval:org.debian.oval:ste:2"/>
+		</linux-def:dpkginfo_test>
+		<linux-def:dpkginfo_test check="all" check_existence="at_least_one_exists" comment="curl is earlier than 0:7.88.1-10+deb12u4" id="oval:org.debian.oval:tst:4" version="1">
+			<linux-def:object object_ref="oval:org.debian.oval:obj:4"/>
+			<linux-def:state state_ref="oval:org.debian.oval:ste:3"/>
+		</linux-def:dpkginfo_test>
+		<linux-def:dpkginfo_test check="all" check_existence="at_least_one_exists" comment="bind9 is earlier than 1:9.18.24-1" id="oval:org.debian.oval:tst:5" version="1">
+			<linux-def:object object_ref="oval:org.debian.oval:obj:5"/>
+			<linux-def:state state_ref="oval:org.debian.oval:ste:4"/>
+		</linux-def:dpkginfo_test>
+	</tests>
+	<objects>
+		<ind-def:textfilecontent54_object id="oval:org.debian.oval:obj:1" version="1">
+			<ind-def:path>/etc</ind-def:path>
+			<ind-def:filename>debian_version</ind-def:filename>
+			<ind-def:pattern operation="pattern match">(\d+)\.\d</ind-def:pattern>
+			<ind-def:instance datatype="int">1</ind-def:instance>
+		</ind-def:textfilecontent54_object>
+		<unix-def:uname_object id="oval:org.debian.oval:obj:2" version="1"/>
+		<linux-def:dpkginfo_object id="oval:org.debian.oval:obj:3" version="1">
+			<linux-def:name>openssl</linux-def:name>
+		</linux-def:dpkginfo_object>
+		<linux-def:dpkginfo_object id="oval:org.debian.oval:obj:4" version="1">
+			<linux-def:name>curl</linux-def:name>
+		</linux-def:dpkginfo_object>
+		<linux-def:dpkginfo_object id="oval:org.debian.oval:obj:5" version="1">
+			<linux-def:name>bind9</linux-def:name>
+		</linux-def:dpkginfo_object>
+	</objects>
+	<states>
+		<ind-def:textfilecontent54_state id="oval:org.debian.oval:ste:1" version="1">
+			<ind-def:subexpression operation="equals">12</ind-def:subexpression>
+		</ind-def:textfilecontent54_state>
+		<linux-def:dpkginfo_state id="oval:org.debian.oval:ste:2" version="1">
+			<linux-def:evr datatype="debian_evr_string" operation="less than">0:3.0.11-1~deb12u2</linux-def:evr>
+		</linux-def:dpkginfo_state>
+		<linux-def:dpkginfo_state id="oval:org.debian.oval:ste:3" version="1">
+			<linux-def:evr datatype="debian_evr_string" operation="less than">0:7.88.1-10+deb12u4</linux-def:evr>
+		</linux-def:dpkginfo_state>
+		<linux-def:dpkginfo_state id="oval:org.debian.oval:ste:4" version="1">
+			<linux-def:evr datatype="debian_evr_string" operation="less than">1:9.18.24-1</linux-def:evr>
+		</linux-def:dpkginfo_state>
+	</states>
+</oval_definitions>
+`
+	t.Run("#parseDebianXML", func(t *testing.T) {
+		result, err := parseDebianXML(strings.NewReader(debianOvalXML))
+		require.NoError(t, err)
+
+		require.Len(t, result.Definitions, 3)
+		require.Equal(t, "oval:org.debian:def:2023567800", result.Definitions[0].Id)
+		require.ElementsMatch(t, result.Definitions[0].Vulnerabilities, []oval_input.ReferenceXML{
+			{Id: "CVE-2023-5678"},
+		})
+		require.Equal(t, "AND", result.Definitions[0].Criteria.Operator)
+		require.Equal(t, "oval:org.debian.oval:tst:1", result.Definitions[0].Criteria.Criteriums[0].TestId)
+		require.Len(t, result.Definitions[0].Criteria.Criterias, 2)
+
+		require.Len(t, result.ReleaseTests, 1)
+		require.Equal(t, "oval:org.debian.oval:tst:1", result.ReleaseTests[0].Id)
+		require.Equal(t, "all", result.ReleaseTests[0].Check)
+		require.Equal(t, "oval:org.debian.oval:ste:1", result.ReleaseTests[0].States[0].Id)
+
+		require.Len(t, result.ReleaseStates, 1)
+		require.Equal(t, "oval:org.debian.oval:ste:1", result.ReleaseStates[0].Id)
+		require.Equal(t, "equals", result.ReleaseStates[0].Subexpression.Op)
+		require.Equal(t, "12", result.ReleaseStates[0].Subexpression.Value)
+
+		require.Len(t, result.UnameTests, 1)
+		require.Equal(t, "oval:org.debian.oval:tst:2", result.UnameTests[0].Id)
+		require.Empty(t, result.UnameTests[0].States)
+
+		require.Len(t, result.DpkgInfoTests, 3)
+		require.Equal(t, "oval:org.debian.oval:tst:3", result.DpkgInfoTests[0].Id)
+		require.Equal(t, "oval:org.debian.oval:obj:3", result.DpkgInfoTests[0].Object.Id)
+		require.Equal(t, "oval:org.debian.oval:ste:2", result.DpkgInfoTests[0].States[0].Id)
+
+		require.Len(t, result.DpkgInfoObjects, 3)
+		require.Equal(t, "openssl", result.DpkgInfoObjects[0].Name.Value)
+
+		require.Len(t, result.DpkgInfoStates, 3)
+		require.Equal(t, "debian_evr_string", result.DpkgInfoStates[0].Evr.Datatype)
+		require.Equal(t, "less than", result.DpkgInfoStates[0].Evr.Op)
+		require.Equal(t, "0:3.0.11-1~deb12u2", result.DpkgInfoStates[0].Evr.Value)
+	})
+
+	t.Run("#mapToDebianResult", func(t *testing.T) {
+		xmlResult, err := parseDebianXML(strings.NewReader(debianOvalXML))
+		require.NoError(t, err)
+
+		result, err := mapToDebianResult(xmlResult)
+		require.NoError(t, err)
+
+		require.Len(t, result.Definitions, 3)
+		require.Equal(t, []string{"CVE-2023-5678"}, result.Definitions[0].Vulnerabilities)
+		require.ElementsMatch(t, result.Definitions[0].CollectTestIds(), []int{1, 2, 3})
+
+		require.Len(t, result.ReleaseTests, 1)
+		require.Equal(t, []oval_parsed.ObjectStateSimpleValue{
+			oval_parsed.NewObjectStateSimpleValue("", "equals", "12"),
+		}, result.ReleaseTests[1].States)
+		require.Equal(t, []int{2}, result.ArchTests)
+
+		require.Len(t, result.PackageTests, 3)
+		tst, ok := result.PackageTests[5]
+		require.True(t, ok)
+		require.Equal(t, []string{"bind9"}, tst.Objects)
+		require.Equal(t, []oval_parsed.ObjectStateEvrString{
+			oval_parsed.NewObjectStateEvrString("less than", "1:9.18.24-1"),
+		}, tst.States)
+	})
+
+	t.Run("Debian OVAL definitions are evaluated using debian_evr_string", func(t *testing.T) {
+		xmlResult, err := parseDebianXML(strings.NewReader(debianOvalXML))
+		require.NoError(t, err)
+
+		result, err := mapToDebianResult(xmlResult)
+		require.NoError(t, err)
+
+		software := []mdmlab.Software{
+			// 3.0.11-1~deb12u1 < 3.0.11-1~deb12u2
+			{ID: 1, Name: "openssl", Version: "3.0.11-1~deb12u1", Source: "deb_packages"},
+			// Already patched
+			{ID: 2, Name: "curl", Version: "7.88.1-10+deb12u5", Source: "deb_packages"},
+			// Epoch must be taken into account
+			{ID: 3, Name: "bind9", Version: "1:9.18.19-1~deb12u1", Source: "deb_packages"},
+			{ID: 4, Name: "vim", Version: "2:9.0.1378-2", Source: "deb_packages"},
+		}
+
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "debian", Name: "Debian GNU/Linux 12.5.0"}, software)
+		require.NoError(t, err)
+		require.ElementsMatch(t, vulns, []mdmlab.SoftwareVulnerability{
+			{SoftwareID: 1, CVE: "CVE-2023-5678", ResolvedInVersion: ptr.String("3.0.11-1~deb12u2")},
+			// Non-zero epochs are part of the version reported by dpkg
+			{SoftwareID: 3, CVE: "CVE-2023-50387", ResolvedInVersion: ptr.String("1:9.18.24-1")},
+		})
+
+		// The definitions only apply to the release targeted by the file
+		vulns, err = result.Eval(mdmlab.OSVersion{Platform: "debian", Name: "Debian GNU/Linux 11.9.0"}, software)
+		require.NoError(t, err)
+		require.Empty(t, vulns)
+	})
+
+	t.Run("Debian architecture tests with a state are not matched", func(t *testing.T) {
+		ovalXML := strings.Replace(debianOvalXML,
+			`<unix-def:object object_ref="oval:org.debian.oval:obj:2"/>`,
+			`<unix-def:object object_ref="oval:org.debian.oval:obj:2"/>
+			<unix-def:state state_ref="oval:org.debian.oval:ste:5"/>`, 1)
+		xmlResult, err := parseDebianXML(strings.NewReader(ovalXML))
+		require.NoError(t, err)
+
+		result, err := mapToDebianResult(xmlResult)
+		require.NoError(t, err)
+		require.Empty(t, result.ArchTests)
+
+		software := []mdmlab.Software{
+			{ID: 1, Name: "openssl", Version: "3.0.11-1~deb12u1", Source: "deb_packages"},
+		}
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "debian", Name: "Debian GNU/Linux 12.5.0"}, software)
+		require.NoError(t, err)
+		require.Empty(t, vulns)
+	})
+
+	t.Run("Debian security tracker OVAL excerpt", func(t *testing.T) {
+		f, err := os.Open(filepath.Join("..", "testdata", "debian", "oval-definitions-bookworm-excerpt.xml.bz2"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		xmlResult, err := parseDebianXML(bzip2.NewReader(f))
+		require.NoError(t, err)
+
+		result, err := mapToDebianResult(xmlResult)
+		require.NoError(t, err)
+
+		// DSA references are not reported
+		require.Len(t, result.Definitions, 3)
+		require.Equal(t, []string{"CVE-2023-38545"}, result.Definitions[0].CveVulnerabilities())
+
+		software := []mdmlab.Software{
+			{ID: 1, Name: "curl", Version: "7.88.1-10+deb12u3", Source: "deb_packages"},
+			{ID: 2, Name: "openssl", Version: "3.0.11-1~deb12u2", Source: "deb_packages"},
+			// Known limitation: libssl3 is built from the openssl source package, which the OVAL
+			// tests target, so it is not matched.
+			{ID: 3, Name: "libssl3", Version: "3.0.11-1~deb12u2", Source: "deb_packages"},
+			{ID: 4, Name: "bind9", Version: "1:9.18.24-1", Source: "deb_packages"},
+		}
+
+		vulns, err := result.Eval(mdmlab.OSVersion{Platform: "debian", Name: "Debian GNU/Linux 12.7.0"}, software)
+		require.NoError(t, err)
+		require.ElementsMatch(t, vulns, []mdmlab.SoftwareVulnerability{
+			{SoftwareID: 1, CVE: "CVE-2023-38545", ResolvedInVersion: ptr.String("7.88.1-10+deb12u4")},
+			{SoftwareID: 2, CVE: "CVE-2023-5678", ResolvedInVersion: ptr.String("3.0.14-1~deb12u1")},
+		})
+	})
 }
@@ -97,6 +97,7 @@ func Sync(dstDir string, platforms []Platform) error {
 	if err != nil {
 		return fmt.Errorf("getOvalSources: %w", err)
 	}
+	addUpstreamSources(sources)
 
 	if platforms == nil {
 		for s := range sources {
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Debvercmp compares two Debian version strings ([EPOCH:]UPSTREAM_VERSION[-DEBIAN_REVISION]) following
+// dpkg's algorithm (see https://www.debian.org/doc/debian-policy/ch-controlfields.html#version):
+//   - EPOCHs are compared based on their numeric values, if missing then '0' is assumed.
+//   - UPSTREAM_VERSIONs are compared using dpkg's verrevcmp algorithm, if equal then
+//     DEBIAN_REVISIONs are compared using the same algorithm.
+//
+// Unlike rpmvercmp, a tilde sorts before anything, even the end of the version string, so
+// '1.0~rc1' < '1.0'.
+//
+// Returns:
+//
+//	-1 if a < b
+//	0 if a == b
+//	1 if a > b
+func Debvercmp(a, b string) int {
+	epoch1, upstream1, revision1 := debVersionParts(a)
+	epoch2, upstream2, revision2 := debVersionParts(b)
+
+	if epoch1 < epoch2 {
+		return -1
+	} else if epoch1 > epoch2 {
+		return 1
+	}
+
+	if r := debVerRevCmp(upstream1, upstream2); r != 0 {
+		return r
+	}
+
+	return debVerRevCmp(revision1, revision2)
+}
+
+// debVersionParts splits a Debian version string into its epoch, upstream version and revision.
+// The epoch is everything before the first ':' and the revision everything after the last '-'.
+func debVersionParts(v string) (int, string, string) {
+	v = strings.TrimSpace(v)
+
+	var epoch int
+	if e, rest, found := strings.Cut(v, ":"); found {
+		if n, err := strconv.Atoi(e); err == nil {
+			epoch = n
+		}
+		v = rest
+	}
+
+	var revision string
+	if idx := strings.LastIndex(v, "-"); idx != -1 {
+		revision = v[idx+1:]
+		v = v[:idx]
+	}
+
+	return epoch, v, revision
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// debCharOrder returns the weight of 'c' when comparing the non-digit parts of a version: the
+// end of the string and digits weigh nothing, letters sort earlier than non-letters and tildes sort
+// before everything.
+func debCharOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// debVerRevCmp is a port of dpkg's verrevcmp, comparing alternating runs of non-digits
+// (lexically, using debCharOrder) and digits (numerically).
+func debVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac := debCharOrder(a, i)
+			bc := debCharOrder(b, j)
+			if ac != bc {
+				if ac < bc {
+					return -1
+				}
+				return 1
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff < 0 {
+			return -1
+		}
+		if firstDiff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
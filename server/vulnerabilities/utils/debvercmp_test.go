@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebVersionParts(t *testing.T) {
+	cases := []struct {
+		v        string
+		epoch    int
+		upstream string
+		revision string
+	}{
+		{"", 0, "", ""},
+		{"1.0", 0, "1.0", ""},
+		{"0:1.0", 0, "1.0", ""},
+		{"1:1.0-1", 1, "1.0", "1"},
+		{"1.0-1-2", 0, "1.0-1", "2"},
+		{"2:1.0:1-1", 2, "1.0:1", "1"},
+		{"3.0.11-1~deb12u1", 0, "3.0.11", "1~deb12u1"},
+		{" 1:9.18.19-1~deb12u1 ", 1, "9.18.19", "1~deb12u1"},
+	}
+
+	for _, c := range cases {
+		epoch, upstream, revision := debVersionParts(c.v)
+		require.Equal(t, c.epoch, epoch, c.v)
+		require.Equal(t, c.upstream, upstream, c.v)
+		require.Equal(t, c.revision, revision, c.v)
+	}
+}
+
+func TestDebvercmp(t *testing.T) {
+	const (
+		LESS    = -1
+		EQUAL   = 0
+		GREATER = 1
+	)
+
+	cases := []struct {
+		a        string
+		expected int
+		b        string
+	}{
+		{"", EQUAL, ""},
+		{"1.0", EQUAL, "1.0"},
+		{"1.0", EQUAL, "0:1.0"},
+		{"1.0", LESS, "1.1"},
+		{"1.2", LESS, "1.10"},
+		{"1.01", EQUAL, "1.1"},
+		{"1.0", LESS, "1.0-1"},
+		{"1.0-1", LESS, "1.0-2"},
+		{"1.0-10", GREATER, "1.0-9"},
+		{"1:1.0", GREATER, "2.0"},
+		{"1:1.0", LESS, "2:0.1"},
+		{"1.0~rc1", LESS, "1.0"},
+		{"1.0~rc1", LESS, "1.0~rc2"},
+		{"1.0~~", LESS, "1.0~"},
+		{"1.0~", LESS, "1.0"},
+		{"1.0", LESS, "1.0a"},
+		{"1.0a", LESS, "1.0+"},
+		{"1.0+", LESS, "1.0.1"},
+		{"1.0.", GREATER, "1.0"},
+		{"1.0a", LESS, "1.0b"},
+		{"1.0B", LESS, "1.0a"},
+		{"3.0.11-1~deb12u1", LESS, "3.0.11-1~deb12u2"},
+		{"3.0.11-1~deb12u2", LESS, "3.0.11-1"},
+		{"3.0.9-1", LESS, "0:3.0.11-1~deb12u1"},
+		{"3.0.13-1~deb12u1", GREATER, "0:3.0.11-1~deb12u1"},
+		{"7.88.1-10+deb12u5", LESS, "7.88.1-10+deb12u7"},
+		{"1:9.18.24-1", GREATER, "1:9.18.19-1~deb12u1"},
+		{"9.18.24-1", LESS, "1:9.18.19-1~deb12u1"},
+		{"2.36-9+deb12u4", GREATER, "2.36-9+deb12u3"},
+		{"1.2.3+dfsg-1", GREATER, "1.2.3-1"},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.expected, Debvercmp(c.a, c.b), "comparing '%s' vs '%s' should be %d but was %d", c.a, c.b, c.expected, Debvercmp(c.a, c.b))
+		require.Equal(t, -c.expected, Debvercmp(c.b, c.a), "comparing '%s' vs '%s' should be %d", c.b, c.a, -c.expected)
+	}
+}
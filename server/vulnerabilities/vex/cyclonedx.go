@@ -0,0 +1,143 @@
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+const (
+	cycloneDXSpecVersion      = "1.5"
+	cycloneDXStateNotAffected = "not_affected"
+)
+
+type cycloneDXDocument struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber,omitempty"`
+	Version         int                      `json:"version"`
+	Metadata        *cycloneDXMetadata       `json:"metadata,omitempty"`
+	Vulnerabilities []cycloneDXVulnerability `json:"vulnerabilities"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Authors   []cycloneDXAuthor `json:"authors,omitempty"`
+}
+
+type cycloneDXAuthor struct {
+	Name string `json:"name"`
+}
+
+type cycloneDXVulnerability struct {
+	ID       string             `json:"id"`
+	Analysis *cycloneDXAnalysis `json:"analysis,omitempty"`
+	Affects  []cycloneDXAffects `json:"affects,omitempty"`
+}
+
+type cycloneDXAnalysis struct {
+	State         string `json:"state"`
+	Justification string `json:"justification,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+type cycloneDXAffects struct {
+	Ref string `json:"ref"`
+}
+
+// CycloneDX defines its own set of justifications, these are mapped to the closest OpenVEX
+// justification.
+var (
+	cycloneDXToJustification = map[string]mdmlab.VEXJustification{
+		"code_not_present":                mdmlab.VEXJustificationVulnerableCodeNotPresent,
+		"code_not_reachable":              mdmlab.VEXJustificationVulnerableCodeNotInExecutePath,
+		"requires_dependency":             mdmlab.VEXJustificationComponentNotPresent,
+		"requires_configuration":          mdmlab.VEXJustificationVulnerableCodeCannotBeControlledByAdversary,
+		"requires_environment":            mdmlab.VEXJustificationVulnerableCodeCannotBeControlledByAdversary,
+		"protected_by_compiler":           mdmlab.VEXJustificationInlineMitigationsAlreadyExist,
+		"protected_at_runtime":            mdmlab.VEXJustificationInlineMitigationsAlreadyExist,
+		"protected_at_perimeter":          mdmlab.VEXJustificationInlineMitigationsAlreadyExist,
+		"protected_by_mitigating_control": mdmlab.VEXJustificationInlineMitigationsAlreadyExist,
+	}
+	justificationToCycloneDX = map[mdmlab.VEXJustification]string{
+		mdmlab.VEXJustificationComponentNotPresent:                         "requires_dependency",
+		mdmlab.VEXJustificationVulnerableCodeNotPresent:                    "code_not_present",
+		mdmlab.VEXJustificationVulnerableCodeNotInExecutePath:              "code_not_reachable",
+		mdmlab.VEXJustificationVulnerableCodeCannotBeControlledByAdversary: "requires_environment",
+		mdmlab.VEXJustificationInlineMitigationsAlreadyExist:               "protected_by_mitigating_control",
+	}
+)
+
+func parseCycloneDX(b []byte) ([]*mdmlab.VulnerabilitySuppression, error) {
+	var doc cycloneDXDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX document: %w", err)
+	}
+
+	var suppressions []*mdmlab.VulnerabilitySuppression
+	for i, v := range doc.Vulnerabilities {
+		if v.Analysis == nil || v.Analysis.State != cycloneDXStateNotAffected {
+			continue
+		}
+		justification, ok := cycloneDXToJustification[v.Analysis.Justification]
+		if !ok {
+			return nil, fmt.Errorf("vulnerability %d: unsupported justification %q", i, v.Analysis.Justification)
+		}
+
+		products := make([]string, 0, len(v.Affects))
+		for _, a := range v.Affects {
+			products = append(products, a.Ref)
+		}
+		s, err := suppressionsForProducts(v.ID, products, justification, v.Analysis.Detail)
+		if err != nil {
+			return nil, fmt.Errorf("vulnerability %d: %w", i, err)
+		}
+		suppressions = append(suppressions, s...)
+	}
+	return suppressions, nil
+}
+
+func exportCycloneDX(suppressions []*mdmlab.VulnerabilitySuppression, author string, now time.Time) ([]byte, error) {
+	doc := cycloneDXDocument{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     cycloneDXSpecVersion,
+		SerialNumber:    "urn:uuid:" + uuid.NewString(),
+		Version:         1,
+		Metadata:        &cycloneDXMetadata{Timestamp: now.UTC()},
+		Vulnerabilities: []cycloneDXVulnerability{},
+	}
+	if author != "" {
+		doc.Metadata.Authors = []cycloneDXAuthor{{Name: author}}
+	}
+
+	type vulnKey struct {
+		cve, justification, comment string
+	}
+	byKey := make(map[vulnKey]int)
+	for _, s := range suppressions {
+		key := vulnKey{s.CVE, string(s.Justification), s.Comment}
+		i, ok := byKey[key]
+		if !ok {
+			i = len(doc.Vulnerabilities)
+			byKey[key] = i
+			doc.Vulnerabilities = append(doc.Vulnerabilities, cycloneDXVulnerability{
+				ID: s.CVE,
+				Analysis: &cycloneDXAnalysis{
+					State:         cycloneDXStateNotAffected,
+					Justification: justificationToCycloneDX[s.Justification],
+					Detail:        s.Comment,
+				},
+			})
+		}
+		doc.Vulnerabilities[i].Affects = append(doc.Vulnerabilities[i].Affects, cycloneDXAffects{Ref: productID(s)})
+	}
+	sort.SliceStable(doc.Vulnerabilities, func(i, j int) bool {
+		return doc.Vulnerabilities[i].ID < doc.Vulnerabilities[j].ID
+	})
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,122 @@
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+const (
+	openVEXContext           = "https://openvex.dev/ns/v0.2.0"
+	openVEXStatusNotAffected = "not_affected"
+)
+
+type openVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Version    int                `json:"version"`
+	Tooling    string             `json:"tooling,omitempty"`
+	Statements []openVEXStatement `json:"statements"`
+}
+
+type openVEXStatement struct {
+	Vulnerability   openVEXVulnerability `json:"vulnerability"`
+	Products        []openVEXProduct     `json:"products,omitempty"`
+	Status          string               `json:"status"`
+	Justification   string               `json:"justification,omitempty"`
+	ImpactStatement string               `json:"impact_statement,omitempty"`
+}
+
+type openVEXVulnerability struct {
+	Name string `json:"name"`
+}
+
+// UnmarshalJSON supports both the vulnerability object of OpenVEX v0.2 and the plain string
+// used by earlier versions of the specification.
+func (v *openVEXVulnerability) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		v.Name = name
+		return nil
+	}
+	type alias openVEXVulnerability
+	return json.Unmarshal(b, (*alias)(v))
+}
+
+type openVEXProduct struct {
+	ID string `json:"@id"`
+}
+
+func parseOpenVEX(b []byte) ([]*mdmlab.VulnerabilitySuppression, error) {
+	var doc openVEXDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenVEX document: %w", err)
+	}
+
+	var suppressions []*mdmlab.VulnerabilitySuppression
+	for i, st := range doc.Statements {
+		if st.Status != openVEXStatusNotAffected {
+			continue
+		}
+		justification := mdmlab.VEXJustification(st.Justification)
+		if !justification.IsValid() {
+			return nil, fmt.Errorf("statement %d: unsupported justification %q", i, st.Justification)
+		}
+
+		products := make([]string, 0, len(st.Products))
+		for _, p := range st.Products {
+			products = append(products, p.ID)
+		}
+		s, err := suppressionsForProducts(st.Vulnerability.Name, products, justification, st.ImpactStatement)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i, err)
+		}
+		suppressions = append(suppressions, s...)
+	}
+	return suppressions, nil
+}
+
+func exportOpenVEX(suppressions []*mdmlab.VulnerabilitySuppression, author string, now time.Time) ([]byte, error) {
+	doc := openVEXDocument{
+		Context:    openVEXContext,
+		ID:         "urn:uuid:" + uuid.NewString(),
+		Author:     author,
+		Timestamp:  now.UTC(),
+		Version:    1,
+		Tooling:    "mdmlab",
+		Statements: []openVEXStatement{},
+	}
+
+	// group the suppressions sharing the same statement in a single statement with multiple
+	// products
+	type statementKey struct {
+		cve, justification, comment string
+	}
+	byKey := make(map[statementKey]int)
+	for _, s := range suppressions {
+		key := statementKey{s.CVE, string(s.Justification), s.Comment}
+		i, ok := byKey[key]
+		if !ok {
+			i = len(doc.Statements)
+			byKey[key] = i
+			doc.Statements = append(doc.Statements, openVEXStatement{
+				Vulnerability:   openVEXVulnerability{Name: s.CVE},
+				Status:          openVEXStatusNotAffected,
+				Justification:   string(s.Justification),
+				ImpactStatement: s.Comment,
+			})
+		}
+		doc.Statements[i].Products = append(doc.Statements[i].Products, openVEXProduct{ID: productID(s)})
+	}
+	sort.SliceStable(doc.Statements, func(i, j int) bool {
+		return doc.Statements[i].Vulnerability.Name < doc.Statements[j].Vulnerability.Name
+	})
+
+	return json.MarshalIndent(doc, "", "  ")
+}
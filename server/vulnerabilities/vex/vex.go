@@ -0,0 +1,99 @@
+// Package vex converts vulnerability suppressions to and from VEX (Vulnerability Exploitability
+// eXchange) documents, in the OpenVEX and CycloneDX formats.
+//
+// Only "not affected" statements are mapped to suppressions. The products of the statements are
+// identified by the scope of the suppression prefixed with "mdmlab:", e.g. "mdmlab:global" or
+// "mdmlab:software_title/12".
+package vex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+// Format is a supported VEX document format.
+type Format string
+
+const (
+	FormatOpenVEX   Format = "openvex"
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// productIDPrefix is the prefix of the product identifiers of the statements.
+const productIDPrefix = "mdmlab:"
+
+// ParseFormat returns the format matching the provided name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatOpenVEX, FormatCycloneDX:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported VEX format %q", s)
+}
+
+// Parse returns the suppressions described by the "not affected" statements of the OpenVEX or
+// CycloneDX document. Statements with any other status are ignored.
+func Parse(b []byte) ([]*mdmlab.VulnerabilitySuppression, error) {
+	var probe struct {
+		Context   string `json:"@context"`
+		BOMFormat string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, fmt.Errorf("invalid VEX document: %w", err)
+	}
+
+	switch {
+	case strings.Contains(probe.Context, "openvex"):
+		return parseOpenVEX(b)
+	case probe.BOMFormat == "CycloneDX":
+		return parseCycloneDX(b)
+	}
+	return nil, errors.New("unsupported VEX document: expected an OpenVEX or CycloneDX document")
+}
+
+// Export returns the suppressions as a VEX document in the given format. Expiration dates are
+// not part of the VEX formats and are not exported.
+func Export(format Format, suppressions []*mdmlab.VulnerabilitySuppression, author string, now time.Time) ([]byte, error) {
+	switch format {
+	case FormatOpenVEX:
+		return exportOpenVEX(suppressions, author, now)
+	case FormatCycloneDX:
+		return exportCycloneDX(suppressions, author, now)
+	}
+	return nil, fmt.Errorf("unsupported VEX format %q", format)
+}
+
+func productID(s *mdmlab.VulnerabilitySuppression) string {
+	return productIDPrefix + s.ScopeKey()
+}
+
+// suppressionsForProducts returns a suppression for each of the products. A statement without
+// products is a global suppression.
+func suppressionsForProducts(cve string, products []string, justification mdmlab.VEXJustification, comment string) ([]*mdmlab.VulnerabilitySuppression, error) {
+	if len(products) == 0 {
+		products = []string{productIDPrefix + mdmlab.VulnerabilitySuppressionScopeGlobal}
+	}
+
+	suppressions := make([]*mdmlab.VulnerabilitySuppression, 0, len(products))
+	for _, p := range products {
+		key, ok := strings.CutPrefix(p, productIDPrefix)
+		if !ok {
+			return nil, fmt.Errorf("unsupported product %q for %s: products must be identified as %q followed by the suppression scope", p, cve, productIDPrefix)
+		}
+		s := &mdmlab.VulnerabilitySuppression{
+			CVE:           strings.ToUpper(strings.TrimSpace(cve)),
+			Justification: justification,
+			Comment:       comment,
+		}
+		if err := s.SetScopeFromKey(key); err != nil {
+			return nil, fmt.Errorf("unsupported product %q for %s: %w", p, cve, err)
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions, nil
+}
@@ -0,0 +1,147 @@
+package vex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenVEX(t *testing.T) {
+	doc := `{
+		"@context": "https://openvex.dev/ns/v0.2.0",
+		"@id": "https://example.com/vex/1",
+		"author": "security@example.com",
+		"timestamp": "2025-01-30T10:00:00Z",
+		"version": 1,
+		"statements": [
+			{
+				"vulnerability": {"name": "CVE-2024-0001"},
+				"products": [{"@id": "mdmlab:team/1"}, {"@id": "mdmlab:host/7"}],
+				"status": "not_affected",
+				"justification": "vulnerable_code_not_in_execute_path",
+				"impact_statement": "feature disabled"
+			},
+			{
+				"vulnerability": "cve-2024-0002",
+				"status": "not_affected",
+				"justification": "component_not_present"
+			},
+			{
+				"vulnerability": {"name": "CVE-2024-0003"},
+				"products": [{"@id": "mdmlab:global"}],
+				"status": "affected"
+			}
+		]
+	}`
+
+	suppressions, err := Parse([]byte(doc))
+	require.NoError(t, err)
+	require.Len(t, suppressions, 3)
+
+	require.Equal(t, "CVE-2024-0001", suppressions[0].CVE)
+	require.Equal(t, ptr.Uint(1), suppressions[0].TeamID)
+	require.Equal(t, mdmlab.VEXJustificationVulnerableCodeNotInExecutePath, suppressions[0].Justification)
+	require.Equal(t, "feature disabled", suppressions[0].Comment)
+	require.Equal(t, ptr.Uint(7), suppressions[1].HostID)
+	require.Nil(t, suppressions[1].TeamID)
+
+	// statements without products are global
+	require.Equal(t, "CVE-2024-0002", suppressions[2].CVE)
+	require.Equal(t, mdmlab.VulnerabilitySuppressionScopeGlobal, suppressions[2].ScopeKey())
+
+	_, err = Parse([]byte(`{
+		"@context": "https://openvex.dev/ns/v0.2.0",
+		"statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "products": [{"@id": "pkg:npm/foo@1.0.0"}], "status": "not_affected", "justification": "component_not_present"}]
+	}`))
+	require.ErrorContains(t, err, "unsupported product")
+
+	_, err = Parse([]byte(`{
+		"@context": "https://openvex.dev/ns/v0.2.0",
+		"statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "status": "not_affected", "justification": "not_a_justification"}]
+	}`))
+	require.ErrorContains(t, err, "unsupported justification")
+}
+
+func TestParseCycloneDX(t *testing.T) {
+	doc := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"version": 1,
+		"vulnerabilities": [
+			{
+				"id": "CVE-2024-0001",
+				"analysis": {"state": "not_affected", "justification": "code_not_reachable", "detail": "not used"},
+				"affects": [{"ref": "mdmlab:software_title/3"}]
+			},
+			{
+				"id": "CVE-2024-0002",
+				"analysis": {"state": "exploitable"}
+			},
+			{
+				"id": "CVE-2024-0003"
+			}
+		]
+	}`
+
+	suppressions, err := Parse([]byte(doc))
+	require.NoError(t, err)
+	require.Len(t, suppressions, 1)
+	require.Equal(t, "CVE-2024-0001", suppressions[0].CVE)
+	require.Equal(t, ptr.Uint(3), suppressions[0].SoftwareTitleID)
+	require.Equal(t, mdmlab.VEXJustificationVulnerableCodeNotInExecutePath, suppressions[0].Justification)
+	require.Equal(t, "not used", suppressions[0].Comment)
+
+	_, err = Parse([]byte(`{"bomFormat": "SPDX"}`))
+	require.ErrorContains(t, err, "unsupported VEX document")
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	now := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
+	suppressions := []*mdmlab.VulnerabilitySuppression{
+		{CVE: "CVE-2024-0002", Justification: mdmlab.VEXJustificationInlineMitigationsAlreadyExist, Comment: "waf rule"},
+		{CVE: "CVE-2024-0001", TeamID: ptr.Uint(0), Justification: mdmlab.VEXJustificationVulnerableCodeNotPresent},
+		{CVE: "CVE-2024-0001", HostID: ptr.Uint(4), Justification: mdmlab.VEXJustificationVulnerableCodeNotPresent},
+	}
+
+	for _, format := range []Format{FormatOpenVEX, FormatCycloneDX} {
+		t.Run(string(format), func(t *testing.T) {
+			b, err := Export(format, suppressions, "admin@example.com", now)
+			require.NoError(t, err)
+			require.True(t, json.Valid(b))
+
+			parsed, err := Parse(b)
+			require.NoError(t, err)
+			require.Len(t, parsed, 3)
+
+			// statements are sorted by CVE and the suppressions sharing a statement are grouped
+			require.Equal(t, "CVE-2024-0001", parsed[0].CVE)
+			require.Equal(t, "team/0", parsed[0].ScopeKey())
+			require.Equal(t, "CVE-2024-0001", parsed[1].CVE)
+			require.Equal(t, "host/4", parsed[1].ScopeKey())
+			require.Equal(t, "CVE-2024-0002", parsed[2].CVE)
+			require.Equal(t, "global", parsed[2].ScopeKey())
+			require.Equal(t, mdmlab.VEXJustificationInlineMitigationsAlreadyExist, parsed[2].Justification)
+			require.Equal(t, "waf rule", parsed[2].Comment)
+		})
+	}
+
+	_, err := Export(Format("spdx"), suppressions, "", now)
+	require.Error(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("OpenVEX")
+	require.NoError(t, err)
+	require.Equal(t, FormatOpenVEX, f)
+
+	f, err = ParseFormat("cyclonedx")
+	require.NoError(t, err)
+	require.Equal(t, FormatCycloneDX, f)
+
+	_, err = ParseFormat("csaf")
+	require.Error(t, err)
+}
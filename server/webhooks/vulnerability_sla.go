@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/it-laborato/MDM_Lab/server"
+	"github.com/it-laborato/MDM_Lab/server/contexts/ctxerr"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+)
+
+type slaBreachHostPayloadPart struct {
+	ID              uint      `json:"id"`
+	DisplayName     string    `json:"display_name"`
+	URL             string    `json:"url"`
+	FirstDetectedAt time.Time `json:"first_detected_at"`
+	DueAt           time.Time `json:"due_at"`
+}
+
+// SLABreachPayload is the payload of the vulnerabilities webhook sent for a
+// vulnerability that was not remediated by its SLA due date.
+type SLABreachPayload struct {
+	CVE              string   `json:"cve"`
+	Link             string   `json:"details_link"`
+	SLARule          string   `json:"sla_rule"`
+	CVSSScore        *float64 `json:"cvss_score,omitempty"`
+	EPSSProbability  *float64 `json:"epss_probability,omitempty"`
+	CISAKnownExploit *bool    `json:"cisa_known_exploit,omitempty"`
+
+	Hosts []*slaBreachHostPayloadPart `json:"hosts_affected"`
+}
+
+// TriggerVulnerabilitySLABreachWebhook sends the vulnerabilities webhook for
+// the vulnerabilities still affecting hosts after their SLA due date. Each
+// host vulnerability is reported once per detection.
+func TriggerVulnerabilitySLABreachWebhook(
+	ctx context.Context,
+	ds mdmlab.Datastore,
+	logger kitlog.Logger,
+	appConfig *mdmlab.AppConfig,
+	now time.Time,
+) error {
+	vulnConfig := appConfig.WebhookSettings.VulnerabilitiesWebhook
+	if !vulnConfig.Enable || !vulnConfig.EnableSLABreachAlerts {
+		return nil
+	}
+
+	rules := appConfig.VulnerabilitySettings.SLARules
+	detectedBefore, ok := mdmlab.VulnerabilitySLAOverdueDetectedBefore(rules, now)
+	if !ok {
+		return nil
+	}
+
+	serverURL, err := url.Parse(appConfig.ServerSettings.ServerURL)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "invalid server url")
+	}
+
+	vulns, err := ds.ListOpenHostVulnerabilities(ctx, mdmlab.HostVulnerabilityListOptions{
+		DetectedBefore:       detectedBefore,
+		SLABreachNotNotified: true,
+	})
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list open host vulnerabilities")
+	}
+	overdue := mdmlab.OverdueHostVulnerabilities(vulns, rules, now)
+	level.Debug(logger).Log("enabled", "true", "slaBreaches", len(overdue))
+
+	cveGrouped := make(map[string][]*mdmlab.OverdueHostVulnerability)
+	for _, v := range overdue {
+		cveGrouped[v.CVE] = append(cveGrouped[v.CVE], v)
+	}
+	cves := make([]string, 0, len(cveGrouped))
+	for cve := range cveGrouped {
+		cves = append(cves, cve)
+	}
+	sort.Strings(cves)
+
+	batchSize := vulnConfig.HostBatchSize
+	for _, cve := range cves {
+		vulns := cveGrouped[cve]
+		for len(vulns) > 0 {
+			limit := len(vulns)
+			if batchSize > 0 && len(vulns) > batchSize {
+				limit = batchSize
+			}
+			batch := vulns[:limit]
+			if err := sendSLABreachBatch(ctx, vulnConfig.DestinationURL, getSLABreachPayload(serverURL, batch), now); err != nil {
+				return ctxerr.Wrap(ctx, err, "send SLA breach batch")
+			}
+
+			ids := make([]uint, 0, len(batch))
+			for _, v := range batch {
+				ids = append(ids, v.ID)
+			}
+			if err := ds.SetHostVulnerabilitiesSLABreachNotified(ctx, ids, now); err != nil {
+				return ctxerr.Wrap(ctx, err, "set host vulnerabilities SLA breach notified")
+			}
+			vulns = vulns[limit:]
+		}
+	}
+
+	return nil
+}
+
+func getSLABreachPayload(hostBaseURL *url.URL, vulns []*mdmlab.OverdueHostVulnerability) SLABreachPayload {
+	// all the vulnerabilities are for the same CVE so they share the same metadata
+	first := vulns[0]
+	payload := SLABreachPayload{
+		CVE:              first.CVE,
+		Link:             fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", first.CVE),
+		SLARule:          first.SLARule,
+		CVSSScore:        first.CVSSScore,
+		EPSSProbability:  first.EPSSProbability,
+		CISAKnownExploit: first.CISAKnownExploit,
+		Hosts:            make([]*slaBreachHostPayloadPart, 0, len(vulns)),
+	}
+	for _, v := range vulns {
+		hostURL := *hostBaseURL
+		hostURL.Path = path.Join(hostURL.Path, "hosts", fmt.Sprint(v.HostID))
+		payload.Hosts = append(payload.Hosts, &slaBreachHostPayloadPart{
+			ID:              v.HostID,
+			DisplayName:     v.HostDisplayName,
+			URL:             hostURL.String(),
+			FirstDetectedAt: v.FirstDetectedAt,
+			DueAt:           v.DueAt,
+		})
+	}
+	return payload
+}
+
+func sendSLABreachBatch(ctx context.Context, targetURL string, breach SLABreachPayload, now time.Time) error {
+	payload := map[string]interface{}{
+		"timestamp":  now,
+		"sla_breach": breach,
+	}
+
+	if err := server.PostJSONWithTimeout(ctx, targetURL, &payload); err != nil {
+		return ctxerr.Wrapf(ctx, err, "posting to %s", targetURL)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/it-laborato/MDM_Lab/server/mdmlab"
+	"github.com/it-laborato/MDM_Lab/server/mock"
+	"github.com/it-laborato/MDM_Lab/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerVulnerabilitySLABreachWebhook(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	logger := kitlog.NewNopLogger()
+	now := time.Date(2025, 2, 7, 12, 0, 0, 0, time.UTC)
+
+	var requests []map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(b, &payload))
+		requests = append(requests, payload)
+	}))
+	defer srv.Close()
+
+	appCfg := &mdmlab.AppConfig{
+		ServerSettings: mdmlab.ServerSettings{ServerURL: "https://mdmlab.example.com"},
+		VulnerabilitySettings: mdmlab.VulnerabilitySettings{
+			SLARules: []mdmlab.VulnerabilitySLARule{
+				{Name: "Critical", Days: 14, MinCVSSScore: ptr.Float64(9)},
+				{Name: "Default", Days: 90},
+			},
+		},
+		WebhookSettings: mdmlab.WebhookSettings{
+			VulnerabilitiesWebhook: mdmlab.VulnerabilitiesWebhookSettings{
+				Enable:                true,
+				DestinationURL:        srv.URL,
+				HostBatchSize:         2,
+				EnableSLABreachAlerts: true,
+			},
+		},
+	}
+
+	detected := now.Add(-20 * 24 * time.Hour)
+	ds.ListOpenHostVulnerabilitiesFunc = func(ctx context.Context, opts mdmlab.HostVulnerabilityListOptions) ([]*mdmlab.HostVulnerability, error) {
+		require.True(t, opts.SLABreachNotNotified)
+		require.Equal(t, now.Add(-14*24*time.Hour), opts.DetectedBefore)
+		return []*mdmlab.HostVulnerability{
+			{ID: 1, HostID: 1, HostDisplayName: "h1", CVE: "CVE-2024-0002", CVSSScore: ptr.Float64(9.8), FirstDetectedAt: detected},
+			{ID: 2, HostID: 2, HostDisplayName: "h2", CVE: "CVE-2024-0002", CVSSScore: ptr.Float64(9.8), FirstDetectedAt: detected},
+			{ID: 3, HostID: 3, HostDisplayName: "h3", CVE: "CVE-2024-0002", CVSSScore: ptr.Float64(9.8), FirstDetectedAt: detected},
+			{ID: 4, HostID: 1, HostDisplayName: "h1", CVE: "CVE-2024-0001", CVSSScore: ptr.Float64(9.1), FirstDetectedAt: detected},
+			// not overdue yet
+			{ID: 5, HostID: 1, HostDisplayName: "h1", CVE: "CVE-2024-0003", CVSSScore: ptr.Float64(5), FirstDetectedAt: detected},
+		}, nil
+	}
+	var notified []uint
+	ds.SetHostVulnerabilitiesSLABreachNotifiedFunc = func(ctx context.Context, ids []uint, notifiedAt time.Time) error {
+		require.Equal(t, now, notifiedAt)
+		notified = append(notified, ids...)
+		return nil
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		appCfg := *appCfg
+		appCfg.WebhookSettings.VulnerabilitiesWebhook.EnableSLABreachAlerts = false
+		require.NoError(t, TriggerVulnerabilitySLABreachWebhook(ctx, ds, logger, &appCfg, now))
+		require.False(t, ds.ListOpenHostVulnerabilitiesFuncInvoked)
+	})
+
+	t.Run("no rules", func(t *testing.T) {
+		appCfg := *appCfg
+		appCfg.VulnerabilitySettings.SLARules = nil
+		require.NoError(t, TriggerVulnerabilitySLABreachWebhook(ctx, ds, logger, &appCfg, now))
+		require.False(t, ds.ListOpenHostVulnerabilitiesFuncInvoked)
+	})
+
+	t.Run("trigger requests", func(t *testing.T) {
+		require.NoError(t, TriggerVulnerabilitySLABreachWebhook(ctx, ds, logger, appCfg, now))
+		require.Equal(t, []uint{4, 1, 2, 3}, notified)
+
+		// requests are sent by CVE, in batches of 2 hosts
+		require.Len(t, requests, 3)
+		var breaches []SLABreachPayload
+		for _, r := range requests {
+			var breach SLABreachPayload
+			require.NoError(t, json.Unmarshal(r["sla_breach"], &breach))
+			breaches = append(breaches, breach)
+		}
+		require.Equal(t, "CVE-2024-0001", breaches[0].CVE)
+		require.Len(t, breaches[0].Hosts, 1)
+		require.Equal(t, "CVE-2024-0002", breaches[1].CVE)
+		require.Equal(t, "Critical", breaches[1].SLARule)
+		require.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2024-0002", breaches[1].Link)
+		require.Len(t, breaches[1].Hosts, 2)
+		require.Equal(t, "https://mdmlab.example.com/hosts/1", breaches[1].Hosts[0].URL)
+		require.Equal(t, detected.Add(14*24*time.Hour), breaches[1].Hosts[0].DueAt)
+		require.Len(t, breaches[2].Hosts, 1)
+		require.Equal(t, uint(3), breaches[2].Hosts[0].ID)
+	})
+}
@@ -325,15 +325,19 @@ func (a *AppleMDM) installBootstrapPackage(ctx context.Context, hostUUID string,
 		return "", err
 	}
 
-	// Get CloudFront CDN signed URL if configured
-	url := a.getSignedURL(ctx, meta)
+	appCfg, err := a.Datastore.AppConfig(ctx)
+	if err != nil {
+		return "", err
+	}
 
+	// Get the URL of the local software installer cache of the host's site,
+	// or CloudFront CDN signed URL if configured
+	url := a.getCacheURL(ctx, hostUUID, meta, appCfg)
 	if url == "" {
-		appCfg, err := a.Datastore.AppConfig(ctx)
-		if err != nil {
-			return "", err
-		}
+		url = a.getSignedURL(ctx, meta)
+	}
 
+	if url == "" {
 		url, err = meta.URL(appCfg.MDMUrl())
 		if err != nil {
 			return "", err
@@ -354,6 +358,38 @@ func (a *AppleMDM) installBootstrapPackage(ctx context.Context, hostUUID string,
 	return cmdUUID, nil
 }
 
+// getCacheURL returns the URL of the bootstrap package on the software
+// installer cache that serves the host's subnet, or an empty string if there
+// is none. The IP address of the host is only known if it was enrolled before
+// (e.g. a host that is re-provisioned), new hosts download the package from
+// the server.
+func (a *AppleMDM) getCacheURL(ctx context.Context, hostUUID string, meta *mdmlab.MDMAppleBootstrapPackage, appCfg *mdmlab.AppConfig) string {
+	if len(appCfg.SoftwareInstallerSettings.Caches) == 0 {
+		return ""
+	}
+
+	host, err := a.Datastore.HostByIdentifier(ctx, hostUUID)
+	if err != nil {
+		if !mdmlab.IsNotFound(err) {
+			// log the error but continue without cache
+			level.Error(a.Log).Log("msg", "failed to get host for bootstrap package cache", "host_uuid", hostUUID, "err", err)
+		}
+		return ""
+	}
+	cacheURL := appCfg.SoftwareInstallerSettings.CacheURLForIP(host.PrimaryIP)
+	if cacheURL == "" {
+		return ""
+	}
+
+	pkgURL, err := meta.URL(cacheURL)
+	if err != nil {
+		level.Error(a.Log).Log("msg", "failed to build bootstrap package cache URL", "cache_url", cacheURL, "err", err)
+		return ""
+	}
+	// the cache verifies the package with its hash before storing it
+	return pkgURL + "&sha256=" + hex.EncodeToString(meta.Sha256)
+}
+
 func (a *AppleMDM) getSignedURL(ctx context.Context, meta *mdmlab.MDMAppleBootstrapPackage) string {
 	var url string
 	if a.BootstrapPackageStore != nil {
@@ -14,6 +14,7 @@ import (
 	"github.com/it-laborato/MDM_Lab/server/mdmlab"
 	apple_mdm "github.com/it-laborato/MDM_Lab/server/mdm/apple"
 	nanomdm_push "github.com/it-laborato/MDM_Lab/server/mdm/nanomdm/push"
+	dsmock "github.com/it-laborato/MDM_Lab/server/mock"
 	mock "github.com/it-laborato/MDM_Lab/server/mock/mdm"
 	"github.com/it-laborato/MDM_Lab/server/ptr"
 	kitlog "github.com/go-kit/log"
@@ -657,3 +658,53 @@ func TestGetSignedURL(t *testing.T) {
 	assert.False(t, mockStore.ExistsFuncInvoked)
 
 }
+
+func TestGetCacheURL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	meta := &mdmlab.MDMAppleBootstrapPackage{
+		Sha256: []byte{1, 2, 3},
+		Token:  "token",
+	}
+
+	var data []byte
+	buf := bytes.NewBuffer(data)
+	logger := kitlog.NewLogfmtLogger(buf)
+	ds := new(dsmock.Store)
+	a := &AppleMDM{Datastore: ds, Log: logger}
+
+	// no cache configured
+	appCfg := &mdmlab.AppConfig{}
+	assert.Empty(t, a.getCacheURL(ctx, "uuid", meta, appCfg))
+	assert.False(t, ds.HostByIdentifierFuncInvoked)
+
+	appCfg.SoftwareInstallerSettings.Caches = []mdmlab.SoftwareInstallerCache{
+		{URL: "https://cache.example.com:8443", Subnets: []string{"10.1.0.0/16"}},
+	}
+	host := &mdmlab.Host{UUID: "uuid", PrimaryIP: "10.1.2.3"}
+	ds.HostByIdentifierFunc = func(ctx context.Context, identifier string) (*mdmlab.Host, error) {
+		if identifier != host.UUID {
+			return nil, &dsmock.Error{Message: "not found"}
+		}
+		return host, nil
+	}
+
+	// host in the subnet of the cache
+	assert.Equal(t, "https://cache.example.com:8443/api/latest/mdmlab/mdm/bootstrap?token=token&sha256=010203", a.getCacheURL(ctx, "uuid", meta, appCfg))
+
+	// host in another subnet
+	host.PrimaryIP = "192.168.1.2"
+	assert.Empty(t, a.getCacheURL(ctx, "uuid", meta, appCfg))
+
+	// new host, its IP address is unknown
+	assert.Empty(t, a.getCacheURL(ctx, "new-uuid", meta, appCfg))
+	assert.Empty(t, buf.String())
+
+	// the errors are logged
+	ds.HostByIdentifierFunc = func(ctx context.Context, identifier string) (*mdmlab.Host, error) {
+		return nil, errors.New("test error")
+	}
+	assert.Empty(t, a.getCacheURL(ctx, "uuid", meta, appCfg))
+	assert.Contains(t, buf.String(), "test error")
+}